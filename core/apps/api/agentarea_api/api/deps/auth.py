@@ -4,10 +4,13 @@ This module provides dependency injection functions for authentication
 and authorization used across the AgentArea API endpoints.
 """
 
+import hmac
 import logging
 from typing import Annotated
 
 from agentarea_common.auth import UserContext, UserContextDep
+from agentarea_common.config import get_settings
+from agentarea_common.config.app import get_app_settings
 from fastapi import Depends, Header, HTTPException, status
 
 logger = logging.getLogger(__name__)
@@ -62,3 +65,36 @@ async def get_admin_user_context(
 
 # Type alias for admin context dependency
 AdminUserContextDep = Annotated[UserContext, Depends(get_admin_user_context)]
+
+
+async def verify_manager_token(
+    authorization: str | None = Header(None, alias="Authorization"),
+) -> None:
+    """Verify that a request comes from an MCP manager.
+
+    Managers call the platform with MCP_MANAGER_SERVICE_TOKEN as a bearer token instead of a
+    user's JWT. Without a token configured, their calls are only accepted in DEV_MODE.
+
+    Raises:
+        HTTPException: If the token is missing or wrong, or none is configured
+    """
+    expected = get_settings().mcp.MCP_MANAGER_SERVICE_TOKEN
+    if not expected:
+        if get_app_settings().DEV_MODE:
+            return
+        raise HTTPException(
+            status_code=status.HTTP_503_SERVICE_UNAVAILABLE,
+            detail="MCP_MANAGER_SERVICE_TOKEN is not configured",
+        )
+
+    scheme, _, token = (authorization or "").partition(" ")
+    if scheme.lower() != "bearer" or not hmac.compare_digest(token.strip(), expected):
+        raise HTTPException(
+            status_code=status.HTTP_401_UNAUTHORIZED,
+            detail="A valid MCP manager service token is required",
+            headers={"WWW-Authenticate": "Bearer"},
+        )
+
+
+# Type alias for routes only MCP managers may call
+ManagerTokenDep = Annotated[None, Depends(verify_manager_token)]
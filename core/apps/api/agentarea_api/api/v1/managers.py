@@ -0,0 +1,117 @@
+"""Registry of the MCP managers serving this platform.
+
+Each manager PUTs a heartbeat with its version, backend and capacity every few seconds and
+DELETEs its registration on shutdown. Registrations are kept in Redis so every API worker
+sees the same list; a manager whose heartbeats stop is reported with ``alive: false``.
+
+Only managers can register and deregister: those calls carry MCP_MANAGER_SERVICE_TOKEN
+instead of a user's JWT. Users can list the registrations.
+"""
+
+import json
+import logging
+from datetime import UTC, datetime
+
+import redis.asyncio as redis
+from agentarea_api.api.deps.auth import ManagerTokenDep
+from agentarea_common.config import get_settings
+from fastapi import APIRouter, HTTPException, Query, Response
+from pydantic import BaseModel, Field
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter(prefix="/managers", tags=["managers"])
+
+KEY_PREFIX = "mcp_manager:"
+# Registrations of managers that stopped sending heartbeats stay listed for a day
+RETENTION_SECONDS = 24 * 60 * 60
+# A manager is considered dead once it missed this many heartbeats
+MISSED_HEARTBEATS = 3
+
+
+class ManagerCapacity(BaseModel):
+    max_instances: int = 0
+    gpus: int = 0
+
+
+class ManagerHeartbeat(BaseModel):
+    manager_id: str
+    version: str
+    backend_type: str
+    url: str | None = None
+    capacity: ManagerCapacity = Field(default_factory=ManagerCapacity)
+    running_instances: int = 0
+    total_instances: int = 0
+    heartbeat_interval_seconds: int = 15
+    started_at: datetime | None = None
+    timestamp: datetime | None = None
+
+
+class ManagerResponse(ManagerHeartbeat):
+    last_seen: datetime
+    alive: bool
+
+
+def _redis() -> redis.Redis:
+    return redis.from_url(get_settings().mcp.REDIS_URL, decode_responses=True)
+
+
+def _to_response(data: str) -> ManagerResponse:
+    record = json.loads(data)
+    heartbeat = ManagerHeartbeat.model_validate(record["heartbeat"])
+    last_seen = datetime.fromisoformat(record["last_seen"])
+    deadline = max(heartbeat.heartbeat_interval_seconds, 1) * MISSED_HEARTBEATS
+    alive = (datetime.now(UTC) - last_seen).total_seconds() <= deadline
+    return ManagerResponse(**heartbeat.model_dump(), last_seen=last_seen, alive=alive)
+
+
+@router.put("/{manager_id}", status_code=204)
+async def register_manager(
+    manager_id: str, heartbeat: ManagerHeartbeat, _: ManagerTokenDep
+) -> Response:
+    """Register a manager or refresh its registration."""
+    if heartbeat.manager_id != manager_id:
+        raise HTTPException(status_code=400, detail="manager_id does not match the URL")
+
+    record = {
+        "heartbeat": heartbeat.model_dump(mode="json"),
+        "last_seen": datetime.now(UTC).isoformat(),
+    }
+    async with _redis() as client:
+        await client.set(KEY_PREFIX + manager_id, json.dumps(record), ex=RETENTION_SECONDS)
+    return Response(status_code=204)
+
+
+@router.get("/", response_model=list[ManagerResponse])
+async def list_managers(
+    alive: bool | None = Query(None, description="Only list managers that are (or are not) alive"),
+):
+    """List registered managers, including those whose heartbeats stopped recently."""
+    async with _redis() as client:
+        keys = [key async for key in client.scan_iter(match=KEY_PREFIX + "*")]
+        values = await client.mget(keys) if keys else []
+
+    managers = [_to_response(value) for value in values if value]
+    if alive is not None:
+        managers = [manager for manager in managers if manager.alive == alive]
+    return sorted(managers, key=lambda manager: manager.manager_id)
+
+
+@router.get("/{manager_id}", response_model=ManagerResponse)
+async def get_manager(manager_id: str):
+    """Get a registered manager."""
+    async with _redis() as client:
+        value = await client.get(KEY_PREFIX + manager_id)
+    if value is None:
+        raise HTTPException(status_code=404, detail="Manager not found")
+    return _to_response(value)
+
+
+@router.delete("/{manager_id}", status_code=204)
+async def deregister_manager(manager_id: str, _: ManagerTokenDep) -> Response:
+    """Remove a manager's registration, e.g. when it shuts down cleanly."""
+    async with _redis() as client:
+        removed = await client.delete(KEY_PREFIX + manager_id)
+    if removed:
+        logger.info(f"MCP manager {manager_id} deregistered")
+    return Response(status_code=204)
@@ -4,17 +4,29 @@ from typing import Any
 from uuid import UUID
 
 import httpx
-from agentarea_api.api.deps.services import get_mcp_server_instance_service
+from agentarea_api.api.deps.auth import ManagerTokenDep
+from agentarea_api.api.deps.services import DatabaseSessionDep, get_mcp_server_instance_service
 from agentarea_common.config import get_settings
 from agentarea_mcp.application.service import MCPServerInstanceService
 from agentarea_mcp.domain.mpc_server_instance_model import MCPServerInstance
-from fastapi import APIRouter, Depends, HTTPException, Query
+from fastapi import APIRouter, Depends, HTTPException, Query, Response
 from pydantic import BaseModel, Field
+from sqlalchemy import update
 
 logger = logging.getLogger(__name__)
 
 router = APIRouter(prefix="/mcp-server-instances", tags=["mcp-server-instances"])
 
+# Provisioning phases the MCP manager reports that have a matching instance status; other
+# phases, such as "running" or "stopped", are stored as they are
+PROVISIONING_STATUSES = {
+    "pulling": "pending",
+    "building": "pending",
+    "starting": "pending",
+    "healthy": "running",
+    "failed": "error",
+}
+
 
 class MCPServerInstanceCreateRequest(BaseModel):
     name: str = Field(..., description="Name of the MCP server instance")
@@ -30,6 +42,17 @@ class MCPServerInstanceUpdate(BaseModel):
     status: str | None = None
 
 
+class MCPServerInstanceProvisioning(BaseModel):
+    id: str = Field(..., description="Delivery ID, the same for a redelivered callback")
+    instance_id: str
+    name: str
+    phase: str = Field(..., max_length=50)
+    reason: str | None = None
+    container_id: str | None = None
+    url: str | None = None
+    timestamp: datetime
+
+
 class MCPServerInstanceResponse(BaseModel):
     id: UUID
     name: str
@@ -312,6 +335,32 @@ async def stop_mcp_server_instance(
     return {"status": "success", "message": "Instance stopped successfully"}
 
 
+@router.post("/{instance_id}/provisioning", status_code=204)
+async def report_mcp_server_instance_provisioning(
+    instance_id: UUID,
+    progress: MCPServerInstanceProvisioning,
+    session: DatabaseSessionDep,
+    _: ManagerTokenDep,
+) -> Response:
+    """Record provisioning progress the MCP manager reports for an instance.
+
+    Called by the manager with its service token rather than by a user, so the instance is
+    looked up across workspaces. An unknown instance is a 404, which the manager does not retry.
+    """
+    status = PROVISIONING_STATUSES.get(progress.phase, progress.phase)
+    result = await session.execute(
+        update(MCPServerInstance).where(MCPServerInstance.id == instance_id).values(status=status)
+    )
+    if result.rowcount == 0:
+        raise HTTPException(status_code=404, detail="MCP Server Instance not found")
+
+    logger.info(
+        f"MCP manager reported {progress.phase} for instance {instance_id}"
+        + (f": {progress.reason}" if progress.reason else "")
+    )
+    return Response(status_code=204)
+
+
 # REMOVED: Insecure endpoint that exposed secrets via HTTP
 # Secrets are now resolved directly in the Go service using Infisical SDK
 
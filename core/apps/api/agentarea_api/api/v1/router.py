@@ -7,6 +7,7 @@ from . import (
     agents_tasks,
     agents_well_known,
     auth,
+    managers,
     mcp_server_instances,
     mcp_servers_specifications,
     model_instances,
@@ -37,6 +38,7 @@ v1_router.include_router(agents_a2a.router, prefix="/agents/{agent_id}")
 v1_router.include_router(agents_well_known.router, prefix="/agents/{agent_id}")
 v1_router.include_router(mcp_servers_specifications.router)
 v1_router.include_router(mcp_server_instances.router)
+v1_router.include_router(managers.router)
 
 # Include LLM architecture routers (4-entity system)
 v1_router.include_router(provider_specs.router)
@@ -5,6 +5,7 @@ authentication using the modular auth provider system.
 """
 
 import logging
+import re
 
 from fastapi import Request, status
 from starlette.middleware.base import BaseHTTPMiddleware
@@ -19,6 +20,14 @@ from .providers.factory import AuthProviderFactory
 
 logger = logging.getLogger(__name__)
 
+# Routes MCP managers call with their service token instead of a user's JWT. The routes verify
+# the token themselves, so the middleware lets them through.
+MANAGER_ROUTES = [
+    ("PUT", re.compile(r"^/v1/managers/[^/]+/?$")),
+    ("DELETE", re.compile(r"^/v1/managers/[^/]+/?$")),
+    ("POST", re.compile(r"^/v1/mcp-server-instances/[^/]+/provisioning/?$")),
+]
+
 
 class AuthMiddleware(BaseHTTPMiddleware):
     """Authentication middleware for FastAPI applications."""
@@ -162,4 +171,8 @@ class AuthMiddleware(BaseHTTPMiddleware):
             if request.url.path.startswith(prefix):
                 return True
 
+        for method, pattern in MANAGER_ROUTES:
+            if request.method == method and pattern.match(request.url.path):
+                return True
+
         return False
@@ -12,6 +12,8 @@ class MCPSettings(BaseAppSettings):
     MCP_PROXY_HOST: str = "http://localhost:7999"  # Host for agents to access MCP servers
     MCP_CLIENT_TIMEOUT: int = 30
     REDIS_URL: str = "redis://localhost:6379"
+    # Bearer token MCP managers register and report provisioning progress with
+    MCP_MANAGER_SERVICE_TOKEN: str = ""
 
 
 class MCPManagerSettings(BaseSettings):
@@ -0,0 +1,157 @@
+"""
+Unit tests for the MCP manager registry endpoints and their service token
+"""
+
+from types import SimpleNamespace
+
+import pytest
+from agentarea_api.api.deps import auth as auth_deps
+from agentarea_api.api.v1 import managers
+from agentarea_common.auth.middleware import AuthMiddleware
+from fastapi import FastAPI
+from fastapi.testclient import TestClient
+
+SERVICE_TOKEN = "manager-service-token"
+
+HEARTBEAT = {
+    "manager_id": "manager-1",
+    "version": "1.2.0",
+    "backend_type": "podman",
+    "capacity": {"max_instances": 50, "gpus": 0},
+    "heartbeat_interval_seconds": 15,
+}
+
+
+class FakeRedis:
+    """In-memory stand-in for the registry's Redis client."""
+
+    def __init__(self):
+        self.data: dict[str, str] = {}
+
+    async def __aenter__(self):
+        return self
+
+    async def __aexit__(self, *args):
+        return None
+
+    async def set(self, key, value, ex=None):
+        self.data[key] = value
+
+    async def get(self, key):
+        return self.data.get(key)
+
+    async def mget(self, keys):
+        return [self.data.get(key) for key in keys]
+
+    async def delete(self, key):
+        return 1 if self.data.pop(key, None) is not None else 0
+
+    async def scan_iter(self, match):
+        prefix = match.rstrip("*")
+        for key in list(self.data):
+            if key.startswith(prefix):
+                yield key
+
+
+def configure(monkeypatch, token: str, dev_mode: bool = False) -> None:
+    monkeypatch.setattr(
+        auth_deps,
+        "get_settings",
+        lambda: SimpleNamespace(mcp=SimpleNamespace(MCP_MANAGER_SERVICE_TOKEN=token)),
+    )
+    monkeypatch.setattr(auth_deps, "get_app_settings", lambda: SimpleNamespace(DEV_MODE=dev_mode))
+
+
+@pytest.fixture
+def redis_client(monkeypatch):
+    client = FakeRedis()
+    monkeypatch.setattr(managers, "_redis", lambda: client)
+    return client
+
+
+@pytest.fixture
+def client(redis_client):
+    app = FastAPI()
+    app.include_router(managers.router, prefix="/v1")
+    return TestClient(app)
+
+
+def bearer(token: str) -> dict[str, str]:
+    return {"Authorization": f"Bearer {token}"}
+
+
+class TestManagerRegistry:
+    """Test cases for registering, listing and deregistering managers."""
+
+    def test_register_requires_service_token(self, monkeypatch, client, redis_client):
+        """Heartbeats without the service token, or with a user's token, are rejected."""
+        configure(monkeypatch, SERVICE_TOKEN)
+
+        response = client.put("/v1/managers/manager-1", json=HEARTBEAT)
+        assert response.status_code == 401
+
+        response = client.put("/v1/managers/manager-1", json=HEARTBEAT, headers=bearer("user-jwt"))
+        assert response.status_code == 401
+        assert redis_client.data == {}
+
+    def test_register_list_and_deregister(self, monkeypatch, client, redis_client):
+        """A manager with the service token registers, is listed and deregisters."""
+        configure(monkeypatch, SERVICE_TOKEN)
+
+        response = client.put(
+            "/v1/managers/manager-1", json=HEARTBEAT, headers=bearer(SERVICE_TOKEN)
+        )
+        assert response.status_code == 204
+
+        response = client.get("/v1/managers/")
+        assert response.status_code == 200
+        listed = response.json()
+        assert [manager["manager_id"] for manager in listed] == ["manager-1"]
+        assert listed[0]["alive"] is True
+
+        response = client.delete("/v1/managers/manager-1")
+        assert response.status_code == 401
+        assert "mcp_manager:manager-1" in redis_client.data
+
+        response = client.delete("/v1/managers/manager-1", headers=bearer(SERVICE_TOKEN))
+        assert response.status_code == 204
+        assert redis_client.data == {}
+
+    def test_register_rejects_mismatched_id(self, monkeypatch, client):
+        """The manager ID in the body has to match the URL."""
+        configure(monkeypatch, SERVICE_TOKEN)
+
+        response = client.put(
+            "/v1/managers/manager-2", json=HEARTBEAT, headers=bearer(SERVICE_TOKEN)
+        )
+        assert response.status_code == 400
+
+    def test_register_without_configured_token(self, monkeypatch, client):
+        """Without a configured token, registrations are only accepted in DEV_MODE."""
+        configure(monkeypatch, "")
+        response = client.put("/v1/managers/manager-1", json=HEARTBEAT)
+        assert response.status_code == 503
+
+        configure(monkeypatch, "", dev_mode=True)
+        response = client.put("/v1/managers/manager-1", json=HEARTBEAT)
+        assert response.status_code == 204
+
+
+class TestManagerRoutesBypassUserAuth:
+    """The auth middleware leaves manager calls to the service token check."""
+
+    @pytest.mark.parametrize(
+        ("method", "path", "public"),
+        [
+            ("PUT", "/v1/managers/manager-1", True),
+            ("DELETE", "/v1/managers/manager-1", True),
+            ("POST", "/v1/mcp-server-instances/abc/provisioning", True),
+            ("GET", "/v1/managers/", False),
+            ("GET", "/v1/managers/manager-1", False),
+            ("PATCH", "/v1/mcp-server-instances/abc", False),
+        ],
+    )
+    def test_manager_routes(self, method, path, public):
+        middleware = AuthMiddleware.__new__(AuthMiddleware)
+        request = SimpleNamespace(method=method, url=SimpleNamespace(path=path))
+        assert middleware._is_public_route(request) is public
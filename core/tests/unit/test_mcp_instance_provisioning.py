@@ -0,0 +1,114 @@
+"""
+Unit tests for provisioning progress the MCP manager reports for an instance
+"""
+
+from types import SimpleNamespace
+from uuid import uuid4
+
+import pytest
+from agentarea_api.api.deps import auth as auth_deps
+from agentarea_api.api.v1 import mcp_server_instances
+from agentarea_common.infrastructure.database import get_db_session
+from fastapi import FastAPI
+from fastapi.testclient import TestClient
+
+SERVICE_TOKEN = "manager-service-token"
+
+
+class FakeSession:
+    """Records the statements it executes and reports rowcount rows updated."""
+
+    def __init__(self, rowcount: int = 1):
+        self.rowcount = rowcount
+        self.statements = []
+
+    async def execute(self, statement):
+        self.statements.append(statement)
+        return SimpleNamespace(rowcount=self.rowcount)
+
+
+@pytest.fixture
+def session(monkeypatch):
+    monkeypatch.setattr(
+        auth_deps,
+        "get_settings",
+        lambda: SimpleNamespace(mcp=SimpleNamespace(MCP_MANAGER_SERVICE_TOKEN=SERVICE_TOKEN)),
+    )
+    monkeypatch.setattr(auth_deps, "get_app_settings", lambda: SimpleNamespace(DEV_MODE=False))
+    return FakeSession()
+
+
+@pytest.fixture
+def client(session):
+    app = FastAPI()
+    app.include_router(mcp_server_instances.router, prefix="/v1")
+
+    async def override_session():
+        yield session
+
+    app.dependency_overrides[get_db_session] = override_session
+    return TestClient(app)
+
+
+def progress(instance_id: str, phase: str, reason: str | None = None) -> dict:
+    return {
+        "id": "delivery-1",
+        "instance_id": instance_id,
+        "name": "search",
+        "phase": phase,
+        "reason": reason,
+        "timestamp": "2026-01-01T00:00:00Z",
+    }
+
+
+def updated_status(statement) -> str:
+    return statement.compile().params["status"]
+
+
+class TestProvisioningCallback:
+    """Test cases for POST /v1/mcp-server-instances/{instance_id}/provisioning."""
+
+    def test_requires_service_token(self, client, session):
+        """Callbacks without the manager's service token change nothing."""
+        instance_id = str(uuid4())
+
+        response = client.post(
+            f"/v1/mcp-server-instances/{instance_id}/provisioning",
+            json=progress(instance_id, "healthy"),
+        )
+        assert response.status_code == 401
+        assert session.statements == []
+
+    @pytest.mark.parametrize(
+        ("phase", "status"),
+        [
+            ("pulling", "pending"),
+            ("healthy", "running"),
+            ("failed", "error"),
+            ("stopped", "stopped"),
+        ],
+    )
+    def test_updates_instance_status(self, client, session, phase, status):
+        """Each phase is stored as the instance status it corresponds to."""
+        instance_id = str(uuid4())
+
+        response = client.post(
+            f"/v1/mcp-server-instances/{instance_id}/provisioning",
+            json=progress(instance_id, phase),
+            headers={"Authorization": f"Bearer {SERVICE_TOKEN}"},
+        )
+        assert response.status_code == 204
+        assert len(session.statements) == 1
+        assert updated_status(session.statements[0]) == status
+
+    def test_unknown_instance(self, client, session):
+        """An instance the platform does not know is a 404 the manager does not retry."""
+        session.rowcount = 0
+        instance_id = str(uuid4())
+
+        response = client.post(
+            f"/v1/mcp-server-instances/{instance_id}/provisioning",
+            json=progress(instance_id, "failed", "image not found"),
+            headers={"Authorization": f"Bearer {SERVICE_TOKEN}"},
+        )
+        assert response.status_code == 404
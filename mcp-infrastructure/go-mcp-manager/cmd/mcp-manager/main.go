@@ -2,36 +2,57 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
-	"os/exec"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 
 	"github.com/agentarea/mcp-manager/internal/api"
+	"github.com/agentarea/mcp-manager/internal/authz"
 	"github.com/agentarea/mcp-manager/internal/backends"
+	"github.com/agentarea/mcp-manager/internal/chaos"
 	"github.com/agentarea/mcp-manager/internal/config"
 	"github.com/agentarea/mcp-manager/internal/container"
 	"github.com/agentarea/mcp-manager/internal/environment"
 	"github.com/agentarea/mcp-manager/internal/events"
+	"github.com/agentarea/mcp-manager/internal/logbuffer"
+	"github.com/agentarea/mcp-manager/internal/mtls"
 	"github.com/agentarea/mcp-manager/internal/providers"
+	"github.com/agentarea/mcp-manager/internal/proxy"
+	"github.com/agentarea/mcp-manager/internal/registration"
+	"github.com/agentarea/mcp-manager/internal/requestid"
+	"github.com/agentarea/mcp-manager/internal/retry"
 	"github.com/agentarea/mcp-manager/internal/secrets"
+	"github.com/agentarea/mcp-manager/pkg/models"
 )
 
 const version = "0.1.0"
 
 func main() {
+	selfTest := flag.Bool("self-test", false, "run the environment doctor at startup and exit if any check fails")
+	flag.Parse()
+
 	// Load configuration
 	cfg := config.Load()
 
 	// Setup logging
-	logger := setupLogging(cfg)
+	logger, logBuffer := setupLogging(cfg)
+
+	// Every dependency gets the configured backoff and its own retry budget
+	retry.Configure(retry.Policy{
+		MaxAttempts:    cfg.Retry.MaxAttempts,
+		InitialBackoff: cfg.Retry.InitialBackoff,
+		MaxBackoff:     cfg.Retry.MaxBackoff,
+		BudgetRatio:    cfg.Retry.BudgetRatio,
+		BudgetMin:      cfg.Retry.BudgetMin,
+	})
 
 	// Create context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -40,6 +61,7 @@ func main() {
 	// Detect environment and initialize appropriate backend
 	var backend backends.Backend
 	var containerManager *container.Manager
+	var fakeBackend *backends.Fake
 	
 	if cfg.Environment != "" {
 		logger.Info("Using forced environment", slog.String("environment", cfg.Environment))
@@ -78,15 +100,41 @@ func main() {
 			os.Exit(1)
 		}
 		
+	case "fake":
+		logger.Info("Initializing fake backend")
+		fakePublisher := events.NewEventPublisher(cfg.Redis.URL, logger)
+		fakePublisher.SetSigningSecret(cfg.Redis.SigningSecret)
+		fakeBackend = backends.NewFake(cfg, fakePublisher, logger)
+		backend = fakeBackend
+
+		if err := backend.Initialize(ctx); err != nil {
+			logger.Error("Failed to initialize fake backend", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+
 	default:
 		logger.Error("Unsupported environment type", slog.String("type", envType))
 		os.Exit(1)
 	}
 
-	// Start Traefik in background only for Docker environments
+	// Fault injection for integration tests; injects nothing unless CHAOS_ENABLED is set
+	chaosController := chaos.NewController(cfg.Chaos.Enabled, logger)
+	if containerManager != nil {
+		containerManager.SetChaos(chaosController)
+	}
+
+	// Supervise Traefik in background only for Docker environments
+	var proxySupervisor *proxy.Supervisor
 	if envType == "docker" {
+		if cfg.Traefik.Mode != proxy.ModeExternal {
+			if err := proxy.ValidateConfig(cfg.Traefik); err != nil {
+				logger.Error("Invalid Traefik configuration", slog.String("error", err.Error()))
+				os.Exit(1)
+			}
+		}
+		proxySupervisor = proxy.NewSupervisor(cfg.Traefik, logger)
 		go func() {
-			if err := startTraefik(logger); err != nil {
+			if err := proxySupervisor.Run(ctx); err != nil {
 				logger.Error("Failed to start Traefik", slog.String("error", err.Error()))
 			}
 		}()
@@ -100,12 +148,24 @@ func main() {
 	}
 	defer secretResolver.Close()
 
+	if containerManager != nil {
+		containerManager.SetSecretResolver(secretResolver)
+	}
+	if *selfTest {
+		runSelfTest(ctx, containerManager, logger)
+	}
+
 	// Initialize providers based on environment
 	var providerManager *providers.ProviderManager
 	if envType == "docker" && containerManager != nil {
 		dockerProvider := providers.NewDockerProvider(secretResolver, containerManager, logger)
 		urlProvider := providers.NewURLProvider(logger)
 		providerManager = providers.NewProviderManager(dockerProvider, urlProvider)
+	} else if fakeBackend != nil {
+		// Instance events are simulated by the fake backend in place of the container manager
+		dockerProvider := providers.NewDockerProvider(secretResolver, fakeBackend, logger)
+		urlProvider := providers.NewURLProvider(logger)
+		providerManager = providers.NewProviderManager(dockerProvider, urlProvider)
 	} else {
 		// For Kubernetes, we'll use the backend directly through the API
 		urlProvider := providers.NewURLProvider(logger)
@@ -114,6 +174,14 @@ func main() {
 
 	// Initialize event subscriber
 	eventSubscriber := events.NewEventSubscriber(cfg.Redis.URL, providerManager, logger)
+	eventSubscriber.SetChaos(chaosController)
+	eventSubscriber.AcceptLegacyEvents(cfg.Redis.AcceptLegacyEvents)
+	if cfg.Redis.SigningEnabled() {
+		eventSubscriber.RequireSignatures(cfg.Redis)
+		logger.Info("Event signature verification enabled",
+			slog.Bool("hmac", cfg.Redis.SigningSecret != ""),
+			slog.Bool("jwt", cfg.Redis.SigningJWKSURL != ""))
+	}
 
 	// Start event subscriber in a goroutine
 	go func() {
@@ -122,9 +190,43 @@ func main() {
 		}
 	}()
 
+	// Keep this manager registered with the Core API until shutdown
+	heartbeat := registration.NewHeartbeat(cfg, version, envType, countInstances(backend), logger)
+	heartbeatCtx, stopHeartbeat := context.WithCancel(ctx)
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		heartbeat.Run(heartbeatCtx)
+	}()
+
+	// API callers are authenticated by the rate limiter and the role checks alike
+	var authenticator *authz.Authenticator
+	if cfg.Authz.Enabled() {
+		authenticator, err = authz.New(cfg.Authz, logger)
+		if err != nil {
+			logger.Error("Invalid API authorization configuration", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
+
 	// Setup HTTP router
-	router := setupRouter(cfg, logger)
+	router := setupRouter(cfg, authenticator, logger)
 	handler := api.NewHandler(backend, containerManager, logger, version)
+	handler.AddReadinessCheck("redis", eventSubscriber.Ping)
+	if secretResolver.Configured() {
+		handler.AddReadinessCheck("secrets", secretResolver.Ping)
+	}
+	if proxySupervisor != nil {
+		handler.SetProxySupervisor(proxySupervisor)
+	}
+	handler.SetChaos(chaosController)
+	handler.SetLogBuffer(logBuffer)
+	if authenticator != nil {
+		handler.SetAuthenticator(authenticator)
+		logger.Info("API authorization enabled",
+			slog.Bool("api_keys", cfg.Authz.APIKeysFile != ""),
+			slog.Bool("jwt", cfg.Authz.JWKSURL != ""))
+	}
 	handler.SetupRoutes(router)
 
 	// Start HTTP server
@@ -135,13 +237,36 @@ func main() {
 		WriteTimeout: cfg.Server.WriteTimeout,
 	}
 
+	// Require client certificates on the management API when it has a certificate of its own
+	if cfg.MTLS.APIEnabled() {
+		// The proxy's callbacks present certificates of the internal CA
+		authority, err := mtls.LoadOrCreateAuthority(cfg.MTLS.Dir)
+		if err != nil {
+			logger.Warn("Failed to load the internal CA, the proxy cannot call back into the API",
+				slog.String("error", err.Error()))
+		}
+		tlsConfig, err := mtls.ServerConfig(cfg.MTLS, authority)
+		if err != nil {
+			logger.Error("Invalid management API TLS configuration", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		server.TLSConfig = tlsConfig
+	}
+
 	// Start server in a goroutine
 	go func() {
 		logger.Info("Starting MCP Manager with embedded Traefik",
 			slog.String("version", version),
-			slog.String("address", server.Addr))
+			slog.String("address", server.Addr),
+			slog.Bool("mtls", server.TLSConfig != nil))
 
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if server.TLSConfig != nil {
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			logger.Error("Server failed to start", slog.String("error", err.Error()))
 			os.Exit(1)
 		}
@@ -154,19 +279,28 @@ func main() {
 
 	logger.Info("Shutting down server...")
 
+	// Deregister first so the platform stops scheduling onto this manager
+	stopHeartbeat()
+	<-heartbeatDone
+
 	// Graceful shutdown
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.Container.ShutdownTimeout)
 	defer shutdownCancel()
 
-	if err := server.Shutdown(shutdownCtx); err != nil {
-		logger.Error("Server forced to shutdown", slog.String("error", err.Error()))
-	}
-
 	// Close event subscriber
 	if err := eventSubscriber.Close(); err != nil {
 		logger.Error("Failed to close event subscriber", slog.String("error", err.Error()))
 	}
 
+	// Finish or roll back creates and deletes while the API still answers their callers
+	if containerManager != nil {
+		containerManager.DrainOperations(shutdownCtx)
+	}
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Server forced to shutdown", slog.String("error", err.Error()))
+	}
+
 	// Shutdown backend
 	if err := backend.Shutdown(shutdownCtx); err != nil {
 		logger.Error("Failed to shutdown backend", slog.String("error", err.Error()))
@@ -182,25 +316,80 @@ func main() {
 	logger.Info("Server shutdown complete")
 }
 
-// setupLogging configures structured logging
-func setupLogging(cfg *config.Config) *slog.Logger {
+// countInstances counts the backend's running and tracked instances for the heartbeat
+func countInstances(backend backends.Backend) registration.InstanceCounter {
+	return func(ctx context.Context) (int, int, error) {
+		instances, err := backend.ListInstances(ctx)
+		if err != nil {
+			return 0, 0, err
+		}
+		running := 0
+		for _, instance := range instances {
+			if instance.Status == string(models.StatusRunning) {
+				running++
+			}
+		}
+		return running, len(instances), nil
+	}
+}
+
+// runSelfTest runs the environment doctor and exits with the failed checks' hints if any fails
+func runSelfTest(ctx context.Context, containerManager *container.Manager, logger *slog.Logger) {
+	if containerManager == nil {
+		logger.Error("The startup self-test needs the podman backend; run without --self-test on other backends")
+		os.Exit(1)
+	}
+
+	logger.Info("Running startup self-test")
+	report := containerManager.RunDoctor(ctx)
+	for _, check := range report.Checks {
+		switch check.Status {
+		case models.DoctorFail:
+			logger.Error("Self-test check failed",
+				slog.String("check", check.Name),
+				slog.String("error", check.Message),
+				slog.String("hint", check.Hint))
+		case models.DoctorSkip:
+			logger.Warn("Self-test check skipped", slog.String("check", check.Name), slog.String("reason", check.Message))
+		default:
+			logger.Info("Self-test check passed", slog.String("check", check.Name), slog.String("detail", check.Message))
+		}
+	}
+	if !report.Passed {
+		logger.Error("Startup self-test failed, exiting")
+		os.Exit(1)
+	}
+	logger.Info("Startup self-test passed", slog.Int64("duration_ms", report.DurationMs))
+}
+
+// setupLogging configures structured logging, also keeping recent entries in the returned buffer
+// when LOG_BUFFER_LINES is set
+func setupLogging(cfg *config.Config) (*slog.Logger, *logbuffer.Buffer) {
 	var handler slog.Handler
 
 	opts := &slog.HandlerOptions{
 		Level: getLogLevel(cfg.Logging.Level),
 	}
 
+	var buffer *logbuffer.Buffer
+	output := io.Writer(os.Stdout)
+	if cfg.Logging.BufferLines > 0 {
+		buffer = logbuffer.New(cfg.Logging.BufferLines)
+		output = io.MultiWriter(os.Stdout, buffer)
+	}
+
 	if cfg.Logging.Format == "json" {
-		handler = slog.NewJSONHandler(os.Stdout, opts)
+		handler = slog.NewJSONHandler(output, opts)
 	} else {
-		handler = slog.NewTextHandler(os.Stdout, opts)
+		handler = slog.NewTextHandler(output, opts)
 	}
 
-	return slog.New(handler)
+	// Attach request and trace IDs from the context to every entry
+	return slog.New(requestid.NewHandler(handler)), buffer
 }
 
 // setupRouter configures the HTTP router
-func setupRouter(cfg *config.Config, logger *slog.Logger) *gin.Engine {
+func setupRouter(cfg *config.Config, authenticator *authz.Authenticator, logger *slog.Logger) *gin.Engine {
 	// Set Gin mode based on log level
 	if cfg.Logging.Level == "DEBUG" {
 		gin.SetMode(gin.DebugMode)
@@ -210,12 +399,24 @@ func setupRouter(cfg *config.Config, logger *slog.Logger) *gin.Engine {
 
 	router := gin.New()
 
+	// Only the configured proxies may name the client in X-Forwarded-For, so callers cannot pick
+	// the IP they are rate limited and logged by
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.Error("Invalid SERVER_TRUSTED_PROXIES", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
 	// Add middleware
 	router.Use(gin.Recovery())
 
+	// Accept or generate X-Request-ID before anything logs
+	router.Use(api.RequestIDMiddleware())
+
 	// Add logging middleware
 	router.Use(gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		requestID, _ := param.Keys["request_id"].(string)
 		logger.Info("HTTP request",
+			slog.String("request_id", requestID),
 			slog.String("method", param.Method),
 			slog.String("path", param.Path),
 			slog.Int("status", param.StatusCode),
@@ -224,6 +425,14 @@ func setupRouter(cfg *config.Config, logger *slog.Logger) *gin.Engine {
 		return ""
 	}))
 
+	// Add per-client rate limiting if enabled
+	if cfg.RateLimit.Enabled {
+		router.Use(api.NewRateLimiter(cfg.RateLimit, authenticator, logger).Middleware())
+		logger.Info("API rate limiting enabled",
+			slog.Float64("requests_per_second", cfg.RateLimit.RequestsPerSecond),
+			slog.Int("burst", cfg.RateLimit.Burst))
+	}
+
 	// Add CORS middleware if enabled
 	if cfg.Server.CORSEnabled {
 		corsConfig := cors.DefaultConfig()
@@ -233,8 +442,8 @@ func setupRouter(cfg *config.Config, logger *slog.Logger) *gin.Engine {
 			corsConfig.AllowAllOrigins = true
 		}
 		corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-		corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
-		corsConfig.ExposeHeaders = []string{"Content-Length"}
+		corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", requestid.Header, requestid.TraceparentHeader}
+		corsConfig.ExposeHeaders = []string{"Content-Length", requestid.Header}
 		corsConfig.AllowCredentials = true
 
 		router.Use(cors.New(corsConfig))
@@ -261,65 +470,3 @@ func getLogLevel(level string) slog.Level {
 		return slog.LevelInfo
 	}
 }
-
-// startTraefik starts the Traefik reverse proxy
-func startTraefik(logger *slog.Logger) error {
-	logger.Info("Starting embedded Traefik reverse proxy")
-
-	// Create Traefik static configuration
-	if err := createTraefikStaticConfig(); err != nil {
-		return fmt.Errorf("failed to create Traefik static config: %w", err)
-	}
-
-	// Ensure dynamic config directory exists
-	os.MkdirAll("/etc/traefik", 0755)
-
-	// Start Traefik process
-	cmd := exec.Command("traefik", "--configfile=/etc/traefik/traefik.yml")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start Traefik: %w", err)
-	}
-
-	logger.Info("Traefik started successfully", slog.Int("pid", cmd.Process.Pid))
-
-	// Wait for process to finish (this will run until container stops)
-	if err := cmd.Wait(); err != nil {
-		logger.Error("Traefik process exited", slog.String("error", err.Error()))
-		return err
-	}
-
-	return nil
-}
-
-// createTraefikStaticConfig creates the static Traefik configuration
-func createTraefikStaticConfig() error {
-	staticConfig := `
-# Static Traefik configuration
-global:
-  checkNewVersion: false
-  sendAnonymousUsage: false
-
-log:
-  level: INFO
-
-entryPoints:
-  web:
-    address: ":80"
-  websecure:
-    address: ":443"
-
-providers:
-  file:
-    directory: /etc/traefik
-    watch: true
-
-api:
-  dashboard: true
-  insecure: true
-`
-
-	return os.WriteFile("/etc/traefik/traefik.yml", []byte(staticConfig), 0644)
-}
@@ -0,0 +1,177 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// Notifier names
+const (
+	NotifierSlack     = "slack"
+	NotifierPagerDuty = "pagerduty"
+	NotifierEvents    = "events"
+)
+
+// deliveryAttempts is how often a notification is tried before it is dropped
+const deliveryAttempts = 3
+
+// Notifier delivers alert notifications to one destination
+type Notifier interface {
+	// Name is how rules refer to the notifier
+	Name() string
+	// Notify sends a firing or resolved alert
+	Notify(ctx context.Context, alert models.Alert) error
+}
+
+// New returns the notifiers configured with webhook URLs and keys; the event bus notifier is
+// added by the caller that owns the publisher
+func New(cfg config.AlertingConfig) []Notifier {
+	client := &http.Client{Timeout: cfg.Timeout}
+	var notifiers []Notifier
+	if cfg.SlackWebhookURL != "" {
+		notifiers = append(notifiers, &SlackNotifier{url: cfg.SlackWebhookURL, client: client})
+	}
+	if cfg.PagerDutyRoutingKey != "" {
+		notifiers = append(notifiers, &PagerDutyNotifier{url: cfg.PagerDutyURL, routingKey: cfg.PagerDutyRoutingKey, client: client})
+	}
+	return notifiers
+}
+
+// Summary is the one-line text of a notification
+func Summary(alert models.Alert) string {
+	return fmt.Sprintf("[%s] %s: %s", strings.ToUpper(alert.State), alert.RuleID, alert.Summary)
+}
+
+// SlackNotifier posts alerts to a Slack incoming webhook
+type SlackNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// Name implements Notifier
+func (n *SlackNotifier) Name() string {
+	return NotifierSlack
+}
+
+// Notify implements Notifier
+func (n *SlackNotifier) Notify(ctx context.Context, alert models.Alert) error {
+	return postJSON(ctx, n.client, n.url, map[string]string{"text": Summary(alert)})
+}
+
+// PagerDutyNotifier triggers and resolves PagerDuty incidents through the Events API v2, one
+// incident per alert ID
+type PagerDutyNotifier struct {
+	url        string
+	routingKey string
+	client     *http.Client
+}
+
+// Name implements Notifier
+func (n *PagerDutyNotifier) Name() string {
+	return NotifierPagerDuty
+}
+
+// Notify implements Notifier
+func (n *PagerDutyNotifier) Notify(ctx context.Context, alert models.Alert) error {
+	action := "trigger"
+	if alert.State == models.AlertStateResolved {
+		action = "resolve"
+	}
+	event := map[string]any{
+		"routing_key":  n.routingKey,
+		"event_action": action,
+		"dedup_key":    alert.ID,
+		"payload": map[string]any{
+			"summary":        Summary(alert),
+			"source":         "mcp-manager",
+			"severity":       alert.Severity,
+			"component":      alert.ServiceName,
+			"class":          alert.Kind,
+			"custom_details": alert,
+		},
+	}
+	return postJSON(ctx, n.client, n.url, event)
+}
+
+// funcNotifier adapts a function to Notifier
+type funcNotifier struct {
+	name   string
+	notify func(ctx context.Context, alert models.Alert) error
+}
+
+// NotifierFunc returns a notifier named name that calls notify
+func NotifierFunc(name string, notify func(ctx context.Context, alert models.Alert) error) Notifier {
+	return &funcNotifier{name: name, notify: notify}
+}
+
+// Name implements Notifier
+func (n *funcNotifier) Name() string {
+	return n.name
+}
+
+// Notify implements Notifier
+func (n *funcNotifier) Notify(ctx context.Context, alert models.Alert) error {
+	return n.notify(ctx, alert)
+}
+
+// Deliver sends an alert through a notifier, retrying with linear backoff, and logs when it
+// could not be delivered
+func Deliver(ctx context.Context, notifier Notifier, alert models.Alert, logger *slog.Logger) {
+	var err error
+retry:
+	for attempt := 1; attempt <= deliveryAttempts; attempt++ {
+		if err = notifier.Notify(ctx, alert); err == nil {
+			logger.DebugContext(ctx, "Delivered alert notification",
+				slog.String("notifier", notifier.Name()),
+				slog.String("alert", alert.ID),
+				slog.String("state", alert.State))
+			return
+		}
+		if attempt < deliveryAttempts {
+			select {
+			case <-ctx.Done():
+				break retry
+			case <-time.After(time.Duration(attempt) * time.Second):
+			}
+		}
+	}
+	logger.WarnContext(ctx, "Failed to deliver alert notification",
+		slog.String("notifier", notifier.Name()),
+		slog.String("alert", alert.ID),
+		slog.String("state", alert.State),
+		slog.String("error", err.Error()))
+}
+
+// postJSON POSTs body as JSON and fails on any status but 2xx
+func postJSON(ctx context.Context, client *http.Client, url string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("notification rejected with status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
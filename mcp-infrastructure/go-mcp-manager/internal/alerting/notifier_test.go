@@ -0,0 +1,69 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestNotifiers(t *testing.T) {
+	bodies := make(chan map[string]any, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies <- body
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	if notifiers := New(config.AlertingConfig{}); len(notifiers) != 0 {
+		t.Errorf("Expected no notifiers without configuration, got %d", len(notifiers))
+	}
+	notifiers := New(config.AlertingConfig{
+		SlackWebhookURL:     server.URL,
+		PagerDutyRoutingKey: "routing-key",
+		PagerDutyURL:        server.URL,
+		Timeout:             2 * time.Second,
+	})
+	if len(notifiers) != 2 || notifiers[0].Name() != NotifierSlack || notifiers[1].Name() != NotifierPagerDuty {
+		t.Fatalf("Expected slack and pagerduty notifiers, got %v", notifiers)
+	}
+
+	alert := models.Alert{
+		ID:       "unhealthy/github",
+		RuleID:   "unhealthy",
+		Severity: models.AlertSeverityCritical,
+		State:    models.AlertStateResolved,
+		Summary:  "instance github is unhealthy",
+	}
+	if err := notifiers[0].Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Expected slack delivery, got %v", err)
+	}
+	if text := (<-bodies)["text"]; text != "[RESOLVED] unhealthy: instance github is unhealthy" {
+		t.Errorf("Expected the summary as slack text, got %v", text)
+	}
+
+	if err := notifiers[1].Notify(context.Background(), alert); err != nil {
+		t.Fatalf("Expected pagerduty delivery, got %v", err)
+	}
+	event := <-bodies
+	if event["event_action"] != "resolve" || event["dedup_key"] != "unhealthy/github" || event["routing_key"] != "routing-key" {
+		t.Errorf("Expected a resolve event keyed by the alert ID, got %v", event)
+	}
+
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid routing key", http.StatusBadRequest)
+	}))
+	defer rejecting.Close()
+	notifier := &PagerDutyNotifier{url: rejecting.URL, client: http.DefaultClient}
+	if err := notifier.Notify(context.Background(), alert); err == nil || !strings.Contains(err.Error(), "invalid routing key") {
+		t.Errorf("Expected the rejection to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// getSchedulingDecisions lists recent scheduling decisions, explaining how each new container
+// fitted the host and whether it was admitted
+func (h *Handler) getSchedulingDecisions(c *gin.Context) {
+	limit, ok := queryLimit(c, "decisions")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, h.containerManager.SchedulingDecisions(c.Query("service"), limit))
+}
+
+// getPreemptions lists recent preemptions: which instances were stopped, for which create and why
+func (h *Handler) getPreemptions(c *gin.Context) {
+	limit, ok := queryLimit(c, "preemptions")
+	if !ok {
+		return
+	}
+	response, err := h.containerManager.Preemptions(c.Query("service"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "preemptions_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// queryLimit reads the optional limit query parameter, answering 400 when it is not a positive
+// number; zero means no limit
+func queryLimit(c *gin.Context, noun string) (int, bool) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return 0, true
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_limit",
+			Code:    http.StatusBadRequest,
+			Message: "limit must be a positive number of " + noun,
+		})
+		return 0, false
+	}
+	return limit, true
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// adoptContainer brings a running container started outside the manager under management
+func (h *Handler) adoptContainer(c *gin.Context) {
+	var req models.AdoptContainerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	adopted, err := h.containerManager.AdoptContainer(c.Request.Context(), req)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusCreated, adopted)
+	case errors.Is(err, container.ErrAlreadyManaged):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "already_managed",
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+	case errors.Is(err, container.ErrNotOwned):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "not_owned",
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+	case errors.Is(err, container.ErrNotAdoptable):
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   "not_adoptable",
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "container_adopt_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+	}
+}
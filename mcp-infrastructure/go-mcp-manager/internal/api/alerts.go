@@ -0,0 +1,154 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// listAlerts returns the pending and firing alerts
+func (h *Handler) listAlerts(c *gin.Context) {
+	c.JSON(http.StatusOK, h.containerManager.Alerts())
+}
+
+// listAlertRules returns the alert rules from configuration and the API
+func (h *Handler) listAlertRules(c *gin.Context) {
+	rules, err := h.containerManager.ListAlertRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "alert_rules_unavailable",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// setAlertRule creates or replaces the alert rule in the path
+func (h *Handler) setAlertRule(c *gin.Context) {
+	var rule models.AlertRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+	rule.ID = c.Param("id")
+
+	saved, err := h.containerManager.SetAlertRule(c.Request.Context(), rule)
+	if err != nil {
+		respondAlertRuleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, saved)
+}
+
+// deleteAlertRule removes the alert rule in the path
+func (h *Handler) deleteAlertRule(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.containerManager.DeleteAlertRule(c.Request.Context(), id); err != nil {
+		respondAlertRuleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Alert rule deleted successfully",
+		"id":      id,
+	})
+}
+
+// respondAlertRuleError answers a refused change of an alert rule
+func respondAlertRuleError(c *gin.Context, err error) {
+	status, code := http.StatusInternalServerError, "alert_rule_update_failed"
+	switch {
+	case errors.Is(err, container.ErrInvalidAlertRule):
+		status, code = http.StatusBadRequest, "invalid_alert_rule"
+	case errors.Is(err, container.ErrAlertRuleNotFound):
+		status, code = http.StatusNotFound, "alert_rule_not_found"
+	case errors.Is(err, container.ErrAlertRuleReadOnly):
+		status, code = http.StatusConflict, "alert_rule_read_only"
+	}
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Code:    status,
+		Message: err.Error(),
+	})
+}
+
+// listAlertSilences returns the silences that have not ended
+func (h *Handler) listAlertSilences(c *gin.Context) {
+	silences, err := h.containerManager.ListAlertSilences()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "silences_unavailable",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, silences)
+}
+
+// createAlertSilence mutes the notifications of a rule, an instance or both for a while
+func (h *Handler) createAlertSilence(c *gin.Context) {
+	var silence models.AlertSilence
+	if err := c.ShouldBindJSON(&silence); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	created, err := h.containerManager.CreateAlertSilence(c.Request.Context(), silence)
+	if errors.Is(err, container.ErrInvalidSilence) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_silence",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "silence_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// deleteAlertSilence ends the silence in the path
+func (h *Handler) deleteAlertSilence(c *gin.Context) {
+	id := c.Param("id")
+	err := h.containerManager.DeleteAlertSilence(c.Request.Context(), id)
+	if errors.Is(err, container.ErrSilenceNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "silence_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "silence_delete_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Silence deleted successfully",
+		"id":      id,
+	})
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// archiveContainer moves a stopped container to cold storage, keeping its slug reserved
+func (h *Handler) archiveContainer(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	archived, err := h.containerManager.ArchiveContainer(c.Request.Context(), serviceName)
+	if err != nil {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "container_archive_failed",
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, archived)
+}
+
+// unarchiveContainer restores an archived container and starts it under its original slug
+func (h *Handler) unarchiveContainer(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	if !h.containerManager.IsArchived(serviceName) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "archived_container_not_found",
+			Code:    http.StatusNotFound,
+			Message: "no archived container named " + serviceName,
+		})
+		return
+	}
+
+	container, err := h.containerManager.UnarchiveContainer(c.Request.Context(), serviceName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "container_unarchive_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, container)
+}
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/authz"
+	"github.com/agentarea/mcp-manager/internal/backends"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// principalKey is the gin context key the authenticated caller is stored under
+const principalKey = "principal"
+
+// maxWorkspacePeekBytes bounds how much of a create request is read to find its workspace
+const maxWorkspacePeekBytes = 4 << 20
+
+// publicRoutes are served without credentials: probes, API docs, and the proxy's callbacks, which
+// carry their own credentials
+var publicRoutes = map[string]bool{
+	"/health":                             true,
+	"/livez":                              true,
+	"/readyz":                             true,
+	"/":                                   true,
+	"/openapi.yaml":                       true,
+	"/openapi.json":                       true,
+	"/docs":                               true,
+	"/docs/*filepath":                     true,
+	"/proxy/auth/:slug":                   true,
+	"/proxy/unavailable/:slug":            true,
+	"/proxy/limits/:slug":                 true,
+	"/proxy/limit-exceeded/:slug/:status": true,
+	"/proxy/session/:slug":                true,
+}
+
+// createRoutes name the workspace of the instance they create in the request body
+var createRoutes = map[string]bool{
+	"POST /instances":           true,
+	"POST /instances/validate":  true,
+	"POST /containers":          true,
+	"POST /containers/validate": true,
+}
+
+// listRoutes filter their results to the caller's workspaces
+var listRoutes = map[string]bool{
+	"GET /instances":    true,
+	"GET /containers":   true,
+	"GET /slugs":        true,
+	"GET /authz/whoami": true,
+}
+
+// SetAuthenticator requires API callers to authenticate and enforces their roles
+func (h *Handler) SetAuthenticator(authenticator *authz.Authenticator) {
+	h.authenticator = authenticator
+}
+
+// authorize authenticates the caller, checks its role grants the route's permission, and keeps
+// workspace-scoped callers to instances of their workspaces
+func (h *Handler) authorize(c *gin.Context) {
+	route := c.FullPath()
+	if publicRoutes[route] {
+		c.Next()
+		return
+	}
+
+	principal, err := h.authenticate(c)
+	if err != nil {
+		c.Header("WWW-Authenticate", `Bearer realm="mcp-manager"`)
+		message := "an API key or bearer token is required"
+		if errors.Is(err, authz.ErrInvalidCredentials) {
+			message = "the API key or bearer token is not valid"
+		}
+		c.AbortWithStatusJSON(http.StatusUnauthorized, models.ErrorResponse{
+			Error:   "unauthenticated",
+			Code:    http.StatusUnauthorized,
+			Message: message,
+		})
+		return
+	}
+	c.Set(principalKey, principal)
+
+	permission := requiredPermission(c.Request.Method, route)
+	if !principal.Role.Allows(permission) {
+		c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "forbidden",
+			Code:    http.StatusForbidden,
+			Message: fmt.Sprintf("role %s lacks the %s permission", principal.Role, permission),
+		})
+		return
+	}
+
+	if principal.Scoped() {
+		if err := h.checkWorkspace(c, principal, route); err != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, models.ErrorResponse{
+				Error:   "workspace_forbidden",
+				Code:    http.StatusForbidden,
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+	c.Next()
+}
+
+// authenticate returns the caller of a request, which the rate limiter may have authenticated already
+func (h *Handler) authenticate(c *gin.Context) (*authz.Principal, error) {
+	if value, exists := c.Get(principalKey); exists {
+		return value.(*authz.Principal), nil
+	}
+	return h.authenticator.Authenticate(c.Request)
+}
+
+// requiredPermission returns the permission a route needs. Host-wide operations need admin, reads
+// need read, and everything else changes instances.
+func requiredPermission(method, route string) authz.Permission {
+	switch {
+	case strings.HasPrefix(route, "/admin/"), strings.HasPrefix(route, "/debug/"),
+		strings.HasPrefix(route, "/webhooks"), route == "/containers/adopt":
+		return authz.PermissionAdmin
+	case route == "/containers/:service/exec", route == "/containers/:service/port-forward":
+		// Commands and tunnels reach the server around its route and whatever auth it has
+		return authz.PermissionAdmin
+	case strings.HasPrefix(route, "/budgets") && method != http.MethodGet:
+		// Budgets are set by the platform, not by the workspaces they limit
+		return authz.PermissionAdmin
+	case strings.HasPrefix(route, "/alerts") && method != http.MethodGet:
+		// Alert rules and silences cover the whole host
+		return authz.PermissionAdmin
+	case route == "/containers/:service/files":
+		// Config files hold credentials, so reading them takes as much as writing them
+		return authz.PermissionWrite
+	case method == http.MethodGet, method == http.MethodHead, strings.HasSuffix(route, "/validate"):
+		return authz.PermissionRead
+	default:
+		return authz.PermissionWrite
+	}
+}
+
+// checkWorkspace admits a scoped principal to routes on a single instance of its workspaces,
+// to creating instances in them, and to lists, which handlers filter
+func (h *Handler) checkWorkspace(c *gin.Context, principal *authz.Principal, route string) error {
+	key := c.Request.Method + " " + route
+	switch {
+	case listRoutes[key]:
+		return nil
+	case createRoutes[key]:
+		workspaceID, err := peekWorkspace(c)
+		if err != nil {
+			return err
+		}
+		if !principal.CanAccessWorkspace(workspaceID) {
+			return fmt.Errorf("instances cannot be created in workspace %q", workspaceID)
+		}
+		return nil
+	case strings.Contains(route, ":service"), strings.Contains(route, ":instance_id"):
+		workspaceID, found := h.resourceWorkspace(c)
+		if !found || !principal.CanAccessWorkspace(workspaceID) {
+			return errors.New("the instance does not belong to any of your workspaces")
+		}
+		return nil
+	case strings.Contains(route, ":workspace_id"):
+		if !principal.CanAccessWorkspace(c.Param("workspace_id")) {
+			return fmt.Errorf("workspace %q is not one of your workspaces", c.Param("workspace_id"))
+		}
+		return nil
+	default:
+		return errors.New("this route spans every workspace and needs an unscoped role")
+	}
+}
+
+// resourceWorkspace returns the workspace of the instance a route addresses. Workspaces are only
+// known for containers the podman backend manages.
+func (h *Handler) resourceWorkspace(c *gin.Context) (string, bool) {
+	if h.containerManager == nil {
+		return "", false
+	}
+	serviceName := c.Param("service")
+	if instanceID := c.Param("instance_id"); instanceID != "" {
+		var found bool
+		if serviceName, found = h.containerManager.ServiceNameForInstance(instanceID); !found {
+			return "", false
+		}
+	}
+	return h.containerManager.WorkspaceOf(serviceName)
+}
+
+// peekWorkspace reads workspace_id from a JSON request body, leaving the body for the handler
+func peekWorkspace(c *gin.Context) (string, error) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWorkspacePeekBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read request body: %w", err)
+	}
+	if len(body) > maxWorkspacePeekBytes {
+		return "", fmt.Errorf("request body exceeds %d bytes", maxWorkspacePeekBytes)
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var request struct {
+		WorkspaceID string `json:"workspace_id"`
+	}
+	// A malformed body is left for the handler to reject
+	_ = json.Unmarshal(body, &request)
+	return request.WorkspaceID, nil
+}
+
+// requireAuthorization refuses a route that must not be served to anonymous callers when the API
+// has no authorization configured, and reports whether it may go on
+func (h *Handler) requireAuthorization(c *gin.Context) bool {
+	if h.authenticator != nil {
+		return true
+	}
+	c.JSON(http.StatusForbidden, models.ErrorResponse{
+		Error:   "authorization_required",
+		Code:    http.StatusForbidden,
+		Message: "this endpoint is only served when API authorization is configured",
+	})
+	return false
+}
+
+// requestPrincipal returns the caller of a request; without authorization everyone is an unscoped admin
+func requestPrincipal(c *gin.Context) *authz.Principal {
+	if value, exists := c.Get(principalKey); exists {
+		return value.(*authz.Principal)
+	}
+	return authz.Anonymous
+}
+
+// visibleWorkspace reports whether the caller may see instances of workspaceID in lists
+func visibleWorkspace(c *gin.Context, workspaceID string) bool {
+	return requestPrincipal(c).CanAccessWorkspace(workspaceID)
+}
+
+// instanceWorkspace returns the workspace of a listed instance, empty when it is unknown
+func (h *Handler) instanceWorkspace(instance *backends.InstanceStatus) string {
+	if h.containerManager == nil {
+		return ""
+	}
+	workspaceID, _ := h.containerManager.WorkspaceOf(instance.ServiceName)
+	return workspaceID
+}
+
+// whoami describes the caller and what it may do
+func (h *Handler) whoami(c *gin.Context) {
+	caller := requestPrincipal(c)
+	permissions := make([]string, 0, 3)
+	for _, permission := range caller.Role.Permissions() {
+		permissions = append(permissions, string(permission))
+	}
+	c.JSON(http.StatusOK, models.Whoami{
+		Subject:     caller.Subject,
+		Source:      caller.Source,
+		Role:        string(caller.Role),
+		Permissions: permissions,
+		Workspaces:  caller.Workspaces,
+		Enforced:    h.authenticator != nil,
+	})
+}
@@ -0,0 +1,366 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/authz"
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/internal/state"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// routeScope is how a route is kept to the workspaces of a scoped caller
+type routeScope int
+
+const (
+	// scopeHost routes span every workspace and need an unscoped role
+	scopeHost routeScope = iota
+	// scopeList routes filter what they return to the caller's workspaces
+	scopeList
+	// scopeCreate routes create an instance in the workspace named by the request body
+	scopeCreate
+	// scopeService routes address an instance by service name
+	scopeService
+	// scopeInstance routes address an instance by instance ID
+	scopeInstance
+	// scopeWorkspace routes address a workspace
+	scopeWorkspace
+)
+
+// routeAccess is what a route requires of its caller. Public routes require no permission.
+type routeAccess struct {
+	permission authz.Permission
+	scope      routeScope
+}
+
+var (
+	publicAccess = routeAccess{}
+	readHost     = routeAccess{authz.PermissionRead, scopeHost}
+	adminHost    = routeAccess{authz.PermissionAdmin, scopeHost}
+)
+
+// routeAccessTable lists every route SetupRoutes registers, keyed by method and path; "*" stands
+// for every method of a route registered with Any
+var routeAccessTable = map[string]routeAccess{
+	"GET /":                        publicAccess,
+	"GET /health":                  publicAccess,
+	"GET /livez":                   publicAccess,
+	"GET /readyz":                  publicAccess,
+	"GET /openapi.yaml":            publicAccess,
+	"GET /openapi.json":            publicAccess,
+	"GET /docs":                    publicAccess,
+	"GET /docs/*filepath":          publicAccess,
+	"* /proxy/auth/:slug":          publicAccess,
+	"* /proxy/limits/:slug":        publicAccess,
+	"* /proxy/session/:slug":       publicAccess,
+	"GET /proxy/unavailable/:slug": publicAccess,
+	"GET /proxy/limit-exceeded/:slug/:status": publicAccess,
+
+	"GET /authz/whoami": {authz.PermissionRead, scopeList},
+
+	"GET /instances":                                  {authz.PermissionRead, scopeList},
+	"POST /instances":                                 {authz.PermissionWrite, scopeCreate},
+	"POST /instances/validate":                        {authz.PermissionRead, scopeCreate},
+	"POST /instances/from-server-json":                {authz.PermissionWrite, scopeHost},
+	"GET /instances/health":                           readHost,
+	"GET /instances/:instance_id":                     {authz.PermissionRead, scopeInstance},
+	"PUT /instances/:instance_id":                     {authz.PermissionWrite, scopeInstance},
+	"DELETE /instances/:instance_id":                  {authz.PermissionWrite, scopeInstance},
+	"GET /instances/:instance_id/health":              {authz.PermissionRead, scopeInstance},
+	"POST /instances/:instance_id/health":             {authz.PermissionWrite, scopeInstance},
+	"GET /instances/:instance_id/health/detailed":     {authz.PermissionRead, scopeInstance},
+	"GET /instances/:instance_id/inspect":             {authz.PermissionRead, scopeInstance},
+	"GET /instances/:instance_id/logs":                {authz.PermissionRead, scopeInstance},
+	"GET /instances/:instance_id/connection":          {authz.PermissionRead, scopeInstance},
+	"GET /instances/:instance_id/events":              {authz.PermissionRead, scopeInstance},
+	"GET /instances/:instance_id/revisions":           {authz.PermissionRead, scopeInstance},
+	"POST /instances/:instance_id/rollback/:revision": {authz.PermissionWrite, scopeInstance},
+	"GET /instances/:instance_id/diff":                {authz.PermissionRead, scopeInstance},
+	"POST /instances/:instance_id/reconcile":          {authz.PermissionWrite, scopeInstance},
+
+	"GET /containers":                          {authz.PermissionRead, scopeList},
+	"POST /containers":                         {authz.PermissionWrite, scopeCreate},
+	"POST /containers/validate":                {authz.PermissionRead, scopeCreate},
+	"POST /containers/adopt":                   adminHost,
+	"GET /containers/health":                   readHost,
+	"GET /containers/:service":                 {authz.PermissionRead, scopeService},
+	"DELETE /containers/:service":              {authz.PermissionWrite, scopeService},
+	"GET /containers/:service/health":          {authz.PermissionRead, scopeService},
+	"POST /containers/:service/health":         {authz.PermissionWrite, scopeService},
+	"GET /containers/:service/health/detailed": {authz.PermissionRead, scopeService},
+	"GET /containers/:service/health/history":  {authz.PermissionRead, scopeService},
+	"GET /containers/:service/slo":             {authz.PermissionRead, scopeService},
+	"GET /containers/:service/sessions":        {authz.PermissionRead, scopeService},
+	"GET /containers/:service/spec":            {authz.PermissionRead, scopeService},
+	"GET /containers/:service/checkpoint":      {authz.PermissionRead, scopeService},
+	"GET /containers/:service/connection":      {authz.PermissionRead, scopeService},
+	"GET /containers/:service/manifests":       {authz.PermissionRead, scopeService},
+	"GET /containers/:service/env-schema":      {authz.PermissionRead, scopeService},
+	"GET /containers/:service/replicas":        {authz.PermissionRead, scopeService},
+	"GET /containers/:service/inspect":         {authz.PermissionRead, scopeService},
+	"GET /containers/:service/traffic":         {authz.PermissionRead, scopeService},
+	"GET /containers/:service/files":           {authz.PermissionWrite, scopeService},
+	"PUT /containers/:service/files":           {authz.PermissionWrite, scopeService},
+	"GET /containers/:service/port-forward":    {authz.PermissionAdmin, scopeService},
+	"POST /containers/:service/exec":           {authz.PermissionAdmin, scopeService},
+	"POST /containers/:service/start":          {authz.PermissionWrite, scopeService},
+	"POST /containers/:service/stop":           {authz.PermissionWrite, scopeService},
+	"POST /containers/:service/checkpoint":     {authz.PermissionWrite, scopeService},
+	"POST /containers/:service/restore":        {authz.PermissionWrite, scopeService},
+	"POST /containers/:service/clone":          {authz.PermissionWrite, scopeService},
+	"POST /containers/:service/stage":          {authz.PermissionWrite, scopeService},
+	"POST /containers/:service/promote":        {authz.PermissionWrite, scopeService},
+	"POST /containers/:service/rollback":       {authz.PermissionWrite, scopeService},
+	"POST /containers/:service/archive":        {authz.PermissionWrite, scopeService},
+	"POST /containers/:service/unarchive":      {authz.PermissionWrite, scopeService},
+	"POST /containers/:service/route/refresh":  {authz.PermissionWrite, scopeService},
+	"PUT /containers/:service/slug":            {authz.PermissionWrite, scopeService},
+	"PATCH /containers/:service/environment":   {authz.PermissionWrite, scopeService},
+
+	"GET /monitoring/status":         readHost,
+	"GET /monitoring/health-summary": readHost,
+	"GET /slugs":                     {authz.PermissionRead, scopeList},
+	"GET /images/*ref":               readHost,
+	"GET /storage/usage":             readHost,
+	"GET /traffic/usage":             readHost,
+	"GET /scheduler/decisions":       readHost,
+	"GET /scheduler/preemptions":     readHost,
+	"GET /jobs":                      readHost,
+	"GET /jobs/:id":                  readHost,
+	"GET /jobs/:id/logs":             readHost,
+
+	"GET /budgets":                                 readHost,
+	"GET /budgets/global":                          readHost,
+	"PUT /budgets/global":                          adminHost,
+	"DELETE /budgets/global":                       adminHost,
+	"POST /budgets/global/reset":                   adminHost,
+	"GET /budgets/workspaces/:workspace_id":        {authz.PermissionRead, scopeWorkspace},
+	"PUT /budgets/workspaces/:workspace_id":        {authz.PermissionAdmin, scopeWorkspace},
+	"DELETE /budgets/workspaces/:workspace_id":     {authz.PermissionAdmin, scopeWorkspace},
+	"POST /budgets/workspaces/:workspace_id/reset": {authz.PermissionAdmin, scopeWorkspace},
+
+	"GET /alerts":                 readHost,
+	"GET /alerts/rules":           readHost,
+	"PUT /alerts/rules/:id":       adminHost,
+	"DELETE /alerts/rules/:id":    adminHost,
+	"GET /alerts/silences":        readHost,
+	"POST /alerts/silences":       adminHost,
+	"DELETE /alerts/silences/:id": adminHost,
+
+	"GET /webhooks":        adminHost,
+	"POST /webhooks":       adminHost,
+	"DELETE /webhooks/:id": adminHost,
+
+	"GET /admin/gpus":           adminHost,
+	"GET /admin/gc":             adminHost,
+	"POST /admin/gc":            adminHost,
+	"GET /admin/export/compose": adminHost,
+	"GET /admin/exec-audit":     adminHost,
+	"GET /admin/preemption":     adminHost,
+	"POST /admin/preemption":    adminHost,
+	"GET /admin/doctor":         adminHost,
+	"GET /admin/cordon":         adminHost,
+	"POST /admin/cordon":        adminHost,
+	"POST /admin/uncordon":      adminHost,
+	"POST /admin/drain":         adminHost,
+	"GET /admin/registry-cache": adminHost,
+	"GET /admin/backup":         adminHost,
+	"POST /admin/restore":       adminHost,
+	"GET /admin/support-bundle": adminHost,
+}
+
+// servedOnlyWithAuthorization are refused to every caller while the API has no authenticator
+var servedOnlyWithAuthorization = map[string]bool{
+	"POST /containers/:service/exec":        true,
+	"GET /containers/:service/port-forward": true,
+}
+
+// lookupRouteAccess returns the table entry of a registered route and its key
+func lookupRouteAccess(method, path string) (string, routeAccess, bool) {
+	for _, key := range []string{method + " " + path, "* " + path} {
+		if access, known := routeAccessTable[key]; known {
+			return key, access, true
+		}
+	}
+	return "", routeAccess{}, false
+}
+
+// authzTestHandler returns a handler whose manager knows an archived service in each of
+// workspaces ws-a and ws-b
+func authzTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	dir := t.TempDir()
+	store := state.NewStore(dir)
+	for service, workspace := range map[string]string{"svc-a": "ws-a", "svc-b": "ws-b"} {
+		record := models.ArchivedContainer{Container: models.Container{ServiceName: service, WorkspaceID: workspace}}
+		if err := store.Put("archived", service, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	manager := container.NewManager(&config.Config{State: config.StateConfig{Dir: dir}}, testLogger())
+	return NewHandler(nil, manager, testLogger(), "test")
+}
+
+// registeredRoutes returns the routes SetupRoutes registers on h
+func registeredRoutes(h *Handler) gin.RoutesInfo {
+	router := gin.New()
+	h.SetupRoutes(router)
+	return router.Routes()
+}
+
+// routeRequest fills a route's parameters with those of workspace ws-a, or ws-b when other is set
+func routeRequest(method, path string, other bool) *http.Request {
+	service, workspace := "svc-a", "ws-a"
+	if other {
+		service, workspace = "svc-b", "ws-b"
+	}
+	target := strings.NewReplacer(
+		":service", service,
+		":instance_id", service,
+		":workspace_id", workspace,
+		":slug", "github",
+		":status", "429",
+		":revision", "1",
+		":id", "1",
+		"*ref", "docker.io/library/busybox",
+		"*filepath", "index.html",
+	).Replace(path)
+	body := `{"workspace_id":"` + workspace + `"}`
+	return httptest.NewRequest(method, target, strings.NewReader(body))
+}
+
+func TestRouteAccessTableCoversEveryRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	routes := registeredRoutes(authzTestHandler(t))
+
+	registered := make(map[string]bool)
+	for _, route := range routes {
+		key, _, known := lookupRouteAccess(route.Method, route.Path)
+		if !known {
+			t.Errorf("Route %s %s is not in the access table", route.Method, route.Path)
+			continue
+		}
+		registered[key] = true
+	}
+	for key := range routeAccessTable {
+		if !registered[key] {
+			t.Errorf("Access table entry %s is not a registered route", key)
+		}
+	}
+}
+
+func TestAuthorizeRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := authzTestHandler(t)
+	routes := registeredRoutes(handler)
+
+	handler.SetAuthenticator(testKeysAuthenticator(t, []map[string]any{
+		testKeyEntry("admin-secret", authz.RoleAdmin),
+		testKeyEntry("operator-secret", authz.RoleOperator),
+		testKeyEntry("viewer-secret", authz.RoleViewer),
+		testKeyEntry("scoped-admin-secret", authz.RoleAdmin, "ws-a"),
+		testKeyEntry("scoped-operator-secret", authz.RoleOperator, "ws-a"),
+		testKeyEntry("scoped-viewer-secret", authz.RoleViewer, "ws-a"),
+	}))
+	// Every route answers 200 once authorize lets it through, so only the middleware is tested
+	router := gin.New()
+	router.Use(handler.authorize)
+	for _, route := range routes {
+		router.Handle(route.Method, route.Path, func(c *gin.Context) { c.Status(http.StatusOK) })
+	}
+
+	callers := []struct {
+		key    string
+		role   authz.Role
+		scoped bool
+	}{
+		{"", "", false},
+		{"admin-secret", authz.RoleAdmin, false},
+		{"operator-secret", authz.RoleOperator, false},
+		{"viewer-secret", authz.RoleViewer, false},
+		{"scoped-admin-secret", authz.RoleAdmin, true},
+		{"scoped-operator-secret", authz.RoleOperator, true},
+		{"scoped-viewer-secret", authz.RoleViewer, true},
+	}
+
+	for _, route := range routes {
+		_, access, known := lookupRouteAccess(route.Method, route.Path)
+		if !known {
+			continue
+		}
+		for _, caller := range callers {
+			for _, other := range []bool{false, true} {
+				want := http.StatusOK
+				switch {
+				case access == publicAccess:
+				case caller.key == "":
+					want = http.StatusUnauthorized
+				case !caller.role.Allows(access.permission):
+					want = http.StatusForbidden
+				case !caller.scoped, access.scope == scopeList:
+				case access.scope == scopeHost:
+					want = http.StatusForbidden
+				case access.scope == scopeInstance:
+					// Instance IDs only resolve to running containers, and this manager has none
+					want = http.StatusForbidden
+				case other:
+					want = http.StatusForbidden
+				}
+
+				req := routeRequest(route.Method, route.Path, other)
+				if caller.key != "" {
+					req.Header.Set("X-API-Key", caller.key)
+				}
+				recorder := httptest.NewRecorder()
+				router.ServeHTTP(recorder, req)
+				if recorder.Code != want {
+					t.Errorf("%s %s (other workspace %t) as %s: expected %d, got %d %s",
+						route.Method, route.Path, other, caller.key, want, recorder.Code, recorder.Body.String())
+				}
+			}
+		}
+	}
+}
+
+func TestRoutesWithoutAuthenticator(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := authzTestHandler(t)
+	router := gin.New()
+	handler.SetupRoutes(router)
+
+	for _, route := range router.Routes() {
+		key, access, known := lookupRouteAccess(route.Method, route.Path)
+		if !known || access == publicAccess {
+			continue
+		}
+		if servedOnlyWithAuthorization[key] {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, routeRequest(route.Method, route.Path, false))
+			if recorder.Code != http.StatusForbidden || !strings.Contains(recorder.Body.String(), "authorization_required") {
+				t.Errorf("Expected %s to be refused without an authenticator, got %d %s", key, recorder.Code, recorder.Body.String())
+			}
+			continue
+		}
+		// Every other route is served to the anonymous caller, which holds every permission
+		if !authz.Anonymous.Role.Allows(access.permission) || authz.Anonymous.Scoped() {
+			t.Errorf("Expected the anonymous caller to be allowed %s", key)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/authz/whoami", nil))
+	var whoami models.Whoami
+	if err := json.Unmarshal(recorder.Body.Bytes(), &whoami); err != nil || recorder.Code != http.StatusOK {
+		t.Fatalf("Expected whoami to answer the anonymous caller, got %d %s", recorder.Code, recorder.Body.String())
+	}
+	if whoami.Enforced || whoami.Role != string(authz.RoleAdmin) {
+		t.Errorf("Expected an unenforced admin caller, got %+v", whoami)
+	}
+}
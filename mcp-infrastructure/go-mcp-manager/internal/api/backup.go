@@ -0,0 +1,58 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// getBackup returns a snapshot of the host's desired state for POST /admin/restore
+func (h *Handler) getBackup(c *gin.Context) {
+	backup, err := h.containerManager.Backup(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "backup_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, backup)
+}
+
+// restoreBackup reconciles the host to a backup taken with GET /admin/backup
+func (h *Handler) restoreBackup(c *gin.Context) {
+	var backup models.Backup
+	if err := c.ShouldBindJSON(&backup); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.containerManager.Restore(c.Request.Context(), &backup)
+	if errors.Is(err, container.ErrBackupVersion) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "unsupported_backup",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "restore_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
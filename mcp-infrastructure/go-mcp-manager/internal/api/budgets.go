@@ -0,0 +1,118 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// listBudgets returns the global budget and every workspace with a budget or counted usage
+func (h *Handler) listBudgets(c *gin.Context) {
+	budgets, err := h.containerManager.ListBudgets()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "budgets_unavailable",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, budgets)
+}
+
+// getBudget returns the usage and budget of the workspace in the path, or the global ones
+func (h *Handler) getBudget(c *gin.Context) {
+	status, err := h.containerManager.GetBudget(c.Param("workspace_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "budgets_unavailable",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// setBudget sets the budget of the workspace in the path, or the global one
+func (h *Handler) setBudget(c *gin.Context) {
+	var budget models.Budget
+	if err := c.ShouldBindJSON(&budget); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+	budget.WorkspaceID = c.Param("workspace_id")
+
+	status, err := h.containerManager.SetBudget(c.Request.Context(), budget)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_budget",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// deleteBudget removes the budget of the workspace in the path, or the global one
+func (h *Handler) deleteBudget(c *gin.Context) {
+	workspaceID := c.Param("workspace_id")
+	err := h.containerManager.DeleteBudget(c.Request.Context(), workspaceID)
+	if errors.Is(err, container.ErrNoBudget) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "budget_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "budget_delete_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Budget deleted successfully",
+		"workspace_id": workspaceID,
+	})
+}
+
+// resetBudgetUsage counts the usage of the workspace in the path, or the global usage, from zero
+func (h *Handler) resetBudgetUsage(c *gin.Context) {
+	status, err := h.containerManager.ResetBudgetUsage(c.Request.Context(), c.Param("workspace_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "budget_reset_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// respondBudgetExhausted answers a create or start refused because a budget is used up, and
+// reports whether it did
+func respondBudgetExhausted(c *gin.Context, err error) bool {
+	if !errors.Is(err, container.ErrBudgetExhausted) {
+		return false
+	}
+	c.JSON(http.StatusPaymentRequired, models.ErrorResponse{
+		Error:   "budget_exhausted",
+		Code:    http.StatusPaymentRequired,
+		Message: err.Error(),
+	})
+	return true
+}
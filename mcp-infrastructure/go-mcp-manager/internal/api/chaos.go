@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/chaos"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// getChaos returns the faults currently injected
+func (h *Handler) getChaos(c *gin.Context) {
+	c.JSON(http.StatusOK, h.chaos.Faults())
+}
+
+// setChaos replaces the injected faults
+func (h *Handler) setChaos(c *gin.Context) {
+	var faults chaos.Faults
+	if err := c.ShouldBindJSON(&faults); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.chaos.SetFaults(faults); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, h.chaos.Faults())
+}
+
+// resetChaos stops injecting faults
+func (h *Handler) resetChaos(c *gin.Context) {
+	h.chaos.SetFaults(chaos.Faults{})
+	c.JSON(http.StatusOK, h.chaos.Faults())
+}
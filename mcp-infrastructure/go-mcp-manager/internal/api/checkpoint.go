@@ -0,0 +1,116 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// checkpointContainer saves a running container's processes to an archive with CRIU
+func (h *Handler) checkpointContainer(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	if _, err := h.containerManager.GetContainer(serviceName); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var req models.CheckpointRequest
+	// The body is optional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	checkpoint, err := h.containerManager.CheckpointContainer(c.Request.Context(), serviceName, req)
+	if err != nil {
+		checkpointError(c, "checkpoint_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, checkpoint)
+}
+
+// getCheckpoint returns a container's latest checkpoint
+func (h *Handler) getCheckpoint(c *gin.Context) {
+	checkpoint, err := h.containerManager.GetCheckpoint(c.Param("service"))
+	if err != nil {
+		checkpointError(c, "checkpoint_failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, checkpoint)
+}
+
+// restoreContainer brings a stopped container back from a checkpoint
+func (h *Handler) restoreContainer(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	if _, err := h.containerManager.GetContainer(serviceName); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var req models.RestoreRequest
+	// The body is optional
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "invalid_request",
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	restored, err := h.containerManager.RestoreContainer(c.Request.Context(), serviceName, req)
+	if err != nil {
+		checkpointError(c, "restore_failed", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restored)
+}
+
+// checkpointError answers a failed checkpoint or restore, with 501 where CRIU cannot be used and
+// 404 when there is no checkpoint
+func checkpointError(c *gin.Context, code string, err error) {
+	switch {
+	case errors.Is(err, container.ErrCheckpointUnsupported):
+		c.JSON(http.StatusNotImplemented, models.ErrorResponse{
+			Error:   "checkpoint_unsupported",
+			Code:    http.StatusNotImplemented,
+			Message: err.Error(),
+		})
+	case errors.Is(err, container.ErrNoCheckpoint):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "checkpoint_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   code,
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+	}
+}
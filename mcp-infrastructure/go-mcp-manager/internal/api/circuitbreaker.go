@@ -0,0 +1,30 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// proxyUnavailable is the error page the proxy fetches when it answers an MCP route with 503,
+// so agents get a structured body and Retry-After instead of a bare status
+func (h *Handler) proxyUnavailable(c *gin.Context) {
+	serviceName, retryAfter, found := h.containerManager.RecordCircuitTrip(c.Param("slug"))
+
+	message := "MCP server is unavailable"
+	if found {
+		message = fmt.Sprintf("MCP server %s is failing, requests are rejected until it recovers", serviceName)
+	}
+
+	seconds := max(int(math.Ceil(retryAfter.Seconds())), 1)
+	c.Header("Retry-After", fmt.Sprintf("%d", seconds))
+	c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+		Error:   "upstream_unavailable",
+		Code:    http.StatusServiceUnavailable,
+		Message: message,
+	})
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// cloneContainer creates a new container from an existing container's effective spec
+func (h *Handler) cloneContainer(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	var req models.CloneContainerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if _, err := h.containerManager.GetContainer(serviceName); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	clone, err := h.containerManager.CloneContainer(c.Request.Context(), serviceName, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "container_clone_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, clone)
+}
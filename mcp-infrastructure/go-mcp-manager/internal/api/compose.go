@@ -0,0 +1,24 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// exportCompose returns a docker-compose/podman-compose file describing all managed instances
+func (h *Handler) exportCompose(c *gin.Context) {
+	data, err := h.containerManager.ExportCompose(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "export_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="compose.yaml"`)
+	c.Data(http.StatusOK, "application/yaml", data)
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// maintenanceRequest is the optional body of the cordon and drain endpoints
+type maintenanceRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// getCordonStatus returns whether this host is cordoned and what is queued or drained
+func (h *Handler) getCordonStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.containerManager.GetCordonStatus())
+}
+
+// cordonHost stops the host from accepting new containers ahead of maintenance
+func (h *Handler) cordonHost(c *gin.Context) {
+	var req maintenanceRequest
+	// The body is optional
+	_ = c.ShouldBindJSON(&req)
+
+	if req.Reason == "" {
+		req.Reason = "cordoned via API"
+	}
+
+	status, err := h.containerManager.Cordon(c.Request.Context(), req.Reason)
+	h.respondMaintenance(c, status, err, "cordon_failed")
+}
+
+// drainHost cordons the host and gracefully stops all running containers
+func (h *Handler) drainHost(c *gin.Context) {
+	var req maintenanceRequest
+	// The body is optional
+	_ = c.ShouldBindJSON(&req)
+
+	if req.Reason == "" {
+		req.Reason = "drained via API"
+	}
+
+	status, err := h.containerManager.Drain(c.Request.Context(), req.Reason)
+	h.respondMaintenance(c, status, err, "drain_failed")
+}
+
+// uncordonHost ends maintenance, restarting drained containers and replaying queued creates
+func (h *Handler) uncordonHost(c *gin.Context) {
+	status, err := h.containerManager.Uncordon(c.Request.Context())
+	h.respondMaintenance(c, status, err, "uncordon_failed")
+}
+
+// respondMaintenance writes the cordon status or the error of a maintenance operation
+func (h *Handler) respondMaintenance(c *gin.Context, status container.CordonStatus, err error, code string) {
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   code,
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
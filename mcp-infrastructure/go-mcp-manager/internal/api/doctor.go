@@ -0,0 +1,12 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// runDoctor runs the environment self-test and returns its report, whether or not it passed
+func (h *Handler) runDoctor(c *gin.Context) {
+	c.JSON(http.StatusOK, h.containerManager.RunDoctor(c.Request.Context()))
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// updateContainerEnvironment sets or removes some of a container's environment variables and
+// restarts it with them under the same slug
+func (h *Handler) updateContainerEnvironment(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	var req models.UpdateEnvironmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if _, err := h.containerManager.GetContainer(serviceName); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.containerManager.UpdateEnvironment(c.Request.Context(), serviceName, req.Environment)
+	if err != nil {
+		if respondEnvSchemaError(c, err) {
+			return
+		}
+		if errors.Is(err, container.ErrInvalidEnvironmentUpdate) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "invalid_environment_update",
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "environment_update_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
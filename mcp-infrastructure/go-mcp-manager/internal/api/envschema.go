@@ -0,0 +1,51 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// getContainerEnvSchema returns the environment variables a container declared it reads, so
+// clients can render a configuration form for it
+func (h *Handler) getContainerEnvSchema(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	current, err := h.containerManager.GetContainer(serviceName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	schema := current.EnvSchema
+	if schema == nil {
+		schema = []models.EnvVarSpec{}
+	}
+	c.JSON(http.StatusOK, models.EnvSchemaResponse{ServiceName: serviceName, EnvSchema: schema})
+}
+
+// respondEnvSchemaError answers 422 with every variable that fails its env_schema when err is a
+// schema mismatch, and reports whether it was
+func respondEnvSchemaError(c *gin.Context, err error) bool {
+	var schemaErr *container.EnvSchemaError
+	if !errors.As(err, &schemaErr) {
+		return false
+	}
+	c.JSON(http.StatusUnprocessableEntity, models.EnvSchemaErrorResponse{
+		ErrorResponse: models.ErrorResponse{
+			Error:   "invalid_environment",
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		},
+		Variables: schemaErr.Problems,
+	})
+	return true
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// execContainer runs an allowlisted diagnostic command in a container, e.g. to check the config
+// file a server wrote. Admin only, so it is refused unless API authorization is configured; every
+// attempt is audited.
+func (h *Handler) execContainer(c *gin.Context) {
+	if !h.requireAuthorization(c) {
+		return
+	}
+	serviceName := c.Param("service")
+
+	if _, err := h.containerManager.GetContainer(serviceName); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var req models.ExecRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	caller := requestPrincipal(c)
+	result, err := h.containerManager.ExecContainer(c.Request.Context(), serviceName, req, caller.Subject, caller.Source)
+	switch {
+	case errors.Is(err, container.ErrExecDisabled):
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "exec_disabled",
+			Code:    http.StatusForbidden,
+			Message: err.Error(),
+		})
+	case errors.Is(err, container.ErrCommandNotAllowed):
+		c.JSON(http.StatusForbidden, models.ErrorResponse{
+			Error:   "command_not_allowed",
+			Code:    http.StatusForbidden,
+			Message: err.Error(),
+		})
+	case errors.Is(err, container.ErrContainerNotRunning):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "container_not_running",
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "exec_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// getExecAudit lists recent exec attempts, newest first
+func (h *Handler) getExecAudit(c *gin.Context) {
+	limit, ok := queryLimit(c, "records")
+	if !ok {
+		return
+	}
+	response, err := h.containerManager.ExecAudit(c.Query("service"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "exec_audit_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/authz"
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/internal/container"
+)
+
+func TestExecNeedsAuthorization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewHandler(nil, container.NewManager(&config.Config{}, testLogger()), testLogger(), "test")
+	router := gin.New()
+	router.POST("/containers/:service/exec", handler.execContainer)
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/containers/github/exec", strings.NewReader(`{"command":["cat","/proc/1/environ"]}`))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	if recorder := send(); recorder.Code != http.StatusForbidden || !strings.Contains(recorder.Body.String(), "authorization_required") {
+		t.Errorf("Expected exec to be refused without API authorization, got %d %s", recorder.Code, recorder.Body.String())
+	}
+
+	handler.SetAuthenticator(testAuthenticator(t, map[string]authz.Role{"admin-secret": authz.RoleAdmin}))
+	if recorder := send(); recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected exec to reach the container lookup with API authorization, got %d", recorder.Code)
+	}
+}
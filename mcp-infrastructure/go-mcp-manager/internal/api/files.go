@@ -0,0 +1,91 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// putContainerFile copies a file, such as a config document, into a container
+func (h *Handler) putContainerFile(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	if _, err := h.containerManager.GetContainer(serviceName); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var req models.ContainerFile
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	file, err := h.containerManager.WriteContainerFile(c.Request.Context(), serviceName, req)
+	if err != nil {
+		containerFileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, file)
+}
+
+// getContainerFile copies a file out of a container
+func (h *Handler) getContainerFile(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	if _, err := h.containerManager.GetContainer(serviceName); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+	if c.Query("path") == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: "the path query parameter is required",
+		})
+		return
+	}
+
+	file, err := h.containerManager.ReadContainerFile(c.Request.Context(), serviceName, c.Query("path"))
+	if err != nil {
+		containerFileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, file)
+}
+
+// containerFileError maps a file copy failure to its status
+func containerFileError(c *gin.Context, err error) {
+	status, code := http.StatusInternalServerError, "file_copy_failed"
+	switch {
+	case errors.Is(err, container.ErrPathNotAllowed):
+		status, code = http.StatusForbidden, "path_not_allowed"
+	case errors.Is(err, container.ErrFileTooLarge):
+		status, code = http.StatusRequestEntityTooLarge, "file_too_large"
+	case errors.Is(err, container.ErrFileNotFound):
+		status, code = http.StatusNotFound, "file_not_found"
+	case errors.Is(err, container.ErrInvalidFile):
+		status, code = http.StatusBadRequest, "invalid_file"
+	}
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Code:    status,
+		Message: err.Error(),
+	})
+}
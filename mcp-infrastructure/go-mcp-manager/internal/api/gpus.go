@@ -0,0 +1,12 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getGPUCapacity reports the host's GPU pool and which containers hold each GPU
+func (h *Handler) getGPUCapacity(c *gin.Context) {
+	c.JSON(http.StatusOK, h.containerManager.GetGPUCapacity())
+}
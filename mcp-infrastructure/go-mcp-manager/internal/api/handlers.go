@@ -1,21 +1,40 @@
 package api
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/agentarea/mcp-manager/internal/authz"
 	"github.com/agentarea/mcp-manager/internal/backends"
+	"github.com/agentarea/mcp-manager/internal/chaos"
 	"github.com/agentarea/mcp-manager/internal/container"
-	"github.com/agentarea/mcp-manager/internal/models"
+	"github.com/agentarea/mcp-manager/internal/logbuffer"
+	"github.com/agentarea/mcp-manager/internal/proxy"
+	"github.com/agentarea/mcp-manager/internal/retry"
+	"github.com/agentarea/mcp-manager/pkg/models"
 )
 
+// instanceHealthCheckWorkers caps the health checks GET /instances/health runs at once
+const instanceHealthCheckWorkers = 8
+
+// idempotencyKeyHeader makes POST /instances and POST /containers safe to retry
+const idempotencyKeyHeader = "Idempotency-Key"
+
 // Handler holds the HTTP handlers and dependencies
 type Handler struct {
 	backend          backends.Backend
 	containerManager *container.Manager // Keep for backward compatibility
+	proxy            *proxy.Supervisor  // Nil outside Docker environments
+	chaos            *chaos.Controller
+	logBuffer        *logbuffer.Buffer // Nil when LOG_BUFFER_LINES is 0
+	authenticator    *authz.Authenticator // Nil when the API is open to every caller
+	readinessChecks  map[string]ReadinessCheck
 	logger           *slog.Logger
 	startTime        time.Time
 	version          string
@@ -32,29 +51,54 @@ func NewHandler(backend backends.Backend, containerManager *container.Manager, l
 	}
 }
 
+// SetProxySupervisor lets the health endpoint report the reverse proxy's state
+func (h *Handler) SetProxySupervisor(supervisor *proxy.Supervisor) {
+	h.proxy = supervisor
+}
+
+// SetChaos serves /debug/chaos when controller has chaos mode enabled
+func (h *Handler) SetChaos(controller *chaos.Controller) {
+	h.chaos = controller
+}
+
+// SetLogBuffer lets support bundles include the manager's recent logs
+func (h *Handler) SetLogBuffer(buffer *logbuffer.Buffer) {
+	h.logBuffer = buffer
+}
+
 // SetupRoutes sets up the HTTP routes
 func (h *Handler) SetupRoutes(router *gin.Engine) {
+	// Role checks must be installed before any route is registered to apply to it
+	if h.authenticator != nil {
+		router.Use(h.authorize)
+	}
+	router.GET("/authz/whoami", h.whoami)
+
 	// OpenAPI documentation routes
 	h.SetupOpenAPIRoutes(router)
 
 	// Health check
 	router.GET("/health", h.healthCheck)
+	router.GET("/livez", h.livez)
+	router.GET("/readyz", h.readyz)
 
 	// Instance management (backend-agnostic)
 	router.GET("/instances", h.listInstances)
 	router.POST("/instances", h.createInstance)
-	router.GET("/instances/:id", h.getInstance)
-	router.PUT("/instances/:id", h.updateInstance)
-	router.DELETE("/instances/:id", h.deleteInstance)
+	router.POST("/instances/from-server-json", h.createInstanceFromServerJSON)
+	router.GET("/instances/:instance_id", h.getInstance)
+	router.PUT("/instances/:instance_id", h.updateInstance)
+	router.DELETE("/instances/:instance_id", h.deleteInstance)
 
 	// Instance validation
 	router.POST("/instances/validate", h.validateInstance)
 
 	// Instance monitoring and health checks
-	router.GET("/instances/:id/health", h.checkInstanceHealth)
-	router.POST("/instances/:id/health", h.healthCheckInstance)
-	router.GET("/instances/:id/health/detailed", h.getDetailedInstanceHealth)
+	router.GET("/instances/:instance_id/health", h.checkInstanceHealth)
+	router.POST("/instances/:instance_id/health", h.healthCheckInstance)
+	router.GET("/instances/:instance_id/health/detailed", h.getDetailedInstanceHealth)
 	router.GET("/instances/health", h.healthCheckInstances)
+	router.GET("/instances/:instance_id/inspect", h.inspectInstance)
 	router.GET("/monitoring/status", h.getMonitoringStatus)
 	router.GET("/monitoring/health-summary", h.getHealthSummary)
 
@@ -65,10 +109,130 @@ func (h *Handler) SetupRoutes(router *gin.Engine) {
 		router.GET("/containers/:service", h.getContainer)
 		router.DELETE("/containers/:service", h.deleteContainer)
 		router.POST("/containers/validate", h.validateContainer)
+		router.POST("/containers/adopt", h.adoptContainer)
 		router.GET("/containers/:service/health", h.checkContainerHealth)
 		router.POST("/containers/:service/health", h.healthCheckContainer)
 		router.GET("/containers/:service/health/detailed", h.getDetailedContainerHealth)
+		router.GET("/containers/:service/health/history", h.getContainerHealthHistory)
+		router.GET("/containers/:service/slo", h.getContainerSLO)
 		router.GET("/containers/health", h.healthCheckContainers)
+		router.POST("/containers/:service/stop", h.stopContainer)
+		router.POST("/containers/:service/start", h.startContainer)
+		router.POST("/containers/:service/checkpoint", h.checkpointContainer)
+		router.GET("/containers/:service/checkpoint", h.getCheckpoint)
+		router.POST("/containers/:service/restore", h.restoreContainer)
+		router.POST("/containers/:service/clone", h.cloneContainer)
+		router.POST("/containers/:service/stage", h.stageContainer)
+		router.POST("/containers/:service/promote", h.promoteContainer)
+		router.POST("/containers/:service/rollback", h.rollbackContainer)
+		router.POST("/containers/:service/archive", h.archiveContainer)
+		router.POST("/containers/:service/unarchive", h.unarchiveContainer)
+		router.POST("/containers/:service/route/refresh", h.refreshContainerRoute)
+		router.GET("/containers/:service/connection", h.getConnectionContract)
+		router.GET("/containers/:service/manifests", h.getContainerManifests)
+		router.GET("/containers/:service/env-schema", h.getContainerEnvSchema)
+		router.PATCH("/containers/:service/environment", h.updateContainerEnvironment)
+		router.PUT("/containers/:service/slug", h.renameContainerSlug)
+		router.GET("/containers/:service/sessions", h.listContainerSessions)
+		router.GET("/containers/:service/replicas", h.getContainerReplicas)
+		router.GET("/containers/:service/spec", h.getContainerSpec)
+		router.GET("/containers/:service/inspect", h.inspectContainer)
+		router.POST("/containers/:service/exec", h.execContainer)
+		router.GET("/containers/:service/port-forward", h.portForwardContainer)
+		router.PUT("/containers/:service/files", h.putContainerFile)
+		router.GET("/containers/:service/files", h.getContainerFile)
+		router.GET("/containers/:service/traffic", h.getContainerTraffic)
+		router.GET("/traffic/usage", h.getTrafficUsage)
+		router.GET("/slugs", h.listSlugs)
+
+		// Podman-only instance endpoints, resolved through the instance ID the platform registered
+		router.GET("/instances/:instance_id/logs", h.getInstanceLogs)
+		router.GET("/instances/:instance_id/connection", h.getInstanceConnection)
+		router.GET("/instances/:instance_id/events", h.getInstanceEvents)
+		router.GET("/instances/:instance_id/revisions", h.getInstanceRevisions)
+		router.POST("/instances/:instance_id/rollback/:revision", h.rollbackInstance)
+		router.GET("/instances/:instance_id/diff", h.getInstanceDiff)
+		router.POST("/instances/:instance_id/reconcile", h.reconcileInstance)
+
+		// Error page the proxy serves while a route's circuit breaker is open
+		router.GET("/proxy/unavailable/:slug", h.proxyUnavailable)
+		// Access token check the proxy makes before forwarding to a route that requires one;
+		// depending on the proxy version it uses the method of the original request
+		router.Any("/proxy/auth/:slug", h.proxyAuth)
+		// Header size check and error page of routes with size or timeout limits
+		router.Any("/proxy/limits/:slug", h.proxyRequestLimits)
+		router.GET("/proxy/limit-exceeded/:slug/:status", h.proxyLimitExceeded)
+		// Session check of routes that give each session its own container
+		router.Any("/proxy/session/:slug", h.proxySession)
+
+		// Lifecycle webhooks
+		router.GET("/webhooks", h.listWebhooks)
+		router.POST("/webhooks", h.createWebhook)
+		router.DELETE("/webhooks/:id", h.deleteWebhook)
+
+		// Spot/preemptible host handling
+		router.GET("/admin/preemption", h.getPreemptionStatus)
+		router.POST("/admin/preemption", h.triggerPreemption)
+		router.GET("/admin/gpus", h.getGPUCapacity)
+
+		// Environment self-test: pull, run and route a test container, resolve a secret
+		router.GET("/admin/doctor", h.runDoctor)
+
+		// How new containers fitted the host's memory and CPU, and which instances they preempted
+		router.GET("/scheduler/decisions", h.getSchedulingDecisions)
+		router.GET("/scheduler/preemptions", h.getPreemptions)
+
+		// Usage budgets of the host and of workspaces; exhausted budgets refuse or stop instances
+		router.GET("/budgets", h.listBudgets)
+		router.GET("/budgets/global", h.getBudget)
+		router.PUT("/budgets/global", h.setBudget)
+		router.DELETE("/budgets/global", h.deleteBudget)
+		router.POST("/budgets/global/reset", h.resetBudgetUsage)
+		router.GET("/budgets/workspaces/:workspace_id", h.getBudget)
+		router.PUT("/budgets/workspaces/:workspace_id", h.setBudget)
+		router.DELETE("/budgets/workspaces/:workspace_id", h.deleteBudget)
+		router.POST("/budgets/workspaces/:workspace_id/reset", h.resetBudgetUsage)
+
+		// Alert rules evaluated by the manager, the alerts they raise and silences muting them
+		router.GET("/alerts", h.listAlerts)
+		router.GET("/alerts/rules", h.listAlertRules)
+		router.PUT("/alerts/rules/:id", h.setAlertRule)
+		router.DELETE("/alerts/rules/:id", h.deleteAlertRule)
+		router.GET("/alerts/silences", h.listAlertSilences)
+		router.POST("/alerts/silences", h.createAlertSilence)
+		router.DELETE("/alerts/silences/:id", h.deleteAlertSilence)
+
+		// Maintenance: cordon, drain and uncordon
+		router.GET("/admin/cordon", h.getCordonStatus)
+		router.POST("/admin/cordon", h.cordonHost)
+		router.POST("/admin/drain", h.drainHost)
+		router.POST("/admin/uncordon", h.uncordonHost)
+
+		// Storage usage, unused image and build cache garbage collection, and the registry cache
+		router.GET("/storage/usage", h.getStorageUsage)
+		router.GET("/admin/gc", h.getGCReport)
+		router.POST("/admin/gc", h.runGC)
+		router.GET("/admin/registry-cache", h.getRegistryCacheStatus)
+		router.GET("/admin/backup", h.getBackup)
+		router.POST("/admin/restore", h.restoreBackup)
+		router.GET("/admin/export/compose", h.exportCompose)
+		router.GET("/admin/exec-audit", h.getExecAudit)
+		router.GET("/admin/support-bundle", h.getSupportBundle)
+
+		// Image metadata for prefilling instance specs; the reference may contain slashes
+		router.GET("/images/*ref", h.getImageMetadata)
+
+		// Background jobs such as image builds from source
+		router.GET("/jobs", h.listJobs)
+		router.GET("/jobs/:id", h.getJob)
+		router.GET("/jobs/:id/logs", h.getJobLogs)
+	}
+
+	// Fault injection for integration tests, only when CHAOS_ENABLED is set
+	if h.chaos.Enabled() {
+		router.GET("/debug/chaos", h.getChaos)
+		router.PUT("/debug/chaos", h.setChaos)
+		router.DELETE("/debug/chaos", h.resetChaos)
 	}
 }
 
@@ -94,6 +258,15 @@ func (h *Handler) healthCheck(c *gin.Context) {
 		Uptime:            uptime,
 	}
 
+	// MCP traffic is unroutable while the proxy is down even though the API still answers
+	if h.proxy != nil {
+		proxyStatus := h.proxy.Status()
+		response.Proxy = &proxyStatus
+		if !h.proxy.Healthy() {
+			response.Status = "degraded"
+		}
+	}
+
 	c.JSON(http.StatusOK, response)
 }
 
@@ -103,7 +276,7 @@ func (h *Handler) healthCheck(c *gin.Context) {
 func (h *Handler) listInstances(c *gin.Context) {
 	instances, err := h.backend.ListInstances(c.Request.Context())
 	if err != nil {
-		h.logger.Error("Failed to list instances", slog.String("error", err.Error()))
+		h.logger.ErrorContext(c.Request.Context(), "Failed to list instances", slog.String("error", err.Error()))
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "list_instances_failed",
 			Code:    http.StatusInternalServerError,
@@ -111,6 +284,9 @@ func (h *Handler) listInstances(c *gin.Context) {
 		})
 		return
 	}
+	instances = slices.DeleteFunc(instances, func(instance *backends.InstanceStatus) bool {
+		return !visibleWorkspace(c, h.instanceWorkspace(instance))
+	})
 
 	response := gin.H{
 		"instances": instances,
@@ -126,15 +302,39 @@ func (h *Handler) createInstance(c *gin.Context) {
 		InstanceID   string            `json:"instance_id" binding:"required"`
 		Name         string            `json:"name" binding:"required"`
 		ServiceName  string            `json:"service_name" binding:"required"`
-		Image        string            `json:"image" binding:"required"`
+		Slug         string            `json:"slug"`
+		Image        string            `json:"image"`
 		Port         int               `json:"port"`
 		Command      []string          `json:"command,omitempty"`
 		Environment  map[string]string `json:"environment,omitempty"`
 		WorkspaceID  string            `json:"workspace_id" binding:"required"`
 		Resources    struct {
-			Requests backends.ResourceList `json:"requests,omitempty"`
-			Limits   backends.ResourceList `json:"limits,omitempty"`
+			Requests  backends.ResourceList `json:"requests,omitempty"`
+			Limits    backends.ResourceList `json:"limits,omitempty"`
+			PidsLimit int                   `json:"pids_limit,omitempty"`
 		} `json:"resources,omitempty"`
+		HealthCheck *models.HealthCheckConfig `json:"health_check,omitempty"`
+		Route       *models.RouteConfig       `json:"route,omitempty"`
+		Routing     *models.RoutingConfig     `json:"routing,omitempty"`
+		GPUs        int                       `json:"gpus,omitempty"`
+		Devices     []string                  `json:"devices,omitempty"`
+		Security    *models.SecurityConfig    `json:"security,omitempty"`
+		// SharedNetwork lets the instance reach containers outside its workspace network
+		SharedNetwork  bool                  `json:"shared_network,omitempty"`
+		DNS            []string              `json:"dns,omitempty"`
+		ExtraHosts     []string              `json:"extra_hosts,omitempty"`
+		Proxy          *models.ProxyConfig   `json:"proxy,omitempty"`
+		Tmpfs          []models.ScratchMount `json:"tmpfs,omitempty"`
+		ScratchVolumes []models.ScratchMount `json:"scratch_volumes,omitempty"`
+		DependsOn      []models.Dependency   `json:"depends_on,omitempty"`
+		Init           *models.InitConfig    `json:"init,omitempty"`
+		Files          map[string]string     `json:"files,omitempty"`
+		// Source builds the image from a git repository instead of pulling Image
+		Source *models.SourceConfig `json:"source,omitempty"`
+		// Runtime runs an npx or uvx package instead of Image
+		Runtime     *models.RuntimeConfig     `json:"runtime,omitempty"`
+		LogShipping *models.LogShippingConfig `json:"log_shipping,omitempty"`
+		EnvSchema   []models.EnvVarSpec       `json:"env_schema,omitempty"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -145,6 +345,20 @@ func (h *Handler) createInstance(c *gin.Context) {
 		})
 		return
 	}
+	sources := 0
+	for _, set := range []bool{req.Image != "", req.Source != nil, req.Runtime != nil} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: "exactly one of image, source and runtime is required",
+		})
+		return
+	}
 
 	// Set default port if not specified
 	if req.Port == 0 {
@@ -156,20 +370,133 @@ func (h *Handler) createInstance(c *gin.Context) {
 		InstanceID:  req.InstanceID,
 		Name:        req.Name,
 		ServiceName: req.ServiceName,
+		Slug:        req.Slug,
 		Image:       req.Image,
 		Port:        req.Port,
 		Command:     req.Command,
 		Environment: req.Environment,
 		WorkspaceID: req.WorkspaceID,
 		Resources: backends.ResourceRequirements{
-			Requests: req.Resources.Requests,
-			Limits:   req.Resources.Limits,
+			Requests:  req.Resources.Requests,
+			Limits:    req.Resources.Limits,
+			PidsLimit: req.Resources.PidsLimit,
 		},
+		HealthCheck: req.HealthCheck,
+		Route:       req.Route,
+		Routing:     req.Routing,
+		GPUs:        req.GPUs,
+		Devices:     req.Devices,
+		Security:    req.Security,
+
+		SharedNetwork:  req.SharedNetwork,
+		DNS:            req.DNS,
+		ExtraHosts:     req.ExtraHosts,
+		Proxy:          req.Proxy,
+		Tmpfs:          req.Tmpfs,
+		ScratchVolumes: req.ScratchVolumes,
+		DependsOn:      req.DependsOn,
+		Init:           req.Init,
+		Files:          req.Files,
+		Source:         req.Source,
+		Runtime:        req.Runtime,
+		LogShipping:    req.LogShipping,
+		EnvSchema:      req.EnvSchema,
+
+		IdempotencyKey: c.GetHeader(idempotencyKeyHeader),
+	}
+	h.createInstanceFromSpec(c, spec)
+}
+
+// createInstanceFromSpec plans spec under ?dry_run, or creates it and maps the backend's errors
+// to responses
+func (h *Handler) createInstanceFromSpec(c *gin.Context, spec *backends.InstanceSpec) {
+	// Checked here rather than by each backend, so Kubernetes instances are held to the schema too
+	environment, err := container.ApplyEnvSchema(spec.EnvSchema, spec.Environment)
+	if respondEnvSchemaError(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+	spec.Environment = environment
+
+	if isDryRun(c) {
+		plan, err := h.backend.PlanInstance(c.Request.Context(), spec)
+		h.respondPlan(c, plan, err)
+		return
 	}
 
 	result, err := h.backend.CreateInstance(c.Request.Context(), spec)
+	if errors.Is(err, container.ErrCreateQueueFull) {
+		abortTooManyRequests(c, h.createRetryAfter(), err.Error())
+		return
+	}
+	if errors.Is(err, container.ErrCordoned) {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error:   "host_cordoned",
+			Code:    http.StatusServiceUnavailable,
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, container.ErrShuttingDown) {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error:   "shutting_down",
+			Code:    http.StatusServiceUnavailable,
+			Message: err.Error(),
+		})
+		return
+	}
+	if respondBudgetExhausted(c, err) || respondHostPreempted(c, err) {
+		return
+	}
+	if errors.Is(err, container.ErrStoragePressure) {
+		c.JSON(http.StatusInsufficientStorage, models.ErrorResponse{
+			Error:   "insufficient_storage",
+			Code:    http.StatusInsufficientStorage,
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, container.ErrInsufficientResources) {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error:   "insufficient_resources",
+			Code:    http.StatusServiceUnavailable,
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, container.ErrIdempotencyKeyReused) {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   "idempotency_key_reused",
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, container.ErrSlugConflict) {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "slug_conflict",
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, container.ErrInvalidSlug) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_slug",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
 	if err != nil {
-		h.logger.Error("Failed to create instance", slog.String("error", err.Error()))
+		h.logger.ErrorContext(c.Request.Context(), "Failed to create instance", slog.String("error", err.Error()))
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "instance_creation_failed",
 			Code:    http.StatusInternalServerError,
@@ -183,11 +510,11 @@ func (h *Handler) createInstance(c *gin.Context) {
 
 // getInstance returns details of a specific instance
 func (h *Handler) getInstance(c *gin.Context) {
-	instanceID := c.Param("id")
+	instanceID := c.Param("instance_id")
 
 	instance, err := h.backend.GetInstanceStatus(c.Request.Context(), instanceID)
 	if err != nil {
-		h.logger.Error("Failed to get instance", slog.String("instance_id", instanceID), slog.String("error", err.Error()))
+		h.logger.ErrorContext(c.Request.Context(), "Failed to get instance", slog.String("instance_id", instanceID), slog.String("error", err.Error()))
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Error:   "instance_not_found",
 			Code:    http.StatusNotFound,
@@ -201,7 +528,7 @@ func (h *Handler) getInstance(c *gin.Context) {
 
 // updateInstance updates an existing instance
 func (h *Handler) updateInstance(c *gin.Context) {
-	instanceID := c.Param("id")
+	instanceID := c.Param("instance_id")
 
 	var req struct {
 		Image       string            `json:"image,omitempty"`
@@ -267,7 +594,7 @@ func (h *Handler) updateInstance(c *gin.Context) {
 
 	err = h.backend.UpdateInstance(c.Request.Context(), instanceID, spec)
 	if err != nil {
-		h.logger.Error("Failed to update instance", slog.String("instance_id", instanceID), slog.String("error", err.Error()))
+		h.logger.ErrorContext(c.Request.Context(), "Failed to update instance", slog.String("instance_id", instanceID), slog.String("error", err.Error()))
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "instance_update_failed",
 			Code:    http.StatusInternalServerError,
@@ -284,11 +611,11 @@ func (h *Handler) updateInstance(c *gin.Context) {
 
 // deleteInstance removes an instance
 func (h *Handler) deleteInstance(c *gin.Context) {
-	instanceID := c.Param("id")
+	instanceID := c.Param("instance_id")
 
 	err := h.backend.DeleteInstance(c.Request.Context(), instanceID)
 	if err != nil {
-		h.logger.Error("Failed to delete instance", slog.String("instance_id", instanceID), slog.String("error", err.Error()))
+		h.logger.ErrorContext(c.Request.Context(), "Failed to delete instance", slog.String("instance_id", instanceID), slog.String("error", err.Error()))
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "instance_deletion_failed",
 			Code:    http.StatusInternalServerError,
@@ -360,11 +687,11 @@ func (h *Handler) validateInstance(c *gin.Context) {
 
 // checkInstanceHealth checks if a specific instance is healthy
 func (h *Handler) checkInstanceHealth(c *gin.Context) {
-	instanceID := c.Param("id")
+	instanceID := c.Param("instance_id")
 
 	healthResult, err := h.backend.PerformHealthCheck(c.Request.Context(), instanceID)
 	if err != nil {
-		h.logger.Error("Failed to perform health check", slog.String("instance_id", instanceID), slog.String("error", err.Error()))
+		h.logger.ErrorContext(c.Request.Context(), "Failed to perform health check", slog.String("instance_id", instanceID), slog.String("error", err.Error()))
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "health_check_failed",
 			Code:    http.StatusInternalServerError,
@@ -383,11 +710,11 @@ func (h *Handler) checkInstanceHealth(c *gin.Context) {
 
 // healthCheckInstance performs an HTTP health check on the instance's endpoint
 func (h *Handler) healthCheckInstance(c *gin.Context) {
-	instanceID := c.Param("id")
+	instanceID := c.Param("instance_id")
 
 	healthResult, err := h.backend.PerformHealthCheck(c.Request.Context(), instanceID)
 	if err != nil {
-		h.logger.Error("Failed to perform health check", slog.String("instance_id", instanceID), slog.String("error", err.Error()))
+		h.logger.ErrorContext(c.Request.Context(), "Failed to perform health check", slog.String("instance_id", instanceID), slog.String("error", err.Error()))
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "health_check_failed",
 			Code:    http.StatusInternalServerError,
@@ -401,7 +728,7 @@ func (h *Handler) healthCheckInstance(c *gin.Context) {
 
 // getDetailedInstanceHealth performs detailed health check on an instance
 func (h *Handler) getDetailedInstanceHealth(c *gin.Context) {
-	instanceID := c.Param("id")
+	instanceID := c.Param("instance_id")
 
 	// Get instance status first
 	instance, err := h.backend.GetInstanceStatus(c.Request.Context(), instanceID)
@@ -417,7 +744,7 @@ func (h *Handler) getDetailedInstanceHealth(c *gin.Context) {
 	// Perform health check
 	healthResult, err := h.backend.PerformHealthCheck(c.Request.Context(), instanceID)
 	if err != nil {
-		h.logger.Error("Failed to perform health check", slog.String("instance_id", instanceID), slog.String("error", err.Error()))
+		h.logger.ErrorContext(c.Request.Context(), "Failed to perform health check", slog.String("instance_id", instanceID), slog.String("error", err.Error()))
 		healthResult = &backends.HealthCheckResult{
 			Healthy:     false,
 			Status:      "error",
@@ -432,6 +759,8 @@ func (h *Handler) getDetailedInstanceHealth(c *gin.Context) {
 		"service_name":   instance.ServiceName,
 		"status":         instance.Status,
 		"healthy":        healthResult.Healthy,
+		"live":           healthResult.Live,
+		"ready":          healthResult.Ready,
 		"http_reachable": healthResult.HTTPReachable,
 		"response_time":  healthResult.ResponseTime,
 		"timestamp":      healthResult.Timestamp,
@@ -474,21 +803,34 @@ func (h *Handler) healthCheckInstances(c *gin.Context) {
 			return
 		}
 
-		healthResults := make([]interface{}, 0, len(instances))
-		for _, instance := range instances {
-			healthResult, err := h.backend.PerformHealthCheck(c.Request.Context(), instance.ID)
-			if err != nil {
-				// Create error result for this instance
-				healthResult = &backends.HealthCheckResult{
-					Healthy:     false,
-					Status:      "error",
-					ServiceName: instance.ServiceName,
-					Error:       err.Error(),
-					Timestamp:   time.Now(),
+		// Check instances in parallel, bounded so a large fleet does not spawn a probe per instance at once
+		healthResults := make([]interface{}, len(instances))
+		slots := make(chan struct{}, instanceHealthCheckWorkers)
+		var wg sync.WaitGroup
+		for i, instance := range instances {
+			wg.Add(1)
+			slots <- struct{}{}
+			go func(i int, instance *backends.InstanceStatus) {
+				defer func() {
+					<-slots
+					wg.Done()
+				}()
+
+				healthResult, err := h.backend.PerformHealthCheck(c.Request.Context(), instance.ID)
+				if err != nil {
+					// Create error result for this instance
+					healthResult = &backends.HealthCheckResult{
+						Healthy:     false,
+						Status:      "error",
+						ServiceName: instance.ServiceName,
+						Error:       err.Error(),
+						Timestamp:   time.Now(),
+					}
 				}
-			}
-			healthResults = append(healthResults, healthResult)
+				healthResults[i] = healthResult
+			}(i, instance)
 		}
+		wg.Wait()
 
 		c.JSON(http.StatusOK, gin.H{
 			"health_checks": healthResults,
@@ -501,13 +843,31 @@ func (h *Handler) healthCheckInstances(c *gin.Context) {
 
 // listContainers returns a list of all managed containers
 func (h *Handler) listContainers(c *gin.Context) {
-	containers := h.containerManager.ListContainers()
+	containers := slices.DeleteFunc(h.containerManager.ListContainers(), func(container models.Container) bool {
+		return !visibleWorkspace(c, container.WorkspaceID)
+	})
 
 	response := models.ListContainersResponse{
 		Containers: containers,
 		Total:      len(containers),
 	}
 
+	// Archived containers are hidden unless explicitly requested
+	if c.Query("include") == "archived" {
+		archived, err := h.containerManager.ListArchived()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+				Error:   "archive_unavailable",
+				Code:    http.StatusInternalServerError,
+				Message: err.Error(),
+			})
+			return
+		}
+		response.Archived = slices.DeleteFunc(archived, func(container models.ArchivedContainer) bool {
+			return !visibleWorkspace(c, container.WorkspaceID)
+		})
+	}
+
 	c.JSON(http.StatusOK, response)
 }
 
@@ -523,8 +883,94 @@ func (h *Handler) createContainer(c *gin.Context) {
 		return
 	}
 
+	if isDryRun(c) {
+		plan, err := h.containerManager.PlanContainer(c.Request.Context(), req)
+		h.respondPlan(c, plan, err)
+		return
+	}
+
 	// Create container (Traefik routing is handled automatically via labels)
-	container, err := h.containerManager.CreateContainer(c.Request.Context(), req)
+	var created *models.Container
+	var err error
+	if key := c.GetHeader(idempotencyKeyHeader); key != "" {
+		created, err = h.containerManager.CreateContainerIdempotent(c.Request.Context(), key, req)
+	} else {
+		created, err = h.containerManager.CreateContainer(c.Request.Context(), req)
+	}
+	if errors.Is(err, container.ErrCreateQueueFull) {
+		abortTooManyRequests(c, h.createRetryAfter(), err.Error())
+		return
+	}
+	if errors.Is(err, container.ErrCordoned) {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error:   "host_cordoned",
+			Code:    http.StatusServiceUnavailable,
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, container.ErrShuttingDown) {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error:   "shutting_down",
+			Code:    http.StatusServiceUnavailable,
+			Message: err.Error(),
+		})
+		return
+	}
+	if respondBudgetExhausted(c, err) || respondHostPreempted(c, err) {
+		return
+	}
+	if errors.Is(err, container.ErrStoragePressure) {
+		c.JSON(http.StatusInsufficientStorage, models.ErrorResponse{
+			Error:   "insufficient_storage",
+			Code:    http.StatusInsufficientStorage,
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, container.ErrInsufficientResources) {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error:   "insufficient_resources",
+			Code:    http.StatusServiceUnavailable,
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, container.ErrIdempotencyKeyReused) {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   "idempotency_key_reused",
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, container.ErrSlugConflict) {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "slug_conflict",
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, container.ErrInvalidSlug) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_slug",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+	if respondEnvSchemaError(c, err) {
+		return
+	}
+	if errors.Is(err, container.ErrUnsupportedPlatform) {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   "unsupported_platform",
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "container_creation_failed",
@@ -534,7 +980,7 @@ func (h *Handler) createContainer(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, container)
+	c.JSON(http.StatusCreated, created)
 }
 
 // getContainer returns details of a specific container
@@ -542,6 +988,12 @@ func (h *Handler) getContainer(c *gin.Context) {
 	serviceName := c.Param("service")
 
 	container, err := h.containerManager.GetContainer(serviceName)
+	if err != nil && c.Query("include") == "archived" {
+		if archived, archivedErr := h.containerManager.GetArchived(serviceName); archivedErr == nil {
+			c.JSON(http.StatusOK, archived)
+			return
+		}
+	}
 	if err != nil {
 		c.JSON(http.StatusNotFound, models.ErrorResponse{
 			Error:   "container_not_found",
@@ -716,6 +1168,8 @@ func (h *Handler) healthCheckContainer(c *gin.Context) {
 // healthCheckContainers performs health checks on containers
 func (h *Handler) healthCheckContainers(c *gin.Context) {
 	serviceName := c.Query("service")
+	// fresh=true skips cached results and probes every container
+	fresh := c.Query("fresh") == "true"
 
 	if serviceName != "" {
 		// Health check for specific container
@@ -730,7 +1184,7 @@ func (h *Handler) healthCheckContainers(c *gin.Context) {
 		}
 
 		// Perform health check
-		healthResult, err := h.containerManager.PerformHealthCheck(c.Request.Context(), serviceName)
+		healthResult, err := h.containerManager.CheckHealth(c.Request.Context(), serviceName, fresh)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 				Error:   "health_check_failed",
@@ -742,24 +1196,8 @@ func (h *Handler) healthCheckContainers(c *gin.Context) {
 
 		c.JSON(http.StatusOK, healthResult)
 	} else {
-		// Health check for all containers
-		containers := h.containerManager.ListContainers()
-		healthResults := make([]map[string]interface{}, 0, len(containers))
-
-		for _, container := range containers {
-			healthResult, err := h.containerManager.PerformHealthCheck(c.Request.Context(), container.ServiceName)
-			if err != nil {
-				// Create error result for this container
-				healthResult = map[string]interface{}{
-					"service_name":     container.ServiceName,
-					"container_status": string(container.Status),
-					"healthy":          false,
-					"error":            err.Error(),
-					"timestamp":        time.Now(),
-				}
-			}
-			healthResults = append(healthResults, healthResult)
-		}
+		// Health check for all containers, in parallel and from cache where recent enough
+		healthResults := h.containerManager.CheckHealthAll(c.Request.Context(), fresh)
 
 		c.JSON(http.StatusOK, map[string]interface{}{
 			"health_checks": healthResults,
@@ -773,7 +1211,7 @@ func (h *Handler) getMonitoringStatus(c *gin.Context) {
 	// Use backend to get instance status
 	instances, err := h.backend.ListInstances(c.Request.Context())
 	if err != nil {
-		h.logger.Error("Failed to list instances for monitoring", slog.String("error", err.Error()))
+		h.logger.ErrorContext(c.Request.Context(), "Failed to list instances for monitoring", slog.String("error", err.Error()))
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "monitoring_status_failed",
 			Code:    http.StatusInternalServerError,
@@ -810,6 +1248,15 @@ func (h *Handler) getMonitoringStatus(c *gin.Context) {
 		"timestamp":            time.Now(),
 		"uptime":               time.Since(h.startTime).String(),
 	}
+	if h.containerManager != nil {
+		response["upstream_pool"] = h.containerManager.UpstreamPoolStats()
+		response["pending_callbacks"] = h.containerManager.PendingCallbacks()
+		response["pending_events"] = h.containerManager.PendingEvents()
+		response["in_flight_operations"] = h.containerManager.InFlightOperations()
+		response["concurrency_saturation"] = h.containerManager.ConcurrencySaturation()
+	}
+	response["retries"] = retry.Snapshot()
+	h.addUptimeSummary(response)
 
 	c.JSON(http.StatusOK, response)
 }
@@ -828,15 +1275,15 @@ func (h *Handler) getDetailedContainerHealth(c *gin.Context) {
 		return
 	}
 
-	// This is a placeholder - in real implementation, you'd use the health checker
-	// healthResult, err := h.containerManager.healthChecker.PerformHealthCheck(c.Request.Context(), container)
-	// For now, return basic health info
+	// Report the latest result from the background health monitor
 	response := gin.H{
 		"container_id":   container.ID,
 		"service_name":   container.ServiceName,
 		"status":         string(container.Status),
 		"healthy":        container.Status == models.StatusRunning,
-		"http_reachable": false, // Would be determined by actual health check
+		"live":           container.Status == models.StatusRunning,
+		"ready":          container.Ready,
+		"http_reachable": false,
 		"response_time":  0,
 		"timestamp":      time.Now(),
 		"details": gin.H{
@@ -846,6 +1293,28 @@ func (h *Handler) getDetailedContainerHealth(c *gin.Context) {
 			"updated_at":     container.UpdatedAt,
 		},
 	}
+	if result, exists := h.containerManager.GetContainerHealthStatus(serviceName); exists {
+		response["healthy"] = result.Healthy
+		response["live"] = result.Live
+		response["http_reachable"] = result.HTTPReachable
+		response["response_time"] = result.ResponseTime
+		response["timestamp"] = result.Timestamp
+		if result.Error != "" {
+			response["error"] = result.Error
+		}
+		if result.ReadinessError != "" {
+			response["readiness_error"] = result.ReadinessError
+		}
+	}
+	if _, uptime, err := h.containerManager.GetHealthHistory(serviceName); err == nil && uptime >= 0 {
+		response["uptime_percent"] = uptime
+	}
+	if breaker, err := h.containerManager.CircuitBreakerStatus(serviceName); err == nil {
+		response["circuit_breaker"] = breaker
+	}
+	if concurrency, err := h.containerManager.ConcurrencyStatus(serviceName); err == nil && concurrency != nil {
+		response["concurrency"] = concurrency
+	}
 
 	c.JSON(http.StatusOK, response)
 }
@@ -855,7 +1324,7 @@ func (h *Handler) getHealthSummary(c *gin.Context) {
 	// Use backend to get instance status
 	instances, err := h.backend.ListInstances(c.Request.Context())
 	if err != nil {
-		h.logger.Error("Failed to list instances for health summary", slog.String("error", err.Error()))
+		h.logger.ErrorContext(c.Request.Context(), "Failed to list instances for health summary", slog.String("error", err.Error()))
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
 			Error:   "health_summary_failed",
 			Code:    http.StatusInternalServerError,
@@ -896,6 +1365,7 @@ func (h *Handler) getHealthSummary(c *gin.Context) {
 		"timestamp":            time.Now(),
 		"uptime":               time.Since(h.startTime).String(),
 	}
+	h.addUptimeSummary(response)
 
 	c.JSON(http.StatusOK, response)
 }
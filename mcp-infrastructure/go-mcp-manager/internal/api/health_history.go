@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// getContainerHealthHistory returns the recent health check results of a container
+func (h *Handler) getContainerHealthHistory(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	entries, uptime, err := h.containerManager.GetHealthHistory(serviceName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	response := gin.H{
+		"service_name": serviceName,
+		"history":      entries,
+		"total":        len(entries),
+	}
+	if uptime >= 0 {
+		response["uptime_percent"] = uptime
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// getContainerSLO returns the availability and proxy error rate of a container over 1h, 24h and 30d
+func (h *Handler) getContainerSLO(c *gin.Context) {
+	report, err := h.containerManager.GetSLO(c.Param("service"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// addUptimeSummary adds per-container and average uptime percentages of the recent health checks
+// to a monitoring response, and the rolling SLOs of every container
+func (h *Handler) addUptimeSummary(response gin.H) {
+	if h.containerManager == nil {
+		return
+	}
+
+	response["slo"] = h.containerManager.SLOSummary()
+
+	uptime := h.containerManager.HealthUptime()
+	if len(uptime) == 0 {
+		return
+	}
+
+	total := 0.0
+	for _, value := range uptime {
+		total += value
+	}
+	response["uptime_percent"] = total / float64(len(uptime))
+	response["container_uptime_percent"] = uptime
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/internal/registry"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// getImageMetadata returns an image's ports, entrypoint, environment defaults and labels. Image
+// references contain slashes, so the route captures the rest of the path, which must end in
+// /metadata.
+func (h *Handler) getImageMetadata(c *gin.Context) {
+	ref, found := strings.CutSuffix(strings.TrimPrefix(c.Param("ref"), "/"), "/metadata")
+	if !found || ref == "" {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "not_found",
+			Code:    http.StatusNotFound,
+			Message: "expected /images/{ref}/metadata",
+		})
+		return
+	}
+
+	metadata, err := h.containerManager.ImageMetadata(c.Request.Context(), ref, c.Query("pull") == "true")
+	var platformErr *registry.PlatformError
+	switch {
+	case errors.Is(err, container.ErrInvalidImageReference):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_image_reference",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	case errors.Is(err, registry.ErrNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "image_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+	case errors.As(err, &platformErr):
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   "unsupported_platform",
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		})
+	case err != nil:
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "image_inspection_failed",
+			Code:    http.StatusBadGateway,
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusOK, metadata)
+	}
+}
+
+// getRegistryCacheStatus reports the pull-through registry cache and its hit counters
+func (h *Handler) getRegistryCacheStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.containerManager.RegistryCacheStatus(c.Request.Context()))
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/backends"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// inspectContainer returns podman's view of a container, so failing instances can be debugged
+// without shell access to the host
+func (h *Handler) inspectContainer(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	if _, err := h.containerManager.GetContainer(serviceName); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	inspect, err := h.containerManager.InspectContainer(c.Request.Context(), serviceName)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to inspect container",
+			slog.String("service_name", serviceName),
+			slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "inspect_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, inspect)
+}
+
+// inspectInstance returns the runtime's view of an instance on any backend that can inspect one
+func (h *Handler) inspectInstance(c *gin.Context) {
+	instanceID := c.Param("instance_id")
+
+	inspector, ok := h.backend.(backends.Inspector)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, models.ErrorResponse{
+			Error:   "inspect_unsupported",
+			Code:    http.StatusNotImplemented,
+			Message: "the backend cannot inspect instances",
+		})
+		return
+	}
+
+	inspect, err := inspector.InspectInstance(c.Request.Context(), instanceID)
+	if errors.Is(err, backends.ErrInstanceNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "instance_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to inspect instance",
+			slog.String("instance_id", instanceID),
+			slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "inspect_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, inspect)
+}
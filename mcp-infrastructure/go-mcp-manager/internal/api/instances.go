@@ -0,0 +1,196 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// resolveInstance maps the instance_id path parameter to a container's service name, writing a
+// 404 when no container serves that instance
+func (h *Handler) resolveInstance(c *gin.Context) (string, bool) {
+	instanceID := c.Param("instance_id")
+	serviceName, found := h.containerManager.ServiceNameForInstance(instanceID)
+	if !found {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "instance_not_found",
+			Code:    http.StatusNotFound,
+			Message: fmt.Sprintf("instance not found: %s", instanceID),
+		})
+		return "", false
+	}
+	return serviceName, true
+}
+
+// getInstanceLogs returns the most recent output of an instance's container
+func (h *Handler) getInstanceLogs(c *gin.Context) {
+	serviceName, found := h.resolveInstance(c)
+	if !found {
+		return
+	}
+
+	tail := 0
+	if raw := c.Query("tail"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{
+				Error:   "invalid_tail",
+				Code:    http.StatusBadRequest,
+				Message: "tail must be a positive number of lines",
+			})
+			return
+		}
+		tail = parsed
+	}
+
+	logs, err := h.containerManager.GetContainerLogs(c.Request.Context(), serviceName, tail)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "logs_unavailable",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.InstanceLogsResponse{
+		InstanceID:  c.Param("instance_id"),
+		ServiceName: serviceName,
+		Logs:        logs,
+	})
+}
+
+// getInstanceConnection returns the connection contract of an instance
+func (h *Handler) getInstanceConnection(c *gin.Context) {
+	serviceName, found := h.resolveInstance(c)
+	if !found {
+		return
+	}
+
+	contract, err := h.containerManager.GetConnectionContract(serviceName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "instance_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, contract)
+}
+
+// getInstanceEvents returns the event timeline of an instance, which outlives its container for a
+// while after deletion
+func (h *Handler) getInstanceEvents(c *gin.Context) {
+	events, err := h.containerManager.GetInstanceEvents(c.Param("instance_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "instance_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// getInstanceRevisions returns the specs an instance was deployed from, which outlive its
+// container as long as its events
+func (h *Handler) getInstanceRevisions(c *gin.Context) {
+	revisions, err := h.containerManager.GetSpecRevisions(c.Param("instance_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "instance_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// rollbackInstance redeploys a previous spec revision of an instance
+func (h *Handler) rollbackInstance(c *gin.Context) {
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil || revision < 1 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_revision",
+			Code:    http.StatusBadRequest,
+			Message: "revision must be a positive number",
+		})
+		return
+	}
+
+	result, err := h.containerManager.RollbackInstance(c.Request.Context(), c.Param("instance_id"), revision)
+	if errors.Is(err, container.ErrRevisionNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "revision_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "rollback_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// getInstanceDiff compares an instance's running container with its declared spec
+func (h *Handler) getInstanceDiff(c *gin.Context) {
+	serviceName, found := h.resolveInstance(c)
+	if !found {
+		return
+	}
+
+	diff, err := h.containerManager.DiffContainer(c.Request.Context(), serviceName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "diff_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+	diff.InstanceID = c.Param("instance_id")
+	if !diff.InSync {
+		diff.ReconcileURL = fmt.Sprintf("/instances/%s/reconcile", diff.InstanceID)
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// reconcileInstance recreates an instance's container from its declared spec if it drifted
+func (h *Handler) reconcileInstance(c *gin.Context) {
+	serviceName, found := h.resolveInstance(c)
+	if !found {
+		return
+	}
+
+	result, err := h.containerManager.ReconcileContainer(c.Request.Context(), serviceName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "reconcile_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
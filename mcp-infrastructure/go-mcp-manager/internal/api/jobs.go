@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// listJobs returns recent jobs such as image builds, optionally filtered by ?service=
+func (h *Handler) listJobs(c *gin.Context) {
+	jobs := h.containerManager.ListJobs(c.Query("service"))
+	c.JSON(http.StatusOK, gin.H{
+		"jobs":  jobs,
+		"total": len(jobs),
+	})
+}
+
+// getJob returns the state of a job
+func (h *Handler) getJob(c *gin.Context) {
+	job, err := h.containerManager.GetJob(c.Param("id"))
+	if err != nil {
+		respondJobNotFound(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// getJobLogs returns the output a job has written so far, such as podman build logs
+func (h *Handler) getJobLogs(c *gin.Context) {
+	id := c.Param("id")
+	job, err := h.containerManager.GetJob(id)
+	if err != nil {
+		respondJobNotFound(c, err)
+		return
+	}
+	logs, err := h.containerManager.GetJobLogs(id)
+	if err != nil {
+		respondJobNotFound(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.JobLogsResponse{
+		ID:     job.ID,
+		Status: job.Status,
+		Logs:   logs,
+	})
+}
+
+// respondJobNotFound writes the 404 returned for unknown or expired jobs
+func respondJobNotFound(c *gin.Context, err error) {
+	c.JSON(http.StatusNotFound, models.ErrorResponse{
+		Error:   "job_not_found",
+		Code:    http.StatusNotFound,
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// stopContainer stops a container but keeps it, its slug and its route configuration
+func (h *Handler) stopContainer(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	if _, err := h.containerManager.GetContainer(serviceName); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	container, err := h.containerManager.StopContainer(c.Request.Context(), serviceName)
+	if err != nil {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "container_stop_failed",
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, container)
+}
+
+// startContainer starts a stopped container and re-enables its route
+func (h *Handler) startContainer(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	if _, err := h.containerManager.GetContainer(serviceName); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	container, err := h.containerManager.StartContainer(c.Request.Context(), serviceName)
+	if respondBudgetExhausted(c, err) || respondHostPreempted(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "container_start_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, container)
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// proxyRequestLimits answers the proxy's check of a request's header size for an MCP route: 200
+// lets the request through, anything else is returned to the client instead
+func (h *Handler) proxyRequestLimits(c *gin.Context) {
+	status, message := h.containerManager.CheckProxyRequestLimits(c.Param("slug"), c.Request.Header)
+	if status == 0 {
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.JSON(status, models.ErrorResponse{
+		Error:   "headers_too_large",
+		Code:    status,
+		Message: message,
+	})
+}
+
+// proxyLimitExceeded is the error page the proxy fetches when it answers an MCP route with 413, 429
+// or 504, so agents learn which instance's limit they hit instead of getting a bare status
+func (h *Handler) proxyLimitExceeded(c *gin.Context) {
+	status, err := strconv.Atoi(c.Param("status"))
+	if err != nil || status < 400 || status > 599 {
+		status = http.StatusBadGateway
+	}
+	message, retryAfter := h.containerManager.ProxyLimitExceeded(c.Request.Context(), c.Param("slug"), status)
+	if retryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+	}
+
+	code := "upstream_error"
+	switch status {
+	case http.StatusRequestEntityTooLarge:
+		code = "body_too_large"
+	case http.StatusTooManyRequests:
+		code = "too_many_requests"
+	case http.StatusGatewayTimeout:
+		code = "upstream_timeout"
+	}
+	c.JSON(status, models.ErrorResponse{
+		Error:   code,
+		Code:    status,
+		Message: message,
+	})
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"sigs.k8s.io/yaml"
+
+	"github.com/agentarea/mcp-manager/internal/backends"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// Formats accepted by GET /containers/:service/manifests
+const (
+	manifestFormatYAML = "yaml"
+	manifestFormatHelm = "helm"
+)
+
+// getContainerManifests renders a container as Kubernetes manifests, or as Helm values with
+// ?format=helm, so it can be moved to a cluster or a GitOps repository
+func (h *Handler) getContainerManifests(c *gin.Context) {
+	serviceName := c.Param("service")
+	format := c.DefaultQuery("format", manifestFormatYAML)
+	if format != manifestFormatYAML && format != manifestFormatHelm {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: "format must be yaml or helm",
+		})
+		return
+	}
+
+	container, err := h.containerManager.GetContainer(serviceName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	body, err := h.renderContainerManifests(container, format)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   "manifest_render_failed",
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.Data(http.StatusOK, "application/yaml", body)
+}
+
+// renderContainerManifests renders container with the manager's Kubernetes settings
+func (h *Handler) renderContainerManifests(container *models.Container, format string) ([]byte, error) {
+	spec, err := backends.ContainerSpec(container)
+	if err != nil {
+		return nil, err
+	}
+	manifests, err := backends.RenderManifests(h.containerManager.Config(), spec)
+	if err != nil {
+		return nil, err
+	}
+	return renderManifestsYAML(manifests, format)
+}
+
+// renderManifestsYAML writes the manifests as a multi-document YAML stream, or the Helm values
+func renderManifestsYAML(manifests *backends.Manifests, format string) ([]byte, error) {
+	if format == manifestFormatHelm {
+		return yaml.Marshal(manifests.HelmValues())
+	}
+
+	var out bytes.Buffer
+	for i, object := range manifests.Objects() {
+		data, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			out.WriteString("---\n")
+		}
+		out.Write(data)
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// isDryRun reports whether a create request only asks for the plan, via ?dry_run=true
+func isDryRun(c *gin.Context) bool {
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+	return dryRun
+}
+
+// respondPlan writes the plan of a dry-run create, or why the create would be rejected
+func (h *Handler) respondPlan(c *gin.Context, plan *models.CreatePlan, err error) {
+	if errors.Is(err, container.ErrCordoned) {
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+			Error:   "host_cordoned",
+			Code:    http.StatusServiceUnavailable,
+			Message: err.Error(),
+		})
+		return
+	}
+	if respondEnvSchemaError(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   "plan_rejected",
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// portForwardDialTimeout bounds connecting the tunnel to the container
+const portForwardDialTimeout = 5 * time.Second
+
+// portForwardContainer upgrades to a WebSocket that carries a TCP connection to the container's
+// server port in binary frames, so a local MCP inspector can reach a production instance through
+// e.g. `websocat -b tcp-l:127.0.0.1:8000 ws://...`. Admin only, so it is refused unless API
+// authorization is configured: the tunnel bypasses the route's access token.
+func (h *Handler) portForwardContainer(c *gin.Context) {
+	if !h.requireAuthorization(c) {
+		return
+	}
+	serviceName := c.Param("service")
+
+	if _, err := h.containerManager.GetContainer(serviceName); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+	if !strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "websocket_required",
+			Code:    http.StatusBadRequest,
+			Message: "port forwarding needs a WebSocket upgrade request",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	address, err := h.containerManager.PortForwardAddress(ctx, serviceName)
+	if errors.Is(err, container.ErrContainerNotRunning) {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "container_not_running",
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "port_forward_failed",
+			Code:    http.StatusBadGateway,
+			Message: err.Error(),
+		})
+		return
+	}
+	// Dialled before the upgrade so an unreachable server is still answered with a JSON error;
+	// closed here too, as the handler never runs when the handshake fails
+	upstream, err := net.DialTimeout("tcp", address, portForwardDialTimeout)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "port_forward_failed",
+			Code:    http.StatusBadGateway,
+			Message: err.Error(),
+		})
+		return
+	}
+	defer upstream.Close()
+
+	caller := requestPrincipal(c)
+	h.logger.WarnContext(ctx, "Port forward opened",
+		slog.String("service", serviceName),
+		slog.String("caller", caller.Subject),
+		slog.String("source", caller.Source),
+		slog.String("remote", c.ClientIP()))
+
+	// Clients are tools rather than browsers, so the Origin header is not checked
+	server := websocket.Server{Handler: func(ws *websocket.Conn) {
+		ws.PayloadType = websocket.BinaryFrame
+		// The server's read and write timeouts would otherwise cut the tunnel off
+		_ = ws.SetDeadline(time.Time{})
+
+		started := time.Now()
+		var sent, received int64
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sent, _ = io.Copy(upstream, ws)
+			// Let the server see the client is done while the rest of its answer is relayed
+			if tcp, ok := upstream.(*net.TCPConn); ok {
+				_ = tcp.CloseWrite()
+			}
+		}()
+		received, _ = io.Copy(ws, upstream)
+		_ = ws.Close()
+		wg.Wait()
+
+		h.logger.WarnContext(ctx, "Port forward closed",
+			slog.String("service", serviceName),
+			slog.String("caller", caller.Subject),
+			slog.Int64("bytes_sent", sent),
+			slog.Int64("bytes_received", received),
+			slog.Duration("duration", time.Since(started)))
+	}}
+	server.ServeHTTP(c.Writer, c.Request)
+}
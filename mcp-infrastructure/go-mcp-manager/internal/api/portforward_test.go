@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/authz"
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/internal/container"
+)
+
+func TestPortForwardNeedsAuthorization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewHandler(nil, container.NewManager(&config.Config{}, testLogger()), testLogger(), "test")
+	router := gin.New()
+	router.GET("/containers/:service/port-forward", handler.portForwardContainer)
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/containers/github/port-forward", nil)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	if recorder := send(); recorder.Code != http.StatusForbidden || !strings.Contains(recorder.Body.String(), "authorization_required") {
+		t.Errorf("Expected an unauthenticated tunnel to be refused, got %d %s", recorder.Code, recorder.Body.String())
+	}
+
+	handler.SetAuthenticator(testAuthenticator(t, map[string]authz.Role{"admin-secret": authz.RoleAdmin}))
+	if recorder := send(); recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected the tunnel to reach the container lookup with API authorization, got %d", recorder.Code)
+	}
+}
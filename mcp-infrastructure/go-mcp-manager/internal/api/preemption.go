@@ -0,0 +1,47 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// getPreemptionStatus returns whether this host is preemptible and if a preemption is in progress
+func (h *Handler) getPreemptionStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.containerManager.GetPreemptionStatus())
+}
+
+// triggerPreemption signals an imminent host preemption, after which the host starts no containers
+func (h *Handler) triggerPreemption(c *gin.Context) {
+	var req struct {
+		Reason string `json:"reason,omitempty"`
+	}
+	// The body is optional
+	_ = c.ShouldBindJSON(&req)
+
+	if req.Reason == "" {
+		req.Reason = "preemption signalled via API"
+	}
+
+	status := h.containerManager.HandlePreemption(c.Request.Context(), req.Reason)
+
+	c.JSON(http.StatusAccepted, status)
+}
+
+// respondHostPreempted answers a create or start refused because the host is being preempted, and
+// reports whether it did
+func respondHostPreempted(c *gin.Context, err error) bool {
+	if !errors.Is(err, container.ErrHostPreempted) {
+		return false
+	}
+	c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+		Error:   "host_preempted",
+		Code:    http.StatusServiceUnavailable,
+		Message: err.Error(),
+	})
+	return true
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// proxyAuth answers the proxy's forward auth request for an MCP route: 200 lets the request through,
+// anything else is returned to the client instead
+func (h *Handler) proxyAuth(c *gin.Context) {
+	scheme, authorized := h.containerManager.AuthorizeProxyRequest(c.Param("slug"), c.GetHeader("Authorization"))
+	if authorized {
+		c.Status(http.StatusOK)
+		return
+	}
+
+	if scheme == models.ProxyAuthBasic {
+		c.Header("WWW-Authenticate", `Basic realm="mcp", charset="UTF-8"`)
+	} else {
+		c.Header("WWW-Authenticate", `Bearer realm="mcp"`)
+	}
+	c.JSON(http.StatusUnauthorized, models.ErrorResponse{
+		Error:   "unauthorized",
+		Code:    http.StatusUnauthorized,
+		Message: "a valid access token for this MCP server is required",
+	})
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+
+	"github.com/agentarea/mcp-manager/internal/authz"
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// clientLimiter is the token bucket for a single API client
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimiter applies per-client token bucket limits to the HTTP API
+type RateLimiter struct {
+	clients       map[string]*clientLimiter
+	mutex         sync.Mutex
+	limit         rate.Limit
+	burst         int
+	authenticator *authz.Authenticator
+	logger        *slog.Logger
+}
+
+// NewRateLimiter creates a rate limiter from configuration. Without an authenticator, clients are
+// told apart by IP only, since their credentials cannot be verified.
+func NewRateLimiter(cfg config.RateLimitConfig, authenticator *authz.Authenticator, logger *slog.Logger) *RateLimiter {
+	return &RateLimiter{
+		clients:       make(map[string]*clientLimiter),
+		limit:         rate.Limit(cfg.RequestsPerSecond),
+		burst:         cfg.Burst,
+		authenticator: authenticator,
+		logger:        logger,
+	}
+}
+
+// Middleware returns a gin middleware rejecting clients over their limit with 429
+func (rl *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isUnlimitedRoute(c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		key := rl.clientKey(c)
+		limiter := rl.limiterFor(key)
+
+		reservation := limiter.Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			reservation.Cancel()
+			rl.logger.Warn("Rate limit exceeded",
+				slog.String("client", key),
+				slog.String("path", c.Request.URL.Path))
+			abortTooManyRequests(c, delay, "rate limit exceeded")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// isUnlimitedRoute reports whether a route is never throttled: liveness and readiness probes, and
+// the proxy's callbacks, which Traefik makes for every proxied request from its one address, so a
+// shared bucket would throttle the traffic of all instances together
+func isUnlimitedRoute(route string) bool {
+	switch route {
+	case "/health", "/livez", "/readyz":
+		return true
+	}
+	return publicRoutes[route] && strings.HasPrefix(route, "/proxy/")
+}
+
+// limiterFor returns the limiter for a client, creating it and evicting idle clients as needed
+func (rl *RateLimiter) limiterFor(key string) *rate.Limiter {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	now := time.Now()
+	client, exists := rl.clients[key]
+	if !exists {
+		// Opportunistically drop clients idle for a while to keep the map bounded
+		for k, c := range rl.clients {
+			if now.Sub(c.lastSeen) > 10*time.Minute {
+				delete(rl.clients, k)
+			}
+		}
+
+		client = &clientLimiter{limiter: rate.NewLimiter(rl.limit, rl.burst)}
+		rl.clients[key] = client
+	}
+	client.lastSeen = now
+
+	return client.limiter
+}
+
+// clientKey identifies the caller by a hash of its credential once the authenticator accepts it,
+// and by client IP otherwise, so made-up keys share their sender's bucket. The client IP only
+// comes from X-Forwarded-For when the router trusts the peer as a proxy. The principal is kept
+// for the authorization check.
+func (rl *RateLimiter) clientKey(c *gin.Context) string {
+	if rl.authenticator != nil {
+		if principal, err := rl.authenticator.Authenticate(c.Request); err == nil {
+			c.Set(principalKey, principal)
+			return "key:" + credentialHash(c.Request)
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// credentialHash returns a short hash of the API key or bearer token of a request, which can be
+// logged without revealing it
+func credentialHash(r *http.Request) string {
+	credential := r.Header.Get("X-API-Key")
+	if credential == "" {
+		_, credential, _ = strings.Cut(r.Header.Get("Authorization"), " ")
+		credential = strings.TrimSpace(credential)
+	}
+	sum := sha256.Sum256([]byte(credential))
+	return hex.EncodeToString(sum[:8])
+}
+
+// abortTooManyRequests aborts the request with 429 and a Retry-After header
+func abortTooManyRequests(c *gin.Context, retryAfter time.Duration, message string) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", fmt.Sprintf("%d", seconds))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, models.ErrorResponse{
+		Error:   "too_many_requests",
+		Code:    http.StatusTooManyRequests,
+		Message: message,
+	})
+}
+
+// createRetryAfter returns the back-off suggested when the create queue is saturated
+func (h *Handler) createRetryAfter() time.Duration {
+	if h.containerManager == nil {
+		return time.Second
+	}
+	return h.containerManager.CreateRetryAfter()
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/authz"
+	"github.com/agentarea/mcp-manager/internal/config"
+)
+
+func TestRateLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.RateLimitConfig{Enabled: true, RequestsPerSecond: 0.001, Burst: 2}
+	authenticator := testAuthenticator(t, map[string]authz.Role{"operator-secret": authz.RoleOperator})
+
+	router := gin.New()
+	limiter := NewRateLimiter(cfg, authenticator, testLogger())
+	router.Use(limiter.Middleware())
+	router.GET("/instances", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(header, value string) int {
+		req := httptest.NewRequest(http.MethodGet, "/instances", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	for i := 0; i < cfg.Burst; i++ {
+		if code := send("", ""); code != http.StatusOK {
+			t.Errorf("Expected request %d within the burst to pass, got %d", i+1, code)
+		}
+	}
+	if code := send("", ""); code != http.StatusTooManyRequests {
+		t.Errorf("Expected the request over the burst to get 429, got %d", code)
+	}
+	// Keys the authenticator does not know share the bucket of the client's IP
+	if code := send("X-API-Key", "made-up"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected an unknown key to get no bucket of its own, got %d", code)
+	}
+
+	// A valid key has its own bucket, keyed by a hash that does not reveal it
+	for i := 0; i < cfg.Burst; i++ {
+		if code := send("Authorization", "Bearer operator-secret"); code != http.StatusOK {
+			t.Errorf("Expected authenticated request %d within the burst to pass, got %d", i+1, code)
+		}
+	}
+	if code := send("X-API-Key", "operator-secret"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected the authenticated request over the burst to get 429, got %d", code)
+	}
+	for key := range limiter.clients {
+		if strings.Contains(key, "operator-secret") {
+			t.Errorf("Expected clients to be keyed without their credential, got %q", key)
+		}
+	}
+	if len(limiter.clients) != 2 {
+		t.Errorf("Expected one bucket for the IP and one for the key, got %d", len(limiter.clients))
+	}
+}
+
+func TestRateLimiterExemptsProxyCallbacks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.RateLimitConfig{Enabled: true, RequestsPerSecond: 0.001, Burst: 1}
+
+	router := gin.New()
+	router.Use(NewRateLimiter(cfg, nil, testLogger()).Middleware())
+	for route := range publicRoutes {
+		router.GET(route, func(c *gin.Context) { c.Status(http.StatusOK) })
+	}
+	router.GET("/instances", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	send := func(path string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	// Traefik's forward-auth and middleware calls all come from its one address
+	for _, path := range []string{"/proxy/auth/github", "/proxy/limits/github", "/proxy/session/github", "/proxy/unavailable/github", "/proxy/limit-exceeded/github/429", "/livez"} {
+		for i := 0; i < 3; i++ {
+			if code := send(path); code != http.StatusOK {
+				t.Errorf("Expected %s never to be throttled, got %d on request %d", path, code, i+1)
+			}
+		}
+	}
+	if code := send("/instances"); code != http.StatusOK {
+		t.Errorf("Expected the first API request to pass, got %d", code)
+	}
+	if code := send("/instances"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected the API to stay limited, got %d", code)
+	}
+	// Public docs are not callbacks, so they share the client's bucket
+	if code := send("/openapi.yaml"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected the API docs to be limited, got %d", code)
+	}
+}
+
+func TestRateLimiterIgnoresSpoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.RateLimitConfig{Enabled: true, RequestsPerSecond: 0.001, Burst: 1}
+
+	newRouter := func(trustedProxies []string) *gin.Engine {
+		router := gin.New()
+		if err := router.SetTrustedProxies(trustedProxies); err != nil {
+			t.Fatal(err)
+		}
+		router.Use(NewRateLimiter(cfg, nil, testLogger()).Middleware())
+		router.GET("/instances", func(c *gin.Context) { c.Status(http.StatusOK) })
+		return router
+	}
+	send := func(router *gin.Engine, remoteAddr, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/instances", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	// Without trusted proxies, a made-up X-Forwarded-For does not buy a fresh bucket
+	router := newRouter(nil)
+	if code := send(router, "10.0.0.1:1234", "203.0.113.1"); code != http.StatusOK {
+		t.Errorf("Expected the first request to pass, got %d", code)
+	}
+	if code := send(router, "10.0.0.1:1234", "203.0.113.2"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected a spoofed X-Forwarded-For to share the peer's bucket, got %d", code)
+	}
+
+	// Behind a trusted proxy, each forwarded client has its own bucket
+	router = newRouter([]string{"10.0.0.1"})
+	if code := send(router, "10.0.0.1:1234", "203.0.113.1"); code != http.StatusOK {
+		t.Errorf("Expected the first forwarded client to pass, got %d", code)
+	}
+	if code := send(router, "10.0.0.1:1234", "203.0.113.2"); code != http.StatusOK {
+		t.Errorf("Expected another forwarded client to pass, got %d", code)
+	}
+	if code := send(router, "10.0.0.9:1234", "203.0.113.3"); code != http.StatusOK {
+		t.Errorf("Expected a direct client to pass, got %d", code)
+	}
+	if code := send(router, "10.0.0.9:1234", "203.0.113.4"); code != http.StatusTooManyRequests {
+		t.Errorf("Expected an untrusted peer to be limited by its own address, got %d", code)
+	}
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// testAuthenticator returns an authenticator accepting each key as its role
+func testAuthenticator(t *testing.T, keys map[string]authz.Role) *authz.Authenticator {
+	t.Helper()
+	var entries []map[string]any
+	for key, role := range keys {
+		entries = append(entries, testKeyEntry(key, role))
+	}
+	return testKeysAuthenticator(t, entries)
+}
+
+// testKeyEntry returns an API keys file entry accepting key as role
+func testKeyEntry(key string, role authz.Role, workspaces ...string) map[string]any {
+	sum := sha256.Sum256([]byte(key))
+	return map[string]any{"name": key, "key_sha256": hex.EncodeToString(sum[:]), "role": string(role), "workspaces": workspaces}
+}
+
+// testKeysAuthenticator returns an authenticator reading entries from an API keys file
+func testKeysAuthenticator(t *testing.T, entries []map[string]any) *authz.Authenticator {
+	t.Helper()
+	data, _ := json.Marshal(map[string]any{"keys": entries})
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	authenticator, err := authz.New(config.AuthzConfig{APIKeysFile: path}, testLogger())
+	if err != nil {
+		t.Fatalf("Expected API keys to load, got %v", err)
+	}
+	return authenticator
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/backends"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// readinessTimeout bounds each dependency check, so a hung dependency fails the probe instead of
+// timing it out
+const readinessTimeout = 2 * time.Second
+
+// ReadinessCheck returns an error when a dependency the manager needs is unusable
+type ReadinessCheck func(ctx context.Context) error
+
+// AddReadinessCheck makes /readyz fail while check does
+func (h *Handler) AddReadinessCheck(name string, check ReadinessCheck) {
+	if h.readinessChecks == nil {
+		h.readinessChecks = make(map[string]ReadinessCheck)
+	}
+	h.readinessChecks[name] = check
+}
+
+// livez reports that the process is up and serving; it checks nothing else, so a broken
+// dependency never gets the manager restarted
+func (h *Handler) livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "alive",
+		"uptime": time.Since(h.startTime).String(),
+	})
+}
+
+// readyz checks every dependency concurrently and answers 503 while any of them fails, so
+// orchestrators stop sending traffic to a manager whose runtime is broken
+func (h *Handler) readyz(c *gin.Context) {
+	checks := h.dependencyChecks()
+	response := models.ReadinessResponse{
+		Status:       models.ReadinessReady,
+		Dependencies: make(map[string]models.DependencyStatus, len(checks)),
+		Timestamp:    time.Now(),
+	}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status := runReadinessCheck(c.Request.Context(), check)
+			mutex.Lock()
+			response.Dependencies[name] = status
+			if status.Status != models.DependencyOK {
+				response.Status = models.ReadinessNotReady
+			}
+			mutex.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	code := http.StatusOK
+	if response.Status != models.ReadinessReady {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, response)
+}
+
+// dependencyChecks returns the backend's and proxy's checks along with those added by the caller
+func (h *Handler) dependencyChecks() map[string]ReadinessCheck {
+	checks := make(map[string]ReadinessCheck, len(h.readinessChecks)+3)
+	if checker, ok := h.backend.(backends.ReadinessChecker); ok {
+		checks["runtime"] = checker.CheckReady
+	}
+	if h.proxy != nil {
+		checks["proxy"] = h.proxy.Ready
+	}
+	if h.containerManager != nil {
+		checks["state"] = func(context.Context) error { return h.containerManager.CheckStateStore() }
+	}
+	for name, check := range h.readinessChecks {
+		checks[name] = check
+	}
+	return checks
+}
+
+// runReadinessCheck runs check within readinessTimeout and times it
+func runReadinessCheck(ctx context.Context, check ReadinessCheck) models.DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	started := time.Now()
+	err := check(ctx)
+	status := models.DependencyStatus{
+		Status:    models.DependencyOK,
+		LatencyMS: time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		status.Status = models.DependencyFailing
+		status.Error = err.Error()
+	}
+	return status
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/requestid"
+)
+
+// RequestIDMiddleware accepts or generates an X-Request-ID, picks up the W3C traceparent,
+// stores both in the request context and echoes the request ID in the response
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := requestid.Sanitize(c.GetHeader(requestid.Header))
+		if id == "" {
+			id = requestid.New()
+		}
+
+		ctx := requestid.WithRequestID(c.Request.Context(), id)
+		ctx = requestid.WithTraceID(ctx, requestid.ParseTraceparent(c.GetHeader(requestid.TraceparentHeader)))
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Set("request_id", id)
+		c.Header(requestid.Header, id)
+
+		c.Next()
+	}
+}
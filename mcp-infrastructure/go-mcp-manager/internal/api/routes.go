@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// refreshContainerRoute re-registers a container's proxy route against its current IP
+func (h *Handler) refreshContainerRoute(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	if _, err := h.containerManager.GetContainer(serviceName); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.containerManager.RefreshRoute(c.Request.Context(), serviceName)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, models.ErrorResponse{
+			Error:   "route_refresh_failed",
+			Code:    http.StatusBadGateway,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// getConnectionContract describes the transport, timeouts and session affinity clients should expect
+func (h *Handler) getConnectionContract(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	contract, err := h.containerManager.GetConnectionContract(serviceName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, contract)
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// getContainerReplicas reports the replicas of a scaled instance and the load they were scaled on
+func (h *Handler) getContainerReplicas(c *gin.Context) {
+	status, err := h.containerManager.GetScaling(c.Param("service"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/backends"
+	"github.com/agentarea/mcp-manager/internal/serverjson"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// serverJSONRequest creates an instance from an MCP registry server.json document
+type serverJSONRequest struct {
+	InstanceID  string             `json:"instance_id" binding:"required"`
+	Name        string             `json:"name" binding:"required"`
+	ServiceName string             `json:"service_name" binding:"required"`
+	WorkspaceID string             `json:"workspace_id" binding:"required"`
+	Server      *serverjson.Server `json:"server" binding:"required"`
+	// RegistryType picks the package to run when the server publishes several
+	RegistryType string            `json:"registry_type,omitempty"`
+	Environment  map[string]string `json:"environment,omitempty"`
+	Arguments    map[string]string `json:"arguments,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+}
+
+// createInstanceFromServerJSON translates a registry server.json into an image or npx/uvx spec
+// and creates it like POST /instances, so callers need not translate registry entries themselves
+func (h *Handler) createInstanceFromServerJSON(c *gin.Context) {
+	var req serverJSONRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	translation, err := serverjson.Translate(req.Server, serverjson.Options{
+		RegistryType: req.RegistryType,
+		Environment:  req.Environment,
+		Arguments:    req.Arguments,
+		Headers:      req.Headers,
+	})
+	var missing *serverjson.MissingInputsError
+	if errors.As(err, &missing) {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   "missing_required_inputs",
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   "unsupported_server_json",
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	spec := &backends.InstanceSpec{
+		InstanceID:  req.InstanceID,
+		Name:        req.Name,
+		ServiceName: req.ServiceName,
+		WorkspaceID: req.WorkspaceID,
+		Image:       translation.Image,
+		Runtime:     translation.Runtime,
+		Port:        translation.Port,
+		Command:     translation.Command,
+		Environment: translation.Environment,
+		EnvSchema:   translation.EnvSchema,
+		Route: &models.RouteConfig{
+			Transport:      translation.Transport,
+			RequestHeaders: translation.RequestHeaders,
+		},
+
+		IdempotencyKey: c.GetHeader(idempotencyKeyHeader),
+	}
+	h.createInstanceFromSpec(c, spec)
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// proxySession answers the proxy's session check for an MCP route: 200 lets the request through,
+// a redirect sends it back through the proxy once its session's container is routed, and anything
+// else is returned to the client instead. Over mutual TLS every caller presented a verified
+// certificate, like the proxy does.
+func (h *Handler) proxySession(c *gin.Context) {
+	clientVerified := c.Request.TLS != nil && len(c.Request.TLS.PeerCertificates) > 0
+	status, message := h.containerManager.ProxySession(c.Request.Context(), c.Param("slug"), c.Request.Header, clientVerified)
+	switch status {
+	case 0:
+		c.Status(http.StatusOK)
+	case http.StatusUnauthorized:
+		c.Header("WWW-Authenticate", `Bearer realm="mcp"`)
+		c.JSON(status, models.ErrorResponse{
+			Error:   "unauthorized",
+			Code:    status,
+			Message: message,
+		})
+	case http.StatusTemporaryRedirect:
+		location := c.GetHeader("X-Forwarded-Uri")
+		if location == "" {
+			location = "/mcp/" + c.Param("slug")
+		}
+		c.Redirect(http.StatusTemporaryRedirect, location)
+	case http.StatusBadRequest:
+		c.JSON(status, models.ErrorResponse{
+			Error:   "session_required",
+			Code:    status,
+			Message: message,
+		})
+	default:
+		c.JSON(status, models.ErrorResponse{
+			Error:   "session_unavailable",
+			Code:    status,
+			Message: message,
+		})
+	}
+}
+
+// listContainerSessions lists the containers serving the sessions of an instance with per-session
+// isolation
+func (h *Handler) listContainerSessions(c *gin.Context) {
+	sessions, err := h.containerManager.ListSessions(c.Param("service"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// listSlugs lists the slugs assigned to instances, which keep them when recreated, and the
+// conflicts between assignments and routes
+func (h *Handler) listSlugs(c *gin.Context) {
+	slugs := slices.DeleteFunc(h.containerManager.SlugAssignments(), func(assignment models.SlugAssignment) bool {
+		return !visibleWorkspace(c, assignment.WorkspaceID)
+	})
+
+	response := models.SlugsResponse{Slugs: slugs}
+	for _, assignment := range slugs {
+		if assignment.Conflict != "" {
+			response.Conflicts++
+		}
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// renameContainerSlug moves a container's route to another slug
+func (h *Handler) renameContainerSlug(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	var req models.RenameSlugRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if _, err := h.containerManager.GetContainer(serviceName); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	renamed, err := h.containerManager.RenameSlug(c.Request.Context(), serviceName, req.Slug)
+	switch {
+	case errors.Is(err, container.ErrInvalidSlug):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_slug",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	case errors.Is(err, container.ErrSlugConflict):
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "slug_conflict",
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "slug_rename_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusOK, renamed)
+	}
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// getContainerSpec returns the spec a container runs with, credentials masked, and whether each
+// field came from the request, a template or a default, to explain how it ended up configured
+func (h *Handler) getContainerSpec(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	spec, err := h.containerManager.ContainerSpec(serviceName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, spec)
+}
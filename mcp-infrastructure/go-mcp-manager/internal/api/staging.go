@@ -0,0 +1,115 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// stageContainer creates a staging copy of a container with a new version under a preview URL
+func (h *Handler) stageContainer(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	var req models.StageContainerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if _, err := h.containerManager.GetContainer(serviceName); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	staged, err := h.containerManager.StageContainer(c.Request.Context(), serviceName, req)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse{
+			Error:   "staging_failed",
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, staged)
+}
+
+// promoteContainer moves a container's production URL to its verified staging container
+func (h *Handler) promoteContainer(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	var req struct {
+		// Force promotes without verifying the staging container again
+		Force bool `json:"force,omitempty"`
+	}
+	// The body is optional
+	_ = c.ShouldBindJSON(&req)
+
+	if _, err := h.containerManager.GetContainer(serviceName); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.containerManager.PromoteContainer(c.Request.Context(), serviceName, req.Force)
+	if errors.Is(err, container.ErrStagingCheckFailed) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "staging_check_failed",
+			"code":    http.StatusUnprocessableEntity,
+			"message": err.Error(),
+			"check":   result.Check,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "promotion_failed",
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// rollbackContainer moves a container's production URL back to the container it replaced
+func (h *Handler) rollbackContainer(c *gin.Context) {
+	serviceName := c.Param("service")
+
+	if _, err := h.containerManager.GetContainer(serviceName); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.containerManager.RollbackContainer(c.Request.Context(), serviceName)
+	if err != nil {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "rollback_failed",
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
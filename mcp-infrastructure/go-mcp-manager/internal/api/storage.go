@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// getStorageUsage reports image storage usage and per-image and per-container disk consumption
+func (h *Handler) getStorageUsage(c *gin.Context) {
+	usage, err := h.containerManager.GetStorageUsage(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "storage_usage_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
+
+// getGCReport returns the report of the last image garbage collection
+func (h *Handler) getGCReport(c *gin.Context) {
+	report := h.containerManager.LastGCReport()
+	if report == nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "gc_not_run",
+			Code:    http.StatusNotFound,
+			Message: "no garbage collection has run yet",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// runGC removes unused images and build cache now, regardless of the disk usage threshold
+func (h *Handler) runGC(c *gin.Context) {
+	var req struct {
+		DryRun bool `json:"dry_run,omitempty"`
+	}
+	// The body is optional
+	_ = c.ShouldBindJSON(&req)
+
+	report, err := h.containerManager.RunGC(c.Request.Context(), true, req.DryRun)
+	if errors.Is(err, container.ErrGCRunning) {
+		c.JSON(http.StatusConflict, models.ErrorResponse{
+			Error:   "gc_running",
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "gc_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
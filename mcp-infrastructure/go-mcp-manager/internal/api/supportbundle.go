@@ -0,0 +1,36 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// getSupportBundle returns a gzipped tarball of logs, redacted configuration, state, events, routes
+// and per-container inspect data and health history to attach to a bug report
+func (h *Handler) getSupportBundle(c *gin.Context) {
+	// The bundle is built in memory so that a failure can still be answered with an error
+	var bundle bytes.Buffer
+	if err := h.containerManager.WriteSupportBundle(c.Request.Context(), &bundle, h.version, h.logBuffer.Bytes()); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{
+			Error:   "support_bundle_failed",
+			Code:    http.StatusInternalServerError,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logger.InfoContext(c.Request.Context(), "Support bundle generated",
+		slog.Int("size_bytes", bundle.Len()),
+		slog.String("caller", requestPrincipal(c).Subject))
+
+	filename := fmt.Sprintf("support-bundle-%s.tar.gz", time.Now().UTC().Format("20060102T150405Z"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "application/gzip", bundle.Bytes())
+}
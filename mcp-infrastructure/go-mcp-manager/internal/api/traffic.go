@@ -0,0 +1,48 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/container"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// getContainerTraffic returns the request counts, status codes, latency and bytes proxied to a container
+func (h *Handler) getContainerTraffic(c *gin.Context) {
+	stats, err := h.containerManager.GetTraffic(c.Param("service"))
+	if errors.Is(err, container.ErrTrafficNotCollected) {
+		respondTrafficNotCollected(c, err)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "container_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// getTrafficUsage returns proxied traffic per instance and per workspace, optionally for one workspace
+func (h *Handler) getTrafficUsage(c *gin.Context) {
+	usage, err := h.containerManager.GetTrafficUsage(c.Query("workspace_id"))
+	if err != nil {
+		respondTrafficNotCollected(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
+
+// respondTrafficNotCollected reports that the proxy access log is not configured for accounting
+func respondTrafficNotCollected(c *gin.Context, err error) {
+	c.JSON(http.StatusServiceUnavailable, models.ErrorResponse{
+		Error:   "traffic_not_collected",
+		Code:    http.StatusServiceUnavailable,
+		Message: err.Error(),
+	})
+}
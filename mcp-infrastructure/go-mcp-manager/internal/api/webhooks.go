@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/agentarea/mcp-manager/internal/webhooks"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// listWebhooks returns all registered lifecycle webhooks
+func (h *Handler) listWebhooks(c *gin.Context) {
+	registered := h.containerManager.Webhooks().List()
+
+	c.JSON(http.StatusOK, gin.H{
+		"webhooks": registered,
+		"total":    len(registered),
+	})
+}
+
+// createWebhook registers a new lifecycle webhook
+func (h *Handler) createWebhook(c *gin.Context) {
+	var req struct {
+		URL    string               `json:"url" binding:"required"`
+		Secret string               `json:"secret,omitempty"`
+		Events []webhooks.EventType `json:"events,omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_request",
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if parsed, err := url.Parse(req.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{
+			Error:   "invalid_webhook_url",
+			Code:    http.StatusBadRequest,
+			Message: "url must be an absolute http or https URL",
+		})
+		return
+	}
+
+	webhook := h.containerManager.Webhooks().Register(req.URL, req.Secret, req.Events)
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// deleteWebhook removes a registered webhook
+func (h *Handler) deleteWebhook(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.containerManager.Webhooks().Unregister(id); err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{
+			Error:   "webhook_not_found",
+			Code:    http.StatusNotFound,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Webhook deleted successfully",
+		"webhook_id": id,
+	})
+}
@@ -0,0 +1,162 @@
+// Package authz authenticates callers of the management API by API key or JWT and maps them to
+// roles, optionally scoped to workspaces.
+package authz
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+)
+
+// Role grants a fixed set of permissions
+type Role string
+
+// Roles a caller can be bound to
+const (
+	RoleAdmin    Role = "admin"
+	RoleOperator Role = "operator"
+	RoleViewer   Role = "viewer"
+)
+
+// Permission is what a route requires of the caller's role
+type Permission string
+
+// Permissions, from weakest to strongest
+const (
+	// PermissionRead reads instances and their health, logs and traffic
+	PermissionRead Permission = "read"
+	// PermissionWrite creates, changes and deletes instances
+	PermissionWrite Permission = "write"
+	// PermissionAdmin acts on the whole host: backups, cordoning, GC, webhooks and adoption
+	PermissionAdmin Permission = "admin"
+)
+
+// Sources a principal is authenticated from
+const (
+	SourceAPIKey    = "api_key"
+	SourceJWT       = "jwt"
+	SourceAnonymous = "anonymous"
+)
+
+var (
+	// ErrNoCredentials is returned when a request carries neither an API key nor a bearer token
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned for unknown API keys and tokens that fail verification
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// ParseRole returns the role named by s
+func ParseRole(s string) (Role, error) {
+	switch role := Role(strings.ToLower(strings.TrimSpace(s))); role {
+	case RoleAdmin, RoleOperator, RoleViewer:
+		return role, nil
+	default:
+		return "", fmt.Errorf("unknown role %q, expected %s, %s or %s", s, RoleAdmin, RoleOperator, RoleViewer)
+	}
+}
+
+// Permissions returns the permissions the role grants
+func (r Role) Permissions() []Permission {
+	switch r {
+	case RoleAdmin:
+		return []Permission{PermissionRead, PermissionWrite, PermissionAdmin}
+	case RoleOperator:
+		return []Permission{PermissionRead, PermissionWrite}
+	case RoleViewer:
+		return []Permission{PermissionRead}
+	default:
+		return nil
+	}
+}
+
+// Allows reports whether the role grants permission
+func (r Role) Allows(permission Permission) bool {
+	return slices.Contains(r.Permissions(), permission)
+}
+
+// Principal is an authenticated caller
+type Principal struct {
+	Subject string
+	Role    Role
+	// Workspaces limits the caller to instances of these workspaces; empty allows every workspace
+	Workspaces []string
+	Source     string
+}
+
+// Anonymous is the principal of every request when authorization is not configured
+var Anonymous = &Principal{Subject: "anonymous", Role: RoleAdmin, Source: SourceAnonymous}
+
+// Scoped reports whether the principal is limited to some workspaces
+func (p *Principal) Scoped() bool {
+	return len(p.Workspaces) > 0
+}
+
+// CanAccessWorkspace reports whether the principal may act on instances of workspaceID. Scoped
+// principals cannot reach instances outside any workspace.
+func (p *Principal) CanAccessWorkspace(workspaceID string) bool {
+	if !p.Scoped() {
+		return true
+	}
+	return workspaceID != "" && slices.Contains(p.Workspaces, workspaceID)
+}
+
+// Authenticator identifies API callers from their API key or JWT
+type Authenticator struct {
+	keys   map[string]*Principal
+	jwt    *jwtVerifier
+	logger *slog.Logger
+}
+
+// New creates an authenticator from the configured API keys file and JWKS
+func New(cfg config.AuthzConfig, logger *slog.Logger) (*Authenticator, error) {
+	authenticator := &Authenticator{logger: logger}
+
+	if cfg.APIKeysFile != "" {
+		keys, err := loadAPIKeys(cfg.APIKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		authenticator.keys = keys
+	}
+	if cfg.JWKSURL != "" {
+		authenticator.jwt = newJWTVerifier(cfg)
+	}
+	return authenticator, nil
+}
+
+// Authenticate returns the principal of a request. API keys are read from X-API-Key or a bearer
+// token; bearer tokens shaped like a JWT are verified against the JWKS.
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return a.authenticateKey(key)
+	}
+
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return nil, ErrNoCredentials
+	}
+	token = strings.TrimSpace(token)
+	if a.jwt != nil && strings.Count(token, ".") == 2 {
+		principal, err := a.jwt.verify(r.Context(), token)
+		if err != nil {
+			a.logger.Debug("Rejected bearer token", slog.String("error", err.Error()))
+			return nil, ErrInvalidCredentials
+		}
+		return principal, nil
+	}
+	return a.authenticateKey(token)
+}
+
+// authenticateKey looks up the principal an API key is bound to
+func (a *Authenticator) authenticateKey(key string) (*Principal, error) {
+	principal, exists := a.keys[hashAPIKey(key)]
+	if !exists {
+		return nil, ErrInvalidCredentials
+	}
+	return principal, nil
+}
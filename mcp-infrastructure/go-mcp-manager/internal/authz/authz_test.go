@@ -0,0 +1,203 @@
+package authz
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+)
+
+func TestRoles(t *testing.T) {
+	if !RoleOperator.Allows(PermissionWrite) || RoleOperator.Allows(PermissionAdmin) {
+		t.Error("Expected operators to change instances but not the host")
+	}
+	if RoleViewer.Allows(PermissionWrite) || !RoleViewer.Allows(PermissionRead) {
+		t.Error("Expected viewers to only read")
+	}
+	if _, err := ParseRole("superuser"); err == nil {
+		t.Error("Expected unknown roles to be rejected")
+	}
+
+	scoped := &Principal{Role: RoleOperator, Workspaces: []string{"ws-1"}}
+	if !scoped.CanAccessWorkspace("ws-1") || scoped.CanAccessWorkspace("ws-2") || scoped.CanAccessWorkspace("") {
+		t.Error("Expected a scoped principal to reach only its workspaces")
+	}
+	if !Anonymous.CanAccessWorkspace("ws-2") {
+		t.Error("Expected an unscoped principal to reach every workspace")
+	}
+}
+
+func TestAPIKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	writeJSON(t, path, map[string]any{"keys": []map[string]any{
+		{"name": "core-api", "key_sha256": hashAPIKey("core-secret"), "role": "operator"},
+		{"name": "dashboard", "key_sha256": hashAPIKey("viewer-secret"), "role": "viewer", "workspaces": []string{"ws-1"}},
+	}})
+	authenticator, err := New(config.AuthzConfig{APIKeysFile: path}, testLogger())
+	if err != nil {
+		t.Fatalf("Expected API keys to load, got %v", err)
+	}
+
+	principal, err := authenticator.Authenticate(request("X-API-Key", "core-secret"))
+	if err != nil || principal.Subject != "core-api" || principal.Role != RoleOperator || principal.Scoped() {
+		t.Errorf("Expected the core-api operator, got %+v, %v", principal, err)
+	}
+	principal, err = authenticator.Authenticate(request("Authorization", "Bearer viewer-secret"))
+	if err != nil || principal.Role != RoleViewer || !principal.Scoped() {
+		t.Errorf("Expected the scoped dashboard viewer, got %+v, %v", principal, err)
+	}
+	if _, err := authenticator.Authenticate(request("X-API-Key", "guess")); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected an unknown key to be rejected, got %v", err)
+	}
+	if _, err := authenticator.Authenticate(request("", "")); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected a request without credentials to be rejected, got %v", err)
+	}
+
+	writeJSON(t, path, map[string]any{"keys": []map[string]any{{"name": "plain", "key_sha256": "core-secret", "role": "admin"}}})
+	if _, err := New(config.AuthzConfig{APIKeysFile: path}, testLogger()); err == nil {
+		t.Error("Expected keys not stored as SHA-256 to be rejected")
+	}
+}
+
+func TestJWT(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	fetches := 0
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]any{
+			{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+			{"kty": "RSA", "kid": "rsa-1", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+		}})
+	}))
+	defer jwks.Close()
+
+	authenticator, err := New(config.AuthzConfig{
+		JWKSURL:         jwks.URL,
+		JWKSCacheTTL:    time.Hour,
+		JWTIssuer:       "https://auth.agentarea.dev",
+		JWTAudience:     "mcp-manager",
+		RoleClaim:       "role",
+		WorkspacesClaim: "workspaces",
+	}, testLogger())
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+
+	claims := map[string]any{
+		"sub":        "alice",
+		"iss":        "https://auth.agentarea.dev",
+		"aud":        []string{"mcp-manager"},
+		"exp":        time.Now().Add(time.Hour).Unix(),
+		"role":       "operator",
+		"workspaces": []string{"ws-1"},
+	}
+	principal, err := authenticator.Authenticate(request("Authorization", "Bearer "+signES256(t, ecKey, "ec-1", claims)))
+	if err != nil || principal.Subject != "alice" || principal.Role != RoleOperator || principal.Source != SourceJWT || !principal.CanAccessWorkspace("ws-1") {
+		t.Errorf("Expected the ES256 token to authenticate alice, got %+v, %v", principal, err)
+	}
+	if _, err := authenticator.Authenticate(request("Authorization", "Bearer "+signRS256(t, rsaKey, "rsa-1", claims))); err != nil {
+		t.Errorf("Expected the RS256 token to authenticate, got %v", err)
+	}
+	if fetches != 1 {
+		t.Errorf("Expected the JWKS to be fetched once, got %d", fetches)
+	}
+
+	rejected := map[string]map[string]any{
+		"expired":        with(claims, "exp", time.Now().Add(-time.Hour).Unix()),
+		"wrong audience": with(claims, "aud", "other-service"),
+		"wrong issuer":   with(claims, "iss", "https://evil.example"),
+		"unknown role":   with(claims, "role", "root"),
+	}
+	for name, bad := range rejected {
+		if _, err := authenticator.Authenticate(request("Authorization", "Bearer "+signES256(t, ecKey, "ec-1", bad))); err == nil {
+			t.Errorf("Expected the %s token to be rejected", name)
+		}
+	}
+
+	// A token signed by another key under a known key ID fails verification
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if _, err := authenticator.Authenticate(request("Authorization", "Bearer "+signES256(t, otherKey, "ec-1", claims))); err == nil {
+		t.Error("Expected a forged token to be rejected")
+	}
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func request(header, value string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/containers", nil)
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	return req
+}
+
+func writeJSON(t *testing.T, path string, v any) {
+	t.Helper()
+	data, _ := json.Marshal(v)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func with(claims map[string]any, key string, value any) map[string]any {
+	copied := make(map[string]any, len(claims))
+	for k, v := range claims {
+		copied[k] = v
+	}
+	copied[key] = value
+	return copied
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signingInput(t *testing.T, alg, kid string, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("Failed to encode claims: %v", err)
+	}
+	return b64(header) + "." + b64(payload)
+}
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]any) string {
+	input := signingInput(t, "ES256", kid, claims)
+	digest := sha256.Sum256([]byte(input))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	signature := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	return input + "." + b64(signature)
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	input := signingInput(t, "RS256", kid, claims)
+	digest := sha256.Sum256([]byte(input))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return input + "." + b64(signature)
+}
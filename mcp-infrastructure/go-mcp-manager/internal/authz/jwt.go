@@ -0,0 +1,52 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/internal/jwks"
+)
+
+// jwtVerifier maps JWTs signed by the configured JWKS to principals
+type jwtVerifier struct {
+	cfg  config.AuthzConfig
+	keys *jwks.KeySet
+}
+
+// newJWTVerifier creates a verifier; the JWKS is fetched on first use
+func newJWTVerifier(cfg config.AuthzConfig) *jwtVerifier {
+	return &jwtVerifier{cfg: cfg, keys: jwks.New(cfg.JWKSURL, cfg.JWKSCacheTTL)}
+}
+
+// verify checks a token's signature and claims and returns the principal it describes
+func (v *jwtVerifier) verify(ctx context.Context, token string) (*Principal, error) {
+	claims, err := v.keys.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if v.cfg.JWTIssuer != "" && claims["iss"] != v.cfg.JWTIssuer {
+		return nil, fmt.Errorf("unexpected issuer %v", claims["iss"])
+	}
+	if v.cfg.JWTAudience != "" && !slices.Contains(jwks.Strings(claims["aud"]), v.cfg.JWTAudience) {
+		return nil, fmt.Errorf("token is not meant for audience %q", v.cfg.JWTAudience)
+	}
+	return v.principal(claims)
+}
+
+// principal maps a token's role and workspaces claims to a principal
+func (v *jwtVerifier) principal(claims map[string]any) (*Principal, error) {
+	roleClaim, _ := claims[v.cfg.RoleClaim].(string)
+	role, err := ParseRole(roleClaim)
+	if err != nil {
+		return nil, fmt.Errorf("claim %q: %w", v.cfg.RoleClaim, err)
+	}
+	subject, _ := claims["sub"].(string)
+	return &Principal{
+		Subject:    subject,
+		Role:       role,
+		Workspaces: jwks.Strings(claims[v.cfg.WorkspacesClaim]),
+		Source:     SourceJWT,
+	}, nil
+}
@@ -0,0 +1,67 @@
+package authz
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// apiKeysFile is the JSON document binding API keys to roles. Keys are stored as their SHA-256
+// so the file does not hold usable credentials.
+type apiKeysFile struct {
+	Keys []apiKeyEntry `json:"keys"`
+}
+
+// apiKeyEntry binds one API key to a role
+type apiKeyEntry struct {
+	Name       string   `json:"name"`
+	KeySHA256  string   `json:"key_sha256"`
+	Role       string   `json:"role"`
+	Workspaces []string `json:"workspaces,omitempty"`
+}
+
+// loadAPIKeys reads the API keys file, indexed by key hash
+func loadAPIKeys(path string) (map[string]*Principal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys file: %w", err)
+	}
+	var file apiKeysFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse API keys file: %w", err)
+	}
+
+	keys := make(map[string]*Principal, len(file.Keys))
+	for i, entry := range file.Keys {
+		if entry.Name == "" {
+			return nil, fmt.Errorf("API key %d has no name", i)
+		}
+		hash := strings.ToLower(entry.KeySHA256)
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("API key %q: key_sha256 must be a hex SHA-256 digest", entry.Name)
+		}
+		if _, exists := keys[hash]; exists {
+			return nil, fmt.Errorf("API key %q is listed twice", entry.Name)
+		}
+		role, err := ParseRole(entry.Role)
+		if err != nil {
+			return nil, fmt.Errorf("API key %q: %w", entry.Name, err)
+		}
+		keys[hash] = &Principal{
+			Subject:    entry.Name,
+			Role:       role,
+			Workspaces: entry.Workspaces,
+			Source:     SourceAPIKey,
+		}
+	}
+	return keys, nil
+}
+
+// hashAPIKey returns the hex SHA-256 an API key is stored as
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -8,7 +8,7 @@ import (
 
 	"github.com/agentarea/mcp-manager/internal/config"
 	"github.com/agentarea/mcp-manager/internal/container"
-	"github.com/agentarea/mcp-manager/internal/models"
+	"github.com/agentarea/mcp-manager/pkg/models"
 )
 
 // DockerBackend implements the Backend interface using the existing container.Manager (Podman)
@@ -36,13 +36,13 @@ func (d *DockerBackend) GetManager() *container.Manager {
 
 // Initialize initializes the Docker backend
 func (d *DockerBackend) Initialize(ctx context.Context) error {
-	d.logger.Info("Initializing Docker backend")
+	d.logger.InfoContext(ctx, "Initializing Docker backend")
 	return d.manager.Initialize(ctx)
 }
 
 // CreateInstance creates a new MCP server instance using the existing container manager
 func (d *DockerBackend) CreateInstance(ctx context.Context, spec *InstanceSpec) (*InstanceResult, error) {
-	d.logger.Info("Creating instance with Docker backend",
+	d.logger.InfoContext(ctx, "Creating instance with Docker backend",
 		slog.String("name", spec.Name),
 		slog.String("image", spec.Image))
 
@@ -50,9 +50,15 @@ func (d *DockerBackend) CreateInstance(ctx context.Context, spec *InstanceSpec)
 	req := d.specToCreateRequest(spec)
 
 	// Use existing manager to create container
-	container, err := d.manager.CreateContainer(ctx, req)
+	var container *models.Container
+	var err error
+	if spec.IdempotencyKey != "" {
+		container, err = d.manager.CreateContainerIdempotent(ctx, spec.IdempotencyKey, req)
+	} else {
+		container, err = d.manager.CreateContainer(ctx, req)
+	}
 	if err != nil {
-		d.logger.Error("Failed to create container via manager",
+		d.logger.ErrorContext(ctx, "Failed to create container via manager",
 			slog.String("name", spec.Name),
 			slog.String("error", err.Error()))
 		return nil, fmt.Errorf("failed to create container: %w", err)
@@ -67,7 +73,7 @@ func (d *DockerBackend) CreateInstance(ctx context.Context, spec *InstanceSpec)
 		CreatedAt: container.CreatedAt,
 	}
 
-	d.logger.Info("Successfully created instance",
+	d.logger.InfoContext(ctx, "Successfully created instance",
 		slog.String("id", result.ID),
 		slog.String("name", result.Name),
 		slog.String("url", result.URL))
@@ -77,7 +83,7 @@ func (d *DockerBackend) CreateInstance(ctx context.Context, spec *InstanceSpec)
 
 // DeleteInstance removes an MCP server instance
 func (d *DockerBackend) DeleteInstance(ctx context.Context, instanceID string) error {
-	d.logger.Info("Deleting instance with Docker backend",
+	d.logger.InfoContext(ctx, "Deleting instance with Docker backend",
 		slog.String("instance_id", instanceID))
 
 	// Find container by ID or service name
@@ -88,14 +94,14 @@ func (d *DockerBackend) DeleteInstance(ctx context.Context, instanceID string) e
 
 	err := d.manager.DeleteContainer(ctx, serviceName)
 	if err != nil {
-		d.logger.Error("Failed to delete container",
+		d.logger.ErrorContext(ctx, "Failed to delete container",
 			slog.String("instance_id", instanceID),
 			slog.String("service_name", serviceName),
 			slog.String("error", err.Error()))
 		return fmt.Errorf("failed to delete container: %w", err)
 	}
 
-	d.logger.Info("Successfully deleted instance",
+	d.logger.InfoContext(ctx, "Successfully deleted instance",
 		slog.String("instance_id", instanceID),
 		slog.String("service_name", serviceName))
 
@@ -117,7 +123,7 @@ func (d *DockerBackend) GetInstanceStatus(ctx context.Context, instanceID string
 	// Get real-time status
 	status, err := d.manager.GetContainerStatus(ctx, serviceName)
 	if err != nil {
-		d.logger.Warn("Failed to get real-time status, using cached",
+		d.logger.WarnContext(ctx, "Failed to get real-time status, using cached",
 			slog.String("service_name", serviceName),
 			slog.String("error", err.Error()))
 		status = container.Status
@@ -128,6 +134,8 @@ func (d *DockerBackend) GetInstanceStatus(ctx context.Context, instanceID string
 	if healthResult, exists := d.manager.GetContainerHealthStatus(serviceName); exists {
 		healthStatus = &HealthCheckResult{
 			Healthy:       healthResult.Healthy,
+			Live:          healthResult.Live,
+			Ready:         healthResult.Ready,
 			Status:        string(healthResult.Status),
 			HTTPReachable: healthResult.HTTPReachable,
 			ResponseTime:  healthResult.ResponseTime,
@@ -141,6 +149,7 @@ func (d *DockerBackend) GetInstanceStatus(ctx context.Context, instanceID string
 
 	instanceStatus := &InstanceStatus{
 		ID:           container.ID,
+		InstanceID:   container.Environment["MCP_INSTANCE_ID"],
 		Name:         container.ServiceName,
 		ServiceName:  container.ServiceName,
 		Status:       string(status),
@@ -168,6 +177,8 @@ func (d *DockerBackend) ListInstances(ctx context.Context) ([]*InstanceStatus, e
 		if healthResult, exists := d.manager.GetContainerHealthStatus(container.ServiceName); exists {
 			healthStatus = &HealthCheckResult{
 				Healthy:       healthResult.Healthy,
+				Live:          healthResult.Live,
+				Ready:         healthResult.Ready,
 				Status:        string(healthResult.Status),
 				HTTPReachable: healthResult.HTTPReachable,
 				ResponseTime:  healthResult.ResponseTime,
@@ -181,6 +192,7 @@ func (d *DockerBackend) ListInstances(ctx context.Context) ([]*InstanceStatus, e
 
 		instance := &InstanceStatus{
 			ID:           container.ID,
+			InstanceID:   container.Environment["MCP_INSTANCE_ID"],
 			Name:         container.ServiceName,
 			ServiceName:  container.ServiceName,
 			Status:       string(container.Status),
@@ -202,7 +214,7 @@ func (d *DockerBackend) ListInstances(ctx context.Context) ([]*InstanceStatus, e
 
 // UpdateInstance updates an existing instance configuration
 func (d *DockerBackend) UpdateInstance(ctx context.Context, instanceID string, spec *InstanceSpec) error {
-	d.logger.Info("Updating instance with Docker backend",
+	d.logger.InfoContext(ctx, "Updating instance with Docker backend",
 		slog.String("instance_id", instanceID))
 
 	// For Docker backend, we need to recreate the container
@@ -220,6 +232,11 @@ func (d *DockerBackend) UpdateInstance(ctx context.Context, instanceID string, s
 	return nil
 }
 
+// PlanInstance returns the podman run the container manager would execute for spec
+func (d *DockerBackend) PlanInstance(ctx context.Context, spec *InstanceSpec) (*models.CreatePlan, error) {
+	return d.manager.PlanContainer(ctx, d.specToCreateRequest(spec))
+}
+
 // PerformHealthCheck performs health check on an instance
 func (d *DockerBackend) PerformHealthCheck(ctx context.Context, instanceID string) (*HealthCheckResult, error) {
 	serviceName := d.findServiceNameByID(instanceID)
@@ -242,6 +259,14 @@ func (d *DockerBackend) PerformHealthCheck(ctx context.Context, instanceID strin
 		result.Healthy = healthy
 	}
 
+	if live, ok := healthData["live"].(bool); ok {
+		result.Live = live
+	}
+
+	if ready, ok := healthData["ready"].(bool); ok {
+		result.Ready = ready
+	}
+
 	if status, ok := healthData["container_status"].(string); ok {
 		result.Status = status
 	}
@@ -271,29 +296,59 @@ func (d *DockerBackend) PerformHealthCheck(ctx context.Context, instanceID strin
 
 // Shutdown gracefully shuts down the Docker backend
 func (d *DockerBackend) Shutdown(ctx context.Context) error {
-	d.logger.Info("Shutting down Docker backend")
+	d.logger.InfoContext(ctx, "Shutting down Docker backend")
 	return d.manager.Shutdown(ctx)
 }
 
+// CheckReady reports whether podman answers
+func (d *DockerBackend) CheckReady(ctx context.Context) error {
+	return d.manager.CheckRuntime(ctx)
+}
+
+// InspectInstance returns podman's inspect data for an instance's container
+func (d *DockerBackend) InspectInstance(ctx context.Context, instanceID string) (*models.ContainerInspect, error) {
+	serviceName := d.findServiceNameByID(instanceID)
+	if serviceName == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInstanceNotFound, instanceID)
+	}
+	return d.manager.InspectContainer(ctx, serviceName)
+}
+
 // Helper methods
 
 // specToCreateRequest converts InstanceSpec to models.CreateContainerRequest
 func (d *DockerBackend) specToCreateRequest(spec *InstanceSpec) models.CreateContainerRequest {
 	req := models.CreateContainerRequest{
 		ServiceName: spec.ServiceName,
+		Slug:        spec.Slug,
 		Image:       spec.Image,
 		Port:        spec.Port,
 		Environment: spec.Environment,
 		Labels:      spec.Labels,
 		Command:     spec.Command,
-	}
-
-	// Add resource limits if specified
-	if spec.Resources.Limits.Memory != "" {
-		req.MemoryLimit = spec.Resources.Limits.Memory
-	}
-	if spec.Resources.Limits.CPU != "" {
-		req.CPULimit = spec.Resources.Limits.CPU
+		HealthCheck: spec.HealthCheck,
+		Route:       spec.Route,
+		Routing:     spec.Routing,
+		GPUs:        spec.GPUs,
+		Devices:     spec.Devices,
+		Resources:   spec.Resources.LimitsSpec(),
+		Security:    spec.Security,
+
+		WorkspaceID:   spec.WorkspaceID,
+		SharedNetwork: spec.SharedNetwork,
+		DNS:           spec.DNS,
+		ExtraHosts:    spec.ExtraHosts,
+		Proxy:         spec.Proxy,
+
+		Tmpfs:          spec.Tmpfs,
+		ScratchVolumes: spec.ScratchVolumes,
+		DependsOn:      spec.DependsOn,
+		Init:           spec.Init,
+		Files:          spec.Files,
+		Source:         spec.Source,
+		Runtime:        spec.Runtime,
+		LogShipping:    spec.LogShipping,
+		EnvSchema:      spec.EnvSchema,
 	}
 
 	// Add MCP-specific environment variables
@@ -309,26 +364,6 @@ func (d *DockerBackend) specToCreateRequest(spec *InstanceSpec) models.CreateCon
 
 // findServiceNameByID finds the service name by container ID or instance ID
 func (d *DockerBackend) findServiceNameByID(instanceID string) string {
-	containers := d.manager.ListContainers()
-	
-	for _, container := range containers {
-		// Check if ID matches
-		if container.ID == instanceID {
-			return container.ServiceName
-		}
-		
-		// Check if instance ID matches from environment
-		if mcpInstanceID, exists := container.Environment["MCP_INSTANCE_ID"]; exists {
-			if mcpInstanceID == instanceID {
-				return container.ServiceName
-			}
-		}
-		
-		// Check if service name matches directly
-		if container.ServiceName == instanceID {
-			return container.ServiceName
-		}
-	}
-	
-	return ""
-}
\ No newline at end of file
+	serviceName, _ := d.manager.ServiceNameForInstance(instanceID)
+	return serviceName
+}
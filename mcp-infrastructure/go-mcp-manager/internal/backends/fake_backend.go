@@ -0,0 +1,348 @@
+package backends
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	mathrand "math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/internal/events"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// StatusPublisher is the part of events.EventPublisher the fake backend reports transitions through
+type StatusPublisher interface {
+	PublishStarting(ctx context.Context, instanceID, name string) error
+	PublishRunning(ctx context.Context, instanceID, name, containerID, url string) error
+	PublishFailed(ctx context.Context, instanceID, name, errorMsg string) error
+}
+
+var _ StatusPublisher = (*events.EventPublisher)(nil)
+
+// Fake implements the Backend interface in memory, so the platform can run without podman or
+// Kubernetes. Instances start after a configurable delay and fail at a configurable rate.
+type Fake struct {
+	startupDelay time.Duration
+	failureRate  float64
+	proxyHost    string
+	publisher    StatusPublisher // Optional; nil keeps transitions local
+	logger       *slog.Logger
+
+	mutex     sync.RWMutex
+	instances map[string]*fakeInstance // by container ID
+	random    *mathrand.Rand
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+// fakeInstance is a simulated instance and the timer that finishes its startup
+type fakeInstance struct {
+	status  InstanceStatus
+	started *time.Timer
+}
+
+// NewFake creates an in-memory backend; publisher may be nil
+func NewFake(cfg *config.Config, publisher StatusPublisher, logger *slog.Logger) *Fake {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Fake{
+		startupDelay: cfg.Fake.StartupDelay,
+		failureRate:  cfg.Fake.FailureRate,
+		proxyHost:    cfg.Traefik.ProxyHost,
+		publisher:    publisher,
+		logger:       logger,
+		instances:    make(map[string]*fakeInstance),
+		random:       mathrand.New(mathrand.NewSource(time.Now().UnixNano())),
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// Initialize validates the simulation settings
+func (f *Fake) Initialize(ctx context.Context) error {
+	if f.failureRate < 0 || f.failureRate > 1 {
+		return fmt.Errorf("fake failure rate must be between 0 and 1, got %v", f.failureRate)
+	}
+	f.logger.WarnContext(ctx, "Using the fake backend: instances are simulated and nothing is run",
+		slog.Duration("startup_delay", f.startupDelay),
+		slog.Float64("failure_rate", f.failureRate))
+	return nil
+}
+
+// CreateInstance registers a simulated instance that finishes starting after the startup delay
+func (f *Fake) CreateInstance(ctx context.Context, spec *InstanceSpec) (*InstanceResult, error) {
+	serviceName := spec.ServiceName
+	if serviceName == "" {
+		serviceName = spec.Name
+	}
+	if serviceName == "" {
+		return nil, fmt.Errorf("service name is required")
+	}
+
+	f.mutex.Lock()
+	for _, existing := range f.instances {
+		if existing.status.ServiceName == serviceName {
+			f.mutex.Unlock()
+			return nil, fmt.Errorf("instance %s already exists", serviceName)
+		}
+	}
+
+	now := time.Now()
+	instance := &fakeInstance{status: InstanceStatus{
+		ID:          "fake-" + randomHex(6),
+		InstanceID:  spec.InstanceID,
+		Name:        spec.Name,
+		ServiceName: serviceName,
+		Status:      string(models.StatusStarting),
+		URL:         fmt.Sprintf("%s/mcp/%s", f.proxyHost, serviceName),
+		InternalURL: fmt.Sprintf("http://%s:%d", serviceName, spec.Port),
+		Image:       spec.Image,
+		Port:        spec.Port,
+		Environment: spec.Environment,
+		Labels:      spec.Labels,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}}
+	f.instances[instance.status.ID] = instance
+	containerID := instance.status.ID
+	instance.started = time.AfterFunc(f.startupDelay, func() { f.finishStartup(containerID) })
+	f.mutex.Unlock()
+
+	if f.publisher != nil && spec.InstanceID != "" {
+		if err := f.publisher.PublishStarting(ctx, spec.InstanceID, serviceName); err != nil {
+			f.logger.WarnContext(ctx, "Failed to publish starting status",
+				slog.String("instance_id", spec.InstanceID),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	f.logger.InfoContext(ctx, "Created fake instance",
+		slog.String("id", containerID),
+		slog.String("service_name", serviceName))
+
+	return &InstanceResult{
+		ID:          containerID,
+		Name:        serviceName,
+		URL:         instance.status.URL,
+		InternalURL: instance.status.InternalURL,
+		Status:      instance.status.Status,
+		CreatedAt:   now,
+	}, nil
+}
+
+// finishStartup moves a starting instance to running, or to error at the failure rate
+func (f *Fake) finishStartup(containerID string) {
+	f.mutex.Lock()
+	instance, exists := f.instances[containerID]
+	if !exists || f.ctx.Err() != nil {
+		f.mutex.Unlock()
+		return
+	}
+	failed := f.failureRate > 0 && f.random.Float64() < f.failureRate
+	if failed {
+		instance.status.Status = string(models.StatusError)
+	} else {
+		instance.status.Status = string(models.StatusRunning)
+	}
+	instance.status.UpdatedAt = time.Now()
+	status := instance.status
+	f.mutex.Unlock()
+
+	if f.publisher == nil || status.InstanceID == "" {
+		return
+	}
+	var err error
+	if failed {
+		err = f.publisher.PublishFailed(f.ctx, status.InstanceID, status.ServiceName, "simulated startup failure")
+	} else {
+		err = f.publisher.PublishRunning(f.ctx, status.InstanceID, status.ServiceName, status.ID, status.URL)
+	}
+	if err != nil {
+		f.logger.Warn("Failed to publish fake instance status",
+			slog.String("instance_id", status.InstanceID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// DeleteInstance removes a simulated instance
+func (f *Fake) DeleteInstance(ctx context.Context, instanceID string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	instance := f.find(instanceID)
+	if instance == nil {
+		return fmt.Errorf("instance not found: %s", instanceID)
+	}
+	instance.started.Stop()
+	delete(f.instances, instance.status.ID)
+
+	f.logger.InfoContext(ctx, "Deleted fake instance",
+		slog.String("id", instance.status.ID),
+		slog.String("service_name", instance.status.ServiceName))
+	return nil
+}
+
+// GetInstanceStatus returns a simulated instance by container ID, instance ID or service name
+func (f *Fake) GetInstanceStatus(ctx context.Context, instanceID string) (*InstanceStatus, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	instance := f.find(instanceID)
+	if instance == nil {
+		return nil, fmt.Errorf("instance not found: %s", instanceID)
+	}
+	status := instance.status
+	status.HealthStatus = f.health(instance)
+	return &status, nil
+}
+
+// ListInstances returns all simulated instances ordered by creation
+func (f *Fake) ListInstances(ctx context.Context) ([]*InstanceStatus, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	instances := make([]*InstanceStatus, 0, len(f.instances))
+	for _, instance := range f.instances {
+		status := instance.status
+		status.HealthStatus = f.health(instance)
+		instances = append(instances, &status)
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].CreatedAt.Before(instances[j].CreatedAt)
+	})
+	return instances, nil
+}
+
+// UpdateInstance recreates a simulated instance with the new spec, like the Docker backend
+func (f *Fake) UpdateInstance(ctx context.Context, instanceID string, spec *InstanceSpec) error {
+	if err := f.DeleteInstance(ctx, instanceID); err != nil {
+		return fmt.Errorf("failed to delete existing instance: %w", err)
+	}
+	if _, err := f.CreateInstance(ctx, spec); err != nil {
+		return fmt.Errorf("failed to recreate instance: %w", err)
+	}
+	return nil
+}
+
+// PlanInstance returns the simulated instance CreateInstance would register
+func (f *Fake) PlanInstance(ctx context.Context, spec *InstanceSpec) (*models.CreatePlan, error) {
+	serviceName := spec.ServiceName
+	if serviceName == "" {
+		serviceName = spec.Name
+	}
+	if serviceName == "" {
+		return nil, fmt.Errorf("service name is required")
+	}
+
+	environment := make(map[string]string, len(spec.Environment))
+	for key := range spec.Environment {
+		environment[key] = "***"
+	}
+	return &models.CreatePlan{
+		Backend:     string(BackendTypeFake),
+		ServiceName: serviceName,
+		Name:        serviceName,
+		Image:       spec.Image,
+		Slug:        serviceName,
+		URL:         fmt.Sprintf("%s/mcp/%s", f.proxyHost, serviceName),
+		Environment: environment,
+		Warnings:    []string{"fake backend: nothing would be run"},
+	}, nil
+}
+
+// PerformHealthCheck reports running instances as healthy
+func (f *Fake) PerformHealthCheck(ctx context.Context, instanceID string) (*HealthCheckResult, error) {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	instance := f.find(instanceID)
+	if instance == nil {
+		return nil, fmt.Errorf("instance not found: %s", instanceID)
+	}
+	return f.health(instance), nil
+}
+
+// Shutdown stops pending startups
+func (f *Fake) Shutdown(ctx context.Context) error {
+	f.cancel()
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, instance := range f.instances {
+		instance.started.Stop()
+	}
+	return nil
+}
+
+// HandleMCPInstanceCreated simulates an instance requested through a Redis event, so the fake
+// can stand in for the container manager behind the Docker provider
+func (f *Fake) HandleMCPInstanceCreated(ctx context.Context, instanceID, name string, jsonSpec map[string]interface{}) error {
+	spec := &InstanceSpec{
+		Name:        name,
+		InstanceID:  instanceID,
+		ServiceName: name,
+		Port:        8000,
+	}
+	if image, ok := jsonSpec["image"].(string); ok {
+		spec.Image = image
+	}
+	if port, ok := jsonSpec["port"].(float64); ok {
+		spec.Port = int(port)
+	}
+
+	if _, err := f.CreateInstance(ctx, spec); err != nil {
+		if f.publisher != nil {
+			f.publisher.PublishFailed(ctx, instanceID, name, err.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// HandleMCPInstanceDeleted removes the instance requested through a Redis event
+func (f *Fake) HandleMCPInstanceDeleted(ctx context.Context, instanceID string) error {
+	return f.DeleteInstance(ctx, instanceID)
+}
+
+// find looks an instance up by container ID, instance ID or service name; the caller holds the mutex
+func (f *Fake) find(id string) *fakeInstance {
+	if instance, exists := f.instances[id]; exists {
+		return instance
+	}
+	for _, instance := range f.instances {
+		if id != "" && (instance.status.InstanceID == id || instance.status.ServiceName == id) {
+			return instance
+		}
+	}
+	return nil
+}
+
+// health returns the simulated health of instance
+func (f *Fake) health(instance *fakeInstance) *HealthCheckResult {
+	result := &HealthCheckResult{
+		Healthy:       instance.status.Status == string(models.StatusRunning),
+		Live:          instance.status.Status == string(models.StatusRunning),
+		Ready:         instance.status.Status == string(models.StatusRunning),
+		Status:        instance.status.Status,
+		HTTPReachable: instance.status.Status == string(models.StatusRunning),
+		ContainerID:   instance.status.ID,
+		ServiceName:   instance.status.ServiceName,
+		Timestamp:     time.Now(),
+	}
+	if instance.status.Status == string(models.StatusError) {
+		result.Error = "simulated startup failure"
+	}
+	return result
+}
+
+// randomHex returns n random bytes hex encoded
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,118 @@
+package backends
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// recordingPublisher records the statuses the fake backend publishes
+type recordingPublisher struct {
+	mutex    sync.Mutex
+	statuses []string
+}
+
+func (p *recordingPublisher) record(status string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.statuses = append(p.statuses, status)
+	return nil
+}
+
+func (p *recordingPublisher) PublishStarting(ctx context.Context, instanceID, name string) error {
+	return p.record(string(models.StatusStarting))
+}
+
+func (p *recordingPublisher) PublishRunning(ctx context.Context, instanceID, name, containerID, url string) error {
+	return p.record(string(models.StatusRunning))
+}
+
+func (p *recordingPublisher) PublishFailed(ctx context.Context, instanceID, name, errorMsg string) error {
+	return p.record("failed")
+}
+
+func (p *recordingPublisher) recorded() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return append([]string(nil), p.statuses...)
+}
+
+// waitForStatus polls the fake until instanceID reaches status
+func waitForStatus(t *testing.T, fake *Fake, instanceID, status string) *InstanceStatus {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got, err := fake.GetInstanceStatus(context.Background(), instanceID)
+		if err != nil {
+			t.Fatalf("Expected instance %s to exist, got %v", instanceID, err)
+		}
+		if got.Status == status {
+			return got
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected status %s, got %s", status, got.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFakeLifecycle(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ctx := context.Background()
+
+	cfg := &config.Config{Fake: config.FakeConfig{StartupDelay: 10 * time.Millisecond}}
+	publisher := &recordingPublisher{}
+	fake := NewFake(cfg, publisher, logger)
+	if err := fake.Initialize(ctx); err != nil {
+		t.Fatalf("Expected fake backend to initialize, got %v", err)
+	}
+
+	result, err := fake.CreateInstance(ctx, &InstanceSpec{Name: "echo", ServiceName: "echo", InstanceID: "inst-1", Image: "echo:latest", Port: 8000})
+	if err != nil {
+		t.Fatalf("Expected instance to be created, got %v", err)
+	}
+	if result.Status != string(models.StatusStarting) {
+		t.Errorf("Expected new instance to be starting, got %s", result.Status)
+	}
+	if _, err := fake.CreateInstance(ctx, &InstanceSpec{ServiceName: "echo"}); err == nil {
+		t.Errorf("Expected a duplicate service name to be rejected")
+	}
+
+	running := waitForStatus(t, fake, "inst-1", string(models.StatusRunning))
+	if running.ID != result.ID || running.HealthStatus == nil || !running.HealthStatus.Healthy {
+		t.Errorf("Expected running instance %s to be healthy, got %+v", result.ID, running)
+	}
+	if got := publisher.recorded(); len(got) != 2 || got[0] != "starting" || got[1] != "running" {
+		t.Errorf("Expected starting then running to be published, got %v", got)
+	}
+
+	if err := fake.DeleteInstance(ctx, result.ID); err != nil {
+		t.Fatalf("Expected instance to be deleted, got %v", err)
+	}
+	if instances, _ := fake.ListInstances(ctx); len(instances) != 0 {
+		t.Errorf("Expected no instances after delete, got %d", len(instances))
+	}
+
+	// Every instance fails to start at failure rate 1, including those created through events
+	failing := NewFake(&config.Config{Fake: config.FakeConfig{FailureRate: 1}}, nil, logger)
+	if err := failing.HandleMCPInstanceCreated(ctx, "inst-2", "broken", map[string]interface{}{"image": "broken:latest", "port": float64(9000)}); err != nil {
+		t.Fatalf("Expected event to create an instance, got %v", err)
+	}
+	failed := waitForStatus(t, failing, "inst-2", string(models.StatusError))
+	if failed.Port != 9000 || failed.HealthStatus.Healthy {
+		t.Errorf("Expected failed instance on port 9000 to be unhealthy, got %+v", failed)
+	}
+	if err := failing.HandleMCPInstanceDeleted(ctx, "inst-2"); err != nil {
+		t.Errorf("Expected event to delete the instance, got %v", err)
+	}
+
+	if err := NewFake(&config.Config{Fake: config.FakeConfig{FailureRate: 2}}, nil, logger).Initialize(ctx); err == nil {
+		t.Errorf("Expected a failure rate above 1 to be rejected")
+	}
+}
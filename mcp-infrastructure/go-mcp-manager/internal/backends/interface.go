@@ -2,7 +2,10 @@ package backends
 
 import (
 	"context"
+	"errors"
 	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
 )
 
 // Backend defines the interface for container management backends (Docker/Kubernetes)
@@ -22,6 +25,9 @@ type Backend interface {
 	// UpdateInstance updates an existing instance configuration
 	UpdateInstance(ctx context.Context, instanceID string, spec *InstanceSpec) error
 	
+	// PlanInstance returns what CreateInstance would do for spec without creating anything
+	PlanInstance(ctx context.Context, spec *InstanceSpec) (*models.CreatePlan, error)
+	
 	// PerformHealthCheck performs health check on an instance
 	PerformHealthCheck(ctx context.Context, instanceID string) (*HealthCheckResult, error)
 	
@@ -49,25 +55,97 @@ type InstanceSpec struct {
 	
 	// Networking
 	ExposedPort int `json:"exposed_port,omitempty"`
+
+	// Preferred /mcp/{slug} path, suffixed when another instance has it
+	Slug string `json:"slug,omitempty"`
 	
 	// Volume mounts for writable directories (security sandbox)
 	WritablePaths []string `json:"writable_paths,omitempty"`
+
+	// Health check overrides (path, port, interval, thresholds)
+	HealthCheck *models.HealthCheckConfig `json:"health_check,omitempty"`
+
+	// Proxy middlewares (rate limit, IP allowlist, headers, buffering)
+	Route *models.RouteConfig `json:"route,omitempty"`
+
+	// Path or host based routing
+	Routing *models.RoutingConfig `json:"routing,omitempty"`
+
+	// GPUs to assign from the host pool and extra devices (CDI names or /dev paths) to pass through
+	GPUs    int      `json:"gpus,omitempty"`
+	Devices []string `json:"devices,omitempty"`
+
+	// Overrides of the hardened container security defaults
+	Security *models.SecurityConfig `json:"security,omitempty"`
+
+	// Attach to the shared network in addition to the workspace network
+	SharedNetwork bool `json:"shared_network,omitempty"`
+
+	// Resolvers, "host:ip" hosts entries and HTTP proxy, over the manager defaults
+	DNS        []string            `json:"dns,omitempty"`
+	ExtraHosts []string            `json:"extra_hosts,omitempty"`
+	Proxy      *models.ProxyConfig `json:"proxy,omitempty"`
+
+	// Size-limited writable mounts: in-memory tmpfs and disk-backed scratch volumes
+	Tmpfs          []models.ScratchMount `json:"tmpfs,omitempty"`
+	ScratchVolumes []models.ScratchMount `json:"scratch_volumes,omitempty"`
+
+	// Instances started first and advertised to this one through env vars
+	DependsOn []models.Dependency `json:"depends_on,omitempty"`
+
+	// One-shot setup command run to completion before the server starts
+	Init *models.InitConfig `json:"init,omitempty"`
+
+	// Files mounted read-only into the server, by container path
+	Files map[string]string `json:"files,omitempty"`
+
+	// Repository the image is built from when Image is empty
+	Source *models.SourceConfig `json:"source,omitempty"`
+
+	// npx or uvx package run behind a stdio-to-HTTP bridge when Image is empty
+	Runtime *models.RuntimeConfig `json:"runtime,omitempty"`
+
+	// Where the instance's logs are forwarded instead of the manager default
+	LogShipping *models.LogShippingConfig `json:"log_shipping,omitempty"`
+
+	// Environment variables the server reads, checked before the instance is created
+	EnvSchema []models.EnvVarSpec `json:"env_schema,omitempty"`
 	
 	// Metadata
 	InstanceID   string `json:"instance_id"`
 	WorkspaceID  string `json:"workspace_id,omitempty"`
 	ServiceName  string `json:"service_name"`
+
+	// IdempotencyKey makes the create safe to retry: an identical live instance is returned as is
+	// and a changed spec replaces it. Set from the Idempotency-Key header, never from the body.
+	IdempotencyKey string `json:"-"`
 }
 
 // ResourceRequirements defines resource constraints for instances
 type ResourceRequirements struct {
-	Requests ResourceList `json:"requests,omitempty"`
-	Limits   ResourceList `json:"limits,omitempty"`
+	Requests  ResourceList `json:"requests,omitempty"`
+	Limits    ResourceList `json:"limits,omitempty"`
+	PidsLimit int          `json:"pids_limit,omitempty"`
 }
 
 type ResourceList struct {
-	CPU    string `json:"cpu,omitempty"`
-	Memory string `json:"memory,omitempty"`
+	CPU              string `json:"cpu,omitempty"`
+	Memory           string `json:"memory,omitempty"`
+	EphemeralStorage string `json:"ephemeral_storage,omitempty"`
+}
+
+// LimitsSpec returns the limits as the container model, or nil when none are set
+func (r ResourceRequirements) LimitsSpec() *models.ResourceLimits {
+	limits := models.ResourceLimits{
+		Memory:           r.Limits.Memory,
+		CPU:              r.Limits.CPU,
+		PidsLimit:        r.PidsLimit,
+		EphemeralStorage: r.Limits.EphemeralStorage,
+	}
+	if limits == (models.ResourceLimits{}) {
+		return nil
+	}
+	return &limits
 }
 
 // InstanceResult represents the result of creating an instance
@@ -83,6 +161,7 @@ type InstanceResult struct {
 // InstanceStatus represents the current status of an instance
 type InstanceStatus struct {
 	ID            string            `json:"id"`
+	InstanceID    string            `json:"instance_id,omitempty"` // Core API instance the workload serves
 	Name          string            `json:"name"`
 	ServiceName   string            `json:"service_name"`
 	Status        string            `json:"status"`
@@ -100,6 +179,8 @@ type InstanceStatus struct {
 // HealthCheckResult represents the result of a health check
 type HealthCheckResult struct {
 	Healthy         bool          `json:"healthy"`
+	Live            bool          `json:"live"`
+	Ready           bool          `json:"ready"`
 	Status          string        `json:"status"`
 	HTTPReachable   bool          `json:"http_reachable"`
 	ResponseTime    time.Duration `json:"response_time"`
@@ -116,8 +197,24 @@ type BackendType string
 const (
 	BackendTypeDocker     BackendType = "docker"
 	BackendTypeKubernetes BackendType = "kubernetes"
+	BackendTypeFake       BackendType = "fake"
 )
 
+// ReadinessChecker is implemented by backends that can tell whether their runtime is reachable
+type ReadinessChecker interface {
+	// CheckReady returns an error when the backend cannot currently run containers
+	CheckReady(ctx context.Context) error
+}
+
+// ErrInstanceNotFound is returned when no instance has the requested ID
+var ErrInstanceNotFound = errors.New("instance not found")
+
+// Inspector is implemented by backends that can report an instance's runtime state for debugging
+type Inspector interface {
+	// InspectInstance returns the mounts, networks, restarts, exits and image digest of an instance
+	InspectInstance(ctx context.Context, instanceID string) (*models.ContainerInspect, error)
+}
+
 // BackendFactory creates backend instances based on configuration
 type BackendFactory interface {
 	CreateBackend(backendType BackendType) (Backend, error)
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -80,7 +81,7 @@ func NewKubernetesBackend(cfg *config.Config, logger *slog.Logger) (*KubernetesB
 
 // Initialize initializes the Kubernetes backend
 func (k *KubernetesBackend) Initialize(ctx context.Context) error {
-	k.logger.Info("Initializing Kubernetes backend",
+	k.logger.InfoContext(ctx, "Initializing Kubernetes backend",
 		slog.String("namespace", k.k8sConfig.Namespace),
 		slog.String("domain", k.k8sConfig.Domain))
 
@@ -89,7 +90,7 @@ func (k *KubernetesBackend) Initialize(ctx context.Context) error {
 		return fmt.Errorf("failed to ensure namespace: %w", err)
 	}
 
-	k.logger.Info("Kubernetes backend initialized successfully")
+	k.logger.InfoContext(ctx, "Kubernetes backend initialized successfully")
 	return nil
 }
 
@@ -97,7 +98,11 @@ func (k *KubernetesBackend) Initialize(ctx context.Context) error {
 func (k *KubernetesBackend) CreateInstance(ctx context.Context, spec *InstanceSpec) (*InstanceResult, error) {
 	instanceName := k.sanitizeInstanceName(spec.Name)
 
-	k.logger.Info("Creating Kubernetes instance",
+	if err := checkKubernetesSupport(spec); err != nil {
+		return nil, err
+	}
+
+	k.logger.InfoContext(ctx, "Creating Kubernetes instance",
 		slog.String("name", spec.Name),
 		slog.String("instance_name", instanceName),
 		slog.String("image", spec.Image))
@@ -106,6 +111,7 @@ func (k *KubernetesBackend) CreateInstance(ctx context.Context, spec *InstanceSp
 	resources := []func(context.Context, string, *InstanceSpec) error{
 		k.createConfigMap,
 		k.createSecret,
+		k.applyFilesSecret,
 		k.createDeployment,
 		k.createService,
 		k.createIngress,
@@ -113,7 +119,7 @@ func (k *KubernetesBackend) CreateInstance(ctx context.Context, spec *InstanceSp
 
 	for _, createFunc := range resources {
 		if err := createFunc(ctx, instanceName, spec); err != nil {
-			k.logger.Error("Failed to create resource, cleaning up",
+			k.logger.ErrorContext(ctx, "Failed to create resource, cleaning up",
 				slog.String("instance_name", instanceName),
 				slog.String("error", err.Error()))
 
@@ -125,7 +131,7 @@ func (k *KubernetesBackend) CreateInstance(ctx context.Context, spec *InstanceSp
 
 	// Wait for deployment to be ready
 	if err := k.waitForDeploymentReady(ctx, instanceName); err != nil {
-		k.logger.Error("Deployment not ready, cleaning up",
+		k.logger.ErrorContext(ctx, "Deployment not ready, cleaning up",
 			slog.String("instance_name", instanceName),
 			slog.String("error", err.Error()))
 
@@ -151,7 +157,7 @@ func (k *KubernetesBackend) CreateInstance(ctx context.Context, spec *InstanceSp
 		CreatedAt:   time.Now(),
 	}
 
-	k.logger.Info("Successfully created Kubernetes instance",
+	k.logger.InfoContext(ctx, "Successfully created Kubernetes instance",
 		slog.String("id", result.ID),
 		slog.String("name", result.Name),
 		slog.String("url", result.URL))
@@ -159,6 +165,59 @@ func (k *KubernetesBackend) CreateInstance(ctx context.Context, spec *InstanceSp
 	return result, nil
 }
 
+// checkKubernetesSupport rejects spec features only the podman backend implements
+func checkKubernetesSupport(spec *InstanceSpec) error {
+	if len(spec.DependsOn) > 0 {
+		return fmt.Errorf("depends_on is not supported by the kubernetes backend")
+	}
+	if spec.Source != nil {
+		return fmt.Errorf("building from source is not supported by the kubernetes backend, push an image instead")
+	}
+	if spec.Runtime != nil {
+		return fmt.Errorf("npx/uvx runtimes are not supported by the kubernetes backend")
+	}
+	if spec.LogShipping != nil {
+		return fmt.Errorf("log_shipping is not supported by the kubernetes backend, collect pod logs with the cluster's log agent")
+	}
+	if spec.Slug != "" {
+		return fmt.Errorf("slug is not supported by the kubernetes backend, instances are routed under their name")
+	}
+	if len(spec.DNS) > 0 || len(spec.ExtraHosts) > 0 || spec.Proxy != nil {
+		return fmt.Errorf("dns, extra_hosts and proxy are not supported by the kubernetes backend, configure them on the cluster")
+	}
+	return nil
+}
+
+// PlanInstance renders the manifests CreateInstance would apply, with Secret values masked
+func (k *KubernetesBackend) PlanInstance(ctx context.Context, spec *InstanceSpec) (*models.CreatePlan, error) {
+	if err := checkKubernetesSupport(spec); err != nil {
+		return nil, err
+	}
+	manifests, err := k.renderManifests(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &models.CreatePlan{
+		Backend:     string(BackendTypeKubernetes),
+		ServiceName: spec.ServiceName,
+		Name:        manifests.Deployment.Name,
+		Image:       spec.Image,
+		Slug:        manifests.InstanceName,
+		URL:         k.k8sConfig.GetInstanceURL(manifests.InstanceName),
+		Environment: manifests.Secret.StringData,
+		Resources:   spec.Resources.LimitsSpec(),
+		Manifests:   manifests.Objects(),
+	}
+	if spec.Resources.Limits.CPU == "" && spec.Resources.Limits.Memory == "" {
+		plan.Policies = append(plan.Policies, "default resources from the Kubernetes configuration")
+	}
+	if k.k8sConfig.TLS.Enabled {
+		plan.Policies = append(plan.Policies, "tls: "+k.k8sConfig.TLS.SecretName)
+	}
+	return plan, nil
+}
+
 // DeleteInstance removes an MCP server instance and all its Kubernetes resources
 func (k *KubernetesBackend) DeleteInstance(ctx context.Context, instanceID string) error {
 	instanceName, err := k.findInstanceNameByID(ctx, instanceID)
@@ -166,7 +225,7 @@ func (k *KubernetesBackend) DeleteInstance(ctx context.Context, instanceID strin
 		return fmt.Errorf("failed to find instance: %w", err)
 	}
 
-	k.logger.Info("Deleting Kubernetes instance",
+	k.logger.InfoContext(ctx, "Deleting Kubernetes instance",
 		slog.String("instance_id", instanceID),
 		slog.String("instance_name", instanceName))
 
@@ -174,7 +233,7 @@ func (k *KubernetesBackend) DeleteInstance(ctx context.Context, instanceID strin
 		return fmt.Errorf("failed to cleanup resources: %w", err)
 	}
 
-	k.logger.Info("Successfully deleted Kubernetes instance",
+	k.logger.InfoContext(ctx, "Successfully deleted Kubernetes instance",
 		slog.String("instance_id", instanceID),
 		slog.String("instance_name", instanceName))
 
@@ -206,7 +265,7 @@ func (k *KubernetesBackend) GetInstanceStatus(ctx context.Context, instanceID st
 		Namespace: k.k8sConfig.Namespace,
 		Name:      fmt.Sprintf("mcp-%s", instanceName),
 	}, configMap); err != nil {
-		k.logger.Warn("Failed to get configmap for metadata",
+		k.logger.WarnContext(ctx, "Failed to get configmap for metadata",
 			slog.String("instance_name", instanceName),
 			slog.String("error", err.Error()))
 	}
@@ -283,7 +342,7 @@ func (k *KubernetesBackend) ListInstances(ctx context.Context) ([]*InstanceStatu
 
 		status, err := k.GetInstanceStatus(ctx, string(deployment.UID))
 		if err != nil {
-			k.logger.Warn("Failed to get instance status",
+			k.logger.WarnContext(ctx, "Failed to get instance status",
 				slog.String("instance_name", instanceName),
 				slog.String("error", err.Error()))
 			continue
@@ -302,7 +361,7 @@ func (k *KubernetesBackend) UpdateInstance(ctx context.Context, instanceID strin
 		return fmt.Errorf("failed to find instance: %w", err)
 	}
 
-	k.logger.Info("Updating Kubernetes instance",
+	k.logger.InfoContext(ctx, "Updating Kubernetes instance",
 		slog.String("instance_id", instanceID),
 		slog.String("instance_name", instanceName))
 
@@ -315,13 +374,16 @@ func (k *KubernetesBackend) UpdateInstance(ctx context.Context, instanceID strin
 	if err := k.updateSecret(ctx, instanceName, spec); err != nil {
 		return fmt.Errorf("failed to update secret: %w", err)
 	}
+	if err := k.applyFilesSecret(ctx, instanceName, spec); err != nil {
+		return err
+	}
 
 	// Update deployment (this will trigger a rolling update)
 	if err := k.updateDeployment(ctx, instanceName, spec); err != nil {
 		return fmt.Errorf("failed to update deployment: %w", err)
 	}
 
-	k.logger.Info("Successfully updated Kubernetes instance",
+	k.logger.InfoContext(ctx, "Successfully updated Kubernetes instance",
 		slog.String("instance_id", instanceID),
 		slog.String("instance_name", instanceName))
 
@@ -356,6 +418,7 @@ func (k *KubernetesBackend) PerformHealthCheck(ctx context.Context, instanceID s
 
 	result := &HealthCheckResult{
 		Healthy:     ready,
+		Live:        deployment.Status.ReadyReplicas > 0,
 		Status:      k.getDeploymentStatus(deployment),
 		ServiceName: instanceName,
 		Timestamp:   time.Now(),
@@ -367,6 +430,7 @@ func (k *KubernetesBackend) PerformHealthCheck(ctx context.Context, instanceID s
 		result.HTTPReachable = httpHealthy
 		result.ResponseTime = responseTime
 		result.Healthy = ready && httpHealthy
+		result.Ready = result.Healthy
 	}
 
 	return result, nil
@@ -374,11 +438,19 @@ func (k *KubernetesBackend) PerformHealthCheck(ctx context.Context, instanceID s
 
 // Shutdown gracefully shuts down the Kubernetes backend
 func (k *KubernetesBackend) Shutdown(ctx context.Context) error {
-	k.logger.Info("Shutting down Kubernetes backend")
+	k.logger.InfoContext(ctx, "Shutting down Kubernetes backend")
 	// No specific cleanup needed for Kubernetes client
 	return nil
 }
 
+// CheckReady reports whether the Kubernetes API server answers
+func (k *KubernetesBackend) CheckReady(ctx context.Context) error {
+	if _, err := k.clientset.Discovery().RESTClient().Get().AbsPath("/readyz").DoRaw(ctx); err != nil {
+		return fmt.Errorf("kubernetes API server is not ready: %w", err)
+	}
+	return nil
+}
+
 // Helper methods
 
 // sanitizeInstanceName sanitizes an instance name for Kubernetes
@@ -431,7 +503,7 @@ func (k *KubernetesBackend) ensureNamespace(ctx context.Context) error {
 			if err := k.client.Create(ctx, namespace); err != nil {
 				return fmt.Errorf("failed to create namespace: %w", err)
 			}
-			k.logger.Info("Created namespace", slog.String("namespace", k.k8sConfig.Namespace))
+			k.logger.InfoContext(ctx, "Created namespace", slog.String("namespace", k.k8sConfig.Namespace))
 		} else {
 			return fmt.Errorf("failed to get namespace: %w", err)
 		}
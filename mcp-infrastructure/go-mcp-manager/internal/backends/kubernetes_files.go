@@ -0,0 +1,114 @@
+package backends
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// filesVolumeName is the pod volume projected files are mounted from
+const filesVolumeName = "files"
+
+// filesSecretName returns the Secret holding an instance's projected files
+func filesSecretName(instanceName string) string {
+	return fmt.Sprintf("mcp-%s-files", instanceName)
+}
+
+// projectedFileKeys returns the Secret key of each projected file path. Paths are not valid keys,
+// so files are numbered in path order.
+func projectedFileKeys(files map[string]string) map[string]string {
+	keys := make(map[string]string, len(files))
+	for i, filePath := range slices.Sorted(maps.Keys(files)) {
+		keys[filePath] = fmt.Sprintf("file-%d", i)
+	}
+	return keys
+}
+
+// buildFilesSecret builds the Secret an instance's projected files are mounted from; nil when it
+// has none
+func (k *KubernetesBackend) buildFilesSecret(instanceName string, spec *InstanceSpec) *corev1.Secret {
+	if len(spec.Files) == 0 {
+		return nil
+	}
+	data := make(map[string][]byte, len(spec.Files))
+	for filePath, key := range projectedFileKeys(spec.Files) {
+		data[key] = []byte(spec.Files[filePath])
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      filesSecretName(instanceName),
+			Namespace: k.k8sConfig.Namespace,
+			Labels:    k.getCommonLabels(instanceName),
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+}
+
+// applyFilesSecret creates or replaces the files Secret of an instance, deleting it once the
+// instance has no files
+func (k *KubernetesBackend) applyFilesSecret(ctx context.Context, instanceName string, spec *InstanceSpec) error {
+	existing := &corev1.Secret{}
+	err := k.client.Get(ctx, types.NamespacedName{
+		Namespace: k.k8sConfig.Namespace,
+		Name:      filesSecretName(instanceName),
+	}, existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get files secret: %w", err)
+	}
+	found := err == nil
+
+	secret := k.buildFilesSecret(instanceName, spec)
+	switch {
+	case secret == nil && found:
+		if err := k.client.Delete(ctx, existing); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete files secret: %w", err)
+		}
+	case secret != nil && found:
+		existing.Data = secret.Data
+		if err := k.client.Update(ctx, existing); err != nil {
+			return fmt.Errorf("failed to update files secret: %w", err)
+		}
+	case secret != nil:
+		if err := k.client.Create(ctx, secret); err != nil {
+			return fmt.Errorf("failed to create files secret: %w", err)
+		}
+	}
+	return nil
+}
+
+// filesVolume returns the volume an instance's projected files are mounted from
+func filesVolume(instanceName string) corev1.Volume {
+	mode := int32(0o444)
+	return corev1.Volume{
+		Name: filesVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  filesSecretName(instanceName),
+				DefaultMode: &mode,
+			},
+		},
+	}
+}
+
+// filesVolumeMounts mounts each projected file read-only at its path. A subPath mount replaces
+// only that file, leaving the rest of its directory from the image.
+func filesVolumeMounts(spec *InstanceSpec) []corev1.VolumeMount {
+	keys := projectedFileKeys(spec.Files)
+	mounts := make([]corev1.VolumeMount, 0, len(keys))
+	for _, filePath := range slices.Sorted(maps.Keys(keys)) {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      filesVolumeName,
+			MountPath: filePath,
+			SubPath:   keys[filePath],
+			ReadOnly:  true,
+		})
+	}
+	return mounts
+}
@@ -0,0 +1,174 @@
+package backends
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// serverContainerName is the container of an instance's pod that runs the MCP server
+const serverContainerName = "mcp-server"
+
+// InspectInstance returns the state of the server container in an instance's newest pod
+func (k *KubernetesBackend) InspectInstance(ctx context.Context, instanceID string) (*models.ContainerInspect, error) {
+	instanceName, err := k.findInstanceNameByID(ctx, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find instance: %w", err)
+	}
+
+	pods := &corev1.PodList{}
+	if err := k.client.List(ctx, pods, client.InNamespace(k.k8sConfig.Namespace), client.MatchingLabels{
+		"app.kubernetes.io/name":     "mcp-server",
+		"app.kubernetes.io/instance": instanceName,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("instance %s has no pod to inspect", instanceID)
+	}
+
+	// During a rollout the newest pod is the one being debugged
+	newest := &pods.Items[0]
+	for i := range pods.Items {
+		if pods.Items[i].CreationTimestamp.After(newest.CreationTimestamp.Time) {
+			newest = &pods.Items[i]
+		}
+	}
+
+	inspect := podInspect(newest)
+	inspect.ServiceName = instanceName
+	return inspect, nil
+}
+
+// podInspect normalizes the server container of a pod into the shape podman inspect is reported in
+func podInspect(pod *corev1.Pod) *models.ContainerInspect {
+	inspect := &models.ContainerInspect{
+		Runtime:  string(BackendTypeKubernetes),
+		Pod:      pod.Name,
+		Mounts:   []models.InspectMount{},
+		Networks: []models.InspectNetwork{},
+	}
+	if pod.Status.PodIP != "" {
+		inspect.Networks = append(inspect.Networks, models.InspectNetwork{
+			Name:      "pod",
+			IPAddress: pod.Status.PodIP,
+		})
+	}
+
+	volumes := make(map[string]corev1.Volume, len(pod.Spec.Volumes))
+	for _, volume := range pod.Spec.Volumes {
+		volumes[volume.Name] = volume
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.Name != serverContainerName {
+			continue
+		}
+		inspect.Image = container.Image
+		for _, mount := range container.VolumeMounts {
+			kind, source := volumeSource(volumes[mount.Name])
+			inspect.Mounts = append(inspect.Mounts, models.InspectMount{
+				Type:        kind,
+				Source:      source,
+				Destination: mount.MountPath,
+				ReadOnly:    mount.ReadOnly,
+			})
+		}
+		for _, port := range container.Ports {
+			if inspect.Ports == nil {
+				inspect.Ports = make(map[string]string)
+			}
+			key := fmt.Sprintf("%d/%s", port.ContainerPort, strings.ToLower(string(port.Protocol)))
+			inspect.Ports[key] = ""
+			if port.HostPort != 0 {
+				inspect.Ports[key] = net.JoinHostPort(port.HostIP, strconv.Itoa(int(port.HostPort)))
+			}
+		}
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != serverContainerName {
+			continue
+		}
+		inspect.ContainerID = status.ContainerID
+		inspect.ImageID = status.ImageID
+		// The image ID is the resolved reference, e.g. docker.io/org/server@sha256:...
+		if _, digest, found := strings.Cut(status.ImageID, "@"); found {
+			inspect.ImageDigest = digest
+		}
+		inspect.RestartCount = int(status.RestartCount)
+
+		switch state := status.State; {
+		case state.Running != nil:
+			inspect.State = "running"
+			inspect.StartedAt = podTime(state.Running.StartedAt)
+		case state.Terminated != nil:
+			inspect.State = "exited"
+			inspect.Reason = state.Terminated.Reason
+			inspect.Error = state.Terminated.Message
+			inspect.ExitCode = int(state.Terminated.ExitCode)
+			inspect.OOMKilled = state.Terminated.Reason == "OOMKilled"
+			inspect.StartedAt = podTime(state.Terminated.StartedAt)
+			inspect.FinishedAt = podTime(state.Terminated.FinishedAt)
+		case state.Waiting != nil:
+			inspect.State = "waiting"
+			inspect.Reason = state.Waiting.Reason
+			inspect.Error = state.Waiting.Message
+		}
+
+		// A crash-looping container is usually waiting, so its last run says why
+		if last := status.LastTerminationState.Terminated; last != nil {
+			exitCode := int(last.ExitCode)
+			inspect.LastExitCode = &exitCode
+			inspect.LastReason = last.Reason
+			if last.Reason == "OOMKilled" {
+				inspect.OOMKilled = true
+			}
+			if inspect.FinishedAt == nil {
+				inspect.FinishedAt = podTime(last.FinishedAt)
+			}
+		}
+	}
+	if inspect.State == "" {
+		inspect.State = strings.ToLower(string(pod.Status.Phase))
+	}
+	return inspect
+}
+
+// volumeSource names the kind of a pod volume and what backs it
+func volumeSource(volume corev1.Volume) (string, string) {
+	switch {
+	case volume.EmptyDir != nil:
+		if volume.EmptyDir.Medium == corev1.StorageMediumMemory {
+			return "tmpfs", volume.Name
+		}
+		return "emptyDir", volume.Name
+	case volume.PersistentVolumeClaim != nil:
+		return "persistentVolumeClaim", volume.PersistentVolumeClaim.ClaimName
+	case volume.ConfigMap != nil:
+		return "configMap", volume.ConfigMap.Name
+	case volume.Secret != nil:
+		return "secret", volume.Secret.SecretName
+	case volume.HostPath != nil:
+		return "hostPath", volume.HostPath.Path
+	default:
+		return "volume", volume.Name
+	}
+}
+
+// podTime returns nil for an unset Kubernetes timestamp
+func podTime(t metav1.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	value := t.Time
+	return &value
+}
@@ -0,0 +1,215 @@
+package backends
+
+import (
+	"fmt"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maskedValue replaces Secret values in rendered manifests
+const maskedValue = "***"
+
+// Manifests are the Kubernetes objects an instance is deployed as, with Secret values masked.
+// FilesSecret is nil for an instance without projected files.
+type Manifests struct {
+	InstanceName string
+	ConfigMap    *corev1.ConfigMap
+	Secret       *corev1.Secret
+	FilesSecret  *corev1.Secret
+	Deployment   *appsv1.Deployment
+	Service      *corev1.Service
+	Ingress      *networkingv1.Ingress
+}
+
+// Objects returns the manifests in the order they are applied
+func (m *Manifests) Objects() []interface{} {
+	objects := []interface{}{m.ConfigMap, m.Secret}
+	if m.FilesSecret != nil {
+		objects = append(objects, m.FilesSecret)
+	}
+	return append(objects, m.Deployment, m.Service, m.Ingress)
+}
+
+// RenderManifests renders spec as the Kubernetes backend would deploy it, without a cluster, using
+// the Kubernetes settings in cfg
+func RenderManifests(cfg *config.Config, spec *InstanceSpec) (*Manifests, error) {
+	k := &KubernetesBackend{config: cfg, k8sConfig: &cfg.Kubernetes}
+	return k.renderManifests(spec)
+}
+
+// renderManifests builds the objects CreateInstance would create for spec
+func (k *KubernetesBackend) renderManifests(spec *InstanceSpec) (*Manifests, error) {
+	if err := checkKubernetesSupport(spec); err != nil {
+		return nil, err
+	}
+	instanceName := k.sanitizeInstanceName(spec.Name)
+
+	configMap, err := k.buildConfigMap(instanceName, spec)
+	if err != nil {
+		return nil, err
+	}
+	configMap.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"}
+
+	secret, err := k.buildSecret(instanceName, spec)
+	if err != nil {
+		return nil, err
+	}
+	secret.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"}
+	masked := make(map[string]string, len(secret.Data))
+	for key := range secret.Data {
+		masked[key] = maskedValue
+	}
+	secret.Data = nil
+	secret.StringData = masked
+
+	filesSecret := k.buildFilesSecret(instanceName, spec)
+	if filesSecret != nil {
+		filesSecret.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"}
+		maskedFiles := make(map[string]string, len(filesSecret.Data))
+		for key := range filesSecret.Data {
+			maskedFiles[key] = maskedValue
+		}
+		filesSecret.Data = nil
+		filesSecret.StringData = maskedFiles
+	}
+
+	deployment, err := k.buildDeployment(instanceName, spec)
+	if err != nil {
+		return nil, err
+	}
+	deployment.TypeMeta = metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"}
+
+	service, err := k.buildService(instanceName, spec)
+	if err != nil {
+		return nil, err
+	}
+	service.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Service"}
+
+	ingress, err := k.buildIngress(instanceName, spec)
+	if err != nil {
+		return nil, err
+	}
+	ingress.TypeMeta = metav1.TypeMeta{APIVersion: "networking.k8s.io/v1", Kind: "Ingress"}
+
+	return &Manifests{
+		InstanceName: instanceName,
+		ConfigMap:    configMap,
+		Secret:       secret,
+		FilesSecret:  filesSecret,
+		Deployment:   deployment,
+		Service:      service,
+		Ingress:      ingress,
+	}, nil
+}
+
+// HelmValues returns the manifests as values for a generic single-container chart
+func (m *Manifests) HelmValues() map[string]interface{} {
+	server := m.Deployment.Spec.Template.Spec.Containers[0]
+
+	values := map[string]interface{}{
+		"nameOverride":    m.InstanceName,
+		"image":           server.Image,
+		"imagePullPolicy": string(server.ImagePullPolicy),
+		"replicaCount":    1,
+		"env":             m.Secret.StringData,
+		"resources":       server.Resources,
+		"securityContext": server.SecurityContext,
+		"service": map[string]interface{}{
+			"type":       string(m.Service.Spec.Type),
+			"port":       80,
+			"targetPort": server.Ports[0].ContainerPort,
+		},
+	}
+	if m.Deployment.Spec.Replicas != nil {
+		values["replicaCount"] = *m.Deployment.Spec.Replicas
+	}
+	if len(server.Command) > 0 {
+		values["command"] = server.Command
+	}
+	if len(server.Args) > 0 {
+		values["args"] = server.Args
+	}
+	if server.LivenessProbe != nil {
+		values["livenessProbe"] = server.LivenessProbe
+	}
+	if server.ReadinessProbe != nil {
+		values["readinessProbe"] = server.ReadinessProbe
+	}
+	if len(m.Deployment.Spec.Template.Spec.Volumes) > 0 {
+		values["volumes"] = m.Deployment.Spec.Template.Spec.Volumes
+		values["volumeMounts"] = server.VolumeMounts
+	}
+	if m.FilesSecret != nil {
+		values["files"] = m.FilesSecret.StringData
+	}
+
+	ingress := map[string]interface{}{
+		"enabled":     true,
+		"annotations": m.Ingress.Annotations,
+	}
+	if m.Ingress.Spec.IngressClassName != nil {
+		ingress["className"] = *m.Ingress.Spec.IngressClassName
+	}
+	if rules := m.Ingress.Spec.Rules; len(rules) > 0 && rules[0].HTTP != nil {
+		paths := make([]map[string]interface{}, 0, len(rules[0].HTTP.Paths))
+		for _, path := range rules[0].HTTP.Paths {
+			paths = append(paths, map[string]interface{}{"path": path.Path, "pathType": string(*path.PathType)})
+		}
+		ingress["hosts"] = []map[string]interface{}{{"host": rules[0].Host, "paths": paths}}
+	}
+	if len(m.Ingress.Spec.TLS) > 0 {
+		ingress["tls"] = m.Ingress.Spec.TLS
+	}
+	values["ingress"] = ingress
+
+	return values
+}
+
+// ContainerSpec describes a podman container as the spec the Kubernetes backend deploys. The
+// image is used as is: an image built from source or a package bridge must be pushed to a
+// registry the cluster can pull from. Sidecar dependencies and log shipping have no equivalent.
+func ContainerSpec(container *models.Container) (*InstanceSpec, error) {
+	if container == nil {
+		return nil, fmt.Errorf("container is required")
+	}
+
+	spec := &InstanceSpec{
+		Name:           container.ServiceName,
+		Image:          container.Image,
+		Port:           container.Port,
+		Environment:    container.Environment,
+		Labels:         container.Labels,
+		Command:        container.Command,
+		HealthCheck:    container.HealthCheck,
+		Route:          container.Route,
+		Routing:        container.Routing,
+		GPUs:           container.GPUs,
+		Devices:        container.Devices,
+		Security:       container.Security,
+		SharedNetwork:  container.SharedNetwork,
+		Tmpfs:          container.Tmpfs,
+		ScratchVolumes: container.ScratchVolumes,
+		Init:           container.Init,
+		Files:          container.Files,
+		InstanceID:     container.Environment["MCP_INSTANCE_ID"],
+		WorkspaceID:    container.WorkspaceID,
+		ServiceName:    container.ServiceName,
+	}
+	if limits := container.Resources; limits != nil {
+		spec.Resources = ResourceRequirements{
+			Limits: ResourceList{
+				CPU:              limits.CPU,
+				Memory:           limits.Memory,
+				EphemeralStorage: limits.EphemeralStorage,
+			},
+			PidsLimit: limits.PidsLimit,
+		}
+	}
+	return spec, nil
+}
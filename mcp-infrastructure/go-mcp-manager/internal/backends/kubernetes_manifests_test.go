@@ -0,0 +1,55 @@
+package backends
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestRenderManifests(t *testing.T) {
+	cfg := &config.Config{Kubernetes: config.DefaultKubernetesConfig()}
+	container := &models.Container{
+		ServiceName: "github",
+		Image:       "ghcr.io/example/github-mcp:1.0",
+		Port:        3000,
+		Command:     []string{"--stdio=false"},
+		Environment: map[string]string{"MCP_INSTANCE_ID": "inst-1", "TOKEN": "secret"},
+		Resources:   &models.ResourceLimits{Memory: "512Mi", CPU: "500m"},
+	}
+
+	spec, err := ContainerSpec(container)
+	if err != nil {
+		t.Fatalf("Expected a spec, got %v", err)
+	}
+	if spec.InstanceID != "inst-1" || spec.Resources.Limits.Memory != "512Mi" {
+		t.Errorf("Expected instance ID and limits to carry over, got %+v", spec)
+	}
+
+	manifests, err := RenderManifests(cfg, spec)
+	if err != nil {
+		t.Fatalf("Expected manifests, got %v", err)
+	}
+	if len(manifests.Objects()) != 5 {
+		t.Errorf("Expected 5 objects, got %d", len(manifests.Objects()))
+	}
+	if manifests.Secret.Data != nil || manifests.Secret.StringData["TOKEN"] != "***" {
+		t.Errorf("Expected Secret values to be masked, got %v", manifests.Secret.StringData)
+	}
+	server := manifests.Deployment.Spec.Template.Spec.Containers[0]
+	if server.Image != container.Image || strings.Join(server.Args, " ") != "--stdio=false" {
+		t.Errorf("Expected image and command to be used as is, got %s %v", server.Image, server.Args)
+	}
+	if manifests.Deployment.Kind != "Deployment" || manifests.Ingress.APIVersion != "networking.k8s.io/v1" {
+		t.Errorf("Expected type metadata to be set, got %q %q", manifests.Deployment.Kind, manifests.Ingress.APIVersion)
+	}
+
+	values := manifests.HelmValues()
+	if values["image"] != container.Image {
+		t.Errorf("Expected Helm values to carry the image, got %v", values["image"])
+	}
+	if service, ok := values["service"].(map[string]interface{}); !ok || service["targetPort"] != int32(3000) {
+		t.Errorf("Expected the service to target port 3000, got %v", values["service"])
+	}
+}
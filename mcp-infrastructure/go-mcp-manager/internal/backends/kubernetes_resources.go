@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -23,8 +25,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// createConfigMap creates a ConfigMap for the MCP instance
-func (k *KubernetesBackend) createConfigMap(ctx context.Context, instanceName string, spec *InstanceSpec) error {
+// buildConfigMap builds a ConfigMap for the MCP instance
+func (k *KubernetesBackend) buildConfigMap(instanceName string, spec *InstanceSpec) (*corev1.ConfigMap, error) {
 	configMap := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      fmt.Sprintf("mcp-%s", instanceName),
@@ -39,6 +41,15 @@ func (k *KubernetesBackend) createConfigMap(ctx context.Context, instanceName st
 		},
 	}
 
+	return configMap, nil
+}
+
+// createConfigMap creates the ConfigMap for the MCP instance
+func (k *KubernetesBackend) createConfigMap(ctx context.Context, instanceName string, spec *InstanceSpec) error {
+	configMap, err := k.buildConfigMap(instanceName, spec)
+	if err != nil {
+		return err
+	}
 	if err := k.client.Create(ctx, configMap); err != nil {
 		return fmt.Errorf("failed to create configmap: %w", err)
 	}
@@ -46,8 +57,8 @@ func (k *KubernetesBackend) createConfigMap(ctx context.Context, instanceName st
 	return nil
 }
 
-// createSecret creates a Secret for environment variables
-func (k *KubernetesBackend) createSecret(ctx context.Context, instanceName string, spec *InstanceSpec) error {
+// buildSecret builds a Secret for environment variables
+func (k *KubernetesBackend) buildSecret(instanceName string, spec *InstanceSpec) (*corev1.Secret, error) {
 	secretData := make(map[string][]byte)
 	
 	// Add environment variables
@@ -70,6 +81,15 @@ func (k *KubernetesBackend) createSecret(ctx context.Context, instanceName strin
 		Data: secretData,
 	}
 
+	return secret, nil
+}
+
+// createSecret creates the Secret for the MCP instance
+func (k *KubernetesBackend) createSecret(ctx context.Context, instanceName string, spec *InstanceSpec) error {
+	secret, err := k.buildSecret(instanceName, spec)
+	if err != nil {
+		return err
+	}
 	if err := k.client.Create(ctx, secret); err != nil {
 		return fmt.Errorf("failed to create secret: %w", err)
 	}
@@ -77,9 +97,63 @@ func (k *KubernetesBackend) createSecret(ctx context.Context, instanceName strin
 	return nil
 }
 
-// createDeployment creates a Deployment for the MCP server
-func (k *KubernetesBackend) createDeployment(ctx context.Context, instanceName string, spec *InstanceSpec) error {
+// memoryQuantity converts a podman or Kubernetes size to a byte quantity; "512m" means MiB, not millibytes
+func memoryQuantity(value string) (resource.Quantity, error) {
+	bytes, err := config.ParseMemory(value)
+	if err != nil {
+		return resource.Quantity{}, err
+	}
+	return *resource.NewQuantity(bytes, resource.BinarySI), nil
+}
+
+// applySecurityOverrides relaxes the configured security context with an instance's json_spec overrides
+func applySecurityOverrides(securityContext *corev1.SecurityContext, security *models.SecurityConfig) {
+	if security == nil {
+		return
+	}
+
+	if security.ReadOnlyRootFS != nil {
+		readOnly := *security.ReadOnlyRootFS
+		securityContext.ReadOnlyRootFilesystem = &readOnly
+	}
+	if security.AllowPrivilegeEscalation {
+		allow := true
+		securityContext.AllowPrivilegeEscalation = &allow
+	}
+	for _, capability := range security.AddCapabilities {
+		securityContext.Capabilities.Add = append(securityContext.Capabilities.Add,
+			corev1.Capability(strings.TrimPrefix(strings.ToUpper(capability), "CAP_")))
+	}
+
+	// Kubernetes only accepts numeric IDs; user names are left to the image
+	uid, gid, _ := strings.Cut(security.User, ":")
+	if n, err := strconv.ParseInt(uid, 10, 64); err == nil {
+		securityContext.RunAsUser = &n
+	}
+	if n, err := strconv.ParseInt(gid, 10, 64); err == nil {
+		securityContext.RunAsGroup = &n
+	}
+
+	if security.SeccompProfile != "" {
+		profile := security.SeccompProfile
+		securityContext.SeccompProfile = &corev1.SeccompProfile{
+			Type:             corev1.SeccompProfileTypeLocalhost,
+			LocalhostProfile: &profile,
+		}
+	}
+}
+
+// gpuResourceName is the extended resource advertised by the NVIDIA device plugin
+const gpuResourceName corev1.ResourceName = "nvidia.com/gpu"
+
+// buildDeployment builds a Deployment for the MCP server
+func (k *KubernetesBackend) buildDeployment(instanceName string, spec *InstanceSpec) (*appsv1.Deployment, error) {
 	labels := k.getCommonLabels(instanceName)
+
+	// Enforce the same per-instance quota policy as the podman backend
+	if err := k.config.Container.ValidateResources(spec.Resources.LimitsSpec()); err != nil {
+		return nil, fmt.Errorf("invalid resources: %w", err)
+	}
 	
 	// Convert ResourceList to config.ResourceRequirements
 	var configRequests, configLimits *config.ResourceRequirements
@@ -109,13 +183,32 @@ func (k *KubernetesBackend) createDeployment(ctx context.Context, instanceName s
 		resourceRequirements.Requests[corev1.ResourceCPU] = resource.MustParse(requests.CPU)
 	}
 	if requests.Memory != "" {
-		resourceRequirements.Requests[corev1.ResourceMemory] = resource.MustParse(requests.Memory)
+		quantity, err := memoryQuantity(requests.Memory)
+		if err != nil {
+			return nil, fmt.Errorf("invalid memory request: %w", err)
+		}
+		resourceRequirements.Requests[corev1.ResourceMemory] = quantity
 	}
 	if limits.CPU != "" {
 		resourceRequirements.Limits[corev1.ResourceCPU] = resource.MustParse(limits.CPU)
 	}
 	if limits.Memory != "" {
-		resourceRequirements.Limits[corev1.ResourceMemory] = resource.MustParse(limits.Memory)
+		quantity, err := memoryQuantity(limits.Memory)
+		if err != nil {
+			return nil, fmt.Errorf("invalid memory limit: %w", err)
+		}
+		resourceRequirements.Limits[corev1.ResourceMemory] = quantity
+	}
+	if storage := spec.Resources.Limits.EphemeralStorage; storage != "" {
+		quantity, err := memoryQuantity(storage)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ephemeral storage limit: %w", err)
+		}
+		resourceRequirements.Limits[corev1.ResourceEphemeralStorage] = quantity
+	}
+	// The scheduler only places the pod on a node with enough free GPUs
+	if spec.GPUs > 0 {
+		resourceRequirements.Limits[gpuResourceName] = *resource.NewQuantity(int64(spec.GPUs), resource.DecimalSI)
 	}
 
 	// Security context
@@ -132,11 +225,14 @@ func (k *KubernetesBackend) createDeployment(ctx context.Context, instanceName s
 	for _, cap := range k.k8sConfig.SecurityContext.DropCapabilities {
 		securityContext.Capabilities.Drop = append(securityContext.Capabilities.Drop, corev1.Capability(cap))
 	}
+	applySecurityOverrides(securityContext, spec.Security)
 
 	// Container definition
 	container := corev1.Container{
-		Name:  "mcp-server",
+		Name:  serverContainerName,
 		Image: spec.Image,
+		// Like podman run's trailing arguments, the command replaces the image's CMD
+		Args: spec.Command,
 		Ports: []corev1.ContainerPort{
 			{
 				Name:          "http",
@@ -188,6 +284,29 @@ func (k *KubernetesBackend) createDeployment(ctx context.Context, instanceName s
 		},
 	}
 
+	// Apply per-instance health check configuration to the liveness probe
+	if hc := spec.HealthCheck; hc != nil {
+		probe := container.LivenessProbe
+		if hc.Path != "" {
+			probe.HTTPGet.Path = hc.Path
+		}
+		if hc.Port > 0 {
+			probe.HTTPGet.Port = intstr.FromInt(hc.Port)
+		}
+		if hc.IntervalSeconds > 0 {
+			probe.PeriodSeconds = int32(hc.IntervalSeconds)
+		}
+		if hc.TimeoutSeconds > 0 {
+			probe.TimeoutSeconds = int32(hc.TimeoutSeconds)
+		}
+		if hc.HealthyThreshold > 0 {
+			probe.SuccessThreshold = int32(hc.HealthyThreshold)
+		}
+		if hc.UnhealthyThreshold > 0 {
+			probe.FailureThreshold = int32(hc.UnhealthyThreshold)
+		}
+	}
+
 	// Add custom command if specified
 	if len(spec.Command) > 0 {
 		container.Command = spec.Command
@@ -214,8 +333,26 @@ func (k *KubernetesBackend) createDeployment(ctx context.Context, instanceName s
 		})
 	}
 
+	// Add tmpfs and scratch mounts; those at /tmp or /var/run resize the default volume instead
+	for _, mount := range k.scratchEmptyDirs(spec) {
+		if defaultWritableVolumes[mount.path] == "" {
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{
+				Name:      mount.name,
+				MountPath: mount.path,
+			})
+		}
+	}
+
+	// Add projected files, each mounted over its own path
+	volumeMounts = append(volumeMounts, filesVolumeMounts(spec)...)
+
 	container.VolumeMounts = volumeMounts
 
+	var initContainers []corev1.Container
+	if spec.Init != nil {
+		initContainers = append(initContainers, initContainer(spec.Init, container))
+	}
+
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      fmt.Sprintf("mcp-%s", instanceName),
@@ -239,8 +376,9 @@ func (k *KubernetesBackend) createDeployment(ctx context.Context, instanceName s
 						RunAsNonRoot: &k.k8sConfig.SecurityContext.RunAsNonRoot,
 						RunAsUser:    &k.k8sConfig.SecurityContext.RunAsUser,
 					},
-					Containers: []corev1.Container{container},
-					Volumes:    k.createVolumes(spec),
+					InitContainers: initContainers,
+					Containers:     []corev1.Container{container},
+					Volumes:        k.createVolumes(instanceName, spec),
 				},
 			},
 		},
@@ -253,6 +391,15 @@ func (k *KubernetesBackend) createDeployment(ctx context.Context, instanceName s
 	deployment.Spec.Template.ObjectMeta.Annotations["agentarea.io/instance-id"] = spec.InstanceID
 	deployment.Spec.Template.ObjectMeta.Annotations["agentarea.io/workspace-id"] = spec.WorkspaceID
 
+	return deployment, nil
+}
+
+// createDeployment creates the Deployment for the MCP instance
+func (k *KubernetesBackend) createDeployment(ctx context.Context, instanceName string, spec *InstanceSpec) error {
+	deployment, err := k.buildDeployment(instanceName, spec)
+	if err != nil {
+		return err
+	}
 	if err := k.client.Create(ctx, deployment); err != nil {
 		return fmt.Errorf("failed to create deployment: %w", err)
 	}
@@ -260,8 +407,38 @@ func (k *KubernetesBackend) createDeployment(ctx context.Context, instanceName s
 	return nil
 }
 
-// createVolumes creates the volume specifications for writable directories
-func (k *KubernetesBackend) createVolumes(spec *InstanceSpec) []corev1.Volume {
+// initContainer builds the init container that runs a spec's init command before the MCP server.
+// It shares the server's environment, mounts and hardening; init environment entries take precedence.
+func initContainer(spec *models.InitConfig, server corev1.Container) corev1.Container {
+	image := spec.Image
+	if image == "" {
+		image = server.Image
+	}
+
+	keys := make([]string, 0, len(spec.Environment))
+	for key := range spec.Environment {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	env := make([]corev1.EnvVar, 0, len(keys))
+	for _, key := range keys {
+		env = append(env, corev1.EnvVar{Name: key, Value: spec.Environment[key]})
+	}
+
+	return corev1.Container{
+		Name:            "mcp-init",
+		Image:           image,
+		Command:         spec.Command,
+		EnvFrom:         server.EnvFrom,
+		Env:             env,
+		Resources:       server.Resources,
+		SecurityContext: server.SecurityContext,
+		VolumeMounts:    server.VolumeMounts,
+	}
+}
+
+// createVolumes creates the volume specifications for writable directories and projected files
+func (k *KubernetesBackend) createVolumes(instanceName string, spec *InstanceSpec) []corev1.Volume {
 	// Default volumes (always needed for security)
 	volumes := []corev1.Volume{
 		{
@@ -289,11 +466,74 @@ func (k *KubernetesBackend) createVolumes(spec *InstanceSpec) []corev1.Volume {
 		})
 	}
 
+	// Add size-limited tmpfs and scratch volumes
+	for _, mount := range k.scratchEmptyDirs(spec) {
+		if name := defaultWritableVolumes[mount.path]; name != "" {
+			for i := range volumes {
+				if volumes[i].Name == name {
+					volumes[i].EmptyDir = mount.source
+				}
+			}
+			continue
+		}
+		volumes = append(volumes, corev1.Volume{
+			Name:         mount.name,
+			VolumeSource: corev1.VolumeSource{EmptyDir: mount.source},
+		})
+	}
+
+	if len(spec.Files) > 0 {
+		volumes = append(volumes, filesVolume(instanceName))
+	}
+
 	return volumes
 }
 
-// createService creates a Service for the MCP server
-func (k *KubernetesBackend) createService(ctx context.Context, instanceName string, spec *InstanceSpec) error {
+// defaultWritableVolumes maps the always-mounted writable paths to their volume names
+var defaultWritableVolumes = map[string]string{
+	"/tmp":     "tmp",
+	"/var/run": "var-run",
+}
+
+// emptyDirMount is a tmpfs or scratch mount backed by an emptyDir volume
+type emptyDirMount struct {
+	name   string
+	path   string
+	source *corev1.EmptyDirVolumeSource
+}
+
+// scratchEmptyDirs maps tmpfs mounts to memory-backed and scratch volumes to disk-backed emptyDirs
+func (k *KubernetesBackend) scratchEmptyDirs(spec *InstanceSpec) []emptyDirMount {
+	var mounts []emptyDirMount
+	add := func(prefix string, index int, mount models.ScratchMount, medium corev1.StorageMedium) {
+		source := &corev1.EmptyDirVolumeSource{Medium: medium}
+		size := mount.Size
+		if size == "" {
+			size = k.config.Container.DefaultScratchSize
+		}
+		if size != "" {
+			if quantity, err := memoryQuantity(size); err == nil {
+				source.SizeLimit = &quantity
+			}
+		}
+		mounts = append(mounts, emptyDirMount{
+			name:   fmt.Sprintf("%s-%d", prefix, index),
+			path:   mount.Path,
+			source: source,
+		})
+	}
+
+	for i, mount := range spec.Tmpfs {
+		add("tmpfs", i, mount, corev1.StorageMediumMemory)
+	}
+	for i, mount := range spec.ScratchVolumes {
+		add("scratch", i, mount, corev1.StorageMediumDefault)
+	}
+	return mounts
+}
+
+// buildService builds a Service for the MCP server
+func (k *KubernetesBackend) buildService(instanceName string, spec *InstanceSpec) (*corev1.Service, error) {
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      fmt.Sprintf("mcp-%s", instanceName),
@@ -317,6 +557,11 @@ func (k *KubernetesBackend) createService(ctx context.Context, instanceName stri
 		},
 	}
 
+	// Pin clients to one replica when the instance asks for sticky sessions
+	if spec.Route != nil && spec.Route.StickySessions {
+		service.Spec.SessionAffinity = corev1.ServiceAffinityClientIP
+	}
+
 	// Add metrics port if monitoring is enabled
 	if k.k8sConfig.Monitoring.Enabled {
 		service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{
@@ -327,6 +572,15 @@ func (k *KubernetesBackend) createService(ctx context.Context, instanceName stri
 		})
 	}
 
+	return service, nil
+}
+
+// createService creates the Service for the MCP instance
+func (k *KubernetesBackend) createService(ctx context.Context, instanceName string, spec *InstanceSpec) error {
+	service, err := k.buildService(instanceName, spec)
+	if err != nil {
+		return err
+	}
 	if err := k.client.Create(ctx, service); err != nil {
 		return fmt.Errorf("failed to create service: %w", err)
 	}
@@ -334,8 +588,8 @@ func (k *KubernetesBackend) createService(ctx context.Context, instanceName stri
 	return nil
 }
 
-// createIngress creates an Ingress for external access
-func (k *KubernetesBackend) createIngress(ctx context.Context, instanceName string, spec *InstanceSpec) error {
+// buildIngress builds an Ingress for external access
+func (k *KubernetesBackend) buildIngress(instanceName string, spec *InstanceSpec) (*networkingv1.Ingress, error) {
 	pathType := networkingv1.PathTypePrefix
 	
 	ingress := &networkingv1.Ingress{
@@ -343,7 +597,7 @@ func (k *KubernetesBackend) createIngress(ctx context.Context, instanceName stri
 			Name:        fmt.Sprintf("mcp-%s", instanceName),
 			Namespace:   k.k8sConfig.Namespace,
 			Labels:      k.getCommonLabels(instanceName),
-			Annotations: k.k8sConfig.GetIngressAnnotations(),
+			Annotations: k.k8sConfig.GetIngressAnnotations(spec.Route),
 		},
 		Spec: networkingv1.IngressSpec{
 			IngressClassName: &k.k8sConfig.IngressClass,
@@ -383,6 +637,15 @@ func (k *KubernetesBackend) createIngress(ctx context.Context, instanceName stri
 		}
 	}
 
+	return ingress, nil
+}
+
+// createIngress creates the Ingress for the MCP instance
+func (k *KubernetesBackend) createIngress(ctx context.Context, instanceName string, spec *InstanceSpec) error {
+	ingress, err := k.buildIngress(instanceName, spec)
+	if err != nil {
+		return err
+	}
 	if err := k.client.Create(ctx, ingress); err != nil {
 		return fmt.Errorf("failed to create ingress: %w", err)
 	}
@@ -439,6 +702,12 @@ func (k *KubernetesBackend) cleanupResources(ctx context.Context, instanceName s
 				Namespace: k.k8sConfig.Namespace,
 			},
 		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      filesSecretName(instanceName),
+				Namespace: k.k8sConfig.Namespace,
+			},
+		},
 		&corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      resourceName,
@@ -450,7 +719,7 @@ func (k *KubernetesBackend) cleanupResources(ctx context.Context, instanceName s
 	var lastError error
 	for _, resource := range resources {
 		if err := k.client.Delete(ctx, resource); err != nil && !errors.IsNotFound(err) {
-			k.logger.Warn("Failed to delete resource",
+			k.logger.WarnContext(ctx, "Failed to delete resource",
 				slog.String("resource", fmt.Sprintf("%T", resource)),
 				slog.String("name", resourceName),
 				slog.String("error", err.Error()))
@@ -531,6 +800,10 @@ func (k *KubernetesBackend) updateDeployment(ctx context.Context, instanceName s
 			container.Command = spec.Command
 		}
 
+		if err := k.config.Container.ValidateResources(spec.Resources.LimitsSpec()); err != nil {
+			return fmt.Errorf("invalid resources: %w", err)
+		}
+
 		// Convert ResourceList to config.ResourceRequirements
 		var configRequests, configLimits *config.ResourceRequirements
 		if spec.Resources.Requests.CPU != "" || spec.Resources.Requests.Memory != "" {
@@ -554,13 +827,21 @@ func (k *KubernetesBackend) updateDeployment(ctx context.Context, instanceName s
 			container.Resources.Requests[corev1.ResourceCPU] = resource.MustParse(requests.CPU)
 		}
 		if requests.Memory != "" {
-			container.Resources.Requests[corev1.ResourceMemory] = resource.MustParse(requests.Memory)
+			quantity, err := memoryQuantity(requests.Memory)
+			if err != nil {
+				return fmt.Errorf("invalid memory request: %w", err)
+			}
+			container.Resources.Requests[corev1.ResourceMemory] = quantity
 		}
 		if limits.CPU != "" {
 			container.Resources.Limits[corev1.ResourceCPU] = resource.MustParse(limits.CPU)
 		}
 		if limits.Memory != "" {
-			container.Resources.Limits[corev1.ResourceMemory] = resource.MustParse(limits.Memory)
+			quantity, err := memoryQuantity(limits.Memory)
+			if err != nil {
+				return fmt.Errorf("invalid memory limit: %w", err)
+			}
+			container.Resources.Limits[corev1.ResourceMemory] = quantity
 		}
 	}
 
@@ -604,7 +885,7 @@ func (k *KubernetesBackend) findInstanceNameByID(ctx context.Context, instanceID
 		}
 	}
 
-	return "", fmt.Errorf("instance not found: %s", instanceID)
+	return "", fmt.Errorf("%w: %s", ErrInstanceNotFound, instanceID)
 }
 
 // getDeploymentStatus determines status from deployment conditions
@@ -0,0 +1,273 @@
+// Package callbacks reports instance provisioning progress back to the Core API, so the
+// platform's database follows what actually happened on the host. Callbacks that cannot be
+// delivered are kept in a local outbox and retried in order once the Core API is back.
+package callbacks
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/internal/retry"
+	"github.com/agentarea/mcp-manager/internal/state"
+)
+
+// Provisioning phases reported in addition to the statuses published on Redis
+const (
+	PhasePulling = "pulling"
+	PhaseHealthy = "healthy"
+)
+
+// outboxBucket holds callbacks waiting for the Core API to come back
+const outboxBucket = "callback_outbox"
+
+// queueSize bounds the callbacks waiting for the delivery goroutine; overflow goes to the outbox
+const queueSize = 256
+
+// DeliveryHeader carries the progress ID, so the Core API can ignore redelivered callbacks
+const DeliveryHeader = "X-MCP-Manager-Delivery"
+
+// errPermanent marks responses that retrying cannot fix, such as a deleted instance
+var errPermanent = errors.New("callback rejected")
+
+// Progress is the payload POSTed to the Core API
+type Progress struct {
+	ID          string    `json:"id"`
+	InstanceID  string    `json:"instance_id"`
+	Name        string    `json:"name"`
+	Phase       string    `json:"phase"`
+	Reason      string    `json:"reason,omitempty"`
+	ContainerID string    `json:"container_id,omitempty"`
+	URL         string    `json:"url,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// Reporter delivers provisioning progress to the Core API from a single goroutine, so the
+// callbacks of an instance arrive in the order they happened
+type Reporter struct {
+	enabled       bool
+	baseURL       string
+	token         string
+	path          string
+	maxRetries    int
+	flushInterval time.Duration
+	httpClient    *http.Client
+	store         *state.Store
+	logger        *slog.Logger
+
+	queue chan Progress
+	// last is the phase and reason last reported per instance, to drop repeats
+	mutex sync.Mutex
+	last  map[string]string
+}
+
+// NewReporter creates a reporter for the Core API at coreAPIURL, authenticating with its service
+// token, with its outbox in store
+func NewReporter(cfg config.CallbackConfig, coreAPIURL, token string, store *state.Store, logger *slog.Logger) *Reporter {
+	return &Reporter{
+		enabled:       cfg.Enabled && coreAPIURL != "",
+		baseURL:       strings.TrimSuffix(coreAPIURL, "/"),
+		token:         token,
+		path:          cfg.Path,
+		maxRetries:    cfg.MaxRetries,
+		flushInterval: cfg.FlushInterval,
+		httpClient:    &http.Client{Timeout: cfg.Timeout},
+		store:         store,
+		logger:        logger,
+		queue:         make(chan Progress, queueSize),
+		last:          make(map[string]string),
+	}
+}
+
+// Report queues progress for delivery. A phase repeated with the same reason is dropped.
+func (r *Reporter) Report(progress Progress) {
+	if !r.enabled || progress.InstanceID == "" {
+		return
+	}
+
+	r.mutex.Lock()
+	key := progress.Phase + "\x00" + progress.Reason
+	if r.last[progress.InstanceID] == key {
+		r.mutex.Unlock()
+		return
+	}
+	r.last[progress.InstanceID] = key
+	r.mutex.Unlock()
+
+	if progress.ID == "" {
+		progress.ID = generateID()
+	}
+	if progress.Timestamp.IsZero() {
+		progress.Timestamp = time.Now()
+	}
+
+	select {
+	case r.queue <- progress:
+	default:
+		r.enqueueOutbox(progress)
+	}
+}
+
+// Forget drops the last reported phase of an instance, e.g. once it is deleted
+func (r *Reporter) Forget(instanceID string) {
+	r.mutex.Lock()
+	delete(r.last, instanceID)
+	r.mutex.Unlock()
+}
+
+// Pending returns the number of callbacks waiting in the outbox
+func (r *Reporter) Pending() int {
+	keys, err := r.store.Keys(outboxBucket)
+	if err != nil {
+		return 0
+	}
+	return len(keys)
+}
+
+// Run delivers queued callbacks and retries the outbox until ctx is cancelled
+func (r *Reporter) Run(ctx context.Context) {
+	if !r.enabled {
+		return
+	}
+
+	interval := r.flushInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	r.flush(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case progress := <-r.queue:
+			// Anything behind an undelivered callback waits its turn in the outbox
+			if r.Pending() > 0 {
+				r.enqueueOutbox(progress)
+				continue
+			}
+			if err := r.deliver(ctx, progress); err != nil && !errors.Is(err, errPermanent) {
+				r.enqueueOutbox(progress)
+			}
+		case <-ticker.C:
+			r.flush(ctx)
+		}
+	}
+}
+
+// flush sends outbox callbacks oldest first and stops at the first one the Core API does not take
+func (r *Reporter) flush(ctx context.Context) {
+	records, err := r.store.List(outboxBucket)
+	if err != nil || len(records) == 0 {
+		return
+	}
+
+	pending := make([]Progress, 0, len(records))
+	for _, data := range records {
+		var progress Progress
+		if err := json.Unmarshal(data, &progress); err == nil {
+			pending = append(pending, progress)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].Timestamp.Before(pending[j].Timestamp)
+	})
+
+	for _, progress := range pending {
+		if err := r.send(ctx, progress); err != nil && !errors.Is(err, errPermanent) {
+			r.logger.DebugContext(ctx, "Core API still unavailable, keeping callbacks in the outbox",
+				slog.Int("pending", len(pending)),
+				slog.String("error", err.Error()))
+			return
+		}
+		if err := r.store.Delete(outboxBucket, progress.ID); err != nil {
+			r.logger.WarnContext(ctx, "Failed to remove delivered callback from the outbox",
+				slog.String("id", progress.ID),
+				slog.String("error", err.Error()))
+		}
+	}
+	r.logger.InfoContext(ctx, "Delivered callbacks from the outbox", slog.Int("count", len(pending)))
+}
+
+// deliver sends progress, retrying with exponential backoff within the Core API's retry budget
+func (r *Reporter) deliver(ctx context.Context, progress Progress) error {
+	err := retry.For(retry.CoreAPI).Do(ctx, func(ctx context.Context) error {
+		err := r.send(ctx, progress)
+		if errors.Is(err, errPermanent) {
+			return retry.Permanent(err)
+		}
+		return err
+	}, retry.MaxAttempts(r.maxRetries+1))
+
+	if err != nil {
+		r.logger.WarnContext(ctx, "Failed to deliver provisioning callback",
+			slog.String("instance_id", progress.InstanceID),
+			slog.String("phase", progress.Phase),
+			slog.String("error", err.Error()))
+	}
+	return err
+}
+
+// send performs a single delivery attempt
+func (r *Reporter) send(ctx context.Context, progress Progress) error {
+	body, err := json.Marshal(progress)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errPermanent, err)
+	}
+
+	target := r.baseURL + strings.ReplaceAll(r.path, "{instance_id}", url.PathEscape(progress.InstanceID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errPermanent, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryHeader, progress.ID)
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("callback request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case retry.RetryableStatus(resp.StatusCode):
+		return fmt.Errorf("core API returned status %d", resp.StatusCode)
+	}
+	return fmt.Errorf("%w: core API returned status %d", errPermanent, resp.StatusCode)
+}
+
+// enqueueOutbox stores progress for a later flush
+func (r *Reporter) enqueueOutbox(progress Progress) {
+	if err := r.store.Put(outboxBucket, progress.ID, progress); err != nil {
+		r.logger.Error("Failed to store callback in the outbox",
+			slog.String("instance_id", progress.InstanceID),
+			slog.String("phase", progress.Phase),
+			slog.String("error", err.Error()))
+	}
+}
+
+// generateID generates a random progress identifier
+func generateID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "cb_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,88 @@
+package callbacks
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/internal/state"
+)
+
+func TestReporterOutbox(t *testing.T) {
+	var up atomic.Bool
+	var mutex sync.Mutex
+	var delivered []Progress
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path != "/v1/mcp-server-instances/inst-1/provisioning" {
+			t.Errorf("Expected the instance ID in the callback path, got %s", r.URL.Path)
+		}
+		var progress Progress
+		_ = json.NewDecoder(r.Body).Decode(&progress)
+		if r.Header.Get(DeliveryHeader) != progress.ID {
+			t.Errorf("Expected delivery header %s, got %s", progress.ID, r.Header.Get(DeliveryHeader))
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer manager-token" {
+			t.Errorf("Expected the service token on the callback, got %q", auth)
+		}
+		mutex.Lock()
+		delivered = append(delivered, progress)
+		mutex.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	store := state.NewStore("")
+	reporter := NewReporter(config.CallbackConfig{
+		Enabled:       true,
+		Path:          "/v1/mcp-server-instances/{instance_id}/provisioning",
+		Timeout:       time.Second,
+		FlushInterval: 20 * time.Millisecond,
+	}, server.URL, "manager-token", store, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reporter.Run(ctx)
+
+	reporter.Report(Progress{InstanceID: "inst-1", Name: "github", Phase: PhasePulling})
+	reporter.Report(Progress{InstanceID: "inst-1", Name: "github", Phase: PhasePulling})
+	reporter.Report(Progress{InstanceID: "inst-1", Name: "github", Phase: "starting"})
+	reporter.Report(Progress{InstanceID: "inst-1", Name: "github", Phase: "failed", Reason: "exit code 1"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for reporter.Pending() < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if pending := reporter.Pending(); pending != 3 {
+		t.Fatalf("Expected 3 callbacks in the outbox while the Core API is down, got %d", pending)
+	}
+
+	up.Store(true)
+	for reporter.Pending() > 0 && time.Now().Before(deadline.Add(time.Second)) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	phases := make([]string, 0, len(delivered))
+	for _, progress := range delivered {
+		phases = append(phases, progress.Phase)
+	}
+	if len(phases) != 3 || phases[0] != PhasePulling || phases[1] != "starting" || phases[2] != "failed" {
+		t.Errorf("Expected pulling, starting and failed delivered in order, got %v", phases)
+	}
+	if len(delivered) == 3 && delivered[2].Reason != "exit code 1" {
+		t.Errorf("Expected the failure reason delivered, got %q", delivered[2].Reason)
+	}
+}
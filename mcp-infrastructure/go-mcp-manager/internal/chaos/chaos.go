@@ -0,0 +1,157 @@
+// Package chaos injects failures into a running manager so the platform's retry and error
+// handling can be exercised end to end. It is for test environments only: faults are
+// configured through /debug/chaos, which is only served when CHAOS_ENABLED is set.
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Faults are the failures currently injected; the zero value injects nothing
+type Faults struct {
+	// PullDelaySeconds is added before every image pull
+	PullDelaySeconds int `json:"pull_delay_seconds,omitempty"`
+	// CrashAfterSeconds kills containers this long after they start
+	CrashAfterSeconds int `json:"crash_after_seconds,omitempty"`
+	// HealthCheckFailureRate is the share of health checks reported as failed, from 0 to 1
+	HealthCheckFailureRate float64 `json:"health_check_failure_rate,omitempty"`
+	// EventDropRate is the share of incoming instance events ignored, from 0 to 1
+	EventDropRate float64 `json:"event_drop_rate,omitempty"`
+	// Services limits container faults to these service names; empty applies them to all
+	Services []string `json:"services,omitempty"`
+}
+
+// Validate checks that delays are not negative and rates are between 0 and 1
+func (f Faults) Validate() error {
+	if f.PullDelaySeconds < 0 || f.CrashAfterSeconds < 0 {
+		return fmt.Errorf("delays cannot be negative")
+	}
+	for name, rate := range map[string]float64{
+		"health_check_failure_rate": f.HealthCheckFailureRate,
+		"event_drop_rate":           f.EventDropRate,
+	} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("%s must be between 0 and 1", name)
+		}
+	}
+	return nil
+}
+
+// appliesTo reports whether container faults target serviceName
+func (f Faults) appliesTo(serviceName string) bool {
+	return len(f.Services) == 0 || slices.Contains(f.Services, serviceName)
+}
+
+// Controller holds the injected faults. A nil or disabled controller injects nothing, so
+// callers never need to check whether chaos mode is on.
+type Controller struct {
+	enabled bool
+	logger  *slog.Logger
+
+	mutex  sync.Mutex
+	faults Faults
+	random *rand.Rand
+}
+
+// NewController creates a controller; when enabled is false it never injects a fault
+func NewController(enabled bool, logger *slog.Logger) *Controller {
+	if enabled {
+		logger.Warn("Chaos mode enabled: faults can be injected through /debug/chaos")
+	}
+	return &Controller{
+		enabled: enabled,
+		logger:  logger,
+		random:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Enabled reports whether faults can be injected
+func (c *Controller) Enabled() bool {
+	return c != nil && c.enabled
+}
+
+// Faults returns the faults currently injected
+func (c *Controller) Faults() Faults {
+	if !c.Enabled() {
+		return Faults{}
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.faults
+}
+
+// SetFaults replaces the injected faults
+func (c *Controller) SetFaults(faults Faults) error {
+	if !c.Enabled() {
+		return fmt.Errorf("chaos mode is not enabled")
+	}
+	if err := faults.Validate(); err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	c.faults = faults
+	c.mutex.Unlock()
+
+	c.logger.Warn("Chaos faults updated",
+		slog.Int("pull_delay_seconds", faults.PullDelaySeconds),
+		slog.Int("crash_after_seconds", faults.CrashAfterSeconds),
+		slog.Float64("health_check_failure_rate", faults.HealthCheckFailureRate),
+		slog.Float64("event_drop_rate", faults.EventDropRate),
+		slog.Any("services", faults.Services))
+	return nil
+}
+
+// DelayPull waits out the injected pull delay, returning early with ctx's error
+func (c *Controller) DelayPull(ctx context.Context, image string) error {
+	delay := time.Duration(c.Faults().PullDelaySeconds) * time.Second
+	if delay <= 0 {
+		return nil
+	}
+
+	c.logger.WarnContext(ctx, "Chaos: delaying image pull",
+		slog.String("image", image),
+		slog.Duration("delay", delay))
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+// CrashAfter returns how long after starting serviceName's container should be killed, or 0
+func (c *Controller) CrashAfter(serviceName string) time.Duration {
+	faults := c.Faults()
+	if !faults.appliesTo(serviceName) {
+		return 0
+	}
+	return time.Duration(faults.CrashAfterSeconds) * time.Second
+}
+
+// FailHealthCheck decides whether this health check of serviceName is reported as failed
+func (c *Controller) FailHealthCheck(serviceName string) bool {
+	faults := c.Faults()
+	return faults.appliesTo(serviceName) && c.roll(faults.HealthCheckFailureRate)
+}
+
+// DropEvent decides whether an incoming event is ignored
+func (c *Controller) DropEvent() bool {
+	return c.roll(c.Faults().EventDropRate)
+}
+
+// roll returns true with probability rate
+func (c *Controller) roll(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.random.Float64() < rate
+}
@@ -0,0 +1,64 @@
+package chaos
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestController(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	var nilController *Controller
+	if nilController.Enabled() || nilController.FailHealthCheck("svc") || nilController.DropEvent() || nilController.CrashAfter("svc") != 0 {
+		t.Errorf("Expected a nil controller to inject nothing")
+	}
+	if err := nilController.DelayPull(context.Background(), "image"); err != nil {
+		t.Errorf("Expected no pull delay from a nil controller, got %v", err)
+	}
+
+	disabled := NewController(false, logger)
+	if err := disabled.SetFaults(Faults{EventDropRate: 1}); err == nil {
+		t.Errorf("Expected setting faults on a disabled controller to fail")
+	}
+	if disabled.DropEvent() {
+		t.Errorf("Expected a disabled controller not to drop events")
+	}
+
+	controller := NewController(true, logger)
+	for _, faults := range []Faults{{PullDelaySeconds: -1}, {CrashAfterSeconds: -1}, {HealthCheckFailureRate: 1.5}, {EventDropRate: -0.1}} {
+		if err := controller.SetFaults(faults); err == nil {
+			t.Errorf("Expected %+v to be rejected", faults)
+		}
+	}
+
+	if err := controller.SetFaults(Faults{CrashAfterSeconds: 5, HealthCheckFailureRate: 1, EventDropRate: 1, Services: []string{"flaky"}}); err != nil {
+		t.Fatalf("Expected faults to be accepted, got %v", err)
+	}
+	if got := controller.CrashAfter("flaky"); got != 5*time.Second {
+		t.Errorf("Expected crash after 5s, got %v", got)
+	}
+	if got := controller.CrashAfter("other"); got != 0 {
+		t.Errorf("Expected no crash for a service outside the filter, got %v", got)
+	}
+	if !controller.FailHealthCheck("flaky") || controller.FailHealthCheck("other") {
+		t.Errorf("Expected health checks to fail only for the targeted service")
+	}
+	if !controller.DropEvent() {
+		t.Errorf("Expected events to be dropped at rate 1")
+	}
+
+	if err := controller.SetFaults(Faults{PullDelaySeconds: 60}); err != nil {
+		t.Fatalf("Expected faults to be accepted, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := controller.DelayPull(ctx, "image"); err == nil {
+		t.Errorf("Expected a cancelled context to cut the pull delay short")
+	}
+	if controller.DropEvent() || controller.FailHealthCheck("flaky") {
+		t.Errorf("Expected replaced faults to stop dropping events and failing health checks")
+	}
+}
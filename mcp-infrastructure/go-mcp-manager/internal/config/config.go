@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	goruntime "runtime"
 	"strconv"
 	"strings"
 	"time"
@@ -28,12 +29,81 @@ type Config struct {
 
 	// Core API configuration
 	CoreAPIURL string `json:"core_api_url"`
+	// Service token sent as a bearer token on heartbeats and callbacks; the Core API
+	// only accepts manager writes carrying it
+	CoreAPIToken string `json:"-"`
+
+	// Provisioning progress reported back to the Core API
+	Callbacks CallbackConfig `json:"callbacks"`
+
+	// Manager registration and heartbeat with the Core API
+	Registration RegistrationConfig `json:"registration"`
+
+	// Fault injection for integration testing
+	Chaos ChaosConfig `json:"chaos"`
+
+	// Simulated backend selected with BACKEND_ENVIRONMENT=fake
+	Fake FakeConfig `json:"fake"`
 
 	// Kubernetes configuration
 	Kubernetes KubernetesConfig `json:"kubernetes"`
 
 	// Environment override (for forcing backend selection)
 	Environment string `json:"environment"`
+
+	// Webhook notification configuration
+	Webhooks WebhookConfig `json:"webhooks"`
+
+	// Spot/preemptible host configuration
+	Preemption PreemptionConfig `json:"preemption"`
+
+	// Persistent manager state configuration
+	State StateConfig `json:"state"`
+
+	// Inactive instance archiving configuration
+	Archive ArchiveConfig `json:"archive"`
+
+	// Unused image and build cache garbage collection
+	GC GCConfig `json:"gc"`
+
+	// Free space required on image storage before creating containers
+	Storage StorageConfig `json:"storage"`
+
+	// Host memory and CPU new containers may claim
+	Admission AdmissionConfig `json:"admission"`
+
+	// Pull-through registry cache images are pulled through
+	RegistryMirror RegistryMirrorConfig `json:"registry_mirror"`
+
+	// Container log forwarding to an external log store
+	LogShipping LogShippingConfig `json:"log_shipping"`
+
+	// HTTP API rate limiting and create concurrency configuration
+	RateLimit RateLimitConfig `json:"rate_limit"`
+
+	// Background health monitoring configuration
+	HealthMonitor HealthMonitorConfig `json:"health_monitor"`
+
+	// GPU configuration
+	GPU GPUConfig `json:"gpu"`
+
+	// Per-workspace container networks
+	Network NetworkConfig `json:"network"`
+
+	// Mutual TLS on the management API and between the proxy and containers
+	MTLS MTLSConfig `json:"mtls"`
+
+	// Role-based access control on the management API
+	Authz AuthzConfig `json:"authz"`
+
+	// Retries of podman, Redis, secrets and Core API calls
+	Retry RetryConfig `json:"retry"`
+
+	// Environment self-test served at /admin/doctor and run with --self-test
+	Doctor DoctorConfig `json:"doctor"`
+
+	// Alert rules evaluated by the manager and the notifiers alerts are sent to
+	Alerting AlertingConfig `json:"alerting"`
 }
 
 // ServerConfig holds HTTP server configuration
@@ -45,6 +115,9 @@ type ServerConfig struct {
 	// CORS configuration
 	CORSEnabled        bool     `json:"cors_enabled"`
 	CORSAllowedOrigins []string `json:"cors_allowed_origins"`
+	// TrustedProxies are the addresses or CIDRs whose X-Forwarded-For header names the client;
+	// empty trusts none, so clients are identified by their peer address
+	TrustedProxies []string `json:"trusted_proxies"`
 }
 
 // ContainerConfig holds container runtime configuration
@@ -54,16 +127,106 @@ type ContainerConfig struct {
 	StorageRunroot   string `json:"storage_runroot"`
 	StorageGraphroot string `json:"storage_graphroot"`
 
+	// Host is the engine socket, such as a podman machine's or Docker Desktop's; empty uses the
+	// local engine
+	Host string `json:"host"`
+	// PublishPorts publishes container ports on loopback and routes there, for engines in a VM
+	// whose container IPs the host cannot reach; on by default on macOS and Windows
+	PublishPorts bool `json:"publish_ports"`
+
 	// Management settings
 	NamePrefix      string        `json:"name_prefix"`
 	ManagedByLabel  string        `json:"managed_by_label"`
 	MaxContainers   int           `json:"max_containers"`
 	StartupTimeout  time.Duration `json:"startup_timeout"`
 	ShutdownTimeout time.Duration `json:"shutdown_timeout"`
+	// DrainTimeout is how long shutdown waits for creates and deletes before rolling them back
+	DrainTimeout time.Duration `json:"drain_timeout"`
 
 	// Resource limits
 	DefaultMemoryLimit string `json:"default_memory_limit"`
 	DefaultCPULimit    string `json:"default_cpu_limit"`
+	DefaultPidsLimit   int    `json:"default_pids_limit"`
+
+	// Per-instance quota policy; empty or zero leaves the resource uncapped
+	MaxMemoryLimit      string `json:"max_memory_limit"`
+	MaxCPULimit         string `json:"max_cpu_limit"`
+	MaxPidsLimit        int    `json:"max_pids_limit"`
+	MaxEphemeralStorage string `json:"max_ephemeral_storage"`
+
+	// Hardening applied to every podman container
+	Security ContainerSecurityConfig `json:"security"`
+
+	// Limits for tmpfs and scratch volume mounts requested in json_spec
+	DefaultScratchSize string `json:"default_scratch_size"`
+	MaxScratchSize     string `json:"max_scratch_size"`
+	MaxScratchMounts   int    `json:"max_scratch_mounts"`
+
+	// Default and maximum run time of json_spec init commands
+	InitTimeout    time.Duration `json:"init_timeout"`
+	MaxInitTimeout time.Duration `json:"max_init_timeout"`
+
+	// Maximum run time of an image build from json_spec source
+	BuildTimeout time.Duration `json:"build_timeout"`
+
+	// Defaults for every container: DNS servers, "host:ip" hosts entries and the HTTP proxy, for
+	// networks where external APIs are only reachable through a corporate proxy
+	DNS        []string `json:"dns"`
+	ExtraHosts []string `json:"extra_hosts"`
+	HTTPProxy  string   `json:"http_proxy"`
+	HTTPSProxy string   `json:"https_proxy"`
+	NoProxy    string   `json:"no_proxy"`
+
+	// AllowEmulation runs images published without a variant for the host's architecture under
+	// qemu user-mode emulation instead of rejecting them
+	AllowEmulation bool `json:"allow_emulation"`
+
+	// Base images that bridge npx and uvx stdio servers to HTTP for json_spec runtime shortcuts
+	NpxRuntimeImage string `json:"npx_runtime_image"`
+	UvxRuntimeImage string `json:"uvx_runtime_image"`
+
+	// Supervisor is "podman" to run containers directly, or "systemd" to run each under a
+	// generated unit that brings it back after manager restarts and host reboots
+	Supervisor string `json:"supervisor"`
+	// SystemdUnitDir is where units are written; empty uses the user or system unit directory
+	SystemdUnitDir string `json:"systemd_unit_dir"`
+	// SystemdUser manages units with systemctl --user, as rootless podman needs
+	SystemdUser bool `json:"systemd_user"`
+
+	// CheckpointDir holds the archives containers are checkpointed to with CRIU
+	CheckpointDir string `json:"checkpoint_dir"`
+
+	// Exec lets admins run diagnostic commands inside containers
+	Exec ExecConfig `json:"exec"`
+
+	// Directories files may be copied into and out of containers under, and the largest file
+	FileAllowedPaths []string `json:"file_allowed_paths"`
+	FileMaxBytes     int64    `json:"file_max_bytes"`
+}
+
+// ExecConfig guards the diagnostic commands admins may run inside containers
+type ExecConfig struct {
+	Enabled bool `json:"enabled"`
+	// AllowedCommands are the programs that may be run, matched against the command's first word
+	AllowedCommands []string `json:"allowed_commands"`
+	// MaxOutputBytes caps stdout and stderr each; longer output is cut off
+	MaxOutputBytes int           `json:"max_output_bytes"`
+	Timeout        time.Duration `json:"timeout"`
+	// MaxAuditRecords is how many exec attempts the audit log keeps
+	MaxAuditRecords int `json:"max_audit_records"`
+}
+
+// ContainerSecurityConfig holds the hardened defaults for podman containers and what json_spec may relax
+type ContainerSecurityConfig struct {
+	// Hardened runs containers with a read-only rootfs, all capabilities dropped and no-new-privileges
+	Hardened    bool   `json:"hardened"`
+	DefaultUser string `json:"default_user"`
+	// SeccompProfile is the default seccomp profile path; empty keeps podman's built-in profile
+	SeccompProfile    string `json:"seccomp_profile"`
+	SeccompProfileDir string `json:"seccomp_profile_dir"`
+	// AllowedCapabilities are the capabilities json_spec may add back
+	AllowedCapabilities []string `json:"allowed_capabilities"`
+	AllowRoot           bool     `json:"allow_root"`
 }
 
 // TraefikConfig holds Traefik configuration
@@ -73,17 +236,391 @@ type TraefikConfig struct {
 	DefaultDomain     string `json:"default_domain"`
 	ProxyHost         string `json:"proxy_host"`
 	ManagerServiceURL string `json:"manager_service_url"`
+	// RouteReconcileInterval is how often container IPs are compared against their routes; zero disables it
+	RouteReconcileInterval time.Duration `json:"route_reconcile_interval"`
+	// PortProbeTimeout is how long a new container's port may take to accept connections before the
+	// route follows another port that does; zero disables probing
+	PortProbeTimeout time.Duration `json:"port_probe_timeout"`
+	// CertResolver names the Traefik certificate resolver used for host-routed containers; empty uses the default certificate
+	CertResolver string `json:"cert_resolver"`
+
+	// Mode is "embedded" to run and supervise Traefik, or "external" when Traefik is managed elsewhere
+	Mode string `json:"mode"`
+	// ConfigDir holds the static and dynamic Traefik configuration files
+	ConfigDir         string        `json:"config_dir"`
+	Binary            string        `json:"binary"`
+	RestartMaxBackoff time.Duration `json:"restart_max_backoff"`
+
+	// Static configuration written for the embedded Traefik
+	WebAddress       string                 `json:"web_address"`
+	WebSecureAddress string                 `json:"websecure_address"`
+	LogLevel         string                 `json:"log_level"`
+	Dashboard        TraefikDashboardConfig `json:"dashboard"`
+	AccessLog        TraefikAccessLogConfig `json:"access_log"`
+	Metrics          TraefikMetricsConfig   `json:"metrics"`
+
+	// CircuitBreaker is applied to every MCP route unless the route disables it
+	CircuitBreaker TraefikCircuitBreakerConfig `json:"circuit_breaker"`
+
+	// Limits applies to every MCP route unless the route sets its own
+	Limits TraefikRouteLimitsConfig `json:"limits"`
+
+	// Upstream tunes the connection pools to MCP containers, in Traefik and in the manager's own probes
+	Upstream UpstreamPoolConfig `json:"upstream"`
+}
+
+// UpstreamPoolConfig holds connection pool and keep-alive settings for connections to MCP containers
+type UpstreamPoolConfig struct {
+	// MaxIdleConnsPerHost keeps this many connections per container open for reuse; connections
+	// beyond it are closed after each request, which costs an ephemeral port in TIME_WAIT
+	MaxIdleConnsPerHost int           `json:"max_idle_conns_per_host"`
+	IdleConnTimeout     time.Duration `json:"idle_conn_timeout"`
+	DialTimeout         time.Duration `json:"dial_timeout"`
+	// KeepAlive is the TCP keep-alive probe interval of the manager's connections; Traefik uses its own
+	KeepAlive time.Duration `json:"keep_alive"`
+}
+
+// TraefikCircuitBreakerConfig holds the default circuit breaker of MCP routes
+type TraefikCircuitBreakerConfig struct {
+	Enabled    bool   `json:"enabled"`
+	Expression string `json:"expression"`
+	// FallbackDuration is how long requests are rejected with 503 once the breaker trips
+	FallbackDuration time.Duration `json:"fallback_duration"`
+	// RecoveryDuration is how long traffic is ramped back up before the breaker closes
+	RecoveryDuration time.Duration `json:"recovery_duration"`
+}
+
+// TraefikRouteLimitsConfig holds the default size and timeout limits of MCP routes; zero leaves a
+// limit unset
+type TraefikRouteLimitsConfig struct {
+	MaxRequestBodyBytes  int64 `json:"max_request_body_bytes"`
+	MaxResponseBodyBytes int64 `json:"max_response_body_bytes"`
+	MaxHeaderBytes       int   `json:"max_header_bytes"`
+	// Timeout is how long an MCP server may take to start its response before the proxy answers 504
+	Timeout time.Duration `json:"timeout"`
+	// MaxConcurrentRequests is how many requests an MCP server is sent at once before the proxy answers 429
+	MaxConcurrentRequests int `json:"max_concurrent_requests"`
+}
+
+// TraefikDashboardConfig controls the Traefik dashboard and API
+type TraefikDashboardConfig struct {
+	Enabled bool   `json:"enabled"`
+	Address string `json:"address"`
+	// Users are htpasswd entries ("user:hash"); when set the dashboard requires basic auth
+	Users []string `json:"-"`
+}
+
+// TraefikAccessLogConfig controls Traefik's request access log
+type TraefikAccessLogConfig struct {
+	Enabled bool `json:"enabled"`
+	// Format is "common" or "json"
+	Format string `json:"format"`
+	// FilePath writes the log to a file instead of stdout
+	FilePath string `json:"file_path"`
+}
+
+// TraefikMetricsConfig controls Traefik's Prometheus metrics endpoint
+type TraefikMetricsConfig struct {
+	Enabled bool   `json:"enabled"`
+	Address string `json:"address"`
 }
 
 // LoggingConfig holds logging configuration
 type LoggingConfig struct {
 	Level  string `json:"level"`
 	Format string `json:"format"`
+	// BufferLines is how many recent entries are kept in memory for support bundles; 0 keeps none
+	BufferLines int `json:"buffer_lines"`
 }
 
 // RedisConfig holds Redis configuration for event handling
 type RedisConfig struct {
 	URL string `json:"url"`
+	// SigningSecret and SigningJWKSURL make the manager act only on create and delete events
+	// signed with the shared HMAC secret or a key of the platform's JWKS. With SigningSecret the
+	// manager signs the events it publishes the same way.
+	SigningSecret  string `json:"-"`
+	SigningJWKSURL string `json:"signing_jwks_url"`
+	SigningIssuer  string `json:"signing_issuer"`
+	// SignatureMaxAge rejects signed events older than this, and how long event IDs are remembered
+	SignatureMaxAge time.Duration `json:"signature_max_age"`
+	// AcceptLegacyEvents decodes events without a schema_version through the compatibility shim
+	AcceptLegacyEvents bool `json:"accept_legacy_events"`
+	// OutboxFlushInterval is how often events Redis did not take are resent from the outbox
+	OutboxFlushInterval time.Duration `json:"outbox_flush_interval"`
+}
+
+// SigningEnabled reports whether events must be signed
+func (c RedisConfig) SigningEnabled() bool {
+	return c.SigningSecret != "" || c.SigningJWKSURL != ""
+}
+
+// WebhookConfig holds configuration for outgoing lifecycle webhooks
+type WebhookConfig struct {
+	URLs       []string      `json:"urls"`
+	Secret     string        `json:"-"`
+	Timeout    time.Duration `json:"timeout"`
+	MaxRetries int           `json:"max_retries"`
+}
+
+// CallbackConfig holds configuration for provisioning progress POSTed to the Core API
+type CallbackConfig struct {
+	Enabled bool `json:"enabled"`
+	// Path is appended to CoreAPIURL; {instance_id} is replaced with the instance's ID
+	Path       string        `json:"path"`
+	Timeout    time.Duration `json:"timeout"`
+	MaxRetries int           `json:"max_retries"`
+	// FlushInterval is how often callbacks that could not be delivered are retried from the outbox
+	FlushInterval time.Duration `json:"flush_interval"`
+}
+
+// RegistrationConfig holds configuration for the heartbeat registering this manager with the Core API
+type RegistrationConfig struct {
+	Enabled bool `json:"enabled"`
+	// ManagerID identifies this manager among others; defaults to the host name
+	ManagerID string `json:"manager_id"`
+	// AdvertiseURL is where the platform reaches this manager's HTTP API
+	AdvertiseURL string `json:"advertise_url"`
+	// Path is appended to CoreAPIURL; {manager_id} is replaced with ManagerID
+	Path     string        `json:"path"`
+	Interval time.Duration `json:"interval"`
+	Timeout  time.Duration `json:"timeout"`
+}
+
+// FakeConfig holds configuration for the in-memory backend used without a container runtime
+type FakeConfig struct {
+	// StartupDelay is how long a simulated instance stays starting before it runs or fails
+	StartupDelay time.Duration `json:"startup_delay"`
+	// FailureRate is the share of simulated instances that fail to start, from 0 to 1
+	FailureRate float64 `json:"failure_rate"`
+}
+
+// ChaosConfig holds configuration for fault injection; never enable it in production
+type ChaosConfig struct {
+	// Enabled serves /debug/chaos, through which faults are injected
+	Enabled bool `json:"enabled"`
+}
+
+// PreemptionConfig holds configuration for spot/preemptible host awareness
+type PreemptionConfig struct {
+	// Preemptible marks this host as running on spot/preemptible capacity
+	Preemptible bool `json:"preemptible"`
+	// Provider selects the cloud metadata format to poll ("aws", "gcp" or empty for API-only)
+	Provider     string        `json:"provider"`
+	MetadataURL  string        `json:"metadata_url"`
+	PollInterval time.Duration `json:"poll_interval"`
+}
+
+// StateConfig holds configuration for the manager's persistent state
+type StateConfig struct {
+	// Dir is where state files are written; empty keeps state in memory only
+	Dir string `json:"dir"`
+}
+
+// MTLSConfig holds mutual TLS settings for the management API and the proxy's hops to containers
+type MTLSConfig struct {
+	// CertFile and KeyFile serve the management API over TLS, requiring client certificates issued
+	// by ClientCAFile; empty serves plain HTTP
+	CertFile     string `json:"cert_file"`
+	KeyFile      string `json:"key_file"`
+	ClientCAFile string `json:"client_ca_file"`
+	// AllowedSPIFFEIDs admits only clients whose certificate carries one of these URI SANs; empty
+	// admits any verified client
+	AllowedSPIFFEIDs []string `json:"allowed_spiffe_ids"`
+	// Upstream issues every new container a server certificate and the proxy a client certificate
+	// for it, so the hop between them is mutual TLS
+	Upstream bool `json:"upstream"`
+	// Dir holds the manager's internal CA and the certificates it issues
+	Dir string `json:"dir"`
+}
+
+// APIEnabled reports whether the management API is served over mutual TLS
+func (c MTLSConfig) APIEnabled() bool {
+	return c.CertFile != "" && c.KeyFile != ""
+}
+
+// RetryConfig holds the backoff and retry budget applied to each external dependency
+type RetryConfig struct {
+	MaxAttempts    int           `json:"max_attempts"`
+	InitialBackoff time.Duration `json:"initial_backoff"`
+	MaxBackoff     time.Duration `json:"max_backoff"`
+	// BudgetRatio is the retries each call earns; BudgetMin the retries always allowed
+	BudgetRatio float64 `json:"budget_ratio"`
+	BudgetMin   float64 `json:"budget_min"`
+}
+
+// DoctorConfig holds the environment self-test's settings
+type DoctorConfig struct {
+	// Image is pulled and run as the test container; it must provide sleep
+	Image string `json:"image"`
+	// Timeout bounds the whole self-test
+	Timeout time.Duration `json:"timeout"`
+}
+
+// AlertingConfig holds the alert rules read at startup and where alert notifications are sent
+type AlertingConfig struct {
+	// RulesFile is a JSON array of alert rules; rules added through the API are kept in the state store
+	RulesFile          string        `json:"rules_file"`
+	EvaluationInterval time.Duration `json:"evaluation_interval"`
+	// SlackWebhookURL is a Slack incoming webhook; the URL is its credential
+	SlackWebhookURL string `json:"-"`
+	// PagerDutyRoutingKey is the integration key of a PagerDuty Events API v2 service
+	PagerDutyRoutingKey string `json:"-"`
+	PagerDutyURL        string `json:"pagerduty_url"`
+	// EventBus publishes alerts as instance warnings on the platform event bus
+	EventBus bool `json:"event_bus"`
+	// Timeout bounds each delivery attempt of a notification
+	Timeout time.Duration `json:"timeout"`
+}
+
+// AuthzConfig holds how API callers are authenticated and bound to roles
+type AuthzConfig struct {
+	// APIKeysFile is a JSON file binding API key hashes to roles and workspaces
+	APIKeysFile string `json:"api_keys_file"`
+	// JWKSURL publishes the keys bearer JWTs are verified with
+	JWKSURL      string        `json:"jwks_url"`
+	JWKSCacheTTL time.Duration `json:"jwks_cache_ttl"`
+	JWTIssuer    string        `json:"jwt_issuer"`
+	JWTAudience  string        `json:"jwt_audience"`
+	// RoleClaim and WorkspacesClaim name the JWT claims holding the role and the workspaces
+	RoleClaim       string `json:"role_claim"`
+	WorkspacesClaim string `json:"workspaces_claim"`
+}
+
+// Enabled reports whether API callers must authenticate
+func (c AuthzConfig) Enabled() bool {
+	return c.APIKeysFile != "" || c.JWKSURL != ""
+}
+
+// ArchiveConfig holds configuration for archiving long-inactive instances
+type ArchiveConfig struct {
+	// InactiveAfter archives stopped instances not started for this long; zero disables archiving
+	InactiveAfter time.Duration `json:"inactive_after"`
+	CheckInterval time.Duration `json:"check_interval"`
+}
+
+// GCConfig holds configuration for removing unused images and build cache
+type GCConfig struct {
+	// Interval between periodic runs; zero disables them, POST /admin/gc still runs on demand
+	Interval time.Duration `json:"interval"`
+	// UnusedFor is how long no container may have used an image before it is removed
+	UnusedFor time.Duration `json:"unused_for"`
+	// DiskThresholdPercent skips periodic runs while image storage usage is below it; zero always runs
+	DiskThresholdPercent float64 `json:"disk_threshold_percent"`
+	// PruneBuildCache also removes the build cache left by source builds
+	PruneBuildCache bool `json:"prune_build_cache"`
+}
+
+// StorageConfig holds the free space creates require on the image storage filesystem.
+// Zero disables a limit; with both set, dropping below either refuses creates.
+type StorageConfig struct {
+	MinFreeBytes   uint64  `json:"min_free_bytes"`
+	MinFreePercent float64 `json:"min_free_percent"`
+}
+
+// AdmissionConfig holds how much of the host's memory and CPU new containers may claim. Every
+// create is scored against it; one that does not fit is refused only with Enforce.
+type AdmissionConfig struct {
+	Enforce bool `json:"enforce"`
+	// MemoryReserveBytes of the host's free memory are kept for everything but new containers
+	MemoryReserveBytes uint64 `json:"memory_reserve_bytes"`
+	// CPUOvercommit is how many times the host's CPUs the CPU limits of containers may add up to
+	CPUOvercommit float64 `json:"cpu_overcommit"`
+	// DecisionLogSize is how many recent decisions are kept for GET /scheduler/decisions
+	DecisionLogSize int `json:"decision_log_size"`
+}
+
+// RegistryMirrorConfig holds the pull-through cache that images of one upstream registry are
+// pulled through, so repeated pulls are served locally instead of counting against its rate limits
+type RegistryMirrorConfig struct {
+	// Host is the mirror's registry host, e.g. "mirror.internal:5000"; empty with Provision uses
+	// the provisioned mirror and disables pulling through a mirror otherwise
+	Host string `json:"host"`
+	// Upstream is the registry whose images are pulled through the mirror
+	Upstream string `json:"upstream"`
+	// Insecure pulls from the mirror over plain HTTP, as a mirror on loopback usually serves
+	Insecure bool `json:"insecure"`
+	// MetricsURL serves the mirror's expvar counters, from which cache hits are reported
+	MetricsURL string `json:"metrics_url"`
+
+	// Provision runs a registry container as the mirror, published on loopback at Port, with its
+	// debug server, which serves the metrics, on Port+1
+	Provision bool   `json:"provision"`
+	Image     string `json:"image"`
+	Port      int    `json:"port"`
+}
+
+// LogShippingConfig holds the default sink container logs are forwarded to
+type LogShippingConfig struct {
+	// Sink is "loki", "opensearch" or "http"; empty ships only instances that set their own sink
+	Sink string `json:"sink"`
+	URL  string `json:"url"`
+	// Index is the OpenSearch index documents are written to
+	Index string `json:"index"`
+	// AuthHeader is sent as the Authorization header to the default sink
+	AuthHeader    string        `json:"-"`
+	BatchSize     int           `json:"batch_size"`
+	FlushInterval time.Duration `json:"flush_interval"`
+	Timeout       time.Duration `json:"timeout"`
+}
+
+// RateLimitConfig holds per-client API rate limits and the global create concurrency cap
+type RateLimitConfig struct {
+	Enabled           bool    `json:"enabled"`
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	Burst             int     `json:"burst"`
+
+	// MaxConcurrentCreates caps simultaneous podman runs; extra creates wait in a bounded queue
+	MaxConcurrentCreates int           `json:"max_concurrent_creates"`
+	CreateQueueSize      int           `json:"create_queue_size"`
+	CreateQueueTimeout   time.Duration `json:"create_queue_timeout"`
+}
+
+// HealthMonitorConfig holds configuration for the background health monitoring loop
+type HealthMonitorConfig struct {
+	// Workers is the number of health checks run in parallel
+	Workers int `json:"workers"`
+	// HistorySize is the number of results kept per container
+	HistorySize int `json:"history_size"`
+	// MaxStaleness is how old a background result may be before an API health check probes again
+	MaxStaleness time.Duration `json:"max_staleness"`
+	// InspectCacheTTL is how long podman inspect results are reused unless a podman event invalidates them
+	InspectCacheTTL time.Duration `json:"inspect_cache_ttl"`
+	// CrashLoopThreshold is how many exits within CrashLoopWindow put a container in crash_loop,
+	// ending its restarts; zero leaves exited containers stopped
+	CrashLoopThreshold int           `json:"crash_loop_threshold"`
+	CrashLoopWindow    time.Duration `json:"crash_loop_window"`
+	// CrashLoopLogLines is how many lines of output the crash_loop event keeps
+	CrashLoopLogLines int `json:"crash_loop_log_lines"`
+	// RestartBackoff is the delay before restarting an exited container, doubled for every
+	// further exit within CrashLoopWindow up to RestartMaxBackoff
+	RestartBackoff    time.Duration `json:"restart_backoff"`
+	RestartMaxBackoff time.Duration `json:"restart_max_backoff"`
+	// ReadinessGatesRoutes registers a container's route only while it answers the MCP handshake
+	ReadinessGatesRoutes bool `json:"readiness_gates_routes"`
+}
+
+// GPUConfig describes the GPUs available for passthrough on this host
+type GPUConfig struct {
+	// Count is the number of GPUs that can be assigned; zero rejects GPU instances
+	Count int `json:"count"`
+	// CDIPrefix is the CDI device kind GPUs are requested as, e.g. "nvidia.com/gpu"
+	CDIPrefix string `json:"cdi_prefix"`
+}
+
+// NetworkConfig holds configuration for isolating workspaces on their own podman networks
+type NetworkConfig struct {
+	// PerWorkspace puts each workspace's containers on a dedicated network instead of Traefik.Network
+	PerWorkspace bool   `json:"per_workspace"`
+	Prefix       string `json:"prefix"`
+	// Isolate blocks traffic between workspace networks (netavark isolate option)
+	Isolate bool `json:"isolate"`
+	// ProxyContainer is connected to every workspace network so Traefik can reach instances;
+	// empty uses this host's name, which is the manager container when Traefik is embedded
+	ProxyContainer string `json:"proxy_container"`
+	// Pods runs each workspace's containers in a podman pod so they reach each other on localhost
+	Pods      bool   `json:"pods"`
+	PodPrefix string `json:"pod_prefix"`
 }
 
 // Load loads configuration from environment variables with sensible defaults
@@ -97,40 +634,299 @@ func Load() *Config {
 			// CORS disabled by default for security
 			CORSEnabled:        getEnvBool("CORS_ENABLED", false),
 			CORSAllowedOrigins: getEnvStringSlice("CORS_ALLOWED_ORIGINS", []string{}),
+			TrustedProxies:     getEnvStringSlice("SERVER_TRUSTED_PROXIES", nil),
 		},
 		Container: ContainerConfig{
 			Runtime:            getEnv("CONTAINER_RUNTIME", "podman"),
 			StorageDriver:      getEnv("CONTAINERS_STORAGE_DRIVER", "overlay"),
 			StorageRunroot:     getEnv("CONTAINERS_STORAGE_RUNROOT", "/tmp/containers"),
 			StorageGraphroot:   getEnv("CONTAINERS_STORAGE_GRAPHROOT", "/var/lib/containers/storage"),
+			Host:               getEnv("CONTAINER_HOST", ""),
+			PublishPorts:       getEnvBool("CONTAINER_PUBLISH_PORTS", goruntime.GOOS != "linux"),
 			NamePrefix:         getEnv("CONTAINER_NAME_PREFIX", "mcp-"),
 			ManagedByLabel:     getEnv("CONTAINER_MANAGED_BY_LABEL", "mcp-manager"),
 			MaxContainers:      getEnvInt("MAX_CONTAINERS", 50),
 			StartupTimeout:     getEnvDuration("STARTUP_TIMEOUT", 120*time.Second),
 			ShutdownTimeout:    getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+			DrainTimeout:       getEnvDuration("SHUTDOWN_DRAIN_TIMEOUT", 20*time.Second),
 			DefaultMemoryLimit: getEnv("DEFAULT_MEMORY_LIMIT", "512m"),
 			DefaultCPULimit:    getEnv("DEFAULT_CPU_LIMIT", "1.0"),
+			DefaultPidsLimit:   getEnvInt("DEFAULT_PIDS_LIMIT", 512),
+
+			MaxMemoryLimit:      getEnv("MAX_MEMORY_LIMIT", "4g"),
+			MaxCPULimit:         getEnv("MAX_CPU_LIMIT", "4.0"),
+			MaxPidsLimit:        getEnvInt("MAX_PIDS_LIMIT", 4096),
+			MaxEphemeralStorage: getEnv("MAX_EPHEMERAL_STORAGE", ""),
+
+			DefaultScratchSize: getEnv("SCRATCH_DEFAULT_SIZE", "64m"),
+			MaxScratchSize:     getEnv("SCRATCH_MAX_SIZE", "1g"),
+			MaxScratchMounts:   getEnvInt("SCRATCH_MAX_MOUNTS", 8),
+
+			InitTimeout:    getEnvDuration("INIT_TIMEOUT", 10*time.Minute),
+			MaxInitTimeout: getEnvDuration("INIT_MAX_TIMEOUT", time.Hour),
+
+			BuildTimeout: getEnvDuration("BUILD_TIMEOUT", 30*time.Minute),
+
+			DNS:        getEnvStringSlice("CONTAINER_DNS", []string{}),
+			ExtraHosts: getEnvStringSlice("CONTAINER_EXTRA_HOSTS", []string{}),
+			HTTPProxy:  getEnv("CONTAINER_HTTP_PROXY", ""),
+			HTTPSProxy: getEnv("CONTAINER_HTTPS_PROXY", ""),
+			NoProxy:    getEnv("CONTAINER_NO_PROXY", ""),
+
+			AllowEmulation: getEnvBool("IMAGE_ALLOW_EMULATION", false),
+
+			NpxRuntimeImage: getEnv("RUNTIME_NPX_IMAGE", "docker.io/supercorp/supergateway:latest"),
+			UvxRuntimeImage: getEnv("RUNTIME_UVX_IMAGE", "docker.io/supercorp/supergateway:uvx"),
+
+			Supervisor:     getEnv("CONTAINER_SUPERVISOR", "podman"),
+			SystemdUnitDir: getEnv("SYSTEMD_UNIT_DIR", ""),
+			SystemdUser:    getEnvBool("SYSTEMD_USER", os.Geteuid() != 0),
+
+			CheckpointDir: getEnv("CHECKPOINT_DIR", "/var/lib/mcp-manager/checkpoints"),
+
+			Exec: ExecConfig{
+				Enabled:         getEnvBool("EXEC_ENABLED", false),
+				AllowedCommands: getEnvStringSlice("EXEC_ALLOWED_COMMANDS", []string{"cat", "ls", "stat", "head", "tail", "wc", "df", "du", "ps", "id", "uname", "date"}),
+				MaxOutputBytes:  getEnvInt("EXEC_MAX_OUTPUT_BYTES", 64*1024),
+				Timeout:         getEnvDuration("EXEC_TIMEOUT", 10*time.Second),
+				MaxAuditRecords: getEnvInt("EXEC_MAX_AUDIT_RECORDS", 1000),
+			},
+
+			FileAllowedPaths: getEnvStringSlice("FILES_ALLOWED_PATHS", []string{"/config", "/tmp"}),
+			FileMaxBytes:     int64(getEnvInt("FILES_MAX_BYTES", 1024*1024)),
+
+			Security: ContainerSecurityConfig{
+				Hardened:            getEnvBool("CONTAINER_HARDENED", true),
+				DefaultUser:         getEnv("CONTAINER_DEFAULT_USER", "1000:1000"),
+				SeccompProfile:      getEnv("CONTAINER_SECCOMP_PROFILE", ""),
+				SeccompProfileDir:   getEnv("CONTAINER_SECCOMP_PROFILE_DIR", "/etc/mcp-manager/seccomp"),
+				AllowedCapabilities: getEnvStringSlice("CONTAINER_ALLOWED_CAPABILITIES", []string{"CHOWN", "DAC_OVERRIDE", "FOWNER", "NET_BIND_SERVICE", "SETGID", "SETUID"}),
+				AllowRoot:           getEnvBool("CONTAINER_ALLOW_ROOT", false),
+			},
 		},
 		Traefik: TraefikConfig{
-			Network:           getEnv("TRAEFIK_NETWORK", "podman"),
-			ProxyPort:         getEnvInt("TRAEFIK_PROXY_PORT", 81),
-			DefaultDomain:     getEnv("DEFAULT_DOMAIN", "localhost"),
-			ProxyHost:         getEnv("MCP_PROXY_HOST", "http://localhost:7999"),
-			ManagerServiceURL: getEnv("MANAGER_SERVICE_URL", "http://localhost:8000"),
+			Network:                getEnv("TRAEFIK_NETWORK", "podman"),
+			ProxyPort:              getEnvInt("TRAEFIK_PROXY_PORT", 81),
+			DefaultDomain:          getEnv("DEFAULT_DOMAIN", "localhost"),
+			ProxyHost:              getEnv("MCP_PROXY_HOST", "http://localhost:7999"),
+			ManagerServiceURL:      getEnv("MANAGER_SERVICE_URL", "http://localhost:8000"),
+			RouteReconcileInterval: getEnvDuration("ROUTE_RECONCILE_INTERVAL", 30*time.Second),
+			PortProbeTimeout:       getEnvDuration("PORT_PROBE_TIMEOUT", 10*time.Second),
+			CertResolver:           getEnv("TRAEFIK_CERT_RESOLVER", ""),
+			Mode:                   getEnv("TRAEFIK_MODE", "embedded"),
+			ConfigDir:              getEnv("TRAEFIK_CONFIG_DIR", "/etc/traefik"),
+			Binary:                 getEnv("TRAEFIK_BINARY", "traefik"),
+			RestartMaxBackoff:      getEnvDuration("TRAEFIK_RESTART_MAX_BACKOFF", time.Minute),
+			WebAddress:             getEnv("TRAEFIK_WEB_ADDRESS", ":80"),
+			WebSecureAddress:       getEnv("TRAEFIK_WEBSECURE_ADDRESS", ":443"),
+			LogLevel:               getEnv("TRAEFIK_LOG_LEVEL", "INFO"),
+			Dashboard: TraefikDashboardConfig{
+				Enabled: getEnvBool("TRAEFIK_DASHBOARD", true),
+				Address: getEnv("TRAEFIK_DASHBOARD_ADDRESS", ":8080"),
+				Users:   getEnvStringSlice("TRAEFIK_DASHBOARD_USERS", []string{}),
+			},
+			AccessLog: TraefikAccessLogConfig{
+				Enabled:  getEnvBool("TRAEFIK_ACCESS_LOG", false),
+				Format:   getEnv("TRAEFIK_ACCESS_LOG_FORMAT", "common"),
+				FilePath: getEnv("TRAEFIK_ACCESS_LOG_PATH", ""),
+			},
+			Metrics: TraefikMetricsConfig{
+				Enabled: getEnvBool("TRAEFIK_METRICS", false),
+				Address: getEnv("TRAEFIK_METRICS_ADDRESS", ":8082"),
+			},
+			CircuitBreaker: TraefikCircuitBreakerConfig{
+				Enabled:          getEnvBool("TRAEFIK_CIRCUIT_BREAKER", true),
+				Expression:       getEnv("TRAEFIK_CIRCUIT_BREAKER_EXPRESSION", "NetworkErrorRatio() > 0.50 || ResponseCodeRatio(500, 600, 0, 600) > 0.50"),
+				FallbackDuration: getEnvDuration("TRAEFIK_CIRCUIT_BREAKER_FALLBACK", 10*time.Second),
+				RecoveryDuration: getEnvDuration("TRAEFIK_CIRCUIT_BREAKER_RECOVERY", 10*time.Second),
+			},
+			Limits: TraefikRouteLimitsConfig{
+				MaxRequestBodyBytes:   int64(getEnvInt("ROUTE_MAX_REQUEST_BODY_BYTES", 0)),
+				MaxResponseBodyBytes:  int64(getEnvInt("ROUTE_MAX_RESPONSE_BODY_BYTES", 0)),
+				MaxHeaderBytes:        getEnvInt("ROUTE_MAX_HEADER_BYTES", 0),
+				Timeout:               getEnvDuration("ROUTE_TIMEOUT", 0),
+				MaxConcurrentRequests: getEnvInt("ROUTE_MAX_CONCURRENT_REQUESTS", 0),
+			},
+			Upstream: UpstreamPoolConfig{
+				MaxIdleConnsPerHost: getEnvInt("UPSTREAM_MAX_IDLE_CONNS_PER_HOST", 200),
+				IdleConnTimeout:     getEnvDuration("UPSTREAM_IDLE_CONN_TIMEOUT", 90*time.Second),
+				DialTimeout:         getEnvDuration("UPSTREAM_DIAL_TIMEOUT", 30*time.Second),
+				KeepAlive:           getEnvDuration("UPSTREAM_TCP_KEEPALIVE", 15*time.Second),
+			},
 		},
 		Logging: LoggingConfig{
-			Level:  getEnv("LOG_LEVEL", "INFO"),
-			Format: getEnv("LOG_FORMAT", "json"),
+			Level:       getEnv("LOG_LEVEL", "INFO"),
+			Format:      getEnv("LOG_FORMAT", "json"),
+			BufferLines: getEnvInt("LOG_BUFFER_LINES", 5000),
 		},
 		Redis: RedisConfig{
-			URL: getEnv("REDIS_URL", "redis://localhost:6379"),
+			URL:                 getEnv("REDIS_URL", "redis://localhost:6379"),
+			SigningSecret:       getEnv("EVENT_SIGNING_SECRET", ""),
+			SigningJWKSURL:      getEnv("EVENT_SIGNING_JWKS_URL", ""),
+			SigningIssuer:       getEnv("EVENT_SIGNING_ISSUER", ""),
+			SignatureMaxAge:     getEnvDuration("EVENT_SIGNATURE_MAX_AGE", 5*time.Minute),
+			AcceptLegacyEvents:  getEnvBool("EVENT_ACCEPT_LEGACY", true),
+			OutboxFlushInterval: getEnvDuration("EVENT_OUTBOX_FLUSH_INTERVAL", 10*time.Second),
 		},
 		CoreAPIURL: getEnv("CORE_API_URL", "http://localhost:8000"),
+		CoreAPIToken: getEnv("CORE_API_TOKEN", ""),
+		Callbacks: CallbackConfig{
+			Enabled:       getEnvBool("CORE_API_CALLBACKS_ENABLED", false),
+			Path:          getEnv("CORE_API_CALLBACK_PATH", "/v1/mcp-server-instances/{instance_id}/provisioning"),
+			Timeout:       getEnvDuration("CORE_API_CALLBACK_TIMEOUT", 5*time.Second),
+			MaxRetries:    getEnvInt("CORE_API_CALLBACK_MAX_RETRIES", 3),
+			FlushInterval: getEnvDuration("CORE_API_CALLBACK_FLUSH_INTERVAL", 30*time.Second),
+		},
+		Registration: RegistrationConfig{
+			Enabled:      getEnvBool("MANAGER_REGISTRATION_ENABLED", false),
+			ManagerID:    getEnv("MANAGER_ID", defaultManagerID()),
+			AdvertiseURL: getEnv("MANAGER_ADVERTISE_URL", ""),
+			Path:         getEnv("MANAGER_REGISTRATION_PATH", "/v1/managers/{manager_id}"),
+			Interval:     getEnvDuration("MANAGER_HEARTBEAT_INTERVAL", 15*time.Second),
+			Timeout:      getEnvDuration("MANAGER_HEARTBEAT_TIMEOUT", 5*time.Second),
+		},
+		Chaos: ChaosConfig{
+			Enabled: getEnvBool("CHAOS_ENABLED", false),
+		},
+		Fake: FakeConfig{
+			StartupDelay: getEnvDuration("FAKE_STARTUP_DELAY", 2*time.Second),
+			FailureRate:  getEnvFloat("FAKE_FAILURE_RATE", 0),
+		},
 		Kubernetes: loadKubernetesConfig(),
 		Environment: getEnv("BACKEND_ENVIRONMENT", ""),
+		Webhooks: WebhookConfig{
+			URLs:       getEnvStringSlice("WEBHOOK_URLS", []string{}),
+			Secret:     getEnv("WEBHOOK_SECRET", ""),
+			Timeout:    getEnvDuration("WEBHOOK_TIMEOUT", 5*time.Second),
+			MaxRetries: getEnvInt("WEBHOOK_MAX_RETRIES", 3),
+		},
+		Preemption: PreemptionConfig{
+			Preemptible:  getEnvBool("HOST_PREEMPTIBLE", false),
+			Provider:     getEnv("PREEMPTION_PROVIDER", ""),
+			MetadataURL:  getEnv("PREEMPTION_METADATA_URL", ""),
+			PollInterval: getEnvDuration("PREEMPTION_POLL_INTERVAL", 5*time.Second),
+		},
+		State: StateConfig{
+			Dir: getEnv("STATE_DIR", "/var/lib/mcp-manager"),
+		},
+		Archive: ArchiveConfig{
+			InactiveAfter: time.Duration(getEnvInt("ARCHIVE_INACTIVE_DAYS", 0)) * 24 * time.Hour,
+			CheckInterval: getEnvDuration("ARCHIVE_CHECK_INTERVAL", time.Hour),
+		},
+		GC: GCConfig{
+			Interval:             getEnvDuration("GC_INTERVAL", 6*time.Hour),
+			UnusedFor:            time.Duration(getEnvInt("GC_UNUSED_DAYS", 7)) * 24 * time.Hour,
+			DiskThresholdPercent: getEnvFloat("GC_DISK_THRESHOLD_PERCENT", 80),
+			PruneBuildCache:      getEnvBool("GC_PRUNE_BUILD_CACHE", true),
+		},
+		Storage: StorageConfig{
+			MinFreeBytes:   uint64(getEnvInt("STORAGE_MIN_FREE_MB", 2048)) << 20,
+			MinFreePercent: getEnvFloat("STORAGE_MIN_FREE_PERCENT", 5),
+		},
+		Admission: AdmissionConfig{
+			Enforce:            getEnvBool("ADMISSION_ENFORCE", false),
+			MemoryReserveBytes: uint64(getEnvInt("ADMISSION_MEMORY_RESERVE_MB", 256)) << 20,
+			CPUOvercommit:      getEnvFloat("ADMISSION_CPU_OVERCOMMIT", 4),
+			DecisionLogSize:    getEnvInt("ADMISSION_DECISION_LOG_SIZE", 200),
+		},
+		RegistryMirror: RegistryMirrorConfig{
+			Host:       getEnv("REGISTRY_MIRROR", ""),
+			Upstream:   getEnv("REGISTRY_MIRROR_UPSTREAM", "docker.io"),
+			Insecure:   getEnvBool("REGISTRY_MIRROR_INSECURE", false),
+			MetricsURL: getEnv("REGISTRY_MIRROR_METRICS_URL", ""),
+			Provision:  getEnvBool("REGISTRY_MIRROR_PROVISION", false),
+			Image:      getEnv("REGISTRY_MIRROR_IMAGE", "docker.io/library/registry:2"),
+			Port:       getEnvInt("REGISTRY_MIRROR_PORT", 5000),
+		},
+		LogShipping: LogShippingConfig{
+			Sink:          getEnv("LOG_SHIPPING_SINK", ""),
+			URL:           getEnv("LOG_SHIPPING_URL", ""),
+			Index:         getEnv("LOG_SHIPPING_INDEX", "mcp-logs"),
+			AuthHeader:    getEnv("LOG_SHIPPING_AUTH_HEADER", ""),
+			BatchSize:     getEnvInt("LOG_SHIPPING_BATCH_SIZE", 500),
+			FlushInterval: getEnvDuration("LOG_SHIPPING_FLUSH_INTERVAL", 5*time.Second),
+			Timeout:       getEnvDuration("LOG_SHIPPING_TIMEOUT", 10*time.Second),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:              getEnvBool("RATE_LIMIT_ENABLED", false),
+			RequestsPerSecond:    getEnvFloat("RATE_LIMIT_RPS", 10),
+			Burst:                getEnvInt("RATE_LIMIT_BURST", 20),
+			MaxConcurrentCreates: getEnvInt("MAX_CONCURRENT_CREATES", 4),
+			CreateQueueSize:      getEnvInt("CREATE_QUEUE_SIZE", 16),
+			CreateQueueTimeout:   getEnvDuration("CREATE_QUEUE_TIMEOUT", 30*time.Second),
+		},
+		HealthMonitor: HealthMonitorConfig{
+			Workers:              getEnvInt("HEALTH_CHECK_WORKERS", 4),
+			HistorySize:          getEnvInt("HEALTH_HISTORY_SIZE", 120),
+			MaxStaleness:         getEnvDuration("HEALTH_CHECK_MAX_STALENESS", 15*time.Second),
+			InspectCacheTTL:      getEnvDuration("PODMAN_INSPECT_CACHE_TTL", 5*time.Second),
+			CrashLoopThreshold:   getEnvInt("CRASH_LOOP_THRESHOLD", 5),
+			CrashLoopWindow:      getEnvDuration("CRASH_LOOP_WINDOW", 10*time.Minute),
+			CrashLoopLogLines:    getEnvInt("CRASH_LOOP_LOG_LINES", 50),
+			RestartBackoff:       getEnvDuration("RESTART_BACKOFF", 10*time.Second),
+			RestartMaxBackoff:    getEnvDuration("RESTART_MAX_BACKOFF", 5*time.Minute),
+			ReadinessGatesRoutes: getEnvBool("READINESS_GATES_ROUTES", false),
+		},
+		Network: NetworkConfig{
+			PerWorkspace:   getEnvBool("WORKSPACE_NETWORKS_ENABLED", false),
+			Prefix:         getEnv("WORKSPACE_NETWORK_PREFIX", "mcp-ws-"),
+			Isolate:        getEnvBool("WORKSPACE_NETWORK_ISOLATE", true),
+			ProxyContainer: getEnv("PROXY_CONTAINER_NAME", ""),
+			Pods:           getEnvBool("WORKSPACE_PODS_ENABLED", false),
+			PodPrefix:      getEnv("WORKSPACE_POD_PREFIX", "mcp-pod-"),
+		},
+		GPU: GPUConfig{
+			Count:     getEnvInt("GPU_COUNT", 0),
+			CDIPrefix: getEnv("GPU_CDI_PREFIX", "nvidia.com/gpu"),
+		},
+		MTLS: MTLSConfig{
+			CertFile:         getEnv("MANAGER_TLS_CERT_FILE", ""),
+			KeyFile:          getEnv("MANAGER_TLS_KEY_FILE", ""),
+			ClientCAFile:     getEnv("MANAGER_TLS_CLIENT_CA_FILE", ""),
+			AllowedSPIFFEIDs: getEnvStringSlice("MANAGER_TLS_ALLOWED_SPIFFE_IDS", []string{}),
+			Upstream:         getEnvBool("UPSTREAM_MTLS", false),
+			Dir:              getEnv("MTLS_DIR", "/var/lib/mcp-manager/pki"),
+		},
+		Authz: AuthzConfig{
+			APIKeysFile:     getEnv("AUTHZ_API_KEYS_FILE", ""),
+			JWKSURL:         getEnv("AUTHZ_JWKS_URL", ""),
+			JWKSCacheTTL:    getEnvDuration("AUTHZ_JWKS_CACHE_TTL", time.Hour),
+			JWTIssuer:       getEnv("AUTHZ_JWT_ISSUER", ""),
+			JWTAudience:     getEnv("AUTHZ_JWT_AUDIENCE", ""),
+			RoleClaim:       getEnv("AUTHZ_ROLE_CLAIM", "role"),
+			WorkspacesClaim: getEnv("AUTHZ_WORKSPACES_CLAIM", "workspaces"),
+		},
+		Retry: RetryConfig{
+			MaxAttempts:    getEnvInt("RETRY_MAX_ATTEMPTS", 3),
+			InitialBackoff: getEnvDuration("RETRY_INITIAL_BACKOFF", 250*time.Millisecond),
+			MaxBackoff:     getEnvDuration("RETRY_MAX_BACKOFF", 10*time.Second),
+			BudgetRatio:    getEnvFloat("RETRY_BUDGET_RATIO", 0.2),
+			BudgetMin:      getEnvFloat("RETRY_BUDGET_MIN", 10),
+		},
+		Doctor: DoctorConfig{
+			Image:   getEnv("DOCTOR_IMAGE", "docker.io/library/busybox:latest"),
+			Timeout: getEnvDuration("DOCTOR_TIMEOUT", 2*time.Minute),
+		},
+		Alerting: AlertingConfig{
+			RulesFile:           getEnv("ALERT_RULES_FILE", ""),
+			EvaluationInterval:  getEnvDuration("ALERT_EVALUATION_INTERVAL", 15*time.Second),
+			SlackWebhookURL:     getEnv("ALERT_SLACK_WEBHOOK_URL", ""),
+			PagerDutyRoutingKey: getEnv("ALERT_PAGERDUTY_ROUTING_KEY", ""),
+			PagerDutyURL:        getEnv("ALERT_PAGERDUTY_URL", "https://events.pagerduty.com/v2/enqueue"),
+			EventBus:            getEnvBool("ALERT_EVENT_BUS", false),
+			Timeout:             getEnvDuration("ALERT_NOTIFY_TIMEOUT", 10*time.Second),
+		},
 	}
 }
 
+// defaultManagerID names the manager after its host, falling back to a fixed ID
+func defaultManagerID() string {
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return "mcp-manager"
+}
+
 // Helper functions for environment variable parsing
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -148,6 +944,15 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
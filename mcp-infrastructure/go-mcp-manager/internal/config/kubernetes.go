@@ -2,7 +2,10 @@ package config
 
 import (
 	"fmt"
+	"strconv"
 	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
 )
 
 // KubernetesConfig holds Kubernetes-specific configuration
@@ -259,11 +262,33 @@ func (k *KubernetesConfig) GetInternalServiceURL(instanceName string, port int)
 	return fmt.Sprintf("http://mcp-%s.%s.svc.cluster.local:%d", instanceName, k.Namespace, port)
 }
 
-// GetIngressAnnotations returns ingress annotations based on configuration
-func (k *KubernetesConfig) GetIngressAnnotations() map[string]string {
+// GetIngressAnnotations returns ingress annotations based on configuration and the instance's route options
+func (k *KubernetesConfig) GetIngressAnnotations(route *models.RouteConfig) map[string]string {
 	annotations := map[string]string{
 		"nginx.ingress.kubernetes.io/rewrite-target": "/$2",
 	}
+
+	if route != nil {
+		switch route.Transport {
+		case models.TransportSSE, models.TransportStreamableHTTP, models.TransportWebSocket:
+			// Stream responses straight through and keep long-lived connections open
+			idleTimeout := route.IdleTimeoutSeconds
+			if idleTimeout == 0 {
+				idleTimeout = 3600
+			}
+			annotations["nginx.ingress.kubernetes.io/proxy-buffering"] = "off"
+			annotations["nginx.ingress.kubernetes.io/proxy-read-timeout"] = strconv.Itoa(idleTimeout)
+			annotations["nginx.ingress.kubernetes.io/proxy-send-timeout"] = strconv.Itoa(idleTimeout)
+		}
+		if route.StickySessions {
+			cookieName := route.StickyCookieName
+			if cookieName == "" {
+				cookieName = "mcp_sticky"
+			}
+			annotations["nginx.ingress.kubernetes.io/affinity"] = "cookie"
+			annotations["nginx.ingress.kubernetes.io/session-cookie-name"] = cookieName
+		}
+	}
 	
 	if k.TLS.Enabled && k.TLS.CertManager.Enabled {
 		if k.TLS.CertManager.ClusterIssuer != "" {
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// memoryUnits maps podman (b, k, m, g) and Kubernetes binary (Ki, Mi, Gi, Ti) suffixes to bytes
+var memoryUnits = map[string]int64{
+	"":   1,
+	"b":  1,
+	"k":  1 << 10,
+	"kb": 1 << 10,
+	"ki": 1 << 10,
+	"m":  1 << 20,
+	"mb": 1 << 20,
+	"mi": 1 << 20,
+	"g":  1 << 30,
+	"gb": 1 << 30,
+	"gi": 1 << 30,
+	"t":  1 << 40,
+	"tb": 1 << 40,
+	"ti": 1 << 40,
+}
+
+// ParseMemory converts a size such as "512m", "1g" or "512Mi" to bytes
+func ParseMemory(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	split := strings.IndexFunc(value, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	number, unit := value, ""
+	if split >= 0 {
+		number, unit = value[:split], value[split:]
+	}
+
+	multiplier, ok := memoryUnits[strings.ToLower(unit)]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q, expected a number with an optional b, k, m, g or Ki, Mi, Gi suffix", value)
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q, expected a positive number", value)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// ParseCPU converts a CPU amount such as "0.5" or "500m" to cores
+func ParseCPU(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	divisor := 1.0
+	if strings.HasSuffix(value, "m") {
+		value = strings.TrimSuffix(value, "m")
+		divisor = 1000
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid cpu %q, expected cores (0.5) or millicores (500m)", value)
+	}
+	return n / divisor, nil
+}
+
+// ValidateResources checks per-instance resource limits against the configured quota policy
+func (c ContainerConfig) ValidateResources(limits *models.ResourceLimits) error {
+	if limits == nil {
+		return nil
+	}
+
+	sizes := []struct {
+		name  string
+		value string
+		max   string
+	}{
+		{"memory", limits.Memory, c.MaxMemoryLimit},
+		{"ephemeral_storage", limits.EphemeralStorage, c.MaxEphemeralStorage},
+	}
+	for _, size := range sizes {
+		if size.value == "" {
+			continue
+		}
+		bytes, err := ParseMemory(size.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", size.name, err)
+		}
+		if size.max == "" {
+			continue
+		}
+		maxBytes, err := ParseMemory(size.max)
+		if err != nil {
+			return fmt.Errorf("invalid %s quota: %w", size.name, err)
+		}
+		if bytes > maxBytes {
+			return fmt.Errorf("%s %s exceeds the per-instance quota of %s", size.name, size.value, size.max)
+		}
+	}
+
+	if limits.CPU != "" {
+		cpus, err := ParseCPU(limits.CPU)
+		if err != nil {
+			return err
+		}
+		if c.MaxCPULimit != "" {
+			maxCPUs, err := ParseCPU(c.MaxCPULimit)
+			if err != nil {
+				return fmt.Errorf("invalid cpu quota: %w", err)
+			}
+			if cpus > maxCPUs {
+				return fmt.Errorf("cpu %s exceeds the per-instance quota of %s", limits.CPU, c.MaxCPULimit)
+			}
+		}
+	}
+
+	if limits.PidsLimit < 0 {
+		return fmt.Errorf("pids_limit must be positive")
+	}
+	if c.MaxPidsLimit > 0 && limits.PidsLimit > c.MaxPidsLimit {
+		return fmt.Errorf("pids_limit %d exceeds the per-instance quota of %d", limits.PidsLimit, c.MaxPidsLimit)
+	}
+
+	return nil
+}
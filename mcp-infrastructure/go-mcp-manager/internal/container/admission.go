@@ -0,0 +1,250 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// ErrInsufficientResources is returned when admission is enforced and a new container's memory or
+// CPU limit does not fit the host
+var ErrInsufficientResources = errors.New("insufficient host resources")
+
+// Weights of the parts of a placement score
+const (
+	memoryScoreWeight = 0.4
+	cpuScoreWeight    = 0.3
+	imageScoreWeight  = 0.2
+	slotScoreWeight   = 0.1
+)
+
+// admissionState keeps the most recent scheduling decisions
+type admissionState struct {
+	mutex     sync.Mutex
+	decisions []models.SchedulingDecision
+}
+
+// engineInfo is the subset of `podman info` and `docker info` admission needs; podman reports
+// the host under "host", docker at the top level and without free memory
+type engineInfo struct {
+	Host struct {
+		MemFree  int64 `json:"memFree"`
+		MemTotal int64 `json:"memTotal"`
+		CPUs     int   `json:"cpus"`
+	} `json:"host"`
+	NCPU int `json:"NCPU"`
+}
+
+// hostCapacity is the free memory and CPU count of the engine's host; unknown values are zero
+type hostCapacity struct {
+	memFree int64
+	cpus    int
+}
+
+// admissionInput is what a placement is scored on
+type admissionInput struct {
+	memoryRequest int64
+	memoryFree    int64
+	cpuRequest    float64
+	cpuAllocated  float64
+	cpus          int
+	imagePresent  bool
+	containers    int
+	maxContainers int
+}
+
+// hostCapacity reads the free memory and CPU count of the container engine's host
+func (m *Manager) hostCapacity(ctx context.Context) (hostCapacity, error) {
+	output, err := podmanCommand(ctx, m.logger, "info", "--format", "json").Output()
+	if err != nil {
+		return hostCapacity{}, fmt.Errorf("failed to read engine info: %w", err)
+	}
+	var info engineInfo
+	if err := json.Unmarshal(output, &info); err != nil {
+		return hostCapacity{}, fmt.Errorf("failed to parse engine info: %w", err)
+	}
+	capacity := hostCapacity{memFree: info.Host.MemFree, cpus: info.Host.CPUs}
+	if capacity.cpus == 0 {
+		capacity.cpus = info.NCPU
+	}
+	return capacity, nil
+}
+
+// scoreAdmission decides whether a container fits the host and rates the placement, explaining
+// each part of the decision. Memory and CPU the engine does not report are scored as half used.
+func scoreAdmission(input admissionInput, cfg config.AdmissionConfig) (float64, bool, []string) {
+	fits := true
+	var reasons []string
+
+	memoryScore := 0.5
+	if input.memoryFree > 0 {
+		available := input.memoryFree - int64(cfg.MemoryReserveBytes)
+		if input.memoryRequest > available {
+			fits = false
+			memoryScore = 0
+			reasons = append(reasons, fmt.Sprintf("needs %d MiB of memory, %d MiB free after the %d MiB reserve",
+				input.memoryRequest>>20, max(available, 0)>>20, cfg.MemoryReserveBytes>>20))
+		} else {
+			memoryScore = 1 - float64(input.memoryRequest)/float64(available)
+			reasons = append(reasons, fmt.Sprintf("%d MiB of memory fits in %d MiB free", input.memoryRequest>>20, available>>20))
+		}
+	} else {
+		reasons = append(reasons, "free memory of the host is unknown")
+	}
+
+	cpuScore := 0.5
+	if input.cpus > 0 && cfg.CPUOvercommit > 0 {
+		capacity := float64(input.cpus) * cfg.CPUOvercommit
+		claimed := input.cpuAllocated + input.cpuRequest
+		if claimed > capacity {
+			fits = false
+			cpuScore = 0
+			reasons = append(reasons, fmt.Sprintf("%.2f CPUs would raise CPU limits to %.2f of %.2f allowed (%d CPUs, %gx overcommit)",
+				input.cpuRequest, claimed, capacity, input.cpus, cfg.CPUOvercommit))
+		} else {
+			cpuScore = 1 - claimed/capacity
+			reasons = append(reasons, fmt.Sprintf("CPU limits would be %.2f of %.2f allowed", claimed, capacity))
+		}
+	} else {
+		reasons = append(reasons, "CPU count of the host is unknown")
+	}
+
+	imageScore := 0.0
+	if input.imagePresent {
+		imageScore = 1
+		reasons = append(reasons, "image is already pulled")
+	} else {
+		reasons = append(reasons, "image has to be pulled")
+	}
+
+	slotScore := 1.0
+	if input.maxContainers > 0 {
+		if input.containers >= input.maxContainers {
+			fits = false
+			reasons = append(reasons, fmt.Sprintf("all %d container slots are taken", input.maxContainers))
+		}
+		slotScore = max(1-float64(input.containers)/float64(input.maxContainers), 0)
+	}
+
+	score := 100 * (memoryScoreWeight*memoryScore + cpuScoreWeight*cpuScore + imageScoreWeight*imageScore + slotScoreWeight*slotScore)
+	return math.Round(score*10) / 10, fits, reasons
+}
+
+// admitContainer scores a new container against the host and records the decision. A container
+// that does not fit is refused with ErrInsufficientResources when admission is enforced. The
+// container named replacing, which the new one replaces, is not counted.
+func (m *Manager) admitContainer(ctx context.Context, req *models.CreateContainerRequest, replacing string) error {
+	limits, err := m.resolveResources(requestedResources(req))
+	if err != nil {
+		// Reported by the create itself
+		return nil
+	}
+	cfg := m.config.Admission
+	input := admissionInput{maxContainers: m.config.Container.MaxContainers}
+	if limits != nil {
+		input.memoryRequest, _ = config.ParseMemory(limits.Memory)
+		input.cpuRequest, _ = config.ParseCPU(limits.CPU)
+	}
+
+	capacity, err := m.hostCapacity(ctx)
+	if err != nil {
+		m.logger.DebugContext(ctx, "Scoring placement without host capacity", slog.String("error", err.Error()))
+	}
+	input.memoryFree, input.cpus = capacity.memFree, capacity.cpus
+
+	m.mutex.RLock()
+	input.containers = len(m.containers)
+	if _, exists := m.containers[replacing]; exists {
+		input.containers--
+	}
+	for _, container := range m.containers {
+		if container.ServiceName == replacing {
+			continue
+		}
+		if container.Status == models.StatusRunning && container.Resources != nil {
+			cpus, _ := config.ParseCPU(container.Resources.CPU)
+			input.cpuAllocated += cpus
+		}
+	}
+	m.mutex.RUnlock()
+	input.imagePresent = req.Image != "" && podmanCommand(ctx, m.logger, "image", "exists", req.Image).Run() == nil
+
+	score, fits, reasons := scoreAdmission(input, cfg)
+	host := currentEngine().host
+	if host == "" {
+		host = "local"
+	}
+	decision := models.SchedulingDecision{
+		ServiceName:        req.ServiceName,
+		Image:              req.Image,
+		Host:               host,
+		Admitted:           fits || !cfg.Enforce,
+		Enforced:           cfg.Enforce,
+		Score:              score,
+		MemoryRequestBytes: input.memoryRequest,
+		MemoryFreeBytes:    input.memoryFree,
+		CPURequest:         input.cpuRequest,
+		CPUAllocated:       input.cpuAllocated,
+		CPUCapacity:        float64(input.cpus) * cfg.CPUOvercommit,
+		ImagePresent:       input.imagePresent,
+		Containers:         input.containers,
+		MaxContainers:      input.maxContainers,
+		Reasons:            reasons,
+		DecidedAt:          time.Now(),
+	}
+	m.recordDecision(decision)
+
+	if !fits {
+		m.logger.WarnContext(ctx, "Container does not fit the host",
+			slog.String("service", req.ServiceName),
+			slog.Bool("refused", cfg.Enforce),
+			slog.String("reasons", strings.Join(reasons, "; ")))
+		if cfg.Enforce {
+			return fmt.Errorf("%w: %s", ErrInsufficientResources, strings.Join(reasons, "; "))
+		}
+	}
+	return nil
+}
+
+// recordDecision keeps a scheduling decision, dropping the oldest beyond the configured log size
+func (m *Manager) recordDecision(decision models.SchedulingDecision) {
+	size := m.config.Admission.DecisionLogSize
+	if size <= 0 {
+		return
+	}
+	m.admission.mutex.Lock()
+	defer m.admission.mutex.Unlock()
+	m.admission.decisions = append(m.admission.decisions, decision)
+	if excess := len(m.admission.decisions) - size; excess > 0 {
+		m.admission.decisions = append([]models.SchedulingDecision(nil), m.admission.decisions[excess:]...)
+	}
+}
+
+// SchedulingDecisions returns up to limit recent scheduling decisions, newest first, only those
+// for serviceName when it is set
+func (m *Manager) SchedulingDecisions(serviceName string, limit int) *models.SchedulingDecisionsResponse {
+	response := &models.SchedulingDecisionsResponse{
+		Enforced:  m.config.Admission.Enforce,
+		Decisions: []models.SchedulingDecision{},
+	}
+	m.admission.mutex.Lock()
+	defer m.admission.mutex.Unlock()
+	for i := len(m.admission.decisions) - 1; i >= 0; i-- {
+		if limit > 0 && len(response.Decisions) >= limit {
+			break
+		}
+		if decision := m.admission.decisions[i]; serviceName == "" || decision.ServiceName == serviceName {
+			response.Decisions = append(response.Decisions, decision)
+		}
+	}
+	return response
+}
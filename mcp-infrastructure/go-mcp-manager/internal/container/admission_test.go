@@ -0,0 +1,55 @@
+package container
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestAdmissionScoring(t *testing.T) {
+	cfg := config.AdmissionConfig{MemoryReserveBytes: 256 << 20, CPUOvercommit: 2, DecisionLogSize: 2}
+	input := admissionInput{
+		memoryRequest: 512 << 20,
+		memoryFree:    4 << 30,
+		cpuRequest:    1,
+		cpuAllocated:  2,
+		cpus:          4,
+		imagePresent:  true,
+		containers:    5,
+		maxContainers: 50,
+	}
+	score, fits, _ := scoreAdmission(input, cfg)
+	if !fits {
+		t.Error("Expected the container to fit")
+	}
+	input.imagePresent = false
+	if pulled, _, _ := scoreAdmission(input, cfg); pulled >= score {
+		t.Errorf("Expected a pulled image to score higher, got %v and %v", score, pulled)
+	}
+
+	input.memoryFree = 600 << 20
+	if _, fits, reasons := scoreAdmission(input, cfg); fits || !strings.Contains(strings.Join(reasons, "; "), "after the 256 MiB reserve") {
+		t.Errorf("Expected memory beyond the reserve to be refused, got %v", reasons)
+	}
+	input.memoryFree, input.cpuAllocated = 0, 7.5
+	if _, fits, reasons := scoreAdmission(input, cfg); fits || !strings.Contains(reasons[0], "unknown") {
+		t.Errorf("Expected CPU overcommit to be refused with unknown memory, got %v", reasons)
+	}
+
+	manager := newTestManager(&config.Config{
+		Container: config.ContainerConfig{NamePrefix: "test-", MaxContainers: 10},
+		State:     config.StateConfig{Dir: t.TempDir()},
+		Admission: cfg,
+	})
+	for _, service := range []string{"a", "b", "c"} {
+		manager.recordDecision(models.SchedulingDecision{ServiceName: service})
+	}
+	if decisions := manager.SchedulingDecisions("", 0).Decisions; len(decisions) != 2 || decisions[0].ServiceName != "c" {
+		t.Errorf("Expected the two newest decisions, newest first, got %+v", decisions)
+	}
+	if decisions := manager.SchedulingDecisions("b", 0).Decisions; len(decisions) != 1 {
+		t.Errorf("Expected the decision of b, got %+v", decisions)
+	}
+}
@@ -0,0 +1,219 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// adoptedBucket is the state bucket recording containers adopted into management, keyed by
+// container name. Podman cannot add labels to an existing container, so the ownership and
+// metadata labels a created container carries are kept here instead.
+const adoptedBucket = "adopted"
+
+// ErrAlreadyManaged is returned when adopting a container this manager already owns
+var ErrAlreadyManaged = errors.New("container is already managed")
+
+// ErrNotAdoptable is returned when a container cannot be brought under management as requested
+var ErrNotAdoptable = errors.New("container cannot be adopted")
+
+// adoptionRecord is the stored metadata of an adopted container
+type adoptionRecord struct {
+	ServiceName string                    `json:"service_name"`
+	Slug        string                    `json:"slug"`
+	Port        int                       `json:"port"`
+	InstanceID  string                    `json:"instance_id,omitempty"`
+	HealthCheck *models.HealthCheckConfig `json:"health_check,omitempty"`
+	Route       *models.RouteConfig       `json:"route,omitempty"`
+	AdoptedAt   time.Time                 `json:"adopted_at"`
+}
+
+// adoptInspect is the subset of podman inspect output adoption reads
+type adoptInspect struct {
+	podmanInspectMetadata
+	ID        string `json:"Id"`
+	Name      string `json:"Name"`
+	ImageName string `json:"ImageName"`
+	State     struct {
+		Running bool `json:"Running"`
+	} `json:"State"`
+}
+
+// AdoptContainer brings a running container started outside the manager under management: it
+// checks the MCP server answers, assigns a slug and route, and records ownership so discovery
+// and health monitoring treat it like a container the manager created
+func (m *Manager) AdoptContainer(ctx context.Context, req models.AdoptContainerRequest) (*models.Container, error) {
+	if req.Container == "" {
+		return nil, fmt.Errorf("%w: container is required", ErrNotAdoptable)
+	}
+	if req.Port < 1 || req.Port > 65535 {
+		return nil, fmt.Errorf("%w: port must be between 1 and 65535", ErrNotAdoptable)
+	}
+	if err := validateRouteConfig(req.Route); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotAdoptable, err)
+	}
+	route, err := withProxyToken(req.Route)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := podmanCommand(ctx, m.logger, "inspect", "--type", "container", req.Container).Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: no container named %s", ErrNotAdoptable, req.Container)
+	}
+	var inspected []adoptInspect
+	if err := json.Unmarshal(output, &inspected); err != nil || len(inspected) == 0 {
+		return nil, fmt.Errorf("failed to parse container inspect output")
+	}
+	target := inspected[0]
+	name := strings.TrimPrefix(target.Name, "/")
+
+	switch m.containerOwnership(name, target.Config.Labels) {
+	case ownedBySelf, ownedLegacy, ownedAdopted:
+		return nil, fmt.Errorf("%w: %s", ErrAlreadyManaged, name)
+	}
+	if owner, labelled := target.Config.Labels[managedByLabel]; labelled {
+		return nil, fmt.Errorf("%w: %s is managed by %s", ErrNotOwned, name, owner)
+	}
+	if !target.State.Running {
+		return nil, fmt.Errorf("%w: %s is not running", ErrNotAdoptable, name)
+	}
+
+	serviceName := req.ServiceName
+	if serviceName == "" {
+		serviceName = strings.TrimPrefix(name, m.config.Container.NamePrefix)
+	}
+	m.mutex.RLock()
+	err = m.checkAdoptableName(serviceName)
+	m.mutex.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// Health checks probe the adopted port unless told otherwise, since a container started by hand
+	// need not expose it
+	healthCheck := req.HealthCheck
+	if healthCheck == nil {
+		healthCheck = &models.HealthCheckConfig{}
+	}
+	if healthCheck.Port == 0 {
+		healthCheck.Port = req.Port
+	}
+
+	containerIP, err := m.getContainerIP(ctx, target.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotAdoptable, err)
+	}
+	probeURL := fmt.Sprintf("http://%s:%d%s", containerIP, healthCheck.Port, healthCheck.Path)
+	probeCtx, cancel := context.WithTimeout(ctx, healthTimeout(healthCheck))
+	reachable, _, err := m.healthChecker.checkHTTPEndpoint(probeCtx, probeURL, healthCheck.ExpectedStatus)
+	cancel()
+	if err != nil || !reachable {
+		reason := "unhealthy response"
+		if err != nil {
+			reason = err.Error()
+		}
+		return nil, fmt.Errorf("%w: %s is not reachable at %s: %s", ErrNotAdoptable, name, probeURL, reason)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if err := m.checkAdoptableName(serviceName); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	record := adoptionRecord{
+		ServiceName: serviceName,
+		Slug:        generateSlug(serviceName),
+		Port:        req.Port,
+		InstanceID:  req.InstanceID,
+		HealthCheck: healthCheck,
+		Route:       route,
+		AdoptedAt:   now,
+	}
+	if err := m.store.Put(adoptedBucket, name, record); err != nil {
+		return nil, fmt.Errorf("failed to record adoption: %w", err)
+	}
+
+	if err := m.traefikManager.AddMCPService(ctx, record.Slug, containerIP, req.Port, route, nil, nil); err != nil {
+		m.forgetAdoption(ctx, name)
+		return nil, fmt.Errorf("failed to add Traefik route: %w", err)
+	}
+
+	environment := map[string]string{
+		"MCP_SERVICE_NAME":   serviceName,
+		"MCP_CONTAINER_PORT": fmt.Sprintf("%d", req.Port),
+	}
+	if req.InstanceID != "" {
+		environment["MCP_INSTANCE_ID"] = req.InstanceID
+	}
+
+	container := &models.Container{
+		ID:          target.ID,
+		Name:        name,
+		ServiceName: serviceName,
+		Slug:        record.Slug,
+		Image:       target.ImageName,
+		Status:      models.StatusRunning,
+		Port:        req.Port,
+		URL:         fmt.Sprintf("%s/mcp/%s", m.config.Traefik.ProxyHost, record.Slug),
+		Host:        m.config.Traefik.ProxyHost,
+		CreatedAt:   target.Created,
+		UpdatedAt:   now,
+		Environment: environment,
+		HealthCheck: healthCheck,
+		Route:       route,
+		Network:     m.workspaceNetworkName(""),
+	}
+	m.containers[serviceName] = container
+
+	m.logger.InfoContext(ctx, "Adopted container into management",
+		slog.String("container", name),
+		slog.String("service", serviceName),
+		slog.String("slug", record.Slug),
+		slog.String("url", container.URL))
+
+	return container, nil
+}
+
+// checkAdoptableName fails when serviceName is already used by a managed or archived container;
+// the caller holds the mutex
+func (m *Manager) checkAdoptableName(serviceName string) error {
+	if _, exists := m.containers[serviceName]; exists {
+		return fmt.Errorf("%w: container %s already exists", ErrNotAdoptable, serviceName)
+	}
+	if m.IsArchived(serviceName) {
+		return fmt.Errorf("%w: container %s is archived", ErrNotAdoptable, serviceName)
+	}
+	return nil
+}
+
+// adoption returns the adoption record of the container named name, if it was adopted
+func (m *Manager) adoption(name string) (adoptionRecord, bool) {
+	var record adoptionRecord
+	found, err := m.store.Get(adoptedBucket, name, &record)
+	if err != nil || !found {
+		return adoptionRecord{}, false
+	}
+	return record, true
+}
+
+// forgetAdoption drops the adoption record of a container that is deleted
+func (m *Manager) forgetAdoption(ctx context.Context, name string) {
+	if !m.store.Has(adoptedBucket, name) {
+		return
+	}
+	if err := m.store.Delete(adoptedBucket, name); err != nil {
+		m.logger.WarnContext(ctx, "Failed to clear adoption record",
+			slog.String("container", name),
+			slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,55 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestAdoptContainer(t *testing.T) {
+	cfg := &config.Config{Container: config.ContainerConfig{NamePrefix: "mcp-", ManagedByLabel: "mcp-manager"}}
+	manager := newTestManager(cfg)
+	ctx := context.Background()
+
+	if got := manager.containerOwnership("hand-started", nil); got != ownedByOther {
+		t.Errorf("Expected an unknown container to be foreign, got %d", got)
+	}
+	if err := manager.store.Put(adoptedBucket, "hand-started", adoptionRecord{ServiceName: "hand", Slug: "hand-abc", Port: 3000}); err != nil {
+		t.Fatalf("Failed to record adoption: %v", err)
+	}
+	if got := manager.containerOwnership("hand-started", nil); got != ownedAdopted {
+		t.Errorf("Expected an adopted container to be owned, got %d", got)
+	}
+	if got := manager.containerOwnership("hand-started", map[string]string{managedByLabel: "other-manager"}); got != ownedByOther {
+		t.Errorf("Expected another manager's label to win over an adoption record, got %d", got)
+	}
+	if record, found := manager.adoption("hand-started"); !found || record.Slug != "hand-abc" {
+		t.Errorf("Expected the adoption record back, got %+v", record)
+	}
+	manager.forgetAdoption(ctx, "hand-started")
+	if _, found := manager.adoption("hand-started"); found {
+		t.Error("Expected the adoption record to be forgotten")
+	}
+
+	invalid := []models.AdoptContainerRequest{
+		{Port: 3000},
+		{Container: "hand-started"},
+		{Container: "hand-started", Port: 70000},
+	}
+	for _, req := range invalid {
+		if _, err := manager.AdoptContainer(ctx, req); !errors.Is(err, ErrNotAdoptable) {
+			t.Errorf("Expected %+v to be rejected, got %v", req, err)
+		}
+	}
+
+	manager.containers["github"] = &models.Container{ServiceName: "github"}
+	if err := manager.checkAdoptableName("github"); !errors.Is(err, ErrNotAdoptable) {
+		t.Errorf("Expected a taken service name to be rejected, got %v", err)
+	}
+	if err := manager.checkAdoptableName("slack"); err != nil {
+		t.Errorf("Expected a free service name to be accepted, got %v", err)
+	}
+}
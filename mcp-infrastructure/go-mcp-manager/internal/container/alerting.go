@@ -0,0 +1,545 @@
+package container
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/alerting"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// alertRulesBucket holds the alert rules set through the API, keyed by rule ID
+	alertRulesBucket = "alert_rules"
+	// alertSilencesBucket holds silences, keyed by silence ID
+	alertSilencesBucket = "alert_silences"
+	// defaultCreateFailureWindow is how far back create_failure_rate counts creates by default
+	defaultCreateFailureWindow = 15 * time.Minute
+	// maxCreateFailureWindow bounds the window, and so how long create outcomes are kept
+	maxCreateFailureWindow = 24 * time.Hour
+)
+
+var (
+	// ErrInvalidAlertRule is returned for a rule with an unknown kind or out of range settings
+	ErrInvalidAlertRule = errors.New("invalid alert rule")
+	// ErrAlertRuleNotFound is returned when a rule that does not exist is removed
+	ErrAlertRuleNotFound = errors.New("alert rule not found")
+	// ErrAlertRuleReadOnly is returned when a rule from ALERT_RULES_FILE is changed through the API
+	ErrAlertRuleReadOnly = errors.New("alert rule is set in ALERT_RULES_FILE")
+	// ErrInvalidSilence is returned for a silence without an end in the future
+	ErrInvalidSilence = errors.New("invalid silence")
+	// ErrSilenceNotFound is returned when a silence that does not exist is removed
+	ErrSilenceNotFound = errors.New("silence not found")
+)
+
+// alertRuleIDPattern keeps rule IDs usable in URLs and as PagerDuty dedup keys
+var alertRuleIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// alertState holds the rules read at startup, the notifiers and the alerts currently pending or firing
+type alertState struct {
+	mutex       sync.Mutex
+	configRules []models.AlertRule
+	notifiers   []alerting.Notifier
+	active      map[string]*trackedAlert
+	// creates are the outcomes of recent creates, oldest first
+	creates []createOutcome
+}
+
+// trackedAlert is an active alert and whether its firing was sent to notifiers
+type trackedAlert struct {
+	alert    models.Alert
+	notified bool
+}
+
+// createOutcome is when a create finished and whether it failed
+type createOutcome struct {
+	at     time.Time
+	failed bool
+}
+
+// alertingNotifiers returns the configured notifiers, with the event bus when enabled
+func (m *Manager) alertingNotifiers() []alerting.Notifier {
+	notifiers := alerting.New(m.config.Alerting)
+	if m.config.Alerting.EventBus {
+		notifiers = append(notifiers, alerting.NotifierFunc(alerting.NotifierEvents, func(ctx context.Context, alert models.Alert) error {
+			return m.eventPublisher.PublishWarning(ctx, alert.InstanceID, alert.ServiceName, "alert_"+alert.State, alerting.Summary(alert))
+		}))
+	}
+	return notifiers
+}
+
+// loadAlertRulesFile reads the rules of ALERT_RULES_FILE, skipping those that are invalid
+func (m *Manager) loadAlertRulesFile() {
+	path := m.config.Alerting.RulesFile
+	if path == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		m.logger.Warn("Failed to read alert rules",
+			slog.String("path", path),
+			slog.String("error", err.Error()))
+		return
+	}
+	var rules []models.AlertRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		m.logger.Warn("Failed to parse alert rules",
+			slog.String("path", path),
+			slog.String("error", err.Error()))
+		return
+	}
+
+	seen := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if err := m.normalizeAlertRule(&rule); err != nil || seen[rule.ID] {
+			if err == nil {
+				err = fmt.Errorf("rule %s is defined twice", rule.ID)
+			}
+			m.logger.Warn("Skipping alert rule",
+				slog.String("path", path),
+				slog.String("rule", rule.ID),
+				slog.String("error", err.Error()))
+			continue
+		}
+		seen[rule.ID] = true
+		rule.Source = "config"
+		m.alerts.configRules = append(m.alerts.configRules, rule)
+	}
+}
+
+// normalizeAlertRule fills in defaults and checks a rule's settings and notifiers
+func (m *Manager) normalizeAlertRule(rule *models.AlertRule) error {
+	if !alertRuleIDPattern.MatchString(rule.ID) {
+		return fmt.Errorf("%w: id %q must be lowercase letters, digits, '-' and '_'", ErrInvalidAlertRule, rule.ID)
+	}
+	switch rule.Kind {
+	case models.AlertKindInstanceUnhealthy:
+		if rule.Threshold != 0 || rule.WindowSeconds != 0 {
+			return fmt.Errorf("%w: %s takes no threshold or window", ErrInvalidAlertRule, rule.Kind)
+		}
+	case models.AlertKindCreateFailureRate, models.AlertKindCapacity:
+		if rule.Threshold < 0 || rule.Threshold >= 100 {
+			return fmt.Errorf("%w: threshold must be a percentage from 0 to below 100", ErrInvalidAlertRule)
+		}
+		if rule.Kind == models.AlertKindCapacity && rule.WindowSeconds != 0 {
+			return fmt.Errorf("%w: %s takes no window", ErrInvalidAlertRule, rule.Kind)
+		}
+		if rule.Kind == models.AlertKindCreateFailureRate && rule.WindowSeconds == 0 {
+			rule.WindowSeconds = int(defaultCreateFailureWindow / time.Second)
+		}
+		if rule.WindowSeconds < 0 || time.Duration(rule.WindowSeconds)*time.Second > maxCreateFailureWindow {
+			return fmt.Errorf("%w: window_seconds must be at most %d", ErrInvalidAlertRule, int(maxCreateFailureWindow/time.Second))
+		}
+	default:
+		return fmt.Errorf("%w: kind must be %s, %s or %s", ErrInvalidAlertRule,
+			models.AlertKindInstanceUnhealthy, models.AlertKindCreateFailureRate, models.AlertKindCapacity)
+	}
+	if rule.ForSeconds < 0 {
+		return fmt.Errorf("%w: for_seconds cannot be negative", ErrInvalidAlertRule)
+	}
+
+	if rule.Severity == "" {
+		rule.Severity = models.AlertSeverityWarning
+	}
+	if !slices.Contains([]string{models.AlertSeverityInfo, models.AlertSeverityWarning, models.AlertSeverityCritical}, rule.Severity) {
+		return fmt.Errorf("%w: severity must be info, warning or critical", ErrInvalidAlertRule)
+	}
+	configured := m.notifierNames()
+	for _, name := range rule.Notifiers {
+		if !slices.Contains(configured, name) {
+			return fmt.Errorf("%w: notifier %q is not configured, configured notifiers are %s", ErrInvalidAlertRule, name, strings.Join(configured, ", "))
+		}
+	}
+	return nil
+}
+
+// notifierNames returns the names of the configured notifiers
+func (m *Manager) notifierNames() []string {
+	names := make([]string, 0, len(m.alerts.notifiers))
+	for _, notifier := range m.alerts.notifiers {
+		names = append(names, notifier.Name())
+	}
+	return names
+}
+
+// ListAlertRules returns the rules from ALERT_RULES_FILE and those set through the API, by ID
+func (m *Manager) ListAlertRules() (*models.AlertRulesResponse, error) {
+	rules, err := m.alertRules()
+	if err != nil {
+		return nil, err
+	}
+	return &models.AlertRulesResponse{Rules: rules}, nil
+}
+
+// alertRules returns every rule, by ID
+func (m *Manager) alertRules() ([]models.AlertRule, error) {
+	records, err := m.store.List(alertRulesBucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alert rules: %w", err)
+	}
+	rules := slices.Clone(m.alerts.configRules)
+	for _, raw := range records {
+		var rule models.AlertRule
+		if err := json.Unmarshal(raw, &rule); err != nil {
+			continue
+		}
+		if !slices.ContainsFunc(rules, func(existing models.AlertRule) bool { return existing.ID == rule.ID }) {
+			rules = append(rules, rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].ID < rules[j].ID
+	})
+	if rules == nil {
+		rules = []models.AlertRule{}
+	}
+	return rules, nil
+}
+
+// isConfigAlertRule reports whether a rule comes from ALERT_RULES_FILE
+func (m *Manager) isConfigAlertRule(id string) bool {
+	return slices.ContainsFunc(m.alerts.configRules, func(rule models.AlertRule) bool { return rule.ID == id })
+}
+
+// SetAlertRule creates or replaces a rule; it is evaluated from the next evaluation on
+func (m *Manager) SetAlertRule(ctx context.Context, rule models.AlertRule) (*models.AlertRule, error) {
+	if m.isConfigAlertRule(rule.ID) {
+		return nil, fmt.Errorf("%w: %s", ErrAlertRuleReadOnly, rule.ID)
+	}
+	if err := m.normalizeAlertRule(&rule); err != nil {
+		return nil, err
+	}
+	rule.Source = "api"
+	rule.UpdatedAt = time.Now()
+	if err := m.store.Put(alertRulesBucket, rule.ID, rule); err != nil {
+		return nil, fmt.Errorf("failed to save alert rule: %w", err)
+	}
+	m.logger.InfoContext(ctx, "Alert rule set",
+		slog.String("rule", rule.ID),
+		slog.String("kind", rule.Kind),
+		slog.Float64("threshold", rule.Threshold),
+		slog.Int("for_seconds", rule.ForSeconds))
+	return &rule, nil
+}
+
+// DeleteAlertRule removes a rule set through the API; its alerts resolve at the next evaluation
+func (m *Manager) DeleteAlertRule(ctx context.Context, id string) error {
+	if m.isConfigAlertRule(id) {
+		return fmt.Errorf("%w: %s", ErrAlertRuleReadOnly, id)
+	}
+	if !m.store.Has(alertRulesBucket, id) {
+		return fmt.Errorf("%w: %s", ErrAlertRuleNotFound, id)
+	}
+	if err := m.store.Delete(alertRulesBucket, id); err != nil {
+		return fmt.Errorf("failed to delete alert rule: %w", err)
+	}
+	m.logger.InfoContext(ctx, "Alert rule removed", slog.String("rule", id))
+	return nil
+}
+
+// ListAlertSilences returns the silences that have not ended, ending soonest first. Ended ones
+// are removed.
+func (m *Manager) ListAlertSilences() (*models.AlertSilencesResponse, error) {
+	silences, err := m.alertSilences(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &models.AlertSilencesResponse{Silences: silences}, nil
+}
+
+// alertSilences returns the silences still in effect at now and removes the ended ones
+func (m *Manager) alertSilences(now time.Time) ([]models.AlertSilence, error) {
+	records, err := m.store.List(alertSilencesBucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read silences: %w", err)
+	}
+	silences := []models.AlertSilence{}
+	for id, raw := range records {
+		var silence models.AlertSilence
+		if err := json.Unmarshal(raw, &silence); err != nil {
+			continue
+		}
+		if !silence.EndsAt.After(now) {
+			if err := m.store.Delete(alertSilencesBucket, id); err != nil {
+				m.logger.Warn("Failed to remove ended silence",
+					slog.String("silence", id),
+					slog.String("error", err.Error()))
+			}
+			continue
+		}
+		silences = append(silences, silence)
+	}
+	sort.Slice(silences, func(i, j int) bool {
+		return silences[i].EndsAt.Before(silences[j].EndsAt)
+	})
+	return silences, nil
+}
+
+// CreateAlertSilence mutes the alerts of a rule, of an instance or of both until the silence ends
+func (m *Manager) CreateAlertSilence(ctx context.Context, silence models.AlertSilence) (*models.AlertSilence, error) {
+	now := time.Now()
+	if silence.DurationSeconds < 0 {
+		return nil, fmt.Errorf("%w: duration_seconds cannot be negative", ErrInvalidSilence)
+	}
+	if silence.DurationSeconds > 0 {
+		silence.EndsAt = now.Add(time.Duration(silence.DurationSeconds) * time.Second)
+	}
+	if !silence.EndsAt.After(now) {
+		return nil, fmt.Errorf("%w: set duration_seconds or an ends_at in the future", ErrInvalidSilence)
+	}
+
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	silence.ID = hex.EncodeToString(id)
+	silence.CreatedAt = now
+	if err := m.store.Put(alertSilencesBucket, silence.ID, silence); err != nil {
+		return nil, fmt.Errorf("failed to save silence: %w", err)
+	}
+	m.logger.InfoContext(ctx, "Alerts silenced",
+		slog.String("silence", silence.ID),
+		slog.String("rule", silence.RuleID),
+		slog.String("service", silence.ServiceName),
+		slog.Time("ends_at", silence.EndsAt),
+		slog.String("comment", silence.Comment))
+	return &silence, nil
+}
+
+// DeleteAlertSilence ends a silence before its time
+func (m *Manager) DeleteAlertSilence(ctx context.Context, id string) error {
+	if !m.store.Has(alertSilencesBucket, id) {
+		return fmt.Errorf("%w: %s", ErrSilenceNotFound, id)
+	}
+	if err := m.store.Delete(alertSilencesBucket, id); err != nil {
+		return fmt.Errorf("failed to delete silence: %w", err)
+	}
+	m.logger.InfoContext(ctx, "Silence removed", slog.String("silence", id))
+	return nil
+}
+
+// silenced reports whether a silence matches an alert
+func silenced(alert models.Alert, silences []models.AlertSilence) bool {
+	return slices.ContainsFunc(silences, func(silence models.AlertSilence) bool {
+		return (silence.RuleID == "" || silence.RuleID == alert.RuleID) &&
+			(silence.ServiceName == "" || silence.ServiceName == alert.ServiceName)
+	})
+}
+
+// Alerts returns the pending and firing alerts by ID and the configured notifiers
+func (m *Manager) Alerts() *models.AlertsResponse {
+	m.alerts.mutex.Lock()
+	alerts := make([]models.Alert, 0, len(m.alerts.active))
+	for _, tracked := range m.alerts.active {
+		alerts = append(alerts, tracked.alert)
+	}
+	m.alerts.mutex.Unlock()
+
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].ID < alerts[j].ID
+	})
+	return &models.AlertsResponse{Alerts: alerts, Notifiers: m.notifierNames()}
+}
+
+// recordCreateOutcome counts a finished create towards create_failure_rate rules
+func (m *Manager) recordCreateOutcome(failed bool) {
+	now := time.Now()
+	m.alerts.mutex.Lock()
+	defer m.alerts.mutex.Unlock()
+
+	expired := 0
+	for expired < len(m.alerts.creates) && now.Sub(m.alerts.creates[expired].at) > maxCreateFailureWindow {
+		expired++
+	}
+	m.alerts.creates = append(m.alerts.creates[expired:], createOutcome{at: now, failed: failed})
+}
+
+// createFailures counts the creates finished since a time and how many of them failed
+func (m *Manager) createFailures(since time.Time) (total, failed int) {
+	m.alerts.mutex.Lock()
+	defer m.alerts.mutex.Unlock()
+
+	for _, outcome := range m.alerts.creates {
+		if outcome.at.After(since) {
+			total++
+			if outcome.failed {
+				failed++
+			}
+		}
+	}
+	return total, failed
+}
+
+// startAlerting evaluates the alert rules periodically
+func (m *Manager) startAlerting() {
+	ticker := time.NewTicker(m.config.Alerting.EvaluationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.healthCtx.Done():
+			return
+		case <-ticker.C:
+			m.evaluateAlerts(m.healthCtx, time.Now())
+		}
+	}
+}
+
+// alertedContainer is what alert evaluation needs of a container
+type alertedContainer struct {
+	serviceName string
+	instanceID  string
+	status      models.ContainerStatus
+}
+
+// matchingAlerts returns the alerts whose rule's condition holds at now, keyed by alert ID
+func (m *Manager) matchingAlerts(rules []models.AlertRule, now time.Time) map[string]models.Alert {
+	m.mutex.RLock()
+	containers := make([]alertedContainer, 0, len(m.containers))
+	for _, container := range m.containers {
+		containers = append(containers, alertedContainer{
+			serviceName: container.ServiceName,
+			instanceID:  container.Environment["MCP_INSTANCE_ID"],
+			status:      container.Status,
+		})
+	}
+	slotsUsed := m.capacityUsedUnsafe()
+	m.mutex.RUnlock()
+	maxContainers := m.config.Container.MaxContainers
+
+	matches := make(map[string]models.Alert)
+	for _, rule := range rules {
+		alert := models.Alert{ID: rule.ID, RuleID: rule.ID, Kind: rule.Kind, Severity: rule.Severity}
+		switch rule.Kind {
+		case models.AlertKindInstanceUnhealthy:
+			for _, container := range containers {
+				if container.status != models.StatusUnhealthy && container.status != models.StatusError {
+					continue
+				}
+				instanceAlert := alert
+				instanceAlert.ID = rule.ID + "/" + container.serviceName
+				instanceAlert.ServiceName = container.serviceName
+				instanceAlert.InstanceID = container.instanceID
+				instanceAlert.Summary = fmt.Sprintf("instance %s is %s", container.serviceName, container.status)
+				matches[instanceAlert.ID] = instanceAlert
+			}
+		case models.AlertKindCreateFailureRate:
+			window := time.Duration(rule.WindowSeconds) * time.Second
+			total, failed := m.createFailures(now.Add(-window))
+			if total == 0 {
+				continue
+			}
+			alert.Value = float64(failed) * 100 / float64(total)
+			if alert.Value > rule.Threshold {
+				alert.Summary = fmt.Sprintf("%d of %d creates in the last %s failed (%.1f%%, threshold %.1f%%)",
+					failed, total, window, alert.Value, rule.Threshold)
+				matches[alert.ID] = alert
+			}
+		case models.AlertKindCapacity:
+			if maxContainers <= 0 {
+				continue
+			}
+			alert.Value = float64(slotsUsed) * 100 / float64(maxContainers)
+			if alert.Value > rule.Threshold {
+				alert.Summary = fmt.Sprintf("%d of %d container slots are taken (%.1f%%, threshold %.1f%%)",
+					slotsUsed, maxContainers, alert.Value, rule.Threshold)
+				matches[alert.ID] = alert
+			}
+		}
+	}
+	return matches
+}
+
+// evaluateAlerts moves alerts between pending, firing and resolved and sends the firing and
+// resolved ones to the notifiers of their rule
+func (m *Manager) evaluateAlerts(ctx context.Context, now time.Time) {
+	rules, err := m.alertRules()
+	if err != nil {
+		m.logger.WarnContext(ctx, "Failed to evaluate alert rules", slog.String("error", err.Error()))
+		return
+	}
+	silences, err := m.alertSilences(now)
+	if err != nil {
+		m.logger.WarnContext(ctx, "Failed to read silences", slog.String("error", err.Error()))
+	}
+	rulesByID := make(map[string]models.AlertRule, len(rules))
+	for _, rule := range rules {
+		rulesByID[rule.ID] = rule
+	}
+	matches := m.matchingAlerts(rules, now)
+
+	type notification struct {
+		alert models.Alert
+		rule  models.AlertRule
+	}
+	var notifications []notification
+
+	m.alerts.mutex.Lock()
+	if m.alerts.active == nil {
+		m.alerts.active = make(map[string]*trackedAlert)
+	}
+	for id, tracked := range m.alerts.active {
+		if _, holds := matches[id]; holds {
+			continue
+		}
+		delete(m.alerts.active, id)
+		if tracked.notified {
+			resolved := tracked.alert
+			resolved.State = models.AlertStateResolved
+			resolved.ResolvedAt = &now
+			notifications = append(notifications, notification{alert: resolved, rule: rulesByID[resolved.RuleID]})
+		}
+	}
+	for id, match := range matches {
+		tracked, exists := m.alerts.active[id]
+		if !exists {
+			match.State = models.AlertStatePending
+			match.Since = now
+			tracked = &trackedAlert{alert: match}
+			m.alerts.active[id] = tracked
+		} else {
+			tracked.alert.Value = match.Value
+			tracked.alert.Summary = match.Summary
+			tracked.alert.Severity = match.Severity
+		}
+		tracked.alert.Silenced = silenced(tracked.alert, silences)
+
+		rule := rulesByID[match.RuleID]
+		if tracked.alert.State == models.AlertStatePending && now.Sub(tracked.alert.Since) >= time.Duration(rule.ForSeconds)*time.Second {
+			tracked.alert.State = models.AlertStateFiring
+			tracked.alert.FiredAt = &now
+			m.logger.WarnContext(ctx, "Alert firing",
+				slog.String("alert", id),
+				slog.String("severity", tracked.alert.Severity),
+				slog.String("summary", tracked.alert.Summary),
+				slog.Bool("silenced", tracked.alert.Silenced))
+		}
+		// An alert firing while silenced is sent once its silence ends
+		if tracked.alert.State == models.AlertStateFiring && !tracked.notified && !tracked.alert.Silenced {
+			tracked.notified = true
+			notifications = append(notifications, notification{alert: tracked.alert, rule: rule})
+		}
+	}
+	m.alerts.mutex.Unlock()
+
+	for _, n := range notifications {
+		if n.alert.State == models.AlertStateResolved {
+			m.logger.InfoContext(ctx, "Alert resolved", slog.String("alert", n.alert.ID))
+		}
+		for _, notifier := range m.alerts.notifiers {
+			if len(n.rule.Notifiers) == 0 || slices.Contains(n.rule.Notifiers, notifier.Name()) {
+				go alerting.Deliver(m.healthCtx, notifier, n.alert, m.logger)
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/alerting"
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestAlertRules(t *testing.T) {
+	dir := t.TempDir()
+	rulesFile := filepath.Join(dir, "rules.json")
+	rules := `[{"id": "unhealthy", "kind": "instance_unhealthy", "for_seconds": 300, "severity": "critical"},
+{"id": "bad", "kind": "disk_full"}]`
+	if err := os.WriteFile(rulesFile, []byte(rules), 0644); err != nil {
+		t.Fatalf("Failed to write rules: %v", err)
+	}
+	cfg := &config.Config{
+		State:     config.StateConfig{Dir: dir},
+		Container: config.ContainerConfig{MaxContainers: 2},
+		Alerting:  config.AlertingConfig{RulesFile: rulesFile},
+	}
+	manager := newTestManager(cfg)
+
+	notified := make(chan models.Alert, 4)
+	manager.alerts.notifiers = append(manager.alerts.notifiers, alerting.NotifierFunc("test", func(ctx context.Context, alert models.Alert) error {
+		notified <- alert
+		return nil
+	}))
+
+	if _, err := manager.SetAlertRule(context.Background(), models.AlertRule{ID: "unhealthy", Kind: models.AlertKindCapacity}); !errors.Is(err, ErrAlertRuleReadOnly) {
+		t.Errorf("Expected rules from the file to be read-only, got %v", err)
+	}
+	if _, err := manager.SetAlertRule(context.Background(), models.AlertRule{ID: "full", Kind: models.AlertKindCapacity, Threshold: 150}); !errors.Is(err, ErrInvalidAlertRule) {
+		t.Errorf("Expected a threshold over 100%% to be refused, got %v", err)
+	}
+	if _, err := manager.SetAlertRule(context.Background(), models.AlertRule{ID: "full", Kind: models.AlertKindCapacity, Threshold: 90, Notifiers: []string{"slack"}}); !errors.Is(err, ErrInvalidAlertRule) {
+		t.Errorf("Expected an unconfigured notifier to be refused, got %v", err)
+	}
+	if _, err := manager.SetAlertRule(context.Background(), models.AlertRule{ID: "full", Kind: models.AlertKindCapacity, Threshold: 90}); err != nil {
+		t.Fatalf("Expected the capacity rule to be set, got %v", err)
+	}
+	list, _ := manager.ListAlertRules()
+	if len(list.Rules) != 2 || list.Rules[0].ID != "full" || list.Rules[1].Source != "config" {
+		t.Errorf("Expected the API rule and the valid file rule, got %+v", list.Rules)
+	}
+
+	manager.containers["github"] = &models.Container{ServiceName: "github", Status: models.StatusUnhealthy}
+	manager.containers["slack"] = &models.Container{ServiceName: "slack", Status: models.StatusRunning}
+	now := time.Now()
+
+	// Capacity fires at once, the unhealthy instance only after five minutes
+	manager.evaluateAlerts(context.Background(), now)
+	alerts := manager.Alerts().Alerts
+	if len(alerts) != 2 || alerts[0].ID != "full" || alerts[0].State != models.AlertStateFiring ||
+		alerts[1].ID != "unhealthy/github" || alerts[1].State != models.AlertStatePending {
+		t.Fatalf("Expected capacity firing and github pending, got %+v", alerts)
+	}
+	if alert := <-notified; alert.ID != "full" || alert.Value != 100 {
+		t.Errorf("Expected the capacity alert to be sent, got %+v", alert)
+	}
+
+	silence, err := manager.CreateAlertSilence(context.Background(), models.AlertSilence{ServiceName: "github", DurationSeconds: 3600})
+	if err != nil {
+		t.Fatalf("Expected a silence, got %v", err)
+	}
+	manager.evaluateAlerts(context.Background(), now.Add(6*time.Minute))
+	alerts = manager.Alerts().Alerts
+	if alerts[1].State != models.AlertStateFiring || !alerts[1].Silenced {
+		t.Errorf("Expected github firing silenced, got %+v", alerts[1])
+	}
+	select {
+	case alert := <-notified:
+		t.Errorf("Expected no notification while silenced, got %+v", alert)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Ending the silence sends the alert still firing, recovery resolves it
+	if err := manager.DeleteAlertSilence(context.Background(), silence.ID); err != nil {
+		t.Fatalf("Expected the silence to be removed, got %v", err)
+	}
+	manager.evaluateAlerts(context.Background(), now.Add(7*time.Minute))
+	if alert := <-notified; alert.ID != "unhealthy/github" || alert.State != models.AlertStateFiring {
+		t.Errorf("Expected github to be sent once unsilenced, got %+v", alert)
+	}
+	manager.containers["github"].Status = models.StatusRunning
+	manager.evaluateAlerts(context.Background(), now.Add(8*time.Minute))
+	if alert := <-notified; alert.ID != "unhealthy/github" || alert.State != models.AlertStateResolved {
+		t.Errorf("Expected github to resolve, got %+v", alert)
+	}
+
+	// Failed creates over the threshold fire a create_failure_rate rule
+	if _, err := manager.SetAlertRule(context.Background(), models.AlertRule{ID: "creates", Kind: models.AlertKindCreateFailureRate, Threshold: 40}); err != nil {
+		t.Fatalf("Expected the create rule to be set, got %v", err)
+	}
+	manager.recordCreateOutcome(true)
+	manager.recordCreateOutcome(false)
+	manager.evaluateAlerts(context.Background(), time.Now())
+	if alert := <-notified; alert.ID != "creates" || alert.Value != 50 {
+		t.Errorf("Expected a 50%% create failure rate alert, got %+v", alert)
+	}
+}
@@ -0,0 +1,290 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// archiveBucket is the state bucket holding archived container metadata
+const archiveBucket = "archived"
+
+// startArchiver periodically archives containers that have not been started for a long time
+func (m *Manager) startArchiver() {
+	if m.config.Archive.InactiveAfter <= 0 {
+		return
+	}
+
+	m.logger.Info("Inactive instance archiving enabled",
+		slog.Duration("inactive_after", m.config.Archive.InactiveAfter),
+		slog.Duration("interval", m.config.Archive.CheckInterval))
+
+	ticker := time.NewTicker(m.config.Archive.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.healthCtx.Done():
+			return
+		case <-ticker.C:
+			if _, err := m.ArchiveInactive(m.healthCtx); err != nil {
+				m.logger.Error("Failed to archive inactive containers",
+					slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+// ArchiveInactive archives every stopped container whose last start is older than the configured threshold
+func (m *Manager) ArchiveInactive(ctx context.Context) ([]string, error) {
+	if m.config.Archive.InactiveAfter <= 0 {
+		return nil, nil
+	}
+
+	m.mutex.RLock()
+	candidates := make([]*models.Container, 0)
+	for _, container := range m.containers {
+		if container.Status == models.StatusStopped || container.Status == models.StatusError {
+			candidates = append(candidates, container)
+		}
+	}
+	m.mutex.RUnlock()
+
+	archived := make([]string, 0)
+	for _, container := range candidates {
+		lastStarted := m.lastStartedAt(ctx, container)
+		if time.Since(lastStarted) < m.config.Archive.InactiveAfter {
+			continue
+		}
+
+		if _, err := m.ArchiveContainer(ctx, container.ServiceName); err != nil {
+			m.logger.WarnContext(ctx, "Failed to archive inactive container",
+				slog.String("service", container.ServiceName),
+				slog.String("error", err.Error()))
+			continue
+		}
+		archived = append(archived, container.ServiceName)
+	}
+
+	if len(archived) > 0 {
+		m.logger.InfoContext(ctx, "Archived inactive containers",
+			slog.Int("count", len(archived)),
+			slog.Any("services", archived))
+	}
+
+	return archived, nil
+}
+
+// ArchiveContainer moves a stopped container's metadata to cold storage.
+// The podman container is kept stopped so it can be restored, while its route is
+// removed and its slug stays reserved.
+func (m *Manager) ArchiveContainer(ctx context.Context, serviceName string) (*models.ArchivedContainer, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	container, exists := m.containers[serviceName]
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+
+	if container.Status == models.StatusRunning || container.Status == models.StatusStarting {
+		return nil, fmt.Errorf("container %s is %s, only stopped containers can be archived", serviceName, container.Status)
+	}
+
+	record := models.ArchivedContainer{
+		Container:     *container,
+		LastStartedAt: m.lastStartedAt(ctx, container),
+		ArchivedAt:    time.Now(),
+	}
+	record.Status = models.StatusStopped
+
+	if err := m.store.Put(archiveBucket, serviceName, record); err != nil {
+		return nil, fmt.Errorf("failed to archive container: %w", err)
+	}
+
+	// Sidecars are kept stopped alongside the container so a restore can start them again
+	m.stopDependencies(ctx, container)
+
+	if container.Slug != "" {
+		if err := m.traefikManager.RemoveMCPService(ctx, container.Slug); err != nil {
+			m.logger.WarnContext(ctx, "Failed to remove Traefik route for archived container",
+				slog.String("slug", container.Slug),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	delete(m.containers, serviceName)
+	m.forgetStopped(ctx, serviceName)
+	m.forgetSchedule(serviceName)
+	delete(m.containerHealth, container.Name)
+	delete(m.healthCounters, container.Name)
+	delete(m.healthHistory, container.Name)
+
+	m.logger.InfoContext(ctx, "Container archived",
+		slog.String("service", serviceName),
+		slog.String("slug", container.Slug),
+		slog.Time("last_started_at", record.LastStartedAt))
+
+	return &record, nil
+}
+
+// UnarchiveContainer restores an archived container under its original slug and starts it
+func (m *Manager) UnarchiveContainer(ctx context.Context, serviceName string) (*models.Container, error) {
+	if m.IsPreempted() {
+		return nil, ErrHostPreempted
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var record models.ArchivedContainer
+	found, err := m.store.Get(archiveBucket, serviceName, &record)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("archived container %s not found", serviceName)
+	}
+
+	if _, exists := m.containers[serviceName]; exists {
+		return nil, fmt.Errorf("container %s already exists", serviceName)
+	}
+
+	container := record.Container
+	// Archiving released the container's GPUs, which may have been assigned elsewhere since
+	if err := m.checkGPUsAvailableUnsafe(&container); err != nil {
+		return nil, fmt.Errorf("cannot restore container %s: %w", serviceName, err)
+	}
+	container.StoppedAt = nil
+	container.UpdatedAt = time.Now()
+	m.containers[serviceName] = &container
+
+	if err := m.store.Delete(archiveBucket, serviceName); err != nil {
+		m.logger.WarnContext(ctx, "Failed to remove archive record",
+			slog.String("service", serviceName),
+			slog.String("error", err.Error()))
+	}
+
+	if err := m.restartContainer(ctx, &container); err != nil {
+		return &container, fmt.Errorf("container restored but failed to start: %w", err)
+	}
+
+	m.logger.InfoContext(ctx, "Container restored from archive",
+		slog.String("service", serviceName),
+		slog.String("slug", container.Slug))
+
+	return &container, nil
+}
+
+// ListArchived returns all archived containers ordered by service name
+func (m *Manager) ListArchived() ([]models.ArchivedContainer, error) {
+	records, err := m.store.List(archiveBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	archived := make([]models.ArchivedContainer, 0, len(records))
+	for serviceName, data := range records {
+		var record models.ArchivedContainer
+		if err := json.Unmarshal(data, &record); err != nil {
+			m.logger.Warn("Skipping unreadable archive record",
+				slog.String("service", serviceName),
+				slog.String("error", err.Error()))
+			continue
+		}
+		archived = append(archived, record)
+	}
+	sort.Slice(archived, func(i, j int) bool {
+		return archived[i].ServiceName < archived[j].ServiceName
+	})
+
+	return archived, nil
+}
+
+// GetArchived returns the archived container for a service
+func (m *Manager) GetArchived(serviceName string) (*models.ArchivedContainer, error) {
+	var record models.ArchivedContainer
+	found, err := m.store.Get(archiveBucket, serviceName, &record)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("archived container %s not found", serviceName)
+	}
+	return &record, nil
+}
+
+// IsArchived reports whether a service is archived and its slug reserved
+func (m *Manager) IsArchived(serviceName string) bool {
+	return m.store.Has(archiveBucket, serviceName)
+}
+
+// lastStartedAt returns when podman last started the container, falling back to our own bookkeeping
+func (m *Manager) lastStartedAt(ctx context.Context, container *models.Container) time.Time {
+	if container.ID != "" {
+		cmd := podmanCommand(ctx, m.logger, "inspect", container.ID, "--format", "{{.State.StartedAt}}")
+		if output, err := cmd.CombinedOutput(); err == nil {
+			value := strings.TrimSpace(string(output))
+			// podman prints Go's default time format, e.g. "2024-01-02 15:04:05.999999999 +0000 UTC"
+			if started, err := time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", value); err == nil && !started.IsZero() {
+				return started
+			}
+		}
+	}
+	return container.UpdatedAt
+}
+
+// deleteArchived removes an archived container and releases its slug. Callers hold the mutex.
+func (m *Manager) deleteArchived(ctx context.Context, serviceName string) error {
+	record, err := m.GetArchived(serviceName)
+	if err != nil {
+		return err
+	}
+
+	if record.ID != "" {
+		rmCmd := podmanCommand(ctx, m.logger, "rm", "-f", record.ID)
+		if output, err := rmCmd.CombinedOutput(); err != nil {
+			m.logger.WarnContext(ctx, "Failed to remove archived podman container",
+				slog.String("container", record.Name),
+				slog.String("error", err.Error()),
+				slog.String("output", string(output)))
+		}
+	}
+	m.removeUnit(ctx, record.Name)
+
+	m.removeDependencies(ctx, &record.Container)
+	m.removeScratchVolumes(ctx, &record.Container)
+	m.forgetCheckpoint(ctx, serviceName)
+
+	// Drop the record first so the pod is not kept for this container
+	if err := m.store.Delete(archiveBucket, serviceName); err != nil {
+		return fmt.Errorf("failed to delete archive record: %w", err)
+	}
+	m.releasePodUnsafe(ctx, record.Pod)
+	m.releaseNetworkUnsafe(ctx, record.Network)
+
+	m.logger.InfoContext(ctx, "Archived container deleted",
+		slog.String("service", serviceName))
+
+	return nil
+}
+
+// findArchivedByInstanceID returns the service name of the archived container for an MCP instance
+func (m *Manager) findArchivedByInstanceID(instanceID string) string {
+	archived, err := m.ListArchived()
+	if err != nil {
+		return ""
+	}
+	for _, record := range archived {
+		if record.Environment["MCP_INSTANCE_ID"] == instanceID {
+			return record.ServiceName
+		}
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package container
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestArchiveReservesSlug(t *testing.T) {
+	cfg := &config.Config{
+		Container: config.ContainerConfig{
+			NamePrefix:    "test-",
+			MaxContainers: 10,
+		},
+		Redis: config.RedisConfig{
+			URL: "redis://localhost:6379",
+		},
+		State: config.StateConfig{
+			Dir: t.TempDir(),
+		},
+	}
+	manager := newTestManager(cfg)
+
+	manager.containers["old-service"] = &models.Container{
+		Name:        "test-old-service",
+		ServiceName: "old-service",
+		Status:      models.StatusStopped,
+		UpdatedAt:   time.Now().Add(-30 * 24 * time.Hour),
+	}
+
+	archived, err := manager.ArchiveContainer(context.Background(), "old-service")
+	if err != nil {
+		t.Fatalf("Expected archive to succeed, got %v", err)
+	}
+	if archived.ArchivedAt.IsZero() {
+		t.Error("Expected archived_at to be set")
+	}
+
+	if len(manager.ListContainers()) != 0 {
+		t.Error("Expected archived container to be hidden from listings")
+	}
+	if !manager.IsArchived("old-service") {
+		t.Error("Expected service to be archived")
+	}
+
+	_, err = manager.CreateContainer(context.Background(), models.CreateContainerRequest{
+		ServiceName: "old-service",
+		Image:       "nginx:latest",
+		Port:        80,
+	})
+	if err == nil {
+		t.Error("Expected create to be rejected while the slug is reserved")
+	}
+
+	records, err := manager.ListArchived()
+	if err != nil || len(records) != 1 {
+		t.Errorf("Expected 1 archived record, got %d (err %v)", len(records), err)
+	}
+}
@@ -0,0 +1,213 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/webhooks"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// backupVersion is the format version of backups this manager writes and restores
+const backupVersion = 1
+
+// ErrBackupVersion is returned when restoring a backup written in another format version
+var ErrBackupVersion = errors.New("unsupported backup version")
+
+// Backup captures the desired state of the host: the spec, slug and state of every container,
+// archived ones included, and the registered webhooks
+func (m *Manager) Backup(ctx context.Context) (*models.Backup, error) {
+	backup := &models.Backup{
+		Version:    backupVersion,
+		CreatedAt:  time.Now().UTC(),
+		ManagedBy:  m.config.Container.ManagedByLabel,
+		Containers: make([]models.BackupContainer, 0),
+		Skipped:    make(map[string]string),
+	}
+
+	m.mutex.RLock()
+	for serviceName, container := range m.containers {
+		if _, adopted := m.adoption(container.Name); adopted {
+			backup.Skipped[serviceName] = "adopted container, its spec is not known to the manager"
+			continue
+		}
+		// A container its schedule stopped is restored running and the scheduler stops it again
+		state := models.BackupStateRunning
+		if container.Status != models.StatusScheduledOff && (container.StoppedAt != nil || container.Status == models.StatusStopped) {
+			state = models.BackupStateStopped
+		}
+		backup.Containers = append(backup.Containers, models.BackupContainer{
+			Spec:  containerSpec(container),
+			Slug:  container.Slug,
+			State: state,
+		})
+	}
+	m.mutex.RUnlock()
+
+	archived, err := m.ListArchived()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archived containers: %w", err)
+	}
+	for i := range archived {
+		backup.Containers = append(backup.Containers, models.BackupContainer{
+			Spec:  containerSpec(&archived[i].Container),
+			Slug:  archived[i].Slug,
+			State: models.BackupStateArchived,
+		})
+	}
+	sort.Slice(backup.Containers, func(i, j int) bool {
+		return backup.Containers[i].Spec.ServiceName < backup.Containers[j].Spec.ServiceName
+	})
+
+	for _, webhook := range m.webhooks.List() {
+		events := make([]string, 0, len(webhook.Events))
+		for _, event := range webhook.Events {
+			events = append(events, string(event))
+		}
+		backup.Webhooks = append(backup.Webhooks, models.BackupWebhook{
+			URL:    webhook.URL,
+			Secret: webhook.Secret,
+			Events: events,
+		})
+	}
+
+	m.logger.InfoContext(ctx, "Backup created",
+		slog.Int("containers", len(backup.Containers)),
+		slog.Int("webhooks", len(backup.Webhooks)),
+		slog.Int("skipped", len(backup.Skipped)))
+
+	return backup, nil
+}
+
+// Restore reconciles the host to a backup: containers missing here are created under their
+// original slug, pulling or building their images, and put back in the state they were in.
+// Existing containers and webhooks are left alone, so a restore can be repeated.
+func (m *Manager) Restore(ctx context.Context, backup *models.Backup) (*models.RestoreResult, error) {
+	if backup.Version != backupVersion {
+		return nil, fmt.Errorf("%w %d, expected %d", ErrBackupVersion, backup.Version, backupVersion)
+	}
+
+	result := &models.RestoreResult{
+		Created:  make([]string, 0),
+		Existing: make([]string, 0),
+		Failed:   make(map[string]string),
+	}
+
+	for _, entry := range backup.Containers {
+		serviceName := entry.Spec.ServiceName
+		m.mutex.RLock()
+		_, exists := m.containers[serviceName]
+		m.mutex.RUnlock()
+		if exists || m.IsArchived(serviceName) {
+			result.Existing = append(result.Existing, serviceName)
+			continue
+		}
+
+		if err := m.restoreContainer(ctx, entry); err != nil {
+			m.logger.ErrorContext(ctx, "Failed to restore container",
+				slog.String("service", serviceName),
+				slog.String("error", err.Error()))
+			result.Failed[serviceName] = err.Error()
+			continue
+		}
+		result.Created = append(result.Created, serviceName)
+	}
+
+	registered := make(map[string]bool)
+	for _, webhook := range m.webhooks.List() {
+		registered[webhook.URL] = true
+	}
+	for _, webhook := range backup.Webhooks {
+		if registered[webhook.URL] {
+			continue
+		}
+		events := make([]webhooks.EventType, 0, len(webhook.Events))
+		for _, event := range webhook.Events {
+			events = append(events, webhooks.EventType(event))
+		}
+		m.webhooks.Register(webhook.URL, webhook.Secret, events)
+		registered[webhook.URL] = true
+		result.Webhooks++
+	}
+
+	m.logger.InfoContext(ctx, "Backup restored",
+		slog.Int("created", len(result.Created)),
+		slog.Int("existing", len(result.Existing)),
+		slog.Int("failed", len(result.Failed)),
+		slog.Int("webhooks", result.Webhooks))
+
+	return result, nil
+}
+
+// restoreContainer creates a container from a backup entry and stops or archives it as recorded
+func (m *Manager) restoreContainer(ctx context.Context, entry models.BackupContainer) error {
+	serviceName := entry.Spec.ServiceName
+	if _, err := m.createContainer(ctx, entry.Spec, entry.Slug, ""); err != nil {
+		return err
+	}
+
+	switch entry.State {
+	case models.BackupStateStopped:
+		if _, err := m.StopContainer(ctx, serviceName); err != nil {
+			return fmt.Errorf("created but failed to stop: %w", err)
+		}
+	case models.BackupStateArchived:
+		if _, err := m.StopContainer(ctx, serviceName); err != nil {
+			return fmt.Errorf("created but failed to stop: %w", err)
+		}
+		if _, err := m.ArchiveContainer(ctx, serviceName); err != nil {
+			return fmt.Errorf("created but failed to archive: %w", err)
+		}
+	}
+	return nil
+}
+
+// containerSpec returns the create request that reproduces container; images built from source or
+// bridging a package are left for the restore to build again
+func containerSpec(container *models.Container) models.CreateContainerRequest {
+	spec := models.CreateContainerRequest{
+		ServiceName:    container.ServiceName,
+		Port:           container.Port,
+		Environment:    maps.Clone(container.Environment),
+		Labels:         maps.Clone(container.Labels),
+		Command:        slices.Clone(container.Command),
+		HealthCheck:    container.HealthCheck,
+		Route:          container.Route,
+		Routing:        container.Routing,
+		GPUs:           container.GPUs,
+		Devices:        slices.Clone(container.Devices),
+		Resources:      container.Resources,
+		Security:       container.Security,
+		WorkspaceID:    container.WorkspaceID,
+		SharedNetwork:  container.SharedNetwork,
+		DNS:            slices.Clone(container.DNS),
+		ExtraHosts:     slices.Clone(container.ExtraHosts),
+		Proxy:          container.Proxy,
+		Tmpfs:          slices.Clone(container.Tmpfs),
+		ScratchVolumes: slices.Clone(container.ScratchVolumes),
+		Files:          maps.Clone(container.Files),
+		DependsOn:      slices.Clone(container.DependsOn),
+		Init:           container.Init,
+		Source:         container.Source,
+		Runtime:        container.Runtime,
+		LogShipping:    container.LogShipping,
+		Schedule:       container.Schedule,
+		Priority:       container.Priority,
+		Scaling:        container.Scaling,
+		EnvSchema:      slices.Clone(container.EnvSchema),
+	}
+	switch {
+	case container.Runtime != nil:
+		// The bridge image and command are derived from the runtime again
+		spec.Command = nil
+	case container.Source == nil:
+		spec.Image = container.Image
+	}
+	return spec
+}
@@ -0,0 +1,83 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	cfg := &config.Config{Container: config.ContainerConfig{NamePrefix: "mcp-", ManagedByLabel: "mcp-manager"}}
+	manager := newTestManager(cfg)
+	ctx := context.Background()
+
+	stoppedAt := time.Now()
+	manager.containers["github"] = &models.Container{
+		Name: "mcp-github", ServiceName: "github", Slug: "github-abc", Image: "ghcr.io/example/github-mcp:1.0",
+		Port: 3000, Status: models.StatusRunning, Environment: map[string]string{"TOKEN": "secret"},
+	}
+	manager.containers["slack"] = &models.Container{
+		Name: "mcp-slack", ServiceName: "slack", Slug: "slack-def", Port: 8000, Status: models.StatusStopped,
+		StoppedAt: &stoppedAt, Runtime: &models.RuntimeConfig{Type: "npx", Package: "@example/slack-mcp"},
+		Image: "bridge:latest", Command: []string{"--stdio"},
+	}
+	manager.containers["hand"] = &models.Container{Name: "hand-started", ServiceName: "hand", Slug: "hand-123"}
+	if err := manager.store.Put(adoptedBucket, "hand-started", adoptionRecord{ServiceName: "hand"}); err != nil {
+		t.Fatalf("Failed to record adoption: %v", err)
+	}
+	archived := models.ArchivedContainer{Container: models.Container{ServiceName: "jira", Slug: "jira-789", Image: "jira:1", Port: 8000}}
+	if err := manager.store.Put(archiveBucket, "jira", archived); err != nil {
+		t.Fatalf("Failed to archive: %v", err)
+	}
+	manager.Webhooks().Register("https://hooks.example.com/mcp", "signing-secret", nil)
+
+	backup, err := manager.Backup(ctx)
+	if err != nil {
+		t.Fatalf("Expected a backup, got %v", err)
+	}
+	if len(backup.Containers) != 3 {
+		t.Fatalf("Expected 3 containers in the backup, got %d", len(backup.Containers))
+	}
+	states := map[string]string{}
+	for _, entry := range backup.Containers {
+		states[entry.Spec.ServiceName] = entry.State
+	}
+	if states["github"] != models.BackupStateRunning || states["slack"] != models.BackupStateStopped || states["jira"] != models.BackupStateArchived {
+		t.Errorf("Expected running, stopped and archived states, got %v", states)
+	}
+	github := backup.Containers[0]
+	if github.Slug != "github-abc" || github.Spec.Environment["TOKEN"] != "secret" || github.Spec.Image == "" {
+		t.Errorf("Expected the github spec with its slug and environment, got %+v", github)
+	}
+	slack := backup.Containers[2]
+	if slack.Spec.Image != "" || len(slack.Spec.Command) != 0 || slack.Spec.Runtime == nil {
+		t.Errorf("Expected the runtime spec without the bridge image and command, got %+v", slack.Spec)
+	}
+	if _, skipped := backup.Skipped["hand"]; !skipped {
+		t.Errorf("Expected the adopted container to be skipped, got %v", backup.Skipped)
+	}
+	if len(backup.Webhooks) != 1 || backup.Webhooks[0].Secret != "signing-secret" {
+		t.Errorf("Expected the webhook with its secret, got %+v", backup.Webhooks)
+	}
+
+	if _, err := manager.Restore(ctx, &models.Backup{Version: backupVersion + 1}); !errors.Is(err, ErrBackupVersion) {
+		t.Errorf("Expected a newer backup to be rejected, got %v", err)
+	}
+
+	// Everything in the backup already exists here, so only the new webhook is added
+	backup.Webhooks = append(backup.Webhooks, models.BackupWebhook{URL: "https://other.example.com/mcp"})
+	result, err := manager.Restore(ctx, backup)
+	if err != nil {
+		t.Fatalf("Expected the restore to succeed, got %v", err)
+	}
+	if len(result.Existing) != 3 || len(result.Created) != 0 || len(result.Failed) != 0 {
+		t.Errorf("Expected all containers to exist already, got %+v", result)
+	}
+	if result.Webhooks != 1 || len(manager.Webhooks().List()) != 2 {
+		t.Errorf("Expected one webhook to be registered, got %d", result.Webhooks)
+	}
+}
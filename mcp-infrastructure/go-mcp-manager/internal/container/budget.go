@@ -0,0 +1,546 @@
+package container
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/webhooks"
+	"github.com/agentarea/mcp-manager/pkg/events"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// budgetBucket holds the budgets, keyed by workspace ID or globalBudgetKey
+	budgetBucket = "budgets"
+	// budgetUsageBucket holds what was used against each budget, keyed like budgetBucket
+	budgetUsageBucket = "budget_usage"
+	// overBudgetBucket records the containers an exhausted budget stopped, keyed by service name
+	overBudgetBucket = "over_budget"
+	// globalBudgetKey keys the budget of all instances on the host
+	globalBudgetKey = "*"
+	// budgetInterval is how often usage is counted and budgets are checked
+	budgetInterval = time.Minute
+	// budgetWarningPercent is the share of a budget whose use is announced before it runs out
+	budgetWarningPercent = 80
+)
+
+var (
+	// ErrBudgetExhausted is returned when an instance is created or started in a workspace, or on a
+	// host, that used up a budget
+	ErrBudgetExhausted = errors.New("budget exhausted")
+	// ErrNoBudget is returned when a budget that is not set is removed
+	ErrNoBudget = errors.New("no budget set")
+)
+
+// budgetState holds the counters usage was last counted from
+type budgetState struct {
+	mutex sync.Mutex
+	// usageMutex serializes updates of the stored usage
+	usageMutex sync.Mutex
+	// cpu is the CPU time of each running container in nanoseconds, keyed by container name
+	cpu map[string]uint64
+	// egress is the bytes each instance sent through the proxy, keyed by service name
+	egress    map[string]uint64
+	sampledAt time.Time
+}
+
+// budgetUsageRecord is the usage stored for a budget and the highest threshold announced for it
+type budgetUsageRecord struct {
+	models.BudgetUsage
+	Notified int `json:"notified,omitempty"`
+}
+
+// budgetKey returns the store key of the budget of workspaceID, the global one when it is empty
+func budgetKey(workspaceID string) string {
+	if workspaceID == "" {
+		return globalBudgetKey
+	}
+	return workspaceID
+}
+
+// validateBudget checks the limits and action of a budget
+func validateBudget(budget models.Budget) error {
+	if budget.ContainerHours < 0 || budget.CPUSeconds < 0 {
+		return fmt.Errorf("budget limits must not be negative")
+	}
+	switch budget.Action {
+	case "", models.BudgetActionRefuse, models.BudgetActionStop:
+		return nil
+	default:
+		return fmt.Errorf("invalid budget action %q, must be refuse or stop", budget.Action)
+	}
+}
+
+// budgetPercent returns the largest share of a limit of budget that usage used
+func budgetPercent(budget *models.Budget, usage models.BudgetUsage) float64 {
+	if budget == nil {
+		return 0
+	}
+	percent := 0.0
+	if budget.ContainerHours > 0 {
+		percent = max(percent, usage.ContainerHours/budget.ContainerHours*100)
+	}
+	if budget.CPUSeconds > 0 {
+		percent = max(percent, usage.CPUSeconds/budget.CPUSeconds*100)
+	}
+	if budget.EgressBytes > 0 {
+		percent = max(percent, float64(usage.EgressBytes)/float64(budget.EgressBytes)*100)
+	}
+	return percent
+}
+
+// budgetThreshold returns the highest announced threshold percent reached
+func budgetThreshold(percent float64) int {
+	switch {
+	case percent >= 100:
+		return 100
+	case percent >= budgetWarningPercent:
+		return budgetWarningPercent
+	default:
+		return 0
+	}
+}
+
+// readBudget returns the budget of workspaceID, nil when none is set
+func (m *Manager) readBudget(workspaceID string) (*models.Budget, error) {
+	var budget models.Budget
+	found, err := m.store.Get(budgetBucket, budgetKey(workspaceID), &budget)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read budget: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &budget, nil
+}
+
+// readBudgetUsage returns the usage counted against the budget of workspaceID
+func (m *Manager) readBudgetUsage(workspaceID string) (budgetUsageRecord, error) {
+	var record budgetUsageRecord
+	if _, err := m.store.Get(budgetUsageBucket, budgetKey(workspaceID), &record); err != nil {
+		return record, fmt.Errorf("failed to read budget usage: %w", err)
+	}
+	return record, nil
+}
+
+// budgetStatus returns the usage and budget of workspaceID, the host when it is empty
+func (m *Manager) budgetStatus(workspaceID string) (*models.BudgetStatus, error) {
+	budget, err := m.readBudget(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	record, err := m.readBudgetUsage(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	status := &models.BudgetStatus{WorkspaceID: workspaceID, Budget: budget, Usage: record.BudgetUsage}
+	status.Percent = budgetPercent(budget, record.BudgetUsage)
+	status.Exhausted = status.Percent >= 100
+	return status, nil
+}
+
+// checkBudget refuses instances of workspaceID once its budget or the global one is exhausted
+func (m *Manager) checkBudget(workspaceID string) error {
+	scopes := []string{""}
+	if workspaceID != "" {
+		scopes = append(scopes, workspaceID)
+	}
+	for _, scope := range scopes {
+		status, err := m.budgetStatus(scope)
+		if err != nil {
+			return err
+		}
+		if !status.Exhausted {
+			continue
+		}
+		if scope == "" {
+			return fmt.Errorf("%w: the global budget is used up (%.0f%%)", ErrBudgetExhausted, status.Percent)
+		}
+		return fmt.Errorf("%w: workspace %s used up its budget (%.0f%%)", ErrBudgetExhausted, scope, status.Percent)
+	}
+	return nil
+}
+
+// GetBudget returns the usage of workspaceID, the host when it is empty, and its budget if set
+func (m *Manager) GetBudget(workspaceID string) (*models.BudgetStatus, error) {
+	return m.budgetStatus(workspaceID)
+}
+
+// ListBudgets returns the global budget and every workspace with a budget or counted usage
+func (m *Manager) ListBudgets() (*models.BudgetsResponse, error) {
+	global, err := m.budgetStatus("")
+	if err != nil {
+		return nil, err
+	}
+	response := &models.BudgetsResponse{Global: *global, Workspaces: []models.BudgetStatus{}}
+
+	scopes := make(map[string]bool)
+	for _, bucket := range []string{budgetBucket, budgetUsageBucket} {
+		keys, err := m.store.Keys(bucket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list budgets: %w", err)
+		}
+		for _, key := range keys {
+			if key != globalBudgetKey {
+				scopes[key] = true
+			}
+		}
+	}
+	for workspaceID := range scopes {
+		status, err := m.budgetStatus(workspaceID)
+		if err != nil {
+			return nil, err
+		}
+		response.Workspaces = append(response.Workspaces, *status)
+	}
+	sort.Slice(response.Workspaces, func(i, j int) bool {
+		return response.Workspaces[i].WorkspaceID < response.Workspaces[j].WorkspaceID
+	})
+	return response, nil
+}
+
+// SetBudget sets or replaces the budget of budget.WorkspaceID, the global one when it is empty.
+// Usage is kept; a budget raised above it lifts the refusal of new instances.
+func (m *Manager) SetBudget(ctx context.Context, budget models.Budget) (*models.BudgetStatus, error) {
+	if err := validateBudget(budget); err != nil {
+		return nil, err
+	}
+	if budget.Action == "" {
+		budget.Action = models.BudgetActionRefuse
+	}
+	budget.UpdatedAt = time.Now()
+	if err := m.store.Put(budgetBucket, budgetKey(budget.WorkspaceID), budget); err != nil {
+		return nil, fmt.Errorf("failed to save budget: %w", err)
+	}
+	m.logger.InfoContext(ctx, "Budget set",
+		slog.String("workspace", budget.WorkspaceID),
+		slog.Float64("container_hours", budget.ContainerHours),
+		slog.Float64("cpu_seconds", budget.CPUSeconds),
+		slog.Uint64("egress_bytes", budget.EgressBytes),
+		slog.String("action", budget.Action))
+	return m.budgetStatus(budget.WorkspaceID)
+}
+
+// DeleteBudget removes the budget of workspaceID, the global one when it is empty; usage is still counted
+func (m *Manager) DeleteBudget(ctx context.Context, workspaceID string) error {
+	if !m.store.Has(budgetBucket, budgetKey(workspaceID)) {
+		return ErrNoBudget
+	}
+	if err := m.store.Delete(budgetBucket, budgetKey(workspaceID)); err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+	m.logger.InfoContext(ctx, "Budget removed", slog.String("workspace", workspaceID))
+	return nil
+}
+
+// ResetBudgetUsage starts counting the usage of workspaceID, the host when it is empty, from zero,
+// as at the start of a billing period. Instances stopped over budget stay stopped until started.
+func (m *Manager) ResetBudgetUsage(ctx context.Context, workspaceID string) (*models.BudgetStatus, error) {
+	m.budgets.usageMutex.Lock()
+	defer m.budgets.usageMutex.Unlock()
+
+	now := time.Now()
+	record := budgetUsageRecord{BudgetUsage: models.BudgetUsage{Since: now, UpdatedAt: now}}
+	if err := m.store.Put(budgetUsageBucket, budgetKey(workspaceID), record); err != nil {
+		return nil, fmt.Errorf("failed to reset budget usage: %w", err)
+	}
+	m.logger.InfoContext(ctx, "Budget usage reset", slog.String("workspace", workspaceID))
+	return m.budgetStatus(workspaceID)
+}
+
+// startBudgetAccounting periodically counts usage against budgets and enforces them
+func (m *Manager) startBudgetAccounting() {
+	ticker := time.NewTicker(budgetInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.healthCtx.Done():
+			return
+		case <-ticker.C:
+			m.accountBudgets(m.healthCtx)
+		}
+	}
+}
+
+// budgetedContainer is what usage accounting needs of a container
+type budgetedContainer struct {
+	name        string
+	serviceName string
+	workspaceID string
+	running     bool
+}
+
+// accountBudgets adds the container time, CPU time and egress since the last pass to the usage of
+// each workspace and of the host, announces budgets crossing 80% and 100% and stops the running
+// instances of exhausted budgets whose action is "stop"
+func (m *Manager) accountBudgets(ctx context.Context) {
+	m.mutex.RLock()
+	containers := make([]budgetedContainer, 0, len(m.containers))
+	var runningNames []string
+	for _, container := range m.containers {
+		running := container.StoppedAt == nil &&
+			(container.Status == models.StatusRunning || container.Status == models.StatusUnhealthy)
+		containers = append(containers, budgetedContainer{
+			name:        container.Name,
+			serviceName: container.ServiceName,
+			workspaceID: container.WorkspaceID,
+			running:     running,
+		})
+		if running {
+			runningNames = append(runningNames, container.Name)
+		}
+	}
+	m.mutex.RUnlock()
+
+	cpu := map[string]uint64{}
+	if len(runningNames) > 0 {
+		sampled, err := m.sampleCPUTime(ctx, runningNames)
+		if err != nil {
+			m.logger.WarnContext(ctx, "Failed to sample CPU time for budgets", slog.String("error", err.Error()))
+		} else {
+			cpu = sampled
+		}
+	}
+	egress := make(map[string]uint64)
+	m.traffic.mutex.Lock()
+	for _, container := range containers {
+		if stats, exists := m.traffic.stats[container.serviceName]; exists {
+			egress[container.serviceName] = stats.BytesOut
+		}
+	}
+	m.traffic.mutex.Unlock()
+
+	now := time.Now()
+	m.budgets.mutex.Lock()
+	// The first pass only takes the baseline, and time the manager was down is not counted
+	elapsed := time.Duration(0)
+	if !m.budgets.sampledAt.IsZero() {
+		elapsed = min(now.Sub(m.budgets.sampledAt), 2*budgetInterval)
+	}
+	previousCPU, previousEgress := m.budgets.cpu, m.budgets.egress
+	m.budgets.cpu, m.budgets.egress, m.budgets.sampledAt = cpu, egress, now
+	m.budgets.mutex.Unlock()
+	if elapsed == 0 {
+		return
+	}
+
+	used := make(map[string]*models.BudgetUsage)
+	add := func(scope string, hours, cpuSeconds float64, egressBytes uint64) {
+		usage, exists := used[scope]
+		if !exists {
+			usage = &models.BudgetUsage{}
+			used[scope] = usage
+		}
+		usage.ContainerHours += hours
+		usage.CPUSeconds += cpuSeconds
+		usage.EgressBytes += egressBytes
+	}
+	for _, container := range containers {
+		hours, cpuSeconds := 0.0, 0.0
+		if container.running {
+			hours = elapsed.Hours()
+			if current, sampled := cpu[container.name]; sampled {
+				cpuSeconds = float64(counterDelta(previousCPU[container.name], current)) / float64(time.Second)
+			}
+		}
+		var egressBytes uint64
+		if current, exists := egress[container.serviceName]; exists {
+			egressBytes = counterDelta(previousEgress[container.serviceName], current)
+		}
+		add("", hours, cpuSeconds, egressBytes)
+		if container.workspaceID != "" {
+			add(container.workspaceID, hours, cpuSeconds, egressBytes)
+		}
+	}
+
+	for workspaceID, usage := range used {
+		m.chargeBudget(ctx, workspaceID, *usage, now)
+	}
+}
+
+// counterDelta returns how much a counter grew; a counter that went back, as when its container
+// restarted, counts from zero
+func counterDelta(previous, current uint64) uint64 {
+	if current < previous {
+		return current
+	}
+	return current - previous
+}
+
+// chargeBudget adds usage to the usage of workspaceID, the host when it is empty, and enforces its
+// budget
+func (m *Manager) chargeBudget(ctx context.Context, workspaceID string, usage models.BudgetUsage, now time.Time) {
+	m.budgets.usageMutex.Lock()
+	record, err := m.readBudgetUsage(workspaceID)
+	if err != nil {
+		m.budgets.usageMutex.Unlock()
+		m.logger.WarnContext(ctx, "Failed to count budget usage",
+			slog.String("workspace", workspaceID),
+			slog.String("error", err.Error()))
+		return
+	}
+	if record.Since.IsZero() {
+		record.Since = now
+	}
+	record.ContainerHours += usage.ContainerHours
+	record.CPUSeconds += usage.CPUSeconds
+	record.EgressBytes += usage.EgressBytes
+	record.UpdatedAt = now
+
+	budget, err := m.readBudget(workspaceID)
+	if err != nil {
+		m.logger.WarnContext(ctx, "Failed to read budget",
+			slog.String("workspace", workspaceID),
+			slog.String("error", err.Error()))
+	}
+	percent := budgetPercent(budget, record.BudgetUsage)
+	threshold := budgetThreshold(percent)
+	crossed := threshold > record.Notified
+	// A raised budget or a reset lets the thresholds be announced again
+	record.Notified = threshold
+
+	err = m.store.Put(budgetUsageBucket, budgetKey(workspaceID), record)
+	m.budgets.usageMutex.Unlock()
+	if err != nil {
+		m.logger.WarnContext(ctx, "Failed to save budget usage",
+			slog.String("workspace", workspaceID),
+			slog.String("error", err.Error()))
+		return
+	}
+
+	if crossed {
+		m.announceBudgetThreshold(ctx, workspaceID, threshold, percent)
+	}
+	if threshold >= 100 && budget != nil && budget.Action == models.BudgetActionStop {
+		m.stopOverBudget(ctx, workspaceID, percent)
+	}
+}
+
+// announceBudgetThreshold logs, publishes for every affected instance and sends to webhooks that a
+// budget reached threshold percent
+func (m *Manager) announceBudgetThreshold(ctx context.Context, workspaceID string, threshold int, percent float64) {
+	scope := "the global budget"
+	if workspaceID != "" {
+		scope = fmt.Sprintf("the budget of workspace %s", workspaceID)
+	}
+	message := fmt.Sprintf("%.0f%% of %s is used", percent, scope)
+	if threshold >= 100 {
+		message = fmt.Sprintf("%s is exhausted (%.0f%% used)", scope, percent)
+	}
+
+	m.logger.WarnContext(ctx, "Budget threshold reached",
+		slog.String("workspace", workspaceID),
+		slog.Int("threshold", threshold),
+		slog.Float64("percent", percent))
+
+	m.mutex.RLock()
+	instances := make(map[string]string)
+	for _, container := range m.containers {
+		instanceID := container.Environment["MCP_INSTANCE_ID"]
+		if instanceID != "" && (workspaceID == "" || container.WorkspaceID == workspaceID) {
+			instances[instanceID] = container.ServiceName
+		}
+	}
+	m.mutex.RUnlock()
+	for instanceID, serviceName := range instances {
+		if err := m.eventPublisher.PublishWarning(ctx, instanceID, serviceName, "budget_threshold", message); err != nil {
+			m.logger.WarnContext(ctx, "Failed to publish budget warning",
+				slog.String("instance_id", instanceID),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	m.webhooks.Notify(webhooks.Event{
+		Type:        webhooks.EventBudgetThreshold,
+		WorkspaceID: workspaceID,
+		Threshold:   threshold,
+		Error:       message,
+	})
+}
+
+// stopOverBudget stops the running instances of workspaceID, every instance when it is empty. They
+// are kept as StatusOverBudget and not restarted on their own.
+func (m *Manager) stopOverBudget(ctx context.Context, workspaceID string, percent float64) {
+	m.mutex.RLock()
+	var serviceNames []string
+	for _, container := range m.containers {
+		if workspaceID != "" && container.WorkspaceID != workspaceID {
+			continue
+		}
+		if container.StoppedAt == nil && (container.Status == models.StatusRunning || container.Status == models.StatusUnhealthy) {
+			serviceNames = append(serviceNames, container.ServiceName)
+		}
+	}
+	m.mutex.RUnlock()
+
+	for _, serviceName := range serviceNames {
+		container, err := m.stopContainer(ctx, serviceName, false)
+		if err != nil {
+			m.logger.WarnContext(ctx, "Failed to stop instance over budget",
+				slog.String("service", serviceName),
+				slog.String("error", err.Error()))
+			continue
+		}
+
+		m.mutex.Lock()
+		if err := m.store.Put(overBudgetBucket, serviceName, time.Now()); err != nil {
+			m.logger.WarnContext(ctx, "Failed to record instance stopped over budget",
+				slog.String("service", serviceName),
+				slog.String("error", err.Error()))
+		}
+		container.Status = models.StatusOverBudget
+		instanceID := container.Environment["MCP_INSTANCE_ID"]
+		containerID := container.ID
+		m.mutex.Unlock()
+
+		if instanceID != "" {
+			if err := m.eventPublisher.PublishStatusUpdate(ctx, instanceID, serviceName, events.StatusOverBudget, containerID, ""); err != nil {
+				m.logger.WarnContext(ctx, "Failed to publish over budget status",
+					slog.String("instance_id", instanceID),
+					slog.String("error", err.Error()))
+			}
+		}
+		m.logger.WarnContext(ctx, "Stopped instance over budget",
+			slog.String("service", serviceName),
+			slog.String("workspace", workspaceID),
+			slog.Float64("percent", percent))
+	}
+}
+
+// sampleCPUTime returns the CPU time the named containers used since they started, in nanoseconds
+func (m *Manager) sampleCPUTime(ctx context.Context, names []string) (map[string]uint64, error) {
+	args := append([]string{"stats", "--no-stream", "--format", "{{.Name}}\t{{.CPUNano}}"}, names...)
+	output, err := podmanCommand(ctx, m.logger, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read container stats: %w", err)
+	}
+	return parseCPUTime(output), nil
+}
+
+// parseCPUTime reads the "name\tnanoseconds" lines of sampleCPUTime
+func parseCPUTime(output []byte) map[string]uint64 {
+	cpu := make(map[string]uint64)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		name, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), "\t")
+		if !found {
+			continue
+		}
+		nanos, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			continue
+		}
+		cpu[name] = nanos
+	}
+	return cpu
+}
@@ -0,0 +1,71 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestBudgetEnforcement(t *testing.T) {
+	if err := validateBudget(models.Budget{CPUSeconds: -1}); err == nil {
+		t.Error("Expected a negative limit to be rejected")
+	}
+	if err := validateBudget(models.Budget{ContainerHours: 10, Action: "pause"}); err == nil {
+		t.Error("Expected an unknown action to be rejected")
+	}
+	if cpu := parseCPUTime([]byte("test-search\t2500000000\ntest-broken\t--\n")); cpu["test-search"] != 2500000000 || len(cpu) != 1 {
+		t.Errorf("Expected the CPU time of test-search only, got %v", cpu)
+	}
+	if delta := counterDelta(500, 200); delta != 200 {
+		t.Errorf("Expected a restarted counter to count from zero, got %d", delta)
+	}
+
+	manager := newTestManager(&config.Config{
+		Container: config.ContainerConfig{NamePrefix: "test-"},
+		State:     config.StateConfig{Dir: t.TempDir()},
+	})
+	ctx := context.Background()
+	if _, err := manager.SetBudget(ctx, models.Budget{WorkspaceID: "team-a", ContainerHours: 10, EgressBytes: 1000}); err != nil {
+		t.Fatalf("Failed to set budget: %v", err)
+	}
+
+	now := time.Now()
+	manager.chargeBudget(ctx, "team-a", models.BudgetUsage{ContainerHours: 2, EgressBytes: 850}, now)
+	record, _ := manager.readBudgetUsage("team-a")
+	if record.Notified != budgetWarningPercent {
+		t.Errorf("Expected the 80%% threshold to be announced, got %d", record.Notified)
+	}
+	if err := manager.checkBudget("team-a"); err != nil {
+		t.Errorf("Expected instances to be admitted below the budget, got %v", err)
+	}
+
+	manager.chargeBudget(ctx, "team-a", models.BudgetUsage{ContainerHours: 1, EgressBytes: 200}, now)
+	status, err := manager.GetBudget("team-a")
+	if err != nil || !status.Exhausted || status.Usage.ContainerHours != 3 || status.Budget.Action != models.BudgetActionRefuse {
+		t.Fatalf("Expected the egress limit to exhaust the budget, got %+v, %v", status, err)
+	}
+	if err := manager.checkBudget("team-a"); !errors.Is(err, ErrBudgetExhausted) {
+		t.Errorf("Expected ErrBudgetExhausted, got %v", err)
+	}
+	if err := manager.checkBudget("team-b"); err != nil {
+		t.Errorf("Expected other workspaces to be admitted, got %v", err)
+	}
+
+	if _, err := manager.ResetBudgetUsage(ctx, "team-a"); err != nil {
+		t.Fatalf("Failed to reset usage: %v", err)
+	}
+	if err := manager.checkBudget("team-a"); err != nil {
+		t.Errorf("Expected a reset to lift the refusal, got %v", err)
+	}
+	budgets, err := manager.ListBudgets()
+	if err != nil || len(budgets.Workspaces) != 1 || budgets.Workspaces[0].WorkspaceID != "team-a" {
+		t.Errorf("Expected team-a to be listed, got %+v, %v", budgets, err)
+	}
+	if err := manager.DeleteBudget(ctx, "team-b"); !errors.Is(err, ErrNoBudget) {
+		t.Errorf("Expected ErrNoBudget for a workspace without a budget, got %v", err)
+	}
+}
@@ -0,0 +1,195 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// sourceLabel stores the repository a container's image was built from
+	sourceLabel = "mcp.source"
+	// builtFromLabel marks images built from source with their repository
+	builtFromLabel = "mcp.built_from"
+	// buildJobType identifies image build jobs in the jobs API
+	buildJobType = "build"
+	// builtImagePrefix namespaces images built from source in local storage
+	builtImagePrefix = "localhost/mcp-build/"
+)
+
+var (
+	// gitRefPattern accepts branch, tag and commit names without option-like or shell characters
+	gitRefPattern = regexp.MustCompile(`^[A-Za-z0-9._][A-Za-z0-9._/-]*$`)
+	// commitRefPattern matches a full commit hash, whose build can be reused
+	commitRefPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	// scpGitURLPattern matches scp-like SSH URLs such as git@github.com:org/repo.git
+	scpGitURLPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+@[A-Za-z0-9.-]+:[A-Za-z0-9._/~-]+$`)
+)
+
+// parseSourceSpec reads the optional source object from json_spec
+func parseSourceSpec(jsonSpec map[string]interface{}) (*models.SourceConfig, error) {
+	raw, exists := jsonSpec["source"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+
+	if _, ok := raw.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("source must be an object")
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("source is not valid JSON: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	source := &models.SourceConfig{}
+	if err := decoder.Decode(source); err != nil {
+		return nil, fmt.Errorf("invalid source: %w", err)
+	}
+
+	if err := validateSourceConfig(source); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+// validateSourceConfig checks the repository URL, ref and Dockerfile path of a source
+func validateSourceConfig(source *models.SourceConfig) error {
+	if source == nil {
+		return nil
+	}
+
+	if !scpGitURLPattern.MatchString(source.GitURL) {
+		parsed, err := url.Parse(source.GitURL)
+		if err != nil || parsed.Host == "" {
+			return fmt.Errorf("source.git_url %q is not a git URL", source.GitURL)
+		}
+		switch parsed.Scheme {
+		case "https", "http", "git", "ssh":
+		default:
+			return fmt.Errorf("source.git_url scheme %q is not supported", parsed.Scheme)
+		}
+		// Credentials would end up in logs and image metadata
+		if _, hasPassword := parsed.User.Password(); hasPassword {
+			return fmt.Errorf("source.git_url must not contain credentials")
+		}
+		if parsed.Fragment != "" {
+			return fmt.Errorf("source.git_url must not contain a fragment, use source.ref")
+		}
+	}
+
+	if source.Ref != "" && (!gitRefPattern.MatchString(source.Ref) || strings.Contains(source.Ref, "..")) {
+		return fmt.Errorf("source.ref %q is not a valid git ref", source.Ref)
+	}
+
+	if source.Dockerfile != "" {
+		cleaned := path.Clean(source.Dockerfile)
+		if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			return fmt.Errorf("source.dockerfile must be a path inside the repository")
+		}
+	}
+	return nil
+}
+
+// sourceImage returns the local image a source is built into. The tag is derived from the
+// source, so rebuilding the same repository, ref and Dockerfile replaces the previous build.
+func sourceImage(serviceName string, source *models.SourceConfig) string {
+	sum := sha256.Sum256([]byte(source.GitURL + "#" + source.Ref + ":" + source.Dockerfile))
+	return builtImagePrefix + strings.ToLower(serviceName) + ":" + hex.EncodeToString(sum[:6])
+}
+
+// buildContext returns the podman build context for a source, e.g. https://host/repo.git#v1.2
+func buildContext(source *models.SourceConfig) string {
+	if source.Ref == "" {
+		return source.GitURL
+	}
+	return source.GitURL + "#" + source.Ref
+}
+
+// buildSourceImage builds a service's image from its source with podman build, recording the
+// run and its output as a build job. Builds of a full commit hash are reused when present.
+func (m *Manager) buildSourceImage(ctx context.Context, serviceName string, source *models.SourceConfig) (string, error) {
+	image := sourceImage(serviceName, source)
+	job := m.startJob(buildJobType, serviceName)
+	jobID := job.snapshot().ID
+
+	if commitRefPattern.MatchString(source.Ref) {
+		if err := podmanCommand(ctx, m.logger, "image", "exists", image).Run(); err == nil {
+			fmt.Fprintf(job, "Reusing %s built from commit %s\n", image, source.Ref)
+			m.finishJob(job, image, nil)
+			return image, nil
+		}
+	}
+
+	timeout := m.config.Container.BuildTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Minute
+	}
+	buildCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	args := []string{"build", "--pull=newer", "-t", image,
+		"--label", fmt.Sprintf("%s=%s", builtFromLabel, source.GitURL)}
+	if source.Dockerfile != "" {
+		args = append(args, "-f", source.Dockerfile)
+	}
+	args = append(args, buildContext(source))
+
+	m.logger.InfoContext(ctx, "Building image from source",
+		slog.String("service", serviceName),
+		slog.String("job_id", jobID),
+		slog.String("git_url", source.GitURL),
+		slog.String("ref", source.Ref),
+		slog.String("image", image))
+
+	started := time.Now()
+	cmd := podmanCommand(buildCtx, m.logger, args...)
+	cmd.Stdout = job
+	cmd.Stderr = job
+	err := cmd.Run()
+	if err != nil {
+		if errors.Is(buildCtx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("build timed out after %s", timeout)
+		}
+		err = fmt.Errorf("failed to build image for %s (job %s): %w", serviceName, jobID, err)
+		m.finishJob(job, "", err)
+		m.logger.ErrorContext(ctx, "Image build failed",
+			slog.String("service", serviceName),
+			slog.String("job_id", jobID),
+			slog.Duration("duration", time.Since(started)),
+			slog.String("error", err.Error()))
+		return "", err
+	}
+
+	m.finishJob(job, image, nil)
+	m.logger.InfoContext(ctx, "Image built from source",
+		slog.String("service", serviceName),
+		slog.String("job_id", jobID),
+		slog.String("image", image),
+		slog.Duration("duration", time.Since(started)))
+
+	return image, nil
+}
+
+// discoverSource restores the source persisted on a podman container
+func (m *Manager) discoverSource(ctx context.Context, containerID string) *models.SourceConfig {
+	var source models.SourceConfig
+	if !m.discoverJSONLabel(ctx, containerID, sourceLabel, &source) {
+		return nil
+	}
+	return &source
+}
@@ -0,0 +1,40 @@
+package container
+
+import (
+	"context"
+
+	"github.com/agentarea/mcp-manager/internal/callbacks"
+	schema "github.com/agentarea/mcp-manager/pkg/events"
+)
+
+// reportStatus forwards a published status update to the Core API callbacks
+func (m *Manager) reportStatus(_ context.Context, event schema.StatusUpdateEvent) {
+	m.callbacks.Report(callbacks.Progress{
+		InstanceID:  event.InstanceID,
+		Name:        event.Name,
+		Phase:       event.Status,
+		Reason:      event.Error,
+		ContainerID: event.ContainerID,
+		URL:         event.URL,
+		Timestamp:   event.Timestamp,
+	})
+}
+
+// reportPhase reports a provisioning phase that has no Redis status, such as pulling or healthy
+func (m *Manager) reportPhase(instanceID, name, phase string) {
+	m.callbacks.Report(callbacks.Progress{
+		InstanceID: instanceID,
+		Name:       name,
+		Phase:      phase,
+	})
+}
+
+// PendingCallbacks returns the number of Core API callbacks waiting in the outbox
+func (m *Manager) PendingCallbacks() int {
+	return m.callbacks.Pending()
+}
+
+// PendingEvents returns the number of lifecycle events waiting in the outbox for Redis
+func (m *Manager) PendingEvents() int {
+	return m.eventPublisher.Pending()
+}
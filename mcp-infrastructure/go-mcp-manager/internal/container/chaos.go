@@ -0,0 +1,51 @@
+package container
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/chaos"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// SetChaos lets the manager inject the faults configured in controller
+func (m *Manager) SetChaos(controller *chaos.Controller) {
+	m.chaos = controller
+	m.healthChecker.chaos = controller
+}
+
+// scheduleChaosCrash kills a just started container after the injected crash delay, unless it
+// was removed or replaced in the meantime
+func (m *Manager) scheduleChaosCrash(container *models.Container) {
+	delay := m.chaos.CrashAfter(container.ServiceName)
+	if delay <= 0 {
+		return
+	}
+
+	serviceName, containerID := container.ServiceName, container.ID
+	m.logger.Warn("Chaos: container will be killed",
+		slog.String("service", serviceName),
+		slog.Duration("after", delay))
+	time.AfterFunc(delay, func() {
+		m.mutex.RLock()
+		tracked, exists := m.containers[serviceName]
+		current := exists && tracked.ID == containerID
+		m.mutex.RUnlock()
+		if !current {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(m.healthCtx, 30*time.Second)
+		defer cancel()
+		if output, err := podmanCommand(ctx, m.logger, "kill", containerID).CombinedOutput(); err != nil {
+			m.logger.Warn("Chaos: failed to kill container",
+				slog.String("service", serviceName),
+				slog.String("error", err.Error()),
+				slog.String("output", string(output)))
+			return
+		}
+		m.inspect.invalidate(containerID)
+		m.logger.Warn("Chaos: killed container", slog.String("service", serviceName))
+	})
+}
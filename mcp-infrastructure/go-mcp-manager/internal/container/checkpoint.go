@@ -0,0 +1,282 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/webhooks"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// checkpointBucket holds the latest checkpoint of each container, keyed by service name
+	checkpointBucket = "checkpoints"
+	// checkpointedBucket records the containers a checkpoint stopped, keyed by service name
+	checkpointedBucket = "checkpointed"
+)
+
+var (
+	// ErrCheckpointUnsupported is returned when containers cannot be checkpointed, which needs the
+	// local podman engine running them without systemd units
+	ErrCheckpointUnsupported = errors.New("checkpoint and restore need the local podman engine without systemd supervision")
+	// ErrNoCheckpoint is returned when a container without a checkpoint is restored
+	ErrNoCheckpoint = errors.New("no checkpoint to restore")
+)
+
+// checkpointSupported reports whether podman can checkpoint containers with CRIU here; a remote
+// engine would write the archive on its own host and systemd would restart the stopped unit
+func (m *Manager) checkpointSupported() bool {
+	engine := currentEngine()
+	return engine.binary == enginePodman && engine.host == "" && !m.supervised()
+}
+
+// checkpointArchive resolves an archive named in a restore request, which must be in the
+// checkpoint directory
+func (m *Manager) checkpointArchive(name string) (string, error) {
+	dir := filepath.Clean(m.config.Container.CheckpointDir)
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	rel, err := filepath.Rel(dir, filepath.Clean(path))
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("checkpoint archive must be a file in %s", dir)
+	}
+	return filepath.Join(dir, rel), nil
+}
+
+// CheckpointContainer saves a running container's processes, memory and open files to an archive
+// with CRIU. The container is stopped and reports StatusCheckpointed unless req.LeaveRunning is
+// set. Only the latest checkpoint of a container is kept.
+func (m *Manager) CheckpointContainer(ctx context.Context, serviceName string, req models.CheckpointRequest) (*models.Checkpoint, error) {
+	if !m.checkpointSupported() {
+		return nil, ErrCheckpointUnsupported
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	container, exists := m.containers[serviceName]
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+	if container.StoppedAt != nil || container.Status != models.StatusRunning && container.Status != models.StatusUnhealthy {
+		return nil, fmt.Errorf("container %s is %s, only running containers can be checkpointed", serviceName, container.Status)
+	}
+
+	dir := m.config.Container.CheckpointDir
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	createdAt := time.Now()
+	archive := filepath.Join(dir, fmt.Sprintf("%s-%s.tar.gz", container.Name, createdAt.UTC().Format("20060102T150405Z")))
+
+	if !req.LeaveRunning {
+		// Record the stop first so a manager restart in between does not restart the container
+		if err := m.store.Put(stoppedBucket, serviceName, createdAt); err != nil {
+			return nil, fmt.Errorf("failed to record stopped container: %w", err)
+		}
+		if err := m.store.Put(checkpointedBucket, serviceName, createdAt); err != nil {
+			m.forgetStopped(ctx, serviceName)
+			return nil, fmt.Errorf("failed to record checkpointed container: %w", err)
+		}
+	}
+
+	args := []string{"container", "checkpoint", "--export", archive}
+	if req.LeaveRunning {
+		args = append(args, "--leave-running")
+	}
+	if req.TCPEstablished {
+		args = append(args, "--tcp-established")
+	}
+	output, err := podmanCommand(ctx, m.logger, append(args, container.ID)...).CombinedOutput()
+	m.inspect.invalidate(container.ID)
+	if err != nil {
+		if !req.LeaveRunning {
+			m.forgetStopped(ctx, serviceName)
+		}
+		_ = os.Remove(archive)
+		return nil, fmt.Errorf("failed to checkpoint container: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	checkpoint := models.Checkpoint{
+		ServiceName:    serviceName,
+		Archive:        archive,
+		LeftRunning:    req.LeaveRunning,
+		TCPEstablished: req.TCPEstablished,
+		CreatedAt:      createdAt,
+	}
+	if info, err := os.Stat(archive); err == nil {
+		checkpoint.SizeBytes = info.Size()
+	}
+	var previous models.Checkpoint
+	if found, _ := m.store.Get(checkpointBucket, serviceName, &previous); found && previous.Archive != archive {
+		_ = os.Remove(previous.Archive)
+	}
+	if err := m.store.Put(checkpointBucket, serviceName, checkpoint); err != nil {
+		m.logger.WarnContext(ctx, "Failed to record checkpoint",
+			slog.String("service", serviceName),
+			slog.String("error", err.Error()))
+	}
+
+	if !req.LeaveRunning {
+		m.markStoppedUnsafe(ctx, container, models.StatusCheckpointed, createdAt)
+	}
+
+	m.logger.InfoContext(ctx, "Container checkpointed",
+		slog.String("service", serviceName),
+		slog.String("archive", archive),
+		slog.Int64("size_bytes", checkpoint.SizeBytes),
+		slog.Bool("left_running", req.LeaveRunning))
+
+	return &checkpoint, nil
+}
+
+// RestoreContainer brings a stopped container back from its latest checkpoint, or from
+// req.Archive such as one copied from another host, with the processes in the state they were
+// saved in. The checkpoint is restored next to the container, which is only replaced once the
+// restore succeeded.
+func (m *Manager) RestoreContainer(ctx context.Context, serviceName string, req models.RestoreRequest) (*models.Container, error) {
+	if !m.checkpointSupported() {
+		return nil, ErrCheckpointUnsupported
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	container, exists := m.containers[serviceName]
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+	switch container.Status {
+	case models.StatusRunning, models.StatusUnhealthy, models.StatusStarting, models.StatusStopping:
+		if container.StoppedAt == nil {
+			return nil, fmt.Errorf("container %s is %s, stop it before restoring", serviceName, container.Status)
+		}
+	}
+
+	var checkpoint models.Checkpoint
+	recorded, err := m.store.Get(checkpointBucket, serviceName, &checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	archive := checkpoint.Archive
+	if req.Archive != "" {
+		if archive, err = m.checkpointArchive(req.Archive); err != nil {
+			return nil, err
+		}
+	} else if !recorded {
+		return nil, fmt.Errorf("container %s: %w", serviceName, ErrNoCheckpoint)
+	}
+	if _, err := os.Stat(archive); err != nil {
+		return nil, fmt.Errorf("checkpoint archive %s: %w", archive, err)
+	}
+
+	// Dependencies come up first so the restored processes find them
+	if err := m.startDependencies(ctx, container); err != nil {
+		return nil, err
+	}
+
+	restoring := container.Name + "-restoring"
+	_ = podmanCommand(ctx, m.logger, "rm", "-f", "--ignore", restoring).Run()
+	args := []string{"container", "restore", "--import", archive, "--name", restoring}
+	if container.Pod != "" {
+		args = append(args, "--pod", container.Pod)
+	}
+	if req.TCPEstablished {
+		args = append(args, "--tcp-established")
+	} else {
+		// Keeping the address only matters to restored connections, and it may be taken on another host
+		args = append(args, "--ignore-static-ip", "--ignore-static-mac")
+	}
+	output, err := podmanCommand(ctx, m.logger, args...).CombinedOutput()
+	if err != nil {
+		_ = podmanCommand(ctx, m.logger, "rm", "-f", "--ignore", restoring).Run()
+		return nil, fmt.Errorf("failed to restore container: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	lines := strings.Fields(strings.TrimSpace(string(output)))
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("failed to restore container: podman did not report the restored container")
+	}
+	restoredID := lines[len(lines)-1]
+
+	// Replace the container with the restored one under its name
+	if output, err := podmanCommand(ctx, m.logger, "rm", "-f", "--ignore", container.ID).CombinedOutput(); err != nil {
+		m.logger.WarnContext(ctx, "Failed to remove the container replaced by its restore",
+			slog.String("service", serviceName),
+			slog.String("error", err.Error()),
+			slog.String("output", string(output)))
+	}
+	m.inspect.invalidate(container.ID)
+	if output, err := podmanCommand(ctx, m.logger, "rename", restoredID, container.Name).CombinedOutput(); err != nil {
+		m.logger.WarnContext(ctx, "Restored container keeps its temporary name",
+			slog.String("service", serviceName),
+			slog.String("name", restoring),
+			slog.String("error", err.Error()),
+			slog.String("output", string(output)))
+	}
+	container.ID = restoredID
+
+	if err := m.clearStopRecords(serviceName); err != nil {
+		m.logger.WarnContext(ctx, "Failed to clear stop records of restored container",
+			slog.String("service", serviceName),
+			slog.String("error", err.Error()))
+	}
+	container.StoppedAt = nil
+	container.Status = models.StatusStarting
+	container.UpdatedAt = time.Now()
+	if recorded && archive == checkpoint.Archive {
+		restoredAt := time.Now()
+		checkpoint.RestoredAt = &restoredAt
+		_ = m.store.Put(checkpointBucket, serviceName, checkpoint)
+	}
+
+	if err := m.finishStart(ctx, container); err != nil {
+		return container, err
+	}
+	m.notifyWebhook(webhooks.EventContainerStarted, container, "")
+
+	m.logger.InfoContext(ctx, "Container restored from checkpoint",
+		slog.String("service", serviceName),
+		slog.String("archive", archive),
+		slog.String("container_id", restoredID))
+
+	return container, nil
+}
+
+// GetCheckpoint returns the latest checkpoint of a container
+func (m *Manager) GetCheckpoint(serviceName string) (*models.Checkpoint, error) {
+	var checkpoint models.Checkpoint
+	found, err := m.store.Get(checkpointBucket, serviceName, &checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("container %s: %w", serviceName, ErrNoCheckpoint)
+	}
+	return &checkpoint, nil
+}
+
+// forgetCheckpoint removes the checkpoint record and archive of a deleted container
+func (m *Manager) forgetCheckpoint(ctx context.Context, serviceName string) {
+	var checkpoint models.Checkpoint
+	if found, _ := m.store.Get(checkpointBucket, serviceName, &checkpoint); !found {
+		return
+	}
+	if err := os.Remove(checkpoint.Archive); err != nil && !errors.Is(err, os.ErrNotExist) {
+		m.logger.WarnContext(ctx, "Failed to remove checkpoint archive",
+			slog.String("archive", checkpoint.Archive),
+			slog.String("error", err.Error()))
+	}
+	if err := m.store.Delete(checkpointBucket, serviceName); err != nil {
+		m.logger.WarnContext(ctx, "Failed to clear checkpoint record",
+			slog.String("service", serviceName),
+			slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,53 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestCheckpointArchives(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{
+		Container: config.ContainerConfig{NamePrefix: "test-", CheckpointDir: dir},
+		State:     config.StateConfig{Dir: t.TempDir()},
+	}
+	manager := newTestManager(cfg)
+
+	if path, err := manager.checkpointArchive("test-browser-20261018T090000Z.tar.gz"); err != nil || path != filepath.Join(dir, "test-browser-20261018T090000Z.tar.gz") {
+		t.Errorf("Expected an archive in the checkpoint directory, got %q, %v", path, err)
+	}
+	for _, name := range []string{"../state.json", "/etc/passwd", ".", filepath.Join(dir, "..", "other.tar.gz")} {
+		if _, err := manager.checkpointArchive(name); err == nil {
+			t.Errorf("Expected archive %q outside the checkpoint directory to be rejected", name)
+		}
+	}
+
+	if _, err := manager.GetCheckpoint("browser"); !errors.Is(err, ErrNoCheckpoint) {
+		t.Errorf("Expected ErrNoCheckpoint, got %v", err)
+	}
+	archive := filepath.Join(dir, "test-browser.tar.gz")
+	if err := os.WriteFile(archive, []byte("checkpoint"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.store.Put(checkpointBucket, "browser", models.Checkpoint{ServiceName: "browser", Archive: archive}); err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint, err := manager.GetCheckpoint("browser"); err != nil || checkpoint.Archive != archive {
+		t.Errorf("Expected the recorded checkpoint, got %+v, %v", checkpoint, err)
+	}
+	manager.forgetCheckpoint(context.Background(), "browser")
+	if _, err := os.Stat(archive); !os.IsNotExist(err) {
+		t.Errorf("Expected the archive of a deleted container to be removed, got %v", err)
+	}
+
+	cfg.Container.Supervisor = supervisorSystemd
+	if _, err := manager.CheckpointContainer(context.Background(), "browser", models.CheckpointRequest{}); !errors.Is(err, ErrCheckpointUnsupported) {
+		t.Errorf("Expected checkpoints to be refused under systemd, got %v", err)
+	}
+}
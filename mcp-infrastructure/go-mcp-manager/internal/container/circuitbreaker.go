@@ -0,0 +1,200 @@
+package container
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// managerServiceName is the Traefik service routing to the manager API
+const managerServiceName = "mcp-manager-service"
+
+// managerTransportName is the Traefik servers transport of the manager API when it requires client certificates
+const managerTransportName = "mcp-manager-transport"
+
+// Bounds accepted for circuit breaker and retry options
+const (
+	maxBreakerExpressionLength = 256
+	maxBreakerSeconds          = 3600
+	maxRetryAttempts           = 10
+	maxRetryIntervalMs         = 60000
+)
+
+// breakerExpressionPattern admits Traefik breaker expressions, e.g. "LatencyAtQuantileMS(50.0) > 100",
+// but no quotes or line breaks that could escape the generated config
+var breakerExpressionPattern = regexp.MustCompile(`^[A-Za-z0-9_().,<>=!|& ]+$`)
+
+// circuitState records when the proxy last rejected requests to each container
+type circuitState struct {
+	mutex sync.Mutex
+	trips map[string]*circuitTrips
+}
+
+// circuitTrips counts the rejections observed for one container
+type circuitTrips struct {
+	count uint64
+	last  time.Time
+}
+
+// unavailablePath returns the manager path the proxy fetches its error page for a route from
+func unavailablePath(slug string) string {
+	return "/proxy/unavailable/" + slug
+}
+
+// validateRouteResilience checks the circuit breaker and retry options of a route
+func validateRouteResilience(route *models.RouteConfig) error {
+	if cb := route.CircuitBreaker; cb != nil {
+		if cb.Expression != "" && (len(cb.Expression) > maxBreakerExpressionLength || !breakerExpressionPattern.MatchString(cb.Expression)) {
+			return fmt.Errorf("route.circuit_breaker.expression must be at most %d characters of a Traefik breaker expression", maxBreakerExpressionLength)
+		}
+		if cb.FallbackSeconds < 0 || cb.FallbackSeconds > maxBreakerSeconds {
+			return fmt.Errorf("route.circuit_breaker.fallback_seconds must be between 0 and %d", maxBreakerSeconds)
+		}
+		if cb.RecoverySeconds < 0 || cb.RecoverySeconds > maxBreakerSeconds {
+			return fmt.Errorf("route.circuit_breaker.recovery_seconds must be between 0 and %d", maxBreakerSeconds)
+		}
+	}
+
+	if r := route.Retry; r != nil {
+		if r.Attempts < 1 || r.Attempts > maxRetryAttempts {
+			return fmt.Errorf("route.retry.attempts must be between 1 and %d", maxRetryAttempts)
+		}
+		if r.InitialIntervalMs < 0 || r.InitialIntervalMs > maxRetryIntervalMs {
+			return fmt.Errorf("route.retry.initial_interval_ms must be between 0 and %d", maxRetryIntervalMs)
+		}
+	}
+	return nil
+}
+
+// resolveCircuitBreaker merges a route's breaker override into the proxy-wide defaults, returning
+// nil and false when the route runs without a breaker
+func resolveCircuitBreaker(defaults config.TraefikCircuitBreakerConfig, route *models.RouteConfig) (*models.RouteCircuitBreaker, bool) {
+	var override *models.RouteCircuitBreaker
+	if route != nil {
+		override = route.CircuitBreaker
+	}
+	if override != nil && override.Disabled {
+		return nil, false
+	}
+	if override == nil && !defaults.Enabled {
+		return nil, false
+	}
+
+	breaker := &models.RouteCircuitBreaker{
+		Expression:      defaults.Expression,
+		FallbackSeconds: int(defaults.FallbackDuration.Seconds()),
+		RecoverySeconds: int(defaults.RecoveryDuration.Seconds()),
+	}
+	if override != nil {
+		if override.Expression != "" {
+			breaker.Expression = override.Expression
+		}
+		if override.FallbackSeconds > 0 {
+			breaker.FallbackSeconds = override.FallbackSeconds
+		}
+		if override.RecoverySeconds > 0 {
+			breaker.RecoverySeconds = override.RecoverySeconds
+		}
+	}
+	if breaker.Expression == "" {
+		return nil, false
+	}
+	breaker.FallbackSeconds = max(breaker.FallbackSeconds, 1)
+	breaker.RecoverySeconds = max(breaker.RecoverySeconds, 1)
+	return breaker, true
+}
+
+// RecordCircuitTrip records that the proxy rejected a request to the route with slug and returns the
+// container's service name and how long clients should wait before retrying
+func (m *Manager) RecordCircuitTrip(slug string) (string, time.Duration, bool) {
+	m.mutex.RLock()
+	var found *models.Container
+	for _, container := range m.containers {
+		if container.Slug == slug {
+			found = container
+			break
+		}
+	}
+	var serviceName string
+	var breaker *models.RouteCircuitBreaker
+	if found != nil {
+		serviceName = found.ServiceName
+		breaker, _ = resolveCircuitBreaker(m.config.Traefik.CircuitBreaker, found.Route)
+	}
+	m.mutex.RUnlock()
+
+	if found == nil {
+		return "", 0, false
+	}
+
+	m.circuits.mutex.Lock()
+	if m.circuits.trips == nil {
+		m.circuits.trips = make(map[string]*circuitTrips)
+	}
+	trips, exists := m.circuits.trips[serviceName]
+	if !exists {
+		trips = &circuitTrips{}
+		m.circuits.trips[serviceName] = trips
+	}
+	trips.count++
+	trips.last = time.Now()
+	m.circuits.mutex.Unlock()
+
+	// A 503 from the upstream itself also lands here when the route has no breaker
+	retryAfter := time.Second
+	if breaker != nil {
+		retryAfter = time.Duration(breaker.FallbackSeconds) * time.Second
+	}
+	return serviceName, retryAfter, true
+}
+
+// CircuitBreakerStatus reports a container's breaker. The proxy keeps no queryable breaker state, so
+// the state follows the breaker's timeline from the last rejected request: open for the fallback
+// period, half open while traffic recovers, then closed.
+func (m *Manager) CircuitBreakerStatus(serviceName string) (*models.CircuitBreakerStatus, error) {
+	m.mutex.RLock()
+	container, exists := m.containers[serviceName]
+	var breaker *models.RouteCircuitBreaker
+	if exists {
+		breaker, _ = resolveCircuitBreaker(m.config.Traefik.CircuitBreaker, container.Route)
+	}
+	m.mutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+
+	status := &models.CircuitBreakerStatus{State: models.CircuitDisabled}
+	if breaker != nil {
+		status.State = models.CircuitClosed
+		status.Expression = breaker.Expression
+		status.FallbackSeconds = breaker.FallbackSeconds
+		status.RecoverySeconds = breaker.RecoverySeconds
+	}
+
+	m.circuits.mutex.Lock()
+	trips, tripped := m.circuits.trips[serviceName]
+	if tripped {
+		status.Trips = trips.count
+		last := trips.last
+		status.LastTrippedAt = &last
+	}
+	m.circuits.mutex.Unlock()
+
+	if breaker != nil && tripped {
+		since := time.Since(*status.LastTrippedAt)
+		fallback := time.Duration(breaker.FallbackSeconds) * time.Second
+		recovery := time.Duration(breaker.RecoverySeconds) * time.Second
+		switch {
+		case since < fallback:
+			status.State = models.CircuitOpen
+		case since < fallback+recovery:
+			status.State = models.CircuitHalfOpen
+		}
+	}
+	return status, nil
+}
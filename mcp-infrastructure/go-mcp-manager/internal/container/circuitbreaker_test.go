@@ -0,0 +1,75 @@
+package container
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	defaults := config.TraefikCircuitBreakerConfig{
+		Enabled:          true,
+		Expression:       "NetworkErrorRatio() > 0.50",
+		FallbackDuration: 30 * time.Second,
+		RecoveryDuration: 10 * time.Second,
+	}
+
+	breaker, ok := resolveCircuitBreaker(defaults, &models.RouteConfig{
+		CircuitBreaker: &models.RouteCircuitBreaker{FallbackSeconds: 5},
+	})
+	if !ok || breaker.Expression != defaults.Expression || breaker.FallbackSeconds != 5 || breaker.RecoverySeconds != 10 {
+		t.Errorf("Expected route override merged into defaults, got %+v", breaker)
+	}
+	if _, ok := resolveCircuitBreaker(defaults, &models.RouteConfig{CircuitBreaker: &models.RouteCircuitBreaker{Disabled: true}}); ok {
+		t.Errorf("Expected a disabled route breaker to be resolved to none")
+	}
+	if _, ok := resolveCircuitBreaker(config.TraefikCircuitBreakerConfig{}, nil); ok {
+		t.Errorf("Expected no breaker when disabled by default")
+	}
+
+	invalid := []*models.RouteConfig{
+		{CircuitBreaker: &models.RouteCircuitBreaker{Expression: "NetworkErrorRatio() > 0.5\"\ninjected: true"}},
+		{CircuitBreaker: &models.RouteCircuitBreaker{FallbackSeconds: -1}},
+		{Retry: &models.RouteRetry{Attempts: 0}},
+	}
+	for _, route := range invalid {
+		if err := validateRouteConfig(route); err == nil {
+			t.Errorf("Expected route %+v to be rejected", route)
+		}
+	}
+
+	route := &models.RouteConfig{Retry: &models.RouteRetry{Attempts: 3, InitialIntervalMs: 100}}
+	breaker, _ = resolveCircuitBreaker(defaults, route)
+	middlewares, chain := buildRouteMiddlewares("svc-abc", route, breaker, nil, "http://localhost:8000")
+	expected := []string{"mcp-svc-abc-unavailable", "mcp-svc-abc-circuitbreaker", "mcp-svc-abc-retry", "mcp-svc-abc-stripprefix"}
+	if strings.Join(chain, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected middlewares %v, got %v", expected, chain)
+	}
+	if page := middlewares[unavailableMiddleware].Errors; page == nil || page.Service != managerServiceName || page.Query != "/proxy/unavailable/svc-abc" {
+		t.Errorf("Expected the error page to be served by the manager, got %+v", page)
+	}
+	if cb := middlewares[circuitBreakerMiddleware].CircuitBreaker; cb == nil || cb.FallbackDuration != "30s" {
+		t.Errorf("Expected a 30s fallback, got %+v", cb)
+	}
+
+	manager := newTestManager(&config.Config{Traefik: config.TraefikConfig{CircuitBreaker: defaults}})
+	manager.containers["github"] = &models.Container{ServiceName: "github", Slug: "github-ab12"}
+
+	status, err := manager.CircuitBreakerStatus("github")
+	if err != nil || status.State != models.CircuitClosed {
+		t.Errorf("Expected a closed breaker before any rejection, got %+v, %v", status, err)
+	}
+	serviceName, retryAfter, found := manager.RecordCircuitTrip("github-ab12")
+	if !found || serviceName != "github" || retryAfter != 30*time.Second {
+		t.Errorf("Expected a trip of github with 30s Retry-After, got %s %v %v", serviceName, retryAfter, found)
+	}
+	if status, _ := manager.CircuitBreakerStatus("github"); status.State != models.CircuitOpen || status.Trips != 1 {
+		t.Errorf("Expected an open breaker after a rejection, got %+v", status)
+	}
+	if _, _, found := manager.RecordCircuitTrip("unknown"); found {
+		t.Errorf("Expected no trip for an unknown route")
+	}
+}
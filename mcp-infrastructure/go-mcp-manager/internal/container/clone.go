@@ -0,0 +1,194 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// clonedFromLabel records the service a container was cloned from
+const clonedFromLabel = "mcp.cloned_from"
+
+// secretEnvPattern matches environment variable names that are treated as secrets and not cloned
+var secretEnvPattern = regexp.MustCompile(`(?i)(SECRET|TOKEN|PASSWORD|PASSWD|API_?KEY|PRIVATE_?KEY|CREDENTIAL|AUTH)`)
+
+// CloneContainer creates a new container from an existing container's effective spec.
+// The image is pinned to the digest the source runs, secret-like environment variables are
+// dropped, and with Snapshot the source's filesystem is committed first so state carries over.
+func (m *Manager) CloneContainer(ctx context.Context, sourceService string, req models.CloneContainerRequest) (*models.CloneContainerResponse, error) {
+	m.mutex.RLock()
+	tracked, exists := m.containers[sourceService]
+	var source models.Container
+	if exists {
+		source = *tracked
+	}
+	m.mutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", sourceService)
+	}
+	if req.ServiceName == sourceService {
+		return nil, fmt.Errorf("clone must use a different service name than %s", sourceService)
+	}
+	// Checked before the snapshot, which the create would refuse anyway
+	if m.IsPreempted() {
+		return nil, ErrHostPreempted
+	}
+
+	image := source.Image
+	if req.Snapshot {
+		snapshot, err := m.snapshotContainer(ctx, &source, req.ServiceName)
+		if err != nil {
+			return nil, err
+		}
+		image = snapshot
+	} else if pinned := m.pinnedImage(ctx, &source); pinned != "" {
+		image = pinned
+	}
+
+	environment, omitted := cloneEnvironment(source.Environment)
+	// Dependency addresses are injected again for the clone's own sidecars
+	for _, dependency := range source.DependsOn {
+		prefix := dependencyEnvPrefix(dependency.Name)
+		delete(environment, prefix+"_HOST")
+		delete(environment, prefix+"_PORT")
+		delete(environment, prefix+"_URL")
+	}
+	maps.Copy(environment, req.Environment)
+
+	labels := maps.Clone(source.Labels)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[clonedFromLabel] = sourceService
+
+	createReq := models.CreateContainerRequest{
+		ServiceName:    req.ServiceName,
+		Image:          image,
+		Port:           source.Port,
+		Environment:    environment,
+		Labels:         labels,
+		Command:        slices.Clone(source.Command),
+		HealthCheck:    source.HealthCheck,
+		Route:          source.Route,
+		GPUs:           source.GPUs,
+		Devices:        slices.Clone(source.Devices),
+		Resources:      source.Resources,
+		Security:       source.Security,
+		WorkspaceID:    source.WorkspaceID,
+		SharedNetwork:  source.SharedNetwork,
+		DNS:            slices.Clone(source.DNS),
+		ExtraHosts:     slices.Clone(source.ExtraHosts),
+		Proxy:          source.Proxy,
+		Tmpfs:          slices.Clone(source.Tmpfs),
+		ScratchVolumes: slices.Clone(source.ScratchVolumes),
+		Files:          maps.Clone(source.Files),
+		DependsOn:      slices.Clone(source.DependsOn),
+		Init:           source.Init,
+		LogShipping:    source.LogShipping,
+		EnvSchema:      slices.Clone(source.EnvSchema),
+	}
+	// A hostname routes to a single container, so the clone is reachable by path only
+	if source.Routing != nil && source.Routing.Type != models.RoutingHost {
+		createReq.Routing = source.Routing
+	}
+
+	container, err := m.CreateContainer(ctx, createReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clone of %s: %w", sourceService, err)
+	}
+
+	m.logger.InfoContext(ctx, "Container cloned",
+		slog.String("source", sourceService),
+		slog.String("service", req.ServiceName),
+		slog.String("image", image),
+		slog.Bool("snapshot", req.Snapshot),
+		slog.Int("omitted_env", len(omitted)))
+
+	return &models.CloneContainerResponse{
+		Container:          container,
+		Source:             sourceService,
+		Image:              image,
+		Snapshot:           req.Snapshot,
+		OmittedEnvironment: omitted,
+	}, nil
+}
+
+// cloneEnvironment copies an environment without secret-like and manager-owned variables
+func cloneEnvironment(environment map[string]string) (map[string]string, []string) {
+	cloned := make(map[string]string, len(environment))
+	var omitted []string
+	for key, value := range environment {
+		switch {
+		case key == "MCP_INSTANCE_ID":
+			// The clone is not an instance known to the core API
+		case secretEnvPattern.MatchString(key):
+			omitted = append(omitted, key)
+		default:
+			cloned[key] = value
+		}
+	}
+	slices.Sort(omitted)
+	return cloned, omitted
+}
+
+// pinnedImage returns the source image pinned to the digest its container runs, or "" if unknown
+func (m *Manager) pinnedImage(ctx context.Context, container *models.Container) string {
+	if container.ID == "" {
+		return ""
+	}
+	output, err := podmanCommand(ctx, m.logger, "inspect", container.ID, "--format", "{{.ImageDigest}}").Output()
+	if err != nil {
+		m.logger.DebugContext(ctx, "Failed to read image digest, cloning by tag",
+			slog.String("container", container.Name),
+			slog.String("error", err.Error()))
+		return ""
+	}
+
+	digest := strings.TrimSpace(string(output))
+	if !strings.HasPrefix(digest, "sha256:") {
+		return ""
+	}
+	return imageRepository(container.Image) + "@" + digest
+}
+
+// imageRepository strips the tag and digest from an image reference
+func imageRepository(image string) string {
+	if at := strings.Index(image, "@"); at >= 0 {
+		image = image[:at]
+	}
+	// A colon after the last slash separates the tag; earlier colons belong to a registry port
+	if colon := strings.LastIndex(image, ":"); colon > strings.LastIndex(image, "/") {
+		image = image[:colon]
+	}
+	return image
+}
+
+// snapshotContainer commits a container's filesystem to a local image for the clone to run from.
+// Volumes, including scratch volumes, are not part of the snapshot.
+func (m *Manager) snapshotContainer(ctx context.Context, container *models.Container, cloneService string) (string, error) {
+	if container.ID == "" {
+		return "", fmt.Errorf("container %s has no podman container to snapshot", container.ServiceName)
+	}
+
+	image := fmt.Sprintf("localhost/%s-snapshot:%s", m.config.GetContainerName(cloneService), time.Now().UTC().Format("20060102150405"))
+	output, err := podmanCommand(ctx, m.logger, "commit", "--pause=false",
+		"--change", fmt.Sprintf("LABEL %s=%s", clonedFromLabel, container.ServiceName),
+		container.ID, image).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to snapshot container %s: %w: %s", container.ServiceName, err, strings.TrimSpace(string(output)))
+	}
+
+	m.logger.InfoContext(ctx, "Committed container snapshot",
+		slog.String("service", container.ServiceName),
+		slog.String("image", image))
+
+	return image, nil
+}
@@ -0,0 +1,54 @@
+package container
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestCloneSpec(t *testing.T) {
+	environment, omitted := cloneEnvironment(map[string]string{
+		"MCP_INSTANCE_ID":  "abc",
+		"GITHUB_TOKEN":     "ghp_x",
+		"OPENAI_API_KEY":   "sk-x",
+		"LOG_LEVEL":        "debug",
+		"DB_PASSWORD":      "hunter2",
+		"MCP_SERVICE_NAME": "github",
+	})
+	if len(environment) != 2 || environment["LOG_LEVEL"] != "debug" || environment["MCP_SERVICE_NAME"] != "github" {
+		t.Errorf("Expected only non-secret variables to be copied, got %v", environment)
+	}
+	expected := []string{"DB_PASSWORD", "GITHUB_TOKEN", "OPENAI_API_KEY"}
+	if strings.Join(omitted, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected omitted %v, got %v", expected, omitted)
+	}
+
+	for image, expected := range map[string]string{
+		"nginx":                               "nginx",
+		"nginx:1.25":                          "nginx",
+		"registry:5000/team/mcp:v1":           "registry:5000/team/mcp",
+		"ghcr.io/org/mcp@sha256:abc":          "ghcr.io/org/mcp",
+		"localhost:5000/mcp:latest@sha256:ab": "localhost:5000/mcp",
+	} {
+		if got := imageRepository(image); got != expected {
+			t.Errorf("Expected repository %s for %s, got %s", expected, image, got)
+		}
+	}
+
+	cfg := &config.Config{
+		Container: config.ContainerConfig{NamePrefix: "test-", MaxContainers: 10},
+		State:     config.StateConfig{Dir: t.TempDir()},
+	}
+	manager := newTestManager(cfg)
+	manager.containers["github"] = &models.Container{Name: "test-github", ServiceName: "github"}
+
+	if _, err := manager.CloneContainer(context.Background(), "missing", models.CloneContainerRequest{ServiceName: "copy"}); err == nil {
+		t.Error("Expected cloning an unknown container to fail")
+	}
+	if _, err := manager.CloneContainer(context.Background(), "github", models.CloneContainerRequest{ServiceName: "github"}); err == nil {
+		t.Error("Expected cloning onto the source's service name to fail")
+	}
+}
@@ -0,0 +1,255 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// composeHeader explains the export to whoever runs it
+const composeHeader = `# Managed MCP instances exported by mcp-manager.
+# Secret and unclassified environment values are ${VARIABLE} placeholders: set them in .env or
+# the shell before "compose up". Ports are published on loopback instead of routed by the proxy,
+# and stopped instances are in the "stopped" profile.
+`
+
+// composeFile is a compose file as docker compose and podman-compose read it
+type composeFile struct {
+	Services map[string]*composeService `yaml:"services"`
+	Networks map[string]composeNetwork  `yaml:"networks,omitempty"`
+}
+
+// composeService is one container of a compose file
+type composeService struct {
+	Image         string            `yaml:"image"`
+	ContainerName string            `yaml:"container_name"`
+	Command       []string          `yaml:"command,omitempty"`
+	Environment   map[string]string `yaml:"environment,omitempty"`
+	Labels        map[string]string `yaml:"labels,omitempty"`
+	Networks      []string          `yaml:"networks,omitempty"`
+	Ports         []string          `yaml:"ports,omitempty"`
+	DNS           []string          `yaml:"dns,omitempty"`
+	ExtraHosts    []string          `yaml:"extra_hosts,omitempty"`
+	DependsOn     []string          `yaml:"depends_on,omitempty"`
+	Profiles      []string          `yaml:"profiles,omitempty"`
+	MemLimit      string            `yaml:"mem_limit,omitempty"`
+	CPUs          string            `yaml:"cpus,omitempty"`
+	PidsLimit     int               `yaml:"pids_limit,omitempty"`
+	ReadOnly      bool              `yaml:"read_only,omitempty"`
+	User          string            `yaml:"user,omitempty"`
+	CapDrop       []string          `yaml:"cap_drop,omitempty"`
+	CapAdd        []string          `yaml:"cap_add,omitempty"`
+	SecurityOpt   []string          `yaml:"security_opt,omitempty"`
+	Tmpfs         []string          `yaml:"tmpfs,omitempty"`
+	Restart       string            `yaml:"restart,omitempty"`
+}
+
+// composeNetwork keeps the network's name, so exported containers reach each other as they do now
+type composeNetwork struct {
+	Name string `yaml:"name"`
+}
+
+// ExportCompose returns a compose file describing every managed instance and its sidecars. Values
+// that may be credentials are replaced by placeholders, as GET /containers/:service/spec masks
+// them. Adopted containers are left out, since their spec is not known to the manager.
+func (m *Manager) ExportCompose(ctx context.Context) ([]byte, error) {
+	file := composeFile{
+		Services: make(map[string]*composeService),
+		Networks: make(map[string]composeNetwork),
+	}
+
+	m.mutex.RLock()
+	for _, container := range m.containers {
+		if _, adopted := m.adoption(container.Name); adopted {
+			continue
+		}
+		service := m.composeService(container)
+		for _, dependency := range container.DependsOn {
+			if !isSidecar(dependency) {
+				if target, exists := m.containers[dependency.Service]; exists {
+					service.DependsOn = append(service.DependsOn, target.Name)
+				}
+				continue
+			}
+			sidecar := m.composeSidecar(container, dependency)
+			file.Services[sidecar.ContainerName] = sidecar
+			service.DependsOn = append(service.DependsOn, sidecar.ContainerName)
+		}
+		sort.Strings(service.DependsOn)
+		file.Services[service.ContainerName] = service
+		for _, network := range service.Networks {
+			file.Networks[network] = composeNetwork{Name: network}
+		}
+	}
+	m.mutex.RUnlock()
+
+	var buffer bytes.Buffer
+	buffer.WriteString(composeHeader)
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(file); err != nil {
+		return nil, fmt.Errorf("failed to encode compose file: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode compose file: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// composeService describes container as a compose service
+func (m *Manager) composeService(container *models.Container) *composeService {
+	service := &composeService{
+		Image:         container.Image,
+		ContainerName: container.Name,
+		Command:       composeEscapeAll(container.Command),
+		Environment:   composeEnvironment(container),
+		Labels:        container.Labels,
+		Networks:      m.containerNetworks(container),
+		DNS:           container.DNS,
+		ExtraHosts:    container.ExtraHosts,
+		Restart:       "unless-stopped",
+	}
+	composeProxy(service, container)
+	if container.Port > 0 {
+		service.Ports = []string{fmt.Sprintf("127.0.0.1::%d", container.Port)}
+	}
+	if container.StoppedAt != nil || container.Status == models.StatusStopped || container.Status == models.StatusScheduledOff {
+		service.Profiles = []string{"stopped"}
+	}
+	if limits := container.Resources; limits != nil {
+		if memory, err := config.ParseMemory(limits.Memory); err == nil && limits.Memory != "" {
+			service.MemLimit = strconv.FormatInt(memory, 10)
+		}
+		if cpus, err := config.ParseCPU(limits.CPU); err == nil && limits.CPU != "" {
+			service.CPUs = strconv.FormatFloat(cpus, 'f', -1, 64)
+		}
+		service.PidsLimit = limits.PidsLimit
+	}
+	for _, mount := range container.Tmpfs {
+		service.Tmpfs = append(service.Tmpfs, mount.Path)
+	}
+	m.composeSecurity(service, container)
+	return service
+}
+
+// composeSecurity applies the hardening podmanSecurityArgs gives the container
+func (m *Manager) composeSecurity(service *composeService, container *models.Container) {
+	policy := m.config.Container.Security
+	if !policy.Hardened {
+		return
+	}
+	security := container.Security
+	if security == nil {
+		security = &models.SecurityConfig{}
+	}
+	service.ReadOnly = security.ReadOnlyRootFS == nil || *security.ReadOnlyRootFS
+	if service.ReadOnly {
+		// podman mounts these tmpfs itself for read-only containers, docker does not
+		service.Tmpfs = append(service.Tmpfs, "/tmp", "/var/tmp", "/run")
+		if _, exists := service.Environment["HOME"]; !exists {
+			if service.Environment == nil {
+				service.Environment = make(map[string]string)
+			}
+			service.Environment["HOME"] = "/tmp"
+		}
+	}
+	service.CapDrop = []string{"ALL"}
+	for _, capability := range security.AddCapabilities {
+		service.CapAdd = append(service.CapAdd, normalizeCapability(capability))
+	}
+	service.User = security.User
+	if service.User == "" {
+		service.User = policy.DefaultUser
+	}
+	if !security.AllowPrivilegeEscalation {
+		service.SecurityOpt = append(service.SecurityOpt, "no-new-privileges")
+	}
+}
+
+// composeProxy adds the proxy variables connectivityArgs injects, with a placeholder for a proxy
+// URL carrying a password
+func composeProxy(service *composeService, container *models.Container) {
+	for key, value := range proxyEnvironment(container) {
+		if _, exists := container.Environment[key]; exists {
+			continue
+		}
+		if service.Environment == nil {
+			service.Environment = make(map[string]string)
+		}
+		if redactURL(value) != value {
+			service.Environment[key] = "${" + composeVariable(container.Name, key) + "}"
+		} else {
+			service.Environment[key] = composeEscape(value)
+		}
+	}
+}
+
+// composeSidecar describes a container's sidecar dependency as a compose service
+func (m *Manager) composeSidecar(container *models.Container, dependency models.Dependency) *composeService {
+	sidecar := &composeService{
+		Image:         dependency.Image,
+		ContainerName: m.sidecarContainerName(container, dependency),
+		Command:       composeEscapeAll(dependency.Command),
+		Environment:   make(map[string]string, len(dependency.Environment)),
+		Labels:        map[string]string{dependencyOfLabel: container.ServiceName},
+		Networks:      m.containerNetworks(&models.Container{Network: container.Network}),
+		Restart:       "unless-stopped",
+	}
+	// Sidecar environments are not classified by an env_schema, so all of their values are hidden
+	for key := range dependency.Environment {
+		sidecar.Environment[key] = "${" + composeVariable(sidecar.ContainerName, key) + "}"
+	}
+	if container.StoppedAt != nil {
+		sidecar.Profiles = []string{"stopped"}
+	}
+	return sidecar
+}
+
+// composeEnvironment returns the container's environment with every value maskEnvironment hides,
+// and every secret reference, replaced by a placeholder named after the container and variable
+func composeEnvironment(container *models.Container) map[string]string {
+	if len(container.Environment) == 0 {
+		return nil
+	}
+	masked := maskEnvironment(container.Environment, container.EnvSchema)
+	environment := make(map[string]string, len(container.Environment))
+	for key, value := range container.Environment {
+		if masked[key] == maskedValue || strings.HasPrefix(value, "secret_ref:") {
+			environment[key] = "${" + composeVariable(container.Name, key) + "}"
+		} else {
+			environment[key] = composeEscape(value)
+		}
+	}
+	return environment
+}
+
+// composeVariable returns the placeholder variable for a container's environment variable, e.g.
+// MCP_GITHUB_API_KEY for API_KEY of mcp-github
+func composeVariable(containerName, key string) string {
+	return dependencyEnvPrefix(containerName) + "_" + dependencyEnvPrefix(key)
+}
+
+// composeEscape escapes the $ compose would otherwise interpolate
+func composeEscape(value string) string {
+	return strings.ReplaceAll(value, "$", "$$")
+}
+
+// composeEscapeAll escapes each of values
+func composeEscapeAll(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = composeEscape(value)
+	}
+	return escaped
+}
@@ -0,0 +1,66 @@
+package container
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestExportCompose(t *testing.T) {
+	cfg := &config.Config{Container: config.ContainerConfig{NamePrefix: "mcp-"}}
+	cfg.Container.Security.Hardened = true
+	cfg.Traefik.Network = "mcp-network"
+	manager := newTestManager(cfg)
+
+	stoppedAt := time.Now()
+	manager.containers["memory"] = &models.Container{
+		Name:        "mcp-memory",
+		ServiceName: "memory",
+		Image:       "bridge:latest",
+		Port:        8000,
+		Command:     []string{"--port", "$PORT"},
+		Environment: map[string]string{"API_KEY": "sk-live", "REGION": "eu", "TOKEN": "secret_ref:token"},
+		EnvSchema:   []models.EnvVarSpec{{Name: "REGION"}},
+		Resources:   &models.ResourceLimits{Memory: "256m"},
+		DependsOn:   []models.Dependency{{Name: "db", Image: "postgres:16", Environment: map[string]string{"POSTGRES_PASSWORD": "pw"}}},
+	}
+	manager.containers["search"] = &models.Container{Name: "mcp-search", ServiceName: "search", Image: "search:1", StoppedAt: &stoppedAt}
+
+	data, err := manager.ExportCompose(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to export compose file: %v", err)
+	}
+	if strings.Contains(string(data), "sk-live") || strings.Contains(string(data), "pw\n") {
+		t.Errorf("Expected secrets to be replaced by placeholders, got:\n%s", data)
+	}
+
+	var file composeFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		t.Fatalf("Expected valid YAML, got %v", err)
+	}
+	memory := file.Services["mcp-memory"]
+	if memory == nil || memory.Image != "bridge:latest" || memory.MemLimit != "268435456" {
+		t.Fatalf("Expected the instance with its limits, got %+v", memory)
+	}
+	if memory.Environment["API_KEY"] != "${MCP_MEMORY_API_KEY}" || memory.Environment["TOKEN"] != "${MCP_MEMORY_TOKEN}" || memory.Environment["REGION"] != "eu" {
+		t.Errorf("Expected placeholders for secrets and public values kept, got %v", memory.Environment)
+	}
+	if memory.Command[1] != "$$PORT" || !memory.ReadOnly || memory.Ports[0] != "127.0.0.1::8000" {
+		t.Errorf("Expected an escaped command, a read-only root and a loopback port, got %+v", memory)
+	}
+	if len(memory.DependsOn) != 1 || file.Services[memory.DependsOn[0]] == nil || file.Services[memory.DependsOn[0]].Image != "postgres:16" {
+		t.Errorf("Expected the sidecar as a dependency, got %v", memory.DependsOn)
+	}
+	if search := file.Services["mcp-search"]; search == nil || len(search.Profiles) != 1 || search.Profiles[0] != "stopped" {
+		t.Errorf("Expected the stopped instance in the stopped profile, got %+v", search)
+	}
+	if file.Networks["mcp-network"].Name != "mcp-network" {
+		t.Errorf("Expected the network to keep its name, got %v", file.Networks)
+	}
+}
@@ -0,0 +1,78 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrCreateQueueFull is returned when too many create operations are already running or queued
+var ErrCreateQueueFull = errors.New("too many concurrent create operations")
+
+// createGate caps concurrent container creates and queues a bounded number of waiters
+type createGate struct {
+	slots     chan struct{}
+	waiting   int32
+	queueSize int32
+	timeout   time.Duration
+}
+
+// newCreateGate returns a gate allowing maxConcurrent creates; zero or less disables the cap
+func newCreateGate(maxConcurrent, queueSize int, timeout time.Duration) *createGate {
+	if maxConcurrent <= 0 {
+		return nil
+	}
+	return &createGate{
+		slots:     make(chan struct{}, maxConcurrent),
+		queueSize: int32(queueSize),
+		timeout:   timeout,
+	}
+}
+
+// acquire waits for a free create slot, failing fast when the queue is full
+func (g *createGate) acquire(ctx context.Context) error {
+	if g == nil {
+		return nil
+	}
+
+	select {
+	case g.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if atomic.AddInt32(&g.waiting, 1) > g.queueSize {
+		atomic.AddInt32(&g.waiting, -1)
+		return ErrCreateQueueFull
+	}
+	defer atomic.AddInt32(&g.waiting, -1)
+
+	timer := time.NewTimer(g.timeout)
+	defer timer.Stop()
+
+	select {
+	case g.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return ErrCreateQueueFull
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a slot taken by acquire
+func (g *createGate) release() {
+	if g == nil {
+		return
+	}
+	<-g.slots
+}
+
+// CreateRetryAfter suggests how long clients should back off after ErrCreateQueueFull
+func (m *Manager) CreateRetryAfter() time.Duration {
+	if m.createGate == nil || m.createGate.timeout <= 0 {
+		return time.Second
+	}
+	return m.createGate.timeout
+}
@@ -0,0 +1,75 @@
+package container
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestCreateGateRejectsWhenQueueFull(t *testing.T) {
+	gate := newCreateGate(1, 0, 50*time.Millisecond)
+
+	if err := gate.acquire(context.Background()); err != nil {
+		t.Fatalf("Expected first acquire to succeed, got %v", err)
+	}
+
+	if err := gate.acquire(context.Background()); err != ErrCreateQueueFull {
+		t.Errorf("Expected ErrCreateQueueFull with no queue capacity, got %v", err)
+	}
+
+	gate.release()
+	if err := gate.acquire(context.Background()); err != nil {
+		t.Errorf("Expected acquire after release to succeed, got %v", err)
+	}
+}
+
+func TestConcurrencyCap(t *testing.T) {
+	if err := validateRouteConfig(&models.RouteConfig{Limits: &models.RouteLimits{MaxConcurrentRequests: -1}}); err == nil {
+		t.Error("Expected a negative concurrency cap to be rejected")
+	}
+
+	defaults := config.TraefikRouteLimitsConfig{MaxConcurrentRequests: 20}
+	streaming := resolveRouteLimits(defaults, &models.RouteConfig{Transport: models.TransportSSE})
+	if streaming == nil || streaming.MaxConcurrentRequests != 20 {
+		t.Errorf("Expected the default cap on a streaming route, got %+v", streaming)
+	}
+	route := &models.RouteConfig{Limits: &models.RouteLimits{MaxConcurrentRequests: 4}}
+	limits := resolveRouteLimits(defaults, route)
+	if limits.MaxConcurrentRequests != 4 {
+		t.Errorf("Expected the route's cap over the default, got %d", limits.MaxConcurrentRequests)
+	}
+
+	middlewares, _ := buildRouteMiddlewares("svc-abc", route, nil, limits, "http://localhost:8000")
+	if inFlight := middlewares[inFlightMiddleware].InFlightReq; inFlight == nil || inFlight.Amount != 4 {
+		t.Errorf("Expected an in-flight limit of 4, got %+v", inFlight)
+	}
+	if e := middlewares[limitErrorsMiddleware].Errors; e == nil || !slices.Contains(e.Status, "429") {
+		t.Errorf("Expected an error page for 429, got %+v", e)
+	}
+
+	cfg := &config.Config{
+		Container: config.ContainerConfig{NamePrefix: "test-", MaxContainers: 10},
+		State:     config.StateConfig{Dir: t.TempDir()},
+	}
+	manager := newTestManager(cfg)
+	manager.containers["search"] = &models.Container{ServiceName: "search", Slug: "search-1234", Route: route}
+	manager.containers["other"] = &models.Container{ServiceName: "other", Slug: "other-1234"}
+
+	message, retryAfter := manager.ProxyLimitExceeded(context.Background(), "search-1234", http.StatusTooManyRequests)
+	if retryAfter < time.Second || !strings.Contains(message, "4 concurrent requests") {
+		t.Errorf("Expected a retry hint and the cap in the message, got %v %q", retryAfter, message)
+	}
+	status, err := manager.ConcurrencyStatus("search")
+	if err != nil || status == nil || status.Rejected != 1 || !status.Saturated {
+		t.Errorf("Expected one rejection marking the instance saturated, got %+v %v", status, err)
+	}
+	if saturation := manager.ConcurrencySaturation(); len(saturation) != 1 || saturation[0].ServiceName != "search" {
+		t.Errorf("Expected only the capped instance, got %+v", saturation)
+	}
+}
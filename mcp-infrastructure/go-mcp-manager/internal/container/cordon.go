@@ -0,0 +1,297 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// Cordon state is persisted so maintenance mode and queued events survive a manager restart
+const (
+	cordonBucket      = "cordon"
+	cordonStatusKey   = "status"
+	cordonQueueBucket = "cordon_queue"
+)
+
+// ErrCordoned is returned when a create is rejected because the host is cordoned
+var ErrCordoned = errors.New("host is cordoned for maintenance, not accepting new containers")
+
+// CordonStatus describes the maintenance state of this host
+type CordonStatus struct {
+	Cordoned   bool      `json:"cordoned"`
+	Reason     string    `json:"reason,omitempty"`
+	CordonedAt time.Time `json:"cordoned_at,omitempty"`
+	// Queued counts create events received while cordoned, replayed on uncordon
+	Queued int `json:"queued"`
+	// Drained lists the containers stopped by a drain, started again on uncordon
+	Drained   []string  `json:"drained,omitempty"`
+	DrainedAt time.Time `json:"drained_at,omitempty"`
+	// Replayed lists the instances whose queued create events were replayed by the last uncordon
+	Replayed []string `json:"replayed,omitempty"`
+}
+
+// cordonState tracks whether this host accepts new instances
+type cordonState struct {
+	mutex  sync.RWMutex
+	status CordonStatus
+}
+
+// queuedCreate is an instance created event held back while the host is cordoned
+type queuedCreate struct {
+	InstanceID string                 `json:"instance_id"`
+	Name       string                 `json:"name"`
+	JSONSpec   map[string]interface{} `json:"json_spec"`
+	ReceivedAt time.Time              `json:"received_at"`
+}
+
+// loadCordonStatus restores the cordon state persisted before a restart
+func (m *Manager) loadCordonStatus(ctx context.Context) {
+	var status CordonStatus
+	found, err := m.store.Get(cordonBucket, cordonStatusKey, &status)
+	if err != nil {
+		m.logger.WarnContext(ctx, "Failed to load cordon state", slog.String("error", err.Error()))
+		return
+	}
+	if !found {
+		return
+	}
+
+	m.cordon.mutex.Lock()
+	m.cordon.status = status
+	m.cordon.mutex.Unlock()
+
+	if status.Cordoned {
+		m.logger.WarnContext(ctx, "Host is cordoned, new instances are queued until uncordon",
+			slog.String("reason", status.Reason))
+	}
+}
+
+// saveCordonStatusUnsafe persists the cordon state (caller must hold the cordon lock)
+func (m *Manager) saveCordonStatusUnsafe() error {
+	if err := m.store.Put(cordonBucket, cordonStatusKey, m.cordon.status); err != nil {
+		return fmt.Errorf("failed to persist cordon state: %w", err)
+	}
+	return nil
+}
+
+// Cordon stops the host from accepting new instances. API creates are rejected with
+// ErrCordoned while create events are queued and replayed on Uncordon.
+func (m *Manager) Cordon(ctx context.Context, reason string) (CordonStatus, error) {
+	m.cordon.mutex.Lock()
+	defer m.cordon.mutex.Unlock()
+
+	if !m.cordon.status.Cordoned {
+		m.cordon.status = CordonStatus{
+			Cordoned:   true,
+			Reason:     reason,
+			CordonedAt: time.Now(),
+		}
+		if err := m.saveCordonStatusUnsafe(); err != nil {
+			m.cordon.status = CordonStatus{}
+			return CordonStatus{}, err
+		}
+
+		m.logger.WarnContext(ctx, "Host cordoned for maintenance", slog.String("reason", reason))
+	}
+
+	return m.cordonStatusUnsafe(), nil
+}
+
+// Drain cordons the host and gracefully stops every running container, keeping their
+// slugs and routes so Uncordon can start them again.
+func (m *Manager) Drain(ctx context.Context, reason string) (CordonStatus, error) {
+	if _, err := m.Cordon(ctx, reason); err != nil {
+		return CordonStatus{}, err
+	}
+
+	drained := make([]string, 0)
+	for _, container := range m.ListContainers() {
+		if container.StoppedAt != nil || container.Status == models.StatusStopped {
+			continue
+		}
+
+		if _, err := m.StopContainer(ctx, container.ServiceName); err != nil {
+			m.logger.ErrorContext(ctx, "Failed to stop container during drain",
+				slog.String("service", container.ServiceName),
+				slog.String("error", err.Error()))
+			continue
+		}
+		drained = append(drained, container.ServiceName)
+	}
+	sort.Strings(drained)
+
+	m.cordon.mutex.Lock()
+	defer m.cordon.mutex.Unlock()
+
+	m.cordon.status.Drained = mergeServiceNames(m.cordon.status.Drained, drained)
+	m.cordon.status.DrainedAt = time.Now()
+	if err := m.saveCordonStatusUnsafe(); err != nil {
+		return m.cordonStatusUnsafe(), err
+	}
+
+	m.logger.InfoContext(ctx, "Host drained",
+		slog.Int("stopped", len(drained)))
+
+	return m.cordonStatusUnsafe(), nil
+}
+
+// Uncordon accepts new instances again, starts drained containers and replays queued create events
+func (m *Manager) Uncordon(ctx context.Context) (CordonStatus, error) {
+	m.cordon.mutex.Lock()
+	if !m.cordon.status.Cordoned {
+		status := m.cordonStatusUnsafe()
+		m.cordon.mutex.Unlock()
+		return status, nil
+	}
+	drained := m.cordon.status.Drained
+	previous := m.cordon.status
+	m.cordon.status = CordonStatus{}
+	if err := m.saveCordonStatusUnsafe(); err != nil {
+		m.cordon.status = previous
+		m.cordon.mutex.Unlock()
+		return CordonStatus{}, err
+	}
+	m.cordon.mutex.Unlock()
+
+	m.logger.InfoContext(ctx, "Host uncordoned",
+		slog.Int("drained", len(drained)))
+
+	for _, serviceName := range drained {
+		if _, err := m.StartContainer(ctx, serviceName); err != nil {
+			m.logger.ErrorContext(ctx, "Failed to start drained container",
+				slog.String("service", serviceName),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	replayed := make([]string, 0)
+	for _, event := range m.queuedCreates(ctx) {
+		if err := m.store.Delete(cordonQueueBucket, event.InstanceID); err != nil {
+			m.logger.WarnContext(ctx, "Failed to dequeue create event",
+				slog.String("instance_id", event.InstanceID),
+				slog.String("error", err.Error()))
+		}
+
+		m.logger.InfoContext(ctx, "Replaying create event queued while cordoned",
+			slog.String("instance_id", event.InstanceID),
+			slog.String("name", event.Name),
+			slog.Time("received_at", event.ReceivedAt))
+
+		if err := m.HandleMCPInstanceCreated(ctx, event.InstanceID, event.Name, event.JSONSpec); err != nil {
+			m.logger.ErrorContext(ctx, "Failed to replay queued create event",
+				slog.String("instance_id", event.InstanceID),
+				slog.String("error", err.Error()))
+			continue
+		}
+		replayed = append(replayed, event.InstanceID)
+	}
+
+	m.cordon.mutex.Lock()
+	defer m.cordon.mutex.Unlock()
+	m.cordon.status.Replayed = replayed
+	return m.cordonStatusUnsafe(), nil
+}
+
+// GetCordonStatus returns the maintenance state of this host
+func (m *Manager) GetCordonStatus() CordonStatus {
+	m.cordon.mutex.RLock()
+	defer m.cordon.mutex.RUnlock()
+	return m.cordonStatusUnsafe()
+}
+
+// cordonStatusUnsafe returns a copy of the status with the queue length filled in (caller must hold the cordon lock)
+func (m *Manager) cordonStatusUnsafe() CordonStatus {
+	status := m.cordon.status
+	status.Drained = append([]string(nil), status.Drained...)
+	if keys, err := m.store.Keys(cordonQueueBucket); err == nil {
+		status.Queued = len(keys)
+	}
+	return status
+}
+
+// IsCordoned reports whether the host is in maintenance mode
+func (m *Manager) IsCordoned() bool {
+	m.cordon.mutex.RLock()
+	defer m.cordon.mutex.RUnlock()
+	return m.cordon.status.Cordoned
+}
+
+// queueCreateEvent holds back an instance created event until the host is uncordoned
+func (m *Manager) queueCreateEvent(ctx context.Context, instanceID, name string, jsonSpec map[string]interface{}) error {
+	event := queuedCreate{
+		InstanceID: instanceID,
+		Name:       name,
+		JSONSpec:   jsonSpec,
+		ReceivedAt: time.Now(),
+	}
+	if err := m.store.Put(cordonQueueBucket, instanceID, event); err != nil {
+		return fmt.Errorf("failed to queue create event for %s: %w", instanceID, err)
+	}
+
+	m.logger.InfoContext(ctx, "Host cordoned, queued create event",
+		slog.String("instance_id", instanceID),
+		slog.String("name", name))
+
+	if err := m.eventPublisher.PublishWarning(ctx, instanceID, name, "host_cordoned",
+		"host is in maintenance, the instance will be created when it is uncordoned"); err != nil {
+		m.logger.WarnContext(ctx, "Failed to publish cordon warning",
+			slog.String("instance_id", instanceID),
+			slog.String("error", err.Error()))
+	}
+	return nil
+}
+
+// dequeueCreateEvent drops a queued create event, e.g. when the instance is deleted before it was created
+func (m *Manager) dequeueCreateEvent(instanceID string) bool {
+	if !m.store.Has(cordonQueueBucket, instanceID) {
+		return false
+	}
+	return m.store.Delete(cordonQueueBucket, instanceID) == nil
+}
+
+// queuedCreates returns the queued create events in the order they were received
+func (m *Manager) queuedCreates(ctx context.Context) []queuedCreate {
+	records, err := m.store.List(cordonQueueBucket)
+	if err != nil {
+		m.logger.ErrorContext(ctx, "Failed to read queued create events", slog.String("error", err.Error()))
+		return nil
+	}
+
+	events := make([]queuedCreate, 0, len(records))
+	for instanceID, raw := range records {
+		var event queuedCreate
+		if err := json.Unmarshal(raw, &event); err != nil {
+			m.logger.WarnContext(ctx, "Skipping unreadable queued create event",
+				slog.String("instance_id", instanceID),
+				slog.String("error", err.Error()))
+			continue
+		}
+		events = append(events, event)
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].ReceivedAt.Before(events[j].ReceivedAt)
+	})
+	return events
+}
+
+// mergeServiceNames returns the sorted union of two service name lists
+func mergeServiceNames(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	merged := make([]string, 0, len(a)+len(b))
+	for _, name := range append(append([]string{}, a...), b...) {
+		if !seen[name] {
+			seen[name] = true
+			merged = append(merged, name)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
@@ -0,0 +1,55 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestCordonQueuesCreates(t *testing.T) {
+	cfg := &config.Config{
+		Container: config.ContainerConfig{NamePrefix: "test-", MaxContainers: 10},
+		Redis:     config.RedisConfig{URL: "redis://localhost:6379"},
+		State:     config.StateConfig{Dir: t.TempDir()},
+	}
+	manager := newTestManager(cfg)
+	ctx := context.Background()
+
+	if _, err := manager.Cordon(ctx, "kernel upgrade"); err != nil {
+		t.Fatalf("Expected cordon to succeed, got %v", err)
+	}
+
+	_, err := manager.CreateContainer(ctx, models.CreateContainerRequest{ServiceName: "new", Image: "nginx:latest", Port: 80})
+	if !errors.Is(err, ErrCordoned) {
+		t.Errorf("Expected ErrCordoned, got %v", err)
+	}
+
+	spec := map[string]interface{}{"image": "nginx:latest", "port": float64(80)}
+	if err := manager.HandleMCPInstanceCreated(ctx, "inst-1", "first", spec); err != nil {
+		t.Errorf("Expected create event to be queued, got %v", err)
+	}
+	if err := manager.HandleMCPInstanceCreated(ctx, "inst-2", "second", spec); err != nil {
+		t.Errorf("Expected create event to be queued, got %v", err)
+	}
+	if status := manager.GetCordonStatus(); status.Queued != 2 {
+		t.Errorf("Expected 2 queued events, got %d", status.Queued)
+	}
+
+	if err := manager.HandleMCPInstanceDeleted(ctx, "inst-2"); err != nil {
+		t.Errorf("Expected delete of a queued instance to succeed, got %v", err)
+	}
+	queued := manager.queuedCreates(ctx)
+	if len(queued) != 1 || queued[0].InstanceID != "inst-1" || queued[0].JSONSpec["image"] != "nginx:latest" {
+		t.Errorf("Expected only inst-1 to remain queued, got %+v", queued)
+	}
+
+	// Maintenance mode survives a manager restart
+	restarted := newTestManager(cfg)
+	restarted.loadCordonStatus(ctx)
+	if !restarted.IsCordoned() || restarted.GetCordonStatus().Reason != "kernel upgrade" {
+		t.Errorf("Expected cordon to be restored, got %+v", restarted.GetCordonStatus())
+	}
+}
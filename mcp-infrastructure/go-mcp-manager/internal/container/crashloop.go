@@ -0,0 +1,267 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/webhooks"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// crashLoopBucket records containers stopped for exiting too often, keyed by service name
+const crashLoopBucket = "crash_loop"
+
+// processExit describes an exit of a container's process, as far as podman still reports it
+type processExit struct {
+	code      *int
+	oomKilled bool
+	// logs are the last lines of output
+	logs string
+}
+
+// describe appends how the process last ended to message
+func (e processExit) describe(message string) string {
+	switch {
+	case e.oomKilled && e.code != nil:
+		return fmt.Sprintf("%s, last killed for exceeding its memory limit (OOM, exit code %d)", message, *e.code)
+	case e.code != nil:
+		return fmt.Sprintf("%s, last with code %d", message, *e.code)
+	default:
+		return message
+	}
+}
+
+// crashLoopState holds when each container's process recently exited. Guarded by m.mutex.
+type crashLoopState struct {
+	exits map[string][]time.Time
+}
+
+// handleExitUnsafe counts an unexpected exit of a container's process and, unless the container
+// exited too often within the window, schedules a restart after a backoff. Caller must hold m.mutex.
+func (m *Manager) handleExitUnsafe(container *models.Container) {
+	cfg := m.config.HealthMonitor
+	if cfg.CrashLoopThreshold <= 0 || container.StoppedAt != nil {
+		return
+	}
+	if m.crashLoops.exits == nil {
+		m.crashLoops.exits = make(map[string][]time.Time)
+	}
+
+	now := time.Now()
+	var exits []time.Time
+	for _, exitedAt := range m.crashLoops.exits[container.ServiceName] {
+		if now.Sub(exitedAt) < cfg.CrashLoopWindow {
+			exits = append(exits, exitedAt)
+		}
+	}
+	exits = append(exits, now)
+	m.crashLoops.exits[container.ServiceName] = exits
+
+	go m.recoverExited(container, len(exits))
+}
+
+// restartBackoff returns the delay before restarting a container after its given exit within the window
+func restartBackoff(base, ceiling time.Duration, exits int) time.Duration {
+	backoff := base
+	for i := 1; i < exits && backoff < ceiling; i++ {
+		backoff *= 2
+	}
+	return min(backoff, ceiling)
+}
+
+// recoverExited records why a container exited and restarts it after a backoff, or stops
+// restarting it once it exited CrashLoopThreshold times within the window
+func (m *Manager) recoverExited(container *models.Container, exits int) {
+	ctx := m.healthCtx
+	cfg := m.config.HealthMonitor
+	exit := m.exitDetails(ctx, container.ServiceName, cfg.CrashLoopLogLines)
+
+	if exits >= cfg.CrashLoopThreshold {
+		m.enterCrashLoop(ctx, container, exits, exit)
+		return
+	}
+
+	backoff := restartBackoff(cfg.RestartBackoff, cfg.RestartMaxBackoff, exits)
+	message := exit.describe(fmt.Sprintf("Exited %d of %d times within %s", exits, cfg.CrashLoopThreshold, cfg.CrashLoopWindow))
+	if !m.supervised() {
+		message += fmt.Sprintf("; restarting in %s", backoff)
+	}
+	m.recordInstanceEvent(container.Environment["MCP_INSTANCE_ID"], InstanceEvent{
+		Type:        InstanceEventWarning,
+		Reason:      "BackOff",
+		Message:     message,
+		ServiceName: container.ServiceName,
+		ContainerID: container.ID,
+		ExitCode:    exit.code,
+	})
+	if m.supervised() {
+		// The unit restarts the container itself
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(backoff):
+	}
+
+	m.mutex.Lock()
+	// Leave containers that were deleted, stopped by a user or came back in the meantime
+	if !m.tracksUnsafe(container) || container.StoppedAt != nil {
+		m.mutex.Unlock()
+		return
+	}
+	if container.Status != models.StatusStopped && container.Status != models.StatusError {
+		m.mutex.Unlock()
+		return
+	}
+	if m.IsPreempted() {
+		m.recordContainerEvent(container, InstanceEventWarning, "RestartFailed", ErrHostPreempted.Error())
+		m.mutex.Unlock()
+		return
+	}
+	// Marked starting so nothing else restarts it while the lock is released
+	container.Status = models.StatusStarting
+	container.UpdatedAt = time.Now()
+	snapshot := *container
+	m.mutex.Unlock()
+
+	// Starting the process and waiting for it can take until the startup timeout, so it runs
+	// without the lock, on a copy
+	m.logger.InfoContext(ctx, "Restarting container",
+		slog.String("container", snapshot.Name),
+		slog.String("service", snapshot.ServiceName))
+	err := m.startDependencies(ctx, &snapshot)
+	if err == nil {
+		var output []byte
+		if output, err = m.startProcess(ctx, &snapshot); err != nil {
+			err = fmt.Errorf("failed to start container: %w, output: %s", err, string(output))
+		}
+		m.inspect.invalidate(snapshot.ID)
+	}
+	if err == nil {
+		if err = m.waitForContainer(ctx, snapshot.ID); err != nil {
+			err = fmt.Errorf("container failed to start properly: %w", err)
+		}
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// A container deleted or replaced in the meantime was removed along with what was started
+	if !m.tracksUnsafe(container) {
+		return
+	}
+	if container.StoppedAt != nil {
+		// A user stopped it while it was starting, so it is stopped again
+		if output, stopErr := m.stopProcess(ctx, container); stopErr != nil {
+			m.logger.WarnContext(ctx, "Failed to stop container stopped while restarting",
+				slog.String("service", container.ServiceName),
+				slog.String("error", stopErr.Error()),
+				slog.String("output", string(output)))
+		}
+		m.inspect.invalidate(container.ID)
+		return
+	}
+	if err != nil {
+		container.Status = models.StatusError
+		m.logger.WarnContext(ctx, "Failed to restart exited container",
+			slog.String("service", container.ServiceName),
+			slog.String("error", err.Error()))
+		m.recordContainerEvent(container, InstanceEventWarning, "RestartFailed", err.Error())
+		return
+	}
+	m.completeStartUnsafe(ctx, container)
+	m.recordContainerEvent(container, InstanceEventNormal, "Restarted", "Restarted the container after it exited")
+}
+
+// tracksUnsafe reports whether container is still the one tracked under its service name, so it
+// was neither deleted nor replaced. Caller must hold m.mutex.
+func (m *Manager) tracksUnsafe(container *models.Container) bool {
+	tracked, exists := m.containers[container.ServiceName]
+	return exists && tracked == container
+}
+
+// exitDetails returns how a container's process last ended and its last lines of output
+func (m *Manager) exitDetails(ctx context.Context, serviceName string, lines int) processExit {
+	var exit processExit
+	if inspect, err := m.InspectContainer(ctx, serviceName); err == nil {
+		exit.code = &inspect.ExitCode
+		exit.oomKilled = inspect.OOMKilled
+	}
+	if lines > 0 {
+		exit.logs, _ = m.GetContainerLogs(ctx, serviceName, lines)
+	}
+	return exit
+}
+
+// enterCrashLoop stops restarting a container that keeps exiting. It is kept as StatusCrashLoop,
+// like a stopped container, until it is started again.
+func (m *Manager) enterCrashLoop(ctx context.Context, container *models.Container, exits int, exit processExit) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.tracksUnsafe(container) || container.StoppedAt != nil {
+		return
+	}
+
+	// Record the crash loop first so a manager restart in between does not restart the container
+	stoppedAt := time.Now()
+	for _, bucket := range []string{stoppedBucket, crashLoopBucket} {
+		if err := m.store.Put(bucket, container.ServiceName, stoppedAt); err != nil {
+			m.logger.WarnContext(ctx, "Failed to record crash loop",
+				slog.String("service", container.ServiceName),
+				slog.String("error", err.Error()))
+		}
+	}
+	// Keeps a systemd unit from restarting the container again
+	if output, err := m.stopProcess(ctx, container); err != nil {
+		m.logger.WarnContext(ctx, "Failed to stop container in crash loop",
+			slog.String("service", container.ServiceName),
+			slog.String("error", err.Error()),
+			slog.String("output", string(output)))
+	}
+	m.inspect.invalidate(container.ID)
+	m.stopDependencies(ctx, container)
+	if container.Slug != "" {
+		if err := m.traefikManager.RemoveMCPService(ctx, container.Slug); err != nil {
+			m.logger.WarnContext(ctx, "Failed to disable Traefik route for container in crash loop",
+				slog.String("slug", container.Slug),
+				slog.String("service", container.ServiceName),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	container.Status = models.StatusCrashLoop
+	container.StoppedAt = &stoppedAt
+	container.UpdatedAt = stoppedAt
+	delete(m.containerHealth, container.Name)
+
+	message := exit.describe(fmt.Sprintf("Exited %d times within %s", exits, m.config.HealthMonitor.CrashLoopWindow)) +
+		"; no longer restarted until it is started again"
+
+	instanceID := container.Environment["MCP_INSTANCE_ID"]
+	m.recordInstanceEvent(instanceID, InstanceEvent{
+		Type:        InstanceEventWarning,
+		Reason:      "CrashLoop",
+		Message:     message,
+		ServiceName: container.ServiceName,
+		ContainerID: container.ID,
+		ExitCode:    exit.code,
+		Logs:        exit.logs,
+	})
+	if instanceID != "" {
+		if err := m.eventPublisher.PublishCrashLoop(ctx, instanceID, container.ServiceName, message); err != nil {
+			m.logger.WarnContext(ctx, "Failed to publish crash loop",
+				slog.String("instance_id", instanceID),
+				slog.String("error", err.Error()))
+		}
+	}
+	m.notifyWebhook(webhooks.EventContainerCrashLoop, container, message)
+
+	m.logger.ErrorContext(ctx, "Container is in a crash loop, stopped restarting it",
+		slog.String("service", container.ServiceName),
+		slog.Int("exits", exits))
+}
@@ -0,0 +1,124 @@
+package container
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestCrashLoop(t *testing.T) {
+	if backoff := restartBackoff(10*time.Second, 5*time.Minute, 1); backoff != 10*time.Second {
+		t.Errorf("Expected the first restart after 10s, got %s", backoff)
+	}
+	if backoff := restartBackoff(10*time.Second, 5*time.Minute, 3); backoff != 40*time.Second {
+		t.Errorf("Expected the third restart after 40s, got %s", backoff)
+	}
+	if backoff := restartBackoff(10*time.Second, 5*time.Minute, 10); backoff != 5*time.Minute {
+		t.Errorf("Expected the backoff to be capped at 5m, got %s", backoff)
+	}
+
+	cfg := &config.Config{State: config.StateConfig{Dir: t.TempDir()}}
+	cfg.HealthMonitor.CrashLoopWindow = 10 * time.Minute
+	manager := newTestManager(cfg)
+	container := &models.Container{
+		ID:          "abc123",
+		Name:        "mcp-github",
+		ServiceName: "github",
+		Status:      models.StatusStopped,
+		Environment: map[string]string{"MCP_INSTANCE_ID": "inst-1"},
+	}
+	manager.containers["github"] = container
+
+	// Without a threshold exits are not counted
+	manager.handleExitUnsafe(container)
+	if len(manager.crashLoops.exits["github"]) != 0 {
+		t.Errorf("Expected no exits counted without a threshold, got %d", len(manager.crashLoops.exits["github"]))
+	}
+
+	// A cancelled context keeps podman and Redis from being reached
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	exitCode := 1
+	manager.enterCrashLoop(ctx, container, 5, processExit{code: &exitCode, logs: "panic: missing token\n"})
+
+	if container.Status != models.StatusCrashLoop || container.StoppedAt == nil {
+		t.Errorf("Expected the container to be stopped in crash_loop, got %s", container.Status)
+	}
+	if !manager.store.Has(crashLoopBucket, "github") || !manager.store.Has(stoppedBucket, "github") {
+		t.Errorf("Expected the crash loop to be recorded")
+	}
+	response, err := manager.GetInstanceEvents("inst-1")
+	if err != nil {
+		t.Fatalf("Expected events, got %v", err)
+	}
+	last := response.Events[len(response.Events)-1]
+	if last.Reason != "CrashLoop" || last.ExitCode == nil || *last.ExitCode != 1 || last.Logs != "panic: missing token\n" {
+		t.Errorf("Expected a CrashLoop event with the exit code and logs, got %+v", last)
+	}
+}
+
+func TestCrashRestartReleasesLock(t *testing.T) {
+	// Fake podman marks when the container is started and reports it running
+	bin := t.TempDir()
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	started := filepath.Join(bin, "started")
+	script := "#!/bin/sh\ncase \"$1\" in\nstart) touch " + started + " ;;\ninspect) echo running ;;\nesac\n"
+	if err := os.WriteFile(filepath.Join(bin, "podman"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{State: config.StateConfig{Dir: t.TempDir()}}
+	cfg.Container.StartupTimeout = 10 * time.Second
+	cfg.HealthMonitor.CrashLoopThreshold = 5
+	cfg.HealthMonitor.CrashLoopWindow = 10 * time.Minute
+	cfg.HealthMonitor.RestartBackoff = time.Millisecond
+	cfg.HealthMonitor.RestartMaxBackoff = time.Millisecond
+	manager := newTestManager(cfg)
+	container := &models.Container{
+		ID:          "abc123",
+		Name:        "mcp-github",
+		ServiceName: "github",
+		Status:      models.StatusStopped,
+	}
+	manager.containers["github"] = container
+
+	manager.mutex.Lock()
+	manager.handleExitUnsafe(container)
+	manager.mutex.Unlock()
+
+	// While the restart waits for the container to run, the manager stays usable
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(started); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the exited container to be started")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !manager.mutex.TryLock() {
+		t.Fatal("Expected the lock to be free while the restart waits")
+	}
+	if container.Status != models.StatusStarting {
+		t.Errorf("Expected the container to be marked starting, got %s", container.Status)
+	}
+	manager.mutex.Unlock()
+
+	for deadline = time.Now().Add(5 * time.Second); ; time.Sleep(50 * time.Millisecond) {
+		manager.mutex.RLock()
+		status := container.Status
+		manager.mutex.RUnlock()
+		if status == models.StatusRunning {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the restarted container to run, got %s", status)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// dependsOnLabel stores a container's dependencies on the podman container
+	dependsOnLabel = "mcp.depends_on"
+	// dependencyOfLabel marks a sidecar with the service that owns it, keeping it out of discovery
+	dependencyOfLabel = "mcp.dependency_of"
+)
+
+var (
+	// dependencyNamePattern keeps dependency names usable in container names and env var prefixes
+	dependencyNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+	// envPrefixInvalidChars matches characters replaced by '_' in dependency env var prefixes
+	envPrefixInvalidChars = regexp.MustCompile(`[^A-Z0-9]+`)
+)
+
+// parseDependsOnSpec reads the optional depends_on list from json_spec. Entries are either the
+// service name of an existing instance or a sidecar object with name, image and port.
+func parseDependsOnSpec(jsonSpec map[string]interface{}) ([]models.Dependency, error) {
+	raw, exists := jsonSpec["depends_on"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("depends_on must be an array")
+	}
+
+	dependencies := make([]models.Dependency, 0, len(list))
+	for _, item := range list {
+		switch entry := item.(type) {
+		case string:
+			dependencies = append(dependencies, models.Dependency{Name: entry, Service: entry})
+		case map[string]interface{}:
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return nil, fmt.Errorf("depends_on entry is not valid JSON: %w", err)
+			}
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.DisallowUnknownFields()
+
+			var dependency models.Dependency
+			if err := decoder.Decode(&dependency); err != nil {
+				return nil, fmt.Errorf("invalid depends_on entry: %w", err)
+			}
+			dependencies = append(dependencies, dependency)
+		default:
+			return nil, fmt.Errorf("depends_on entries must be service names or sidecar objects")
+		}
+	}
+
+	if err := validateDependencies(dependencies); err != nil {
+		return nil, err
+	}
+	return dependencies, nil
+}
+
+// validateDependencies checks dependency names and that each is either a reference or a sidecar
+func validateDependencies(dependencies []models.Dependency) error {
+	seen := make(map[string]bool)
+	for _, dependency := range dependencies {
+		if !dependencyNamePattern.MatchString(dependency.Name) {
+			return fmt.Errorf("dependency name %q must be lowercase letters, digits and '-'", dependency.Name)
+		}
+		if seen[dependency.Name] {
+			return fmt.Errorf("dependency %s is listed more than once", dependency.Name)
+		}
+		seen[dependency.Name] = true
+
+		if dependency.Image == "" {
+			if dependency.Service == "" {
+				return fmt.Errorf("dependency %s needs either a service or an image", dependency.Name)
+			}
+			if dependency.Port != 0 || len(dependency.Command) > 0 || len(dependency.Environment) > 0 {
+				return fmt.Errorf("dependency %s refers to service %s and cannot set port, command or environment", dependency.Name, dependency.Service)
+			}
+			continue
+		}
+		if dependency.Service != "" {
+			return fmt.Errorf("dependency %s cannot set both service and image", dependency.Name)
+		}
+		if dependency.Port < 0 || dependency.Port > 65535 {
+			return fmt.Errorf("dependency %s port must be between 1 and 65535, or omitted", dependency.Name)
+		}
+	}
+	return nil
+}
+
+// isSidecar reports whether a dependency is provisioned with the container rather than referenced
+func isSidecar(dependency models.Dependency) bool {
+	return dependency.Image != ""
+}
+
+// sidecarContainerName returns the podman container running a container's sidecar dependency
+func (m *Manager) sidecarContainerName(container *models.Container, dependency models.Dependency) string {
+	return m.config.GetContainerName(container.ServiceName + "-" + dependency.Name)
+}
+
+// dependencyEnvPrefix converts a dependency name to its env var prefix, e.g. "vector-db" to "VECTOR_DB"
+func dependencyEnvPrefix(name string) string {
+	return strings.Trim(envPrefixInvalidChars.ReplaceAllString(strings.ToUpper(name), "_"), "_")
+}
+
+// containerNetworks returns the podman networks a container is attached to
+func (m *Manager) containerNetworks(container *models.Container) []string {
+	network := container.Network
+	if network == "" {
+		network = m.config.Traefik.Network
+	}
+	networks := []string{network}
+	if container.SharedNetwork && network != m.config.Traefik.Network {
+		networks = append(networks, m.config.Traefik.Network)
+	}
+	return networks
+}
+
+// sharesNetwork reports whether two containers can reach each other by name
+func (m *Manager) sharesNetwork(a, b *models.Container) bool {
+	for _, left := range m.containerNetworks(a) {
+		for _, right := range m.containerNetworks(b) {
+			if left == right {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// provisionDependenciesUnsafe starts a container's sidecars, checks its referenced instances, and adds
+// their addresses to its environment. It must run before podman run (caller must hold lock).
+func (m *Manager) provisionDependenciesUnsafe(ctx context.Context, container *models.Container) error {
+	return m.linkDependenciesUnsafe(ctx, container, true)
+}
+
+// linkDependenciesUnsafe is provisionDependenciesUnsafe, but leaves the sidecars already running
+// for the container alone unless startSidecars is set (caller must hold lock)
+func (m *Manager) linkDependenciesUnsafe(ctx context.Context, container *models.Container, startSidecars bool) error {
+	if len(container.DependsOn) == 0 {
+		return nil
+	}
+	// Sidecars this call did not start are not its to remove
+	cleanup := func() {
+		if startSidecars {
+			m.removeDependencies(ctx, container)
+		}
+	}
+	// Copy so the addresses are not written into the caller's request
+	container.Environment = maps.Clone(container.Environment)
+	if container.Environment == nil {
+		container.Environment = make(map[string]string)
+	}
+
+	for _, dependency := range container.DependsOn {
+		var host string
+		var port int
+
+		if isSidecar(dependency) {
+			name := m.sidecarContainerName(container, dependency)
+			if startSidecars {
+				var err error
+				if name, err = m.runSidecar(ctx, container, dependency); err != nil {
+					cleanup()
+					return err
+				}
+			}
+			host, port = name, dependency.Port
+		} else {
+			target, exists := m.containers[dependency.Service]
+			if !exists {
+				cleanup()
+				return fmt.Errorf("dependency %s: container %s not found", dependency.Name, dependency.Service)
+			}
+			if target.Status != models.StatusRunning || target.StoppedAt != nil {
+				cleanup()
+				return fmt.Errorf("dependency %s: container %s is %s", dependency.Name, dependency.Service, target.Status)
+			}
+			if !m.sharesNetwork(container, target) {
+				cleanup()
+				return fmt.Errorf("dependency %s: container %s is on a different network", dependency.Name, dependency.Service)
+			}
+			host, port = target.Name, target.Port
+		}
+
+		// Explicit environment wins over the injected addresses
+		prefix := dependencyEnvPrefix(dependency.Name)
+		injected := map[string]string{prefix + "_HOST": host}
+		if port > 0 {
+			injected[prefix+"_PORT"] = strconv.Itoa(port)
+			injected[prefix+"_URL"] = fmt.Sprintf("http://%s:%d", host, port)
+		}
+		for key, value := range injected {
+			if _, exists := container.Environment[key]; !exists {
+				container.Environment[key] = value
+			}
+		}
+	}
+
+	return nil
+}
+
+// runSidecar starts a sidecar dependency on the container's network and waits for it to run
+func (m *Manager) runSidecar(ctx context.Context, container *models.Container, dependency models.Dependency) (string, error) {
+	name := m.sidecarContainerName(container, dependency)
+
+	if err := m.ensureOwned(ctx, name); err != nil {
+		return "", err
+	}
+
+	// A sidecar left over from an earlier attempt is replaced so it matches the current spec
+	if err := podmanCommand(ctx, m.logger, "container", "exists", name).Run(); err == nil {
+		if output, err := podmanCommand(ctx, m.logger, "rm", "-f", name).CombinedOutput(); err != nil {
+			return "", fmt.Errorf("failed to replace sidecar %s: %w: %s", name, err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	args := []string{"run", "-d", "--name", name}
+	// Sidecars are private to the workspace, so they never join the shared network
+	args = append(args, m.podmanNetworkArgs(&models.Container{Network: container.Network})...)
+	args = append(args, "--label", fmt.Sprintf("%s=%s", dependencyOfLabel, container.ServiceName))
+	args = append(args, m.ownershipLabelArgs(container)...)
+	if container.WorkspaceID != "" {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", workspaceLabel, container.WorkspaceID))
+	}
+	for key, value := range dependency.Environment {
+		args = append(args, "-e", fmt.Sprintf("%s=%s", key, value))
+	}
+	// Sidecars such as databases need a writable root, so only the resource defaults apply
+	if limits, err := m.resolveResources(nil); err == nil {
+		args = append(args, podmanResourceArgs(limits)...)
+	}
+	args = append(args, dependency.Image)
+	args = append(args, dependency.Command...)
+
+	output, err := podmanCommand(ctx, m.logger, args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to start dependency %s: %w: %s", dependency.Name, err, strings.TrimSpace(string(output)))
+	}
+	if err := m.waitForContainer(ctx, strings.TrimSpace(string(output))); err != nil {
+		return "", fmt.Errorf("dependency %s failed to start: %w", dependency.Name, err)
+	}
+
+	m.logger.InfoContext(ctx, "Started sidecar dependency",
+		slog.String("service", container.ServiceName),
+		slog.String("dependency", dependency.Name),
+		slog.String("container", name))
+
+	return name, nil
+}
+
+// startDependencies restarts a container's stopped sidecars before the container itself
+func (m *Manager) startDependencies(ctx context.Context, container *models.Container) error {
+	for _, dependency := range container.DependsOn {
+		if !isSidecar(dependency) {
+			continue
+		}
+		name := m.sidecarContainerName(container, dependency)
+		if output, err := podmanCommand(ctx, m.logger, "start", name).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to start dependency %s: %w: %s", dependency.Name, err, strings.TrimSpace(string(output)))
+		}
+		if err := m.waitForContainer(ctx, name); err != nil {
+			return fmt.Errorf("dependency %s failed to start: %w", dependency.Name, err)
+		}
+	}
+	return nil
+}
+
+// stopDependencies stops a container's sidecars, keeping them for a later start
+func (m *Manager) stopDependencies(ctx context.Context, container *models.Container) {
+	for _, dependency := range container.DependsOn {
+		if !isSidecar(dependency) {
+			continue
+		}
+		name := m.sidecarContainerName(container, dependency)
+		if output, err := podmanCommand(ctx, m.logger, "stop", name).CombinedOutput(); err != nil {
+			m.logger.WarnContext(ctx, "Failed to stop sidecar dependency",
+				slog.String("container", name),
+				slog.String("error", err.Error()),
+				slog.String("output", string(output)))
+		}
+	}
+}
+
+// removeDependencies deletes a container's sidecars; referenced instances are left alone
+func (m *Manager) removeDependencies(ctx context.Context, container *models.Container) {
+	for _, dependency := range container.DependsOn {
+		if !isSidecar(dependency) {
+			continue
+		}
+		name := m.sidecarContainerName(container, dependency)
+		if err := podmanCommand(ctx, m.logger, "container", "exists", name).Run(); err != nil {
+			continue
+		}
+		if err := m.ensureOwned(ctx, name); err != nil {
+			continue
+		}
+		if output, err := podmanCommand(ctx, m.logger, "rm", "-f", name).CombinedOutput(); err != nil {
+			m.logger.WarnContext(ctx, "Failed to remove sidecar dependency",
+				slog.String("container", name),
+				slog.String("error", err.Error()),
+				slog.String("output", string(output)))
+		}
+	}
+}
+
+// discoverDependencies restores the dependencies persisted on a podman container
+func (m *Manager) discoverDependencies(ctx context.Context, containerID string) []models.Dependency {
+	var dependencies []models.Dependency
+	m.discoverJSONLabel(ctx, containerID, dependsOnLabel, &dependencies)
+	return dependencies
+}
+
+// isOwnedListing reports whether a podman ps entry is a sidecar or init run owned by another container
+func isOwnedListing(listing map[string]interface{}) bool {
+	labels, ok := listing["Labels"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, sidecar := labels[dependencyOfLabel]
+	_, initRun := labels[initOfLabel]
+	return sidecar || initRun
+}
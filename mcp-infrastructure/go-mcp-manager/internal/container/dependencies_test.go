@@ -0,0 +1,75 @@
+package container
+
+import (
+	"context"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestDependsOn(t *testing.T) {
+	dependencies, err := parseDependsOnSpec(map[string]interface{}{
+		"depends_on": []interface{}{
+			"shared-db",
+			map[string]interface{}{"name": "vector-db", "image": "qdrant/qdrant:v1.9.0", "port": float64(6333)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected depends_on to parse, got %v", err)
+	}
+	if len(dependencies) != 2 || dependencies[0].Service != "shared-db" || !isSidecar(dependencies[1]) {
+		t.Errorf("Expected a reference and a sidecar, got %+v", dependencies)
+	}
+
+	invalid := []interface{}{
+		[]interface{}{map[string]interface{}{"name": "db"}},
+		[]interface{}{map[string]interface{}{"name": "DB", "image": "postgres"}},
+		[]interface{}{"db", "db"},
+		[]interface{}{map[string]interface{}{"name": "db", "image": "postgres", "volume": "/data"}},
+	}
+	for _, spec := range invalid {
+		if _, err := parseDependsOnSpec(map[string]interface{}{"depends_on": spec}); err == nil {
+			t.Errorf("Expected depends_on %v to be rejected", spec)
+		}
+	}
+
+	if prefix := dependencyEnvPrefix("vector-db"); prefix != "VECTOR_DB" {
+		t.Errorf("Expected env prefix VECTOR_DB, got %s", prefix)
+	}
+
+	cfg := &config.Config{
+		Container: config.ContainerConfig{NamePrefix: "test-", MaxContainers: 10},
+		Traefik:   config.TraefikConfig{Network: "mcp-network"},
+		Network:   config.NetworkConfig{PerWorkspace: true, Prefix: "mcp-ws-"},
+	}
+	manager := newTestManager(cfg)
+
+	manager.containers["shared-db"] = &models.Container{
+		Name:        "test-shared-db",
+		ServiceName: "shared-db",
+		Status:      models.StatusRunning,
+		Port:        5432,
+		Network:     "mcp-ws-team-a",
+	}
+	container := &models.Container{
+		ServiceName: "app",
+		Network:     "mcp-ws-team-a",
+		Environment: map[string]string{"SHARED_DB_PORT": "6543"},
+		DependsOn:   dependencies[:1],
+	}
+	if err := manager.provisionDependenciesUnsafe(context.Background(), container); err != nil {
+		t.Fatalf("Expected referenced dependency to resolve, got %v", err)
+	}
+	if container.Environment["SHARED_DB_HOST"] != "test-shared-db" {
+		t.Errorf("Expected SHARED_DB_HOST test-shared-db, got %s", container.Environment["SHARED_DB_HOST"])
+	}
+	if container.Environment["SHARED_DB_PORT"] != "6543" {
+		t.Errorf("Expected explicit SHARED_DB_PORT to be kept, got %s", container.Environment["SHARED_DB_PORT"])
+	}
+
+	other := &models.Container{ServiceName: "other", Network: "mcp-ws-team-b", DependsOn: dependencies[:1]}
+	if err := manager.provisionDependenciesUnsafe(context.Background(), other); err == nil {
+		t.Error("Expected a dependency on another workspace's network to be rejected")
+	}
+}
@@ -0,0 +1,299 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// runtimeEnvironment are variables the engine sets in every container
+var runtimeEnvironment = []string{"container", "HOME", "HOSTNAME", "PATH", "TERM"}
+
+// Kinds of spec differences
+const (
+	// SpecDrift is a running container that no longer matches what the manager created, such as
+	// one edited or recreated by hand on the host
+	SpecDrift = "drift"
+	// SpecOutdated is a running image whose tag now points at another digest
+	SpecOutdated = "outdated"
+)
+
+// SpecDifference is one field in which a running container differs from its declared spec.
+// Environment values are masked.
+type SpecDifference struct {
+	// Field is image, image_digest, container_id, command, environment.NAME or resources.NAME
+	Field   string `json:"field"`
+	Kind    string `json:"kind"`
+	Desired string `json:"desired"`
+	Actual  string `json:"actual"`
+}
+
+// SpecDiff compares the container running an instance with the spec it was declared with
+type SpecDiff struct {
+	InstanceID  string           `json:"instance_id"`
+	ServiceName string           `json:"service_name"`
+	ContainerID string           `json:"container_id"`
+	InSync      bool             `json:"in_sync"`
+	Differences []SpecDifference `json:"differences"`
+	// ReconcileURL recreates the container from its declared spec, set while they differ
+	ReconcileURL string    `json:"reconcile_url,omitempty"`
+	CheckedAt    time.Time `json:"checked_at"`
+}
+
+// SpecReconcileResult is the outcome of recreating a container from its declared spec
+type SpecReconcileResult struct {
+	Container *models.Container `json:"container"`
+	// Reconciled are the differences the recreated container no longer has
+	Reconciled []SpecDifference `json:"reconciled"`
+	Restarted  bool             `json:"restarted"`
+}
+
+// specInspect is the part of podman inspect output a container's spec is compared with
+type specInspect struct {
+	ID          string `json:"Id"`
+	Image       string `json:"Image"`
+	ImageName   string `json:"ImageName"`
+	ImageDigest string `json:"ImageDigest"`
+	Config      struct {
+		Image string   `json:"Image"`
+		Env   []string `json:"Env"`
+		Cmd   []string `json:"Cmd"`
+	} `json:"Config"`
+	HostConfig struct {
+		Memory    int64 `json:"Memory"`
+		NanoCpus  int64 `json:"NanoCpus"`
+		CpuQuota  int64 `json:"CpuQuota"`
+		CpuPeriod int64 `json:"CpuPeriod"`
+		PidsLimit int64 `json:"PidsLimit"`
+	} `json:"HostConfig"`
+}
+
+// DiffContainer compares the container running a service with its declared spec: the image and
+// the digest its tag points at, environment, command and resource limits
+func (m *Manager) DiffContainer(ctx context.Context, serviceName string) (*SpecDiff, error) {
+	snapshot, exists := m.containerSnapshot(serviceName)
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+
+	// Looked up by name, so a container recreated by hand under it is found too
+	output, err := podmanCommand(ctx, m.logger, "inspect", "--type", "container", snapshot.Name).Output()
+	if err != nil {
+		return nil, fmt.Errorf("container %s has no container to compare: %w", serviceName, err)
+	}
+	var inspected []specInspect
+	if err := json.Unmarshal(output, &inspected); err != nil || len(inspected) == 0 {
+		return nil, fmt.Errorf("failed to parse container inspect output")
+	}
+	actual := inspected[0]
+
+	desiredDigest := imageRefDigest(snapshot.Image)
+	if desiredDigest == "" {
+		desiredDigest = m.imageField(ctx, snapshot.Image, "{{.Digest}}")
+	}
+	var imageEnv []string
+	_ = json.Unmarshal([]byte(m.imageField(ctx, actual.Image, "{{json .Config.Env}}")), &imageEnv)
+
+	differences := m.diffSpec(&snapshot, actual, imageEnv, desiredDigest)
+	return &SpecDiff{
+		InstanceID:  snapshot.Environment["MCP_INSTANCE_ID"],
+		ServiceName: serviceName,
+		ContainerID: actual.ID,
+		InSync:      len(differences) == 0,
+		Differences: differences,
+		CheckedAt:   time.Now(),
+	}, nil
+}
+
+// ReconcileContainer recreates a service's container from its declared spec when the running one
+// differs from it. The slug, sidecars, volumes and certificates are kept, like for an environment
+// update, and the previous container is recreated if the new one does not start.
+func (m *Manager) ReconcileContainer(ctx context.Context, serviceName string) (*SpecReconcileResult, error) {
+	diff, err := m.DiffContainer(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	container, exists := m.containers[serviceName]
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+	if diff.InSync {
+		return &SpecReconcileResult{Container: container, Reconciled: []SpecDifference{}}, nil
+	}
+	if _, adopted := m.adoption(container.Name); adopted {
+		return nil, fmt.Errorf("container %s was adopted, so the manager cannot recreate it", serviceName)
+	}
+
+	next := *container
+	if err := m.replaceProcessUnsafe(ctx, container, &next); err != nil {
+		return nil, err
+	}
+	*container = next
+
+	fields := make([]string, len(diff.Differences))
+	for i, difference := range diff.Differences {
+		fields[i] = difference.Field
+	}
+	m.recordContainerEvent(container, InstanceEventNormal, "Reconciled",
+		"Recreated the container from its declared spec, which differed in "+strings.Join(fields, ", "))
+	m.logger.InfoContext(ctx, "Container reconciled with its declared spec",
+		slog.String("service", serviceName),
+		slog.String("fields", strings.Join(fields, ",")))
+
+	return &SpecReconcileResult{Container: container, Reconciled: diff.Differences, Restarted: true}, nil
+}
+
+// diffSpec returns the fields in which actual differs from container's declared spec, given the
+// environment of the image it runs and the digest the declared image points at
+func (m *Manager) diffSpec(container *models.Container, actual specInspect, imageEnv []string, desiredDigest string) []SpecDifference {
+	differences := []SpecDifference{}
+	drift := func(field, desired, actual string) {
+		differences = append(differences, SpecDifference{Field: field, Kind: SpecDrift, Desired: desired, Actual: actual})
+	}
+
+	if container.ID != "" && actual.ID != container.ID {
+		drift("container_id", container.ID, actual.ID)
+	}
+
+	runningImage := actual.ImageName
+	if runningImage == "" {
+		runningImage = actual.Config.Image
+	}
+	desiredImage := normalizeImageRef(container.Image)
+	if running := normalizeImageRef(runningImage); running != desiredImage && running != normalizeImageRef(m.mirroredImage(container.Image)) {
+		drift("image", container.Image, runningImage)
+	} else if desiredDigest != "" && actual.ImageDigest != "" && desiredDigest != actual.ImageDigest {
+		differences = append(differences, SpecDifference{
+			Field: "image_digest", Kind: SpecOutdated, Desired: desiredDigest, Actual: actual.ImageDigest,
+		})
+	}
+
+	differences = append(differences, diffEnvironment(container, actual.Config.Env, imageEnv)...)
+
+	if len(container.Command) > 0 && !slices.Equal(container.Command, actual.Config.Cmd) {
+		drift("command", strings.Join(container.Command, " "), strings.Join(actual.Config.Cmd, " "))
+	}
+
+	if limits := container.Resources; limits != nil {
+		if bytes, err := config.ParseMemory(limits.Memory); limits.Memory != "" && err == nil && bytes != actual.HostConfig.Memory {
+			drift("resources.memory", strconv.FormatInt(bytes, 10), strconv.FormatInt(actual.HostConfig.Memory, 10))
+		}
+		if cpus, err := config.ParseCPU(limits.CPU); limits.CPU != "" && err == nil && cpus != actual.cpus() {
+			drift("resources.cpu", strconv.FormatFloat(cpus, 'f', -1, 64), strconv.FormatFloat(actual.cpus(), 'f', -1, 64))
+		}
+		if limits.PidsLimit > 0 && int64(limits.PidsLimit) != actual.HostConfig.PidsLimit {
+			drift("resources.pids_limit", strconv.Itoa(limits.PidsLimit), strconv.FormatInt(actual.HostConfig.PidsLimit, 10))
+		}
+	}
+	return differences
+}
+
+// diffEnvironment returns the declared variables a container lacks or has another value for, and
+// the variables it has that neither its spec nor its image set. Values are masked.
+func diffEnvironment(container *models.Container, actualEnv, imageEnv []string) []SpecDifference {
+	desired := maps.Clone(container.Environment)
+	if desired == nil {
+		desired = make(map[string]string)
+	}
+	// The manager adds these to the declared variables when it runs the container: proxy
+	// variables unless declared, and certificate paths over any declared value
+	for name, value := range proxyEnvironment(container) {
+		if _, exists := desired[name]; !exists {
+			desired[name] = value
+		}
+	}
+	maps.Copy(desired, upstreamTLSEnvironment(container))
+	actual := envMap(actualEnv)
+	fromImage := envMap(imageEnv)
+	masked := maskEnvironment(desired, container.EnvSchema)
+	mask := func(name, value string) string {
+		if masked[name] == maskedValue {
+			return maskedValue
+		}
+		return value
+	}
+
+	var names []string
+	for name := range desired {
+		names = append(names, name)
+	}
+	for name := range actual {
+		if _, declared := desired[name]; !declared {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var differences []SpecDifference
+	for _, name := range names {
+		want, declared := desired[name]
+		have, set := actual[name]
+		switch {
+		case declared && !set:
+			differences = append(differences, SpecDifference{Field: "environment." + name, Kind: SpecDrift, Desired: mask(name, want)})
+		case declared && have != want && !strings.HasPrefix(want, "secret_ref:"):
+			differences = append(differences, SpecDifference{Field: "environment." + name, Kind: SpecDrift, Desired: mask(name, want), Actual: mask(name, have)})
+		case !declared && !slices.Contains(runtimeEnvironment, name):
+			if value, inImage := fromImage[name]; !inImage || value != have {
+				differences = append(differences, SpecDifference{Field: "environment." + name, Kind: SpecDrift, Actual: maskedValue})
+			}
+		}
+	}
+	return differences
+}
+
+// envMap splits NAME=value entries
+func envMap(entries []string) map[string]string {
+	env := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		name, value, _ := strings.Cut(entry, "=")
+		env[name] = value
+	}
+	return env
+}
+
+// cpus returns the CPU limit of an inspected container, 0 when it has none
+func (s specInspect) cpus() float64 {
+	switch {
+	case s.HostConfig.NanoCpus > 0:
+		return float64(s.HostConfig.NanoCpus) / 1e9
+	case s.HostConfig.CpuQuota > 0 && s.HostConfig.CpuPeriod > 0:
+		return float64(s.HostConfig.CpuQuota) / float64(s.HostConfig.CpuPeriod)
+	default:
+		return 0
+	}
+}
+
+// imageRefDigest returns the digest an image reference pins, if any
+func imageRefDigest(ref string) string {
+	if _, digest, pinned := strings.Cut(ref, "@"); pinned {
+		return digest
+	}
+	return ""
+}
+
+// imageField formats a field of a local image with podman image inspect, empty if it fails
+func (m *Manager) imageField(ctx context.Context, image, format string) string {
+	if image == "" {
+		return ""
+	}
+	output, err := podmanCommand(ctx, m.logger, "image", "inspect", "--format", format, image).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,110 @@
+package container
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestSpecDiff(t *testing.T) {
+	manager := newTestManager(&config.Config{})
+	container := &models.Container{
+		ID:          "abc123",
+		Name:        "mcp-github",
+		ServiceName: "github",
+		Image:       "ghcr.io/example/github-mcp:1.0",
+		Command:     []string{"serve", "--port", "8080"},
+		Environment: map[string]string{"MCP_INSTANCE_ID": "inst-1", "GITHUB_TOKEN": "ghp_secret", "LOG_LEVEL": "info"},
+		EnvSchema:   []models.EnvVarSpec{{Name: "LOG_LEVEL"}},
+		Resources:   &models.ResourceLimits{Memory: "512Mi", CPU: "0.5"},
+	}
+
+	var actual specInspect
+	actual.ID = "abc123"
+	actual.ImageName = "ghcr.io/example/github-mcp:1.0"
+	actual.ImageDigest = "sha256:aaa"
+	actual.Config.Env = []string{"MCP_INSTANCE_ID=inst-1", "GITHUB_TOKEN=ghp_secret", "LOG_LEVEL=info", "PATH=/usr/bin", "container=podman", "NODE_VERSION=20"}
+	actual.Config.Cmd = []string{"serve", "--port", "8080"}
+	actual.HostConfig.Memory = 512 * 1024 * 1024
+	actual.HostConfig.NanoCpus = 500000000
+	imageEnv := []string{"PATH=/usr/bin", "NODE_VERSION=20"}
+
+	if differences := manager.diffSpec(container, actual, imageEnv, "sha256:aaa"); len(differences) != 0 {
+		t.Errorf("Expected a container matching its spec to be in sync, got %+v", differences)
+	}
+
+	// Edited by hand: recreated with another token and log level, an extra variable, another
+	// command and more memory, from an image whose tag has since moved
+	actual.ID = "def456"
+	actual.Config.Env = []string{"MCP_INSTANCE_ID=inst-1", "GITHUB_TOKEN=ghp_other", "LOG_LEVEL=debug", "PATH=/usr/bin", "DEBUG=1"}
+	actual.Config.Cmd = []string{"serve"}
+	actual.HostConfig.Memory = 1024 * 1024 * 1024
+	differences := manager.diffSpec(container, actual, imageEnv, "sha256:bbb")
+
+	byField := make(map[string]SpecDifference)
+	for _, difference := range differences {
+		byField[difference.Field] = difference
+	}
+	expected := []string{"container_id", "image_digest", "environment.GITHUB_TOKEN", "environment.LOG_LEVEL", "environment.DEBUG", "command", "resources.memory"}
+	if len(differences) != len(expected) {
+		t.Errorf("Expected %d differences, got %+v", len(expected), differences)
+	}
+	for _, field := range expected {
+		if _, found := byField[field]; !found {
+			t.Errorf("Expected a difference in %s, got %+v", field, differences)
+		}
+	}
+	if kind := byField["image_digest"].Kind; kind != SpecOutdated {
+		t.Errorf("Expected a moved tag to be outdated, got %q", kind)
+	}
+	if kind := byField["command"].Kind; kind != SpecDrift {
+		t.Errorf("Expected a changed command to be drift, got %q", kind)
+	}
+	if token := byField["environment.GITHUB_TOKEN"]; token.Desired != maskedValue || token.Actual != maskedValue {
+		t.Errorf("Expected secret values to be masked, got %+v", token)
+	}
+	if level := byField["environment.LOG_LEVEL"]; level.Desired != "info" || level.Actual != "debug" {
+		t.Errorf("Expected public values to be shown, got %+v", level)
+	}
+
+	actual.ImageName = "ghcr.io/example/github-mcp:0.9"
+	differences = manager.diffSpec(container, actual, imageEnv, "sha256:bbb")
+	for _, difference := range differences {
+		if difference.Field == "image_digest" {
+			t.Errorf("Expected another image to be reported instead of its digest, got %+v", difference)
+		}
+	}
+	if !slices.ContainsFunc(differences, func(d SpecDifference) bool { return d.Field == "image" }) {
+		t.Errorf("Expected a changed image to be reported, got %+v", differences)
+	}
+
+	// The certificate paths the manager adds for upstream mutual TLS are part of the spec
+	container.UpstreamTLS = true
+	actual.ID = "abc123"
+	actual.ImageName = "ghcr.io/example/github-mcp:1.0"
+	actual.Config.Cmd = []string{"serve", "--port", "8080"}
+	actual.HostConfig.Memory = 512 * 1024 * 1024
+	actual.Config.Env = []string{"MCP_INSTANCE_ID=inst-1", "GITHUB_TOKEN=ghp_secret", "LOG_LEVEL=info", "PATH=/usr/bin"}
+	for _, arg := range manager.upstreamTLSArgs(container) {
+		if strings.HasPrefix(arg, "MCP_TLS_") {
+			actual.Config.Env = append(actual.Config.Env, arg)
+		}
+	}
+	if differences := manager.diffSpec(container, actual, imageEnv, "sha256:aaa"); len(differences) != 0 {
+		t.Errorf("Expected a container with upstream TLS to be in sync, got %+v", differences)
+	}
+
+	actual.Config.Env = actual.Config.Env[:4]
+	differences = manager.diffSpec(container, actual, imageEnv, "sha256:aaa")
+	if len(differences) != 3 {
+		t.Errorf("Expected the 3 missing certificate paths to be reported, got %+v", differences)
+	}
+	for _, difference := range differences {
+		if !strings.HasPrefix(difference.Field, "environment.MCP_TLS_") || difference.Actual != "" {
+			t.Errorf("Expected a missing certificate path, got %+v", difference)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// Labels recording the metadata discovery needs to rebuild a container after a manager restart
+const (
+	serviceNameLabel = "mcp.service_name"
+	slugLabel        = "mcp.slug"
+	portLabel        = "mcp.port"
+	createdAtLabel   = "mcp.created_at"
+	commandLabel     = "mcp.command"
+	// envKeysLabel lists the variables the manager set, so they can be told apart from those of the
+	// image; values are read back from the container and never stored in a label
+	envKeysLabel = "mcp.env_keys"
+	// labelKeysLabel lists the caller's labels, which podman reports mixed with those of the image
+	labelKeysLabel = "mcp.label_keys"
+)
+
+// discoveredMetadata is what one podman inspect yields for a container found at startup
+type discoveredMetadata struct {
+	ServiceName string
+	Slug        string
+	Port        int
+	CreatedAt   time.Time
+	Command     []string
+	Environment map[string]string
+	Labels      map[string]string
+	SpecHash    string
+}
+
+// podmanInspectMetadata is the subset of podman inspect output discovery reads
+type podmanInspectMetadata struct {
+	Created time.Time `json:"Created"`
+	Config  struct {
+		Env    []string          `json:"Env"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+}
+
+// metadataLabelArgs returns the --label arguments that let discovery restore a container
+func metadataLabelArgs(container *models.Container) []string {
+	labels := map[string]string{
+		serviceNameLabel: container.ServiceName,
+		slugLabel:        container.Slug,
+		portLabel:        strconv.Itoa(container.Port),
+		createdAtLabel:   container.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if container.SpecHash != "" {
+		labels[specHashLabel] = container.SpecHash
+	}
+	if len(container.Command) > 0 {
+		if data, err := json.Marshal(container.Command); err == nil {
+			labels[commandLabel] = string(data)
+		}
+	}
+	if len(container.Environment) > 0 {
+		labels[envKeysLabel] = sortedKeys(container.Environment)
+	}
+	if len(container.Labels) > 0 {
+		labels[labelKeysLabel] = sortedKeys(container.Labels)
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	args := make([]string, 0, 2*len(names))
+	for _, name := range names {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", name, labels[name]))
+	}
+	return args
+}
+
+// inspectDiscoveryMetadata reads a container's metadata labels and environment in one podman inspect
+func (m *Manager) inspectDiscoveryMetadata(ctx context.Context, containerID string) (*discoveredMetadata, error) {
+	output, err := podmanCommand(ctx, m.logger, "inspect", containerID).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %w", err)
+	}
+
+	var inspected []podmanInspectMetadata
+	if err := json.Unmarshal(output, &inspected); err != nil || len(inspected) == 0 {
+		return nil, fmt.Errorf("failed to parse container inspect output")
+	}
+	return parseDiscoveryMetadata(&inspected[0]), nil
+}
+
+// parseDiscoveryMetadata extracts discovery metadata, falling back to the MCP_SERVICE_NAME and
+// MCP_CONTAINER_PORT variables for containers created before the metadata labels existed
+func parseDiscoveryMetadata(inspected *podmanInspectMetadata) *discoveredMetadata {
+	labels := inspected.Config.Labels
+	env := make(map[string]string, len(inspected.Config.Env))
+	for _, entry := range inspected.Config.Env {
+		if key, value, found := strings.Cut(entry, "="); found {
+			env[key] = value
+		}
+	}
+
+	metadata := &discoveredMetadata{
+		ServiceName: labels[serviceNameLabel],
+		Slug:        labels[slugLabel],
+		CreatedAt:   inspected.Created,
+		SpecHash:    labels[specHashLabel],
+	}
+	if metadata.ServiceName == "" {
+		metadata.ServiceName = strings.Trim(env["MCP_SERVICE_NAME"], "\"'")
+	}
+
+	port := labels[portLabel]
+	if port == "" {
+		port = env["MCP_CONTAINER_PORT"]
+	}
+	if p, err := strconv.Atoi(port); err == nil && p > 0 {
+		metadata.Port = p
+	}
+
+	if createdAt, err := time.Parse(time.RFC3339, labels[createdAtLabel]); err == nil {
+		metadata.CreatedAt = createdAt
+	}
+
+	if data := labels[commandLabel]; data != "" {
+		_ = json.Unmarshal([]byte(data), &metadata.Command)
+	}
+
+	metadata.Environment = make(map[string]string)
+	if keys := labels[envKeysLabel]; keys != "" {
+		for _, key := range strings.Split(keys, ",") {
+			if value, exists := env[key]; exists {
+				metadata.Environment[key] = value
+			}
+		}
+	} else {
+		// Older containers at least keep the MCP_ variables events are published with
+		for key, value := range env {
+			if strings.HasPrefix(key, "MCP_") {
+				metadata.Environment[key] = value
+			}
+		}
+	}
+
+	if keys := labels[labelKeysLabel]; keys != "" {
+		metadata.Labels = make(map[string]string)
+		for _, key := range strings.Split(keys, ",") {
+			if value, exists := labels[key]; exists {
+				metadata.Labels[key] = value
+			}
+		}
+	}
+	return metadata
+}
+
+// sortedKeys joins the keys of m with commas in sorted order
+func sortedKeys(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
@@ -0,0 +1,56 @@
+package container
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestDiscoveryMetadata(t *testing.T) {
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	container := &models.Container{
+		ServiceName: "github",
+		Slug:        "github-ab12",
+		Port:        3000,
+		CreatedAt:   createdAt,
+		Command:     []string{"node", "server.js"},
+		Environment: map[string]string{"MCP_INSTANCE_ID": "inst-1", "GITHUB_TOKEN": "secret"},
+		Labels:      map[string]string{"team": "platform"},
+	}
+
+	inspected := &podmanInspectMetadata{}
+	inspected.Config.Labels = map[string]string{"org.opencontainers.image.title": "github-mcp"}
+	args := metadataLabelArgs(container)
+	for i := 0; i+1 < len(args); i += 2 {
+		name, value, _ := strings.Cut(args[i+1], "=")
+		inspected.Config.Labels[name] = value
+		if strings.Contains(value, "secret") {
+			t.Errorf("Expected no environment values in labels, got %s", args[i+1])
+		}
+	}
+	inspected.Config.Labels["team"] = "platform"
+	inspected.Config.Env = []string{"PATH=/usr/bin", "MCP_INSTANCE_ID=inst-1", "GITHUB_TOKEN=secret"}
+
+	metadata := parseDiscoveryMetadata(inspected)
+	if metadata.ServiceName != "github" || metadata.Slug != "github-ab12" || metadata.Port != 3000 || !metadata.CreatedAt.Equal(createdAt) {
+		t.Errorf("Expected service, slug, port and creation time restored, got %+v", metadata)
+	}
+	if len(metadata.Environment) != 2 || metadata.Environment["GITHUB_TOKEN"] != "secret" {
+		t.Errorf("Expected only the manager's variables restored, got %v", metadata.Environment)
+	}
+	if len(metadata.Labels) != 1 || metadata.Labels["team"] != "platform" {
+		t.Errorf("Expected only the caller's labels restored, got %v", metadata.Labels)
+	}
+	if strings.Join(metadata.Command, " ") != "node server.js" {
+		t.Errorf("Expected the command restored, got %v", metadata.Command)
+	}
+
+	legacy := &podmanInspectMetadata{Created: createdAt}
+	legacy.Config.Env = []string{"PATH=/usr/bin", "MCP_SERVICE_NAME=github", "MCP_CONTAINER_PORT=8080"}
+	metadata = parseDiscoveryMetadata(legacy)
+	if metadata.ServiceName != "github" || metadata.Port != 8080 || metadata.Slug != "" || len(metadata.Environment) != 2 {
+		t.Errorf("Expected the environment fallback for containers without labels, got %+v", metadata)
+	}
+}
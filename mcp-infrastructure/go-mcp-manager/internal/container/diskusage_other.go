@@ -0,0 +1,15 @@
+//go:build !unix
+
+package container
+
+import (
+	"errors"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// filesystemUsage is not available on this platform, so GC falls back to image sizes
+// and storage pressure checks are skipped
+func filesystemUsage(path string) (*models.FilesystemUsage, error) {
+	return nil, errors.New("filesystem usage is not supported on this platform")
+}
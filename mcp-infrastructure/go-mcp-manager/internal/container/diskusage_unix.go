@@ -0,0 +1,29 @@
+//go:build unix
+
+package container
+
+import (
+	"syscall"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// filesystemUsage returns the size and usage of the filesystem holding path
+func filesystemUsage(path string) (*models.FilesystemUsage, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return nil, err
+	}
+
+	blockSize := uint64(stat.Bsize)
+	usage := &models.FilesystemUsage{
+		Path:           path,
+		TotalBytes:     uint64(stat.Blocks) * blockSize,
+		AvailableBytes: uint64(stat.Bavail) * blockSize,
+	}
+	usage.UsedBytes = usage.TotalBytes - uint64(stat.Bfree)*blockSize
+	if usage.TotalBytes > 0 {
+		usage.UsedPercent = float64(usage.UsedBytes) / float64(usage.TotalBytes) * 100
+	}
+	return usage, nil
+}
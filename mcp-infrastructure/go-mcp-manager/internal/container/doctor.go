@@ -0,0 +1,212 @@
+package container
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/secrets"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// errDoctorSkipped marks a check that does not apply to this host
+var errDoctorSkipped = errors.New("skipped")
+
+// doctorStep runs one doctor check and returns what went wrong
+type doctorStep struct {
+	name string
+	// hint tells an operator what to look at when the step fails
+	hint string
+	run  func(ctx context.Context, state *doctorState) (string, error)
+	// needs names the step that must pass first; the step is skipped otherwise
+	needs string
+}
+
+// doctorState is shared by the steps of one doctor run
+type doctorState struct {
+	name        string
+	created     bool
+	containerID string
+	containerIP string
+}
+
+// SetSecretResolver lets the doctor check that secrets can be resolved, and environment updates
+// resolve secret references
+func (m *Manager) SetSecretResolver(resolver *secrets.SecretResolver) {
+	m.secrets = resolver
+}
+
+// RunDoctor checks that this host can do what provisioning needs: podman answers, an image can
+// be pulled, a container can be created and removed, a route can be programmed, a secret can be
+// resolved and state can be persisted. Everything it creates is removed before it returns.
+func (m *Manager) RunDoctor(ctx context.Context) *models.DoctorReport {
+	ctx, cancel := context.WithTimeout(ctx, m.config.Doctor.Timeout)
+	defer cancel()
+
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	state := &doctorState{name: "mcp-doctor-" + hex.EncodeToString(suffix)}
+	defer m.cleanupDoctor(state)
+
+	report := &models.DoctorReport{Passed: true, StartedAt: time.Now()}
+	passed := make(map[string]bool)
+	for _, step := range m.doctorSteps() {
+		check := models.DoctorCheck{Name: step.name}
+		started := time.Now()
+		switch {
+		case step.needs != "" && !passed[step.needs]:
+			check.Status = models.DoctorSkip
+			check.Message = fmt.Sprintf("skipped because the %s check did not pass", step.needs)
+		default:
+			message, err := step.run(ctx, state)
+			check.Message = message
+			check.Status = models.DoctorPass
+			if errors.Is(err, errDoctorSkipped) {
+				check.Status = models.DoctorSkip
+			} else if err != nil {
+				check.Status = models.DoctorFail
+				check.Message = err.Error()
+				check.Hint = step.hint
+				report.Passed = false
+			}
+		}
+		check.DurationMs = time.Since(started).Milliseconds()
+		passed[step.name] = check.Status == models.DoctorPass
+		report.Checks = append(report.Checks, check)
+	}
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+	return report
+}
+
+// doctorSteps returns the doctor's checks in the order they run
+func (m *Manager) doctorSteps() []doctorStep {
+	image := m.config.Doctor.Image
+	network := m.config.Traefik.Network
+	return []doctorStep{
+		{
+			name: "runtime",
+			hint: "Check that podman is installed and that `podman info` works as the manager's user",
+			run: func(ctx context.Context, _ *doctorState) (string, error) {
+				return "podman answers", m.CheckRuntime(ctx)
+			},
+		},
+		{
+			name:  "pull",
+			needs: "runtime",
+			hint:  "Check DNS, registry credentials and HTTP(S)_PROXY on the host, or set DOCTOR_IMAGE to an image in a reachable registry",
+			run: func(ctx context.Context, _ *doctorState) (string, error) {
+				if output, err := runPodman(ctx, m.logger, "pull", image); err != nil {
+					return "", fmt.Errorf("failed to pull %s: %w: %s", image, err, strings.TrimSpace(string(output)))
+				}
+				return fmt.Sprintf("pulled %s", image), nil
+			},
+		},
+		{
+			name:  "container",
+			needs: "pull",
+			hint:  fmt.Sprintf("Check podman storage and that the network %s exists (podman network ls)", network),
+			run: func(ctx context.Context, state *doctorState) (string, error) {
+				return m.doctorContainer(ctx, state, image, network)
+			},
+		},
+		{
+			name:  "route",
+			needs: "container",
+			hint:  "Check that TRAEFIK_CONFIG_DIR is writable by the manager",
+			run: func(ctx context.Context, state *doctorState) (string, error) {
+				return m.doctorRoute(ctx, state)
+			},
+		},
+		{
+			name: "secrets",
+			hint: "Check INFISICAL_URL, the identity token in INFISICAL_TOKEN_PATH and INFISICAL_PROJECT_ID",
+			run: func(ctx context.Context, _ *doctorState) (string, error) {
+				if m.secrets == nil || !m.secrets.Configured() {
+					return "Infisical is not configured, so secret_ref environment values cannot be resolved", errDoctorSkipped
+				}
+				if err := m.secrets.SelfTest(ctx); err != nil {
+					return "", err
+				}
+				return "Infisical accepted the manager's token", nil
+			},
+		},
+		{
+			name: "state",
+			hint: "Check that STATE_DIR exists and is writable by the manager",
+			run: func(context.Context, *doctorState) (string, error) {
+				if err := m.CheckStateStore(); err != nil {
+					return "", err
+				}
+				if m.config.State.Dir == "" {
+					return "state is kept in memory only and is lost on restart", nil
+				}
+				return fmt.Sprintf("%s is writable", m.config.State.Dir), nil
+			},
+		},
+	}
+}
+
+// doctorContainer starts a test container and waits for it to run. It carries no managed-by
+// label, so discovery never takes it for an instance.
+func (m *Manager) doctorContainer(ctx context.Context, state *doctorState, image, network string) (string, error) {
+	state.created = true
+	output, err := podmanCommand(ctx, m.logger, "run", "-d", "--name", state.name, "--network", network,
+		"--memory", "32m", image, "sleep", "300").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to create a test container: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	state.containerID = strings.TrimSpace(string(output))
+
+	if err := m.waitForContainer(ctx, state.containerID); err != nil {
+		return "", fmt.Errorf("test container did not start: %w", err)
+	}
+	if state.containerIP, err = m.getContainerIP(ctx, state.containerID); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("created and started a test container on network %s", network), nil
+}
+
+// doctorRoute programs a route to the test container and reads it back
+func (m *Manager) doctorRoute(ctx context.Context, state *doctorState) (string, error) {
+	if err := m.traefikManager.AddMCPService(ctx, state.name, state.containerIP, 8080, nil, nil, nil); err != nil {
+		return "", fmt.Errorf("failed to program a route: %w", err)
+	}
+	upstream, err := m.traefikManager.GetMCPServiceUpstream(state.name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the route back: %w", err)
+	}
+	if expected := mcpUpstreamURL(state.containerIP, 8080); upstream != expected {
+		return "", fmt.Errorf("route points at %q instead of %q", upstream, expected)
+	}
+	if err := m.traefikManager.RemoveMCPService(ctx, state.name); err != nil {
+		return "", fmt.Errorf("failed to remove the test route: %w", err)
+	}
+	return "programmed and removed a test route", nil
+}
+
+// cleanupDoctor removes the doctor's test container and route, even after a failed step
+func (m *Manager) cleanupDoctor(state *doctorState) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if upstream, _ := m.traefikManager.GetMCPServiceUpstream(state.name); upstream != "" {
+		if err := m.traefikManager.RemoveMCPService(ctx, state.name); err != nil {
+			m.logger.WarnContext(ctx, "Failed to remove the doctor's test route",
+				slog.String("slug", state.name),
+				slog.String("error", err.Error()))
+		}
+	}
+	if state.created {
+		if output, err := podmanCommand(ctx, m.logger, "rm", "-f", "--ignore", state.name).CombinedOutput(); err != nil {
+			m.logger.WarnContext(ctx, "Failed to remove the doctor's test container",
+				slog.String("container", state.name),
+				slog.String("error", err.Error()),
+				slog.String("output", string(output)))
+		}
+	}
+}
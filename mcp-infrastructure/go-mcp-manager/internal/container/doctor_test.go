@@ -0,0 +1,45 @@
+package container
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestRunDoctor(t *testing.T) {
+	// Without podman on the PATH the runtime check fails and the checks needing it are skipped
+	t.Setenv("PATH", t.TempDir())
+	stateDir := t.TempDir()
+	cfg := &config.Config{
+		State:  config.StateConfig{Dir: stateDir},
+		Doctor: config.DoctorConfig{Image: "docker.io/library/busybox:latest", Timeout: 10 * time.Second},
+	}
+	manager := newTestManager(cfg)
+
+	report := manager.RunDoctor(context.Background())
+	if report.Passed {
+		t.Error("Expected the doctor to fail without podman")
+	}
+	expected := map[string]string{
+		"runtime":   models.DoctorFail,
+		"pull":      models.DoctorSkip,
+		"container": models.DoctorSkip,
+		"route":     models.DoctorSkip,
+		"secrets":   models.DoctorSkip,
+		"state":     models.DoctorPass,
+	}
+	if len(report.Checks) != len(expected) {
+		t.Fatalf("Expected %d checks, got %+v", len(expected), report.Checks)
+	}
+	for _, check := range report.Checks {
+		if check.Status != expected[check.Name] {
+			t.Errorf("Expected the %s check to %s, got %s: %s", check.Name, expected[check.Name], check.Status, check.Message)
+		}
+		if check.Status == models.DoctorFail && check.Hint == "" {
+			t.Errorf("Expected the failed %s check to carry a hint", check.Name)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// Container engines whose CLI the manager can drive
+const (
+	enginePodman = "podman"
+	engineDocker = "docker"
+)
+
+// engine is the container engine CLI every podman invocation runs, and the socket it talks to
+type engine struct {
+	binary string
+	// host is the engine's API socket, e.g. of a podman machine or Docker Desktop; empty uses the
+	// engine's own default
+	host string
+}
+
+// activeEngine is set from the manager's configuration; nil runs the local podman
+var activeEngine atomic.Pointer[engine]
+
+// configureEngine selects the engine CLI and socket for all podman invocations. Podman is used
+// for an unknown engine, since it is the only one every feature works on.
+func configureEngine(runtime, host string, logger *slog.Logger) {
+	binary := strings.ToLower(strings.TrimSpace(runtime))
+	switch binary {
+	case "", enginePodman:
+		binary = enginePodman
+	case engineDocker:
+		logger.Info("Driving containers through the docker CLI; podman-only features such as pods and checkpoints are unavailable",
+			slog.String("host", host))
+	default:
+		logger.Warn("Unknown container runtime, using podman", slog.String("runtime", runtime))
+		binary = enginePodman
+	}
+	activeEngine.Store(&engine{binary: binary, host: host})
+}
+
+// currentEngine returns the configured engine, or the local podman
+func currentEngine() *engine {
+	if e := activeEngine.Load(); e != nil {
+		return e
+	}
+	return &engine{binary: enginePodman}
+}
+
+// command returns the binary and arguments that run the podman command args on this engine
+func (e *engine) command(args []string) (string, []string) {
+	if e.binary == engineDocker {
+		args = dockerArgs(args)
+		if e.host != "" {
+			args = append([]string{"--host", e.host}, args...)
+		}
+		return e.binary, args
+	}
+	if e.host != "" {
+		args = append([]string{"--url", e.host}, args...)
+	}
+	return e.binary, args
+}
+
+// dockerArgs rewrites the podman-only forms the manager uses into their docker equivalents:
+// "exists" becomes an inspect whose exit status answers the same question, rm drops --ignore,
+// which docker's rm -f implies, and a json format becomes docker's per-line template
+func dockerArgs(args []string) []string {
+	translated := slices.Clone(args)
+	if len(translated) >= 2 && translated[1] == "exists" {
+		translated[1] = "inspect"
+	}
+	if len(translated) > 0 && translated[0] == "rm" {
+		translated = slices.DeleteFunc(translated, func(arg string) bool { return arg == "--ignore" })
+	}
+	for i := 1; i < len(translated); i++ {
+		if translated[i-1] == "--format" && translated[i] == "json" {
+			translated[i] = "{{json .}}"
+		}
+	}
+	return translated
+}
+
+// listContainers returns all containers in the shape of podman ps --format json, with sizes when
+// size is set. Docker's listing flattens labels into one string, so on docker the containers are
+// inspected instead.
+func listContainers(ctx context.Context, logger *slog.Logger, size bool) ([]byte, error) {
+	if currentEngine().binary != engineDocker {
+		args := []string{"ps", "-a", "--format", "json"}
+		if size {
+			args = []string{"ps", "-a", "--size", "--format", "json"}
+		}
+		return podmanCommand(ctx, logger, args...).Output()
+	}
+
+	output, err := podmanCommand(ctx, logger, "ps", "-a", "-q", "--no-trunc").Output()
+	if err != nil {
+		return nil, err
+	}
+	ids := strings.Fields(string(output))
+	if len(ids) == 0 {
+		return []byte("[]"), nil
+	}
+	args := []string{"inspect"}
+	if size {
+		args = append(args, "--size")
+	}
+	output, err = podmanCommand(ctx, logger, append(args, ids...)...).Output()
+	if err != nil {
+		return nil, err
+	}
+	return dockerListing(output)
+}
+
+// dockerInspect is the part of docker's container inspect output a listing needs
+type dockerInspect struct {
+	ID    string `json:"Id"`
+	Name  string `json:"Name"`
+	State struct {
+		Status string `json:"Status"`
+	} `json:"State"`
+	Config struct {
+		Image  string            `json:"Image"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	SizeRw     *int64 `json:"SizeRw"`
+	SizeRootFs *int64 `json:"SizeRootFs"`
+}
+
+// dockerListing converts docker inspect output into podman's listing
+func dockerListing(output []byte) ([]byte, error) {
+	var inspected []dockerInspect
+	if err := json.Unmarshal(output, &inspected); err != nil {
+		return nil, fmt.Errorf("failed to parse docker inspect output: %w", err)
+	}
+	listing := make([]map[string]interface{}, 0, len(inspected))
+	for _, container := range inspected {
+		entry := map[string]interface{}{
+			"Id":     container.ID,
+			"Names":  []string{strings.TrimPrefix(container.Name, "/")},
+			"Image":  container.Config.Image,
+			"State":  container.State.Status,
+			"Labels": container.Config.Labels,
+		}
+		if container.SizeRw != nil || container.SizeRootFs != nil {
+			var rw, rootFs int64
+			if container.SizeRw != nil {
+				rw = *container.SizeRw
+			}
+			if container.SizeRootFs != nil {
+				rootFs = *container.SizeRootFs
+			}
+			entry["Size"] = map[string]int64{"rootFsSize": rootFs, "rwSize": rw}
+		}
+		listing = append(listing, entry)
+	}
+	return json.Marshal(listing)
+}
+
+// publishedAddress returns the host address the engine published containerPort of a container on
+func publishedAddress(ctx context.Context, logger *slog.Logger, containerID string, containerPort int) (string, int, error) {
+	output, err := runPodman(ctx, logger, "port", containerID, fmt.Sprintf("%d/tcp", containerPort))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to look up published port %d: %w: %s", containerPort, err, strings.TrimSpace(string(output)))
+	}
+	return parsePublishedAddress(string(output), containerPort)
+}
+
+// parsePublishedAddress reads the first address of podman or docker port output such as
+// "127.0.0.1:40123". Wildcard addresses are reached over loopback.
+func parsePublishedAddress(output string, containerPort int) (string, int, error) {
+	for _, line := range strings.Split(output, "\n") {
+		host, portText, err := net.SplitHostPort(strings.TrimSpace(line))
+		if err != nil {
+			continue
+		}
+		port, err := strconv.Atoi(portText)
+		if err != nil || port <= 0 {
+			continue
+		}
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = "127.0.0.1"
+		}
+		return host, port, nil
+	}
+	return "", 0, fmt.Errorf("port %d is not published", containerPort)
+}
+
+// upstreamAddress returns where the proxy and the manager reach a container's server: containerIP
+// and the container's port, or, when ports are published because container IPs are unreachable
+// from the host, the address the engine published the port on
+func (m *Manager) upstreamAddress(ctx context.Context, container *models.Container, containerIP string) (string, int) {
+	if !m.config.Container.PublishPorts {
+		return containerIP, container.Port
+	}
+	host, port, err := publishedAddress(ctx, m.logger, container.ID, container.Port)
+	if err != nil {
+		m.logger.WarnContext(ctx, "Failed to find published port, routing to the container IP",
+			slog.String("container", container.Name),
+			slog.String("error", err.Error()))
+		return containerIP, container.Port
+	}
+	return host, port
+}
+
+// publishArgs publishes the container's port, and a separate health check port, on loopback
+// with engine-chosen host ports
+func (m *Manager) publishArgs(container *models.Container) []string {
+	if !m.config.Container.PublishPorts {
+		return nil
+	}
+	var args []string
+	ports := []int{container.Port}
+	if container.HealthCheck != nil && container.HealthCheck.Port > 0 && container.HealthCheck.Port != container.Port {
+		ports = append(ports, container.HealthCheck.Port)
+	}
+	for _, port := range ports {
+		if port > 0 {
+			args = append(args, "-p", fmt.Sprintf("127.0.0.1::%d", port))
+		}
+	}
+	return args
+}
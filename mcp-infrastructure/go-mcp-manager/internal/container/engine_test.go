@@ -0,0 +1,56 @@
+package container
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestEngineCommand(t *testing.T) {
+	podman := &engine{binary: enginePodman, host: "unix:///tmp/podman.sock"}
+	if binary, args := podman.command([]string{"image", "exists", "nginx"}); binary != "podman" || strings.Join(args, " ") != "--url unix:///tmp/podman.sock image exists nginx" {
+		t.Errorf("Expected podman to talk to the machine socket, got %s %v", binary, args)
+	}
+
+	docker := &engine{binary: engineDocker, host: "npipe:////./pipe/docker_engine"}
+	for given, expected := range map[string]string{
+		"image exists nginx":                           "image inspect nginx",
+		"rm -f --ignore mcp-github":                    "rm -f mcp-github",
+		"events --format json --filter type=container": "events --format {{json .}} --filter type=container",
+	} {
+		binary, args := docker.command(strings.Fields(given))
+		if binary != "docker" || strings.Join(args, " ") != "--host npipe:////./pipe/docker_engine "+expected {
+			t.Errorf("Expected %q to become %q, got %s %v", given, expected, binary, args)
+		}
+	}
+
+	listing, err := dockerListing([]byte(`[{"Id":"abc","Name":"/mcp-github","State":{"Status":"running"},"Config":{"Image":"nginx","Labels":{"mcp.route":"{\"a\":1,\"b\":2}"}},"SizeRw":10,"SizeRootFs":100}]`))
+	if err != nil {
+		t.Fatalf("Failed to convert docker listing: %v", err)
+	}
+	var sized []podmanContainerSize
+	if err := json.Unmarshal(listing, &sized); err != nil || len(sized) != 1 {
+		t.Fatalf("Expected one podman-shaped container, got %s (%v)", listing, err)
+	}
+	if sized[0].Names[0] != "mcp-github" || sized[0].Labels["mcp.route"] != `{"a":1,"b":2}` || sized[0].Size == nil || sized[0].Size.RwSize != 10 {
+		t.Errorf("Expected name, labels and sizes to carry over, got %+v", sized[0])
+	}
+
+	if host, port, err := parsePublishedAddress("0.0.0.0:40123\n[::]:40123\n", 8000); err != nil || host != "127.0.0.1" || port != 40123 {
+		t.Errorf("Expected 127.0.0.1:40123, got %s:%d (%v)", host, port, err)
+	}
+	if _, _, err := parsePublishedAddress("", 8000); err == nil {
+		t.Error("Expected an unpublished port to be an error")
+	}
+
+	cfg := &config.Config{}
+	cfg.Container.PublishPorts = true
+	manager := &Manager{config: cfg}
+	container := &models.Container{Port: 8000, HealthCheck: &models.HealthCheckConfig{Port: 9000}}
+	if args := strings.Join(manager.publishArgs(container), " "); args != "-p 127.0.0.1::8000 -p 127.0.0.1::9000" {
+		t.Errorf("Expected both ports published on loopback, got %s", args)
+	}
+}
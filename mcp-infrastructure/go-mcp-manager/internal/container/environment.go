@@ -0,0 +1,208 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// ErrInvalidEnvironmentUpdate is returned for an environment update a container cannot take
+var ErrInvalidEnvironmentUpdate = errors.New("invalid environment update")
+
+// UpdateEnvironment sets, or removes when nil, some of a container's environment variables and
+// recreates its container with them. The slug, sidecars, volumes and certificates are kept, so
+// the instance stays at its URL with its data. When the new container does not start, the old
+// one is recreated. A stopped container is left stopped.
+func (m *Manager) UpdateEnvironment(ctx context.Context, serviceName string, changes map[string]*string) (*models.UpdateEnvironmentResponse, error) {
+	snapshot, exists := m.containerSnapshot(serviceName)
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+	if _, adopted := m.adoption(snapshot.Name); adopted {
+		return nil, fmt.Errorf("%w: container %s was adopted, so the manager cannot recreate it", ErrInvalidEnvironmentUpdate, serviceName)
+	}
+	for key := range changes {
+		if key == "" || strings.ContainsAny(key, "= \t\n") {
+			return nil, fmt.Errorf("%w: %q is not a valid variable name", ErrInvalidEnvironmentUpdate, key)
+		}
+		if slices.Contains(managedEnvironment, key) {
+			return nil, fmt.Errorf("%w: %s is set by the manager", ErrInvalidEnvironmentUpdate, key)
+		}
+	}
+
+	// The secret store is remote, so references are resolved before taking the lock
+	resolved, err := m.resolveSecretChanges(snapshot.Environment["MCP_INSTANCE_ID"], changes)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	container, exists := m.containers[serviceName]
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+	environment := maps.Clone(container.Environment)
+	if environment == nil {
+		environment = make(map[string]string)
+	}
+	for key, value := range resolved {
+		if value == nil {
+			delete(environment, key)
+		} else {
+			environment[key] = *value
+		}
+	}
+	if environment, err = ApplyEnvSchema(container.EnvSchema, environment); err != nil {
+		return nil, err
+	}
+
+	changed := []string{}
+	for key := range resolved {
+		previous, had := container.Environment[key]
+		current, has := environment[key]
+		if had != has || previous != current {
+			changed = append(changed, key)
+		}
+	}
+	sort.Strings(changed)
+	if len(changed) == 0 {
+		return &models.UpdateEnvironmentResponse{Container: container, Changed: changed}, nil
+	}
+
+	// The spec hash is left as it is, so retrying the original create does not undo the update
+	updated := *container
+	updated.Environment = environment
+	updated.Provenance = maps.Clone(container.Provenance)
+	for _, key := range changed {
+		if _, set := environment[key]; set && updated.Provenance != nil {
+			updated.Provenance["environment."+key] = models.ProvenanceRequest
+		} else {
+			delete(updated.Provenance, "environment."+key)
+		}
+	}
+	if err := m.replaceProcessUnsafe(ctx, container, &updated); err != nil {
+		return nil, err
+	}
+	*container = updated
+
+	m.logger.InfoContext(ctx, "Container environment updated",
+		slog.String("service", serviceName),
+		slog.String("changed", strings.Join(changed, ",")))
+
+	return &models.UpdateEnvironmentResponse{Container: container, Changed: changed, Restarted: true}, nil
+}
+
+// resolveSecretChanges replaces the secret references among changes with the values the secret
+// store holds for instanceID
+func (m *Manager) resolveSecretChanges(instanceID string, changes map[string]*string) (map[string]*string, error) {
+	resolved := make(map[string]*string, len(changes))
+	references := make(map[string]string)
+	for key, value := range changes {
+		resolved[key] = value
+		if value != nil && strings.HasPrefix(*value, "secret_ref:") {
+			references[key] = *value
+		}
+	}
+	if len(references) == 0 {
+		return resolved, nil
+	}
+	if instanceID == "" {
+		return nil, fmt.Errorf("%w: secret references are stored per instance, and the container has no MCP_INSTANCE_ID", ErrInvalidEnvironmentUpdate)
+	}
+	if m.secrets == nil || !m.secrets.Configured() {
+		return nil, fmt.Errorf("%w: secret references cannot be resolved without Infisical", ErrInvalidEnvironmentUpdate)
+	}
+
+	values, err := m.secrets.ResolveSecrets(instanceID, references)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range values {
+		resolved[key] = &value
+	}
+	return resolved, nil
+}
+
+// replaceProcessUnsafe recreates current's container as next, which only differs in what runs in
+// it. When next does not start, current's container is recreated in its place. The caller holds
+// the mutex.
+func (m *Manager) replaceProcessUnsafe(ctx context.Context, current, next *models.Container) error {
+	if err := m.removeProcess(ctx, current); err != nil {
+		return err
+	}
+	err := m.startReplacement(ctx, next)
+	if err == nil {
+		return nil
+	}
+
+	m.logger.WarnContext(ctx, "Replacement container failed, recreating the previous one",
+		slog.String("service", current.ServiceName),
+		slog.String("error", err.Error()))
+	if removeErr := m.removeProcess(ctx, next); removeErr != nil {
+		err = fmt.Errorf("%w; removing it failed: %v", err, removeErr)
+	}
+	if restoreErr := m.startReplacement(ctx, current); restoreErr != nil {
+		current.Status = models.StatusError
+		return fmt.Errorf("%w; recreating the previous container failed: %v", err, restoreErr)
+	}
+	return fmt.Errorf("%w; the previous container was recreated", err)
+}
+
+// removeProcess stops and removes a container, leaving its sidecars, volumes and route
+func (m *Manager) removeProcess(ctx context.Context, container *models.Container) error {
+	if output, err := m.stopProcess(ctx, container); err != nil {
+		m.logger.WarnContext(ctx, "Failed to stop container",
+			slog.String("container", container.Name),
+			slog.String("error", err.Error()),
+			slog.String("output", string(output)))
+	}
+	output, err := podmanCommand(ctx, m.logger, "rm", "-f", "--ignore", container.Name).CombinedOutput()
+	m.inspect.invalidate(container.ID)
+	if err != nil {
+		return fmt.Errorf("failed to remove container: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// startReplacement runs container, routes its slug to it, and stops it again if a user stopped it
+func (m *Manager) startReplacement(ctx context.Context, container *models.Container) error {
+	output, err := m.podmanRun(ctx, container, m.buildPodmanRunArgs(container))
+	if err != nil {
+		return fmt.Errorf("failed to create container: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	container.ID = strings.TrimSpace(string(output))
+	container.UpdatedAt = time.Now()
+	if err := m.waitForContainer(ctx, container.ID); err != nil {
+		return fmt.Errorf("container failed to start: %w", err)
+	}
+
+	if container.StoppedAt != nil {
+		if output, err := m.stopProcess(ctx, container); err != nil {
+			return fmt.Errorf("failed to stop container: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+		m.inspect.invalidate(container.ID)
+		return nil
+	}
+	if !m.beginStartupUnsafe(container) {
+		container.Status = models.StatusRunning
+	}
+	// A container kept for rollback has no route
+	if container.URL != "" {
+		if _, err := m.refreshRoute(ctx, container); err != nil {
+			m.logger.WarnContext(ctx, "Failed to route recreated container",
+				slog.String("service", container.ServiceName),
+				slog.String("error", err.Error()))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestUpdateEnvironmentValidation(t *testing.T) {
+	cfg := &config.Config{
+		Container: config.ContainerConfig{NamePrefix: "test-", MaxContainers: 10},
+		State:     config.StateConfig{Dir: t.TempDir()},
+	}
+	manager := newTestManager(cfg)
+	manager.containers["app"] = &models.Container{
+		Name:        "test-app",
+		ServiceName: "app",
+		Environment: map[string]string{"API_KEY": "old", "MCP_INSTANCE_ID": "inst-1"},
+		EnvSchema:   []models.EnvVarSpec{{Name: "API_KEY", Required: true}},
+	}
+	value := func(v string) *string { return &v }
+	ctx := context.Background()
+
+	if _, err := manager.UpdateEnvironment(ctx, "missing", map[string]*string{"API_KEY": value("new")}); err == nil {
+		t.Error("Expected an update of an unknown container to fail")
+	}
+	if _, err := manager.UpdateEnvironment(ctx, "app", map[string]*string{"MCP_INSTANCE_ID": value("other")}); !errors.Is(err, ErrInvalidEnvironmentUpdate) {
+		t.Errorf("Expected manager-set variables to be rejected, got %v", err)
+	}
+	if _, err := manager.UpdateEnvironment(ctx, "app", map[string]*string{"API_KEY": value("secret_ref:API_KEY")}); !errors.Is(err, ErrInvalidEnvironmentUpdate) {
+		t.Errorf("Expected secret references to be rejected without a secret store, got %v", err)
+	}
+	var schemaErr *EnvSchemaError
+	if _, err := manager.UpdateEnvironment(ctx, "app", map[string]*string{"API_KEY": nil}); !errors.As(err, &schemaErr) {
+		t.Errorf("Expected removing a required variable to fail the env_schema, got %v", err)
+	}
+
+	result, err := manager.UpdateEnvironment(ctx, "app", map[string]*string{"API_KEY": value("old")})
+	if err != nil {
+		t.Fatalf("Expected an unchanged value to be accepted, got %v", err)
+	}
+	if result.Restarted || len(result.Changed) != 0 {
+		t.Errorf("Expected no restart when nothing changed, got %+v", result)
+	}
+}
@@ -0,0 +1,175 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// envSchemaLabel stores the environment schema a container was created with
+const envSchemaLabel = "mcp.env_schema"
+
+// envSchemaFormats are the value formats an env_schema entry may declare
+var envSchemaFormats = []string{models.EnvFormatString, models.EnvFormatNumber, models.EnvFormatBoolean, models.EnvFormatFilepath}
+
+// EnvSchemaError lists the environment variables that do not satisfy a spec's env_schema
+type EnvSchemaError struct {
+	Problems []models.EnvVarProblem
+}
+
+func (e *EnvSchemaError) Error() string {
+	messages := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		messages[i] = problem.Name + ": " + problem.Message
+	}
+	return "environment does not match env_schema: " + strings.Join(messages, "; ")
+}
+
+// parseEnvSchemaSpec reads the optional env_schema list from json_spec
+func parseEnvSchemaSpec(jsonSpec map[string]interface{}) ([]models.EnvVarSpec, error) {
+	raw, exists := jsonSpec["env_schema"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+
+	if _, ok := raw.([]interface{}); !ok {
+		return nil, fmt.Errorf("env_schema must be a list")
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("env_schema is not valid JSON: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var schema []models.EnvVarSpec
+	if err := decoder.Decode(&schema); err != nil {
+		return nil, fmt.Errorf("invalid env_schema: %w", err)
+	}
+
+	if err := validateEnvSchema(schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// validateEnvSchemaSpec checks json_spec's environment against its env_schema, if it has one
+func validateEnvSchemaSpec(jsonSpec map[string]interface{}) error {
+	schema, err := parseEnvSchemaSpec(jsonSpec)
+	if err != nil || schema == nil {
+		return err
+	}
+	environment := make(map[string]string)
+	if env, ok := jsonSpec["environment"].(map[string]interface{}); ok {
+		for key, value := range env {
+			if str, ok := value.(string); ok {
+				environment[key] = str
+			}
+		}
+	}
+	_, err = ApplyEnvSchema(schema, environment)
+	return err
+}
+
+// validateEnvSchema checks that every entry is named once and declares a known format
+func validateEnvSchema(schema []models.EnvVarSpec) error {
+	seen := make(map[string]bool, len(schema))
+	for _, variable := range schema {
+		if variable.Name == "" {
+			return fmt.Errorf("env_schema entries need a name")
+		}
+		if seen[variable.Name] {
+			return fmt.Errorf("env_schema declares %s twice", variable.Name)
+		}
+		seen[variable.Name] = true
+		if variable.Format != "" && !slices.Contains(envSchemaFormats, variable.Format) {
+			return fmt.Errorf("env_schema format %q of %s must be one of %s", variable.Format, variable.Name, strings.Join(envSchemaFormats, ", "))
+		}
+		if variable.Default != "" {
+			if problem := envValueProblem(variable, variable.Default); problem != "" {
+				return fmt.Errorf("env_schema default of %s: %s", variable.Name, problem)
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyEnvSchema returns environment with the schema's defaults filled in, or an EnvSchemaError
+// listing every required variable that is missing and every value of the wrong format. Values
+// that reference a secret are only checked for presence, since they are resolved later.
+func ApplyEnvSchema(schema []models.EnvVarSpec, environment map[string]string) (map[string]string, error) {
+	if len(schema) == 0 {
+		return environment, nil
+	}
+	if err := validateEnvSchema(schema); err != nil {
+		return nil, err
+	}
+
+	applied := maps.Clone(environment)
+	if applied == nil {
+		applied = make(map[string]string, len(schema))
+	}
+	var problems []models.EnvVarProblem
+	for _, variable := range schema {
+		value, set := applied[variable.Name]
+		if !set || value == "" {
+			if variable.Default != "" {
+				applied[variable.Name] = variable.Default
+			} else if variable.Required {
+				problems = append(problems, models.EnvVarProblem{Name: variable.Name, Reason: models.EnvProblemMissing, Message: "required but not set"})
+			}
+			continue
+		}
+		if strings.HasPrefix(value, "secret_ref:") {
+			continue
+		}
+		if problem := envValueProblem(variable, value); problem != "" {
+			problems = append(problems, models.EnvVarProblem{Name: variable.Name, Reason: models.EnvProblemInvalid, Message: problem})
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &EnvSchemaError{Problems: problems}
+	}
+	return applied, nil
+}
+
+// envValueProblem describes why value does not fit variable, or returns "" when it does. The
+// value itself is never included, since it may be a credential.
+func envValueProblem(variable models.EnvVarSpec, value string) string {
+	switch variable.Format {
+	case models.EnvFormatNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "must be a number"
+		}
+	case models.EnvFormatBoolean:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return "must be true or false"
+		}
+	case models.EnvFormatFilepath:
+		if strings.ContainsRune(value, 0) {
+			return "must be a file path"
+		}
+	}
+	if len(variable.Choices) > 0 && !slices.Contains(variable.Choices, value) {
+		return "must be one of " + strings.Join(variable.Choices, ", ")
+	}
+	return ""
+}
+
+// discoverEnvSchema restores the environment schema persisted on a podman container
+func (m *Manager) discoverEnvSchema(ctx context.Context, containerID string) []models.EnvVarSpec {
+	var schema []models.EnvVarSpec
+	if !m.discoverJSONLabel(ctx, containerID, envSchemaLabel, &schema) {
+		return nil
+	}
+	return schema
+}
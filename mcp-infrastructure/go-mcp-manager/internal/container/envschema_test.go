@@ -0,0 +1,52 @@
+package container
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestApplyEnvSchema(t *testing.T) {
+	schema := []models.EnvVarSpec{
+		{Name: "API_KEY", Required: true, Secret: true},
+		{Name: "PORT", Format: models.EnvFormatNumber, Default: "8080"},
+		{Name: "MODE", Choices: []string{"fast", "safe"}},
+		{Name: "DEBUG", Format: models.EnvFormatBoolean},
+	}
+
+	environment, err := ApplyEnvSchema(schema, map[string]string{"API_KEY": "secret_ref:api-key", "MODE": "safe"})
+	if err != nil {
+		t.Fatalf("Expected the environment to match, got %v", err)
+	}
+	if environment["PORT"] != "8080" {
+		t.Errorf("Expected the default port to be filled in, got %q", environment["PORT"])
+	}
+
+	_, err = ApplyEnvSchema(schema, map[string]string{"PORT": "eighty", "MODE": "slow", "DEBUG": "true"})
+	var schemaErr *EnvSchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("Expected an EnvSchemaError, got %v", err)
+	}
+	reasons := make(map[string]string)
+	for _, problem := range schemaErr.Problems {
+		reasons[problem.Name] = problem.Reason
+	}
+	expected := map[string]string{"API_KEY": models.EnvProblemMissing, "PORT": models.EnvProblemInvalid, "MODE": models.EnvProblemInvalid}
+	if len(reasons) != len(expected) {
+		t.Errorf("Expected problems %v, got %v", expected, reasons)
+	}
+	for name, reason := range expected {
+		if reasons[name] != reason {
+			t.Errorf("Expected %s to be %s, got %q", name, reason, reasons[name])
+		}
+	}
+	if strings.Contains(err.Error(), "eighty") {
+		t.Errorf("Expected values to stay out of the error, got %q", err.Error())
+	}
+
+	if _, err := parseEnvSchemaSpec(map[string]interface{}{"env_schema": []interface{}{map[string]interface{}{"name": "X", "format": "date"}}}); err == nil {
+		t.Error("Expected an unknown format to be rejected")
+	}
+}
@@ -0,0 +1,226 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// execAuditBucket keeps past exec attempts, keyed so that they sort oldest first
+const execAuditBucket = "exec_audit"
+
+// ExecAudit records an attempt to run a command inside a container, including refused ones
+type ExecAudit struct {
+	ServiceName string   `json:"service_name"`
+	Command     []string `json:"command"`
+	// Caller is the subject of the API key or token, and Source how it authenticated
+	Caller string `json:"caller"`
+	Source string `json:"source,omitempty"`
+	// Allowed is false when the command was refused before it ran
+	Allowed     bool      `json:"allowed"`
+	ExitCode    int       `json:"exit_code"`
+	OutputBytes int       `json:"output_bytes"`
+	Truncated   bool      `json:"truncated,omitempty"`
+	TimedOut    bool      `json:"timed_out,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMS  int64     `json:"duration_ms"`
+	At          time.Time `json:"at"`
+}
+
+// ExecAuditResponse lists recent exec attempts, newest first
+type ExecAuditResponse struct {
+	Records []ExecAudit `json:"records"`
+}
+
+var (
+	// ErrExecDisabled is returned when exec is turned off with EXEC_ENABLED
+	ErrExecDisabled = errors.New("running commands in containers is disabled")
+	// ErrCommandNotAllowed is returned for a command whose program is not allowlisted
+	ErrCommandNotAllowed = errors.New("command is not allowed")
+	// ErrContainerNotRunning is returned when a command is run in a stopped container
+	ErrContainerNotRunning = errors.New("container is not running")
+)
+
+// cappedBuffer keeps the first limit bytes written to it and counts the rest
+type cappedBuffer struct {
+	buffer bytes.Buffer
+	limit  int
+	total  int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.total += len(p)
+	if room := b.limit - b.buffer.Len(); room > 0 {
+		b.buffer.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+// truncated reports whether more was written than kept
+func (b *cappedBuffer) truncated() bool {
+	return b.total > b.buffer.Len()
+}
+
+// checkExecCommand admits a command whose program is allowlisted by name. Paths are refused, so
+// an allowlisted name cannot point at another binary.
+func (m *Manager) checkExecCommand(command []string) error {
+	cfg := m.config.Container.Exec
+	if !cfg.Enabled {
+		return ErrExecDisabled
+	}
+	if len(command) == 0 || command[0] == "" {
+		return fmt.Errorf("%w: the command is empty", ErrCommandNotAllowed)
+	}
+	if strings.ContainsRune(command[0], '/') || !slices.Contains(cfg.AllowedCommands, command[0]) {
+		return fmt.Errorf("%w: %s is not one of %s", ErrCommandNotAllowed, command[0], strings.Join(cfg.AllowedCommands, ", "))
+	}
+	return nil
+}
+
+// ExecContainer runs an allowlisted diagnostic command in a running container without a shell,
+// keeping at most the configured output of each stream. Every attempt, refused or not, is logged
+// and added to the audit log with the caller.
+func (m *Manager) ExecContainer(ctx context.Context, serviceName string, req models.ExecRequest, caller, source string) (*models.ExecResult, error) {
+	m.mutex.RLock()
+	container, exists := m.containers[serviceName]
+	var containerID string
+	var status models.ContainerStatus
+	if exists {
+		containerID = container.ID
+		status = container.Status
+	}
+	m.mutex.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+
+	record := ExecAudit{
+		ServiceName: serviceName,
+		Command:     req.Command,
+		Caller:      caller,
+		Source:      source,
+		ExitCode:    -1,
+		At:          time.Now().UTC(),
+	}
+	if err := m.checkExecCommand(req.Command); err != nil {
+		record.Error = err.Error()
+		m.recordExecAudit(ctx, record)
+		return nil, err
+	}
+	if status != models.StatusRunning || containerID == "" {
+		record.Error = ErrContainerNotRunning.Error()
+		m.recordExecAudit(ctx, record)
+		return nil, fmt.Errorf("%w: %s is %s", ErrContainerNotRunning, serviceName, status)
+	}
+	record.Allowed = true
+
+	cfg := m.config.Container.Exec
+	execCtx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	stdout := &cappedBuffer{limit: cfg.MaxOutputBytes}
+	stderr := &cappedBuffer{limit: cfg.MaxOutputBytes}
+	cmd := podmanCommand(execCtx, m.logger, append([]string{"exec", containerID}, req.Command...)...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	started := time.Now()
+	err := cmd.Run()
+	result := &models.ExecResult{
+		ServiceName: serviceName,
+		Command:     req.Command,
+		Stdout:      stdout.buffer.String(),
+		Stderr:      stderr.buffer.String(),
+		Truncated:   stdout.truncated() || stderr.truncated(),
+		DurationMS:  time.Since(started).Milliseconds(),
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(execCtx.Err(), context.DeadlineExceeded):
+		result.TimedOut = true
+		result.ExitCode = -1
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+	case err != nil:
+		record.Error = err.Error()
+		record.DurationMS = result.DurationMS
+		m.recordExecAudit(ctx, record)
+		return nil, fmt.Errorf("failed to run command in container: %w", err)
+	}
+
+	record.ExitCode = result.ExitCode
+	record.OutputBytes = stdout.total + stderr.total
+	record.Truncated = result.Truncated
+	record.TimedOut = result.TimedOut
+	record.DurationMS = result.DurationMS
+	m.recordExecAudit(ctx, record)
+	return result, nil
+}
+
+// recordExecAudit logs an exec attempt and adds it to the audit log, dropping the oldest entries
+// beyond the configured maximum
+func (m *Manager) recordExecAudit(ctx context.Context, record ExecAudit) {
+	m.logger.WarnContext(ctx, "Command run in container",
+		slog.String("service", record.ServiceName),
+		slog.String("command", strings.Join(record.Command, " ")),
+		slog.String("caller", record.Caller),
+		slog.String("source", record.Source),
+		slog.Bool("allowed", record.Allowed),
+		slog.Int("exit_code", record.ExitCode),
+		slog.Int("output_bytes", record.OutputBytes),
+		slog.String("error", record.Error))
+
+	key := fmt.Sprintf("%020d-%s", record.At.UnixNano(), record.ServiceName)
+	if err := m.store.Put(execAuditBucket, key, record); err != nil {
+		m.logger.WarnContext(ctx, "Failed to add exec to the audit log",
+			slog.String("service", record.ServiceName),
+			slog.String("error", err.Error()))
+		return
+	}
+	keys, err := m.store.Keys(execAuditBucket)
+	if err != nil {
+		return
+	}
+	for _, key := range keys[:max(len(keys)-m.config.Container.Exec.MaxAuditRecords, 0)] {
+		if err := m.store.Delete(execAuditBucket, key); err != nil {
+			m.logger.WarnContext(ctx, "Failed to trim exec audit log", slog.String("error", err.Error()))
+			return
+		}
+	}
+}
+
+// ExecAudit returns up to limit recent exec attempts, newest first, only those in serviceName
+// when it is set
+func (m *Manager) ExecAudit(serviceName string, limit int) (*ExecAuditResponse, error) {
+	records, err := m.store.List(execAuditBucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exec audit log: %w", err)
+	}
+	keys := slices.Sorted(maps.Keys(records))
+
+	response := &ExecAuditResponse{Records: []ExecAudit{}}
+	for i := len(keys) - 1; i >= 0; i-- {
+		if limit > 0 && len(response.Records) >= limit {
+			break
+		}
+		var record ExecAudit
+		if err := json.Unmarshal(records[keys[i]], &record); err != nil {
+			continue
+		}
+		if serviceName == "" || record.ServiceName == serviceName {
+			response.Records = append(response.Records, record)
+		}
+	}
+	return response, nil
+}
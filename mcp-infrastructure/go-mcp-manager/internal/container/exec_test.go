@@ -0,0 +1,51 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestExecGuards(t *testing.T) {
+	manager := newTestManager(&config.Config{
+		Container: config.ContainerConfig{
+			NamePrefix: "test-",
+			Exec:       config.ExecConfig{Enabled: true, AllowedCommands: []string{"cat", "ls"}, MaxAuditRecords: 2},
+		},
+		State: config.StateConfig{Dir: t.TempDir()},
+	})
+	manager.containers["test-search"] = &models.Container{ServiceName: "test-search", ID: "abc", Status: models.StatusStopped}
+	ctx := context.Background()
+
+	for _, command := range [][]string{{"sh", "-c", "cat /etc/passwd"}, {"/tmp/cat"}, {}} {
+		if _, err := manager.ExecContainer(ctx, "test-search", models.ExecRequest{Command: command}, "alice", "api_key"); !errors.Is(err, ErrCommandNotAllowed) {
+			t.Errorf("Expected %v to be refused, got %v", command, err)
+		}
+	}
+	if _, err := manager.ExecContainer(ctx, "test-search", models.ExecRequest{Command: []string{"cat", "/app/config.json"}}, "alice", "api_key"); !errors.Is(err, ErrContainerNotRunning) {
+		t.Errorf("Expected ErrContainerNotRunning, got %v", err)
+	}
+
+	audit, err := manager.ExecAudit("", 0)
+	if err != nil || len(audit.Records) != 2 {
+		t.Fatalf("Expected the audit log to keep the last 2 attempts, got %+v, %v", audit, err)
+	}
+	if latest := audit.Records[0]; latest.Caller != "alice" || latest.Allowed || latest.Command[1] != "/app/config.json" {
+		t.Errorf("Expected the newest attempt first with its caller, got %+v", latest)
+	}
+
+	buffer := &cappedBuffer{limit: 4}
+	_, _ = buffer.Write([]byte("abc"))
+	_, _ = buffer.Write([]byte("def"))
+	if buffer.buffer.String() != "abcd" || !buffer.truncated() || buffer.total != 6 {
+		t.Errorf("Expected output capped at 4 bytes, got %q of %d", buffer.buffer.String(), buffer.total)
+	}
+
+	manager.config.Container.Exec.Enabled = false
+	if _, err := manager.ExecContainer(ctx, "test-search", models.ExecRequest{Command: []string{"ls"}}, "alice", "api_key"); !errors.Is(err, ErrExecDisabled) {
+		t.Errorf("Expected ErrExecDisabled, got %v", err)
+	}
+}
@@ -0,0 +1,283 @@
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// defaultFileMode is the permission of files written without a mode
+const defaultFileMode = 0o644
+
+var (
+	// ErrPathNotAllowed is returned for a file outside the allowed directories
+	ErrPathNotAllowed = errors.New("path is not allowed")
+	// ErrFileTooLarge is returned for a file over the configured size limit
+	ErrFileTooLarge = errors.New("file is too large")
+	// ErrFileNotFound is returned when the file to read does not exist in the container
+	ErrFileNotFound = errors.New("file not found")
+	// ErrInvalidFile is returned for a file to write with malformed content or mode
+	ErrInvalidFile = errors.New("invalid file")
+)
+
+// checkFilePath cleans an absolute container path and admits it when it lies under one of the
+// allowed directories, which it returns along with it. The check is lexical; symlinks in the
+// container are refused by checkNoSymlinks.
+func (m *Manager) checkFilePath(filePath string) (string, string, error) {
+	if !path.IsAbs(filePath) {
+		return "", "", fmt.Errorf("%w: %s is not absolute", ErrPathNotAllowed, filePath)
+	}
+	cleaned := path.Clean(filePath)
+	for _, dir := range m.config.Container.FileAllowedPaths {
+		dir = path.Clean(dir)
+		if cleaned != dir && strings.HasPrefix(cleaned, strings.TrimSuffix(dir, "/")+"/") {
+			return cleaned, dir, nil
+		}
+	}
+	return "", "", fmt.Errorf("%w: %s is not under %s", ErrPathNotAllowed, filePath, strings.Join(m.config.Container.FileAllowedPaths, ", "))
+}
+
+// checkNoSymlinks refuses filePath when the allowed directory dir, a directory below it on the
+// way to the file, or the file itself is a symlink in the container. podman cp follows links in
+// the container's root, so a link could lead a copy outside the allowed directories. A missing
+// entry ends the check, and is reported by the copy itself.
+func (m *Manager) checkNoSymlinks(ctx context.Context, containerID, dir, filePath string) error {
+	current := dir
+	for _, name := range strings.Split(strings.TrimPrefix(filePath, dir+"/"), "/") {
+		header, err := m.containerPathHeader(ctx, containerID, current)
+		if errors.Is(err, ErrFileNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag == tar.TypeSymlink {
+			return fmt.Errorf("%w: %s is a symlink", ErrPathNotAllowed, current)
+		}
+		current = path.Join(current, name)
+	}
+
+	header, err := m.containerPathHeader(ctx, containerID, filePath)
+	switch {
+	case errors.Is(err, ErrFileNotFound):
+		return nil
+	case err != nil:
+		return err
+	case header.Typeflag == tar.TypeSymlink:
+		return fmt.Errorf("%w: %s is a symlink", ErrPathNotAllowed, filePath)
+	}
+	return nil
+}
+
+// containerPathHeader returns the archive header podman cp gives a path in a container, which
+// describes a symlink as the link itself, without copying what the path holds
+func (m *Manager) containerPathHeader(ctx context.Context, containerID, entryPath string) (*tar.Header, error) {
+	copyCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := podmanCommand(copyCtx, m.logger, "cp", containerID+":"+entryPath, "-")
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect %s in container: %w", entryPath, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to inspect %s in container: %w", entryPath, err)
+	}
+
+	header, readErr := tar.NewReader(stdout).Next()
+	cancel()
+	_ = cmd.Wait()
+	if readErr == nil {
+		return header, nil
+	}
+	if strings.Contains(strings.ToLower(stderr.String()), "no such file") {
+		return nil, fmt.Errorf("%w: %s", ErrFileNotFound, entryPath)
+	}
+	return nil, fmt.Errorf("failed to inspect %s in container: %v: %s", entryPath, readErr, strings.TrimSpace(stderr.String()))
+}
+
+// fileContainerID returns the podman container of serviceName, which need not be running
+func (m *Manager) fileContainerID(serviceName string) (string, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	container, exists := m.containers[serviceName]
+	if !exists {
+		return "", fmt.Errorf("container %s not found", serviceName)
+	}
+	if container.ID == "" {
+		return "", fmt.Errorf("container %s has no podman container", serviceName)
+	}
+	return container.ID, nil
+}
+
+// WriteContainerFile copies a file into a container with podman cp, creating or replacing it.
+// The parent directory must exist.
+func (m *Manager) WriteContainerFile(ctx context.Context, serviceName string, file models.ContainerFile) (*models.ContainerFile, error) {
+	filePath, dir, err := m.checkFilePath(file.Path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := decodeFileContent(file)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > m.config.Container.FileMaxBytes {
+		return nil, fmt.Errorf("%w: %d bytes is over the %d byte limit", ErrFileTooLarge, len(content), m.config.Container.FileMaxBytes)
+	}
+	mode := int64(defaultFileMode)
+	if file.Mode != "" {
+		if mode, err = strconv.ParseInt(file.Mode, 8, 32); err != nil || mode < 0 || mode > 0o777 {
+			return nil, fmt.Errorf("%w: mode %q must be octal permissions such as 0644", ErrInvalidFile, file.Mode)
+		}
+	}
+	containerID, err := m.fileContainerID(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkNoSymlinks(ctx, containerID, dir, filePath); err != nil {
+		return nil, err
+	}
+
+	var archive bytes.Buffer
+	writer := tar.NewWriter(&archive)
+	if err := writer.WriteHeader(&tar.Header{
+		Name:    path.Base(filePath),
+		Mode:    mode,
+		Size:    int64(len(content)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to archive file: %w", err)
+	}
+	if _, err := writer.Write(content); err != nil {
+		return nil, fmt.Errorf("failed to archive file: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to archive file: %w", err)
+	}
+
+	cmd := podmanCommand(ctx, m.logger, "cp", "-", containerID+":"+path.Dir(filePath))
+	cmd.Stdin = &archive
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to copy file into container: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	m.logger.InfoContext(ctx, "Copied file into container",
+		slog.String("service", serviceName),
+		slog.String("path", filePath),
+		slog.Int("size_bytes", len(content)))
+
+	return &models.ContainerFile{
+		Path:      filePath,
+		Mode:      fmt.Sprintf("%04o", mode),
+		SizeBytes: int64(len(content)),
+	}, nil
+}
+
+// ReadContainerFile copies a regular file out of a container with podman cp. Text is returned as
+// is and other content base64 encoded.
+func (m *Manager) ReadContainerFile(ctx context.Context, serviceName, filePath string) (*models.ContainerFile, error) {
+	filePath, dir, err := m.checkFilePath(filePath)
+	if err != nil {
+		return nil, err
+	}
+	containerID, err := m.fileContainerID(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkNoSymlinks(ctx, containerID, dir, filePath); err != nil {
+		return nil, err
+	}
+
+	// The archive is read as it streams, so an oversized file is refused without being copied whole
+	copyCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := podmanCommand(copyCtx, m.logger, "cp", containerID+":"+filePath, "-")
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy file out of container: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to copy file out of container: %w", err)
+	}
+
+	file, readErr := readFileArchive(stdout, m.config.Container.FileMaxBytes)
+	cancel()
+	waitErr := cmd.Wait()
+	if readErr == nil {
+		file.Path = filePath
+		return file, nil
+	}
+	if strings.Contains(strings.ToLower(stderr.String()), "no such file") {
+		return nil, fmt.Errorf("%w: %s", ErrFileNotFound, filePath)
+	}
+	if waitErr != nil && !errors.Is(readErr, ErrFileTooLarge) && !errors.Is(readErr, ErrFileNotFound) {
+		return nil, fmt.Errorf("failed to copy file out of container: %w: %s", waitErr, strings.TrimSpace(stderr.String()))
+	}
+	return nil, readErr
+}
+
+// readFileArchive reads the single regular file podman cp writes to stdout as a tar archive
+func readFileArchive(archive io.Reader, maxBytes int64) (*models.ContainerFile, error) {
+	reader := tar.NewReader(archive)
+	header, err := reader.Next()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read copied file: %w", err)
+	}
+	if header.Typeflag == tar.TypeSymlink {
+		return nil, fmt.Errorf("%w: %s is a symlink", ErrPathNotAllowed, header.Name)
+	}
+	if header.Typeflag != tar.TypeReg {
+		return nil, fmt.Errorf("%w: only regular files can be read", ErrFileNotFound)
+	}
+	if header.Size > maxBytes {
+		return nil, fmt.Errorf("%w: %d bytes is over the %d byte limit", ErrFileTooLarge, header.Size, maxBytes)
+	}
+	content, err := io.ReadAll(io.LimitReader(reader, maxBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read copied file: %w", err)
+	}
+
+	file := &models.ContainerFile{
+		Content:   string(content),
+		Encoding:  models.FileEncodingText,
+		Mode:      fmt.Sprintf("%04o", header.Mode&0o777),
+		SizeBytes: int64(len(content)),
+	}
+	if !utf8.Valid(content) {
+		file.Content = base64.StdEncoding.EncodeToString(content)
+		file.Encoding = models.FileEncodingBase64
+	}
+	return file, nil
+}
+
+// decodeFileContent returns the bytes of a file to write
+func decodeFileContent(file models.ContainerFile) ([]byte, error) {
+	switch file.Encoding {
+	case "", models.FileEncodingText:
+		return []byte(file.Content), nil
+	case models.FileEncodingBase64:
+		content, err := base64.StdEncoding.DecodeString(file.Content)
+		if err != nil {
+			return nil, fmt.Errorf("%w: content is not valid base64: %v", ErrInvalidFile, err)
+		}
+		return content, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown encoding %q, must be text or base64", ErrInvalidFile, file.Encoding)
+	}
+}
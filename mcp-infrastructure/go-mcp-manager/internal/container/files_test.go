@@ -0,0 +1,102 @@
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestContainerFileGuards(t *testing.T) {
+	manager := newTestManager(&config.Config{
+		Container: config.ContainerConfig{NamePrefix: "test-", FileAllowedPaths: []string{"/config", "/tmp/"}, FileMaxBytes: 8},
+		State:     config.StateConfig{Dir: t.TempDir()},
+	})
+
+	for filePath, allowed := range map[string]bool{
+		"/config/settings.json":  true,
+		"/tmp/a/b.txt":           true,
+		"/config/../etc/passwd":  false,
+		"/configuration/x.json":  false,
+		"/config":                false,
+		"config/settings.json":   false,
+		"/etc/mcp/settings.json": false,
+	} {
+		_, _, err := manager.checkFilePath(filePath)
+		if allowed && err != nil {
+			t.Errorf("Expected %s to be allowed, got %v", filePath, err)
+		}
+		if !allowed && !errors.Is(err, ErrPathNotAllowed) {
+			t.Errorf("Expected %s to be refused, got %v", filePath, err)
+		}
+	}
+
+	ctx := context.Background()
+	if _, err := manager.WriteContainerFile(ctx, "test-search", models.ContainerFile{Path: "/config/a.json", Content: "123456789"}); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("Expected ErrFileTooLarge, got %v", err)
+	}
+	if _, err := manager.WriteContainerFile(ctx, "test-search", models.ContainerFile{Path: "/config/a.bin", Content: "!!", Encoding: "base64"}); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("Expected ErrInvalidFile for malformed base64, got %v", err)
+	}
+	if _, err := manager.WriteContainerFile(ctx, "test-search", models.ContainerFile{Path: "/config/a.json", Content: "{}", Mode: "999"}); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("Expected ErrInvalidFile for a non-octal mode, got %v", err)
+	}
+
+	var archive bytes.Buffer
+	writer := tar.NewWriter(&archive)
+	_ = writer.WriteHeader(&tar.Header{Name: "key.bin", Mode: 0o600, Size: 3})
+	_, _ = writer.Write([]byte{0xff, 0x00, 0x01})
+	_ = writer.Close()
+	file, err := readFileArchive(bytes.NewReader(archive.Bytes()), 8)
+	if err != nil || file.Encoding != models.FileEncodingBase64 || file.Content != "/wAB" || file.Mode != "0600" {
+		t.Errorf("Expected binary content base64 encoded, got %+v, %v", file, err)
+	}
+	if _, err := readFileArchive(bytes.NewReader(archive.Bytes()), 2); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("Expected ErrFileTooLarge for a file over the limit, got %v", err)
+	}
+
+	// podman cp follows symlinks inside the container, so the path is refused when any part is one
+	bin := t.TempDir()
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	for name, header := range map[string]*tar.Header{
+		"dir.tar":  {Name: "config", Typeflag: tar.TypeDir, Mode: 0o755},
+		"link.tar": {Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc"},
+	} {
+		var entry bytes.Buffer
+		writer := tar.NewWriter(&entry)
+		_ = writer.WriteHeader(header)
+		_ = writer.Close()
+		if err := os.WriteFile(filepath.Join(bin, name), entry.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	script := "#!/bin/sh\ncase \"$2\" in\n" +
+		"*:/config/link*) cat " + filepath.Join(bin, "link.tar") + " ;;\n" +
+		"*:/config/new.json) echo 'Error: no such file or directory' >&2; exit 125 ;;\n" +
+		"*) cat " + filepath.Join(bin, "dir.tar") + " ;;\nesac\n"
+	if err := os.WriteFile(filepath.Join(bin, "podman"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := manager.checkNoSymlinks(ctx, "abc123", "/config", "/config/app/settings.json"); err != nil {
+		t.Errorf("Expected a path through directories to be allowed, got %v", err)
+	}
+	if err := manager.checkNoSymlinks(ctx, "abc123", "/config", "/config/new.json"); err != nil {
+		t.Errorf("Expected a file that does not exist yet to be allowed, got %v", err)
+	}
+	for _, filePath := range []string{"/config/link", "/config/link/shadow"} {
+		if err := manager.checkNoSymlinks(ctx, "abc123", "/config", filePath); !errors.Is(err, ErrPathNotAllowed) {
+			t.Errorf("Expected %s through a symlink to be refused, got %v", filePath, err)
+		}
+	}
+	manager.containers["test-search"] = &models.Container{ServiceName: "test-search", ID: "abc123"}
+	if _, err := manager.WriteContainerFile(ctx, "test-search", models.ContainerFile{Path: "/config/link/passwd", Content: "x"}); !errors.Is(err, ErrPathNotAllowed) {
+		t.Errorf("Expected a write through a symlink to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,323 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// imageUsageBucket records when each local image was last seen in use, keyed by image ID
+const imageUsageBucket = "image_usage"
+
+// ErrGCRunning is returned when a garbage collection is requested while one is in progress
+var ErrGCRunning = errors.New("garbage collection is already running")
+
+// gcState serializes garbage collection runs and keeps the last report
+type gcState struct {
+	running sync.Mutex
+	mutex   sync.RWMutex
+	last    *models.GCReport
+}
+
+// podmanImage is the subset of `podman images --format json` GC needs
+type podmanImage struct {
+	ID          string   `json:"Id"`
+	Names       []string `json:"Names"`
+	RepoDigests []string `json:"RepoDigests"`
+	Size        int64    `json:"Size"`
+	Containers  int      `json:"Containers"`
+	Dangling    bool     `json:"Dangling"`
+}
+
+// startGC periodically removes images that no container has used for the configured time
+func (m *Manager) startGC() {
+	if m.config.GC.Interval <= 0 {
+		return
+	}
+
+	m.logger.Info("Image garbage collection enabled",
+		slog.Duration("interval", m.config.GC.Interval),
+		slog.Duration("unused_for", m.config.GC.UnusedFor),
+		slog.Float64("disk_threshold_percent", m.config.GC.DiskThresholdPercent))
+
+	ticker := time.NewTicker(m.config.GC.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.healthCtx.Done():
+			return
+		case <-ticker.C:
+			if _, err := m.RunGC(m.healthCtx, false, false); err != nil && !errors.Is(err, ErrGCRunning) {
+				m.logger.Error("Image garbage collection failed",
+					slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+// RunGC removes images unused for the configured time, then dangling layers and build cache.
+// Unless force is set, nothing is removed while image storage usage is below the threshold.
+// With dryRun the report lists what would be removed without removing it.
+func (m *Manager) RunGC(ctx context.Context, force, dryRun bool) (*models.GCReport, error) {
+	if !m.gc.running.TryLock() {
+		return nil, ErrGCRunning
+	}
+	defer m.gc.running.Unlock()
+
+	report := &models.GCReport{
+		StartedAt:     time.Now(),
+		DryRun:        dryRun,
+		RemovedImages: []models.UnusedImage{},
+	}
+
+	storageRoot := m.imageStorageRoot(ctx)
+	before, usageErr := filesystemUsage(storageRoot)
+	if usageErr == nil {
+		report.DiskUsageBefore = before.UsedPercent
+	}
+
+	threshold := m.config.GC.DiskThresholdPercent
+	if !force && threshold > 0 && usageErr == nil && before.UsedPercent < threshold {
+		report.Skipped = fmt.Sprintf("image storage usage %.1f%% is below the %.0f%% threshold", before.UsedPercent, threshold)
+		return m.finishGC(report), nil
+	}
+
+	unused, err := m.unusedImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var removedBytes uint64
+	for _, image := range unused {
+		if !dryRun {
+			output, err := podmanCommand(ctx, m.logger, "rmi", image.ID).CombinedOutput()
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("failed to remove image %s: %s", image.ID, strings.TrimSpace(string(output))))
+				continue
+			}
+			if err := m.store.Delete(imageUsageBucket, image.ID); err != nil {
+				m.logger.WarnContext(ctx, "Failed to clear image usage record",
+					slog.String("image_id", image.ID),
+					slog.String("error", err.Error()))
+			}
+		}
+		report.RemovedImages = append(report.RemovedImages, image)
+		removedBytes += image.Size
+	}
+
+	if !dryRun {
+		pruned, err := m.pruneDanglingImages(ctx)
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		}
+		report.PrunedDangling = pruned
+	}
+
+	report.ReclaimedBytes = removedBytes
+	if after, err := filesystemUsage(storageRoot); err == nil && usageErr == nil && !dryRun {
+		report.DiskUsageAfter = after.UsedPercent
+		if after.AvailableBytes > before.AvailableBytes {
+			report.ReclaimedBytes = after.AvailableBytes - before.AvailableBytes
+		}
+	}
+
+	m.logger.InfoContext(ctx, "Image garbage collection completed",
+		slog.Bool("dry_run", dryRun),
+		slog.Int("removed_images", len(report.RemovedImages)),
+		slog.Int("pruned_dangling", report.PrunedDangling),
+		slog.Uint64("reclaimed_bytes", report.ReclaimedBytes),
+		slog.Int("errors", len(report.Errors)))
+
+	return m.finishGC(report), nil
+}
+
+// finishGC stamps and keeps a report as the last run
+func (m *Manager) finishGC(report *models.GCReport) *models.GCReport {
+	report.FinishedAt = time.Now()
+
+	m.gc.mutex.Lock()
+	m.gc.last = report
+	m.gc.mutex.Unlock()
+	return report
+}
+
+// LastGCReport returns the report of the most recent garbage collection, or nil if none ran
+func (m *Manager) LastGCReport() *models.GCReport {
+	m.gc.mutex.RLock()
+	defer m.gc.mutex.RUnlock()
+	return m.gc.last
+}
+
+// unusedImages lists local images not used for the configured time. Images in use by any podman
+// container, or referenced by a managed or archived container, are marked as used now; images seen
+// unused for the first time start their unused period now.
+func (m *Manager) unusedImages(ctx context.Context) ([]models.UnusedImage, error) {
+	output, err := podmanCommand(ctx, m.logger, "images", "--format", "json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+
+	var images []podmanImage
+	if err := json.Unmarshal(output, &images); err != nil {
+		return nil, fmt.Errorf("failed to parse image list: %w", err)
+	}
+
+	referenced := m.referencedImages()
+	now := time.Now()
+	present := make(map[string]bool, len(images))
+	var unused []models.UnusedImage
+
+	for _, image := range images {
+		present[image.ID] = true
+		// Dangling images are removed by the prune step
+		if image.Dangling {
+			continue
+		}
+
+		if image.Containers > 0 || imageReferenced(image, referenced) {
+			if err := m.store.Put(imageUsageBucket, image.ID, now); err != nil {
+				return nil, fmt.Errorf("failed to record image usage: %w", err)
+			}
+			continue
+		}
+
+		var lastUsed time.Time
+		found, err := m.store.Get(imageUsageBucket, image.ID, &lastUsed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image usage: %w", err)
+		}
+		if !found {
+			if err := m.store.Put(imageUsageBucket, image.ID, now); err != nil {
+				return nil, fmt.Errorf("failed to record image usage: %w", err)
+			}
+			continue
+		}
+
+		if now.Sub(lastUsed) >= m.config.GC.UnusedFor {
+			unused = append(unused, models.UnusedImage{
+				ID:       image.ID,
+				Names:    image.Names,
+				Size:     uint64(max(image.Size, 0)),
+				LastUsed: lastUsed,
+			})
+		}
+	}
+
+	// Forget images removed outside the manager
+	if ids, err := m.store.Keys(imageUsageBucket); err == nil {
+		for _, id := range ids {
+			if !present[id] {
+				_ = m.store.Delete(imageUsageBucket, id)
+			}
+		}
+	}
+
+	return unused, nil
+}
+
+// referencedImages returns the normalized image references of managed and archived containers
+func (m *Manager) referencedImages() map[string]bool {
+	referenced := make(map[string]bool)
+
+	m.mutex.RLock()
+	for _, container := range m.containers {
+		referenced[normalizeImageRef(container.Image)] = true
+		for _, dependency := range container.DependsOn {
+			if dependency.Image != "" {
+				referenced[normalizeImageRef(dependency.Image)] = true
+			}
+		}
+		if container.Init != nil && container.Init.Image != "" {
+			referenced[normalizeImageRef(container.Init.Image)] = true
+		}
+	}
+	m.mutex.RUnlock()
+
+	// Archived containers are restored from their image, so it must outlive them
+	if archived, err := m.ListArchived(); err == nil {
+		for _, container := range archived {
+			referenced[normalizeImageRef(container.Image)] = true
+		}
+	}
+	return referenced
+}
+
+// imageReferenced reports whether any name or digest of an image is in the referenced set
+func imageReferenced(image podmanImage, referenced map[string]bool) bool {
+	for _, name := range slices.Concat(image.Names, image.RepoDigests) {
+		if referenced[normalizeImageRef(name)] {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeImageRef expands short image references the way podman resolves them against
+// docker.io, e.g. "redis" to "docker.io/library/redis:latest"
+func normalizeImageRef(ref string) string {
+	if ref == "" {
+		return ""
+	}
+
+	name := ref
+	if slash := strings.Index(name, "/"); slash < 0 {
+		name = "docker.io/library/" + name
+	} else if first := name[:slash]; !strings.ContainsAny(first, ".:") && first != "localhost" {
+		name = "docker.io/" + name
+	}
+
+	// Untagged references without a digest mean :latest
+	if !strings.Contains(name, "@") && strings.LastIndex(name, ":") <= strings.LastIndex(name, "/") {
+		name += ":latest"
+	}
+	return name
+}
+
+// pruneDanglingImages removes dangling layers and, if configured, the build cache
+func (m *Manager) pruneDanglingImages(ctx context.Context) (int, error) {
+	args := []string{"image", "prune", "-f"}
+	if m.config.GC.PruneBuildCache {
+		args = append(args, "--build-cache")
+	}
+
+	output, err := podmanCommand(ctx, m.logger, args...).CombinedOutput()
+	if err != nil && m.config.GC.PruneBuildCache {
+		// Older podman versions have no --build-cache flag
+		output, err = podmanCommand(ctx, m.logger, "image", "prune", "-f").CombinedOutput()
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune dangling images: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	pruned := 0
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.TrimSpace(line) != "" {
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+// imageStorageRoot returns the directory podman stores images in
+func (m *Manager) imageStorageRoot(ctx context.Context) string {
+	if root := m.config.Container.StorageGraphroot; root != "" {
+		return root
+	}
+	output, err := podmanCommand(ctx, m.logger, "info", "--format", "{{.Store.GraphRoot}}").Output()
+	if err != nil {
+		return "/"
+	}
+	if root := strings.TrimSpace(string(output)); root != "" {
+		return root
+	}
+	return "/"
+}
@@ -0,0 +1,53 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+)
+
+func TestImageGC(t *testing.T) {
+	refs := map[string]string{
+		"redis":                         "docker.io/library/redis:latest",
+		"qdrant/qdrant:v1.9.0":          "docker.io/qdrant/qdrant:v1.9.0",
+		"ghcr.io/org/server":            "ghcr.io/org/server:latest",
+		"localhost:5000/server:1":       "localhost:5000/server:1",
+		"localhost/mcp-build/search:ab": "localhost/mcp-build/search:ab",
+	}
+	for ref, expected := range refs {
+		if normalized := normalizeImageRef(ref); normalized != expected {
+			t.Errorf("Expected %s to normalize to %s, got %s", ref, expected, normalized)
+		}
+	}
+
+	referenced := map[string]bool{normalizeImageRef("redis:7"): true}
+	if !imageReferenced(podmanImage{Names: []string{"docker.io/library/redis:7"}}, referenced) {
+		t.Errorf("Expected redis:7 to match its fully qualified name")
+	}
+	if imageReferenced(podmanImage{Names: []string{"docker.io/library/redis:6"}}, referenced) {
+		t.Errorf("Expected redis:6 not to be referenced")
+	}
+
+	cfg := &config.Config{GC: config.GCConfig{DiskThresholdPercent: 101, UnusedFor: time.Hour}}
+	manager := newTestManager(cfg)
+	if manager.LastGCReport() != nil {
+		t.Errorf("Expected no GC report before the first run")
+	}
+
+	report, err := manager.RunGC(context.Background(), false, false)
+	if err != nil {
+		t.Fatalf("Expected GC below the threshold to be skipped, got %v", err)
+	}
+	if report.Skipped == "" || len(report.RemovedImages) != 0 || manager.LastGCReport() != report {
+		t.Errorf("Expected a skipped run to be reported, got %+v", report)
+	}
+
+	manager.gc.running.Lock()
+	if _, err := manager.RunGC(context.Background(), true, false); !errors.Is(err, ErrGCRunning) {
+		t.Errorf("Expected ErrGCRunning while a run is in progress, got %v", err)
+	}
+	manager.gc.running.Unlock()
+}
@@ -0,0 +1,218 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// devicesLabel stores a container's requested and assigned devices on the podman container
+const devicesLabel = "mcp.devices"
+
+// maxGPUsPerContainer bounds the gpus field in json_spec
+const maxGPUsPerContainer = 16
+
+// cdiDevicePattern matches fully-qualified CDI device names such as "nvidia.com/gpu=0"
+var cdiDevicePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]*/[a-z0-9][a-z0-9._-]*=[A-Za-z0-9][A-Za-z0-9._:-]*$`)
+
+// deviceAssignment is the persisted form of a container's device configuration
+type deviceAssignment struct {
+	GPUs       int      `json:"gpus,omitempty"`
+	Devices    []string `json:"devices,omitempty"`
+	GPUDevices []string `json:"gpu_devices,omitempty"`
+}
+
+// parseGPUSpec reads the optional gpus count and devices list from json_spec
+func parseGPUSpec(jsonSpec map[string]interface{}) (int, []string, error) {
+	gpus := 0
+	if raw, exists := jsonSpec["gpus"]; exists && raw != nil {
+		n, ok := raw.(float64)
+		if !ok || n != float64(int(n)) || n < 0 || n > maxGPUsPerContainer {
+			return 0, nil, fmt.Errorf("gpus must be an integer between 0 and %d", maxGPUsPerContainer)
+		}
+		gpus = int(n)
+	}
+
+	var devices []string
+	if raw, exists := jsonSpec["devices"]; exists && raw != nil {
+		list, ok := raw.([]interface{})
+		if !ok {
+			return 0, nil, fmt.Errorf("devices must be an array of strings")
+		}
+		for _, item := range list {
+			device, ok := item.(string)
+			if !ok {
+				return 0, nil, fmt.Errorf("devices must be an array of strings")
+			}
+			devices = append(devices, device)
+		}
+	}
+
+	if err := validateDevices(devices); err != nil {
+		return 0, nil, err
+	}
+
+	return gpus, devices, nil
+}
+
+// validateDevices accepts CDI device names and host /dev paths with optional permissions
+func validateDevices(devices []string) error {
+	seen := make(map[string]bool, len(devices))
+	for _, device := range devices {
+		if seen[device] {
+			return fmt.Errorf("device %s is listed more than once", device)
+		}
+		seen[device] = true
+
+		if cdiDevicePattern.MatchString(device) {
+			continue
+		}
+
+		path, perms, _ := strings.Cut(device, ":")
+		if !strings.HasPrefix(path, "/dev/") || strings.Contains(path, "..") {
+			return fmt.Errorf("device %s must be a CDI name (vendor/class=name) or a /dev path", device)
+		}
+		// Host and container paths may differ, as in /dev/foo:/dev/bar:rw
+		if strings.HasPrefix(perms, "/dev/") {
+			_, perms, _ = strings.Cut(perms, ":")
+		}
+		if strings.Trim(perms, "rwm") != "" {
+			return fmt.Errorf("device %s has invalid permissions, expected a combination of r, w and m", device)
+		}
+	}
+	return nil
+}
+
+// gpuIndices returns the pool indices a device claims: one for "<prefix>=N", all for "<prefix>=all"
+func (m *Manager) gpuIndices(device string) []int {
+	name, found := strings.CutPrefix(device, m.config.GPU.CDIPrefix+"=")
+	if !found {
+		return nil
+	}
+	if name == "all" {
+		indices := make([]int, m.config.GPU.Count)
+		for i := range indices {
+			indices[i] = i
+		}
+		return indices
+	}
+	index, err := strconv.Atoi(name)
+	if err != nil {
+		return nil
+	}
+	return []int{index}
+}
+
+// claimedDevices returns the assigned GPUs and explicitly requested devices of a container
+func claimedDevices(container *models.Container) []string {
+	devices := make([]string, 0, len(container.GPUDevices)+len(container.Devices))
+	devices = append(devices, container.GPUDevices...)
+	return append(devices, container.Devices...)
+}
+
+// gpusInUseUnsafe maps each claimed GPU index to the service holding it (caller must hold lock)
+func (m *Manager) gpusInUseUnsafe() map[int]string {
+	inUse := make(map[int]string)
+	for serviceName, container := range m.containers {
+		for _, device := range claimedDevices(container) {
+			for _, index := range m.gpuIndices(device) {
+				inUse[index] = serviceName
+			}
+		}
+	}
+	return inUse
+}
+
+// allocateGPUsUnsafe reserves count GPUs plus any GPUs named explicitly in devices, returning
+// the CDI names to pass to podman. GPUs serviceName already holds count as free, so a container
+// replaced with a new spec can keep them. It must be called with the lock held.
+func (m *Manager) allocateGPUsUnsafe(serviceName string, count int, devices []string) ([]string, error) {
+	capacity := m.config.GPU.Count
+	inUse := m.gpusInUseUnsafe()
+
+	claimed := make(map[int]bool)
+	for _, device := range devices {
+		for _, index := range m.gpuIndices(device) {
+			if index < 0 || index >= capacity {
+				return nil, fmt.Errorf("device %s is outside the host's %d GPUs", device, capacity)
+			}
+			if holder, exists := inUse[index]; exists && holder != serviceName {
+				return nil, fmt.Errorf("device %s is already assigned to %s", device, holder)
+			}
+			claimed[index] = true
+		}
+	}
+
+	if count == 0 {
+		return nil, nil
+	}
+
+	assigned := make([]string, 0, count)
+	for index := 0; index < capacity && len(assigned) < count; index++ {
+		if holder, exists := inUse[index]; exists && holder != serviceName || claimed[index] {
+			continue
+		}
+		assigned = append(assigned, fmt.Sprintf("%s=%d", m.config.GPU.CDIPrefix, index))
+	}
+	if len(assigned) < count {
+		return nil, fmt.Errorf("insufficient GPU capacity: requested %d, %d of %d available",
+			count, len(assigned), capacity)
+	}
+
+	return assigned, nil
+}
+
+// checkGPUsAvailableUnsafe verifies a container's previously assigned GPUs are still free (caller must hold lock)
+func (m *Manager) checkGPUsAvailableUnsafe(container *models.Container) error {
+	inUse := m.gpusInUseUnsafe()
+	for _, device := range claimedDevices(container) {
+		for _, index := range m.gpuIndices(device) {
+			if holder, exists := inUse[index]; exists && holder != container.ServiceName {
+				return fmt.Errorf("GPU %s is now assigned to %s", device, holder)
+			}
+		}
+	}
+	return nil
+}
+
+// GetGPUCapacity reports how much of the host's GPU pool is assigned
+func (m *Manager) GetGPUCapacity() models.GPUCapacity {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	inUse := m.gpusInUseUnsafe()
+	capacity := models.GPUCapacity{
+		Total:       m.config.GPU.Count,
+		Assignments: make(map[string][]string),
+	}
+
+	indices := make([]int, 0, len(inUse))
+	for index := range inUse {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+
+	for _, index := range indices {
+		serviceName := inUse[index]
+		capacity.Assignments[serviceName] = append(capacity.Assignments[serviceName],
+			fmt.Sprintf("%s=%d", m.config.GPU.CDIPrefix, index))
+		if index < capacity.Total {
+			capacity.Allocated++
+		}
+	}
+	capacity.Available = capacity.Total - capacity.Allocated
+
+	return capacity
+}
+
+// discoverDevices restores the device assignment persisted on a podman container
+func (m *Manager) discoverDevices(ctx context.Context, containerID string) deviceAssignment {
+	var assignment deviceAssignment
+	m.discoverJSONLabel(ctx, containerID, devicesLabel, &assignment)
+	return assignment
+}
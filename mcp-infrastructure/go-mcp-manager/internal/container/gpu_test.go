@@ -0,0 +1,64 @@
+package container
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestGPUAllocation(t *testing.T) {
+	cfg := &config.Config{
+		GPU: config.GPUConfig{Count: 2, CDIPrefix: "nvidia.com/gpu"},
+	}
+	manager := newTestManager(cfg)
+
+	if _, _, err := parseGPUSpec(map[string]interface{}{"devices": []interface{}{"/etc/passwd"}}); err == nil {
+		t.Errorf("Expected non-device path to be rejected")
+	}
+
+	assigned, err := manager.allocateGPUsUnsafe("llm", 1, []string{"nvidia.com/gpu=1"})
+	if err != nil {
+		t.Fatalf("Failed to allocate GPU: %v", err)
+	}
+	if len(assigned) != 1 || assigned[0] != "nvidia.com/gpu=0" {
+		t.Errorf("Expected nvidia.com/gpu=0 to be assigned, got %v", assigned)
+	}
+	manager.containers["llm"] = &models.Container{
+		ServiceName: "llm",
+		GPUs:        1,
+		Devices:     []string{"nvidia.com/gpu=1"},
+		GPUDevices:  assigned,
+	}
+
+	if _, err := manager.allocateGPUsUnsafe("embedder", 1, nil); err == nil {
+		t.Errorf("Expected allocation to fail when all GPUs are assigned")
+	}
+
+	args := manager.buildPodmanRunArgs(manager.containers["llm"])
+	devices := 0
+	for i, arg := range args {
+		if arg == "--device" && i+1 < len(args) && strings.HasPrefix(args[i+1], "nvidia.com/gpu=") {
+			devices++
+		}
+	}
+	if devices != 2 {
+		t.Errorf("Expected 2 GPU devices passed to podman, got %d in %v", devices, args)
+	}
+
+	capacity := manager.GetGPUCapacity()
+	if capacity.Allocated != 2 || capacity.Available != 0 {
+		t.Errorf("Expected 2 allocated and 0 available, got %+v", capacity)
+	}
+
+	// A container replaced with a new spec keeps the GPUs it holds
+	if reassigned, err := manager.allocateGPUsUnsafe("llm", 1, []string{"nvidia.com/gpu=1"}); err != nil || len(reassigned) != 1 || reassigned[0] != "nvidia.com/gpu=0" {
+		t.Errorf("Expected llm to keep nvidia.com/gpu=0, got %v, %v", reassigned, err)
+	}
+
+	delete(manager.containers, "llm")
+	if _, err := manager.allocateGPUsUnsafe("embedder", 2, nil); err != nil {
+		t.Errorf("Expected GPUs to be released when the container is removed, got %v", err)
+	}
+}
@@ -5,18 +5,22 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
-	"os/exec"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/agentarea/mcp-manager/internal/models"
+	"github.com/agentarea/mcp-manager/internal/chaos"
+	"github.com/agentarea/mcp-manager/pkg/models"
 )
 
 // HealthChecker handles health checks for MCP containers
 type HealthChecker struct {
 	logger     *slog.Logger
 	httpClient *http.Client
+	inspect    *inspectCache
+	chaos      *chaos.Controller
+	// publishPorts probes the loopback port the engine published instead of the container IP
+	publishPorts bool
 }
 
 // NewHealthChecker creates a new health checker
@@ -26,6 +30,7 @@ func NewHealthChecker(logger *slog.Logger) *HealthChecker {
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		inspect: newInspectCache(0, logger),
 	}
 }
 
@@ -36,15 +41,22 @@ type HealthCheckResult struct {
 	Healthy       bool                   `json:"healthy"`
 	Status        models.ContainerStatus `json:"status"`
 	HTTPReachable bool                   `json:"http_reachable"`
-	ResponseTime  time.Duration          `json:"response_time"`
-	Error         string                 `json:"error,omitempty"`
-	Timestamp     time.Time              `json:"timestamp"`
-	Details       map[string]interface{} `json:"details,omitempty"`
+	// Live is set while the container's process runs, Ready while the server also answers the MCP
+	// handshake; ReadinessError says why it is not ready
+	Live           bool                   `json:"live"`
+	Ready          bool                   `json:"ready"`
+	ReadinessError string                 `json:"readiness_error,omitempty"`
+	ResponseTime   time.Duration          `json:"response_time"`
+	Error          string                 `json:"error,omitempty"`
+	Timestamp      time.Time              `json:"timestamp"`
+	Details        map[string]interface{} `json:"details,omitempty"`
+	// inspect is the podman inspect result the status was read from; zero when it failed
+	inspect inspectEntry
 }
 
 // PerformHealthCheck performs a comprehensive health check on a container
 func (h *HealthChecker) PerformHealthCheck(ctx context.Context, container *models.Container) (*HealthCheckResult, error) {
-	h.logger.Info("Performing health check",
+	h.logger.InfoContext(ctx, "Performing health check",
 		slog.String("container", container.Name),
 		slog.String("service", container.ServiceName))
 
@@ -56,24 +68,27 @@ func (h *HealthChecker) PerformHealthCheck(ctx context.Context, container *model
 	}
 
 	// Check real-time container status from Podman
-	realTimeStatus := h.getRealTimeContainerStatus(ctx, container)
+	realTimeStatus, entry := h.getRealTimeContainerStatus(ctx, container)
 	result.Status = realTimeStatus
+	result.inspect = entry
 
 	// Check container health based on real-time status
 	containerHealthy := h.checkContainerStatusRealTime(realTimeStatus)
 	result.Healthy = containerHealthy
 
 	if !containerHealthy {
-		result.Error = "Container is not running"
+		result.Error = entry.exitReason()
+		result.ReadinessError = result.Error
 		return result, nil
 	}
+	result.Live = realTimeStatus == models.StatusRunning
 
 	// Perform HTTP health check if container is running
 	if realTimeStatus == models.StatusRunning {
 		// Get container IP for direct access instead of using proxy URL
 		containerIP, err := h.getContainerIP(ctx, container.ID)
 		if err != nil {
-			h.logger.Warn("Failed to get container IP for health check",
+			h.logger.WarnContext(ctx, "Failed to get container IP for health check",
 				slog.String("container", container.Name),
 				slog.String("error", err.Error()))
 			// If we can't get IP, skip HTTP health check but consider container healthy since it's running
@@ -81,10 +96,15 @@ func (h *HealthChecker) PerformHealthCheck(ctx context.Context, container *model
 			result.HTTPReachable = false
 			result.Error = "Could not determine container IP for health check"
 		} else {
-			// Get the container's internal exposed port
-			internalPort, err := h.getContainerExposedPort(ctx, container.ID)
+			// Use the configured health check port, falling back to the container's exposed port
+			var internalPort int
+			if container.HealthCheck != nil && container.HealthCheck.Port > 0 {
+				internalPort = container.HealthCheck.Port
+			} else {
+				internalPort, err = h.getContainerExposedPort(ctx, container.ID)
+			}
 			if err != nil {
-				h.logger.Warn("Failed to get container exposed port for health check",
+				h.logger.WarnContext(ctx, "Failed to get container exposed port for health check",
 					slog.String("container", container.Name),
 					slog.String("error", err.Error()))
 				// Skip HTTP health check but consider container healthy since it's running
@@ -93,9 +113,22 @@ func (h *HealthChecker) PerformHealthCheck(ctx context.Context, container *model
 				result.Error = "Could not determine container exposed port for health check"
 			} else {
 				// Construct direct URL to container using internal port
-				directURL := fmt.Sprintf("http://%s:%d", containerIP, internalPort)
+				probeHost, probePort := containerIP, internalPort
+				if h.publishPorts {
+					if host, port, err := publishedAddress(ctx, h.logger, container.ID, internalPort); err == nil {
+						probeHost, probePort = host, port
+					}
+				}
+				directURL := fmt.Sprintf("%s://%s:%d", upstreamScheme(container), probeHost, probePort)
+				expectedStatus := 0
+				if container.HealthCheck != nil {
+					directURL += container.HealthCheck.Path
+					expectedStatus = container.HealthCheck.ExpectedStatus
+				}
 
-				httpHealthy, responseTime, err := h.checkHTTPEndpoint(ctx, directURL)
+				probeCtx, cancel := context.WithTimeout(ctx, healthTimeout(container.HealthCheck))
+				httpHealthy, responseTime, err := h.checkHTTPEndpoint(probeCtx, directURL, expectedStatus)
+				cancel()
 				result.HTTPReachable = httpHealthy
 				result.ResponseTime = responseTime
 
@@ -110,20 +143,39 @@ func (h *HealthChecker) PerformHealthCheck(ctx context.Context, container *model
 				result.Details["direct_http_endpoint"] = directURL
 				result.Details["internal_port"] = internalPort
 				result.Details["response_time_ms"] = responseTime.Milliseconds()
+
+				if !result.Healthy {
+					result.ReadinessError = result.Error
+				} else if err := h.probeReadiness(ctx, container, probeHost, probePort); err != nil {
+					result.ReadinessError = err.Error()
+				}
 			}
 		}
+		if result.ReadinessError == "" && result.Error != "" {
+			// The endpoint to probe could not be determined
+			result.ReadinessError = result.Error
+		}
+		result.Ready = result.ReadinessError == ""
 
 		// Always include the proxy URL for reference
 		result.Details["proxy_url"] = container.URL
 	}
 
+	if h.chaos.FailHealthCheck(container.ServiceName) {
+		result.Healthy = false
+		result.HTTPReachable = false
+		result.Error = "chaos: injected health check failure"
+		result.Ready = false
+		result.ReadinessError = result.Error
+	}
+
 	// Add additional container details
 	result.Details["container_port"] = container.Port
 	result.Details["container_image"] = container.Image
 	result.Details["created_at"] = container.CreatedAt
 	result.Details["updated_at"] = container.UpdatedAt
 
-	h.logger.Info("Health check completed",
+	h.logger.InfoContext(ctx, "Health check completed",
 		slog.String("container", container.Name),
 		slog.Bool("healthy", result.Healthy),
 		slog.Bool("http_reachable", result.HTTPReachable),
@@ -132,23 +184,21 @@ func (h *HealthChecker) PerformHealthCheck(ctx context.Context, container *model
 	return result, nil
 }
 
-// getRealTimeContainerStatus gets the real-time status from Podman
-func (h *HealthChecker) getRealTimeContainerStatus(ctx context.Context, container *models.Container) models.ContainerStatus {
+// getRealTimeContainerStatus gets the real-time status from Podman, with the inspect result it was read from
+func (h *HealthChecker) getRealTimeContainerStatus(ctx context.Context, container *models.Container) (models.ContainerStatus, inspectEntry) {
 	if container.ID == "" {
-		return models.StatusError
+		return models.StatusError, inspectEntry{}
 	}
 
-	cmd := exec.CommandContext(ctx, "podman", "inspect", container.ID, "--format", "{{.State.Status}}")
-	output, err := cmd.CombinedOutput()
+	entry, err := h.inspect.get(ctx, container.ID)
 	if err != nil {
-		h.logger.Error("Failed to get real-time container status",
+		h.logger.ErrorContext(ctx, "Failed to get real-time container status",
 			slog.String("container", container.Name),
 			slog.String("error", err.Error()))
-		return models.StatusError
+		return models.StatusError, inspectEntry{}
 	}
 
-	podmanStatus := strings.TrimSpace(string(output))
-	return h.mapPodmanStatus(podmanStatus)
+	return h.mapPodmanStatus(entry.state), entry
 }
 
 // mapPodmanStatus maps Podman status to our container status
@@ -180,8 +230,9 @@ func (h *HealthChecker) checkContainerStatusRealTime(status models.ContainerStat
 	}
 }
 
-// checkHTTPEndpoint checks if the HTTP endpoint is reachable
-func (h *HealthChecker) checkHTTPEndpoint(ctx context.Context, url string) (bool, time.Duration, error) {
+// checkHTTPEndpoint checks if the HTTP endpoint is reachable and answers with the expected status.
+// An expectedStatus of zero accepts any 2xx or 3xx response.
+func (h *HealthChecker) checkHTTPEndpoint(ctx context.Context, url string, expectedStatus int) (bool, time.Duration, error) {
 	start := time.Now()
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -197,6 +248,13 @@ func (h *HealthChecker) checkHTTPEndpoint(ctx context.Context, url string) (bool
 	}
 	defer resp.Body.Close()
 
+	if expectedStatus != 0 {
+		if resp.StatusCode != expectedStatus {
+			return false, responseTime, fmt.Errorf("health check returned status %d, expected %d", resp.StatusCode, expectedStatus)
+		}
+		return true, responseTime, nil
+	}
+
 	// Consider 2xx and 3xx status codes as healthy
 	healthy := resp.StatusCode >= 200 && resp.StatusCode < 400
 
@@ -210,7 +268,7 @@ func (h *HealthChecker) PerformBulkHealthCheck(ctx context.Context, containers [
 	for _, container := range containers {
 		result, err := h.PerformHealthCheck(ctx, container)
 		if err != nil {
-			h.logger.Error("Health check failed for container",
+			h.logger.ErrorContext(ctx, "Health check failed for container",
 				slog.String("container", container.Name),
 				slog.String("error", err.Error()))
 
@@ -232,7 +290,7 @@ func (h *HealthChecker) PerformBulkHealthCheck(ctx context.Context, containers [
 
 // MonitorContainerHealth starts monitoring container health continuously
 func (h *HealthChecker) MonitorContainerHealth(ctx context.Context, container *models.Container, interval time.Duration, callback func(*HealthCheckResult)) {
-	h.logger.Info("Starting health monitoring",
+	h.logger.InfoContext(ctx, "Starting health monitoring",
 		slog.String("container", container.Name),
 		slog.Duration("interval", interval))
 
@@ -242,13 +300,13 @@ func (h *HealthChecker) MonitorContainerHealth(ctx context.Context, container *m
 	for {
 		select {
 		case <-ctx.Done():
-			h.logger.Info("Health monitoring stopped",
+			h.logger.InfoContext(ctx, "Health monitoring stopped",
 				slog.String("container", container.Name))
 			return
 		case <-ticker.C:
 			result, err := h.PerformHealthCheck(ctx, container)
 			if err != nil {
-				h.logger.Error("Health monitoring check failed",
+				h.logger.ErrorContext(ctx, "Health monitoring check failed",
 					slog.String("container", container.Name),
 					slog.String("error", err.Error()))
 				continue
@@ -260,7 +318,7 @@ func (h *HealthChecker) MonitorContainerHealth(ctx context.Context, container *m
 
 			// Log health status changes
 			if !result.Healthy {
-				h.logger.Warn("Container health check failed",
+				h.logger.WarnContext(ctx, "Container health check failed",
 					slog.String("container", container.Name),
 					slog.String("error", result.Error))
 			}
@@ -316,33 +374,22 @@ func (h *HealthChecker) GetHealthSummary(ctx context.Context, containers []*mode
 
 // getContainerIP retrieves the IP address of a container
 func (h *HealthChecker) getContainerIP(ctx context.Context, containerID string) (string, error) {
-	cmd := exec.CommandContext(ctx, "podman", "inspect", containerID, "--format", "{{.NetworkSettings.IPAddress}}")
-	output, err := cmd.CombinedOutput()
+	entry, err := h.inspect.get(ctx, containerID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get container IP: %w", err)
 	}
 
-	ip := strings.TrimSpace(string(output))
-	if ip == "" {
-		// Try alternative format for newer podman versions
-		cmd = exec.CommandContext(ctx, "podman", "inspect", containerID, "--format", "{{range .NetworkSettings.Networks}}{{.IPAddress}}{{end}}")
-		output, err = cmd.CombinedOutput()
-		if err != nil {
-			return "", fmt.Errorf("failed to get container IP (alternative): %w", err)
-		}
-		ip = strings.TrimSpace(string(output))
-	}
-
-	if ip == "" {
+	// Containers on a workspace and the shared network have one address per network
+	if entry.ip == "" {
 		return "", fmt.Errorf("container IP address is empty")
 	}
 
-	return ip, nil
+	return entry.ip, nil
 }
 
 // getContainerExposedPort retrieves the first exposed HTTP port from a container
 func (h *HealthChecker) getContainerExposedPort(ctx context.Context, containerID string) (int, error) {
-	cmd := exec.CommandContext(ctx, "podman", "inspect", containerID, "--format", "{{range $port, $config := .Config.ExposedPorts}}{{$port}} {{end}}")
+	cmd := podmanCommand(ctx, h.logger, "inspect", containerID, "--format", "{{range $port, $config := .Config.ExposedPorts}}{{$port}} {{end}}")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return 0, fmt.Errorf("failed to get container exposed ports: %w", err)
@@ -373,7 +420,7 @@ func (h *HealthChecker) getContainerExposedPort(ctx context.Context, containerID
 // guessHTTPPort tries to guess the HTTP port based on common patterns
 func (h *HealthChecker) guessHTTPPort(ctx context.Context, containerID string) (int, error) {
 	// Get container image to make educated guesses
-	cmd := exec.CommandContext(ctx, "podman", "inspect", containerID, "--format", "{{.Config.Image}}")
+	cmd := podmanCommand(ctx, h.logger, "inspect", containerID, "--format", "{{.Config.Image}}")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return 80, nil // Default to port 80
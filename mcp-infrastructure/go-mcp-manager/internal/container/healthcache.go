@@ -0,0 +1,130 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// healthCheckDeadline bounds a single health check, leaving room for the podman inspects before the probe
+func healthCheckDeadline(hc *models.HealthCheckConfig) time.Duration {
+	return healthTimeout(hc) + 5*time.Second
+}
+
+// runBounded calls fn for every index below n on at most workers goroutines and waits for them all
+func runBounded(workers, n int, fn func(int)) {
+	if n == 0 {
+		return
+	}
+	workers = min(max(workers, 1), n)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				fn(job)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// CheckHealth returns a container's health. Unless fresh is set, the last background result is reused
+// while it is no older than the configured max staleness, so polling clients do not spawn podman inspects.
+func (m *Manager) CheckHealth(ctx context.Context, serviceName string, fresh bool) (map[string]interface{}, error) {
+	maxStaleness := m.config.HealthMonitor.MaxStaleness
+
+	m.mutex.RLock()
+	tracked, exists := m.containers[serviceName]
+	var container models.Container
+	var cached *HealthCheckResult
+	if exists {
+		container = *tracked
+		if result, ok := m.containerHealth[container.Name]; ok && !fresh && maxStaleness > 0 && time.Since(result.Timestamp) <= maxStaleness {
+			cached = result
+		}
+	}
+	m.mutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+	if cached != nil {
+		return healthResultMap(&container, cached, true), nil
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, healthCheckDeadline(container.HealthCheck))
+	defer cancel()
+
+	result, err := m.healthChecker.PerformHealthCheck(probeCtx, &container)
+	if err != nil {
+		return nil, fmt.Errorf("health check failed: %w", err)
+	}
+	return healthResultMap(&container, result, false), nil
+}
+
+// CheckHealthAll checks every container in parallel on the health monitor's worker pool, reporting
+// failed checks as unhealthy entries
+func (m *Manager) CheckHealthAll(ctx context.Context, fresh bool) []map[string]interface{} {
+	containers := m.ListContainers()
+	results := make([]map[string]interface{}, len(containers))
+
+	runBounded(m.config.HealthMonitor.Workers, len(containers), func(i int) {
+		container := containers[i]
+		result, err := m.CheckHealth(ctx, container.ServiceName, fresh)
+		if err != nil {
+			result = map[string]interface{}{
+				"service_name":     container.ServiceName,
+				"container_status": string(container.Status),
+				"healthy":          false,
+				"error":            err.Error(),
+				"timestamp":        time.Now(),
+			}
+		}
+		results[i] = result
+	})
+	return results
+}
+
+// healthResultMap converts a health result to the map served by the health endpoints
+func healthResultMap(container *models.Container, healthResult *HealthCheckResult, cached bool) map[string]interface{} {
+	result := map[string]interface{}{
+		"service_name":     container.ServiceName,
+		"container_id":     healthResult.ContainerID,
+		"container_status": string(healthResult.Status),
+		"healthy":          healthResult.Healthy,
+		"live":             healthResult.Live,
+		"ready":            healthResult.Ready,
+		"http_reachable":   healthResult.HTTPReachable,
+		"response_time_ms": healthResult.ResponseTime.Milliseconds(),
+		"timestamp":        healthResult.Timestamp,
+		"url":              container.URL,
+		"slug":             container.Slug,
+		"cached":           cached,
+	}
+
+	if healthResult.Error != "" {
+		result["error"] = healthResult.Error
+	}
+
+	if healthResult.ReadinessError != "" {
+		result["readiness_error"] = healthResult.ReadinessError
+	}
+
+	if healthResult.Details != nil {
+		result["details"] = healthResult.Details
+	}
+
+	return result
+}
@@ -0,0 +1,51 @@
+package container
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestHealthCheckPool(t *testing.T) {
+	var mutex sync.Mutex
+	running, peak, calls := 0, 0, 0
+	runBounded(3, 10, func(int) {
+		mutex.Lock()
+		running++
+		calls++
+		peak = max(peak, running)
+		mutex.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mutex.Lock()
+		running--
+		mutex.Unlock()
+	})
+	if calls != 10 || peak > 3 {
+		t.Errorf("Expected 10 calls on at most 3 workers, got %d calls and %d at once", calls, peak)
+	}
+
+	cfg := &config.Config{HealthMonitor: config.HealthMonitorConfig{Workers: 2, MaxStaleness: time.Minute}}
+	manager := newTestManager(cfg)
+	manager.containers["github"] = &models.Container{Name: "mcp-github", ServiceName: "github", Status: models.StatusRunning}
+	manager.containerHealth["mcp-github"] = &HealthCheckResult{ServiceName: "github", Healthy: true, Status: models.StatusRunning, Timestamp: time.Now()}
+
+	result, err := manager.CheckHealth(context.Background(), "github", false)
+	if err != nil || result["cached"] != true || result["healthy"] != true {
+		t.Errorf("Expected the recent background result to be served from cache, got %v, %v", result, err)
+	}
+
+	results := manager.CheckHealthAll(context.Background(), false)
+	if len(results) != 1 || results[0]["cached"] != true {
+		t.Errorf("Expected one cached result, got %v", results)
+	}
+
+	if _, err := manager.CheckHealth(context.Background(), "unknown", false); err == nil {
+		t.Errorf("Expected an error for an unknown container")
+	}
+}
@@ -0,0 +1,168 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// Defaults applied when a container does not configure its own health check
+const (
+	defaultHealthInterval      = 30 * time.Second
+	defaultHealthTimeout       = 10 * time.Second
+	defaultHealthyThreshold    = 1
+	defaultUnhealthyThreshold  = 1
+	healthMonitorTickInterval  = 5 * time.Second
+	minHealthCheckIntervalSecs = 5
+)
+
+// healthCheckLabel stores a container's health check configuration on the podman container
+const healthCheckLabel = "mcp.health_check"
+
+// healthCounters tracks consecutive probe outcomes for threshold evaluation
+type healthCounters struct {
+	consecutiveSuccesses int
+	consecutiveFailures  int
+	// readySuccesses and readyFailures count consecutive readiness outcomes the same way
+	readySuccesses int
+	readyFailures  int
+	lastChecked    time.Time
+	nextCheck      time.Time
+	inFlight       bool
+}
+
+// parseHealthCheckSpec reads the optional health_check object from json_spec
+func parseHealthCheckSpec(jsonSpec map[string]interface{}) (*models.HealthCheckConfig, error) {
+	raw, exists := jsonSpec["health_check"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+
+	spec, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("health_check must be an object")
+	}
+
+	hc := &models.HealthCheckConfig{}
+
+	if path, exists := spec["path"]; exists {
+		str, ok := path.(string)
+		if !ok || !strings.HasPrefix(str, "/") {
+			return nil, fmt.Errorf("health_check.path must be a string starting with /")
+		}
+		hc.Path = str
+	}
+	if path, exists := spec["readiness_path"]; exists {
+		str, ok := path.(string)
+		if !ok || !strings.HasPrefix(str, "/") {
+			return nil, fmt.Errorf("health_check.readiness_path must be a string starting with /")
+		}
+		hc.ReadinessPath = str
+	}
+	if skip, exists := spec["skip_readiness"]; exists {
+		value, ok := skip.(bool)
+		if !ok {
+			return nil, fmt.Errorf("health_check.skip_readiness must be a boolean")
+		}
+		hc.SkipReadiness = value
+	}
+	if raw, exists := spec["startup"]; exists {
+		startup, err := parseStartupProbeSpec(raw)
+		if err != nil {
+			return nil, err
+		}
+		hc.Startup = startup
+	}
+
+	intFields := []struct {
+		name   string
+		target *int
+		min    int
+		max    int
+	}{
+		{"port", &hc.Port, 1, 65535},
+		{"interval_seconds", &hc.IntervalSeconds, minHealthCheckIntervalSecs, 3600},
+		{"timeout_seconds", &hc.TimeoutSeconds, 1, 300},
+		{"healthy_threshold", &hc.HealthyThreshold, 1, 100},
+		{"unhealthy_threshold", &hc.UnhealthyThreshold, 1, 100},
+		{"expected_status", &hc.ExpectedStatus, 100, 599},
+	}
+	for _, field := range intFields {
+		value, exists := spec[field.name]
+		if !exists {
+			continue
+		}
+		n, ok := specInt(value)
+		if !ok || n < field.min || n > field.max {
+			return nil, fmt.Errorf("health_check.%s must be a number between %d and %d", field.name, field.min, field.max)
+		}
+		*field.target = n
+	}
+
+	return hc, nil
+}
+
+// specInt converts a json_spec number to an int
+func specInt(value interface{}) (int, bool) {
+	switch n := value.(type) {
+	case int:
+		return n, true
+	case float64:
+		if n != float64(int(n)) {
+			return 0, false
+		}
+		return int(n), true
+	default:
+		return 0, false
+	}
+}
+
+// healthInterval returns how often the container should be probed
+func healthInterval(hc *models.HealthCheckConfig) time.Duration {
+	if hc == nil || hc.IntervalSeconds == 0 {
+		return defaultHealthInterval
+	}
+	return time.Duration(hc.IntervalSeconds) * time.Second
+}
+
+// healthTimeout returns the per-probe timeout
+func healthTimeout(hc *models.HealthCheckConfig) time.Duration {
+	if hc == nil || hc.TimeoutSeconds == 0 {
+		return defaultHealthTimeout
+	}
+	return time.Duration(hc.TimeoutSeconds) * time.Second
+}
+
+// healthThresholds returns the healthy and unhealthy thresholds
+func healthThresholds(hc *models.HealthCheckConfig) (healthy, unhealthy int) {
+	healthy, unhealthy = defaultHealthyThreshold, defaultUnhealthyThreshold
+	if hc != nil && hc.HealthyThreshold > 0 {
+		healthy = hc.HealthyThreshold
+	}
+	if hc != nil && hc.UnhealthyThreshold > 0 {
+		unhealthy = hc.UnhealthyThreshold
+	}
+	return healthy, unhealthy
+}
+
+// discoverHealthCheck restores the health check configuration persisted on a podman container
+func (m *Manager) discoverHealthCheck(ctx context.Context, containerID string) *models.HealthCheckConfig {
+	value := m.containerLabel(ctx, containerID, healthCheckLabel)
+	if value == "" {
+		return nil
+	}
+
+	var hc models.HealthCheckConfig
+	if err := json.Unmarshal([]byte(value), &hc); err != nil {
+		m.logger.WarnContext(ctx, "Ignoring unreadable health check label",
+			slog.String("container_id", containerID),
+			slog.String("error", err.Error()))
+		return nil
+	}
+	return &hc
+}
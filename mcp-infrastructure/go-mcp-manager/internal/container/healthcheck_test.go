@@ -0,0 +1,74 @@
+package container
+
+import (
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestParseHealthCheckSpec(t *testing.T) {
+	hc, err := parseHealthCheckSpec(map[string]interface{}{
+		"health_check": map[string]interface{}{
+			"path":                "/healthz",
+			"port":                float64(9000),
+			"interval_seconds":    float64(10),
+			"unhealthy_threshold": float64(3),
+			"expected_status":     float64(204),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected valid health check, got %v", err)
+	}
+	if hc.Path != "/healthz" || hc.Port != 9000 || hc.UnhealthyThreshold != 3 || hc.ExpectedStatus != 204 {
+		t.Errorf("Unexpected health check config: %+v", hc)
+	}
+	if healthInterval(hc) != 10*time.Second {
+		t.Errorf("Expected 10s interval, got %s", healthInterval(hc))
+	}
+
+	if _, err := parseHealthCheckSpec(map[string]interface{}{
+		"health_check": map[string]interface{}{"path": "healthz"},
+	}); err == nil {
+		t.Error("Expected relative path to be rejected")
+	}
+}
+
+func TestHealthThresholdsMarkUnhealthy(t *testing.T) {
+	cfg := &config.Config{
+		Container: config.ContainerConfig{
+			NamePrefix:    "test-",
+			MaxContainers: 10,
+		},
+		Redis: config.RedisConfig{
+			URL: "redis://localhost:6379",
+		},
+	}
+	manager := newTestManager(cfg)
+
+	container := &models.Container{
+		Name:        "test-probe",
+		ServiceName: "probe",
+		Status:      models.StatusRunning,
+		HealthCheck: &models.HealthCheckConfig{UnhealthyThreshold: 2, HealthyThreshold: 2},
+	}
+	failing := &HealthCheckResult{Status: models.StatusRunning, Error: "HTTP endpoint not reachable", Timestamp: time.Now()}
+	passing := &HealthCheckResult{Status: models.StatusRunning, Healthy: true, HTTPReachable: true, Timestamp: time.Now()}
+
+	if status := manager.applyHealthThresholds(container, manager.determineContainerStatus(failing), failing); status != models.StatusRunning {
+		t.Errorf("Expected running below the unhealthy threshold, got %s", status)
+	}
+	status := manager.applyHealthThresholds(container, manager.determineContainerStatus(failing), failing)
+	if status != models.StatusUnhealthy {
+		t.Errorf("Expected unhealthy after threshold breach, got %s", status)
+	}
+	container.Status = status
+
+	if status := manager.applyHealthThresholds(container, manager.determineContainerStatus(passing), passing); status != models.StatusUnhealthy {
+		t.Errorf("Expected unhealthy until the healthy threshold is met, got %s", status)
+	}
+	if status := manager.applyHealthThresholds(container, manager.determineContainerStatus(passing), passing); status != models.StatusRunning {
+		t.Errorf("Expected recovery to running, got %s", status)
+	}
+}
@@ -0,0 +1,129 @@
+package container
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// HealthHistoryEntry is a single recorded health check outcome
+type HealthHistoryEntry struct {
+	Timestamp    time.Time              `json:"timestamp"`
+	Healthy      bool                   `json:"healthy"`
+	Status       models.ContainerStatus `json:"status"`
+	ResponseTime time.Duration          `json:"response_time"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+// healthHistory is a fixed-size ring buffer of health check outcomes
+type healthHistory struct {
+	entries []HealthHistoryEntry
+	next    int
+	full    bool
+}
+
+// newHealthHistory creates a ring buffer holding up to size entries
+func newHealthHistory(size int) *healthHistory {
+	if size <= 0 {
+		size = 1
+	}
+	return &healthHistory{entries: make([]HealthHistoryEntry, size)}
+}
+
+// add records an entry, overwriting the oldest once full
+func (h *healthHistory) add(entry HealthHistoryEntry) {
+	h.entries[h.next] = entry
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// list returns the recorded entries, oldest first
+func (h *healthHistory) list() []HealthHistoryEntry {
+	if !h.full {
+		return append([]HealthHistoryEntry(nil), h.entries[:h.next]...)
+	}
+	result := make([]HealthHistoryEntry, 0, len(h.entries))
+	result = append(result, h.entries[h.next:]...)
+	result = append(result, h.entries[:h.next]...)
+	return result
+}
+
+// uptime returns the percentage of healthy checks in the buffer, or -1 when empty
+func (h *healthHistory) uptime() float64 {
+	entries := h.list()
+	if len(entries) == 0 {
+		return -1
+	}
+	healthy := 0
+	for _, entry := range entries {
+		if entry.Healthy {
+			healthy++
+		}
+	}
+	return float64(healthy) * 100 / float64(len(entries))
+}
+
+// jitteredInterval spreads checks by adding up to 10% random delay so containers
+// created together do not get probed in lockstep
+func jitteredInterval(interval time.Duration) time.Duration {
+	spread := int64(interval / 10)
+	if spread <= 0 {
+		return interval
+	}
+	return interval + time.Duration(rand.Int63n(spread))
+}
+
+// recordHealthHistory appends a result to the container's history and counts it towards its
+// availability. Callers hold the mutex.
+func (m *Manager) recordHealthHistory(container *models.Container, result *HealthCheckResult) {
+	history, exists := m.healthHistory[container.Name]
+	if !exists {
+		history = newHealthHistory(m.config.HealthMonitor.HistorySize)
+		m.healthHistory[container.Name] = history
+	}
+	history.add(HealthHistoryEntry{
+		Timestamp:    result.Timestamp,
+		Healthy:      result.Healthy,
+		Status:       result.Status,
+		ResponseTime: result.ResponseTime,
+		Error:        result.Error,
+	})
+	m.recordSLOCheck(container.ServiceName, result.Timestamp, result.Healthy)
+}
+
+// GetHealthHistory returns the recorded health checks of a service, oldest first, with its uptime percentage
+func (m *Manager) GetHealthHistory(serviceName string) ([]HealthHistoryEntry, float64, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	container, exists := m.containers[serviceName]
+	if !exists {
+		return nil, 0, fmt.Errorf("container %s not found", serviceName)
+	}
+
+	history, exists := m.healthHistory[container.Name]
+	if !exists {
+		return []HealthHistoryEntry{}, -1, nil
+	}
+	return history.list(), history.uptime(), nil
+}
+
+// HealthUptime returns the uptime percentage of every service with recorded health checks
+func (m *Manager) HealthUptime() map[string]float64 {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	uptime := make(map[string]float64, len(m.containers))
+	for serviceName, container := range m.containers {
+		if history, exists := m.healthHistory[container.Name]; exists {
+			if value := history.uptime(); value >= 0 {
+				uptime[serviceName] = value
+			}
+		}
+	}
+	return uptime
+}
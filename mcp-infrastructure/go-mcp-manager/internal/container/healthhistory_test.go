@@ -0,0 +1,28 @@
+package container
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHealthHistoryRingBuffer(t *testing.T) {
+	history := newHealthHistory(3)
+	if history.uptime() != -1 {
+		t.Errorf("Expected -1 uptime for empty history, got %f", history.uptime())
+	}
+
+	for i, healthy := range []bool{false, true, true, true} {
+		history.add(HealthHistoryEntry{Timestamp: time.Unix(int64(i), 0), Healthy: healthy})
+	}
+
+	entries := history.list()
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	if entries[0].Timestamp.Unix() != 1 || entries[2].Timestamp.Unix() != 3 {
+		t.Errorf("Expected oldest-first order after wrap, got %v", entries)
+	}
+	if history.uptime() != 100 {
+		t.Errorf("Expected 100%% uptime after the failure was evicted, got %f", history.uptime())
+	}
+}
@@ -0,0 +1,18 @@
+package container
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+)
+
+// testLogger returns the logger the manager tests write to
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, nil))
+}
+
+// newTestManager returns a manager for cfg that logs with testLogger
+func newTestManager(cfg *config.Config) *Manager {
+	return NewManager(cfg, testLogger())
+}
@@ -0,0 +1,262 @@
+package container
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// specHashLabel records the fingerprint of the spec a container was created from
+	specHashLabel = "mcp.spec_hash"
+	// idempotencyKeysBucket maps Idempotency-Key header values to the instance they created
+	idempotencyKeysBucket = "idempotency_keys"
+	// idempotencyKeyTTL is how long a key stays bound to its instance
+	idempotencyKeyTTL = 24 * time.Hour
+)
+
+// ErrIdempotencyKeyReused is returned when an Idempotency-Key is sent again for a different instance
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different instance")
+
+// idempotencyRecord is the instance an Idempotency-Key was first used for
+type idempotencyRecord struct {
+	InstanceID  string    `json:"instance_id"`
+	ServiceName string    `json:"service_name"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// existingSpec is how a create request compares to the container already serving its instance
+type existingSpec int
+
+const (
+	// specAbsent means no container serves the instance yet
+	specAbsent existingSpec = iota
+	// specUnchanged means the container was created from the same spec. It is left as it is, so
+	// a container a user stopped stays stopped.
+	specUnchanged
+	// specChanged means the container must be replaced, because the spec differs
+	specChanged
+)
+
+// specHash fingerprints a create request. encoding/json sorts map keys, so equal specs hash equally.
+func specHash(spec interface{}) string {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// compareExistingSpec finds the container serving instanceID, or named serviceName, and compares
+// its spec fingerprint with hash
+func (m *Manager) compareExistingSpec(instanceID, serviceName, hash string) (*models.Container, existingSpec) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var existing *models.Container
+	if instanceID != "" {
+		for _, container := range m.containers {
+			if container.Environment["MCP_INSTANCE_ID"] == instanceID {
+				existing = container
+				break
+			}
+		}
+	}
+	if existing == nil {
+		existing = m.containers[serviceName]
+	}
+	if existing == nil {
+		return nil, specAbsent
+	}
+
+	snapshot := *existing
+	// Containers from before fingerprints were recorded are left running rather than recreated
+	if snapshot.SpecHash != "" && snapshot.SpecHash != hash {
+		return &snapshot, specChanged
+	}
+	return &snapshot, specUnchanged
+}
+
+// CreateContainerIdempotent creates a container like CreateContainer, but a retry is safe: when a
+// container already serves the instance with an identical spec it is returned unchanged, and when
+// the spec differs the container is replaced with one from the new spec, which keeps serving
+// until the new spec passed its checks and started. key may not be reused for another instance
+// while it is remembered.
+func (m *Manager) CreateContainerIdempotent(ctx context.Context, key string, req models.CreateContainerRequest) (*models.Container, error) {
+	instanceID := req.Environment["MCP_INSTANCE_ID"]
+
+	var record idempotencyRecord
+	found, err := m.store.Get(idempotencyKeysBucket, key, &record)
+	if err != nil {
+		m.logger.WarnContext(ctx, "Failed to read idempotency key", slog.String("error", err.Error()))
+	}
+	if found && time.Since(record.CreatedAt) < idempotencyKeyTTL &&
+		(record.InstanceID != instanceID || record.ServiceName != req.ServiceName) {
+		return nil, fmt.Errorf("%w: %s", ErrIdempotencyKeyReused, record.ServiceName)
+	}
+
+	existing, state := m.compareExistingSpec(instanceID, req.ServiceName, specHash(req))
+	replacing := ""
+	switch state {
+	case specUnchanged:
+		m.logger.InfoContext(ctx, "Container already exists with the same spec",
+			slog.String("service_name", existing.ServiceName),
+			slog.String("idempotency_key", key))
+		m.rememberIdempotencyKey(ctx, key, instanceID, req.ServiceName)
+		return existing, nil
+	case specChanged:
+		if err := checkReplaceable(existing, req.ServiceName); err != nil {
+			return nil, err
+		}
+		m.logger.InfoContext(ctx, "Replacing container whose spec changed",
+			slog.String("service_name", existing.ServiceName),
+			slog.String("idempotency_key", key))
+		replacing = existing.ServiceName
+	}
+
+	container, err := m.createContainer(ctx, req, "", replacing)
+	if err != nil {
+		return nil, err
+	}
+	m.rememberIdempotencyKey(ctx, key, instanceID, req.ServiceName)
+	return container, nil
+}
+
+// checkReplaceable refuses to replace existing with a container named serviceName. A replacement
+// takes over the container's name, sidecars and volumes, so an instance cannot be renamed by it.
+func checkReplaceable(existing *models.Container, serviceName string) error {
+	if existing.ServiceName != serviceName {
+		return fmt.Errorf("instance %s runs as %s, delete it before recreating it as %s",
+			existing.Environment["MCP_INSTANCE_ID"], existing.ServiceName, serviceName)
+	}
+	return nil
+}
+
+// replaceContainerUnsafe swaps current's container for next, prepared from a changed spec of the
+// same service. What next needs is set up while current keeps serving, and the swap goes through
+// replaceProcessUnsafe, so when next does not start current's container is recreated in its place.
+// Sidecars are only restarted when the dependencies changed, and a stopped container stays
+// stopped. On success current takes next's place. The caller holds the mutex.
+func (m *Manager) replaceContainerUnsafe(ctx context.Context, current, next *models.Container) error {
+	if _, adopted := m.adoption(current.Name); adopted {
+		return fmt.Errorf("container %s was adopted, so the manager cannot recreate it", current.ServiceName)
+	}
+
+	restartSidecars := !reflect.DeepEqual(current.DependsOn, next.DependsOn)
+	fail := func(err error) error {
+		// Sidecars of the same name were replaced for next, so bring back the ones current uses
+		if restartSidecars {
+			restored := *current
+			if restoreErr := m.provisionDependenciesUnsafe(ctx, &restored); restoreErr != nil {
+				m.logger.WarnContext(ctx, "Failed to restore sidecar dependencies",
+					slog.String("service", current.ServiceName),
+					slog.String("error", restoreErr.Error()))
+			}
+		}
+		m.recordContainerEvent(current, InstanceEventWarning, "UpdateFailed",
+			"Kept the previous container, as the changed spec could not replace it: "+err.Error())
+		return err
+	}
+
+	next.StoppedAt = current.StoppedAt
+	if err := m.ensureNetwork(ctx, next.Network); err != nil {
+		return fail(err)
+	}
+	if err := m.createScratchVolumes(ctx, next); err != nil {
+		return fail(err)
+	}
+	if err := m.linkDependenciesUnsafe(ctx, next, restartSidecars); err != nil {
+		return fail(err)
+	}
+	if next.Init != nil {
+		next.Status = models.StatusInitializing
+		if err := m.runInit(ctx, next); err != nil {
+			return fail(fmt.Errorf("init failed for %s: %w", next.ServiceName, err))
+		}
+		next.Status = models.StatusStarting
+	}
+	if err := m.provisionUpstreamTLS(next); err != nil {
+		return fail(err)
+	}
+	if next.StoppedAt != nil {
+		next.Status = current.Status
+	}
+	if err := m.replaceProcessUnsafe(ctx, current, next); err != nil {
+		return fail(err)
+	}
+
+	previous := *current
+	*current = *next
+	m.removeDroppedResources(ctx, &previous, current)
+	if previous.Slug != current.Slug {
+		if err := m.traefikManager.RemoveMCPService(ctx, previous.Slug); err != nil {
+			m.logger.WarnContext(ctx, "Failed to remove the route of the previous slug",
+				slog.String("slug", previous.Slug),
+				slog.String("error", err.Error()))
+		}
+	}
+	delete(m.healthCounters, current.Name)
+	delete(m.crashLoops.exits, current.ServiceName)
+	m.recordSlug(ctx, current)
+	m.releasePodUnsafe(ctx, previous.Pod)
+	m.releaseNetworkUnsafe(ctx, previous.Network)
+	m.recordContainerEvent(current, InstanceEventNormal, "Updated", "Recreated the container from its changed spec")
+	return nil
+}
+
+// removeDroppedResources removes the sidecars, scratch volumes and file secrets previous had and
+// its replacement current no longer uses
+func (m *Manager) removeDroppedResources(ctx context.Context, previous, current *models.Container) {
+	dropped := &models.Container{ServiceName: previous.ServiceName}
+	for _, dependency := range previous.DependsOn {
+		kept := slices.ContainsFunc(current.DependsOn, func(other models.Dependency) bool {
+			return other.Name == dependency.Name && isSidecar(other)
+		})
+		if isSidecar(dependency) && !kept {
+			dropped.DependsOn = append(dropped.DependsOn, dependency)
+		}
+	}
+	m.removeDependencies(ctx, dropped)
+
+	for i := len(current.ScratchVolumes); i < len(previous.ScratchVolumes); i++ {
+		m.removeScratchVolume(ctx, scratchVolumeName(previous.Name, i))
+	}
+	for i := len(current.Files); i < len(previous.Files); i++ {
+		name := fileSecretName(previous.Name, i)
+		if output, err := podmanCommand(ctx, m.logger, "secret", "rm", name).CombinedOutput(); err != nil {
+			m.logger.DebugContext(ctx, "Failed to remove file secret",
+				slog.String("secret", name),
+				slog.String("output", string(output)))
+		}
+	}
+}
+
+// rememberIdempotencyKey binds key to an instance and forgets keys older than idempotencyKeyTTL
+func (m *Manager) rememberIdempotencyKey(ctx context.Context, key, instanceID, serviceName string) {
+	record := idempotencyRecord{InstanceID: instanceID, ServiceName: serviceName, CreatedAt: time.Now()}
+	if err := m.store.Put(idempotencyKeysBucket, key, record); err != nil {
+		m.logger.WarnContext(ctx, "Failed to store idempotency key", slog.String("error", err.Error()))
+	}
+
+	keys, err := m.store.Keys(idempotencyKeysBucket)
+	if err != nil {
+		return
+	}
+	for _, other := range keys {
+		var stored idempotencyRecord
+		if found, err := m.store.Get(idempotencyKeysBucket, other, &stored); found && err == nil &&
+			time.Since(stored.CreatedAt) >= idempotencyKeyTTL {
+			_ = m.store.Delete(idempotencyKeysBucket, other)
+		}
+	}
+}
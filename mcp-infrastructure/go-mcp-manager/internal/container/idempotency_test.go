@@ -0,0 +1,147 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestIdempotentCreate(t *testing.T) {
+	manager := newTestManager(&config.Config{})
+	req := models.CreateContainerRequest{
+		ServiceName: "github",
+		Image:       "ghcr.io/example/github-mcp:1.0",
+		Port:        3000,
+		Environment: map[string]string{"MCP_INSTANCE_ID": "inst-1", "TOKEN": "secret"},
+	}
+	manager.containers["github"] = &models.Container{
+		ID:          "abc123",
+		ServiceName: "github",
+		Status:      models.StatusRunning,
+		Environment: req.Environment,
+		SpecHash:    specHash(req),
+	}
+
+	created, err := manager.CreateContainerIdempotent(context.Background(), "key-1", req)
+	if err != nil || created.ID != "abc123" {
+		t.Errorf("Expected the running container to be returned, got %v, %v", created, err)
+	}
+
+	// The same key for another instance is a client bug, not a retry
+	other := req
+	other.ServiceName = "slack"
+	other.Environment = map[string]string{"MCP_INSTANCE_ID": "inst-2"}
+	if _, err := manager.CreateContainerIdempotent(context.Background(), "key-1", other); !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Errorf("Expected ErrIdempotencyKeyReused, got %v", err)
+	}
+
+	changed := req
+	changed.Image = "ghcr.io/example/github-mcp:2.0"
+	if _, state := manager.compareExistingSpec("inst-1", "github", specHash(changed)); state != specChanged {
+		t.Errorf("Expected a changed image to require replacement, got %d", state)
+	}
+	if _, state := manager.compareExistingSpec("inst-3", "notion", specHash(req)); state != specAbsent {
+		t.Errorf("Expected an unknown instance to be absent, got %d", state)
+	}
+
+	// Only the spec decides: a failed or user-stopped container with the same spec is left alone
+	manager.containers["github"].Status = models.StatusError
+	if _, state := manager.compareExistingSpec("inst-1", "github", specHash(req)); state != specUnchanged {
+		t.Errorf("Expected a failed container with the same spec to be kept, got %d", state)
+	}
+	stoppedAt := time.Now()
+	manager.containers["github"].Status = models.StatusStopped
+	manager.containers["github"].StoppedAt = &stoppedAt
+	if _, state := manager.compareExistingSpec("inst-1", "github", specHash(req)); state != specUnchanged {
+		t.Errorf("Expected a stopped container with the same spec to stay stopped, got %d", state)
+	}
+	manager.containers["github"].StoppedAt = nil
+
+	// Containers discovered from before spec hashes were recorded are not recreated
+	manager.containers["github"].Status = models.StatusRunning
+	manager.containers["github"].SpecHash = ""
+	if _, state := manager.compareExistingSpec("inst-1", "github", specHash(changed)); state != specUnchanged {
+		t.Errorf("Expected a container without a spec hash to be kept, got %d", state)
+	}
+}
+
+func TestIdempotentCreateKeepsContainerOnFailedReplacement(t *testing.T) {
+	// Fake podman logs its calls, has every image for the host platform and cannot start the 2.0 image
+	bin := t.TempDir()
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	calls := filepath.Join(bin, "calls.log")
+	script := "#!/bin/sh\necho \"$*\" >> " + calls + "\ncase \"$1\" in\n" +
+		"run) case \"$*\" in *github-mcp:2.0*) echo 'Error: image cannot start' >&2; exit 125 ;; esac; echo def456 ;;\n" +
+		"inspect) echo running ;;\n" +
+		"image) [ \"$2\" = exists ] || echo " + hostPlatform() + " ;;\nesac\n"
+	if err := os.WriteFile(filepath.Join(bin, "podman"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		State:     config.StateConfig{Dir: t.TempDir()},
+		Container: config.ContainerConfig{NamePrefix: "mcp-", MaxContainers: 1, StartupTimeout: 10 * time.Second},
+	}
+	manager := newTestManager(cfg)
+	req := models.CreateContainerRequest{
+		ServiceName: "github",
+		Image:       "ghcr.io/example/github-mcp:1.0",
+		Port:        3000,
+		Environment: map[string]string{"MCP_INSTANCE_ID": "inst-1"},
+	}
+	manager.containers["github"] = &models.Container{
+		ID:          "abc123",
+		Name:        "mcp-github",
+		ServiceName: "github",
+		Image:       req.Image,
+		Port:        req.Port,
+		Status:      models.StatusRunning,
+		Environment: req.Environment,
+		SpecHash:    specHash(req),
+	}
+	ctx := context.Background()
+
+	// A spec that fails validation never touches the running container
+	invalid := req
+	invalid.Image = "ghcr.io/example/github-mcp:2.0"
+	invalid.Slug = "Not A Slug"
+	if _, err := manager.CreateContainerIdempotent(ctx, "key-1", invalid); err == nil {
+		t.Error("Expected an invalid slug to be refused")
+	}
+	invalid = req
+	invalid.Image = ""
+	if _, err := manager.CreateContainerIdempotent(ctx, "key-1", invalid); err == nil {
+		t.Error("Expected a spec without an image to be refused")
+	}
+	if log, _ := os.ReadFile(calls); strings.Contains(string(log), "rm ") || strings.Contains(string(log), "stop ") {
+		t.Errorf("Expected the container to be left alone, got podman calls:\n%s", log)
+	}
+
+	// A spec that does not start is rolled back to the previous container, though every slot is taken
+	changed := req
+	changed.Image = "ghcr.io/example/github-mcp:2.0"
+	if _, err := manager.CreateContainerIdempotent(ctx, "key-2", changed); err == nil || !strings.Contains(err.Error(), "previous container was recreated") {
+		t.Errorf("Expected the previous container to be recreated, got %v", err)
+	}
+	container := manager.containers["github"]
+	if container == nil || container.Image != req.Image || container.SpecHash != specHash(req) || container.ID != "def456" {
+		t.Fatalf("Expected the previous spec to keep serving, got %+v", container)
+	}
+	log, _ := os.ReadFile(calls)
+	runs := []string{}
+	for _, line := range strings.Split(string(log), "\n") {
+		if strings.HasPrefix(line, "run ") {
+			runs = append(runs, line)
+		}
+	}
+	if len(runs) != 2 || !strings.Contains(runs[0], "github-mcp:2.0") || !strings.Contains(runs[1], "github-mcp:1.0") {
+		t.Errorf("Expected the new spec to be tried and the previous one recreated, got %v", runs)
+	}
+}
@@ -0,0 +1,122 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/internal/registry"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// mcpLabelPrefix marks image labels describing the MCP server, e.g. mcp.transport or mcp.port
+const mcpLabelPrefix = "mcp."
+
+// ErrInvalidImageReference is returned for an image reference that cannot be parsed
+var ErrInvalidImageReference = errors.New("invalid image reference")
+
+// podmanImageInspect is the subset of `podman image inspect` image metadata reads
+type podmanImageInspect struct {
+	Digest       string               `json:"Digest"`
+	Size         int64                `json:"Size"`
+	Architecture string               `json:"Architecture"`
+	OS           string               `json:"Os"`
+	Config       registry.ImageConfig `json:"Config"`
+}
+
+// ImageMetadata describes an image without running it. A local image is inspected; otherwise only
+// its config is read from the registry, and if that fails the image is pulled when allowPull.
+func (m *Manager) ImageMetadata(ctx context.Context, ref string, allowPull bool) (*models.ImageMetadata, error) {
+	parsed, err := registry.ParseReference(ref)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImageReference, err)
+	}
+
+	if metadata, err := m.inspectLocalImage(ctx, ref, models.ImageSourceLocal); err == nil {
+		return metadata, nil
+	}
+
+	image, err := m.registry.Image(ctx, parsed)
+	if err == nil {
+		return newImageMetadata(ref, image.Digest, models.ImageSourceRegistry, image.Architecture, image.OS, image.Config, image.Size, true), nil
+	}
+	// A pull would fail the same way for an image without a host variant
+	var platformErr *registry.PlatformError
+	if !allowPull || errors.Is(err, registry.ErrNotFound) || errors.As(err, &platformErr) {
+		return nil, err
+	}
+
+	m.logger.InfoContext(ctx, "Pulling image to inspect it, as its registry config could not be read",
+		slog.String("image", ref),
+		slog.String("error", err.Error()))
+	if err := m.validator.PullImageWithProgress(ctx, ref, "", func(string) {}); err != nil {
+		return nil, err
+	}
+	return m.inspectLocalImage(ctx, ref, models.ImageSourcePulled)
+}
+
+// inspectLocalImage reads the metadata of an image on the host
+func (m *Manager) inspectLocalImage(ctx context.Context, ref, source string) (*models.ImageMetadata, error) {
+	output, err := podmanCommand(ctx, m.logger, "image", "inspect", ref, "--format", "json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("image %s is not on the host: %w", ref, err)
+	}
+	var inspected []podmanImageInspect
+	if err := json.Unmarshal(output, &inspected); err != nil || len(inspected) == 0 {
+		return nil, fmt.Errorf("failed to parse image inspect output for %s", ref)
+	}
+	image := inspected[0]
+	return newImageMetadata(ref, image.Digest, source, image.Architecture, image.OS, image.Config, image.Size, false), nil
+}
+
+// newImageMetadata flattens an image config into the metadata returned to the platform
+func newImageMetadata(ref, digest, source, architecture, os string, config registry.ImageConfig, size int64, compressed bool) *models.ImageMetadata {
+	metadata := &models.ImageMetadata{
+		Reference:      ref,
+		Digest:         digest,
+		Source:         source,
+		Architecture:   architecture,
+		OS:             os,
+		ExposedPorts:   []int{},
+		Entrypoint:     config.Entrypoint,
+		Cmd:            config.Cmd,
+		WorkingDir:     config.WorkingDir,
+		User:           config.User,
+		Env:            make(map[string]string, len(config.Env)),
+		Labels:         make(map[string]string, len(config.Labels)),
+		MCP:            make(map[string]string),
+		SizeBytes:      size,
+		SizeCompressed: compressed,
+	}
+
+	ports := make([]string, 0, len(config.ExposedPorts))
+	for port := range config.ExposedPorts {
+		ports = append(ports, port)
+	}
+	metadata.ExposedPorts = append(metadata.ExposedPorts, parseExposedPorts(strings.Join(ports, " "))...)
+	sort.Ints(metadata.ExposedPorts)
+
+	for _, entry := range config.Env {
+		if key, value, found := strings.Cut(entry, "="); found {
+			metadata.Env[key] = value
+		}
+	}
+	for key, value := range config.Labels {
+		metadata.Labels[key] = value
+		if name, found := strings.CutPrefix(key, mcpLabelPrefix); found {
+			metadata.MCP[name] = value
+		}
+	}
+
+	if port, err := strconv.Atoi(metadata.MCP["port"]); err == nil && port > 0 {
+		metadata.SuggestedPort = port
+	} else if len(metadata.ExposedPorts) == 1 {
+		metadata.SuggestedPort = metadata.ExposedPorts[0]
+	}
+	return metadata
+}
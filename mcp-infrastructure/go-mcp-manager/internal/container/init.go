@@ -0,0 +1,201 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// initLabel stores a container's init configuration on the podman container
+	initLabel = "mcp.init"
+	// initOfLabel marks an init run with the service it prepares, keeping it out of discovery
+	initOfLabel = "mcp.init_of"
+	// initOutputTail bounds how much init output is reported with a failure
+	initOutputTail = 2048
+)
+
+// InitError reports an init command that did not complete successfully
+type InitError struct {
+	ExitCode int
+	TimedOut bool
+	Output   string
+}
+
+// Error describes the failed init run including the end of its output
+func (e *InitError) Error() string {
+	reason := fmt.Sprintf("exited with code %d", e.ExitCode)
+	if e.TimedOut {
+		reason = "timed out"
+	}
+	if e.Output == "" {
+		return "init command " + reason
+	}
+	return fmt.Sprintf("init command %s: %s", reason, e.Output)
+}
+
+// parseInitSpec reads the optional init object from json_spec
+func parseInitSpec(jsonSpec map[string]interface{}) (*models.InitConfig, error) {
+	raw, exists := jsonSpec["init"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+
+	if _, ok := raw.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("init must be an object")
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("init is not valid JSON: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	spec := &models.InitConfig{}
+	if err := decoder.Decode(spec); err != nil {
+		return nil, fmt.Errorf("invalid init: %w", err)
+	}
+
+	if err := validateInitConfig(spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// validateInitConfig checks that an init configuration has a command and a sane timeout
+func validateInitConfig(spec *models.InitConfig) error {
+	if spec == nil {
+		return nil
+	}
+	if len(spec.Command) == 0 || strings.TrimSpace(spec.Command[0]) == "" {
+		return fmt.Errorf("init.command must not be empty")
+	}
+	if spec.TimeoutSeconds < 0 {
+		return fmt.Errorf("init.timeout_seconds must be positive")
+	}
+	return nil
+}
+
+// initTimeout returns how long an init command may run, capped by the configured maximum
+func (m *Manager) initTimeout(spec *models.InitConfig) time.Duration {
+	timeout := m.config.Container.InitTimeout
+	if spec.TimeoutSeconds > 0 {
+		timeout = time.Duration(spec.TimeoutSeconds) * time.Second
+	}
+	if limit := m.config.Container.MaxInitTimeout; limit > 0 && timeout > limit {
+		timeout = limit
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Minute
+	}
+	return timeout
+}
+
+// initContainerName returns the podman container an init command runs in
+func initContainerName(container *models.Container) string {
+	return container.Name + "-init"
+}
+
+// runInit runs a container's init command to completion with the container's network, mounts
+// and hardening, so what it prepares is visible to the container that starts next
+func (m *Manager) runInit(ctx context.Context, container *models.Container) error {
+	spec := container.Init
+	if spec == nil {
+		return nil
+	}
+
+	name := initContainerName(container)
+	timeout := m.initTimeout(spec)
+	initCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Clear a run left behind by a crash so the name is free
+	if err := m.ensureOwned(ctx, name); err != nil {
+		return err
+	}
+	_ = podmanCommand(ctx, m.logger, "rm", "-f", name).Run()
+
+	args := []string{"run", "--rm", "--name", name,
+		"--label", fmt.Sprintf("%s=%s", initOfLabel, container.ServiceName)}
+	args = append(args, m.ownershipLabelArgs(container)...)
+	args = append(args, m.podmanNetworkArgs(container)...)
+	for key, value := range container.Environment {
+		if _, overridden := spec.Environment[key]; !overridden {
+			args = append(args, "-e", fmt.Sprintf("%s=%s", key, value))
+		}
+	}
+	for key, value := range spec.Environment {
+		args = append(args, "-e", fmt.Sprintf("%s=%s", key, value))
+	}
+	args = append(args, connectivityArgs(container, container.Environment, spec.Environment)...)
+	args = append(args, m.podmanScratchArgs(container)...)
+	args = append(args, m.podmanSecurityArgs(container)...)
+	args = append(args, podmanResourceArgs(container.Resources)...)
+
+	image := spec.Image
+	if image == "" {
+		image = container.Image
+	}
+	args = append(args, "--entrypoint", spec.Command[0], image)
+	args = append(args, spec.Command[1:]...)
+
+	m.logger.InfoContext(ctx, "Running init command",
+		slog.String("service", container.ServiceName),
+		slog.String("image", image),
+		slog.Duration("timeout", timeout))
+
+	started := time.Now()
+	output, err := podmanCommand(initCtx, m.logger, args...).CombinedOutput()
+	if err == nil {
+		m.logger.InfoContext(ctx, "Init command completed",
+			slog.String("service", container.ServiceName),
+			slog.Duration("duration", time.Since(started)))
+		return nil
+	}
+
+	initErr := &InitError{ExitCode: -1, Output: tailOutput(output, initOutputTail)}
+	var exitErr *exec.ExitError
+	if errors.Is(initCtx.Err(), context.DeadlineExceeded) {
+		initErr.TimedOut = true
+		// The run was killed with the podman client, so remove the container it left
+		_ = podmanCommand(ctx, m.logger, "rm", "-f", name).Run()
+	} else if errors.As(err, &exitErr) {
+		initErr.ExitCode = exitErr.ExitCode()
+	}
+
+	m.logger.ErrorContext(ctx, "Init command failed",
+		slog.String("service", container.ServiceName),
+		slog.Int("exit_code", initErr.ExitCode),
+		slog.Bool("timed_out", initErr.TimedOut),
+		slog.Duration("duration", time.Since(started)))
+
+	return initErr
+}
+
+// tailOutput returns the last limit bytes of command output, trimmed of surrounding whitespace
+func tailOutput(output []byte, limit int) string {
+	text := strings.TrimSpace(string(output))
+	if len(text) > limit {
+		text = "..." + text[len(text)-limit:]
+	}
+	return text
+}
+
+// discoverInit restores the init configuration persisted on a podman container
+func (m *Manager) discoverInit(ctx context.Context, containerID string) *models.InitConfig {
+	var spec models.InitConfig
+	if !m.discoverJSONLabel(ctx, containerID, initLabel, &spec) {
+		return nil
+	}
+	return &spec
+}
@@ -0,0 +1,61 @@
+package container
+
+import (
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestInitCommand(t *testing.T) {
+	spec, err := parseInitSpec(map[string]interface{}{
+		"init": map[string]interface{}{
+			"command":         []interface{}{"python", "manage.py", "migrate"},
+			"timeout_seconds": float64(120),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected init to parse, got %v", err)
+	}
+	if len(spec.Command) != 3 || spec.TimeoutSeconds != 120 {
+		t.Errorf("Expected the migrate command with a 120s timeout, got %+v", spec)
+	}
+
+	invalid := []interface{}{
+		"migrate",
+		map[string]interface{}{"command": []interface{}{}},
+		map[string]interface{}{"command": []interface{}{"migrate"}, "timeout_seconds": float64(-1)},
+		map[string]interface{}{"command": []interface{}{"migrate"}, "retries": float64(3)},
+	}
+	for _, raw := range invalid {
+		if _, err := parseInitSpec(map[string]interface{}{"init": raw}); err == nil {
+			t.Errorf("Expected init %v to be rejected", raw)
+		}
+	}
+
+	cfg := &config.Config{
+		Container: config.ContainerConfig{InitTimeout: 10 * time.Minute, MaxInitTimeout: time.Hour},
+	}
+	manager := newTestManager(cfg)
+
+	if timeout := manager.initTimeout(&models.InitConfig{}); timeout != 10*time.Minute {
+		t.Errorf("Expected default init timeout of 10m, got %s", timeout)
+	}
+	if timeout := manager.initTimeout(&models.InitConfig{TimeoutSeconds: 7200}); timeout != time.Hour {
+		t.Errorf("Expected init timeout capped at 1h, got %s", timeout)
+	}
+
+	timedOut := &InitError{TimedOut: true}
+	if timedOut.Error() != "init command timed out" {
+		t.Errorf("Expected timeout message, got %q", timedOut.Error())
+	}
+	failed := &InitError{ExitCode: 2, Output: "no such table"}
+	if failed.Error() != "init command exited with code 2: no such table" {
+		t.Errorf("Expected exit code message, got %q", failed.Error())
+	}
+
+	if tail := tailOutput([]byte("  abcdef\n"), 3); tail != "...def" {
+		t.Errorf("Expected output tail ...def, got %q", tail)
+	}
+}
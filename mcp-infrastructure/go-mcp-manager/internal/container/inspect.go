@@ -0,0 +1,177 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// podmanInspect is the subset of podman or docker container inspect output the inspect endpoint
+// reports; docker has no ImageDigest or Pod
+type podmanInspect struct {
+	ID    string `json:"Id"`
+	State struct {
+		Status     string    `json:"Status"`
+		ExitCode   int       `json:"ExitCode"`
+		OOMKilled  bool      `json:"OOMKilled"`
+		Error      string    `json:"Error"`
+		StartedAt  time.Time `json:"StartedAt"`
+		FinishedAt time.Time `json:"FinishedAt"`
+	} `json:"State"`
+	Image        string `json:"Image"`
+	ImageName    string `json:"ImageName"`
+	ImageDigest  string `json:"ImageDigest"`
+	Pod          string `json:"Pod"`
+	RestartCount int    `json:"RestartCount"`
+	Config       struct {
+		Image string `json:"Image"`
+	} `json:"Config"`
+	HostConfig struct {
+		Tmpfs map[string]string `json:"Tmpfs"`
+	} `json:"HostConfig"`
+	Mounts []struct {
+		Type        string   `json:"Type"`
+		Name        string   `json:"Name"`
+		Source      string   `json:"Source"`
+		Destination string   `json:"Destination"`
+		RW          bool     `json:"RW"`
+		Options     []string `json:"Options"`
+	} `json:"Mounts"`
+	NetworkSettings struct {
+		Networks map[string]struct {
+			IPAddress  string   `json:"IPAddress"`
+			Gateway    string   `json:"Gateway"`
+			MacAddress string   `json:"MacAddress"`
+			Aliases    []string `json:"Aliases"`
+		} `json:"Networks"`
+		Ports map[string][]struct {
+			HostIP   string `json:"HostIp"`
+			HostPort string `json:"HostPort"`
+		} `json:"Ports"`
+	} `json:"NetworkSettings"`
+}
+
+// InspectContainer returns the runtime's view of a container: its state and last exit, restarts,
+// OOM kills, image digest, mounts and networks
+func (m *Manager) InspectContainer(ctx context.Context, serviceName string) (*models.ContainerInspect, error) {
+	m.mutex.RLock()
+	container, exists := m.containers[serviceName]
+	var containerID string
+	if exists {
+		containerID = container.ID
+	}
+	m.mutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+	if containerID == "" {
+		return nil, fmt.Errorf("container %s has no running container to inspect", serviceName)
+	}
+
+	output, err := podmanCommand(ctx, m.logger, "inspect", "--type", "container", containerID).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %w", err)
+	}
+	inspect, err := parseContainerInspect(output)
+	if err != nil {
+		return nil, err
+	}
+	inspect.ServiceName = serviceName
+	inspect.Runtime = currentEngine().binary
+	return inspect, nil
+}
+
+// parseContainerInspect normalizes podman or docker container inspect output
+func parseContainerInspect(output []byte) (*models.ContainerInspect, error) {
+	var inspected []podmanInspect
+	if err := json.Unmarshal(output, &inspected); err != nil || len(inspected) == 0 {
+		return nil, fmt.Errorf("failed to parse container inspect output")
+	}
+	raw := inspected[0]
+
+	image := raw.ImageName
+	if image == "" {
+		image = raw.Config.Image
+	}
+	inspect := &models.ContainerInspect{
+		ContainerID:  raw.ID,
+		Pod:          raw.Pod,
+		Image:        image,
+		ImageID:      raw.Image,
+		ImageDigest:  raw.ImageDigest,
+		State:        raw.State.Status,
+		Error:        raw.State.Error,
+		ExitCode:     raw.State.ExitCode,
+		OOMKilled:    raw.State.OOMKilled,
+		RestartCount: raw.RestartCount,
+		StartedAt:    inspectTime(raw.State.StartedAt),
+		FinishedAt:   inspectTime(raw.State.FinishedAt),
+		Mounts:       []models.InspectMount{},
+		Networks:     []models.InspectNetwork{},
+	}
+
+	for _, mount := range raw.Mounts {
+		source := mount.Source
+		// A named volume is easier to find by its name than by its path under the storage root
+		if mount.Type == "volume" && mount.Name != "" {
+			source = mount.Name
+		}
+		inspect.Mounts = append(inspect.Mounts, models.InspectMount{
+			Type:        mount.Type,
+			Source:      source,
+			Destination: mount.Destination,
+			ReadOnly:    !mount.RW,
+			Options:     strings.Join(mount.Options, ","),
+		})
+	}
+	for destination, options := range raw.HostConfig.Tmpfs {
+		inspect.Mounts = append(inspect.Mounts, models.InspectMount{
+			Type:        "tmpfs",
+			Destination: destination,
+			ReadOnly:    strings.Contains(","+options+",", ",ro,"),
+			Options:     options,
+		})
+	}
+	sort.Slice(inspect.Mounts, func(i, j int) bool {
+		return inspect.Mounts[i].Destination < inspect.Mounts[j].Destination
+	})
+
+	for name, network := range raw.NetworkSettings.Networks {
+		inspect.Networks = append(inspect.Networks, models.InspectNetwork{
+			Name:       name,
+			IPAddress:  network.IPAddress,
+			Gateway:    network.Gateway,
+			MACAddress: network.MacAddress,
+			Aliases:    network.Aliases,
+		})
+	}
+	sort.Slice(inspect.Networks, func(i, j int) bool {
+		return inspect.Networks[i].Name < inspect.Networks[j].Name
+	})
+
+	for port, bindings := range raw.NetworkSettings.Ports {
+		if inspect.Ports == nil {
+			inspect.Ports = make(map[string]string)
+		}
+		inspect.Ports[port] = ""
+		if len(bindings) > 0 {
+			inspect.Ports[port] = net.JoinHostPort(bindings[0].HostIP, bindings[0].HostPort)
+		}
+	}
+	return inspect, nil
+}
+
+// inspectTime returns nil for the zero time podman reports for a container that never started or exited
+func inspectTime(t time.Time) *time.Time {
+	if t.IsZero() || t.Year() <= 1 {
+		return nil
+	}
+	return &t
+}
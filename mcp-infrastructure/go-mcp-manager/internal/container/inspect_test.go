@@ -0,0 +1,62 @@
+package container
+
+import (
+	"testing"
+)
+
+func TestParseContainerInspect(t *testing.T) {
+	output := []byte(`[{
+		"Id": "abc123",
+		"State": {"Status": "exited", "ExitCode": 137, "OOMKilled": true, "Error": "",
+			"StartedAt": "2026-05-01T10:00:00.5Z", "FinishedAt": "2026-05-01T10:05:00Z"},
+		"Image": "f00d",
+		"ImageName": "ghcr.io/org/server:1.0",
+		"ImageDigest": "sha256:beef",
+		"Pod": "mcp-pod-team-a",
+		"RestartCount": 3,
+		"HostConfig": {"Tmpfs": {"/tmp": "rw,size=64m"}},
+		"Mounts": [{"Type": "volume", "Name": "mcp-data", "Source": "/var/lib/containers/storage/volumes/mcp-data/_data",
+			"Destination": "/data", "RW": false, "Options": ["nosuid"]}],
+		"NetworkSettings": {
+			"Networks": {"mcp-team-a": {"IPAddress": "10.89.0.5", "Gateway": "10.89.0.1", "Aliases": ["server"]}},
+			"Ports": {"8000/tcp": [], "9000/tcp": [{"HostIp": "127.0.0.1", "HostPort": "39000"}]}
+		}
+	}]`)
+
+	inspect, err := parseContainerInspect(output)
+	if err != nil {
+		t.Fatalf("Failed to parse inspect output: %v", err)
+	}
+	if inspect.State != "exited" || inspect.ExitCode != 137 || !inspect.OOMKilled || inspect.RestartCount != 3 {
+		t.Errorf("Expected an OOM-killed exit after 3 restarts, got %+v", inspect)
+	}
+	if inspect.Image != "ghcr.io/org/server:1.0" || inspect.ImageDigest != "sha256:beef" || inspect.Pod != "mcp-pod-team-a" {
+		t.Errorf("Expected the image name, digest and pod, got %+v", inspect)
+	}
+	if inspect.StartedAt == nil || inspect.FinishedAt == nil {
+		t.Errorf("Expected start and finish times, got %v and %v", inspect.StartedAt, inspect.FinishedAt)
+	}
+	if len(inspect.Mounts) != 2 || inspect.Mounts[0].Source != "mcp-data" || !inspect.Mounts[0].ReadOnly ||
+		inspect.Mounts[1].Type != "tmpfs" || inspect.Mounts[1].Options != "rw,size=64m" {
+		t.Errorf("Expected the named volume and the tmpfs, got %+v", inspect.Mounts)
+	}
+	if len(inspect.Networks) != 1 || inspect.Networks[0].IPAddress != "10.89.0.5" || inspect.Networks[0].Gateway != "10.89.0.1" {
+		t.Errorf("Expected the workspace network, got %+v", inspect.Networks)
+	}
+	if inspect.Ports["8000/tcp"] != "" || inspect.Ports["9000/tcp"] != "127.0.0.1:39000" {
+		t.Errorf("Expected one unpublished and one published port, got %v", inspect.Ports)
+	}
+
+	// Docker reports the image name in Config and times of a container that never exited as zero
+	docker, err := parseContainerInspect([]byte(`[{"Id": "def", "State": {"Status": "running", "StartedAt": "2026-05-01T10:00:00Z",
+		"FinishedAt": "0001-01-01T00:00:00Z"}, "Image": "sha256:f00d", "Config": {"Image": "server:latest"}}]`))
+	if err != nil {
+		t.Fatalf("Failed to parse docker inspect output: %v", err)
+	}
+	if docker.Image != "server:latest" || docker.FinishedAt != nil || docker.Mounts == nil || docker.Networks == nil {
+		t.Errorf("Expected the docker image name and no finish time, got %+v", docker)
+	}
+	if _, err := parseContainerInspect([]byte(`[]`)); err == nil {
+		t.Errorf("Expected an error for empty inspect output")
+	}
+}
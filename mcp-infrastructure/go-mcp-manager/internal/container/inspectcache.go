@@ -0,0 +1,189 @@
+package container
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// inspectFormat reads a container's state and addresses in one podman inspect. The second line holds
+// the legacy address followed by one address per network, so the first field is the preferred one.
+// The third describes the last exit: exit code, OOM kill, restart count and when it finished.
+const inspectFormat = "{{.State.Status}}\n{{.NetworkSettings.IPAddress}} {{range .NetworkSettings.Networks}}{{.IPAddress}} {{end}}\n" +
+	"{{.State.ExitCode}} {{.State.OOMKilled}} {{.RestartCount}} {{json .State.FinishedAt}}"
+
+// podmanEventsRetryInterval is how long to wait before following podman events again after it exits
+const podmanEventsRetryInterval = 5 * time.Second
+
+// inspectCache keeps podman inspect results per container for a short TTL, so status and health
+// endpoints do not fork a podman process per request. Entries are dropped when podman reports an
+// event for the container or the manager changes it.
+type inspectCache struct {
+	ttl    time.Duration
+	logger *slog.Logger
+
+	mutex   sync.Mutex
+	entries map[string]inspectEntry
+}
+
+// inspectEntry is the part of podman inspect the manager polls
+type inspectEntry struct {
+	state string
+	ip    string
+	// exitCode, oomKilled and finishedAt describe the last exit of the process, nil finishedAt when
+	// it never exited; restartCount is how often the runtime restarted it
+	exitCode     int
+	oomKilled    bool
+	restartCount int
+	finishedAt   *time.Time
+	fetchedAt    time.Time
+}
+
+// newInspectCache creates a cache; a zero TTL inspects on every call
+func newInspectCache(ttl time.Duration, logger *slog.Logger) *inspectCache {
+	return &inspectCache{
+		ttl:     ttl,
+		logger:  logger,
+		entries: make(map[string]inspectEntry),
+	}
+}
+
+// get returns the state and IP of a container, inspecting it when the cached entry is missing or expired
+func (c *inspectCache) get(ctx context.Context, containerID string) (inspectEntry, error) {
+	if c.ttl > 0 {
+		c.mutex.Lock()
+		entry, exists := c.entries[containerID]
+		c.mutex.Unlock()
+		if exists && time.Since(entry.fetchedAt) < c.ttl {
+			return entry, nil
+		}
+	}
+
+	output, err := podmanCommand(ctx, c.logger, "inspect", containerID, "--format", inspectFormat).CombinedOutput()
+	if err != nil {
+		return inspectEntry{}, fmt.Errorf("failed to inspect container: %w, output: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	entry := parseInspectEntry(string(output))
+	if c.ttl > 0 {
+		c.mutex.Lock()
+		c.entries[containerID] = entry
+		c.mutex.Unlock()
+	}
+	return entry, nil
+}
+
+// parseInspectEntry reads the output of inspectFormat; fields podman did not report are left zero
+func parseInspectEntry(output string) inspectEntry {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	entry := inspectEntry{state: strings.TrimSpace(lines[0]), fetchedAt: time.Now()}
+	if len(lines) > 1 {
+		if fields := strings.Fields(lines[1]); len(fields) > 0 {
+			entry.ip = fields[0]
+		}
+	}
+	if len(lines) > 2 {
+		fields := strings.Fields(lines[2])
+		if len(fields) == 4 {
+			entry.exitCode, _ = strconv.Atoi(fields[0])
+			entry.oomKilled = fields[1] == "true"
+			entry.restartCount, _ = strconv.Atoi(fields[2])
+			var finishedAt time.Time
+			if err := json.Unmarshal([]byte(fields[3]), &finishedAt); err == nil {
+				entry.finishedAt = inspectTime(finishedAt)
+			}
+		}
+	}
+	return entry
+}
+
+// applyExit copies the last exit of the process onto the container it was inspected for
+func (e inspectEntry) applyExit(container *models.Container) {
+	container.RestartCount = e.restartCount
+	if e.finishedAt == nil {
+		return
+	}
+	exitCode := e.exitCode
+	container.LastExitCode = &exitCode
+	container.OOMKilled = e.oomKilled
+	container.FinishedAt = e.finishedAt
+}
+
+// exitReason explains why a process that is no longer running ended
+func (e inspectEntry) exitReason() string {
+	switch {
+	case e.oomKilled:
+		return fmt.Sprintf("Container was killed for exceeding its memory limit (OOM, exit code %d)", e.exitCode)
+	case e.finishedAt != nil:
+		return fmt.Sprintf("Container exited with code %d", e.exitCode)
+	default:
+		return "Container is not running"
+	}
+}
+
+// invalidate drops the cached entry of a container
+func (c *inspectCache) invalidate(containerID string) {
+	c.mutex.Lock()
+	delete(c.entries, containerID)
+	c.mutex.Unlock()
+}
+
+// reset drops every cached entry, e.g. when events may have been missed
+func (c *inspectCache) reset() {
+	c.mutex.Lock()
+	c.entries = make(map[string]inspectEntry)
+	c.mutex.Unlock()
+}
+
+// startInspectInvalidation follows podman container events and drops the cached inspect result of
+// every container an event names
+func (m *Manager) startInspectInvalidation() {
+	if m.inspect.ttl <= 0 {
+		return
+	}
+
+	for {
+		m.followPodmanEvents(m.healthCtx)
+		// Events emitted while podman events was not running are lost
+		m.inspect.reset()
+
+		select {
+		case <-m.healthCtx.Done():
+			return
+		case <-time.After(podmanEventsRetryInterval):
+		}
+	}
+}
+
+// followPodmanEvents runs podman events until it exits or ctx is cancelled
+func (m *Manager) followPodmanEvents(ctx context.Context) {
+	cmd := podmanCommand(ctx, m.logger, "events", "--format", "json", "--filter", "type=container")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return
+	}
+	if err := cmd.Start(); err != nil {
+		m.logger.WarnContext(ctx, "Failed to follow podman events", slog.String("error", err.Error()))
+		return
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		var event struct {
+			ID string `json:"ID"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || event.ID == "" {
+			continue
+		}
+		m.inspect.invalidate(event.ID)
+	}
+	_ = cmd.Wait()
+}
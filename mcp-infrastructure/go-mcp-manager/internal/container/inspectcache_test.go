@@ -0,0 +1,67 @@
+package container
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestInspectCache(t *testing.T) {
+	cache := newInspectCache(time.Minute, testLogger())
+	cache.entries["abc"] = inspectEntry{state: "running", ip: "10.88.0.5", fetchedAt: time.Now()}
+
+	entry, err := cache.get(context.Background(), "abc")
+	if err != nil || entry.state != "running" || entry.ip != "10.88.0.5" {
+		t.Errorf("Expected the cached inspect result, got %+v, %v", entry, err)
+	}
+
+	cache.invalidate("abc")
+	if _, exists := cache.entries["abc"]; exists {
+		t.Errorf("Expected the entry to be dropped on invalidation")
+	}
+
+	cache.entries["abc"] = inspectEntry{state: "running", fetchedAt: time.Now().Add(-2 * time.Minute)}
+	cache.entries["def"] = inspectEntry{state: "exited", fetchedAt: time.Now()}
+	cache.reset()
+	if len(cache.entries) != 0 {
+		t.Errorf("Expected reset to drop every entry, got %d", len(cache.entries))
+	}
+
+	manager := newTestManager(&config.Config{HealthMonitor: config.HealthMonitorConfig{InspectCacheTTL: time.Minute}})
+	if manager.healthChecker.inspect != manager.inspect {
+		t.Errorf("Expected the health checker to share the manager's inspect cache")
+	}
+}
+
+func TestExitReporting(t *testing.T) {
+	entry := parseInspectEntry("exited\n10.88.0.5 10.88.0.5 \n137 true 2 \"2026-03-01T10:00:00.5Z\"\n")
+	if entry.state != "exited" || entry.ip != "10.88.0.5" {
+		t.Errorf("Expected state and IP to be read, got %+v", entry)
+	}
+	if entry.exitCode != 137 || !entry.oomKilled || entry.restartCount != 2 || entry.finishedAt == nil {
+		t.Fatalf("Expected the OOM kill to be read, got %+v", entry)
+	}
+	if reason := entry.exitReason(); !strings.Contains(reason, "memory limit") || !strings.Contains(reason, "137") {
+		t.Errorf("Expected the reason to name the memory limit, got %s", reason)
+	}
+
+	container := &models.Container{}
+	entry.applyExit(container)
+	if container.LastExitCode == nil || *container.LastExitCode != 137 || !container.OOMKilled || container.RestartCount != 2 || container.FinishedAt == nil {
+		t.Errorf("Expected the exit to be copied to the container, got %+v", container)
+	}
+
+	// A container that never exited reports no exit
+	running := parseInspectEntry("running\n10.88.0.6\n0 false 0 \"0001-01-01T00:00:00Z\"\n")
+	if running.finishedAt != nil || running.exitReason() != "Container is not running" {
+		t.Errorf("Expected no exit for a running container, got %+v", running)
+	}
+	// Output of an older format without the exit line still parses
+	if legacy := parseInspectEntry("running\n10.88.0.7"); legacy.ip != "10.88.0.7" {
+		t.Errorf("Expected the IP without an exit line, got %+v", legacy)
+	}
+}
@@ -0,0 +1,202 @@
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/webhooks"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// instanceEventsBucket holds the timeline of each instance, keyed by instance ID
+	instanceEventsBucket = "instance_events"
+	// maxInstanceEvents is how many events a timeline keeps, dropping the oldest
+	maxInstanceEvents = 100
+	// instanceEventRetention is how long the timeline of a deleted instance is kept
+	instanceEventRetention = 7 * 24 * time.Hour
+)
+
+// Instance event types
+const (
+	InstanceEventNormal  = "Normal"
+	InstanceEventWarning = "Warning"
+)
+
+// InstanceEvent is an entry of an instance's timeline, in the manner of a Kubernetes event
+type InstanceEvent struct {
+	// Type is Normal or Warning
+	Type string `json:"type"`
+	// Reason is a short CamelCase cause such as Created, Pulled, Unhealthy or RouteChanged
+	Reason      string `json:"reason"`
+	Message     string `json:"message"`
+	ServiceName string `json:"service_name"`
+	ContainerID string `json:"container_id,omitempty"`
+	// ExitCode and Logs describe the exit of the container's process, with its last output lines
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Logs     string `json:"logs,omitempty"`
+	// Count is how often the event repeated in a row, between FirstSeen and LastSeen
+	Count     int       `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// InstanceEventsResponse is the timeline of an instance, oldest first
+type InstanceEventsResponse struct {
+	InstanceID  string          `json:"instance_id"`
+	ServiceName string          `json:"service_name,omitempty"`
+	Events      []InstanceEvent `json:"events"`
+}
+
+// timelineState serializes updates of the stored timelines
+type timelineState struct {
+	mutex sync.Mutex
+}
+
+// webhookInstanceEvents maps lifecycle webhooks to the type and reason of the timeline event
+// they are recorded as
+var webhookInstanceEvents = map[webhooks.EventType]struct{ eventType, reason string }{
+	webhooks.EventContainerCreated:   {InstanceEventNormal, "Created"},
+	webhooks.EventContainerFailed:    {InstanceEventWarning, "Failed"},
+	webhooks.EventContainerUnhealthy: {InstanceEventWarning, "Unhealthy"},
+	webhooks.EventContainerRecovered: {InstanceEventNormal, "Recovered"},
+	webhooks.EventContainerDeleted:   {InstanceEventNormal, "Deleted"},
+	webhooks.EventContainerStopped:   {InstanceEventNormal, "Stopped"},
+	webhooks.EventContainerStarted:   {InstanceEventNormal, "Started"},
+	webhooks.EventContainerPreempted: {InstanceEventWarning, "Preempted"},
+	webhooks.EventRouteChanged:       {InstanceEventNormal, "RouteChanged"},
+}
+
+// recordWebhookEvent adds a lifecycle webhook to the timeline of the container's instance
+func (m *Manager) recordWebhookEvent(eventType webhooks.EventType, container *models.Container, errMsg string) {
+	event, exists := webhookInstanceEvents[eventType]
+	if !exists {
+		return
+	}
+	message := errMsg
+	if message == "" {
+		switch eventType {
+		case webhooks.EventContainerCreated:
+			message = fmt.Sprintf("Created container %s from %s", container.Name, container.Image)
+		case webhooks.EventContainerRecovered:
+			message = "Health checks pass again"
+		case webhooks.EventContainerUnhealthy:
+			message = fmt.Sprintf("Container is %s", container.Status)
+		case webhooks.EventContainerDeleted:
+			message = "Removed the container and its route"
+		case webhooks.EventContainerStopped:
+			message = "Stopped the container"
+		case webhooks.EventContainerStarted:
+			message = "Started the container"
+		}
+	}
+	m.recordContainerEvent(container, event.eventType, event.reason, message)
+}
+
+// recordContainerEvent adds an event to the timeline of the container's instance
+func (m *Manager) recordContainerEvent(container *models.Container, eventType, reason, message string) {
+	m.recordInstanceEvent(container.Environment["MCP_INSTANCE_ID"], InstanceEvent{
+		Type:        eventType,
+		Reason:      reason,
+		Message:     message,
+		ServiceName: container.ServiceName,
+		ContainerID: container.ID,
+	})
+}
+
+// recordInstanceEvent adds an event to an instance's timeline. An event repeating the last one
+// only counts it again. Containers without an instance ID have no timeline.
+func (m *Manager) recordInstanceEvent(instanceID string, event InstanceEvent) {
+	if instanceID == "" {
+		return
+	}
+	now := time.Now()
+
+	m.timeline.mutex.Lock()
+	defer m.timeline.mutex.Unlock()
+
+	var events []InstanceEvent
+	if _, err := m.store.Get(instanceEventsBucket, instanceID, &events); err != nil {
+		m.logger.Warn("Failed to read instance events",
+			slog.String("instance_id", instanceID),
+			slog.String("error", err.Error()))
+	}
+	if last := len(events) - 1; last >= 0 && events[last].Type == event.Type &&
+		events[last].Reason == event.Reason && events[last].Message == event.Message {
+		events[last].Count++
+		events[last].LastSeen = now
+		events[last].ContainerID = event.ContainerID
+	} else {
+		event.Count = 1
+		event.FirstSeen = now
+		event.LastSeen = now
+		events = append(events, event)
+	}
+	events = events[max(len(events)-maxInstanceEvents, 0):]
+
+	if err := m.store.Put(instanceEventsBucket, instanceID, events); err != nil {
+		m.logger.Warn("Failed to save instance event",
+			slog.String("instance_id", instanceID),
+			slog.String("reason", event.Reason),
+			slog.String("error", err.Error()))
+	}
+	if event.Reason == "Deleted" {
+		m.pruneInstanceEventsUnsafe(now)
+	}
+}
+
+// pruneInstanceEventsUnsafe removes the timelines of instances deleted longer ago than the
+// retention. Caller must hold m.timeline.mutex.
+func (m *Manager) pruneInstanceEventsUnsafe(now time.Time) {
+	records, err := m.store.List(instanceEventsBucket)
+	if err != nil {
+		return
+	}
+	for instanceID, raw := range records {
+		var events []InstanceEvent
+		if err := json.Unmarshal(raw, &events); err != nil || len(events) == 0 {
+			continue
+		}
+		last := events[len(events)-1]
+		if last.Reason == "Deleted" && now.Sub(last.LastSeen) > instanceEventRetention {
+			if err := m.store.Delete(instanceEventsBucket, instanceID); err != nil {
+				m.logger.Warn("Failed to remove instance events",
+					slog.String("instance_id", instanceID),
+					slog.String("error", err.Error()))
+			}
+			// The instance's deployment history goes with its events
+			if err := m.store.Delete(specRevisionsBucket, instanceID); err != nil {
+				m.logger.Warn("Failed to remove spec revisions",
+					slog.String("instance_id", instanceID),
+					slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+// GetInstanceEvents returns the timeline of an instance, oldest first. Deleted instances keep
+// theirs for a week.
+func (m *Manager) GetInstanceEvents(instanceID string) (*InstanceEventsResponse, error) {
+	m.timeline.mutex.Lock()
+	events := []InstanceEvent{}
+	found, err := m.store.Get(instanceEventsBucket, instanceID, &events)
+	m.timeline.mutex.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read instance events: %w", err)
+	}
+
+	response := &InstanceEventsResponse{InstanceID: instanceID, Events: events}
+	serviceName, tracked := m.ServiceNameForInstance(instanceID)
+	switch {
+	case tracked:
+		response.ServiceName = serviceName
+	case !found:
+		return nil, fmt.Errorf("instance not found: %s", instanceID)
+	case len(events) > 0:
+		response.ServiceName = events[len(events)-1].ServiceName
+	}
+	return response, nil
+}
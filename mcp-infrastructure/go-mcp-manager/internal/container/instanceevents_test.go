@@ -0,0 +1,54 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/internal/webhooks"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestInstanceEvents(t *testing.T) {
+	cfg := &config.Config{State: config.StateConfig{Dir: t.TempDir()}}
+	manager := newTestManager(cfg)
+	container := &models.Container{
+		ID:          "abc123",
+		Name:        "mcp-github",
+		ServiceName: "github",
+		Image:       "ghcr.io/example/github-mcp:1.0",
+		Status:      models.StatusUnhealthy,
+		Environment: map[string]string{"MCP_INSTANCE_ID": "inst-1"},
+	}
+	manager.containers["github"] = container
+
+	manager.recordWebhookEvent(webhooks.EventContainerCreated, container, "")
+	manager.recordWebhookEvent(webhooks.EventContainerUnhealthy, container, "")
+	manager.recordWebhookEvent(webhooks.EventContainerUnhealthy, container, "")
+	// Containers without an instance ID have no timeline
+	manager.recordWebhookEvent(webhooks.EventContainerCreated, &models.Container{ServiceName: "other"}, "")
+
+	response, err := manager.GetInstanceEvents("inst-1")
+	if err != nil {
+		t.Fatalf("Expected events, got %v", err)
+	}
+	if response.ServiceName != "github" || len(response.Events) != 2 {
+		t.Fatalf("Expected 2 events of github, got %+v", response)
+	}
+	if unhealthy := response.Events[1]; unhealthy.Reason != "Unhealthy" || unhealthy.Type != InstanceEventWarning || unhealthy.Count != 2 {
+		t.Errorf("Expected the repeated Unhealthy warning to be counted twice, got %+v", unhealthy)
+	}
+
+	delete(manager.containers, "github")
+	manager.recordWebhookEvent(webhooks.EventContainerDeleted, container, "")
+	response, err = manager.GetInstanceEvents("inst-1")
+	if err != nil {
+		t.Fatalf("Expected the timeline to outlive the container, got %v", err)
+	}
+	if len(response.Events) != 3 || response.Events[2].Reason != "Deleted" || response.ServiceName != "github" {
+		t.Errorf("Expected a Deleted event last, got %+v", response)
+	}
+
+	if _, err := manager.GetInstanceEvents("unknown"); err == nil {
+		t.Errorf("Expected an unknown instance to fail")
+	}
+}
@@ -0,0 +1,80 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// Bounds for the number of log lines returned by GetContainerLogs
+const (
+	defaultLogTail = 200
+	maxLogTail     = 5000
+)
+
+// ServiceNameForInstance resolves the Core API instance ID a container was created for to its
+// service name. Container IDs and service names are accepted too, for callers that predate
+// instance IDs, but an instance ID match always wins.
+func (m *Manager) ServiceNameForInstance(instanceID string) (string, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if instanceID == "" {
+		return "", false
+	}
+	for _, container := range m.containers {
+		if container.Environment["MCP_INSTANCE_ID"] == instanceID {
+			return container.ServiceName, true
+		}
+	}
+	for _, container := range m.containers {
+		if container.ID == instanceID {
+			return container.ServiceName, true
+		}
+	}
+	if container, exists := m.containers[instanceID]; exists {
+		return container.ServiceName, true
+	}
+	return "", false
+}
+
+// WorkspaceOf returns the workspace owning a service's container, archived or not
+func (m *Manager) WorkspaceOf(serviceName string) (string, bool) {
+	m.mutex.RLock()
+	container, exists := m.containers[serviceName]
+	var workspaceID string
+	if exists {
+		workspaceID = container.WorkspaceID
+	}
+	m.mutex.RUnlock()
+	if exists {
+		return workspaceID, true
+	}
+
+	if archived, err := m.GetArchived(serviceName); err == nil {
+		return archived.WorkspaceID, true
+	}
+	return "", false
+}
+
+// GetContainerLogs returns the last tail lines of a container's stdout and stderr
+func (m *Manager) GetContainerLogs(ctx context.Context, serviceName string, tail int) (string, error) {
+	container, err := m.GetContainer(serviceName)
+	if err != nil {
+		return "", err
+	}
+	if container.ID == "" {
+		return "", fmt.Errorf("container %s has not been started", serviceName)
+	}
+
+	if tail <= 0 {
+		tail = defaultLogTail
+	}
+	tail = min(tail, maxLogTail)
+
+	output, err := podmanCommand(ctx, m.logger, "logs", "--tail", strconv.Itoa(tail), container.ID).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to read container logs: %w", err)
+	}
+	return string(output), nil
+}
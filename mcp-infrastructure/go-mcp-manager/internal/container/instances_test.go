@@ -0,0 +1,26 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestServiceNameForInstance(t *testing.T) {
+	manager := newTestManager(&config.Config{})
+	manager.containers["github"] = &models.Container{ID: "abc123", ServiceName: "github", Environment: map[string]string{"MCP_INSTANCE_ID": "inst-1"}}
+	// A service named like another container's instance ID must not shadow the instance lookup
+	manager.containers["inst-2"] = &models.Container{ID: "def456", ServiceName: "inst-2"}
+	manager.containers["slack"] = &models.Container{ID: "ghi789", ServiceName: "slack", Environment: map[string]string{"MCP_INSTANCE_ID": "inst-2"}}
+
+	cases := map[string]string{"inst-1": "github", "inst-2": "slack", "abc123": "github", "github": "github"}
+	for lookup, expected := range cases {
+		if serviceName, found := manager.ServiceNameForInstance(lookup); !found || serviceName != expected {
+			t.Errorf("Expected %s to resolve to %s, got %s", lookup, expected, serviceName)
+		}
+	}
+	if _, found := manager.ServiceNameForInstance("unknown"); found {
+		t.Errorf("Expected an unknown instance not to resolve")
+	}
+}
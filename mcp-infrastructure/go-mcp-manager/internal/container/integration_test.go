@@ -10,7 +10,7 @@ import (
 	"os"
 
 	"github.com/agentarea/mcp-manager/internal/config"
-	"github.com/agentarea/mcp-manager/internal/models"
+	"github.com/agentarea/mcp-manager/pkg/models"
 	redis "github.com/go-redis/redis/v8"
 )
 
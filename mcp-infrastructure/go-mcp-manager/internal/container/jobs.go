@@ -0,0 +1,170 @@
+package container
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// maxRetainedJobs bounds how many jobs are kept; the oldest finished jobs are dropped first
+	maxRetainedJobs = 100
+	// jobLogLimit bounds the output kept per job, keeping the most recent output
+	jobLogLimit = 1 << 20
+)
+
+// jobTracker keeps recent jobs and their output in memory
+type jobTracker struct {
+	mutex sync.RWMutex
+	jobs  map[string]*trackedJob
+}
+
+// trackedJob is a job with the output written to it so far
+type trackedJob struct {
+	mutex sync.RWMutex
+	job   models.Job
+	logs  []byte
+}
+
+// Write appends command output to the job log, dropping the oldest output past jobLogLimit
+func (j *trackedJob) Write(p []byte) (int, error) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.logs = append(j.logs, p...)
+	if overflow := len(j.logs) - jobLogLimit; overflow > 0 {
+		j.logs = append(j.logs[:0], j.logs[overflow:]...)
+	}
+	return len(p), nil
+}
+
+// snapshot returns a copy of the job
+func (j *trackedJob) snapshot() models.Job {
+	j.mutex.RLock()
+	defer j.mutex.RUnlock()
+	return j.job
+}
+
+// setMirror records the latest comparison of a mirroring job
+func (j *trackedJob) setMirror(comparison *models.MirrorComparison) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.job.Mirror = comparison
+}
+
+// startJob registers a running job for a service
+func (m *Manager) startJob(jobType, serviceName string) *trackedJob {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+
+	job := &trackedJob{job: models.Job{
+		ID:          hex.EncodeToString(id),
+		Type:        jobType,
+		ServiceName: serviceName,
+		Status:      models.JobRunning,
+		CreatedAt:   time.Now(),
+	}}
+
+	m.jobs.mutex.Lock()
+	defer m.jobs.mutex.Unlock()
+	if m.jobs.jobs == nil {
+		m.jobs.jobs = make(map[string]*trackedJob)
+	}
+	m.jobs.jobs[job.job.ID] = job
+	m.pruneJobsUnsafe()
+	return job
+}
+
+// finishJob records the outcome of a job
+func (m *Manager) finishJob(job *trackedJob, image string, err error) {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	finishedAt := time.Now()
+	job.job.FinishedAt = &finishedAt
+	job.job.Image = image
+	job.job.Status = models.JobSucceeded
+	if err != nil {
+		job.job.Status = models.JobFailed
+		job.job.Error = err.Error()
+	}
+}
+
+// pruneJobsUnsafe drops the oldest finished jobs beyond maxRetainedJobs (caller must hold jobs lock)
+func (m *Manager) pruneJobsUnsafe() {
+	if len(m.jobs.jobs) <= maxRetainedJobs {
+		return
+	}
+
+	finished := make([]models.Job, 0, len(m.jobs.jobs))
+	for _, job := range m.jobs.jobs {
+		if snapshot := job.snapshot(); snapshot.Status != models.JobRunning {
+			finished = append(finished, snapshot)
+		}
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].CreatedAt.Before(finished[j].CreatedAt)
+	})
+	for _, job := range finished {
+		if len(m.jobs.jobs) <= maxRetainedJobs {
+			break
+		}
+		delete(m.jobs.jobs, job.ID)
+	}
+}
+
+// ListJobs returns recent jobs, newest first, optionally only those of one service
+func (m *Manager) ListJobs(serviceName string) []models.Job {
+	m.jobs.mutex.RLock()
+	defer m.jobs.mutex.RUnlock()
+
+	jobs := make([]models.Job, 0, len(m.jobs.jobs))
+	for _, job := range m.jobs.jobs {
+		snapshot := job.snapshot()
+		if serviceName == "" || snapshot.ServiceName == serviceName {
+			jobs = append(jobs, snapshot)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+// GetJob returns a job by ID
+func (m *Manager) GetJob(id string) (*models.Job, error) {
+	job, err := m.trackedJob(id)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := job.snapshot()
+	return &snapshot, nil
+}
+
+// GetJobLogs returns the output a job has written so far
+func (m *Manager) GetJobLogs(id string) (string, error) {
+	job, err := m.trackedJob(id)
+	if err != nil {
+		return "", err
+	}
+	job.mutex.RLock()
+	defer job.mutex.RUnlock()
+	return string(job.logs), nil
+}
+
+// trackedJob looks up a job by ID
+func (m *Manager) trackedJob(id string) (*trackedJob, error) {
+	m.jobs.mutex.RLock()
+	defer m.jobs.mutex.RUnlock()
+
+	job, exists := m.jobs.jobs[id]
+	if !exists {
+		return nil, fmt.Errorf("job %s not found", id)
+	}
+	return job, nil
+}
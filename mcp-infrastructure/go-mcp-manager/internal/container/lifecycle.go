@@ -0,0 +1,177 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/webhooks"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// stoppedBucket is the state bucket recording when users stopped their containers
+const stoppedBucket = "stopped"
+
+// stopRecordBuckets hold the records of why a container was stopped, cleared when it starts again
+var stopRecordBuckets = []string{stoppedBucket, scheduledOffBucket, preemptedBucket, checkpointedBucket, overBudgetBucket, crashLoopBucket}
+
+// StopContainer stops a container without removing it. The container, its slug and its
+// route configuration are kept, while the route itself is disabled until it is started again.
+func (m *Manager) StopContainer(ctx context.Context, serviceName string) (*models.Container, error) {
+	return m.stopContainer(ctx, serviceName, false)
+}
+
+// stopContainer stops a container for a user or, when scheduled, for its schedule; the container
+// then reports StatusScheduledOff and is started again when its next window opens
+func (m *Manager) stopContainer(ctx context.Context, serviceName string, scheduled bool) (*models.Container, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	container, exists := m.containers[serviceName]
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+	if container.StoppedAt != nil {
+		return container, nil
+	}
+	if container.Status == models.StatusStarting || container.Status == models.StatusStopping {
+		return nil, fmt.Errorf("container %s is %s, try again once it settles", serviceName, container.Status)
+	}
+
+	// Record the stop first so a manager restart in between does not auto-restart the container
+	stoppedAt := time.Now()
+	if err := m.store.Put(stoppedBucket, serviceName, stoppedAt); err != nil {
+		return nil, fmt.Errorf("failed to record stopped container: %w", err)
+	}
+	stoppedStatus := models.StatusStopped
+	if scheduled {
+		stoppedStatus = models.StatusScheduledOff
+		if err := m.store.Put(scheduledOffBucket, serviceName, stoppedAt); err != nil {
+			return nil, fmt.Errorf("failed to record scheduled stop: %w", err)
+		}
+	}
+
+	previousStatus := container.Status
+	container.Status = models.StatusStopping
+
+	output, err := m.stopProcess(ctx, container)
+	m.inspect.invalidate(container.ID)
+	if err != nil {
+		container.Status = previousStatus
+		m.forgetStopped(ctx, serviceName)
+		return nil, fmt.Errorf("failed to stop container: %w, output: %s", err, string(output))
+	}
+	m.markStoppedUnsafe(ctx, container, stoppedStatus, stoppedAt)
+
+	m.logger.InfoContext(ctx, "Container stopped",
+		slog.String("service", serviceName),
+		slog.String("slug", container.Slug),
+		slog.Bool("scheduled", scheduled))
+
+	return container, nil
+}
+
+// markStoppedUnsafe records that container's process stopped: its dependencies are stopped, its
+// route is disabled and the stop is published (caller must hold lock)
+func (m *Manager) markStoppedUnsafe(ctx context.Context, container *models.Container, status models.ContainerStatus, stoppedAt time.Time) {
+	m.stopDependencies(ctx, container)
+
+	if container.Slug != "" {
+		if err := m.traefikManager.RemoveMCPService(ctx, container.Slug); err != nil {
+			m.logger.WarnContext(ctx, "Failed to disable Traefik route for stopped container",
+				slog.String("slug", container.Slug),
+				slog.String("service", container.ServiceName),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	container.Status = status
+	container.StoppedAt = &stoppedAt
+	container.UpdatedAt = stoppedAt
+	delete(m.containerHealth, container.Name)
+
+	if instanceID, exists := container.Environment["MCP_INSTANCE_ID"]; exists {
+		if err := m.eventPublisher.PublishStatusUpdate(ctx, instanceID, container.ServiceName, "stopped", container.ID, ""); err != nil {
+			m.logger.WarnContext(ctx, "Failed to publish stopped status",
+				slog.String("instance_id", instanceID),
+				slog.String("error", err.Error()))
+		}
+	}
+	m.notifyWebhook(webhooks.EventContainerStopped, container, "")
+}
+
+// StartContainer starts a container previously stopped with StopContainer and re-enables its route
+func (m *Manager) StartContainer(ctx context.Context, serviceName string) (*models.Container, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	container, exists := m.containers[serviceName]
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+	if container.Status == models.StatusRunning || container.Status == models.StatusStarting {
+		return container, nil
+	}
+	if m.IsPreempted() {
+		return nil, ErrHostPreempted
+	}
+	// A preempted container gave up its slot and has to wait for one to free up
+	if container.Status == models.StatusPreempted && m.capacityUsedUnsafe() >= m.config.Container.MaxContainers {
+		return nil, fmt.Errorf("container %s was preempted and the maximum container limit is reached (%d)", serviceName, m.config.Container.MaxContainers)
+	}
+	if err := m.checkBudget(container.WorkspaceID); err != nil {
+		return nil, err
+	}
+
+	if err := m.clearStopRecords(serviceName); err != nil {
+		return nil, err
+	}
+	container.StoppedAt = nil
+	delete(m.crashLoops.exits, serviceName)
+
+	if err := m.restartContainer(ctx, container); err != nil {
+		return container, fmt.Errorf("failed to start container: %w", err)
+	}
+	m.notifyWebhook(webhooks.EventContainerStarted, container, "")
+
+	m.logger.InfoContext(ctx, "Container started by user",
+		slog.String("service", serviceName),
+		slog.String("slug", container.Slug))
+
+	return container, nil
+}
+
+// stoppedByUser returns when a user stopped a container, or nil if they did not
+func (m *Manager) stoppedByUser(serviceName string) *time.Time {
+	var stoppedAt time.Time
+	found, err := m.store.Get(stoppedBucket, serviceName, &stoppedAt)
+	if err != nil || !found {
+		return nil
+	}
+	return &stoppedAt
+}
+
+// clearStopRecords drops the records of why a container was stopped before it starts again
+func (m *Manager) clearStopRecords(serviceName string) error {
+	for _, bucket := range stopRecordBuckets {
+		if err := m.store.Delete(bucket, serviceName); err != nil {
+			return fmt.Errorf("failed to clear stopped container: %w", err)
+		}
+	}
+	return nil
+}
+
+// forgetStopped drops the stop records of a container that is deleted or archived
+func (m *Manager) forgetStopped(ctx context.Context, serviceName string) {
+	for _, bucket := range stopRecordBuckets {
+		if !m.store.Has(bucket, serviceName) {
+			continue
+		}
+		if err := m.store.Delete(bucket, serviceName); err != nil {
+			m.logger.WarnContext(ctx, "Failed to clear stopped record",
+				slog.String("service", serviceName),
+				slog.String("error", err.Error()))
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package container
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestUserStoppedContainers(t *testing.T) {
+	cfg := &config.Config{
+		Container: config.ContainerConfig{
+			NamePrefix:    "test-",
+			MaxContainers: 10,
+		},
+		State: config.StateConfig{
+			Dir: t.TempDir(),
+		},
+	}
+	manager := newTestManager(cfg)
+
+	stoppedAt := time.Now()
+	if err := manager.store.Put(stoppedBucket, "paused", stoppedAt); err != nil {
+		t.Fatalf("Expected stopped record to be stored, got %v", err)
+	}
+	restored := manager.stoppedByUser("paused")
+	if restored == nil || !restored.Equal(stoppedAt) {
+		t.Errorf("Expected stopped_at %v to be restored, got %v", stoppedAt, restored)
+	}
+	if manager.stoppedByUser("other") != nil {
+		t.Error("Expected no stopped record for an unknown service")
+	}
+
+	paused := &models.Container{
+		Name:        "test-paused",
+		ServiceName: "paused",
+		Slug:        "paused-abc123",
+		Status:      models.StatusStopped,
+		StoppedAt:   restored,
+	}
+	manager.containers["paused"] = paused
+	if manager.shouldContainerBeRunning(paused) {
+		t.Error("Expected a user-stopped container not to be auto-restarted")
+	}
+
+	container, err := manager.StopContainer(context.Background(), "paused")
+	if err != nil || container.Slug != "paused-abc123" {
+		t.Errorf("Expected stopping a stopped container to be a no-op, got %v", err)
+	}
+
+	// Without podman the stop fails and the container must be left as it was
+	manager.containers["busy"] = &models.Container{
+		ID:          "does-not-exist",
+		Name:        "test-busy",
+		ServiceName: "busy",
+		Status:      models.StatusRunning,
+	}
+	if _, err := manager.StopContainer(context.Background(), "busy"); err == nil {
+		t.Skip("podman is available; skipping stop failure assertions")
+	}
+	if manager.containers["busy"].Status != models.StatusRunning {
+		t.Errorf("Expected status running after a failed stop, got %s", manager.containers["busy"].Status)
+	}
+	if manager.store.Has(stoppedBucket, "busy") {
+		t.Error("Expected stopped record to be cleared after a failed stop")
+	}
+
+	if _, err := manager.ArchiveContainer(context.Background(), "paused"); err != nil {
+		t.Fatalf("Expected archive to succeed, got %v", err)
+	}
+	if manager.store.Has(stoppedBucket, "paused") {
+		t.Error("Expected archiving to clear the stopped record")
+	}
+}
@@ -0,0 +1,322 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// Bounds accepted for route limits
+const (
+	maxRouteHeaderBytes        = 1 << 20
+	maxRouteTimeoutSeconds     = 24 * 60 * 60
+	maxRouteConcurrentRequests = 100000
+)
+
+// saturationWindow is how long a rejection over an instance's concurrency cap marks it saturated
+const saturationWindow = time.Minute
+
+// saturationState counts the requests the proxy rejected over each instance's concurrency cap
+type saturationState struct {
+	mutex     sync.Mutex
+	instances map[string]*saturationCounts
+}
+
+// saturationCounts are the rejections of one instance; recent holds those within saturationWindow
+type saturationCounts struct {
+	total  uint64
+	last   time.Time
+	recent []time.Time
+}
+
+// proxyLimitsPath returns the manager path the proxy asks whether a request fits a route's header limit
+func proxyLimitsPath(slug string) string {
+	return "/proxy/limits/" + slug
+}
+
+// limitExceededPath returns the manager path the proxy fetches its error page for a route's 413 or 504
+// from; the proxy fills in the status
+func limitExceededPath(slug string) string {
+	return "/proxy/limit-exceeded/" + slug + "/{status}"
+}
+
+// validateRouteLimits checks the size and timeout limits of a route
+func validateRouteLimits(route *models.RouteConfig) error {
+	limits := route.Limits
+	if limits == nil {
+		return nil
+	}
+	if limits.MaxRequestBodyBytes < 0 || limits.MaxResponseBodyBytes < 0 {
+		return fmt.Errorf("route.limits body sizes must not be negative")
+	}
+	if limits.MaxHeaderBytes < 0 || limits.MaxHeaderBytes > maxRouteHeaderBytes {
+		return fmt.Errorf("route.limits.max_header_bytes must be between 0 and %d", maxRouteHeaderBytes)
+	}
+	if limits.TimeoutSeconds < 0 || limits.TimeoutSeconds > maxRouteTimeoutSeconds {
+		return fmt.Errorf("route.limits.timeout_seconds must be between 0 and %d", maxRouteTimeoutSeconds)
+	}
+	if limits.MaxConcurrentRequests < 0 || limits.MaxConcurrentRequests > maxRouteConcurrentRequests {
+		return fmt.Errorf("route.limits.max_concurrent_requests must be between 0 and %d", maxRouteConcurrentRequests)
+	}
+	// Body limits are enforced by buffering, which holds event streams back
+	if routeStreaming(route) && (limits.MaxRequestBodyBytes > 0 || limits.MaxResponseBodyBytes > 0) {
+		return fmt.Errorf("route.limits body sizes cannot be used with the %s transport", route.Transport)
+	}
+	return nil
+}
+
+// resolveRouteLimits merges a route's limits into the proxy-wide defaults, returning nil when the
+// route runs without any. Streaming routes do not take the default body limits.
+func resolveRouteLimits(defaults config.TraefikRouteLimitsConfig, route *models.RouteConfig) *models.RouteLimits {
+	limits := models.RouteLimits{
+		MaxHeaderBytes:        defaults.MaxHeaderBytes,
+		TimeoutSeconds:        int(defaults.Timeout.Seconds()),
+		MaxConcurrentRequests: defaults.MaxConcurrentRequests,
+	}
+	if !routeStreaming(route) {
+		limits.MaxRequestBodyBytes = defaults.MaxRequestBodyBytes
+		limits.MaxResponseBodyBytes = defaults.MaxResponseBodyBytes
+	}
+	if route != nil && route.Limits != nil {
+		override := route.Limits
+		if override.MaxRequestBodyBytes > 0 {
+			limits.MaxRequestBodyBytes = override.MaxRequestBodyBytes
+		}
+		if override.MaxResponseBodyBytes > 0 {
+			limits.MaxResponseBodyBytes = override.MaxResponseBodyBytes
+		}
+		if override.MaxHeaderBytes > 0 {
+			limits.MaxHeaderBytes = override.MaxHeaderBytes
+		}
+		if override.TimeoutSeconds > 0 {
+			limits.TimeoutSeconds = override.TimeoutSeconds
+		}
+		if override.MaxConcurrentRequests > 0 {
+			limits.MaxConcurrentRequests = override.MaxConcurrentRequests
+		}
+	}
+	if limits == (models.RouteLimits{}) {
+		return nil
+	}
+	return &limits
+}
+
+// smallestLimit returns the tighter of two body limits, where zero is no limit
+func smallestLimit(a, b int64) int64 {
+	if a == 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// headerBytes returns the size of the headers a client sent, leaving out those the proxy adds when
+// it forwards the request to the manager
+func headerBytes(header http.Header) int {
+	size := 0
+	for name, values := range header {
+		if strings.HasPrefix(name, "X-Forwarded-") || name == "X-Real-Ip" {
+			continue
+		}
+		for _, value := range values {
+			// "Name: value\r\n"
+			size += len(name) + len(value) + 4
+		}
+	}
+	return size
+}
+
+// routeLimitsUnsafe returns the container routed under slug and its limits. The caller holds the mutex.
+func (m *Manager) routeLimitsUnsafe(slug string) (*models.Container, *models.RouteLimits) {
+	container := m.slugRouterUnsafe(slug)
+	if container == nil {
+		return nil, nil
+	}
+	return container, resolveRouteLimits(m.config.Traefik.Limits, container.Route)
+}
+
+// CheckProxyRequestLimits checks the headers of a request the proxy received for the route with
+// slug against its limit, returning the status and message to reject it with or 0 to let it through
+func (m *Manager) CheckProxyRequestLimits(slug string, header http.Header) (int, string) {
+	m.mutex.RLock()
+	container, limits := m.routeLimitsUnsafe(slug)
+	m.mutex.RUnlock()
+
+	if container == nil || limits == nil || limits.MaxHeaderBytes == 0 {
+		return 0, ""
+	}
+	if size := headerBytes(header); size > limits.MaxHeaderBytes {
+		return http.StatusRequestHeaderFieldsTooLarge, fmt.Sprintf("request headers of %d bytes exceed the %d byte limit of MCP server %s",
+			size, limits.MaxHeaderBytes, describeLimitedContainer(container))
+	}
+	return 0, ""
+}
+
+// ProxyLimitExceeded logs a request the proxy answered for the route with slug with status: a body
+// over its size limit, a request over its concurrency cap or a response that took too long. It
+// returns the message for the client and, for a rejection over the cap, when to retry.
+func (m *Manager) ProxyLimitExceeded(ctx context.Context, slug string, status int) (string, time.Duration) {
+	m.mutex.RLock()
+	container, limits := m.routeLimitsUnsafe(slug)
+	m.mutex.RUnlock()
+
+	if container == nil {
+		return fmt.Sprintf("MCP server %s answered with %d", slug, status), 0
+	}
+	if limits == nil {
+		limits = &models.RouteLimits{}
+	}
+
+	var message string
+	var retryAfter time.Duration
+	switch status {
+	case http.StatusRequestEntityTooLarge:
+		// The proxy reports both directions with the same status
+		message = fmt.Sprintf("request or response body of MCP server %s exceeds its limit", describeLimitedContainer(container))
+		if limits.MaxRequestBodyBytes > 0 || limits.MaxResponseBodyBytes > 0 {
+			message += fmt.Sprintf(" (request %s, response %s)",
+				describeBodyLimit(limits.MaxRequestBodyBytes), describeBodyLimit(limits.MaxResponseBodyBytes))
+		}
+	case http.StatusTooManyRequests:
+		// The proxy does not queue, so a slot frees up about when a request in flight completes
+		retryAfter = m.recordSaturation(container.ServiceName)
+		message = fmt.Sprintf("MCP server %s is handling its limit of %d concurrent requests, retry in %ds",
+			describeLimitedContainer(container), limits.MaxConcurrentRequests, int(retryAfter.Seconds()))
+		if limits.MaxConcurrentRequests == 0 {
+			message = fmt.Sprintf("MCP server %s is receiving too many requests", describeLimitedContainer(container))
+		}
+	case http.StatusGatewayTimeout:
+		message = fmt.Sprintf("MCP server %s did not respond in time", describeLimitedContainer(container))
+		if limits.TimeoutSeconds > 0 {
+			message = fmt.Sprintf("MCP server %s did not start responding within %ds", describeLimitedContainer(container), limits.TimeoutSeconds)
+		}
+	default:
+		message = fmt.Sprintf("MCP server %s answered with %d", describeLimitedContainer(container), status)
+	}
+
+	m.logger.WarnContext(ctx, "Proxy limit exceeded",
+		slog.String("service", container.ServiceName),
+		slog.String("instance_id", container.Environment["MCP_INSTANCE_ID"]),
+		slog.String("slug", slug),
+		slog.Int("status", status))
+	return message, retryAfter
+}
+
+// recordSaturation counts a request rejected over a container's concurrency cap and returns how
+// long the client should wait: the container's average response time, at least a second
+func (m *Manager) recordSaturation(serviceName string) time.Duration {
+	now := time.Now()
+	m.saturation.mutex.Lock()
+	if m.saturation.instances == nil {
+		m.saturation.instances = make(map[string]*saturationCounts)
+	}
+	counts, exists := m.saturation.instances[serviceName]
+	if !exists {
+		counts = &saturationCounts{}
+		m.saturation.instances[serviceName] = counts
+	}
+	counts.total++
+	counts.last = now
+	counts.recent = append(recentRejections(counts.recent, now), now)
+	m.saturation.mutex.Unlock()
+
+	retryAfter := time.Second
+	m.traffic.mutex.Lock()
+	if stats, exists := m.traffic.stats[serviceName]; exists && stats.Requests > 0 {
+		average := time.Duration(stats.TotalLatencyMs/float64(stats.Requests)) * time.Millisecond
+		retryAfter = max(retryAfter, average.Round(time.Second))
+	}
+	m.traffic.mutex.Unlock()
+	return retryAfter
+}
+
+// recentRejections drops the rejections older than saturationWindow
+func recentRejections(rejections []time.Time, now time.Time) []time.Time {
+	kept := rejections[:0]
+	for _, at := range rejections {
+		if now.Sub(at) < saturationWindow {
+			kept = append(kept, at)
+		}
+	}
+	return kept
+}
+
+// concurrencyStatus reports the concurrency cap of container, nil when it has none
+func (m *Manager) concurrencyStatus(container *models.Container) *models.ConcurrencyStatus {
+	limits := resolveRouteLimits(m.config.Traefik.Limits, container.Route)
+	if limits == nil || limits.MaxConcurrentRequests == 0 {
+		return nil
+	}
+	status := &models.ConcurrencyStatus{ServiceName: container.ServiceName, Limit: limits.MaxConcurrentRequests}
+
+	m.saturation.mutex.Lock()
+	defer m.saturation.mutex.Unlock()
+	if counts, exists := m.saturation.instances[container.ServiceName]; exists {
+		counts.recent = recentRejections(counts.recent, time.Now())
+		last := counts.last
+		status.Rejected = counts.total
+		status.RejectedLastMinute = len(counts.recent)
+		status.Saturated = len(counts.recent) > 0
+		status.LastRejectedAt = &last
+	}
+	return status
+}
+
+// ConcurrencyStatus reports a container's concurrency cap and how often the proxy rejected
+// requests over it, or nil when the container has no cap
+func (m *Manager) ConcurrencyStatus(serviceName string) (*models.ConcurrencyStatus, error) {
+	m.mutex.RLock()
+	container, exists := m.containers[serviceName]
+	m.mutex.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+	return m.concurrencyStatus(container), nil
+}
+
+// ConcurrencySaturation reports every container with a concurrency cap, saturated ones first
+func (m *Manager) ConcurrencySaturation() []models.ConcurrencyStatus {
+	m.mutex.RLock()
+	containers := make([]*models.Container, 0, len(m.containers))
+	for _, container := range m.containers {
+		containers = append(containers, container)
+	}
+	m.mutex.RUnlock()
+
+	statuses := []models.ConcurrencyStatus{}
+	for _, container := range containers {
+		if status := m.concurrencyStatus(container); status != nil {
+			statuses = append(statuses, *status)
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].RejectedLastMinute != statuses[j].RejectedLastMinute {
+			return statuses[i].RejectedLastMinute > statuses[j].RejectedLastMinute
+		}
+		return statuses[i].ServiceName < statuses[j].ServiceName
+	})
+	return statuses
+}
+
+// describeLimitedContainer names a container and its instance in limit errors
+func describeLimitedContainer(container *models.Container) string {
+	if instanceID := container.Environment["MCP_INSTANCE_ID"]; instanceID != "" {
+		return fmt.Sprintf("%s (instance %s)", container.ServiceName, instanceID)
+	}
+	return container.ServiceName
+}
+
+// describeBodyLimit formats a body limit for limit errors
+func describeBodyLimit(limit int64) string {
+	if limit == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d bytes", limit)
+}
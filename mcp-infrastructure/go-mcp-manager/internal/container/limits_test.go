@@ -0,0 +1,79 @@
+package container
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestRouteLimits(t *testing.T) {
+	defaults := config.TraefikRouteLimitsConfig{MaxRequestBodyBytes: 1 << 20, MaxResponseBodyBytes: 8 << 20, Timeout: 30 * time.Second}
+
+	if err := validateRouteConfig(&models.RouteConfig{Limits: &models.RouteLimits{MaxHeaderBytes: -1}}); err == nil {
+		t.Error("Expected a negative header limit to be rejected")
+	}
+	if err := validateRouteConfig(&models.RouteConfig{Transport: models.TransportSSE, Limits: &models.RouteLimits{MaxResponseBodyBytes: 1024}}); err == nil {
+		t.Error("Expected body limits to be rejected on a streaming route")
+	}
+
+	route := &models.RouteConfig{
+		Buffering: &models.RouteBuffering{MaxRequestBodyBytes: 4096},
+		Limits:    &models.RouteLimits{MaxRequestBodyBytes: 65536, MaxHeaderBytes: 512, TimeoutSeconds: 5},
+	}
+	limits := resolveRouteLimits(defaults, route)
+	if limits.MaxRequestBodyBytes != 65536 || limits.MaxResponseBodyBytes != 8<<20 || limits.TimeoutSeconds != 5 {
+		t.Errorf("Expected the route's limits over the defaults, got %+v", limits)
+	}
+	if streaming := resolveRouteLimits(defaults, &models.RouteConfig{Transport: models.TransportSSE}); streaming.MaxResponseBodyBytes != 0 || streaming.TimeoutSeconds != 30 {
+		t.Errorf("Expected a streaming route to keep only the default timeout, got %+v", streaming)
+	}
+	if resolveRouteLimits(config.TraefikRouteLimitsConfig{}, nil) != nil {
+		t.Error("Expected no limits without defaults or route limits")
+	}
+
+	middlewares, chain := buildRouteMiddlewares("svc-abc", route, nil, limits, "http://localhost:8000")
+	if b := middlewares[bufferingMiddleware].Buffering; b == nil || b.MaxRequestBodyBytes != 4096 || b.MaxResponseBodyBytes != 8<<20 {
+		t.Errorf("Expected the tighter of buffering and limits, got %+v", b)
+	}
+	if e := middlewares[limitErrorsMiddleware].Errors; e == nil || e.Query != "/proxy/limit-exceeded/svc-abc/{status}" {
+		t.Errorf("Expected an error page for 413 and 504, got %+v", e)
+	}
+	if check := middlewares[limitsMiddleware].ForwardAuth; check == nil || check.Address != "http://localhost:8000/proxy/limits/svc-abc" {
+		t.Errorf("Expected the header size check, got %+v", check)
+	}
+	if slices.Index(chain, "mcp-svc-abc-limiterrors") > slices.Index(chain, "mcp-svc-abc-buffering") {
+		t.Errorf("Expected the error page to wrap buffering, got %v", chain)
+	}
+	if _, transport := buildRouteService("svc-abc", "10.88.0.5", 3000, route, limits); transport == nil || transport.ForwardingTimeouts.ResponseHeaderTimeout != "5s" {
+		t.Errorf("Expected a response header timeout, got %+v", transport)
+	}
+
+	cfg := &config.Config{
+		Container: config.ContainerConfig{NamePrefix: "test-", MaxContainers: 10},
+		State:     config.StateConfig{Dir: t.TempDir()},
+	}
+	manager := newTestManager(cfg)
+	manager.containers["search"] = &models.Container{
+		ServiceName: "search",
+		Slug:        "search-1234",
+		Route:       route,
+		Environment: map[string]string{"MCP_INSTANCE_ID": "inst-1"},
+	}
+	header := http.Header{"Accept": {"application/json"}, "X-Forwarded-Uri": {strings.Repeat("a", 1024)}}
+	if status, _ := manager.CheckProxyRequestLimits("search-1234", header); status != 0 {
+		t.Errorf("Expected headers added by the proxy not to count, got %d", status)
+	}
+	header.Set("Cookie", strings.Repeat("a", 1024))
+	if status, message := manager.CheckProxyRequestLimits("search-1234", header); status != http.StatusRequestHeaderFieldsTooLarge || !strings.Contains(message, "inst-1") {
+		t.Errorf("Expected 431 naming the instance, got %d %q", status, message)
+	}
+	if message, _ := manager.ProxyLimitExceeded(context.Background(), "search-1234", http.StatusGatewayTimeout); !strings.Contains(message, "within 5s") {
+		t.Errorf("Expected the timeout in the message, got %q", message)
+	}
+}
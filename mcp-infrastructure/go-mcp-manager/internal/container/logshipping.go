@@ -0,0 +1,385 @@
+package container
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/logship"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// logShippingLabel stores a container's log shipping override
+	logShippingLabel = "mcp.log_shipping"
+	// logOffsetsBucket records the timestamp of the last shipped line, keyed by container ID
+	logOffsetsBucket = "log_offsets"
+	// logShippingReconcileInterval is how often tailers are started for new containers
+	logShippingReconcileInterval = 10 * time.Second
+	// logOffsetSaveInterval bounds how often a tailer persists its offset
+	logOffsetSaveInterval = 5 * time.Second
+	// maxLogLineBytes truncates longer lines so a runaway writer cannot exhaust memory
+	maxLogLineBytes = 64 << 10
+)
+
+// logLabelPattern matches label names valid in Loki, which is the strictest supported sink
+var logLabelPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// logShippingState tracks the log tailer per container and the shipper per sink
+type logShippingState struct {
+	mutex    sync.Mutex
+	tailers  map[string]*logTailer
+	shippers map[logship.Target]*logship.Shipper
+}
+
+// logTailer follows the logs of one container
+type logTailer struct {
+	cancel context.CancelFunc
+}
+
+// parseLogShippingSpec reads the optional log_shipping object from json_spec
+func parseLogShippingSpec(jsonSpec map[string]interface{}) (*models.LogShippingConfig, error) {
+	raw, exists := jsonSpec["log_shipping"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+
+	if _, ok := raw.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("log_shipping must be an object")
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("log_shipping is not valid JSON: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	spec := &models.LogShippingConfig{}
+	if err := decoder.Decode(spec); err != nil {
+		return nil, fmt.Errorf("invalid log_shipping: %w", err)
+	}
+
+	if err := validateLogShippingConfig(spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// validateLogShippingConfig checks the sink type, its URL and the extra label names
+func validateLogShippingConfig(spec *models.LogShippingConfig) error {
+	if spec == nil {
+		return nil
+	}
+
+	if spec.Sink != "" {
+		if !logship.ValidSinkType(spec.Sink) {
+			return fmt.Errorf("log_shipping.sink must be %q, %q or %q", logship.SinkLoki, logship.SinkOpenSearch, logship.SinkHTTP)
+		}
+		parsed, err := url.Parse(spec.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("log_shipping.url must be an http or https URL")
+		}
+	} else if spec.URL != "" || spec.Index != "" {
+		return fmt.Errorf("log_shipping.url and log_shipping.index require log_shipping.sink")
+	}
+
+	for name := range spec.Labels {
+		if !logLabelPattern.MatchString(name) {
+			return fmt.Errorf("log_shipping label %q must contain only letters, digits and underscores", name)
+		}
+	}
+	return nil
+}
+
+// logShippingTarget returns the sink a container's logs go to, if any. The default sink's
+// Authorization header is only sent to the default URL, never to a sink an instance chose.
+func (m *Manager) logShippingTarget(container *models.Container) (logship.Target, bool) {
+	defaults := m.config.LogShipping
+	override := container.LogShipping
+
+	if override != nil && override.Disabled {
+		return logship.Target{}, false
+	}
+	if override != nil && override.Sink != "" {
+		target := logship.Target{Type: override.Sink, URL: override.URL, Index: override.Index}
+		if target.URL == defaults.URL {
+			target.AuthHeader = defaults.AuthHeader
+		}
+		return target, true
+	}
+	if defaults.Sink == "" || defaults.URL == "" {
+		return logship.Target{}, false
+	}
+	return logship.Target{
+		Type:       defaults.Sink,
+		URL:        defaults.URL,
+		Index:      defaults.Index,
+		AuthHeader: defaults.AuthHeader,
+	}, true
+}
+
+// startLogShipping keeps a log tailer running for every running container with a sink
+func (m *Manager) startLogShipping() {
+	if sink := m.config.LogShipping.Sink; sink != "" {
+		m.logger.Info("Container log shipping enabled",
+			slog.String("sink", sink),
+			slog.String("url", m.config.LogShipping.URL))
+	}
+
+	ticker := time.NewTicker(logShippingReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		m.reconcileLogShipping(m.healthCtx)
+
+		select {
+		case <-m.healthCtx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reconcileLogShipping starts tailers for running containers, stops those of containers that
+// are gone, and forgets the offsets of containers the manager no longer tracks
+func (m *Manager) reconcileLogShipping(ctx context.Context) {
+	m.mutex.RLock()
+	running := make(map[string]models.Container)
+	known := make(map[string]bool, len(m.containers))
+	for _, container := range m.containers {
+		if container.ID == "" {
+			continue
+		}
+		known[container.ID] = true
+		if container.Status == models.StatusRunning {
+			running[container.ID] = *container
+		}
+	}
+	m.mutex.RUnlock()
+
+	m.logShipping.mutex.Lock()
+	if m.logShipping.tailers == nil {
+		m.logShipping.tailers = make(map[string]*logTailer)
+		m.logShipping.shippers = make(map[logship.Target]*logship.Shipper)
+	}
+
+	for id, tailer := range m.logShipping.tailers {
+		if _, exists := running[id]; !exists {
+			tailer.cancel()
+			delete(m.logShipping.tailers, id)
+		}
+	}
+
+	for id, container := range running {
+		if _, exists := m.logShipping.tailers[id]; exists {
+			continue
+		}
+		target, ok := m.logShippingTarget(&container)
+		if !ok {
+			continue
+		}
+		shipper, err := m.logShipperUnsafe(target)
+		if err != nil {
+			m.logger.WarnContext(ctx, "Failed to create log sink",
+				slog.String("service", container.ServiceName),
+				slog.String("error", err.Error()))
+			continue
+		}
+
+		tailCtx, cancel := context.WithCancel(ctx)
+		tailer := &logTailer{cancel: cancel}
+		m.logShipping.tailers[id] = tailer
+		go m.tailContainerLogs(tailCtx, tailer, container, shipper)
+	}
+	m.logShipping.mutex.Unlock()
+
+	if ids, err := m.store.Keys(logOffsetsBucket); err == nil {
+		for _, id := range ids {
+			if !known[id] {
+				_ = m.store.Delete(logOffsetsBucket, id)
+			}
+		}
+	}
+}
+
+// logShipperUnsafe returns the shipper for a target, starting one on first use.
+// Caller must hold m.logShipping.mutex.
+func (m *Manager) logShipperUnsafe(target logship.Target) (*logship.Shipper, error) {
+	if shipper, exists := m.logShipping.shippers[target]; exists {
+		return shipper, nil
+	}
+
+	sink, err := logship.NewSink(target, &http.Client{Timeout: m.config.LogShipping.Timeout})
+	if err != nil {
+		return nil, err
+	}
+	shipper := logship.NewShipper(sink, m.config.LogShipping.BatchSize, m.config.LogShipping.FlushInterval, m.logger)
+	m.logShipping.shippers[target] = shipper
+	go shipper.Run(m.healthCtx)
+	return shipper, nil
+}
+
+// logLabels returns the labels attached to every line of a container
+func logLabels(container *models.Container) map[string]string {
+	labels := make(map[string]string)
+	if container.LogShipping != nil {
+		for k, v := range container.LogShipping.Labels {
+			labels[k] = v
+		}
+	}
+
+	labels["service_name"] = container.ServiceName
+	labels["container_name"] = container.Name
+	if instanceID := container.Environment["MCP_INSTANCE_ID"]; instanceID != "" {
+		labels["instance_id"] = instanceID
+	}
+	if container.WorkspaceID != "" {
+		labels["workspace_id"] = container.WorkspaceID
+	}
+	return labels
+}
+
+// tailContainerLogs follows a container's stdout and stderr from the last shipped line until the
+// container exits or the tailer is stopped, so logs outlive the container being recreated
+func (m *Manager) tailContainerLogs(ctx context.Context, tailer *logTailer, container models.Container, shipper *logship.Shipper) {
+	defer func() {
+		m.logShipping.mutex.Lock()
+		if m.logShipping.tailers[container.ID] == tailer {
+			delete(m.logShipping.tailers, container.ID)
+		}
+		m.logShipping.mutex.Unlock()
+	}()
+
+	var resumeFrom time.Time
+	if _, err := m.store.Get(logOffsetsBucket, container.ID, &resumeFrom); err != nil {
+		m.logger.WarnContext(ctx, "Failed to read log offset",
+			slog.String("service", container.ServiceName),
+			slog.String("error", err.Error()))
+	}
+
+	args := []string{"logs", "--follow", "--timestamps"}
+	if !resumeFrom.IsZero() {
+		args = append(args, "--since", resumeFrom.Format(time.RFC3339Nano))
+	}
+	args = append(args, container.ID)
+
+	cmd := podmanCommand(ctx, m.logger, args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return
+	}
+	if err := cmd.Start(); err != nil {
+		m.logger.WarnContext(ctx, "Failed to follow container logs",
+			slog.String("service", container.ServiceName),
+			slog.String("error", err.Error()))
+		return
+	}
+
+	labels := logLabels(&container)
+	var (
+		offsetMutex sync.Mutex
+		offset      = resumeFrom
+		savedAt     time.Time
+	)
+	saveOffset := func(force bool) {
+		offsetMutex.Lock()
+		defer offsetMutex.Unlock()
+		if offset.Equal(resumeFrom) || (!force && time.Since(savedAt) < logOffsetSaveInterval) {
+			return
+		}
+		if err := m.store.Put(logOffsetsBucket, container.ID, offset); err == nil {
+			savedAt = time.Now()
+		}
+	}
+
+	ship := func(stream string) func(string) {
+		return func(line string) {
+			timestamp, message := parseLogLine(line)
+			// --since is inclusive, so lines at or before the offset were shipped already
+			if !resumeFrom.IsZero() && !timestamp.After(resumeFrom) {
+				return
+			}
+			shipper.Ship(logship.Entry{Timestamp: timestamp, Line: message, Stream: stream, Labels: labels})
+
+			offsetMutex.Lock()
+			if timestamp.After(offset) {
+				offset = timestamp
+			}
+			offsetMutex.Unlock()
+			saveOffset(false)
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		readLogLines(stdout, ship("stdout"))
+	}()
+	go func() {
+		defer wg.Done()
+		readLogLines(stderr, ship("stderr"))
+	}()
+	wg.Wait()
+
+	_ = cmd.Wait()
+	saveOffset(true)
+}
+
+// readLogLines calls fn for each line read, truncating lines longer than maxLogLineBytes
+func readLogLines(r io.Reader, fn func(string)) {
+	reader := bufio.NewReader(r)
+	var line []byte
+	for {
+		fragment, isPrefix, err := reader.ReadLine()
+		if len(line) < maxLogLineBytes {
+			line = append(line, fragment[:min(len(fragment), maxLogLineBytes-len(line))]...)
+		}
+		if err != nil {
+			if len(line) > 0 {
+				fn(string(line))
+			}
+			return
+		}
+		if !isPrefix {
+			fn(string(line))
+			line = line[:0]
+		}
+	}
+}
+
+// parseLogLine splits a `podman logs --timestamps` line into its timestamp and message; lines
+// without a timestamp are stamped with the current time
+func parseLogLine(line string) (time.Time, string) {
+	if stamp, message, found := strings.Cut(line, " "); found {
+		if timestamp, err := time.Parse(time.RFC3339Nano, stamp); err == nil {
+			return timestamp, message
+		}
+	}
+	return time.Now(), line
+}
+
+// discoverLogShipping restores the log shipping override persisted on a podman container
+func (m *Manager) discoverLogShipping(ctx context.Context, containerID string) *models.LogShippingConfig {
+	var spec models.LogShippingConfig
+	if !m.discoverJSONLabel(ctx, containerID, logShippingLabel, &spec) {
+		return nil
+	}
+	return &spec
+}
@@ -0,0 +1,70 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestLogShipping(t *testing.T) {
+	spec, err := parseLogShippingSpec(map[string]interface{}{
+		"log_shipping": map[string]interface{}{
+			"sink":   "loki",
+			"url":    "https://loki.example.com",
+			"labels": map[string]interface{}{"team": "search"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected valid log_shipping, got %v", err)
+	}
+	if spec.Sink != "loki" || spec.Labels["team"] != "search" {
+		t.Errorf("Expected loki sink with team label, got %+v", spec)
+	}
+
+	invalid := []map[string]interface{}{
+		{"sink": "syslog", "url": "https://logs.example.com"},
+		{"sink": "http", "url": "file:///var/log/mcp"},
+		{"url": "https://logs.example.com"},
+		{"labels": map[string]interface{}{"team-name": "search"}},
+	}
+	for _, raw := range invalid {
+		if _, err := parseLogShippingSpec(map[string]interface{}{"log_shipping": raw}); err == nil {
+			t.Errorf("Expected log_shipping %v to be rejected", raw)
+		}
+	}
+
+	cfg := &config.Config{LogShipping: config.LogShippingConfig{
+		Sink:       "opensearch",
+		URL:        "https://search.example.com",
+		AuthHeader: "Basic secret",
+	}}
+	manager := newTestManager(cfg)
+
+	target, ok := manager.logShippingTarget(&models.Container{})
+	if !ok || target.Type != "opensearch" || target.AuthHeader != "Basic secret" {
+		t.Errorf("Expected the default sink with its auth header, got %+v", target)
+	}
+	target, ok = manager.logShippingTarget(&models.Container{LogShipping: spec})
+	if !ok || target.URL != "https://loki.example.com" || target.AuthHeader != "" {
+		t.Errorf("Expected the instance sink without the default auth header, got %+v", target)
+	}
+	if _, ok := manager.logShippingTarget(&models.Container{LogShipping: &models.LogShippingConfig{Disabled: true}}); ok {
+		t.Errorf("Expected disabled log shipping to have no target")
+	}
+
+	timestamp, message := parseLogLine("2025-01-02T03:04:05.123456789Z listening on :8000")
+	if message != "listening on :8000" || timestamp.Nanosecond() != 123456789 {
+		t.Errorf("Expected timestamp and message to be split, got %v %q", timestamp, message)
+	}
+
+	labels := logLabels(&models.Container{
+		ServiceName: "github",
+		Environment: map[string]string{"MCP_INSTANCE_ID": "inst-1"},
+		WorkspaceID: "ws-1",
+		LogShipping: &models.LogShippingConfig{Labels: map[string]string{"instance_id": "spoofed"}},
+	})
+	if labels["instance_id"] != "inst-1" || labels["workspace_id"] != "ws-1" || labels["service_name"] != "github" {
+		t.Errorf("Expected instance labels to override custom labels, got %v", labels)
+	}
+}
@@ -5,19 +5,28 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net/http"
-	"os/exec"
 	"regexp"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/agentarea/mcp-manager/internal/callbacks"
+	"github.com/agentarea/mcp-manager/internal/chaos"
 	"github.com/agentarea/mcp-manager/internal/config"
 	"github.com/agentarea/mcp-manager/internal/events"
-	"github.com/agentarea/mcp-manager/internal/models"
+	"github.com/agentarea/mcp-manager/internal/mtls"
+	"github.com/agentarea/mcp-manager/internal/registry"
+	"github.com/agentarea/mcp-manager/internal/retry"
+	"github.com/agentarea/mcp-manager/internal/secrets"
+	"github.com/agentarea/mcp-manager/internal/state"
+	"github.com/agentarea/mcp-manager/internal/webhooks"
+	schema "github.com/agentarea/mcp-manager/pkg/events"
+	"github.com/agentarea/mcp-manager/pkg/models"
 )
 
 // Manager manages container lifecycle for MCP servers
@@ -25,12 +34,46 @@ type Manager struct {
 	config          *config.Config
 	containers      map[string]*models.Container
 	containerHealth map[string]*HealthCheckResult // Track health status
+	healthCounters  map[string]*healthCounters    // Consecutive probe outcomes per container
+	healthHistory   map[string]*healthHistory     // Recent health results per container
 	mutex           sync.RWMutex
 	logger          *slog.Logger
 	traefikManager  *TraefikManager
 	validator       *ContainerValidator
 	healthChecker   *HealthChecker
 	eventPublisher  *events.EventPublisher
+	secrets         *secrets.SecretResolver // Nil until set; used by the doctor and environment updates
+	webhooks        *webhooks.Dispatcher
+	callbacks       *callbacks.Reporter
+	chaos           *chaos.Controller
+	preemption      preemptionState
+	cordon          cordonState
+	jobs            jobTracker
+	gc              gcState
+	mirror          mirrorState
+	logShipping     logShippingState
+	traffic         trafficState
+	slo             sloState
+	alerts          alertState
+	timeline        timelineState
+	revisions       revisionState
+	crashLoops      crashLoopState
+	startups        startupState
+	circuits        circuitState
+	saturation      saturationState
+	sessions        sessionState
+	scaling         scalingState
+	budgets         budgetState
+	admission       admissionState
+	scheduling      scheduleState
+	pki             *mtls.Authority
+	pkiErr          error
+	upstreamPool    *upstreamPool
+	inspect         *inspectCache
+	registry        *registry.Client
+	store           *state.Store
+	createGate      *createGate
+	operations      operationTracker
 	healthCtx       context.Context
 	healthCancel    context.CancelFunc
 }
@@ -39,7 +82,15 @@ type Manager struct {
 func NewManager(cfg *config.Config, logger *slog.Logger) *Manager {
 	traefikManager := NewTraefikManager(cfg, logger)
 	healthChecker := NewHealthChecker(logger)
+	upstreamPool := newUpstreamPool(cfg.Traefik.Upstream)
+	healthChecker.httpClient.Transport = upstreamPool
+	inspect := newInspectCache(cfg.HealthMonitor.InspectCacheTTL, logger)
+	healthChecker.inspect = inspect
+	healthChecker.publishPorts = cfg.Container.PublishPorts
+	configureEngine(cfg.Container.Runtime, cfg.Container.Host, logger)
 	eventPublisher := events.NewEventPublisher(cfg.Redis.URL, logger)
+	webhookDispatcher := webhooks.NewDispatcher(cfg.Webhooks, logger)
+	store := state.NewStore(cfg.State.Dir)
 
 	// Create context for health monitoring
 	healthCtx, healthCancel := context.WithCancel(context.Background())
@@ -48,142 +99,366 @@ func NewManager(cfg *config.Config, logger *slog.Logger) *Manager {
 		config:          cfg,
 		containers:      make(map[string]*models.Container),
 		containerHealth: make(map[string]*HealthCheckResult),
+		healthCounters:  make(map[string]*healthCounters),
+		healthHistory:   make(map[string]*healthHistory),
 		logger:          logger,
 		traefikManager:  traefikManager,
 		healthChecker:   healthChecker,
+		upstreamPool:    upstreamPool,
+		inspect:         inspect,
+		registry:        registry.NewClient(30 * time.Second),
 		eventPublisher:  eventPublisher,
+		webhooks:        webhookDispatcher,
+		callbacks:       callbacks.NewReporter(cfg.Callbacks, cfg.CoreAPIURL, cfg.CoreAPIToken, store, logger),
+		store:           store,
+		createGate:      newCreateGate(cfg.RateLimit.MaxConcurrentCreates, cfg.RateLimit.CreateQueueSize, cfg.RateLimit.CreateQueueTimeout),
 		healthCtx:       healthCtx,
 		healthCancel:    healthCancel,
 	}
 
 	// Create validator with manager reference (after manager is created)
 	manager.validator = NewContainerValidator(logger, manager)
+	manager.initPKI()
+	manager.alerts.notifiers = manager.alertingNotifiers()
+	manager.loadAlertRulesFile()
+
+	// Every status published on Redis is also reported to the Core API
+	eventPublisher.OnStatusUpdate(manager.reportStatus)
+	eventPublisher.SetOutbox(store, cfg.Redis.OutboxFlushInterval)
+	eventPublisher.SetSigningSecret(cfg.Redis.SigningSecret)
 
 	return manager
 }
 
 // Initialize initializes the container manager
 func (m *Manager) Initialize(ctx context.Context) error {
-	m.logger.Info("Initializing container manager")
+	m.logger.InfoContext(ctx, "Initializing container manager")
 
 	// Start health monitoring in background
-	m.logger.Info("Starting health monitoring...")
+	m.logger.InfoContext(ctx, "Starting health monitoring...")
 	go m.startHealthMonitoring()
-	m.logger.Info("Health monitoring started")
+	m.logger.InfoContext(ctx, "Health monitoring started")
+
+	// Watch for spot/preemptible host termination notices
+	go m.startPreemptionWatch()
+
+	// Archive instances that have not been started for a long time
+	go m.startArchiver()
+
+	// Remove images no container has used for a long time
+	go m.startGC()
+
+	// Keep proxy routes pointed at the current container IPs
+	go m.startRouteReconciler()
+
+	// Forward container logs to the configured log sinks
+	go m.startLogShipping()
+
+	// Count proxied requests per instance from the proxy access log
+	go m.startTrafficCollector()
+
+	// Keep the rolling availability and error rate counters of each instance across restarts
+	go m.startSLOTracking()
+
+	// Drop cached podman inspect results when containers change underneath the manager
+	go m.startInspectInvalidation()
+
+	// Start and stop scheduled instances at their window boundaries
+	go m.startScheduler()
+
+	// Remove the containers of sessions that went idle
+	go m.startSessionReaper()
+
+	// Add and remove replicas of scaled instances as their load changes
+	go m.startAutoscaler()
+
+	// Count usage against workspace and global budgets and enforce them
+	go m.startBudgetAccounting()
+
+	// Evaluate alert rules and notify about alerts that fire and resolve
+	go m.startAlerting()
+
+	// Deliver provisioning callbacks to the Core API, including those left in the outbox
+	go m.callbacks.Run(m.healthCtx)
+
+	// Resend lifecycle events Redis did not take, including those left from before a restart
+	go m.eventPublisher.Run(m.healthCtx)
+
+	// Restore maintenance mode before anything can create containers
+	m.loadCordonStatus(ctx)
+
+	// Pulls go through the provisioned mirror, so it runs before anything is created
+	if err := m.ensureRegistryMirror(ctx); err != nil {
+		m.logger.WarnContext(ctx, "Registry mirror unavailable, pulling from upstream registries",
+			slog.String("error", err.Error()))
+	}
 
 	// Discover existing containers
-	m.logger.Info("Discovering existing containers...")
+	m.logger.InfoContext(ctx, "Discovering existing containers...")
 	if err := m.discoverContainers(ctx); err != nil {
-		m.logger.Error("Failed to discover containers", slog.String("error", err.Error()))
+		m.logger.ErrorContext(ctx, "Failed to discover containers", slog.String("error", err.Error()))
 		return err
 	}
-	m.logger.Info("Container discovery completed")
+	m.logger.InfoContext(ctx, "Container discovery completed")
+
+	// Clean up after creates and deletes a previous run did not finish, before the sync recreates
+	// instances that are still pending
+	m.recoverOperations(ctx)
 
 	// Synchronize with Core API to handle pending instances
-	m.logger.Info("Starting Core API synchronization...")
+	m.logger.InfoContext(ctx, "Starting Core API synchronization...")
 	if err := m.syncWithCoreAPI(ctx); err != nil {
-		m.logger.Error("Failed to sync with Core API", slog.String("error", err.Error()))
+		m.logger.ErrorContext(ctx, "Failed to sync with Core API", slog.String("error", err.Error()))
 		// Don't fail initialization - log warning and continue
-		m.logger.Warn("Continuing without full sync - some instances may need manual intervention")
+		m.logger.WarnContext(ctx, "Continuing without full sync - some instances may need manual intervention")
 	}
-	m.logger.Info("Core API synchronization completed")
+	m.logger.InfoContext(ctx, "Core API synchronization completed")
 
 	// Auto-restart containers that should be running
-	m.logger.Info("Starting auto-restart check...")
+	m.logger.InfoContext(ctx, "Starting auto-restart check...")
 	if err := m.autoRestartContainers(ctx); err != nil {
-		m.logger.Error("Failed to auto-restart containers", slog.String("error", err.Error()))
+		m.logger.ErrorContext(ctx, "Failed to auto-restart containers", slog.String("error", err.Error()))
 		// Don't fail initialization - this is not critical
 	}
-	m.logger.Info("Auto-restart check completed")
+	m.logger.InfoContext(ctx, "Auto-restart check completed")
 
-	m.logger.Info("Container manager initialized successfully")
+	m.logger.InfoContext(ctx, "Container manager initialized successfully")
 	return nil
 }
 
 // CreateContainer creates a new container from a template
 func (m *Manager) CreateContainer(ctx context.Context, req models.CreateContainerRequest) (*models.Container, error) {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
+	return m.createContainer(ctx, req, "", "")
+}
 
-	// Check if container already exists
-	if _, exists := m.containers[req.ServiceName]; exists {
-		return nil, fmt.Errorf("container %s already exists", req.ServiceName)
+// createContainer creates a container routed under slug, or under a newly generated slug if empty.
+// When replacing names a tracked container, that container is swapped for the new one once the
+// new spec passed every check, instead of a second one being created.
+func (m *Manager) createContainer(ctx context.Context, req models.CreateContainerRequest, slug, replacing string) (*models.Container, error) {
+	ctx, op, err := m.beginOperation(ctx, operationCreate, req.ServiceName, req.Environment["MCP_INSTANCE_ID"])
+	if err != nil {
+		return nil, err
+	}
+	container, err := m.provisionContainer(ctx, op, req, slug, replacing)
+	m.finishOperation(ctx, op, err)
+	m.recordCreateOutcome(err != nil)
+	if err == nil {
+		m.recordSpecRevision(ctx, req)
 	}
+	return container, err
+}
 
-	// Generate container name using the sanitized service name
-	containerName := m.config.GetContainerName(req.ServiceName)
+// provisionContainer runs the create of createContainer as operation op
+func (m *Manager) provisionContainer(ctx context.Context, op *operation, req models.CreateContainerRequest, slug, replacing string) (*models.Container, error) {
+	// Fingerprint the request as sent, before runtime and source shortcuts rewrite it
+	hash := specHash(req)
+	sentSpec := requestedFromRequest(req)
 
-	// Check container limit
-	if len(m.containers) >= m.config.Container.MaxContainers {
-		return nil, fmt.Errorf("maximum container limit reached (%d)", m.config.Container.MaxContainers)
+	// Bound concurrent podman runs before taking the manager lock
+	if err := m.createGate.acquire(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create container %s: %w", req.ServiceName, err)
 	}
+	defer m.createGate.release()
 
-	// Generate slug for consistent URL routing
-	slug := generateSlug(req.ServiceName)
+	if err := m.applyRuntimeRequest(&req); err != nil {
+		return nil, err
+	}
+	if err := m.checkBudget(req.WorkspaceID); err != nil {
+		return nil, err
+	}
+	if err := m.checkStoragePressure(ctx); err != nil {
+		return nil, err
+	}
 
-	// Create container directly from request
-	container := &models.Container{
-		Name:        containerName,
-		ServiceName: req.ServiceName,
-		Slug:        slug,
-		Image:       req.Image,
-		Status:      models.StatusStarting,
-		Port:        req.Port,
-		URL:         fmt.Sprintf("%s/mcp/%s", m.config.Traefik.ProxyHost, slug),
-		Host:        m.config.Traefik.ProxyHost,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
-		Labels:      req.Labels,
-		Environment: req.Environment,
+	// Build the image from source before taking the manager lock, as builds can take minutes
+	if (req.Image == "") == (req.Source == nil) {
+		return nil, fmt.Errorf("exactly one of image, source and runtime is required")
+	}
+	if req.Source != nil {
+		if err := validateSourceConfig(req.Source); err != nil {
+			return nil, err
+		}
+		image, err := m.buildSourceImage(ctx, req.ServiceName, req.Source)
+		if err != nil {
+			return nil, err
+		}
+		req.Image = image
+	}
+
+	// Reading the image's manifest list goes to the registry, so it is also done before the lock
+	platform, err := m.resolvePlatform(ctx, req.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse a container the host has no room for before pulling its image, unless preempting
+	// lower-priority instances makes room for it; a replacement takes over its predecessor's slot
+	if err := validatePriority(req.Priority); err != nil {
+		return nil, err
+	}
+	for {
+		err := m.admitContainer(ctx, &req, replacing)
+		if err != nil && !errors.Is(err, ErrInsufficientResources) {
+			return nil, err
+		}
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else if replacing == "" && m.slotsFull() {
+			reason = fmt.Sprintf("all %d container slots are taken", m.config.Container.MaxContainers)
+		}
+		if reason == "" || !m.preemptFor(ctx, req.ServiceName, req.Priority, reason) {
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	// podman run would pull a missing image from the upstream, bypassing the registry mirror
+	if m.mirroredImage(req.Image) != "" && podmanCommand(ctx, m.logger, "image", "exists", req.Image).Run() != nil {
+		pullPlatform := ""
+		if platform.Emulated {
+			pullPlatform = platform.Platform
+		}
+		m.pullThroughMirror(ctx, req.Image, pullPlatform, nil)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	container, err := m.prepareContainerUnsafe(req, hash, slug, replacing)
+	if err != nil {
+		return nil, err
+	}
+	container.Platform, container.Emulated = platform.Platform, platform.Emulated
+	container.Provenance = specProvenance(sentSpec, container)
+	containerName, slug := container.Name, container.Slug
+
+	// A replacement is not journaled with its slug, as rolling it back would delete the instance
+	if current, exists := m.containers[replacing]; exists {
+		if err := m.replaceContainerUnsafe(ctx, current, container); err != nil {
+			m.notifyWebhook(webhooks.EventContainerFailed, current, err.Error())
+			return nil, err
+		}
+		m.notifyWebhook(webhooks.EventContainerCreated, current, "")
+		m.logger.InfoContext(ctx, "Container replaced with its changed spec",
+			slog.String("container", containerName),
+			slog.String("id", current.ID),
+			slog.String("service", req.ServiceName))
+		return current, nil
+	}
+	m.journalStep(ctx, op, stepProvision, container)
+
+	if err := m.ensureNetwork(ctx, container.Network); err != nil {
+		m.notifyWebhook(webhooks.EventContainerFailed, container, err.Error())
+		return nil, err
+	}
+	if err := m.createScratchVolumes(ctx, container); err != nil {
+		m.notifyWebhook(webhooks.EventContainerFailed, container, err.Error())
+		m.releaseNetworkUnsafe(ctx, container.Network)
+		return nil, err
+	}
+	if err := m.provisionDependenciesUnsafe(ctx, container); err != nil {
+		m.notifyWebhook(webhooks.EventContainerFailed, container, err.Error())
+		m.removeScratchVolumes(ctx, container)
+		m.releaseNetworkUnsafe(ctx, container.Network)
+		return nil, err
+	}
+
+	// Run the init command to completion before the container starts
+	if container.Init != nil {
+		container.Status = models.StatusInitializing
+		if err := m.runInit(ctx, container); err != nil {
+			container.Status = models.StatusInitFailed
+			m.notifyWebhook(webhooks.EventContainerFailed, container, err.Error())
+			m.removeDependencies(ctx, container)
+			m.removeScratchVolumes(ctx, container)
+			m.releaseNetworkUnsafe(ctx, container.Network)
+			return nil, err
+		}
+		container.Status = models.StatusStarting
+	}
+
+	// Issue the certificates the proxy and the container authenticate each other with
+	if err := m.provisionUpstreamTLS(container); err != nil {
+		container.Status = models.StatusError
+		m.notifyWebhook(webhooks.EventContainerFailed, container, err.Error())
+		m.removeDependencies(ctx, container)
+		m.removeScratchVolumes(ctx, container)
+		m.releaseNetworkUnsafe(ctx, container.Network)
+		return nil, err
 	}
 
 	// Build podman run command
+	m.journalStep(ctx, op, stepRun, container)
 	args := m.buildPodmanRunArgs(container)
 
 	// Execute podman run
-	cmd := exec.CommandContext(ctx, "podman", args...)
-	output, err := cmd.CombinedOutput()
+	output, err := m.podmanRun(ctx, container, args)
 	if err != nil {
 		container.Status = models.StatusError
-		m.logger.Error("Failed to create container",
+		m.logger.ErrorContext(ctx, "Failed to create container",
 			slog.String("container", containerName),
 			slog.String("error", err.Error()),
 			slog.String("output", string(output)))
+		m.notifyWebhook(webhooks.EventContainerFailed, container, err.Error())
+		m.removeDependencies(ctx, container)
+		m.removeScratchVolumes(ctx, container)
+		m.releasePodUnsafe(ctx, container.Pod)
+		m.releaseNetworkUnsafe(ctx, container.Network)
 		return nil, fmt.Errorf("failed to create container: %w", err)
 	}
 
 	// Get container ID from output
 	container.ID = strings.TrimSpace(string(output))
+	m.journalStep(ctx, op, stepRoute, container)
+	m.scheduleChaosCrash(container)
 
 	// Wait for container to be running
 	if err := m.waitForContainer(ctx, container.ID); err != nil {
 		container.Status = models.StatusError
+		m.notifyWebhook(webhooks.EventContainerFailed, container, err.Error())
 		return nil, fmt.Errorf("container failed to start: %w", err)
 	}
 
 	// Get container IP for Traefik routing
 	containerIP, err := m.getContainerIP(ctx, container.ID)
 	if err != nil {
-		m.logger.Error("Failed to get container IP",
+		m.logger.ErrorContext(ctx, "Failed to get container IP",
 			slog.String("container", containerName),
 			slog.String("error", err.Error()))
 		// Continue without IP - container is still created
 		containerIP = "127.0.0.1" // fallback
+	} else if !m.config.Container.PublishPorts {
+		// Route to the port the server actually listens on if the requested one stays closed;
+		// only the requested port is published, so there is nothing to probe when publishing
+		container.Port = m.negotiatePort(ctx, container, containerIP)
 	}
+	upstreamHost, upstreamPort := m.upstreamAddress(ctx, container, containerIP)
 
-	// Add Traefik route for the container using the slug
-	if err := m.traefikManager.AddMCPService(ctx, slug, containerIP, req.Port); err != nil {
-		m.logger.Error("Failed to add Traefik route",
+	// Add Traefik route for the container using the slug, or once the health monitor finds it ready
+	if m.routesAwaitReadiness() {
+		m.logger.DebugContext(ctx, "Deferring Traefik route until the server is ready",
+			slog.String("slug", slug),
+			slog.String("service", req.ServiceName))
+	} else if err := m.traefikManager.AddMCPService(ctx, slug, upstreamHost, upstreamPort, container.Route, container.Routing, m.upstreamTLS(container)); err != nil {
+		m.logger.ErrorContext(ctx, "Failed to add Traefik route",
 			slog.String("slug", slug),
 			slog.String("service", req.ServiceName),
 			slog.String("error", err.Error()))
 		// Continue - container is created but routing may not work
 	}
 
-	container.Status = models.StatusRunning
+	m.journalStep(ctx, op, stepStatus, container)
+	if !m.beginStartupUnsafe(container) {
+		container.Status = models.StatusRunning
+	}
 	m.containers[req.ServiceName] = container
+	m.recordSlug(ctx, container)
+	m.notifyWebhook(webhooks.EventContainerCreated, container, "")
 
-	m.logger.Info("Container created successfully with slug",
+	m.logger.InfoContext(ctx, "Container created successfully with slug",
 		slog.String("container", containerName),
 		slog.String("id", container.ID),
 		slog.String("service", req.ServiceName),
@@ -194,6 +469,167 @@ func (m *Manager) CreateContainer(ctx context.Context, req models.CreateContaine
 	return container, nil
 }
 
+// prepareContainerUnsafe checks req against the host's state and policies and builds the container
+// it describes, without starting anything; an empty slug is generated. The container named
+// replacing may share req's service name and does not take a slot. The caller holds the mutex.
+func (m *Manager) prepareContainerUnsafe(req models.CreateContainerRequest, hash, slug, replacing string) (*models.Container, error) {
+	if m.IsPreempted() {
+		return nil, ErrHostPreempted
+	}
+	if m.IsCordoned() {
+		return nil, ErrCordoned
+	}
+
+	// Check if container already exists
+	_, exists := m.containers[req.ServiceName]
+	if exists && req.ServiceName != replacing {
+		return nil, fmt.Errorf("container %s already exists", req.ServiceName)
+	}
+
+	// Archived containers keep their slug reserved until they are restored or deleted
+	if m.IsArchived(req.ServiceName) {
+		return nil, fmt.Errorf("container %s is archived, unarchive it instead", req.ServiceName)
+	}
+
+	if err := validateRouteConfig(req.Route); err != nil {
+		return nil, err
+	}
+	route, err := withProxyToken(req.Route)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRoutingConfig(req.Routing); err != nil {
+		return nil, err
+	}
+	if hostname := routingHostname(req.Routing); m.hostnameInUseUnsafe(hostname, req.ServiceName) {
+		return nil, fmt.Errorf("hostname %s is already routed to another container", hostname)
+	}
+	if err := validateDevices(req.Devices); err != nil {
+		return nil, err
+	}
+	resources, err := m.resolveResources(requestedResources(&req))
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkSecurityPolicy(req.Security); err != nil {
+		return nil, err
+	}
+	if req.WorkspaceID != "" && !workspaceIDPattern.MatchString(req.WorkspaceID) {
+		return nil, fmt.Errorf("invalid workspace_id %q", req.WorkspaceID)
+	}
+	if err := m.checkScratchPolicy(req.Tmpfs, req.ScratchVolumes); err != nil {
+		return nil, err
+	}
+	if err := m.checkFilesPolicy(req.Files); err != nil {
+		return nil, err
+	}
+	if err := validateDependencies(req.DependsOn); err != nil {
+		return nil, err
+	}
+	if err := validateInitConfig(req.Init); err != nil {
+		return nil, err
+	}
+	if err := validateLogShippingConfig(req.LogShipping); err != nil {
+		return nil, err
+	}
+	if err := validateSchedule(req.Schedule); err != nil {
+		return nil, err
+	}
+	if err := validatePriority(req.Priority); err != nil {
+		return nil, err
+	}
+	if err := m.checkScaling(req.Scaling, req.Route); err != nil {
+		return nil, err
+	}
+	if req.Slug != "" {
+		if err := validateSlug(req.Slug); err != nil {
+			return nil, err
+		}
+	}
+	network, err := m.resolveConnectivity(connectivity{DNS: req.DNS, ExtraHosts: req.ExtraHosts, Proxy: req.Proxy})
+	if err != nil {
+		return nil, err
+	}
+	environment, err := ApplyEnvSchema(req.EnvSchema, req.Environment)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate container name using the sanitized service name
+	containerName := m.config.GetContainerName(req.ServiceName)
+
+	// Check container limit
+	if !exists && m.capacityUsedUnsafe() >= m.config.Container.MaxContainers {
+		return nil, fmt.Errorf("maximum container limit reached (%d)", m.config.Container.MaxContainers)
+	}
+
+	// Route a recreated instance under the slug it had, so its URL keeps working
+	slug, err = m.slugForUnsafe(slug, req.Slug, req.Environment["MCP_INSTANCE_ID"], req.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reserve GPUs before starting anything so concurrent creates cannot oversubscribe the host
+	gpuDevices, err := m.allocateGPUsUnsafe(req.ServiceName, req.GPUs, req.Devices)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create container directly from request
+	container := &models.Container{
+		Name:        containerName,
+		ServiceName: req.ServiceName,
+		Slug:        slug,
+		Image:       req.Image,
+		Status:      models.StatusStarting,
+		Port:        req.Port,
+		URL:         fmt.Sprintf("%s/mcp/%s", m.config.Traefik.ProxyHost, slug),
+		Host:        m.config.Traefik.ProxyHost,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		Labels:      req.Labels,
+		Environment: environment,
+		Command:     req.Command,
+		HealthCheck: req.HealthCheck,
+		Route:       route,
+		Routing:     req.Routing,
+		UpstreamTLS: m.config.MTLS.Upstream,
+		GPUs:        req.GPUs,
+		Devices:     req.Devices,
+		GPUDevices:  gpuDevices,
+		Resources:   resources,
+		Security:    req.Security,
+
+		WorkspaceID:   req.WorkspaceID,
+		Network:       m.workspaceNetworkName(req.WorkspaceID),
+		SharedNetwork: req.SharedNetwork,
+		DNS:           network.DNS,
+		ExtraHosts:    network.ExtraHosts,
+		Proxy:         network.Proxy,
+
+		Tmpfs:          req.Tmpfs,
+		ScratchVolumes: req.ScratchVolumes,
+		Files:          req.Files,
+		DependsOn:      req.DependsOn,
+		Init:           req.Init,
+		Source:         req.Source,
+		Runtime:        req.Runtime,
+		LogShipping:    req.LogShipping,
+		Schedule:       req.Schedule,
+		Priority:       req.Priority,
+		Scaling:        req.Scaling,
+		SpecHash:       hash,
+		EnvSchema:      req.EnvSchema,
+	}
+	container.Pod = m.workspacePodUnsafe(container)
+	return container, nil
+}
+
+// Config returns the configuration the manager runs with
+func (m *Manager) Config() *config.Config {
+	return m.config
+}
+
 // GetContainer gets a container by service name
 func (m *Manager) GetContainer(serviceName string) (*models.Container, error) {
 	m.mutex.RLock()
@@ -231,100 +667,88 @@ func (m *Manager) GetContainerStatus(ctx context.Context, serviceName string) (m
 	}
 
 	// Get real-time status from podman
-	cmd := exec.CommandContext(ctx, "podman", "inspect", container.ID, "--format", "{{.State.Status}}")
-	output, err := cmd.CombinedOutput()
+	entry, err := m.inspect.get(ctx, container.ID)
 	if err != nil {
 		return models.StatusError, fmt.Errorf("failed to get container status: %w", err)
 	}
 
-	podmanStatus := strings.TrimSpace(string(output))
-	status := m.mapPodmanStatus(podmanStatus)
+	status := m.mapPodmanStatus(entry.state)
 
 	// Update cached status
 	m.mutex.RUnlock()
 	m.mutex.Lock()
 	container.Status = status
+	entry.applyExit(container)
 	m.mutex.Unlock()
 	m.mutex.RLock()
 
 	return status, nil
 }
 
-// PerformHealthCheck performs an HTTP health check on a container
+// PerformHealthCheck performs an HTTP health check on a container, answering from the last background
+// check when it is no older than the configured max staleness
 func (m *Manager) PerformHealthCheck(ctx context.Context, serviceName string) (map[string]interface{}, error) {
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
-
-	container, exists := m.containers[serviceName]
-	if !exists {
-		return nil, fmt.Errorf("container %s not found", serviceName)
-	}
+	return m.CheckHealth(ctx, serviceName, false)
+}
 
-	// Use health checker to perform comprehensive health check
-	healthResult, err := m.healthChecker.PerformHealthCheck(ctx, container)
+// DeleteContainer stops and removes a container
+func (m *Manager) DeleteContainer(ctx context.Context, serviceName string) error {
+	ctx, op, err := m.beginOperation(ctx, operationDelete, serviceName, "")
 	if err != nil {
-		return nil, fmt.Errorf("health check failed: %w", err)
-	}
-
-	// Convert health result to map for JSON response
-	result := map[string]interface{}{
-		"service_name":     serviceName,
-		"container_id":     healthResult.ContainerID,
-		"container_status": string(healthResult.Status),
-		"healthy":          healthResult.Healthy,
-		"http_reachable":   healthResult.HTTPReachable,
-		"response_time_ms": healthResult.ResponseTime.Milliseconds(),
-		"timestamp":        healthResult.Timestamp,
-		"url":              container.URL,
-		"slug":             container.Slug,
-	}
-
-	if healthResult.Error != "" {
-		result["error"] = healthResult.Error
+		return err
 	}
-
-	if healthResult.Details != nil {
-		result["details"] = healthResult.Details
+	if container, exists := m.containerSnapshot(serviceName); exists {
+		m.journalStep(ctx, op, stepRemove, &container)
 	}
-
-	return result, nil
+	err = m.deleteContainer(ctx, serviceName)
+	m.finishOperation(ctx, op, err)
+	return err
 }
 
-// DeleteContainer stops and removes a container
-func (m *Manager) DeleteContainer(ctx context.Context, serviceName string) error {
+// deleteContainer runs the delete of DeleteContainer
+func (m *Manager) deleteContainer(ctx context.Context, serviceName string) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
 	container, exists := m.containers[serviceName]
 	if !exists {
+		if m.IsArchived(serviceName) {
+			return m.deleteArchived(ctx, serviceName)
+		}
 		return fmt.Errorf("container %s not found", serviceName)
 	}
 
 	container.Status = models.StatusStopping
 
 	// Stop container
-	stopCmd := exec.CommandContext(ctx, "podman", "stop", container.ID)
-	if output, err := stopCmd.CombinedOutput(); err != nil {
-		m.logger.Error("Failed to stop container",
+	if output, err := m.stopProcess(ctx, container); err != nil {
+		m.logger.ErrorContext(ctx, "Failed to stop container",
 			slog.String("container", container.Name),
 			slog.String("error", err.Error()),
 			slog.String("output", string(output)))
 	}
 
 	// Remove container
-	rmCmd := exec.CommandContext(ctx, "podman", "rm", container.ID)
-	if output, err := rmCmd.CombinedOutput(); err != nil {
-		m.logger.Error("Failed to remove container",
+	rmCmd := podmanCommand(ctx, m.logger, "rm", container.ID)
+	output, err := rmCmd.CombinedOutput()
+	m.inspect.invalidate(container.ID)
+	if err != nil {
+		m.logger.ErrorContext(ctx, "Failed to remove container",
 			slog.String("container", container.Name),
 			slog.String("error", err.Error()),
 			slog.String("output", string(output)))
 		return fmt.Errorf("failed to remove container: %w", err)
 	}
+	m.removeUnit(ctx, container.Name)
+
+	m.removeDependencies(ctx, container)
+	m.removeScratchVolumes(ctx, container)
+	m.removeUpstreamTLS(ctx, container)
 
 	// Remove Traefik route for the container using the slug
 	if container.Slug != "" {
 		if err := m.traefikManager.RemoveMCPService(ctx, container.Slug); err != nil {
-			m.logger.Error("Failed to remove Traefik route",
+			m.logger.ErrorContext(ctx, "Failed to remove Traefik route",
 				slog.String("slug", container.Slug),
 				slog.String("service", serviceName),
 				slog.String("error", err.Error()))
@@ -333,8 +757,21 @@ func (m *Manager) DeleteContainer(ctx context.Context, serviceName string) error
 	}
 
 	delete(m.containers, serviceName)
-
-	m.logger.Info("Container deleted successfully",
+	delete(m.healthCounters, container.Name)
+	delete(m.healthHistory, container.Name)
+	delete(m.crashLoops.exits, serviceName)
+	delete(m.startups.probes, serviceName)
+	m.forgetSLO(serviceName)
+	m.forgetStopped(ctx, serviceName)
+	m.forgetAdoption(ctx, container.Name)
+	m.forgetDeployment(ctx, serviceName)
+	m.forgetSchedule(serviceName)
+	m.forgetCheckpoint(ctx, serviceName)
+	m.releasePodUnsafe(ctx, container.Pod)
+	m.releaseNetworkUnsafe(ctx, container.Network)
+	m.notifyWebhook(webhooks.EventContainerDeleted, container, "")
+
+	m.logger.InfoContext(ctx, "Container deleted successfully",
 		slog.String("container", container.Name),
 		slog.String("service", serviceName))
 
@@ -371,9 +808,8 @@ func (m *Manager) getRunningCountUnsafe() int {
 
 // discoverContainers discovers existing containers managed by this service
 func (m *Manager) discoverContainers(ctx context.Context) error {
-	// List all containers with our prefix
-	cmd := exec.CommandContext(ctx, "podman", "ps", "-a", "--format", "json")
-	output, err := cmd.CombinedOutput()
+	// List all containers; which of them are ours is decided from their labels
+	output, err := listContainers(ctx, m.logger, false)
 	if err != nil {
 		return fmt.Errorf("failed to list containers: %w", err)
 	}
@@ -390,12 +826,13 @@ func (m *Manager) discoverContainers(ctx context.Context) error {
 	// Load Traefik configuration to find existing slugs
 	traefikConfig, err := m.traefikManager.LoadConfig()
 	if err != nil {
-		m.logger.Warn("Failed to load Traefik config for slug discovery",
+		m.logger.WarnContext(ctx, "Failed to load Traefik config for slug discovery",
 			slog.String("error", err.Error()))
 		traefikConfig = nil
 	}
 
 	prefix := m.config.Container.NamePrefix
+	var running []*models.Container
 	for _, pc := range podmanContainers {
 		names, ok := pc["Names"].([]interface{})
 		if !ok || len(names) == 0 {
@@ -403,69 +840,98 @@ func (m *Manager) discoverContainers(ctx context.Context) error {
 		}
 
 		containerName, ok := names[0].(string)
-		if !ok || !strings.HasPrefix(containerName, prefix) {
+		if !ok {
 			continue
 		}
 
-		// Extract service name from container environment (original name)
-		// First try to get original service name from environment variable
-		originalServiceName := ""
-		if inspectCmd := exec.CommandContext(ctx, "podman", "inspect", pc["Id"].(string), "--format", "{{.Config.Env}}"); inspectCmd != nil {
-			if inspectOutput, err := inspectCmd.CombinedOutput(); err == nil {
-				envStr := string(inspectOutput)
-				if strings.Contains(envStr, "MCP_SERVICE_NAME=") {
-					// Extract service name from environment variables
-					if idx := strings.Index(envStr, "MCP_SERVICE_NAME="); idx != -1 {
-						serviceNameStr := envStr[idx+len("MCP_SERVICE_NAME="):]
-						if spaceIdx := strings.Index(serviceNameStr, " "); spaceIdx != -1 {
-							serviceNameStr = serviceNameStr[:spaceIdx]
-						}
-						// Remove any quotes that might be present
-						serviceNameStr = strings.Trim(serviceNameStr, "\"'")
-						if serviceNameStr != "" {
-							originalServiceName = serviceNameStr
-						}
-					}
+		// Only containers carrying this manager's label, or unlabelled ones from before it existed
+		switch m.containerOwnership(containerName, listingLabels(pc)) {
+		case ownedByOther:
+			continue
+		case ownedLegacy:
+			m.logger.InfoContext(ctx, "Discovered container without ownership labels, recognized by name prefix",
+				slog.String("name", containerName))
+		}
+		adopted, isAdopted := m.adoption(containerName)
+
+		// Sidecars and init runs are managed through the container that owns them, and the
+		// registry mirror is not an instance
+		if isOwnedListing(pc) || listingLabels(pc)[mirrorLabel] != "" {
+			continue
+		}
+
+		containerID := pc["Id"].(string)
+
+		// Metadata labels written at create time, or the environment of older containers
+		metadata, err := m.inspectDiscoveryMetadata(ctx, containerID)
+		if err != nil {
+			m.logger.WarnContext(ctx, "Failed to inspect container during discovery",
+				slog.String("name", containerName),
+				slog.String("error", err.Error()))
+			metadata = &discoveredMetadata{}
+		}
+		// Promotions and rollbacks move containers to another service name and slug
+		deployedService, deployedSlug, deployedInstance, isDeployed := m.deploymentRole(containerName)
+		if isDeployed {
+			metadata.ServiceName = deployedService
+			metadata.Slug = deployedSlug
+			if metadata.Environment == nil {
+				metadata.Environment = make(map[string]string)
+			}
+			delete(metadata.Environment, "MCP_INSTANCE_ID")
+			if deployedInstance != "" {
+				metadata.Environment["MCP_INSTANCE_ID"] = deployedInstance
+			}
+		}
+		if isAdopted {
+			metadata.ServiceName = adopted.ServiceName
+			metadata.Slug = adopted.Slug
+			metadata.Port = adopted.Port
+			if adopted.InstanceID != "" {
+				if metadata.Environment == nil {
+					metadata.Environment = make(map[string]string)
 				}
+				metadata.Environment["MCP_INSTANCE_ID"] = adopted.InstanceID
 			}
 		}
 
 		// Fallback to sanitized name if we can't find the original
-		serviceName := originalServiceName
+		serviceName := metadata.ServiceName
 		if serviceName == "" {
 			serviceName = strings.TrimPrefix(containerName, prefix)
 		}
 
-		containerID := pc["Id"].(string)
+		// Archived containers stay in cold storage until explicitly restored
+		if m.IsArchived(serviceName) {
+			m.logger.DebugContext(ctx, "Skipping archived container during discovery",
+				slog.String("service", serviceName))
+			continue
+		}
 
-		// Get container port from inspect
-		port := 8000 // Default port
-		if inspectCmd := exec.CommandContext(ctx, "podman", "inspect", containerID, "--format", "{{.Config.Env}}"); inspectCmd != nil {
-			if inspectOutput, err := inspectCmd.CombinedOutput(); err == nil {
-				envStr := string(inspectOutput)
-				if strings.Contains(envStr, "MCP_CONTAINER_PORT=") {
-					// Extract port from environment variables
-					if idx := strings.Index(envStr, "MCP_CONTAINER_PORT="); idx != -1 {
-						portStr := envStr[idx+len("MCP_CONTAINER_PORT="):]
-						if spaceIdx := strings.Index(portStr, " "); spaceIdx != -1 {
-							portStr = portStr[:spaceIdx]
-						}
-						if portStr != "" {
-							if p, err := strconv.Atoi(portStr); err == nil {
-								port = p
-							}
-						}
-					}
-				}
-			}
+		port := metadata.Port
+		if port == 0 {
+			port = 8000 // Default port
+		}
+		createdAt := metadata.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = time.Now()
 		}
 
-		// Try to find existing slug from Traefik configuration
-		slug := m.findExistingSlugFromTraefik(serviceName, traefikConfig)
-		if slug == "" {
+		// Prefer the slug assigned to the instance, which follows renames, then the one recorded on
+		// the container, then the one in the Traefik configuration
+		slug := metadata.Slug
+		if !isDeployed && !isAdopted {
+			if assigned, found := m.assignedSlug(metadata.Environment["MCP_INSTANCE_ID"], serviceName); found {
+				slug = assigned
+			}
+		}
+		if slug == "" && !isDeployed {
+			slug = m.findExistingSlugFromTraefik(serviceName, traefikConfig)
+		}
+		if slug == "" && !isDeployed {
 			// Fallback to generating a new slug if not found in Traefik
 			slug = generateSlug(serviceName)
-			m.logger.Warn("Could not find existing slug in Traefik config, generating new one",
+			m.logger.WarnContext(ctx, "Could not find existing slug in Traefik config, generating new one",
 				slog.String("service", serviceName),
 				slog.String("slug", slug))
 		}
@@ -480,15 +946,82 @@ func (m *Manager) discoverContainers(ctx context.Context) error {
 			Port:        port,
 			URL:         fmt.Sprintf("%s/mcp/%s", m.config.Traefik.ProxyHost, slug),
 			Host:        m.config.Traefik.ProxyHost,
-			CreatedAt:   time.Now(), // We don't have exact creation time
+			CreatedAt:   createdAt,
 			UpdatedAt:   time.Now(),
+			Environment: metadata.Environment,
+			Labels:      metadata.Labels,
+			Command:     metadata.Command,
+			SpecHash:    metadata.SpecHash,
+			HealthCheck: m.discoverHealthCheck(ctx, containerID),
+			Route:       m.discoverRoute(ctx, containerID),
+			Routing:     m.discoverRouting(ctx, containerID),
+			Resources:   m.discoverResources(ctx, containerID),
+			Security:    m.discoverSecurity(ctx, containerID),
+			WorkspaceID: m.containerLabel(ctx, containerID, workspaceLabel),
+			StoppedAt:   m.stoppedByUser(serviceName),
+			DependsOn:   m.discoverDependencies(ctx, containerID),
+			Init:        m.discoverInit(ctx, containerID),
+			Source:      m.discoverSource(ctx, containerID),
+			Runtime:     m.discoverRuntime(ctx, containerID),
+			LogShipping: m.discoverLogShipping(ctx, containerID),
+			Schedule:    m.discoverSchedule(ctx, containerID),
+			Priority:    m.discoverPriority(ctx, containerID),
+			Scaling:     m.discoverScaling(ctx, containerID),
+			EnvSchema:   m.discoverEnvSchema(ctx, containerID),
+			Provenance:  m.discoverProvenance(ctx, containerID),
+			UpstreamTLS: m.containerLabel(ctx, containerID, upstreamTLSLabel) == "true",
+		}
+		container.Network = m.workspaceNetworkName(container.WorkspaceID)
+		container.Pod = m.containerLabel(ctx, containerID, podLabel)
+		container.Platform, container.Emulated = m.discoverPlatform(ctx, containerID)
+		network := m.discoverConnectivity(ctx, containerID)
+		container.DNS, container.ExtraHosts, container.Proxy = network.DNS, network.ExtraHosts, network.Proxy
+		if container.StoppedAt != nil && m.store.Has(scheduledOffBucket, serviceName) {
+			container.Status = models.StatusScheduledOff
+		}
+		if container.StoppedAt != nil && m.store.Has(preemptedBucket, serviceName) {
+			container.Status = models.StatusPreempted
+		}
+		if container.StoppedAt != nil && m.store.Has(checkpointedBucket, serviceName) {
+			container.Status = models.StatusCheckpointed
+		}
+		if container.StoppedAt != nil && m.store.Has(overBudgetBucket, serviceName) {
+			container.Status = models.StatusOverBudget
+		}
+		if container.StoppedAt != nil && m.store.Has(crashLoopBucket, serviceName) {
+			container.Status = models.StatusCrashLoop
+		}
+		if isAdopted {
+			container.HealthCheck = adopted.HealthCheck
+			container.Route = adopted.Route
+		}
+		if isDeployed && slug == "" {
+			// A container kept for rollback has no route
+			container.URL = ""
 		}
 
+		scratch := m.discoverScratch(ctx, containerID)
+		container.Tmpfs = scratch.Tmpfs
+		container.ScratchVolumes = scratch.ScratchVolumes
+		container.Files = m.discoverFiles(ctx, containerID, containerName)
+
+		// Restore device assignments so discovered GPU containers keep holding their GPUs
+		devices := m.discoverDevices(ctx, containerID)
+		container.GPUs = devices.GPUs
+		container.Devices = devices.Devices
+		container.GPUDevices = devices.GPUDevices
+
 		// Store container using the original service name for lookup
 		// This ensures health checks can find containers by their original name
 		m.containers[serviceName] = container
+		if container.Status == models.StatusRunning && container.StoppedAt == nil {
+			// It served before the manager restarted, so its route is restored below and only
+			// removed once readiness checks fail past the unhealthy threshold
+			container.Ready = true
+			running = append(running, container)
+		}
 
-		m.logger.Info("Discovered existing container with slug",
+		m.logger.InfoContext(ctx, "Discovered existing container with slug",
 			slog.String("name", containerName),
 			slog.String("service", serviceName),
 			slog.String("slug", slug),
@@ -496,6 +1029,29 @@ func (m *Manager) discoverContainers(ctx context.Context) error {
 			slog.String("status", string(container.Status)))
 	}
 
+	// Containers created before slugs were recorded keep theirs when recreated from now on
+	m.recordDiscoveredSlugs(ctx)
+
+	// Under systemd supervision, units are brought in line with the containers found
+	if m.supervised() {
+		running = append(running, m.reconcileUnits(ctx)...)
+	}
+
+	// Routes lost with the dynamic configuration, or pointing at an old IP, are registered again
+	for _, container := range running {
+		if result, err := m.refreshRoute(ctx, container); err != nil {
+			m.logger.WarnContext(ctx, "Failed to restore route for discovered container",
+				slog.String("service", container.ServiceName),
+				slog.String("slug", container.Slug),
+				slog.String("error", err.Error()))
+		} else if result.Changed {
+			m.logger.InfoContext(ctx, "Restored route for discovered container",
+				slog.String("service", container.ServiceName),
+				slog.String("slug", container.Slug),
+				slog.String("upstream", result.Upstream))
+		}
+	}
+
 	return nil
 }
 
@@ -531,11 +1087,20 @@ func (m *Manager) buildPodmanRunArgs(container *models.Container) []string {
 	// Add name
 	args = append(args, "--name", container.Name)
 
-	// Add network (important for Traefik discovery)
-	args = append(args, "--network", m.config.Traefik.Network)
+	// Add network (important for Traefik discovery); workspaces may each have their own, or share a pod
+	if container.Pod != "" {
+		args = append(args, m.podmanPodArgs(container)...)
+	} else {
+		args = append(args, m.podmanNetworkArgs(container)...)
+	}
+	if container.WorkspaceID != "" {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", workspaceLabel, container.WorkspaceID))
+	}
 
 	// No port mapping needed - Traefik will handle routing via path-based routing
-	// The container will expose its internal port and Traefik will proxy to it
+	// The container will expose its internal port and Traefik will proxy to it, unless the
+	// engine runs in a VM and ports are published on loopback instead
+	args = append(args, m.publishArgs(container)...)
 
 	// Add environment variables
 	for key, value := range container.Environment {
@@ -547,13 +1112,149 @@ func (m *Manager) buildPodmanRunArgs(container *models.Container) []string {
 		args = append(args, "--label", fmt.Sprintf("%s=%s", key, value))
 	}
 
-	// Add default resource limits
-	if m.config.Container.DefaultMemoryLimit != "" {
-		args = append(args, "--memory", m.config.Container.DefaultMemoryLimit)
+	// Record ownership and the metadata discovery restores the container from after a manager restart
+	args = append(args, m.ownershipLabelArgs(container)...)
+	args = append(args, metadataLabelArgs(container)...)
+
+	// Persist the health check configuration so it survives manager restarts
+	if container.HealthCheck != nil {
+		if data, err := json.Marshal(container.HealthCheck); err == nil {
+			args = append(args, "--label", fmt.Sprintf("%s=%s", healthCheckLabel, data))
+		}
+	}
+
+	// Persist the route middleware and routing configuration so routes are rebuilt identically after restarts
+	if container.Route != nil {
+		if data, err := json.Marshal(container.Route); err == nil {
+			args = append(args, "--label", fmt.Sprintf("%s=%s", routeLabel, data))
+		}
+	}
+	if container.Routing != nil {
+		if data, err := json.Marshal(container.Routing); err == nil {
+			args = append(args, "--label", fmt.Sprintf("%s=%s", routingLabel, data))
+		}
+	}
+
+	// Pass through assigned GPUs and requested devices, persisting them for capacity tracking after restarts
+	for _, device := range claimedDevices(container) {
+		args = append(args, "--device", device)
+	}
+	if len(container.Devices) > 0 || len(container.GPUDevices) > 0 {
+		assignment := deviceAssignment{GPUs: container.GPUs, Devices: container.Devices, GPUDevices: container.GPUDevices}
+		if data, err := json.Marshal(assignment); err == nil {
+			args = append(args, "--label", fmt.Sprintf("%s=%s", devicesLabel, data))
+		}
+	}
+
+	// Provision writable tmpfs and scratch mounts for read-only roots
+	args = append(args, m.podmanScratchArgs(container)...)
+
+	// Mount the certificate the container serves the proxy with
+	args = append(args, m.upstreamTLSArgs(container)...)
+
+	// Pin emulated containers to the variant chosen for them
+	args = append(args, platformArgs(container)...)
+
+	// Point the container at its resolvers, hosts entries and proxy, persisting them for restarts.
+	// Pod members use the pod's resolvers and hosts entries, which match their own.
+	if container.Pod != "" {
+		member := *container
+		member.DNS, member.ExtraHosts = nil, nil
+		args = append(args, connectivityArgs(&member, container.Environment)...)
+	} else {
+		args = append(args, connectivityArgs(container, container.Environment)...)
+	}
+	if len(container.DNS) > 0 || len(container.ExtraHosts) > 0 || container.Proxy != nil {
+		network := connectivity{DNS: container.DNS, ExtraHosts: container.ExtraHosts, Proxy: container.Proxy}
+		if data, err := json.Marshal(network); err == nil {
+			args = append(args, "--label", fmt.Sprintf("%s=%s", connectivityLabel, data))
+		}
+	}
+
+	// Harden the container, persisting any overrides so restarts apply the same policy
+	args = append(args, m.podmanSecurityArgs(container)...)
+	if container.Security != nil {
+		if data, err := json.Marshal(container.Security); err == nil {
+			args = append(args, "--label", fmt.Sprintf("%s=%s", securityLabel, data))
+		}
+	}
+
+	// Persist dependencies so sidecars are started, stopped and removed with the container after restarts
+	if len(container.DependsOn) > 0 {
+		if data, err := json.Marshal(container.DependsOn); err == nil {
+			args = append(args, "--label", fmt.Sprintf("%s=%s", dependsOnLabel, data))
+		}
+	}
+
+	// Persist the init command so it is reported after restarts; it only runs before the first start
+	if container.Init != nil {
+		if data, err := json.Marshal(container.Init); err == nil {
+			args = append(args, "--label", fmt.Sprintf("%s=%s", initLabel, data))
+		}
+	}
+
+	// Persist the source so a built image can be traced back to its repository
+	if container.Source != nil {
+		if data, err := json.Marshal(container.Source); err == nil {
+			args = append(args, "--label", fmt.Sprintf("%s=%s", sourceLabel, data))
+		}
+	}
+
+	// Persist the package runtime so the container is reported with the package it runs
+	if container.Runtime != nil {
+		if data, err := json.Marshal(container.Runtime); err == nil {
+			args = append(args, "--label", fmt.Sprintf("%s=%s", runtimeLabel, data))
+		}
+	}
+
+	// Persist the log shipping override so logs keep going to the same sink after restarts
+	if container.LogShipping != nil {
+		if data, err := json.Marshal(container.LogShipping); err == nil {
+			args = append(args, "--label", fmt.Sprintf("%s=%s", logShippingLabel, data))
+		}
+	}
+
+	// Persist the schedule so the scheduler keeps applying it after restarts
+	if container.Schedule != nil {
+		if data, err := json.Marshal(container.Schedule); err == nil {
+			args = append(args, "--label", fmt.Sprintf("%s=%s", scheduleLabel, data))
+		}
+	}
+
+	// Persist the priority so preemption keeps honoring it after restarts
+	if container.Priority != nil {
+		if data, err := json.Marshal(container.Priority); err == nil {
+			args = append(args, "--label", fmt.Sprintf("%s=%s", priorityLabel, data))
+		}
+	}
+
+	// Persist the scaling so the autoscaler keeps applying it after restarts
+	if container.Scaling != nil {
+		if data, err := json.Marshal(container.Scaling); err == nil {
+			args = append(args, "--label", fmt.Sprintf("%s=%s", scalingLabel, data))
+		}
 	}
 
-	if m.config.Container.DefaultCPULimit != "" {
-		args = append(args, "--cpus", m.config.Container.DefaultCPULimit)
+	// Persist the environment schema so it can be served after restarts
+	if len(container.EnvSchema) > 0 {
+		if data, err := json.Marshal(container.EnvSchema); err == nil {
+			args = append(args, "--label", fmt.Sprintf("%s=%s", envSchemaLabel, data))
+		}
+	}
+
+	// Persist where the spec's fields came from, for the spec endpoint after restarts
+	if len(container.Provenance) > 0 {
+		if data, err := json.Marshal(container.Provenance); err == nil {
+			args = append(args, "--label", fmt.Sprintf("%s=%s", provenanceLabel, data))
+		}
+	}
+
+	// Add resource limits, persisting them so they are reported after restarts
+	if container.Resources != nil {
+		args = append(args, podmanResourceArgs(container.Resources)...)
+		if data, err := json.Marshal(container.Resources); err == nil {
+			args = append(args, "--label", fmt.Sprintf("%s=%s", resourcesLabel, data))
+		}
 	}
 
 	// Add image
@@ -580,7 +1281,7 @@ func (m *Manager) waitForContainer(ctx context.Context, containerID string) erro
 		case <-timeout:
 			return fmt.Errorf("timeout waiting for container to start")
 		case <-ticker.C:
-			cmd := exec.CommandContext(ctx, "podman", "inspect", containerID, "--format", "{{.State.Status}}")
+			cmd := podmanCommand(ctx, m.logger, "inspect", containerID, "--format", "{{.State.Status}}")
 			output, err := cmd.CombinedOutput()
 			if err != nil {
 				continue
@@ -641,8 +1342,7 @@ func mergeEnvironment(template, request map[string]string) map[string]string {
 // getContainerIP retrieves the IP address of a container in the mcp-network
 func (m *Manager) getContainerIP(ctx context.Context, containerID string) (string, error) {
 	// Use a simpler approach to get container IP
-	cmd := exec.CommandContext(ctx, "podman", "inspect", containerID)
-	output, err := cmd.CombinedOutput()
+	output, err := runPodman(ctx, m.logger, "inspect", containerID)
 	if err != nil {
 		return "", fmt.Errorf("failed to inspect container: %w", err)
 	}
@@ -668,14 +1368,23 @@ func (m *Manager) getContainerIP(ctx context.Context, containerID string) (strin
 		return "", fmt.Errorf("Networks not found")
 	}
 
-	mcpNetwork, ok := networks[m.config.Traefik.Network].(map[string]interface{})
-	if !ok {
-		return "", fmt.Errorf("network %s not found", m.config.Traefik.Network)
+	// Prefer the shared proxy network, falling back to the container's workspace network
+	var ipAddress string
+	for name, settings := range networks {
+		network, ok := settings.(map[string]interface{})
+		if !ok || !m.routableNetwork(name) {
+			continue
+		}
+		if ip, ok := network["IPAddress"].(string); ok && ip != "" {
+			ipAddress = ip
+			if name == m.config.Traefik.Network {
+				break
+			}
+		}
 	}
 
-	ipAddress, ok := mcpNetwork["IPAddress"].(string)
-	if !ok || ipAddress == "" {
-		return "", fmt.Errorf("IPAddress not found or empty")
+	if ipAddress == "" {
+		return "", fmt.Errorf("no IP address on network %s or a workspace network", m.config.Traefik.Network)
 	}
 
 	return ipAddress, nil
@@ -683,9 +1392,133 @@ func (m *Manager) getContainerIP(ctx context.Context, containerID string) (strin
 
 // HandleMCPInstanceCreated handles the creation of an MCP server instance from domain events
 func (m *Manager) HandleMCPInstanceCreated(ctx context.Context, instanceID, name string, jsonSpec map[string]interface{}) error {
+	ctx, op, err := m.beginOperation(ctx, operationCreate, name, instanceID)
+	if err != nil {
+		return err
+	}
+	err = m.handleMCPInstanceCreated(ctx, op, instanceID, name, jsonSpec)
+	m.finishOperation(ctx, op, err)
+	return err
+}
+
+// handleMCPInstanceCreated runs the create of HandleMCPInstanceCreated as operation op
+func (m *Manager) handleMCPInstanceCreated(ctx context.Context, op *operation, instanceID, name string, jsonSpec map[string]interface{}) error {
+	if m.IsPreempted() {
+		return fmt.Errorf("%w, instance %s must be scheduled elsewhere", ErrHostPreempted, instanceID)
+	}
+
+	// While cordoned, creates are held back and replayed on uncordon
+	if m.IsCordoned() {
+		return m.queueCreateEvent(ctx, instanceID, name, jsonSpec)
+	}
+
+	// Starting an archived instance restores it under its reserved slug
+	if m.IsArchived(name) {
+		if _, err := m.UnarchiveContainer(ctx, name); err != nil {
+			return fmt.Errorf("failed to restore archived instance: %w", err)
+		}
+		return nil
+	}
+
+	// Redelivered events are harmless: an unchanged spec keeps the container as it is, a changed
+	// one replaces it once the new spec passed validation and its image was pulled
+	hash := specHash(map[string]interface{}{"name": name, "json_spec": jsonSpec})
+	sentSpec := requestedFromJSONSpec(jsonSpec)
+	existing, state := m.compareExistingSpec(instanceID, name, hash)
+	switch state {
+	case specUnchanged:
+		m.logger.InfoContext(ctx, "Instance already running with the same spec",
+			slog.String("instance_id", instanceID),
+			slog.String("service_name", existing.ServiceName))
+		if existing.Status == models.StatusRunning {
+			if err := m.eventPublisher.PublishRunning(ctx, instanceID, existing.ServiceName, existing.ID, existing.URL); err != nil {
+				m.logger.WarnContext(ctx, "Failed to publish running status",
+					slog.String("instance_id", instanceID),
+					slog.String("error", err.Error()))
+			}
+		}
+		return nil
+	case specChanged:
+		if err := checkReplaceable(existing, name); err != nil {
+			return err
+		}
+		m.logger.InfoContext(ctx, "Updating instance whose spec changed",
+			slog.String("instance_id", instanceID),
+			slog.String("service_name", existing.ServiceName))
+	}
+	replacing := state == specChanged
+
+	// Refuse before pulling or building rather than failing midway when storage is nearly full,
+	// or when the workspace or the host used up its budget
+	refusal := m.checkStoragePressure(ctx)
+	if refusal == nil {
+		// An invalid workspace is reported with the other network settings below
+		if workspaceID, _, err := parseNetworkSpec(jsonSpec); err == nil {
+			refusal = m.checkBudget(workspaceID)
+		}
+	}
+	if refusal != nil {
+		if publishErr := m.eventPublisher.PublishFailed(ctx, instanceID, name, refusal.Error()); publishErr != nil {
+			m.logger.WarnContext(ctx, "Failed to publish failed status",
+				slog.String("instance_id", instanceID),
+				slog.String("error", publishErr.Error()))
+		}
+		m.webhooks.Notify(webhooks.Event{
+			Type:        webhooks.EventContainerFailed,
+			InstanceID:  instanceID,
+			ServiceName: name,
+			Status:      string(models.StatusError),
+			Error:       refusal.Error(),
+		})
+		return refusal
+	}
+
+	// Translate npx/uvx runtime shortcuts into the bridge image before anything reads json_spec
+	jsonSpec, runtime, err := m.applyRuntimeSpec(jsonSpec)
+	if err != nil {
+		return fmt.Errorf("invalid runtime in json_spec: %w", err)
+	}
+
+	// Build the image first when json_spec points at source, so validation sees a local image
+	source, err := parseSourceSpec(jsonSpec)
+	if err != nil {
+		return fmt.Errorf("invalid source in json_spec: %w", err)
+	}
+	if source != nil {
+		if image, _ := jsonSpec["image"].(string); image != "" {
+			return fmt.Errorf("json_spec cannot set both image and source")
+		}
+		if err := m.eventPublisher.PublishBuilding(ctx, instanceID, name); err != nil {
+			m.logger.WarnContext(ctx, "Failed to publish building status",
+				slog.String("instance_id", instanceID),
+				slog.String("error", err.Error()))
+		}
+
+		image, err := m.buildSourceImage(ctx, name, source)
+		if err != nil {
+			if publishErr := m.eventPublisher.PublishFailed(ctx, instanceID, name, err.Error()); publishErr != nil {
+				m.logger.WarnContext(ctx, "Failed to publish failed status",
+					slog.String("instance_id", instanceID),
+					slog.String("error", publishErr.Error()))
+			}
+			m.webhooks.Notify(webhooks.Event{
+				Type:        webhooks.EventContainerFailed,
+				InstanceID:  instanceID,
+				ServiceName: name,
+				Status:      string(models.StatusError),
+				Error:       err.Error(),
+			})
+			return err
+		}
+
+		// Copy so the built image is not written into the caller's spec
+		jsonSpec = maps.Clone(jsonSpec)
+		jsonSpec["image"] = image
+	}
+
 	// Publish validating status
 	if err := m.eventPublisher.PublishValidating(ctx, instanceID, name); err != nil {
-		m.logger.Warn("Failed to publish validating status",
+		m.logger.WarnContext(ctx, "Failed to publish validating status",
 			slog.String("instance_id", instanceID),
 			slog.String("error", err.Error()))
 	}
@@ -698,79 +1531,230 @@ func (m *Manager) HandleMCPInstanceCreated(ctx context.Context, instanceID, name
 		Status:     "validating",
 	}
 
-	// Get current running count before validation (while unlocked)
+	priority, err := parsePrioritySpec(jsonSpec)
+	if err != nil {
+		return fmt.Errorf("invalid priority in json_spec: %w", err)
+	}
+
+	// Get current running count before validation (while unlocked); a replacement takes over the
+	// running container's place, and lower-priority instances that can be preempted once the
+	// instance is known to be valid count as free
 	currentRunningCount := m.GetRunningCount()
+	if replacing && existing.Status == models.StatusRunning {
+		currentRunningCount--
+	} else if !replacing {
+		currentRunningCount = max(currentRunningCount-m.preemptibleCount(name, priority), 0)
+	}
 	maxContainers := m.config.Container.MaxContainers
 
 	// Perform comprehensive validation with image pulling (OUTSIDE MUTEX)
+	m.journalStep(ctx, op, stepPull, nil)
 	validationResult, err := m.ValidateContainerSpecWithLimits(ctx, instance, true, currentRunningCount, maxContainers)
 	if err != nil {
-		m.logger.Error("Container validation failed",
+		m.logger.ErrorContext(ctx, "Container validation failed",
 			slog.String("instance_id", instanceID),
 			slog.String("error", err.Error()))
 		return fmt.Errorf("container validation failed: %w", err)
 	}
 
 	if !validationResult.Valid {
-		m.logger.Error("Container validation failed with errors",
+		m.logger.ErrorContext(ctx, "Container validation failed with errors",
 			slog.String("instance_id", instanceID),
 			slog.Any("errors", validationResult.Errors))
 
-		// Publish failed status
-		errorMsg := fmt.Sprintf("Validation failed: %v", validationResult.Errors)
-		if err := m.eventPublisher.PublishFailed(ctx, instanceID, name, errorMsg); err != nil {
-			m.logger.Warn("Failed to publish failed status",
-				slog.String("instance_id", instanceID),
-				slog.String("error", err.Error()))
-		}
+		// Publish failed status
+		errorMsg := fmt.Sprintf("Validation failed: %v", validationResult.Errors)
+		if err := m.eventPublisher.PublishFailed(ctx, instanceID, name, errorMsg); err != nil {
+			m.logger.WarnContext(ctx, "Failed to publish failed status",
+				slog.String("instance_id", instanceID),
+				slog.String("error", err.Error()))
+		}
+		m.webhooks.Notify(webhooks.Event{
+			Type:        webhooks.EventContainerFailed,
+			InstanceID:  instanceID,
+			ServiceName: name,
+			Status:      string(models.StatusError),
+			Error:       errorMsg,
+		})
+
+		return fmt.Errorf("container validation failed: %v", validationResult.Errors)
+	}
+
+	// Log warnings if any
+	if len(validationResult.Warnings) > 0 {
+		m.logger.WarnContext(ctx, "Container validation completed with warnings",
+			slog.String("instance_id", instanceID),
+			slog.Any("warnings", validationResult.Warnings))
+	}
+
+	// Extract image (validated above)
+	image, ok := jsonSpec["image"].(string)
+	if !ok || image == "" {
+		return fmt.Errorf("image is required in json_spec")
+	}
+
+	// Get container name for later use
+	containerName := m.config.GetContainerName(name)
+
+	// Extract container port (for internal use)
+	containerPort := 8000 // Default MCP port
+	if p, ok := jsonSpec["port"].(float64); ok {
+		containerPort = int(p)
+	} else if p, ok := jsonSpec["port"].(int); ok {
+		containerPort = p
+	} else if exposed := m.imageExposedPort(ctx, image); exposed != 0 {
+		// Without a port in json_spec, the one port the image exposes beats the default
+		containerPort = exposed
+	}
+
+	// Extract environment variables
+	environment := make(map[string]string)
+	if env, ok := jsonSpec["environment"].(map[string]interface{}); ok {
+		for k, v := range env {
+			if str, ok := v.(string); ok {
+				environment[k] = str
+			}
+		}
+	}
+
+	// Extract custom command (optional)
+	var command []string
+	if cmdInterface, ok := jsonSpec["cmd"]; ok {
+		if cmdSlice, ok := cmdInterface.([]interface{}); ok {
+			for _, cmdItem := range cmdSlice {
+				if cmdStr, ok := cmdItem.(string); ok {
+					command = append(command, cmdStr)
+				}
+			}
+		}
+	}
+
+	// Extract health check configuration (optional, validated above)
+	healthCheck, err := parseHealthCheckSpec(jsonSpec)
+	if err != nil {
+		return fmt.Errorf("invalid health_check in json_spec: %w", err)
+	}
+
+	// Extract route middleware and routing configuration (optional, validated above)
+	route, err := parseRouteSpec(jsonSpec)
+	if err != nil {
+		return fmt.Errorf("invalid route in json_spec: %w", err)
+	}
+	if route, err = withProxyToken(route); err != nil {
+		return err
+	}
+	routing, err := parseRoutingSpec(jsonSpec)
+	if err != nil {
+		return fmt.Errorf("invalid routing in json_spec: %w", err)
+	}
+
+	// Extract GPU and device passthrough (optional, validated above)
+	gpus, devices, err := parseGPUSpec(jsonSpec)
+	if err != nil {
+		return fmt.Errorf("invalid devices in json_spec: %w", err)
+	}
+
+	// Extract per-instance resource limits (optional) and apply defaults and quotas
+	requested, err := parseResourcesSpec(jsonSpec)
+	if err != nil {
+		return fmt.Errorf("invalid resources in json_spec: %w", err)
+	}
+	resources, err := m.resolveResources(requested)
+	if err != nil {
+		return fmt.Errorf("invalid resources in json_spec: %w", err)
+	}
+
+	// Extract the owning workspace, which selects the container's network
+	workspaceID, sharedNetwork, err := parseNetworkSpec(jsonSpec)
+	if err != nil {
+		return fmt.Errorf("invalid network settings in json_spec: %w", err)
+	}
+
+	// Extract DNS servers, hosts entries and proxy (optional) and apply the manager's defaults
+	requestedNetwork, err := parseConnectivitySpec(jsonSpec)
+	if err != nil {
+		return fmt.Errorf("invalid network settings in json_spec: %w", err)
+	}
+	network, err := m.resolveConnectivity(requestedNetwork)
+	if err != nil {
+		return fmt.Errorf("invalid network settings in json_spec: %w", err)
+	}
+
+	// Extract tmpfs and scratch volume mounts (optional) and check them against the limits
+	tmpfs, scratchVolumes, err := parseScratchSpec(jsonSpec)
+	if err != nil {
+		return fmt.Errorf("invalid mounts in json_spec: %w", err)
+	}
+	if err := m.checkScratchPolicy(tmpfs, scratchVolumes); err != nil {
+		return fmt.Errorf("invalid mounts in json_spec: %w", err)
+	}
+
+	// Extract the files projected into the container (optional)
+	files, err := parseFilesSpec(jsonSpec)
+	if err != nil {
+		return fmt.Errorf("invalid files in json_spec: %w", err)
+	}
+	if err := m.checkFilesPolicy(files); err != nil {
+		return fmt.Errorf("invalid files in json_spec: %w", err)
+	}
 
-		return fmt.Errorf("container validation failed: %v", validationResult.Errors)
+	// Extract dependencies, which are provisioned before the container starts
+	dependsOn, err := parseDependsOnSpec(jsonSpec)
+	if err != nil {
+		return fmt.Errorf("invalid depends_on in json_spec: %w", err)
 	}
 
-	// Log warnings if any
-	if len(validationResult.Warnings) > 0 {
-		m.logger.Warn("Container validation completed with warnings",
-			slog.String("instance_id", instanceID),
-			slog.Any("warnings", validationResult.Warnings))
+	// Extract the init command run before the container starts (optional)
+	initSpec, err := parseInitSpec(jsonSpec)
+	if err != nil {
+		return fmt.Errorf("invalid init in json_spec: %w", err)
 	}
 
-	// Extract image (validated above)
-	image, ok := jsonSpec["image"].(string)
-	if !ok || image == "" {
-		return fmt.Errorf("image is required in json_spec")
+	// Extract where the container's logs are forwarded (optional)
+	logShipping, err := parseLogShippingSpec(jsonSpec)
+	if err != nil {
+		return fmt.Errorf("invalid log_shipping in json_spec: %w", err)
 	}
 
-	// Get container name for later use
-	containerName := m.config.GetContainerName(name)
+	// Extract the start/stop schedule (optional)
+	schedule, err := parseScheduleSpec(jsonSpec)
+	if err != nil {
+		return fmt.Errorf("invalid schedule in json_spec: %w", err)
+	}
 
-	// Extract container port (for internal use)
-	containerPort := 8000 // Default MCP port
-	if p, ok := jsonSpec["port"].(float64); ok {
-		containerPort = int(p)
-	} else if p, ok := jsonSpec["port"].(int); ok {
-		containerPort = p
+	// Extract the replica bounds and targets (optional)
+	scaling, err := parseScalingSpec(jsonSpec)
+	if err != nil {
+		return fmt.Errorf("invalid scaling in json_spec: %w", err)
+	}
+	if err := m.checkScaling(scaling, route); err != nil {
+		return fmt.Errorf("invalid scaling in json_spec: %w", err)
 	}
 
-	// Extract environment variables
-	environment := make(map[string]string)
-	if env, ok := jsonSpec["environment"].(map[string]interface{}); ok {
-		for k, v := range env {
-			if str, ok := v.(string); ok {
-				environment[k] = str
-			}
+	// Extract the preferred slug (optional)
+	preferredSlug, _ := jsonSpec["slug"].(string)
+	if preferredSlug != "" {
+		if err := validateSlug(preferredSlug); err != nil {
+			return fmt.Errorf("invalid slug in json_spec: %w", err)
 		}
 	}
 
-	// Extract custom command (optional)
-	var command []string
-	if cmdInterface, ok := jsonSpec["cmd"]; ok {
-		if cmdSlice, ok := cmdInterface.([]interface{}); ok {
-			for _, cmdItem := range cmdSlice {
-				if cmdStr, ok := cmdItem.(string); ok {
-					command = append(command, cmdStr)
-				}
-			}
-		}
+	// Check the environment against its schema (optional), filling in declared defaults
+	envSchema, err := parseEnvSchemaSpec(jsonSpec)
+	if err != nil {
+		return fmt.Errorf("invalid env_schema in json_spec: %w", err)
+	}
+	if environment, err = ApplyEnvSchema(envSchema, environment); err != nil {
+		return err
+	}
+
+	// Extract security overrides (optional) and check them against the security policy
+	security, err := parseSecuritySpec(jsonSpec)
+	if err != nil {
+		return fmt.Errorf("invalid security in json_spec: %w", err)
+	}
+	if err := m.checkSecurityPolicy(security); err != nil {
+		return fmt.Errorf("invalid security in json_spec: %w", err)
 	}
 
 	// Add MCP-specific environment variables
@@ -778,22 +1762,67 @@ func (m *Manager) HandleMCPInstanceCreated(ctx context.Context, instanceID, name
 	environment["MCP_SERVICE_NAME"] = name
 	environment["MCP_CONTAINER_PORT"] = fmt.Sprintf("%d", containerPort)
 
+	// Bound concurrent podman runs so a burst of instance events cannot wedge the host
+	if err := m.createGate.acquire(ctx); err != nil {
+		errorMsg := fmt.Sprintf("Failed to create container: %v", err)
+		if publishErr := m.eventPublisher.PublishFailed(ctx, instanceID, name, errorMsg); publishErr != nil {
+			m.logger.WarnContext(ctx, "Failed to publish failed status",
+				slog.String("instance_id", instanceID),
+				slog.String("error", publishErr.Error()))
+		}
+		return fmt.Errorf("failed to create container %s: %w", name, err)
+	}
+	defer m.createGate.release()
+
+	// Only now that the instance passed validation, make room for it by preempting lower-priority
+	// ones when every slot is taken
+	if !replacing {
+		m.preemptWhileFull(ctx, name, priority)
+	}
+
 	// NOW ACQUIRE MUTEX FOR CONTAINER OPERATIONS
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
 	// Check if container already exists
-	if _, exists := m.containers[name]; exists {
+	current, exists := m.containers[name]
+	if exists && !replacing {
 		return fmt.Errorf("container %s already exists", name)
 	}
 
+	// A hostname can only be routed to one container
+	if hostname := routingHostname(routing); m.hostnameInUseUnsafe(hostname, name) {
+		return fmt.Errorf("hostname %s is already routed to another container", hostname)
+	}
+
 	// Check container limit
-	if len(m.containers) >= m.config.Container.MaxContainers {
+	if !exists && m.capacityUsedUnsafe() >= m.config.Container.MaxContainers {
 		return fmt.Errorf("maximum container limit reached (%d)", m.config.Container.MaxContainers)
 	}
 
-	// Generate a unique slug for routing
-	slug := generateSlug(name)
+	// Route a recreated instance under the slug it had, so agents holding its URL keep working
+	slug, err := m.slugForUnsafe("", preferredSlug, instanceID, name)
+	if err != nil {
+		errorMsg := fmt.Sprintf("Failed to create container: %v", err)
+		if publishErr := m.eventPublisher.PublishFailed(ctx, instanceID, name, errorMsg); publishErr != nil {
+			m.logger.WarnContext(ctx, "Failed to publish failed status",
+				slog.String("instance_id", instanceID),
+				slog.String("error", publishErr.Error()))
+		}
+		return fmt.Errorf("failed to create container %s: %w", name, err)
+	}
+
+	// Reserve GPUs while holding the lock so concurrent instances cannot oversubscribe the host
+	gpuDevices, err := m.allocateGPUsUnsafe(name, gpus, devices)
+	if err != nil {
+		errorMsg := fmt.Sprintf("Failed to create container: %v", err)
+		if publishErr := m.eventPublisher.PublishFailed(ctx, instanceID, name, errorMsg); publishErr != nil {
+			m.logger.WarnContext(ctx, "Failed to publish failed status",
+				slog.String("instance_id", instanceID),
+				slog.String("error", publishErr.Error()))
+		}
+		return fmt.Errorf("failed to create container %s: %w", name, err)
+	}
 
 	// Create container with initial status
 	container := &models.Container{
@@ -810,10 +1839,49 @@ func (m *Manager) HandleMCPInstanceCreated(ctx context.Context, instanceID, name
 		Labels:      make(map[string]string), // No labels needed for Traefik
 		Environment: environment,
 		Command:     command,
-	}
+		HealthCheck: healthCheck,
+		Route:       route,
+		Routing:     routing,
+		UpstreamTLS: m.config.MTLS.Upstream,
+		GPUs:        gpus,
+		Devices:     devices,
+		GPUDevices:  gpuDevices,
+		Resources:   resources,
+		Security:    security,
+
+		WorkspaceID:   workspaceID,
+		Network:       m.workspaceNetworkName(workspaceID),
+		SharedNetwork: sharedNetwork,
+		DNS:           network.DNS,
+		ExtraHosts:    network.ExtraHosts,
+		Proxy:         network.Proxy,
+
+		Tmpfs:          tmpfs,
+		ScratchVolumes: scratchVolumes,
+		Files:          files,
+		DependsOn:      dependsOn,
+		Init:           initSpec,
+		Source:         source,
+		Runtime:        runtime,
+		LogShipping:    logShipping,
+		Schedule:       schedule,
+		Priority:       priority,
+		Scaling:        scaling,
+		SpecHash:       hash,
+		EnvSchema:      envSchema,
+		Platform:       validationResult.Platform,
+		Emulated:       validationResult.Emulated,
+	}
+	container.Provenance = specProvenance(sentSpec, container)
+	container.Pod = m.workspacePodUnsafe(container)
+	if exists {
+		return m.updateInstanceUnsafe(ctx, instanceID, current, container)
+	}
+	m.journalStep(ctx, op, stepProvision, container)
 
 	// Store container in tracking map with validating status
 	m.containers[name] = container
+	m.recordSlug(ctx, container)
 
 	// Update status to starting
 	container.Status = models.StatusStarting
@@ -821,42 +1889,103 @@ func (m *Manager) HandleMCPInstanceCreated(ctx context.Context, instanceID, name
 
 	// Publish starting status
 	if err := m.eventPublisher.PublishStarting(ctx, instanceID, name); err != nil {
-		m.logger.Warn("Failed to publish starting status",
+		m.logger.WarnContext(ctx, "Failed to publish starting status",
 			slog.String("instance_id", instanceID),
 			slog.String("error", err.Error()))
 	}
 
-	m.logger.Info("Starting container creation",
+	m.logger.InfoContext(ctx, "Starting container creation",
 		slog.String("container", containerName),
 		slog.String("instance_id", instanceID),
 		slog.String("image", image))
 
+	// Prepare the workspace network, the scratch volumes the container mounts and its dependencies
+	err = m.ensureNetwork(ctx, container.Network)
+	if err == nil {
+		err = m.createScratchVolumes(ctx, container)
+	}
+	if err == nil {
+		err = m.provisionDependenciesUnsafe(ctx, container)
+	}
+	if err != nil {
+		container.Status = models.StatusError
+
+		errorMsg := fmt.Sprintf("Failed to create container: %v", err)
+		if publishErr := m.eventPublisher.PublishFailed(ctx, instanceID, name, errorMsg); publishErr != nil {
+			m.logger.WarnContext(ctx, "Failed to publish failed status",
+				slog.String("instance_id", instanceID),
+				slog.String("error", publishErr.Error()))
+		}
+		m.notifyWebhook(webhooks.EventContainerFailed, container, errorMsg)
+		return err
+	}
+
+	// Run the init command to completion; its failure is reported as init_failed, not a start failure
+	if container.Init != nil {
+		container.Status = models.StatusInitializing
+		if err := m.eventPublisher.PublishInitializing(ctx, instanceID, name); err != nil {
+			m.logger.WarnContext(ctx, "Failed to publish initializing status",
+				slog.String("instance_id", instanceID),
+				slog.String("error", err.Error()))
+		}
+
+		if err := m.runInit(ctx, container); err != nil {
+			container.Status = models.StatusInitFailed
+			container.UpdatedAt = time.Now()
+
+			errorMsg := err.Error()
+			if publishErr := m.eventPublisher.PublishInitFailed(ctx, instanceID, name, errorMsg); publishErr != nil {
+				m.logger.WarnContext(ctx, "Failed to publish init failed status",
+					slog.String("instance_id", instanceID),
+					slog.String("error", publishErr.Error()))
+			}
+			m.notifyWebhook(webhooks.EventContainerFailed, container, errorMsg)
+			return fmt.Errorf("init failed for %s: %w", name, err)
+		}
+		container.Status = models.StatusStarting
+	}
+
+	// Issue the certificates the proxy and the container authenticate each other with
+	if err := m.provisionUpstreamTLS(container); err != nil {
+		container.Status = models.StatusError
+		if publishErr := m.eventPublisher.PublishFailed(ctx, instanceID, name, err.Error()); publishErr != nil {
+			m.logger.WarnContext(ctx, "Failed to publish failed status",
+				slog.String("instance_id", instanceID),
+				slog.String("error", publishErr.Error()))
+		}
+		m.notifyWebhook(webhooks.EventContainerFailed, container, err.Error())
+		return err
+	}
+
 	// Build podman run command
+	m.journalStep(ctx, op, stepRun, container)
 	args := m.buildPodmanRunArgs(container)
 
 	// Execute podman run
-	cmd := exec.CommandContext(ctx, "podman", args...)
-	output, err := cmd.CombinedOutput()
+	output, err := m.podmanRun(ctx, container, args)
 	if err != nil {
 		container.Status = models.StatusError
 
 		// Publish failed status
 		errorMsg := fmt.Sprintf("Failed to create container: %v", err)
 		if publishErr := m.eventPublisher.PublishFailed(ctx, instanceID, name, errorMsg); publishErr != nil {
-			m.logger.Warn("Failed to publish failed status",
+			m.logger.WarnContext(ctx, "Failed to publish failed status",
 				slog.String("instance_id", instanceID),
 				slog.String("error", publishErr.Error()))
 		}
 
-		m.logger.Error("Failed to create container",
+		m.logger.ErrorContext(ctx, "Failed to create container",
 			slog.String("container", containerName),
 			slog.String("error", err.Error()),
 			slog.String("output", string(output)))
+		m.notifyWebhook(webhooks.EventContainerFailed, container, errorMsg)
 		return fmt.Errorf("failed to create container: %w", err)
 	}
 
 	// Get container ID from output
 	container.ID = strings.TrimSpace(string(output))
+	m.journalStep(ctx, op, stepRoute, container)
+	m.scheduleChaosCrash(container)
 
 	// Wait for container to be running
 	if err := m.waitForContainer(ctx, container.ID); err != nil {
@@ -865,10 +1994,11 @@ func (m *Manager) HandleMCPInstanceCreated(ctx context.Context, instanceID, name
 		// Publish failed status
 		errorMsg := fmt.Sprintf("Container failed to start: %v", err)
 		if publishErr := m.eventPublisher.PublishFailed(ctx, instanceID, name, errorMsg); publishErr != nil {
-			m.logger.Warn("Failed to publish failed status",
+			m.logger.WarnContext(ctx, "Failed to publish failed status",
 				slog.String("instance_id", instanceID),
 				slog.String("error", publishErr.Error()))
 		}
+		m.notifyWebhook(webhooks.EventContainerFailed, container, errorMsg)
 
 		return fmt.Errorf("container failed to start: %w", err)
 	}
@@ -876,16 +2006,25 @@ func (m *Manager) HandleMCPInstanceCreated(ctx context.Context, instanceID, name
 	// Get container IP for Traefik routing
 	containerIP, err := m.getContainerIP(ctx, container.ID)
 	if err != nil {
-		m.logger.Error("Failed to get container IP",
+		m.logger.ErrorContext(ctx, "Failed to get container IP",
 			slog.String("container", containerName),
 			slog.String("error", err.Error()))
 		// Continue without IP - container is still created
 		containerIP = "127.0.0.1" // fallback
+	} else if !m.config.Container.PublishPorts {
+		// Route to the port the server actually listens on if json_spec named the wrong one
+		containerPort = m.negotiatePort(ctx, container, containerIP)
+		container.Port = containerPort
 	}
+	upstreamHost, upstreamPort := m.upstreamAddress(ctx, container, containerIP)
 
-	// Add Traefik route for the container using the slug
-	if err := m.traefikManager.AddMCPService(ctx, slug, containerIP, containerPort); err != nil {
-		m.logger.Error("Failed to add Traefik route",
+	// Add Traefik route for the container using the slug, or once the health monitor finds it ready
+	if m.routesAwaitReadiness() {
+		m.logger.DebugContext(ctx, "Deferring Traefik route until the server is ready",
+			slog.String("slug", slug),
+			slog.String("service", name))
+	} else if err := m.traefikManager.AddMCPService(ctx, slug, upstreamHost, upstreamPort, container.Route, container.Routing, m.upstreamTLS(container)); err != nil {
+		m.logger.ErrorContext(ctx, "Failed to add Traefik route",
 			slog.String("slug", slug),
 			slog.String("service", name),
 			slog.String("error", err.Error()))
@@ -893,17 +2032,24 @@ func (m *Manager) HandleMCPInstanceCreated(ctx context.Context, instanceID, name
 	}
 
 	// Update final status and container info
-	container.Status = models.StatusRunning
+	m.journalStep(ctx, op, stepStatus, container)
+	starting := m.beginStartupUnsafe(container)
+	if !starting {
+		container.Status = models.StatusRunning
+	}
 	container.UpdatedAt = time.Now()
 
-	// Publish running status
-	if err := m.eventPublisher.PublishRunning(ctx, instanceID, name, container.ID, container.URL); err != nil {
-		m.logger.Warn("Failed to publish running status",
-			slog.String("instance_id", instanceID),
-			slog.String("error", err.Error()))
+	// Publish running status; a container with a startup probe is published once the health monitor finds it running
+	if !starting {
+		if err := m.eventPublisher.PublishRunning(ctx, instanceID, name, container.ID, container.URL); err != nil {
+			m.logger.WarnContext(ctx, "Failed to publish running status",
+				slog.String("instance_id", instanceID),
+				slog.String("error", err.Error()))
+		}
 	}
+	m.notifyWebhook(webhooks.EventContainerCreated, container, "")
 
-	m.logger.Info("Container created successfully with Traefik routing",
+	m.logger.InfoContext(ctx, "Container created successfully with Traefik routing",
 		slog.String("container", containerName),
 		slog.String("id", container.ID),
 		slog.String("instance_id", instanceID),
@@ -916,9 +2062,48 @@ func (m *Manager) HandleMCPInstanceCreated(ctx context.Context, instanceID, name
 	return nil
 }
 
+// updateInstanceUnsafe replaces current, the container of instanceID, with next, built from the
+// instance's changed spec, and publishes the outcome. A replacement is not journaled with its
+// slug, as rolling it back would delete the instance. The caller holds the mutex.
+func (m *Manager) updateInstanceUnsafe(ctx context.Context, instanceID string, current, next *models.Container) error {
+	next.Status = models.StatusStarting
+	if current.StoppedAt == nil {
+		if err := m.eventPublisher.PublishStarting(ctx, instanceID, next.ServiceName); err != nil {
+			m.logger.WarnContext(ctx, "Failed to publish starting status",
+				slog.String("instance_id", instanceID),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	if err := m.replaceContainerUnsafe(ctx, current, next); err != nil {
+		errorMsg := fmt.Sprintf("Failed to update container: %v", err)
+		if publishErr := m.eventPublisher.PublishFailed(ctx, instanceID, current.ServiceName, errorMsg); publishErr != nil {
+			m.logger.WarnContext(ctx, "Failed to publish failed status",
+				slog.String("instance_id", instanceID),
+				slog.String("error", publishErr.Error()))
+		}
+		m.notifyWebhook(webhooks.EventContainerFailed, current, errorMsg)
+		return fmt.Errorf("failed to update instance %s: %w", instanceID, err)
+	}
+
+	if current.Status == models.StatusRunning && current.StoppedAt == nil {
+		if err := m.eventPublisher.PublishRunning(ctx, instanceID, current.ServiceName, current.ID, current.URL); err != nil {
+			m.logger.WarnContext(ctx, "Failed to publish running status",
+				slog.String("instance_id", instanceID),
+				slog.String("error", err.Error()))
+		}
+	}
+	m.notifyWebhook(webhooks.EventContainerCreated, current, "")
+	m.logger.InfoContext(ctx, "Instance updated to its changed spec",
+		slog.String("instance_id", instanceID),
+		slog.String("service", current.ServiceName),
+		slog.String("id", current.ID))
+	return nil
+}
+
 // HandleMCPInstanceDeleted handles the deletion of an MCP server instance from domain events
 func (m *Manager) HandleMCPInstanceDeleted(ctx context.Context, instanceID string) error {
-	m.logger.Info("Handling MCP instance deletion",
+	m.logger.InfoContext(ctx, "Handling MCP instance deletion",
 		slog.String("instance_id", instanceID))
 
 	// Find container by MCP instance ID
@@ -933,7 +2118,15 @@ func (m *Manager) HandleMCPInstanceDeleted(ctx context.Context, instanceID strin
 	}
 
 	if targetContainer == nil {
-		m.logger.Warn("No container found for MCP instance",
+		if m.dequeueCreateEvent(instanceID) {
+			m.logger.InfoContext(ctx, "Dropped create event queued while cordoned",
+				slog.String("instance_id", instanceID))
+			return nil
+		}
+		if archived := m.findArchivedByInstanceID(instanceID); archived != "" {
+			return m.DeleteContainer(ctx, archived)
+		}
+		m.logger.WarnContext(ctx, "No container found for MCP instance",
 			slog.String("instance_id", instanceID))
 		return nil // Not an error - container might have been manually deleted
 	}
@@ -941,14 +2134,16 @@ func (m *Manager) HandleMCPInstanceDeleted(ctx context.Context, instanceID strin
 	// Delete the container using existing functionality (includes Traefik route cleanup)
 	err := m.DeleteContainer(ctx, targetContainer.ServiceName)
 	if err != nil {
-		m.logger.Error("Failed to delete MCP container",
+		m.logger.ErrorContext(ctx, "Failed to delete MCP container",
 			slog.String("instance_id", instanceID),
 			slog.String("service_name", targetContainer.ServiceName),
 			slog.String("error", err.Error()))
 		return err
 	}
 
-	m.logger.Info("Successfully deleted MCP container",
+	m.callbacks.Forget(instanceID)
+
+	m.logger.InfoContext(ctx, "Successfully deleted MCP container",
 		slog.String("instance_id", instanceID),
 		slog.String("service_name", targetContainer.ServiceName))
 
@@ -977,14 +2172,14 @@ func generateSlug(name string) string {
 
 // ValidateContainerSpec validates container specification before creation
 func (m *Manager) ValidateContainerSpec(ctx context.Context, instance *models.MCPServerInstance, allowImagePull bool) (*ValidationResult, error) {
-	m.logger.Info("Validating container specification",
+	m.logger.InfoContext(ctx, "Validating container specification",
 		slog.String("instance_id", instance.InstanceID),
 		slog.String("name", instance.Name))
 
 	// Use validator for dry-run validation
 	result, err := m.validator.DryRunValidation(ctx, instance)
 	if err != nil {
-		m.logger.Error("Dry-run validation failed",
+		m.logger.ErrorContext(ctx, "Dry-run validation failed",
 			slog.String("instance_id", instance.InstanceID),
 			slog.String("error", err.Error()))
 		return nil, fmt.Errorf("dry-run validation failed: %w", err)
@@ -996,7 +2191,7 @@ func (m *Manager) ValidateContainerSpec(ctx context.Context, instance *models.MC
 		if ok && image != "" {
 			imageResult, err := m.validator.ValidateContainerImage(ctx, image, allowImagePull)
 			if err != nil {
-				m.logger.Error("Image validation failed",
+				m.logger.ErrorContext(ctx, "Image validation failed",
 					slog.String("instance_id", instance.InstanceID),
 					slog.String("image", image),
 					slog.String("error", err.Error()))
@@ -1005,29 +2200,46 @@ func (m *Manager) ValidateContainerSpec(ctx context.Context, instance *models.MC
 
 			// If image needs to be pulled, do it with progress tracking
 			if !imageResult.ImageExists && imageResult.CanPull {
-				m.logger.Info("Pulling required image",
+				m.reportPhase(instance.InstanceID, instance.Name, callbacks.PhasePulling)
+				m.logger.InfoContext(ctx, "Pulling required image",
 					slog.String("instance_id", instance.InstanceID),
 					slog.String("image", image))
 
-				err = m.validator.PullImageWithProgress(ctx, image, func(progress string) {
-					m.logger.Debug("Image pull progress",
+				pullPlatform := ""
+				if imageResult.Emulated {
+					pullPlatform = imageResult.Platform
+				}
+				err = m.validator.PullImageWithProgress(ctx, image, pullPlatform, func(progress string) {
+					m.logger.DebugContext(ctx, "Image pull progress",
 						slog.String("instance_id", instance.InstanceID),
 						slog.String("image", image),
 						slog.String("progress", progress))
 				})
 
 				if err != nil {
-					m.logger.Error("Failed to pull image",
+					m.logger.ErrorContext(ctx, "Failed to pull image",
 						slog.String("instance_id", instance.InstanceID),
 						slog.String("image", image),
 						slog.String("error", err.Error()))
+					m.recordInstanceEvent(instance.InstanceID, InstanceEvent{
+						Type:        InstanceEventWarning,
+						Reason:      "PullFailed",
+						Message:     fmt.Sprintf("Failed to pull image %s: %v", image, err),
+						ServiceName: instance.Name,
+					})
 					return nil, fmt.Errorf("failed to pull image: %w", err)
 				}
+				m.recordInstanceEvent(instance.InstanceID, InstanceEvent{
+					Type:        InstanceEventNormal,
+					Reason:      "Pulled",
+					Message:     fmt.Sprintf("Pulled image %s", image),
+					ServiceName: instance.Name,
+				})
 			}
 		}
 	}
 
-	m.logger.Info("Container specification validation completed",
+	m.logger.InfoContext(ctx, "Container specification validation completed",
 		slog.String("instance_id", instance.InstanceID),
 		slog.Bool("valid", result.Valid),
 		slog.Int("errors", len(result.Errors)),
@@ -1038,7 +2250,7 @@ func (m *Manager) ValidateContainerSpec(ctx context.Context, instance *models.MC
 
 // ValidateContainerSpecWithLimits validates container specification with explicit container limits (deadlock-safe)
 func (m *Manager) ValidateContainerSpecWithLimits(ctx context.Context, instance *models.MCPServerInstance, allowImagePull bool, currentRunningCount int, maxContainers int) (*ValidationResult, error) {
-	m.logger.Info("Validating container specification with limits",
+	m.logger.InfoContext(ctx, "Validating container specification with limits",
 		slog.String("instance_id", instance.InstanceID),
 		slog.String("name", instance.Name),
 		slog.Int("current_running", currentRunningCount),
@@ -1047,7 +2259,7 @@ func (m *Manager) ValidateContainerSpecWithLimits(ctx context.Context, instance
 	// Use validator for dry-run validation (but avoid manager callbacks that cause deadlock)
 	result, err := m.validator.DryRunValidationWithLimits(ctx, instance, currentRunningCount, maxContainers)
 	if err != nil {
-		m.logger.Error("Dry-run validation failed",
+		m.logger.ErrorContext(ctx, "Dry-run validation failed",
 			slog.String("instance_id", instance.InstanceID),
 			slog.String("error", err.Error()))
 		return nil, fmt.Errorf("dry-run validation failed: %w", err)
@@ -1059,7 +2271,7 @@ func (m *Manager) ValidateContainerSpecWithLimits(ctx context.Context, instance
 		if ok && image != "" {
 			imageResult, err := m.validator.ValidateContainerImage(ctx, image, allowImagePull)
 			if err != nil {
-				m.logger.Error("Image validation failed",
+				m.logger.ErrorContext(ctx, "Image validation failed",
 					slog.String("instance_id", instance.InstanceID),
 					slog.String("image", image),
 					slog.String("error", err.Error()))
@@ -1068,29 +2280,46 @@ func (m *Manager) ValidateContainerSpecWithLimits(ctx context.Context, instance
 
 			// If image needs to be pulled, do it with progress tracking
 			if !imageResult.ImageExists && imageResult.CanPull {
-				m.logger.Info("Pulling required image",
+				m.reportPhase(instance.InstanceID, instance.Name, callbacks.PhasePulling)
+				m.logger.InfoContext(ctx, "Pulling required image",
 					slog.String("instance_id", instance.InstanceID),
 					slog.String("image", image))
 
-				err = m.validator.PullImageWithProgress(ctx, image, func(progress string) {
-					m.logger.Debug("Image pull progress",
+				pullPlatform := ""
+				if imageResult.Emulated {
+					pullPlatform = imageResult.Platform
+				}
+				err = m.validator.PullImageWithProgress(ctx, image, pullPlatform, func(progress string) {
+					m.logger.DebugContext(ctx, "Image pull progress",
 						slog.String("instance_id", instance.InstanceID),
 						slog.String("image", image),
 						slog.String("progress", progress))
 				})
 
 				if err != nil {
-					m.logger.Error("Failed to pull image",
+					m.logger.ErrorContext(ctx, "Failed to pull image",
 						slog.String("instance_id", instance.InstanceID),
 						slog.String("image", image),
 						slog.String("error", err.Error()))
+					m.recordInstanceEvent(instance.InstanceID, InstanceEvent{
+						Type:        InstanceEventWarning,
+						Reason:      "PullFailed",
+						Message:     fmt.Sprintf("Failed to pull image %s: %v", image, err),
+						ServiceName: instance.Name,
+					})
 					return nil, fmt.Errorf("failed to pull image: %w", err)
 				}
+				m.recordInstanceEvent(instance.InstanceID, InstanceEvent{
+					Type:        InstanceEventNormal,
+					Reason:      "Pulled",
+					Message:     fmt.Sprintf("Pulled image %s", image),
+					ServiceName: instance.Name,
+				})
 			}
 		}
 	}
 
-	m.logger.Info("Container specification validation completed",
+	m.logger.InfoContext(ctx, "Container specification validation completed",
 		slog.String("instance_id", instance.InstanceID),
 		slog.Bool("valid", result.Valid),
 		slog.Int("errors", len(result.Errors)),
@@ -1103,8 +2332,8 @@ func (m *Manager) ValidateContainerSpecWithLimits(ctx context.Context, instance
 func (m *Manager) startHealthMonitoring() {
 	m.logger.Info("Starting background health monitoring")
 
-	// Check health every 30 seconds
-	ticker := time.NewTicker(30 * time.Second)
+	// Tick frequently and only probe containers whose own interval has elapsed
+	ticker := time.NewTicker(healthMonitorTickInterval)
 	defer ticker.Stop()
 
 	// Do initial health check
@@ -1121,48 +2350,70 @@ func (m *Manager) startHealthMonitoring() {
 	}
 }
 
-// performHealthCheckAll performs health checks on all containers
+// performHealthCheckAll performs health checks on all containers that are due, using a bounded worker pool
 func (m *Manager) performHealthCheckAll() {
-	m.mutex.RLock()
-	containers := make([]*models.Container, 0, len(m.containers))
+	now := time.Now()
+
+	m.mutex.Lock()
+	due := make([]*models.Container, 0, len(m.containers))
 	for _, container := range m.containers {
-		containers = append(containers, container)
+		counters, exists := m.healthCounters[container.Name]
+		if !exists {
+			counters = &healthCounters{}
+			m.healthCounters[container.Name] = counters
+		}
+		if container.StoppedAt != nil {
+			// Stopped by a user, so there is nothing to probe
+			continue
+		}
+		if counters.inFlight || now.Before(counters.nextCheck) {
+			continue
+		}
+		counters.inFlight = true
+		due = append(due, container)
 	}
-	m.mutex.RUnlock()
+	m.mutex.Unlock()
 
-	if len(containers) == 0 {
+	if len(due) == 0 {
 		return
 	}
 
-	m.logger.Debug("Performing health checks on all containers",
-		slog.Int("container_count", len(containers)))
+	m.logger.Debug("Performing health checks on due containers",
+		slog.Int("container_count", len(due)))
 
-	// Perform health checks
-	for _, container := range containers {
-		// Create a timeout context for each health check
-		healthCtx, cancel := context.WithTimeout(m.healthCtx, 15*time.Second)
+	runBounded(m.config.HealthMonitor.Workers, len(due), func(i int) {
+		m.checkContainerHealth(due[i])
+	})
+}
 
-		result, err := m.healthChecker.PerformHealthCheck(healthCtx, container)
-		if err != nil {
-			m.logger.Error("Health check failed",
-				slog.String("container", container.Name),
-				slog.String("error", err.Error()))
+// checkContainerHealth probes a single container and records the outcome
+func (m *Manager) checkContainerHealth(container *models.Container) {
+	if m.checkStartup(container) {
+		return
+	}
 
-			// Create error result
-			result = &HealthCheckResult{
-				ContainerID: container.ID,
-				ServiceName: container.ServiceName,
-				Healthy:     false,
-				Status:      container.Status,
-				Error:       err.Error(),
-				Timestamp:   time.Now(),
-			}
-		}
+	healthCtx, cancel := context.WithTimeout(m.healthCtx, healthCheckDeadline(container.HealthCheck))
+	defer cancel()
+
+	result, err := m.healthChecker.PerformHealthCheck(healthCtx, container)
+	if err != nil {
+		m.logger.Error("Health check failed",
+			slog.String("container", container.Name),
+			slog.String("error", err.Error()))
 
-		// Update health status
-		m.updateContainerHealth(container, result)
-		cancel()
+		// Create error result
+		result = &HealthCheckResult{
+			ContainerID: container.ID,
+			ServiceName: container.ServiceName,
+			Healthy:     false,
+			Status:      container.Status,
+			Error:       err.Error(),
+			Timestamp:   time.Now(),
+		}
 	}
+
+	// Update health status
+	m.updateContainerHealth(container, result)
 }
 
 // updateContainerHealth updates the health status of a container
@@ -1170,12 +2421,32 @@ func (m *Manager) updateContainerHealth(container *models.Container, result *Hea
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
+	// Ignore results for containers deleted or archived while the check was running
+	if tracked, exists := m.containers[container.ServiceName]; !exists || tracked != container {
+		if counters, exists := m.healthCounters[container.Name]; exists {
+			counters.inFlight = false
+		}
+		return
+	}
+
 	// Store health result
 	m.containerHealth[container.Name] = result
+	m.recordHealthHistory(container, result)
+	if !result.inspect.fetchedAt.IsZero() {
+		result.inspect.applyExit(container)
+	}
 
 	// Update container status based on health
 	previousStatus := container.Status
-	newStatus := m.determineContainerStatus(result)
+	newStatus := m.applyHealthThresholds(container, m.determineContainerStatus(result), result)
+	m.applyReadinessUnsafe(container, result)
+
+	// The first passing check completes provisioning; repeats are dropped by the reporter
+	if newStatus == models.StatusRunning && result.Healthy {
+		if instanceID := container.Environment["MCP_INSTANCE_ID"]; instanceID != "" {
+			m.reportPhase(instanceID, container.ServiceName, callbacks.PhaseHealthy)
+		}
+	}
 
 	if newStatus != previousStatus {
 		container.Status = newStatus
@@ -1188,6 +2459,11 @@ func (m *Manager) updateContainerHealth(container *models.Container, result *Hea
 			slog.Bool("healthy", result.Healthy),
 			slog.Bool("http_reachable", result.HTTPReachable))
 
+		m.notifyHealthTransition(container, previousStatus, newStatus, result.Error)
+		if newStatus == models.StatusStopped {
+			m.handleExitUnsafe(container)
+		}
+
 		// Publish status change event if needed
 		if instanceID, exists := container.Environment["MCP_INSTANCE_ID"]; exists {
 			go func() {
@@ -1199,6 +2475,8 @@ func (m *Manager) updateContainerHealth(container *models.Container, result *Hea
 					publishErr = m.eventPublisher.PublishFailed(m.healthCtx, instanceID, container.ServiceName, result.Error)
 				case models.StatusStopped:
 					publishErr = m.eventPublisher.PublishStatusUpdate(m.healthCtx, instanceID, container.ServiceName, "stopped", container.ID, "")
+				case models.StatusUnhealthy:
+					publishErr = m.eventPublisher.PublishStatusUpdate(m.healthCtx, instanceID, container.ServiceName, schema.StatusUnhealthy, container.ID, "")
 				}
 
 				if publishErr != nil {
@@ -1229,6 +2507,80 @@ func (m *Manager) determineContainerStatus(result *HealthCheckResult) models.Con
 	return result.Status
 }
 
+// applyHealthThresholds debounces status changes using the container's healthy/unhealthy thresholds.
+// Containers without a health check configuration keep the immediate transitions of the default behavior.
+// Callers hold the mutex.
+func (m *Manager) applyHealthThresholds(container *models.Container, observed models.ContainerStatus, result *HealthCheckResult) models.ContainerStatus {
+	counters, exists := m.healthCounters[container.Name]
+	if !exists {
+		counters = &healthCounters{}
+		m.healthCounters[container.Name] = counters
+	}
+	counters.lastChecked = result.Timestamp
+	counters.nextCheck = result.Timestamp.Add(jitteredInterval(healthInterval(container.HealthCheck)))
+	counters.inFlight = false
+
+	if observed == models.StatusRunning {
+		counters.consecutiveSuccesses++
+		counters.consecutiveFailures = 0
+	} else {
+		counters.consecutiveFailures++
+		counters.consecutiveSuccesses = 0
+	}
+
+	if container.HealthCheck == nil {
+		return observed
+	}
+
+	healthyThreshold, unhealthyThreshold := healthThresholds(container.HealthCheck)
+
+	switch {
+	case observed == models.StatusRunning:
+		// Recover only after enough consecutive successful probes
+		if container.Status != models.StatusRunning && counters.consecutiveSuccesses < healthyThreshold {
+			return container.Status
+		}
+		return models.StatusRunning
+	case result.Status == models.StatusRunning:
+		// The process is up but the probe fails: mark unhealthy once the threshold is breached
+		if counters.consecutiveFailures < unhealthyThreshold {
+			return container.Status
+		}
+		return models.StatusUnhealthy
+	default:
+		return observed
+	}
+}
+
+// notifyHealthTransition emits unhealthy/recovered webhooks for health status changes
+func (m *Manager) notifyHealthTransition(container *models.Container, previous, current models.ContainerStatus, errMsg string) {
+	switch {
+	case previous == models.StatusRunning && (current == models.StatusError || current == models.StatusStopped || current == models.StatusUnhealthy):
+		m.notifyWebhook(webhooks.EventContainerUnhealthy, container, errMsg)
+	case current == models.StatusRunning && (previous == models.StatusError || previous == models.StatusStopped || previous == models.StatusUnhealthy):
+		m.notifyWebhook(webhooks.EventContainerRecovered, container, "")
+	}
+}
+
+// notifyWebhook sends a lifecycle webhook for a container
+func (m *Manager) notifyWebhook(eventType webhooks.EventType, container *models.Container, errMsg string) {
+	m.recordWebhookEvent(eventType, container, errMsg)
+	m.webhooks.Notify(webhooks.Event{
+		Type:        eventType,
+		InstanceID:  container.Environment["MCP_INSTANCE_ID"],
+		ServiceName: container.ServiceName,
+		ContainerID: container.ID,
+		Status:      string(container.Status),
+		URL:         container.URL,
+		Error:       errMsg,
+	})
+}
+
+// Webhooks returns the webhook dispatcher used for lifecycle notifications
+func (m *Manager) Webhooks() *webhooks.Dispatcher {
+	return m.webhooks
+}
+
 // GetContainerHealthStatus returns the health status of a container
 func (m *Manager) GetContainerHealthStatus(serviceName string) (*HealthCheckResult, bool) {
 	m.mutex.RLock()
@@ -1241,7 +2593,10 @@ func (m *Manager) GetContainerHealthStatus(serviceName string) (*HealthCheckResu
 
 // Shutdown gracefully shuts down the container manager
 func (m *Manager) Shutdown(ctx context.Context) error {
-	m.logger.Info("Shutting down container manager")
+	m.logger.InfoContext(ctx, "Shutting down container manager")
+
+	// Let creates and deletes finish, or roll them back, before monitoring stops
+	m.DrainOperations(ctx)
 
 	// Cancel health monitoring
 	if m.healthCancel != nil {
@@ -1251,9 +2606,9 @@ func (m *Manager) Shutdown(ctx context.Context) error {
 	// Wait for health monitoring to stop or timeout
 	select {
 	case <-ctx.Done():
-		m.logger.Warn("Shutdown timeout reached")
+		m.logger.WarnContext(ctx, "Shutdown timeout reached")
 	case <-time.After(5 * time.Second):
-		m.logger.Info("Container manager shutdown complete")
+		m.logger.InfoContext(ctx, "Container manager shutdown complete")
 	}
 
 	return nil
@@ -1277,23 +2632,25 @@ func (m *Manager) autoRestartContainers(ctx context.Context) error {
 	}
 
 	if len(containersToRestart) == 0 {
-		m.logger.Info("No containers need to be restarted")
+		m.logger.InfoContext(ctx, "No containers need to be restarted")
 		return nil
 	}
 
-	m.logger.Info("Auto-restarting stopped containers",
+	m.logger.InfoContext(ctx, "Auto-restarting stopped containers",
 		slog.Int("count", len(containersToRestart)))
 
 	// Restart containers
 	for _, container := range containersToRestart {
 		if err := m.restartContainer(ctx, container); err != nil {
-			m.logger.Error("Failed to restart container",
+			m.logger.ErrorContext(ctx, "Failed to restart container",
 				slog.String("container", container.Name),
 				slog.String("error", err.Error()))
+			m.recordContainerEvent(container, InstanceEventWarning, "RestartFailed", err.Error())
 			continue
 		}
+		m.recordContainerEvent(container, InstanceEventNormal, "Restarted", "Restarted the container, which had stopped while the manager was down")
 
-		m.logger.Info("Successfully restarted container",
+		m.logger.InfoContext(ctx, "Successfully restarted container",
 			slog.String("container", container.Name),
 			slog.String("service", container.ServiceName))
 	}
@@ -1303,11 +2660,8 @@ func (m *Manager) autoRestartContainers(ctx context.Context) error {
 
 // shouldContainerBeRunning determines if a container should be running based on its metadata
 func (m *Manager) shouldContainerBeRunning(container *models.Container) bool {
-	// For now, assume all discovered containers should be running
-	// In a more sophisticated system, this could check database state,
-	// environment variables, or other metadata to determine desired state
-	_ = container // Parameter may be used in future implementations
-	return true
+	// Containers stopped by a user stay stopped until they are explicitly started
+	return container.StoppedAt == nil
 }
 
 // getRealTimeContainerStatus gets the real-time status from Podman
@@ -1316,22 +2670,20 @@ func (m *Manager) getRealTimeContainerStatus(ctx context.Context, container *mod
 		return models.StatusError
 	}
 
-	cmd := exec.CommandContext(ctx, "podman", "inspect", container.ID, "--format", "{{.State.Status}}")
-	output, err := cmd.CombinedOutput()
+	entry, err := m.inspect.get(ctx, container.ID)
 	if err != nil {
-		m.logger.Debug("Failed to get real-time container status",
+		m.logger.DebugContext(ctx, "Failed to get real-time container status",
 			slog.String("container", container.Name),
 			slog.String("error", err.Error()))
 		return models.StatusError
 	}
 
-	podmanStatus := strings.TrimSpace(string(output))
-	return m.mapPodmanStatus(podmanStatus)
+	return m.mapPodmanStatus(entry.state)
 }
 
 // restartContainer restarts a stopped container
 func (m *Manager) restartContainer(ctx context.Context, container *models.Container) error {
-	m.logger.Info("Restarting container",
+	m.logger.InfoContext(ctx, "Restarting container",
 		slog.String("container", container.Name),
 		slog.String("service", container.ServiceName))
 
@@ -1339,92 +2691,132 @@ func (m *Manager) restartContainer(ctx context.Context, container *models.Contai
 	container.Status = models.StatusStarting
 	container.UpdatedAt = time.Now()
 
+	// Dependencies come up first so the container finds them when it starts
+	if err := m.startDependencies(ctx, container); err != nil {
+		container.Status = models.StatusError
+		return err
+	}
+
 	// Start the container
-	cmd := exec.CommandContext(ctx, "podman", "start", container.ID)
-	output, err := cmd.CombinedOutput()
+	output, err := m.startProcess(ctx, container)
+	m.inspect.invalidate(container.ID)
 	if err != nil {
 		container.Status = models.StatusError
 		return fmt.Errorf("failed to start container: %w, output: %s", err, string(output))
 	}
+	return m.finishStart(ctx, container)
+}
 
+// finishStart waits for a started container to run, refreshes its route and publishes that it is
+// running again
+func (m *Manager) finishStart(ctx context.Context, container *models.Container) error {
 	// Wait for container to be running
 	if err := m.waitForContainer(ctx, container.ID); err != nil {
 		container.Status = models.StatusError
 		return fmt.Errorf("container failed to start properly: %w", err)
 	}
+	m.completeStartUnsafe(ctx, container)
+	return nil
+}
 
-	// Get container IP for Traefik routing (in case it changed)
-	containerIP, err := m.getContainerIP(ctx, container.ID)
-	if err != nil {
-		m.logger.Error("Failed to get container IP after restart",
-			slog.String("container", container.Name),
-			slog.String("error", err.Error()))
-		// Continue - container is started but routing may not work
-		containerIP = "127.0.0.1" // fallback
-	}
-
-	// Update/refresh Traefik route for the container
-	if container.Slug != "" {
-		if err := m.traefikManager.AddMCPService(ctx, container.Slug, containerIP, container.Port); err != nil {
-			m.logger.Error("Failed to update Traefik route after restart",
+// completeStartUnsafe refreshes the route of a container whose process runs again and publishes
+// that it is running. Caller must hold m.mutex.
+func (m *Manager) completeStartUnsafe(ctx context.Context, container *models.Container) {
+	// The new process is routed once it answers again when routes wait for readiness
+	container.Ready = false
+	if container.Slug != "" && m.routesAwaitReadiness() {
+		if err := m.traefikManager.RemoveMCPService(ctx, container.Slug); err != nil {
+			m.logger.WarnContext(ctx, "Failed to remove Traefik route until restarted container is ready",
+				slog.String("slug", container.Slug),
+				slog.String("service", container.ServiceName),
+				slog.String("error", err.Error()))
+		}
+	} else if container.Slug != "" {
+		// Refresh the Traefik route in case the restart assigned a new IP
+		if _, err := m.refreshRoute(ctx, container); err != nil {
+			m.logger.ErrorContext(ctx, "Failed to update Traefik route after restart",
 				slog.String("slug", container.Slug),
 				slog.String("service", container.ServiceName),
 				slog.String("error", err.Error()))
-			// Continue - container is running but routing may not work
+			// Continue - container is running and the route reconciler will retry
 		}
 	}
 
-	// Update final status
-	container.Status = models.StatusRunning
+	// Update final status; with a startup probe the health monitor marks it running once it answers
+	starting := m.beginStartupUnsafe(container)
+	if !starting {
+		container.Status = models.StatusRunning
+	}
 	container.UpdatedAt = time.Now()
 
 	// Publish running status if we have instance ID
-	if instanceID, exists := container.Environment["MCP_INSTANCE_ID"]; exists {
+	if instanceID, exists := container.Environment["MCP_INSTANCE_ID"]; exists && !starting {
 		if err := m.eventPublisher.PublishRunning(ctx, instanceID, container.ServiceName, container.ID, container.URL); err != nil {
-			m.logger.Warn("Failed to publish running status after restart",
+			m.logger.WarnContext(ctx, "Failed to publish running status after restart",
 				slog.String("instance_id", instanceID),
 				slog.String("error", err.Error()))
 		}
 	}
+}
 
-	return nil
+// fetchCoreAPIInstances makes a single request for the Core API's MCP instances
+func fetchCoreAPIInstances(ctx context.Context, client *http.Client, url string) ([]models.MCPServerInstance, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, retry.Permanent(fmt.Errorf("failed to create instances request: %w", err))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch MCP instances: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		err := fmt.Errorf("Core API returned status %d", resp.StatusCode)
+		if !retry.RetryableStatus(resp.StatusCode) {
+			return nil, retry.Permanent(err)
+		}
+		return nil, err
+	}
+
+	var instances []models.MCPServerInstance
+	if err := json.NewDecoder(resp.Body).Decode(&instances); err != nil {
+		return nil, retry.Permanent(fmt.Errorf("failed to decode instances response: %w", err))
+	}
+	return instances, nil
 }
 
 // syncWithCoreAPI synchronizes with the Core API to handle pending instances
 func (m *Manager) syncWithCoreAPI(ctx context.Context) error {
-	m.logger.Info("Starting synchronization with Core API")
+	m.logger.InfoContext(ctx, "Starting synchronization with Core API")
 
 	// Get all MCP instances from Core API
 	url := fmt.Sprintf("%s/v1/mcp-server-instances/", m.config.CoreAPIURL)
-	m.logger.Info("Fetching MCP instances from Core API", slog.String("url", url))
+	m.logger.InfoContext(ctx, "Fetching MCP instances from Core API", slog.String("url", url))
 
 	// Create HTTP client with timeout
 	client := &http.Client{
 		Timeout: 10 * time.Second,
 	}
 
-	resp, err := client.Get(url)
-	if err != nil {
-		return fmt.Errorf("failed to fetch MCP instances: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("Core API returned status %d", resp.StatusCode)
-	}
-
+	// The Core API may still be starting alongside the manager, so a failed fetch is retried
 	var instances []models.MCPServerInstance
-	if err := json.NewDecoder(resp.Body).Decode(&instances); err != nil {
-		return fmt.Errorf("failed to decode instances response: %w", err)
+	err := retry.For(retry.CoreAPI).Do(ctx, func(ctx context.Context) error {
+		var err error
+		instances, err = fetchCoreAPIInstances(ctx, client, url)
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
-	m.logger.Info("Fetched MCP instances from Core API",
+	m.logger.InfoContext(ctx, "Fetched MCP instances from Core API",
 		slog.Int("total_instances", len(instances)))
 
 	// Process each instance
 	pendingCount := 0
 	for _, instance := range instances {
-		m.logger.Info("Processing instance",
+		m.logger.InfoContext(ctx, "Processing instance",
 			slog.String("instance_id", instance.InstanceID),
 			slog.String("name", instance.Name),
 			slog.String("status", instance.Status))
@@ -1433,7 +2825,7 @@ func (m *Manager) syncWithCoreAPI(ctx context.Context) error {
 		if instance.Status == "pending" || instance.Status == "starting" {
 			// Check if container already exists
 			if _, exists := m.containers[instance.Name]; !exists {
-				m.logger.Info("Creating missing container for pending instance",
+				m.logger.InfoContext(ctx, "Creating missing container for pending instance",
 					slog.String("instance_id", instance.InstanceID),
 					slog.String("name", instance.Name))
 
@@ -1443,7 +2835,7 @@ func (m *Manager) syncWithCoreAPI(ctx context.Context) error {
 				port := int(portFloat)
 
 				if !imageOk || !portOk {
-					m.logger.Error("Invalid JSON spec for instance",
+					m.logger.ErrorContext(ctx, "Invalid JSON spec for instance",
 						slog.String("instance_id", instance.InstanceID),
 						slog.String("error", "missing image or port"))
 					continue
@@ -1472,12 +2864,12 @@ func (m *Manager) syncWithCoreAPI(ctx context.Context) error {
 
 				// Create container
 				if _, err := m.CreateContainer(ctx, req); err != nil {
-					m.logger.Error("Failed to create container for pending instance",
+					m.logger.ErrorContext(ctx, "Failed to create container for pending instance",
 						slog.String("instance_id", instance.InstanceID),
 						slog.String("name", instance.Name),
 						slog.String("error", err.Error()))
 				} else {
-					m.logger.Info("Successfully created container for pending instance",
+					m.logger.InfoContext(ctx, "Successfully created container for pending instance",
 						slog.String("instance_id", instance.InstanceID),
 						slog.String("name", instance.Name))
 				}
@@ -1486,7 +2878,7 @@ func (m *Manager) syncWithCoreAPI(ctx context.Context) error {
 		}
 	}
 
-	m.logger.Info("Core API synchronization completed",
+	m.logger.InfoContext(ctx, "Core API synchronization completed",
 		slog.Int("total_instances", len(instances)),
 		slog.Int("pending_processed", pendingCount))
 
@@ -9,7 +9,7 @@ import (
 	"os"
 
 	"github.com/agentarea/mcp-manager/internal/config"
-	"github.com/agentarea/mcp-manager/internal/models"
+	"github.com/agentarea/mcp-manager/pkg/models"
 )
 
 func TestNewManager(t *testing.T) {
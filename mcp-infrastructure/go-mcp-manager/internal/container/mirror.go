@@ -0,0 +1,239 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/registry"
+	"github.com/agentarea/mcp-manager/internal/retry"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// mirrorLabel marks the registry container provisioned as the pull-through cache
+const mirrorLabel = "mcp.registry_mirror"
+
+// mirrorState counts how image pulls used the registry mirror
+type mirrorState struct {
+	pulls     atomic.Int64
+	fallbacks atomic.Int64
+	bypassed  atomic.Int64
+}
+
+// mirrorHost returns the registry host images are pulled through, or "" without a mirror
+func (m *Manager) mirrorHost() string {
+	cfg := m.config.RegistryMirror
+	if cfg.Host != "" {
+		return cfg.Host
+	}
+	if cfg.Provision {
+		return fmt.Sprintf("127.0.0.1:%d", cfg.Port)
+	}
+	return ""
+}
+
+// mirrorInsecure reports whether the mirror is reached over plain HTTP, as the provisioned one is
+func (m *Manager) mirrorInsecure() bool {
+	cfg := m.config.RegistryMirror
+	return cfg.Insecure || (cfg.Host == "" && cfg.Provision)
+}
+
+// mirrorMetricsURL returns where the mirror's expvar counters are served, or "" when unknown
+func (m *Manager) mirrorMetricsURL() string {
+	cfg := m.config.RegistryMirror
+	if cfg.MetricsURL != "" {
+		return cfg.MetricsURL
+	}
+	if cfg.Host == "" && cfg.Provision {
+		return fmt.Sprintf("http://127.0.0.1:%d/debug/vars", cfg.Port+1)
+	}
+	return ""
+}
+
+// mirrorContainerName names the provisioned mirror after the manager owning it
+func (m *Manager) mirrorContainerName() string {
+	return m.config.Container.ManagedByLabel + "-registry-mirror"
+}
+
+// mirroredImage returns the reference that pulls image through the mirror, or "" when the mirror
+// does not serve it
+func (m *Manager) mirroredImage(image string) string {
+	host := m.mirrorHost()
+	if host == "" {
+		return ""
+	}
+	ref, err := registry.ParseReference(image)
+	if err != nil || ref.Registry != m.config.RegistryMirror.Upstream {
+		return ""
+	}
+	ref.Registry = host
+	return ref.String()
+}
+
+// mirrorRemoteURL returns the distribution API URL of upstream a provisioned mirror proxies
+func mirrorRemoteURL(upstream string) string {
+	if upstream == registry.DefaultRegistry {
+		return "https://registry-1.docker.io"
+	}
+	return "https://" + upstream
+}
+
+// ensureRegistryMirror runs the provisioned mirror, creating it on first use. Its cache is kept
+// in a volume, so it survives the container being recreated.
+func (m *Manager) ensureRegistryMirror(ctx context.Context) error {
+	cfg := m.config.RegistryMirror
+	if !cfg.Provision {
+		return nil
+	}
+	name := m.mirrorContainerName()
+	if err := podmanCommand(ctx, m.logger, "container", "exists", name).Run(); err == nil {
+		if output, err := runPodman(ctx, m.logger, "start", name); err != nil {
+			return fmt.Errorf("failed to start registry mirror: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+
+	args := []string{"run", "-d", "--name", name, "--restart", "always",
+		"--label", managedByLabel + "=" + m.config.Container.ManagedByLabel,
+		"--label", mirrorLabel + "=true",
+		"-p", fmt.Sprintf("127.0.0.1:%d:5000", cfg.Port),
+		"-p", fmt.Sprintf("127.0.0.1:%d:5001", cfg.Port+1),
+		"-v", name + ":/var/lib/registry",
+		"-e", "REGISTRY_PROXY_REMOTEURL=" + mirrorRemoteURL(cfg.Upstream),
+		"-e", "REGISTRY_HTTP_DEBUG_ADDR=:5001",
+		cfg.Image,
+	}
+	if output, err := runPodman(ctx, m.logger, args...); err != nil {
+		return fmt.Errorf("failed to run registry mirror: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	m.logger.InfoContext(ctx, "Provisioned registry mirror",
+		slog.String("container", name),
+		slog.String("upstream", cfg.Upstream),
+		slog.Int("port", cfg.Port))
+	return nil
+}
+
+// pullThroughMirror pulls image from the mirror and tags it with its own name, so containers
+// run it as if it came from the upstream. It reports false when the mirror does not serve the
+// image or the pull failed, leaving the caller to pull from the upstream.
+func (m *Manager) pullThroughMirror(ctx context.Context, image, platform string, progressCallback func(string)) bool {
+	mirrored := m.mirroredImage(image)
+	if mirrored == "" {
+		if m.mirrorHost() != "" {
+			m.mirror.bypassed.Add(1)
+		}
+		return false
+	}
+
+	args := pullArgs(mirrored, platform)
+	if m.mirrorInsecure() {
+		args = slices.Insert(args, 1, "--tls-verify=false")
+	}
+	err := retry.For(retry.Podman).Do(ctx, func(ctx context.Context) error {
+		return m.validator.pullImage(ctx, args, progressCallback)
+	})
+	if err == nil {
+		var output []byte
+		if output, err = runPodman(ctx, m.logger, "tag", mirrored, image); err != nil {
+			err = fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+		}
+	}
+	if err != nil {
+		m.mirror.fallbacks.Add(1)
+		m.logger.WarnContext(ctx, "Failed to pull image through registry mirror, pulling from upstream",
+			slog.String("image", image),
+			slog.String("mirror", m.mirrorHost()),
+			slog.String("error", err.Error()))
+		return false
+	}
+	m.mirror.pulls.Add(1)
+	return true
+}
+
+// mirrorProxyCounters is how the registry's proxy exports its counters through expvar
+type mirrorProxyCounters struct {
+	Requests    uint64 `json:"Requests"`
+	Hits        uint64 `json:"Hits"`
+	Misses      uint64 `json:"Misses"`
+	BytesPulled uint64 `json:"BytesPulled"`
+}
+
+// mirrorExpvars is the part of the registry's /debug/vars holding its proxy counters
+type mirrorExpvars struct {
+	Registry struct {
+		Proxy struct {
+			Blobs     *mirrorProxyCounters `json:"blobs"`
+			Manifests *mirrorProxyCounters `json:"manifests"`
+		} `json:"proxy"`
+	} `json:"registry"`
+}
+
+// RegistryCacheStatus reports the mirror in use, how pulls used it and, when its metrics can be
+// read, how many of its requests were served from the cache
+func (m *Manager) RegistryCacheStatus(ctx context.Context) *models.RegistryCacheStatus {
+	status := &models.RegistryCacheStatus{
+		Mirror:      m.mirrorHost(),
+		Provisioned: m.config.RegistryMirror.Provision && m.config.RegistryMirror.Host == "",
+		Pulls:       m.mirror.pulls.Load(),
+		Fallbacks:   m.mirror.fallbacks.Load(),
+		Bypassed:    m.mirror.bypassed.Load(),
+	}
+	status.Enabled = status.Mirror != ""
+	if !status.Enabled {
+		return status
+	}
+	status.Upstream = m.config.RegistryMirror.Upstream
+
+	metricsURL := m.mirrorMetricsURL()
+	if metricsURL == "" {
+		return status
+	}
+	vars, err := fetchMirrorExpvars(ctx, metricsURL)
+	if err != nil {
+		status.MetricsError = err.Error()
+		return status
+	}
+	status.Manifests = vars.Registry.Proxy.Manifests.counters()
+	status.Blobs = vars.Registry.Proxy.Blobs.counters()
+	if status.Blobs != nil && status.Blobs.Requests > 0 {
+		status.HitRatio = float64(status.Blobs.Hits) / float64(status.Blobs.Requests)
+	}
+	return status
+}
+
+// counters converts the registry's counters, which are absent until the proxy served a request
+func (c *mirrorProxyCounters) counters() *models.RegistryCacheCounters {
+	if c == nil {
+		return nil
+	}
+	return &models.RegistryCacheCounters{Requests: c.Requests, Hits: c.Hits, Misses: c.Misses, BytesPulled: c.BytesPulled}
+}
+
+// fetchMirrorExpvars reads the mirror's expvar counters from url
+func fetchMirrorExpvars(ctx context.Context, url string) (*mirrorExpvars, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry mirror metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry mirror metrics returned %s", resp.Status)
+	}
+	var vars mirrorExpvars
+	if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+		return nil, fmt.Errorf("failed to parse registry mirror metrics: %w", err)
+	}
+	return &vars, nil
+}
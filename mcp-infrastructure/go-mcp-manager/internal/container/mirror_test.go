@@ -0,0 +1,42 @@
+package container
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+)
+
+func TestRegistryMirror(t *testing.T) {
+	metrics := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"cmdline":[],"registry":{"proxy":{"blobs":{"Requests":8,"Hits":6,"Misses":2,"BytesPulled":1024},"manifests":{"Requests":4,"Hits":1,"Misses":3}}}}`))
+	}))
+	defer metrics.Close()
+
+	cfg := &config.Config{
+		Container:      config.ContainerConfig{NamePrefix: "test-", MaxContainers: 10},
+		State:          config.StateConfig{Dir: t.TempDir()},
+		RegistryMirror: config.RegistryMirrorConfig{Upstream: "docker.io", Provision: true, Port: 5000, MetricsURL: metrics.URL},
+	}
+	manager := newTestManager(cfg)
+
+	if mirrored := manager.mirroredImage("busybox:1.36"); mirrored != "127.0.0.1:5000/library/busybox:1.36" {
+		t.Errorf("Expected Docker Hub images to be pulled through the provisioned mirror, got %q", mirrored)
+	}
+	if mirrored := manager.mirroredImage("ghcr.io/org/server:1"); mirrored != "" {
+		t.Errorf("Expected images of other registries to bypass the mirror, got %q", mirrored)
+	}
+	if !manager.mirrorInsecure() {
+		t.Error("Expected the provisioned mirror to be reached over plain HTTP")
+	}
+
+	status := manager.RegistryCacheStatus(context.Background())
+	if !status.Enabled || !status.Provisioned || status.Upstream != "docker.io" {
+		t.Errorf("Expected the provisioned mirror to be reported, got %+v", status)
+	}
+	if status.Blobs == nil || status.Blobs.Hits != 6 || status.Manifests == nil || status.Manifests.Misses != 3 || status.HitRatio != 0.75 {
+		t.Errorf("Expected the mirror's proxy counters with a 0.75 blob hit ratio, got %+v", status)
+	}
+}
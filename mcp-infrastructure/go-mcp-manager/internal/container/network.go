@@ -0,0 +1,162 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// workspaceLabel stores the owning workspace on the podman container
+const workspaceLabel = "mcp.workspace"
+
+// maxNetworkNameLength keeps generated network names well under podman's interface name limits
+const maxNetworkNameLength = 63
+
+var (
+	// workspaceIDPattern limits workspace IDs to characters safe for labels and network names
+	workspaceIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+	// networkNameInvalidChars matches characters podman does not allow in network names
+	networkNameInvalidChars = regexp.MustCompile(`[^a-z0-9_.-]+`)
+)
+
+// parseNetworkSpec reads the optional workspace_id and shared_network fields from json_spec
+func parseNetworkSpec(jsonSpec map[string]interface{}) (string, bool, error) {
+	workspaceID := ""
+	if raw, exists := jsonSpec["workspace_id"]; exists && raw != nil {
+		value, ok := raw.(string)
+		if !ok || (value != "" && !workspaceIDPattern.MatchString(value)) {
+			return "", false, fmt.Errorf("workspace_id must be a string of letters, digits, '.', '_' or '-'")
+		}
+		workspaceID = value
+	}
+
+	shared := false
+	if raw, exists := jsonSpec["shared_network"]; exists && raw != nil {
+		value, ok := raw.(bool)
+		if !ok {
+			return "", false, fmt.Errorf("shared_network must be a boolean")
+		}
+		shared = value
+	}
+
+	return workspaceID, shared, nil
+}
+
+// workspaceNetworkName returns the podman network a workspace's containers are attached to
+func (m *Manager) workspaceNetworkName(workspaceID string) string {
+	if !m.config.Network.PerWorkspace || workspaceID == "" {
+		return m.config.Traefik.Network
+	}
+
+	name := m.config.Network.Prefix + networkNameInvalidChars.ReplaceAllString(strings.ToLower(workspaceID), "-")
+	if len(name) > maxNetworkNameLength {
+		name = name[:maxNetworkNameLength]
+	}
+	return name
+}
+
+// isWorkspaceNetwork reports whether a network is one the manager created for a workspace
+func (m *Manager) isWorkspaceNetwork(network string) bool {
+	return m.config.Network.PerWorkspace && network != m.config.Traefik.Network &&
+		strings.HasPrefix(network, m.config.Network.Prefix)
+}
+
+// proxyContainerName returns the container Traefik runs in, which must join every workspace network
+func (m *Manager) proxyContainerName() string {
+	if m.config.Network.ProxyContainer != "" {
+		return m.config.Network.ProxyContainer
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return hostname
+}
+
+// ensureNetwork creates a workspace network if needed and connects the proxy to it
+func (m *Manager) ensureNetwork(ctx context.Context, network string) error {
+	if !m.isWorkspaceNetwork(network) {
+		return nil
+	}
+
+	if err := podmanCommand(ctx, m.logger, "network", "exists", network).Run(); err != nil {
+		args := []string{"network", "create", "--label", fmt.Sprintf("%s=%s", workspaceLabel, network)}
+		if m.config.Network.Isolate {
+			// Keep tenants apart: traffic between isolated networks is dropped
+			args = append(args, "--opt", "isolate=true")
+		}
+		args = append(args, network)
+
+		if output, err := podmanCommand(ctx, m.logger, args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to create network %s: %w: %s", network, err, strings.TrimSpace(string(output)))
+		}
+		m.logger.InfoContext(ctx, "Created workspace network", slog.String("network", network))
+	}
+
+	proxy := m.proxyContainerName()
+	if proxy == "" {
+		return fmt.Errorf("cannot determine the proxy container to attach to network %s", network)
+	}
+	output, err := podmanCommand(ctx, m.logger, "network", "connect", network, proxy).CombinedOutput()
+	if err != nil && !strings.Contains(string(output), "already") {
+		return fmt.Errorf("failed to connect proxy %s to network %s: %w: %s", proxy, network, err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// releaseNetworkUnsafe removes a workspace network once no tracked container uses it (caller must hold lock)
+func (m *Manager) releaseNetworkUnsafe(ctx context.Context, network string) {
+	if !m.isWorkspaceNetwork(network) {
+		return
+	}
+	for _, container := range m.containers {
+		if container.Network == network {
+			return
+		}
+	}
+
+	if proxy := m.proxyContainerName(); proxy != "" {
+		if output, err := podmanCommand(ctx, m.logger, "network", "disconnect", network, proxy).CombinedOutput(); err != nil {
+			m.logger.DebugContext(ctx, "Failed to disconnect proxy from workspace network",
+				slog.String("network", network),
+				slog.String("error", err.Error()),
+				slog.String("output", string(output)))
+		}
+	}
+
+	// Archived containers stay attached to the network, in which case removal fails and it is kept
+	if output, err := podmanCommand(ctx, m.logger, "network", "rm", network).CombinedOutput(); err != nil {
+		m.logger.WarnContext(ctx, "Workspace network not removed",
+			slog.String("network", network),
+			slog.String("error", err.Error()),
+			slog.String("output", string(output)))
+		return
+	}
+
+	m.logger.InfoContext(ctx, "Removed workspace network", slog.String("network", network))
+}
+
+// podmanNetworkArgs returns the network flags for a container's podman run
+func (m *Manager) podmanNetworkArgs(container *models.Container) []string {
+	network := container.Network
+	if network == "" {
+		network = m.config.Traefik.Network
+	}
+
+	args := []string{"--network", network}
+	if container.SharedNetwork && network != m.config.Traefik.Network {
+		args = append(args, "--network", m.config.Traefik.Network)
+	}
+	return args
+}
+
+// routableNetwork reports whether Traefik can reach containers on a network
+func (m *Manager) routableNetwork(network string) bool {
+	return network == m.config.Traefik.Network || m.isWorkspaceNetwork(network)
+}
@@ -0,0 +1,42 @@
+package container
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestWorkspaceNetworks(t *testing.T) {
+	cfg := &config.Config{
+		Traefik: config.TraefikConfig{Network: "mcp-network"},
+		Network: config.NetworkConfig{PerWorkspace: true, Prefix: "mcp-ws-"},
+	}
+	manager := newTestManager(cfg)
+
+	workspaceID, shared, err := parseNetworkSpec(map[string]interface{}{"workspace_id": "Team_A", "shared_network": true})
+	if err != nil {
+		t.Fatalf("Failed to parse network settings: %v", err)
+	}
+
+	network := manager.workspaceNetworkName(workspaceID)
+	if network != "mcp-ws-team_a" {
+		t.Errorf("Expected network mcp-ws-team_a, got %s", network)
+	}
+	if manager.workspaceNetworkName("") != "mcp-network" {
+		t.Errorf("Expected containers without a workspace to use the shared network")
+	}
+	if !manager.routableNetwork(network) || manager.routableNetwork("bridge") {
+		t.Errorf("Expected only the shared and workspace networks to be routable")
+	}
+
+	args := strings.Join(manager.podmanNetworkArgs(&models.Container{Network: network, SharedNetwork: shared}), " ")
+	if args != "--network mcp-ws-team_a --network mcp-network" {
+		t.Errorf("Expected workspace and shared networks, got %q", args)
+	}
+
+	if _, _, err := parseNetworkSpec(map[string]interface{}{"workspace_id": "../other"}); err == nil {
+		t.Errorf("Expected invalid workspace_id to be rejected")
+	}
+}
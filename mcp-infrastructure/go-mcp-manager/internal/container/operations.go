@@ -0,0 +1,350 @@
+package container
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// operationsBucket journals creates and deletes until they finish, so a restart can clean up
+	// after a manager that died midway
+	operationsBucket = "pending_operations"
+	// rollbackTimeout bounds the cleanup of a create aborted by shutdown
+	rollbackTimeout = 30 * time.Second
+
+	operationCreate = "create"
+	operationDelete = "delete"
+)
+
+// Steps of an operation, journaled before each starts. A create runs them in this order; a
+// delete journals stepRemove once it knows the container's slug.
+const (
+	stepValidate  = "validate"
+	stepPull      = "pull"
+	stepProvision = "provision"
+	stepRun       = "run"
+	stepRoute     = "route"
+	stepStatus    = "status"
+	stepRemove    = "remove"
+)
+
+// ErrShuttingDown is returned when a create or delete arrives after shutdown has begun
+var ErrShuttingDown = errors.New("manager is shutting down, not accepting new operations")
+
+// pendingOperation is the journal record of an in-flight create or delete
+type pendingOperation struct {
+	ID            string `json:"id"`
+	Type          string `json:"type"`
+	ServiceName   string `json:"service_name"`
+	InstanceID    string `json:"instance_id,omitempty"`
+	ContainerName string `json:"container_name"`
+	// Step is the step that was about to run when the entry was last written
+	Step string `json:"step"`
+	// Slug is recorded once a create has passed its checks and starts provisioning; a create
+	// without one left nothing behind
+	Slug        string    `json:"slug,omitempty"`
+	ContainerID string    `json:"container_id,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// operation is an in-flight create or delete
+type operation struct {
+	record  pendingOperation
+	cancel  context.CancelFunc
+	aborted atomic.Bool
+}
+
+// operationTracker counts in-flight operations so shutdown can wait for them
+type operationTracker struct {
+	mutex    sync.Mutex
+	draining bool
+	active   map[string]*operation
+	wg       sync.WaitGroup
+}
+
+// operationKey marks a context as belonging to an operation, so nested creates and deletes, such
+// as replacing a container whose spec changed, are part of it
+type operationKey struct{}
+
+// beginOperation journals an operation and returns the context it must run with, which shutdown
+// cancels if the operation outlives SHUTDOWN_DRAIN_TIMEOUT. A nil operation means ctx already
+// belongs to one.
+func (m *Manager) beginOperation(ctx context.Context, kind, serviceName, instanceID string) (context.Context, *operation, error) {
+	if ctx.Value(operationKey{}) != nil {
+		return ctx, nil, nil
+	}
+
+	m.operations.mutex.Lock()
+	defer m.operations.mutex.Unlock()
+	if m.operations.draining {
+		return ctx, nil, ErrShuttingDown
+	}
+
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	op := &operation{record: pendingOperation{
+		ID:            "op_" + hex.EncodeToString(id),
+		Type:          kind,
+		ServiceName:   serviceName,
+		InstanceID:    instanceID,
+		ContainerName: m.config.GetContainerName(serviceName),
+		Step:          stepValidate,
+		StartedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}}
+	if kind == operationDelete {
+		op.record.Step = stepRemove
+	}
+	if err := m.store.Put(operationsBucket, op.record.ID, op.record); err != nil {
+		return ctx, nil, fmt.Errorf("failed to journal %s of %s: %w", kind, serviceName, err)
+	}
+
+	ctx, op.cancel = context.WithCancel(context.WithValue(ctx, operationKey{}, op.record.ID))
+	if m.operations.active == nil {
+		m.operations.active = make(map[string]*operation)
+	}
+	m.operations.active[op.record.ID] = op
+	m.operations.wg.Add(1)
+	return ctx, op, nil
+}
+
+// journalStep writes ahead that op is about to run step on container, which may be nil before
+// the container is known, so a crash during the step is replayed or rolled back on restart
+func (m *Manager) journalStep(ctx context.Context, op *operation, step string, container *models.Container) {
+	if op == nil {
+		return
+	}
+	op.record.Step = step
+	op.record.UpdatedAt = time.Now()
+	if container != nil {
+		op.record.Slug = container.Slug
+		op.record.ContainerID = container.ID
+	}
+	if err := m.store.Put(operationsBucket, op.record.ID, op.record); err != nil {
+		m.logger.WarnContext(ctx, "Failed to journal operation",
+			slog.String("operation", op.record.ID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// finishOperation closes an operation. A create that shutdown aborted is rolled back; the journal
+// entry is kept when that fails, or when an aborted delete did not finish, for the next start.
+func (m *Manager) finishOperation(ctx context.Context, op *operation, err error) {
+	if op == nil {
+		return
+	}
+	defer m.operations.wg.Done()
+	op.cancel()
+
+	keep := false
+	if err != nil && op.aborted.Load() {
+		switch op.record.Type {
+		case operationCreate:
+			rollbackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+			if rollbackErr := m.rollbackCreate(rollbackCtx, op.record); rollbackErr != nil {
+				m.logger.ErrorContext(ctx, "Failed to roll back create aborted by shutdown",
+					slog.String("service", op.record.ServiceName),
+					slog.String("error", rollbackErr.Error()))
+				keep = true
+			}
+			cancel()
+		case operationDelete:
+			keep = true
+		}
+	}
+	if !keep {
+		if err := m.store.Delete(operationsBucket, op.record.ID); err != nil {
+			m.logger.WarnContext(ctx, "Failed to remove operation from the journal",
+				slog.String("operation", op.record.ID),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	m.operations.mutex.Lock()
+	delete(m.operations.active, op.record.ID)
+	m.operations.mutex.Unlock()
+}
+
+// rollbackCreate removes what a create left behind: its container and its route
+func (m *Manager) rollbackCreate(ctx context.Context, record pendingOperation) error {
+	if record.Slug == "" {
+		return nil
+	}
+	if output, err := podmanCommand(ctx, m.logger, "rm", "-f", "--ignore", record.ContainerName).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to remove container %s: %w: %s", record.ContainerName, err, string(output))
+	}
+	m.removeUnit(ctx, record.ContainerName)
+	if upstream, _ := m.traefikManager.GetMCPServiceUpstream(record.Slug); upstream != "" {
+		if err := m.traefikManager.RemoveMCPService(ctx, record.Slug); err != nil {
+			return fmt.Errorf("failed to remove route %s: %w", record.Slug, err)
+		}
+	}
+	m.logger.InfoContext(ctx, "Rolled back create aborted by shutdown",
+		slog.String("service", record.ServiceName),
+		slog.String("container", record.ContainerName))
+	return nil
+}
+
+// InFlightOperations returns the number of creates and deletes running
+func (m *Manager) InFlightOperations() int {
+	m.operations.mutex.Lock()
+	defer m.operations.mutex.Unlock()
+	return len(m.operations.active)
+}
+
+// DrainOperations stops new creates and deletes and waits for running ones. Those still running
+// after SHUTDOWN_DRAIN_TIMEOUT are cancelled and rolled back before ctx is done.
+func (m *Manager) DrainOperations(ctx context.Context) {
+	m.operations.mutex.Lock()
+	m.operations.draining = true
+	running := len(m.operations.active)
+	m.operations.mutex.Unlock()
+	if running == 0 {
+		return
+	}
+
+	m.logger.InfoContext(ctx, "Waiting for in-flight operations", slog.Int("operations", running))
+	drainCtx, cancel := context.WithTimeout(ctx, m.config.Container.DrainTimeout)
+	defer cancel()
+	if m.waitOperations(drainCtx) {
+		m.logger.InfoContext(ctx, "In-flight operations finished")
+		return
+	}
+
+	m.operations.mutex.Lock()
+	for _, op := range m.operations.active {
+		m.logger.WarnContext(ctx, "Aborting operation still running at shutdown",
+			slog.String("type", op.record.Type),
+			slog.String("service", op.record.ServiceName))
+		op.aborted.Store(true)
+		op.cancel()
+	}
+	m.operations.mutex.Unlock()
+	if !m.waitOperations(ctx) {
+		m.logger.WarnContext(ctx, "Shutdown timed out before aborted operations were rolled back; they are cleaned up on the next start")
+	}
+}
+
+// waitOperations waits for in-flight operations to finish and reports whether they did before ctx
+func (m *Manager) waitOperations(ctx context.Context) bool {
+	done := make(chan struct{})
+	go func() {
+		m.operations.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// recoverOperations replays or rolls back what the journal shows a previous run left midway.
+// A create that reached its route or status step and whose container runs gets its route and
+// status replayed; one that stopped earlier is rolled back, and the Core API sync recreates the
+// instance if it is still pending. An interrupted delete is completed, including its route.
+func (m *Manager) recoverOperations(ctx context.Context) {
+	records, err := m.store.List(operationsBucket)
+	if err != nil {
+		m.logger.WarnContext(ctx, "Failed to read the operation journal", slog.String("error", err.Error()))
+		return
+	}
+
+	for id, raw := range records {
+		var record pendingOperation
+		if err := json.Unmarshal(raw, &record); err != nil {
+			m.logger.WarnContext(ctx, "Dropping unreadable operation journal entry",
+				slog.String("operation", id),
+				slog.String("error", err.Error()))
+			_ = m.store.Delete(operationsBucket, id)
+			continue
+		}
+
+		if record.Type == operationDelete {
+			err = m.recoverDelete(ctx, record)
+		} else {
+			err = m.recoverCreate(ctx, record)
+		}
+		if err != nil {
+			m.logger.ErrorContext(ctx, "Failed to recover interrupted operation",
+				slog.String("type", record.Type),
+				slog.String("step", record.Step),
+				slog.String("service", record.ServiceName),
+				slog.String("error", err.Error()))
+			continue
+		}
+		_ = m.store.Delete(operationsBucket, id)
+	}
+}
+
+// containerSnapshot returns a copy of the tracked container named serviceName
+func (m *Manager) containerSnapshot(serviceName string) (models.Container, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	container, exists := m.containers[serviceName]
+	if !exists {
+		return models.Container{}, false
+	}
+	return *container, true
+}
+
+// recoverCreate replays the route and status of a create that got its container running, and
+// rolls back any other create that got as far as provisioning
+func (m *Manager) recoverCreate(ctx context.Context, record pendingOperation) error {
+	if record.Slug == "" {
+		return nil
+	}
+	container, exists := m.containerSnapshot(record.ServiceName)
+	replayable := record.Step == stepRoute || record.Step == stepStatus
+	if exists && replayable && container.Status == models.StatusRunning && container.Slug == record.Slug {
+		m.logger.InfoContext(ctx, "Replaying interrupted create",
+			slog.String("service", record.ServiceName),
+			slog.String("step", record.Step))
+		if _, err := m.refreshRoute(ctx, &container); err != nil {
+			return err
+		}
+		if record.InstanceID != "" {
+			return m.eventPublisher.PublishRunning(ctx, record.InstanceID, record.ServiceName, container.ID, container.URL)
+		}
+		return nil
+	}
+
+	m.logger.InfoContext(ctx, "Rolling back interrupted create",
+		slog.String("service", record.ServiceName),
+		slog.String("step", record.Step))
+	if exists {
+		return m.deleteContainer(ctx, record.ServiceName)
+	}
+	return m.rollbackCreate(ctx, record)
+}
+
+// recoverDelete completes an interrupted delete, removing a route its container left behind
+func (m *Manager) recoverDelete(ctx context.Context, record pendingOperation) error {
+	if _, exists := m.containerSnapshot(record.ServiceName); exists {
+		m.logger.InfoContext(ctx, "Completing interrupted delete", slog.String("service", record.ServiceName))
+		return m.deleteContainer(ctx, record.ServiceName)
+	}
+	if record.Slug != "" && m.routeExists(record.Slug) {
+		m.logger.InfoContext(ctx, "Removing route left by an interrupted delete", slog.String("slug", record.Slug))
+		return m.traefikManager.RemoveMCPService(ctx, record.Slug)
+	}
+	return nil
+}
+
+// routeExists reports whether the proxy has a route for slug
+func (m *Manager) routeExists(slug string) bool {
+	upstream, err := m.traefikManager.GetMCPServiceUpstream(slug)
+	return err == nil && upstream != ""
+}
@@ -0,0 +1,102 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestDrainOperations(t *testing.T) {
+	// Without podman the rollback of the aborted create fails, so its journal entry is kept
+	t.Setenv("PATH", t.TempDir())
+	stateDir := t.TempDir()
+	cfg := &config.Config{
+		State:     config.StateConfig{Dir: stateDir},
+		Container: config.ContainerConfig{NamePrefix: "mcp-", DrainTimeout: 50 * time.Millisecond},
+	}
+	manager := newTestManager(cfg)
+	ctx := context.Background()
+
+	quickCtx, quick, err := manager.beginOperation(ctx, operationCreate, "quick", "")
+	if err != nil {
+		t.Fatalf("Expected the operation to start, got %v", err)
+	}
+	stuckCtx, stuck, _ := manager.beginOperation(ctx, operationCreate, "stuck", "inst-1")
+	manager.journalStep(stuckCtx, stuck, stepRun, &models.Container{Name: "mcp-stuck", Slug: "stuck-abc123"})
+	if nestedCtx, nested, _ := manager.beginOperation(stuckCtx, operationDelete, "stuck", ""); nested != nil || nestedCtx != stuckCtx {
+		t.Error("Expected a nested operation to be part of its parent")
+	}
+	if manager.InFlightOperations() != 2 {
+		t.Errorf("Expected 2 in-flight operations, got %d", manager.InFlightOperations())
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		manager.finishOperation(quickCtx, quick, nil)
+	}()
+	go func() {
+		<-stuckCtx.Done()
+		manager.finishOperation(stuckCtx, stuck, stuckCtx.Err())
+	}()
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	manager.DrainOperations(shutdownCtx)
+
+	if manager.InFlightOperations() != 0 {
+		t.Errorf("Expected no in-flight operations after draining, got %d", manager.InFlightOperations())
+	}
+	if _, _, err := manager.beginOperation(ctx, operationCreate, "late", ""); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Expected ErrShuttingDown after draining, got %v", err)
+	}
+	keys, _ := manager.store.Keys(operationsBucket)
+	if len(keys) != 1 {
+		t.Fatalf("Expected the aborted create to stay journaled, got %v", keys)
+	}
+	var record pendingOperation
+	manager.store.Get(operationsBucket, keys[0], &record)
+	if record.ServiceName != "stuck" || record.Slug != "stuck-abc123" || record.ContainerName != "mcp-stuck" {
+		t.Errorf("Expected the journal to name the stuck create's container and slug, got %+v", record)
+	}
+}
+
+func TestRecoverOperations(t *testing.T) {
+	// Without podman, steps that need the runtime fail and their entries stay for the next start
+	t.Setenv("PATH", t.TempDir())
+	cfg := &config.Config{
+		State:     config.StateConfig{Dir: t.TempDir()},
+		Container: config.ContainerConfig{NamePrefix: "mcp-"},
+		Traefik:   config.TraefikConfig{ManagerServiceURL: "http://localhost:8000"},
+	}
+	manager := newTestManager(cfg)
+	manager.traefikManager.configPath = filepath.Join(t.TempDir(), "dynamic.yml")
+	ctx := context.Background()
+
+	if err := manager.traefikManager.AddMCPService(ctx, "deleted-ab12", "10.88.0.5", 3000, nil, nil, nil); err != nil {
+		t.Fatalf("Failed to add route: %v", err)
+	}
+	journal := map[string]pendingOperation{
+		"op_validate": {Type: operationCreate, ServiceName: "validating", Step: stepValidate},
+		"op_delete":   {Type: operationDelete, ServiceName: "deleted", Step: stepRemove, Slug: "deleted-ab12"},
+		"op_run":      {Type: operationCreate, ServiceName: "running", ContainerName: "mcp-running", Step: stepRun, Slug: "running-cd34"},
+	}
+	for id, record := range journal {
+		record.ID = id
+		manager.store.Put(operationsBucket, id, record)
+	}
+
+	manager.recoverOperations(ctx)
+
+	if manager.routeExists("deleted-ab12") {
+		t.Error("Expected the route left by the interrupted delete to be removed")
+	}
+	keys, _ := manager.store.Keys(operationsBucket)
+	if len(keys) != 1 || keys[0] != "op_run" {
+		t.Errorf("Expected only the create whose rollback needs podman to stay journaled, got %v", keys)
+	}
+}
@@ -0,0 +1,94 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// managedByLabel names the manager that owns a container; its value is CONTAINER_MANAGED_BY_LABEL
+	managedByLabel = "mcp.managed_by"
+	// instanceIDLabel records the Core API instance a container serves
+	instanceIDLabel = "mcp.instance_id"
+)
+
+// ErrNotOwned is returned for containers another manager or a user created under a name this manager uses
+var ErrNotOwned = errors.New("container is not managed by this manager")
+
+// ownership classifies a podman container found on the host
+type ownership int
+
+const (
+	// ownedByOther containers are left alone
+	ownedByOther ownership = iota
+	// ownedBySelf containers carry this manager's managed-by label
+	ownedBySelf
+	// ownedLegacy containers predate the managed-by label and are recognized by their name prefix
+	ownedLegacy
+	// ownedAdopted containers were started outside the manager and adopted into it
+	ownedAdopted
+)
+
+// ownershipLabelArgs returns the --label arguments marking a container, sidecar or init run as owned
+func (m *Manager) ownershipLabelArgs(container *models.Container) []string {
+	args := []string{"--label", fmt.Sprintf("%s=%s", managedByLabel, m.config.Container.ManagedByLabel)}
+	if instanceID := container.Environment["MCP_INSTANCE_ID"]; instanceID != "" {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", instanceIDLabel, instanceID))
+	}
+	return args
+}
+
+// containerOwnership decides from its name and labels whether a container belongs to this manager
+func (m *Manager) containerOwnership(name string, labels map[string]string) ownership {
+	owner, labelled := labels[managedByLabel]
+	switch {
+	case labelled && owner == m.config.Container.ManagedByLabel:
+		return ownedBySelf
+	case !labelled && strings.HasPrefix(name, m.config.Container.NamePrefix):
+		return ownedLegacy
+	case !labelled && m.store.Has(adoptedBucket, name):
+		return ownedAdopted
+	}
+	return ownedByOther
+}
+
+// listingLabels converts the labels of a `podman ps --format json` entry
+func listingLabels(listing map[string]interface{}) map[string]string {
+	raw, _ := listing["Labels"].(map[string]interface{})
+	labels := make(map[string]string, len(raw))
+	for name, value := range raw {
+		if s, ok := value.(string); ok {
+			labels[name] = s
+		}
+	}
+	return labels
+}
+
+// ensureOwned fails with ErrNotOwned when a container named name exists but belongs to someone else,
+// so name-based operations such as replacing a leftover sidecar never touch foreign containers
+func (m *Manager) ensureOwned(ctx context.Context, name string) error {
+	format := fmt.Sprintf("{{.Name}}\n{{index .Config.Labels %q}}", managedByLabel)
+	output, err := podmanCommand(ctx, m.logger, "inspect", "--type", "container", name, "--format", format).Output()
+	if err != nil {
+		// Nothing by that name, so there is nothing to protect
+		return nil
+	}
+
+	actualName, owner, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	labels := make(map[string]string)
+	if owner = strings.TrimSpace(owner); owner != "" && owner != "<no value>" {
+		labels[managedByLabel] = owner
+	}
+	if m.containerOwnership(strings.TrimPrefix(actualName, "/"), labels) == ownedByOther {
+		m.logger.WarnContext(ctx, "Refusing to operate on a container this manager does not own",
+			slog.String("container", name),
+			slog.String("managed_by", owner))
+		return fmt.Errorf("%w: %s", ErrNotOwned, name)
+	}
+	return nil
+}
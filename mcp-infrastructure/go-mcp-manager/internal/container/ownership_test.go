@@ -0,0 +1,36 @@
+package container
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestContainerOwnership(t *testing.T) {
+	cfg := &config.Config{Container: config.ContainerConfig{NamePrefix: "mcp-", ManagedByLabel: "mcp-manager"}}
+	manager := newTestManager(cfg)
+
+	cases := []struct {
+		name     string
+		labels   map[string]string
+		expected ownership
+	}{
+		{"mcp-github", map[string]string{managedByLabel: "mcp-manager"}, ownedBySelf},
+		{"renamed", map[string]string{managedByLabel: "mcp-manager"}, ownedBySelf},
+		{"mcp-github", nil, ownedLegacy},
+		{"mcp-github", map[string]string{managedByLabel: "other-manager"}, ownedByOther},
+		{"postgres", nil, ownedByOther},
+	}
+	for _, c := range cases {
+		if got := manager.containerOwnership(c.name, c.labels); got != c.expected {
+			t.Errorf("Expected ownership %d for %s %v, got %d", c.expected, c.name, c.labels, got)
+		}
+	}
+
+	args := strings.Join(manager.ownershipLabelArgs(&models.Container{Environment: map[string]string{"MCP_INSTANCE_ID": "inst-1"}}), " ")
+	if !strings.Contains(args, "mcp.managed_by=mcp-manager") || !strings.Contains(args, "mcp.instance_id=inst-1") {
+		t.Errorf("Expected managed-by and instance labels, got %s", args)
+	}
+}
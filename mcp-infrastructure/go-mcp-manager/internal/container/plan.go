@@ -0,0 +1,119 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// PlanContainer runs the checks CreateContainer runs and returns the podman invocation, URL and
+// policies it would apply, without pulling, building or starting anything
+func (m *Manager) PlanContainer(ctx context.Context, req models.CreateContainerRequest) (*models.CreatePlan, error) {
+	hash := specHash(req)
+	requested := requestedResources(&req)
+
+	if err := m.applyRuntimeRequest(&req); err != nil {
+		return nil, err
+	}
+	if (req.Image == "") == (req.Source == nil) {
+		return nil, fmt.Errorf("exactly one of image, source and runtime is required")
+	}
+
+	var warnings []string
+	if req.Source != nil {
+		if err := validateSourceConfig(req.Source); err != nil {
+			return nil, err
+		}
+		req.Image = sourceImage(req.ServiceName, req.Source)
+		warnings = append(warnings, fmt.Sprintf("image %s would be built from %s first", req.Image, req.Source.GitURL))
+	}
+	if err := m.checkStoragePressure(ctx); err != nil {
+		warnings = append(warnings, err.Error())
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	container, err := m.prepareContainerUnsafe(req, hash, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	environment := make(map[string]string, len(container.Environment))
+	for key := range container.Environment {
+		environment[key] = "***"
+	}
+
+	return &models.CreatePlan{
+		Backend:     "docker",
+		ServiceName: container.ServiceName,
+		Name:        container.Name,
+		Image:       container.Image,
+		Slug:        container.Slug,
+		URL:         container.URL,
+		Environment: environment,
+		Resources:   container.Resources,
+		Network:     container.Network,
+		GPUDevices:  container.GPUDevices,
+		PodmanArgs:  redactPodmanArgs(m.buildPodmanRunArgs(container)),
+		Policies:    m.appliedPolicies(requested, container),
+		Warnings:    warnings,
+	}, nil
+}
+
+// appliedPolicies describes the manager defaults container gets beyond what was requested
+func (m *Manager) appliedPolicies(requested *models.ResourceLimits, container *models.Container) []string {
+	var policies []string
+
+	if resources := container.Resources; resources != nil {
+		if requested == nil {
+			requested = &models.ResourceLimits{}
+		}
+		var defaults []string
+		if requested.Memory == "" && resources.Memory != "" {
+			defaults = append(defaults, "memory="+resources.Memory)
+		}
+		if requested.CPU == "" && resources.CPU != "" {
+			defaults = append(defaults, "cpu="+resources.CPU)
+		}
+		if requested.PidsLimit == 0 && resources.PidsLimit != 0 {
+			defaults = append(defaults, fmt.Sprintf("pids_limit=%d", resources.PidsLimit))
+		}
+		if len(defaults) > 0 {
+			policies = append(policies, "default resources: "+strings.Join(defaults, ", "))
+		}
+	}
+
+	if policy := m.config.Container.Security; policy.Hardened {
+		security := container.Security
+		if security == nil {
+			security = &models.SecurityConfig{}
+		}
+		policies = append(policies, "hardened: capabilities dropped")
+		if security.ReadOnlyRootFS == nil || *security.ReadOnlyRootFS {
+			policies = append(policies, "hardened: read-only root filesystem")
+		}
+		if !security.AllowPrivilegeEscalation {
+			policies = append(policies, "hardened: no-new-privileges")
+		}
+		if security.User == "" && policy.DefaultUser != "" {
+			policies = append(policies, "default user: "+policy.DefaultUser)
+		}
+		if security.SeccompProfile == "" && policy.SeccompProfile != "" {
+			policies = append(policies, "default seccomp profile: "+policy.SeccompProfile)
+		}
+	}
+
+	if container.Network != m.config.Traefik.Network {
+		policies = append(policies, "workspace network: "+container.Network)
+	}
+	if len(container.GPUDevices) > 0 {
+		policies = append(policies, "gpus assigned: "+strings.Join(container.GPUDevices, ", "))
+	}
+	if container.Runtime != nil {
+		policies = append(policies, fmt.Sprintf("%s package bridged to HTTP by %s", container.Runtime.Type, container.Image))
+	}
+	return policies
+}
@@ -0,0 +1,56 @@
+package container
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestPlanContainer(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Container.MaxContainers = 5
+	cfg.Container.DefaultPidsLimit = 512
+	cfg.Container.Security.Hardened = true
+	cfg.Container.Security.DefaultUser = "1000:1000"
+	cfg.Traefik.ProxyHost = "http://localhost:81"
+	manager := newTestManager(cfg)
+
+	req := models.CreateContainerRequest{
+		ServiceName: "github",
+		Image:       "ghcr.io/example/github-mcp:1.0",
+		Port:        3000,
+		Environment: map[string]string{"TOKEN": "secret"},
+	}
+	plan, err := manager.PlanContainer(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Expected a plan, got %v", err)
+	}
+	if len(manager.containers) != 0 {
+		t.Errorf("Expected a dry run not to track a container, got %d", len(manager.containers))
+	}
+	if plan.Environment["TOKEN"] != "***" {
+		t.Errorf("Expected TOKEN to be masked, got %q", plan.Environment["TOKEN"])
+	}
+	if !strings.HasPrefix(plan.URL, "http://localhost:81/mcp/github-") {
+		t.Errorf("Expected the URL to use the slug, got %s", plan.URL)
+	}
+	args := strings.Join(plan.PodmanArgs, " ")
+	if strings.Contains(args, "secret") || !strings.Contains(args, "-e TOKEN=***") {
+		t.Errorf("Expected podman args with masked environment, got %s", args)
+	}
+	if !strings.Contains(args, "--user 1000:1000") || !strings.Contains(args, "--pids-limit 512") {
+		t.Errorf("Expected hardening and default pids limit in podman args, got %s", args)
+	}
+	if !slices.Contains(plan.Policies, "default resources: pids_limit=512") || !slices.Contains(plan.Policies, "default user: 1000:1000") {
+		t.Errorf("Expected default resources and user to be reported, got %v", plan.Policies)
+	}
+
+	manager.containers["github"] = &models.Container{ServiceName: "github"}
+	if _, err := manager.PlanContainer(context.Background(), req); err == nil {
+		t.Errorf("Expected a plan for an existing container to be rejected")
+	}
+}
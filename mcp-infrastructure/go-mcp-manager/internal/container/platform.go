@@ -0,0 +1,151 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/internal/registry"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// platformLabel stores the "os/architecture" variant of the image a container runs
+const platformLabel = "mcp.platform"
+
+// ErrUnsupportedPlatform is returned for an image without a variant for the host's platform
+var ErrUnsupportedPlatform = errors.New("image has no variant for the host platform")
+
+// qemuArchitectures maps Go architectures to the names qemu registers its binfmt handlers under
+var qemuArchitectures = map[string]string{
+	"amd64":   "x86_64",
+	"386":     "i386",
+	"arm64":   "aarch64",
+	"arm":     "arm",
+	"ppc64le": "ppc64le",
+	"s390x":   "s390x",
+	"riscv64": "riscv64",
+}
+
+// platformChoice is the image variant a container runs
+type platformChoice struct {
+	Platform string
+	// Emulated is set when Platform is not the host's, so the container runs under qemu
+	Emulated bool
+}
+
+// hostPlatform returns the platform images run natively on. A podman machine or Docker Desktop VM
+// has the architecture of the host it runs on.
+func hostPlatform() string {
+	return "linux/" + runtime.GOARCH
+}
+
+// resolvePlatform chooses the variant of image to run. A local image is inspected, otherwise the
+// registry's manifest list is read. An image without a host variant is rejected unless emulation
+// is allowed, in which case linux/amd64, or else the first published variant, runs under qemu.
+// When the registry cannot be read the choice is left to podman.
+func (m *Manager) resolvePlatform(ctx context.Context, image string) (platformChoice, error) {
+	host := hostPlatform()
+
+	var available []string
+	output, err := podmanCommand(ctx, m.logger, "image", "inspect", image, "--format", "{{.Os}}/{{.Architecture}}").Output()
+	if err == nil {
+		available = []string{strings.TrimSpace(string(output))}
+	} else {
+		available, err = m.registryPlatforms(ctx, image)
+		if err != nil {
+			m.logger.DebugContext(ctx, "Could not determine image platforms, leaving the choice to podman",
+				slog.String("image", image),
+				slog.String("error", err.Error()))
+			return platformChoice{}, nil
+		}
+	}
+	return choosePlatform(image, host, available, m.config.Container.AllowEmulation, emulatorRegistered)
+}
+
+// registryPlatforms returns the host platform when the registry serves a variant for it, and the
+// platforms the image is published for otherwise
+func (m *Manager) registryPlatforms(ctx context.Context, image string) ([]string, error) {
+	ref, err := registry.ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := m.registry.Image(ctx, ref)
+	var platformErr *registry.PlatformError
+	if errors.As(err, &platformErr) {
+		return platformErr.Available, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []string{resolved.OS + "/" + resolved.Architecture}, nil
+}
+
+// choosePlatform picks the variant to run from the platforms an image is available for.
+// emulatorFor reports whether qemu can run an architecture on this host.
+func choosePlatform(image, host string, available []string, allowEmulation bool, emulatorFor func(architecture string) bool) (platformChoice, error) {
+	if len(available) == 0 || slices.Contains(available, host) {
+		return platformChoice{Platform: host}, nil
+	}
+
+	var linux []string
+	for _, platform := range available {
+		if strings.HasPrefix(platform, "linux/") {
+			linux = append(linux, platform)
+		}
+	}
+	if len(linux) == 0 || !allowEmulation {
+		return platformChoice{}, fmt.Errorf("%w: %s is published for %s, not %s; publish a %s variant or set IMAGE_ALLOW_EMULATION=true to run it under qemu",
+			ErrUnsupportedPlatform, image, strings.Join(available, ", "), host, host)
+	}
+
+	chosen := linux[0]
+	if slices.Contains(linux, "linux/amd64") {
+		chosen = "linux/amd64"
+	}
+	if !emulatorFor(strings.TrimPrefix(chosen, "linux/")) {
+		return platformChoice{}, fmt.Errorf("%w: %s is published for %s, not %s, and no qemu emulator for %s is registered with binfmt_misc",
+			ErrUnsupportedPlatform, image, strings.Join(available, ", "), host, chosen)
+	}
+	return platformChoice{Platform: chosen, Emulated: true}, nil
+}
+
+// emulatorRegistered reports whether the kernel runs binaries of architecture through a qemu
+// binfmt_misc handler. Engines in a VM, or hosts without binfmt_misc mounted, cannot be checked
+// and are assumed to have one, so podman reports the failure if they do not.
+func emulatorRegistered(architecture string) bool {
+	if currentEngine().host != "" {
+		return true
+	}
+	if _, err := os.Stat("/proc/sys/fs/binfmt_misc/status"); err != nil {
+		return true
+	}
+	name, known := qemuArchitectures[architecture]
+	if !known {
+		return false
+	}
+	_, err := os.Stat("/proc/sys/fs/binfmt_misc/qemu-" + name)
+	return err == nil
+}
+
+// platformArgs pins an emulated container to its variant and records the platform it runs
+func platformArgs(container *models.Container) []string {
+	if container.Platform == "" {
+		return nil
+	}
+	args := []string{"--label", platformLabel + "=" + container.Platform}
+	if container.Emulated {
+		args = append(args, "--platform", container.Platform)
+	}
+	return args
+}
+
+// discoverPlatform restores the platform a podman container runs, and whether it is emulated
+func (m *Manager) discoverPlatform(ctx context.Context, containerID string) (string, bool) {
+	platform := m.containerLabel(ctx, containerID, platformLabel)
+	return platform, platform != "" && platform != hostPlatform()
+}
@@ -0,0 +1,38 @@
+package container
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestChoosePlatform(t *testing.T) {
+	registered := func(string) bool { return true }
+
+	choice, err := choosePlatform("server:1", "linux/arm64", []string{"linux/amd64", "linux/arm64"}, false, registered)
+	if err != nil || choice.Platform != "linux/arm64" || choice.Emulated {
+		t.Errorf("Expected the native variant, got %+v (%v)", choice, err)
+	}
+
+	_, err = choosePlatform("server:1", "linux/arm64", []string{"linux/s390x", "linux/amd64"}, false, registered)
+	if !errors.Is(err, ErrUnsupportedPlatform) || !strings.Contains(err.Error(), "IMAGE_ALLOW_EMULATION") {
+		t.Errorf("Expected ErrUnsupportedPlatform naming the emulation setting, got %v", err)
+	}
+
+	choice, err = choosePlatform("server:1", "linux/arm64", []string{"linux/s390x", "linux/amd64"}, true, registered)
+	if err != nil || choice.Platform != "linux/amd64" || !choice.Emulated {
+		t.Errorf("Expected linux/amd64 under emulation, got %+v (%v)", choice, err)
+	}
+
+	_, err = choosePlatform("server:1", "linux/arm64", []string{"linux/amd64"}, true, func(string) bool { return false })
+	if !errors.Is(err, ErrUnsupportedPlatform) {
+		t.Errorf("Expected emulation to be refused without a qemu handler, got %v", err)
+	}
+
+	args := strings.Join(platformArgs(&models.Container{Platform: "linux/amd64", Emulated: true}), " ")
+	if args != "--label mcp.platform=linux/amd64 --platform linux/amd64" {
+		t.Errorf("Expected the platform label and flag, got %q", args)
+	}
+}
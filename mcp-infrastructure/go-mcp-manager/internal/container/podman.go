@@ -0,0 +1,141 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/internal/retry"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// transientPodmanErrors are podman and registry messages for failures that clear up on their own,
+// such as a locked libpod database or a registry connection dropped mid-pull
+var transientPodmanErrors = []string{
+	"database is locked",
+	"resource temporarily unavailable",
+	"connection refused",
+	"connection reset by peer",
+	"i/o timeout",
+	"tls handshake timeout",
+	"unexpected eof",
+	"too many requests",
+	"service unavailable",
+	"bad gateway",
+	"gateway timeout",
+	"temporary failure in name resolution",
+}
+
+// podmanCommand builds a podman invocation and logs it with the caller's request context,
+// so every runtime call made while provisioning carries the originating request ID.
+func podmanCommand(ctx context.Context, logger *slog.Logger, args ...string) *exec.Cmd {
+	logger.DebugContext(ctx, "Running podman command",
+		slog.String("args", strings.Join(redactPodmanArgs(args), " ")))
+	binary, args := currentEngine().command(args)
+	return exec.CommandContext(ctx, binary, args...)
+}
+
+// CheckRuntime reports whether podman can reach its storage and container database
+func (m *Manager) CheckRuntime(ctx context.Context) error {
+	output, err := podmanCommand(ctx, m.logger, "info", "--format", "{{.Store.GraphRoot}}").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("podman info failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// CheckStateStore reports whether manager state can be persisted
+func (m *Manager) CheckStateStore() error {
+	return m.store.Check()
+}
+
+// runPodman runs a podman command that is safe to repeat, retrying transient failures
+func runPodman(ctx context.Context, logger *slog.Logger, args ...string) ([]byte, error) {
+	var output []byte
+	err := retry.For(retry.Podman).Do(ctx, func(ctx context.Context) error {
+		var err error
+		output, err = podmanCommand(ctx, logger, args...).CombinedOutput()
+		return podmanRetryable(err, output)
+	})
+	return output, err
+}
+
+// podmanRun runs podman run for container, retrying transient failures. A container left behind
+// by a failed attempt is removed before the next one, so the retry can reuse its name. Under
+// systemd supervision the container is created instead and started by its unit.
+func (m *Manager) podmanRun(ctx context.Context, container *models.Container, args []string) ([]byte, error) {
+	if err := m.ensurePod(ctx, container); err != nil {
+		return nil, err
+	}
+	supervised := m.supervised()
+	if supervised {
+		args = supervisedArgs(args)
+	}
+	attempt := 0
+	var output []byte
+	err := retry.For(retry.Podman).Do(ctx, func(ctx context.Context) error {
+		attempt++
+		if attempt > 1 {
+			_ = podmanCommand(ctx, m.logger, "rm", "-f", "--ignore", container.Name).Run()
+		}
+		var err error
+		output, err = podmanCommand(ctx, m.logger, args...).CombinedOutput()
+		return podmanRetryable(err, output)
+	})
+	if err == nil && supervised {
+		if err := m.installUnit(ctx, container, true); err != nil {
+			_ = podmanCommand(ctx, m.logger, "rm", "-f", "--ignore", container.Name).Run()
+			m.removeUnit(ctx, container.Name)
+			return output, err
+		}
+	}
+	return output, err
+}
+
+// podmanRetryable marks err permanent unless podman's output reports a transient failure
+func podmanRetryable(err error, output []byte) error {
+	if err == nil || transientPodmanError(string(output)) {
+		return err
+	}
+	return retry.Permanent(err)
+}
+
+// transientPodmanError reports whether podman output describes a failure worth retrying
+func transientPodmanError(output string) bool {
+	output = strings.ToLower(output)
+	for _, message := range transientPodmanErrors {
+		if strings.Contains(output, message) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactPodmanArgs masks environment variable values so secrets never reach the logs
+func redactPodmanArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+
+	for i := 0; i < len(redacted); i++ {
+		arg := redacted[i]
+		switch {
+		case (arg == "-e" || arg == "--env") && i+1 < len(redacted):
+			redacted[i+1] = maskEnvValue(redacted[i+1])
+			i++
+		case strings.HasPrefix(arg, "--env="):
+			redacted[i] = "--env=" + maskEnvValue(strings.TrimPrefix(arg, "--env="))
+		}
+	}
+
+	return redacted
+}
+
+// maskEnvValue turns KEY=VALUE into KEY=***
+func maskEnvValue(kv string) string {
+	if idx := strings.Index(kv, "="); idx != -1 {
+		return kv[:idx] + "=***"
+	}
+	return kv
+}
@@ -0,0 +1,21 @@
+package container
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPodmanRetryable(t *testing.T) {
+	failure := errors.New("exit status 125")
+	transient := podmanRetryable(failure, []byte("Error: initializing source docker://ghcr.io/org/mcp:1: pinging container registry ghcr.io: Get \"https://ghcr.io/v2/\": net/http: TLS handshake timeout"))
+	if transient != failure {
+		t.Errorf("Expected a registry timeout to be retried, got %v", transient)
+	}
+	permanent := podmanRetryable(failure, []byte("Error: creating container storage: the container name \"mcp-github\" is already in use"))
+	if permanent == failure || !errors.Is(permanent, failure) {
+		t.Errorf("Expected a name conflict not to be retried, got %v", permanent)
+	}
+	if podmanRetryable(nil, nil) != nil {
+		t.Error("Expected success to stay nil")
+	}
+}
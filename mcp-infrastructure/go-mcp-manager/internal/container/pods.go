@@ -0,0 +1,123 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// podLabel stores the workspace pod a container was run in
+const podLabel = "mcp.pod"
+
+// workspacePodName returns the podman pod a workspace's containers share
+func (m *Manager) workspacePodName(workspaceID string) string {
+	name := m.config.Network.PodPrefix + networkNameInvalidChars.ReplaceAllString(strings.ToLower(workspaceID), "-")
+	if len(name) > maxNetworkNameLength {
+		name = name[:maxNetworkNameLength]
+	}
+	return name
+}
+
+// workspacePodUnsafe returns the pod container joins, or "" when it keeps its own network
+// namespace: without workspace pods, outside a workspace, on the shared network, with published
+// ports or with resolvers and hosts entries of its own, none of which can be set per pod member.
+// Members share their ports, so a container whose port is taken in the pod, such as a session or
+// staging copy of a member, also stays outside it (caller must hold lock).
+func (m *Manager) workspacePodUnsafe(container *models.Container) string {
+	if !m.config.Network.Pods || container.WorkspaceID == "" || container.SharedNetwork || m.config.Container.PublishPorts {
+		return ""
+	}
+	defaults, err := m.resolveConnectivity(connectivity{})
+	if err != nil || !slices.Equal(container.DNS, defaults.DNS) || !slices.Equal(container.ExtraHosts, defaults.ExtraHosts) {
+		return ""
+	}
+
+	pod := m.workspacePodName(container.WorkspaceID)
+	for _, other := range m.containers {
+		if other.Pod == pod && other.ServiceName != container.ServiceName && other.Port == container.Port {
+			m.logger.Info("Running container outside its workspace pod, whose port is taken",
+				slog.String("service", container.ServiceName),
+				slog.String("pod", pod),
+				slog.Int("port", container.Port),
+				slog.String("taken_by", other.ServiceName))
+			return ""
+		}
+	}
+	return pod
+}
+
+// ensurePod creates container's workspace pod on its network if it does not exist yet. The pod
+// gets the manager's default resolvers and hosts entries, which is why only containers using
+// those join it.
+func (m *Manager) ensurePod(ctx context.Context, container *models.Container) error {
+	if container.Pod == "" || podmanCommand(ctx, m.logger, "pod", "exists", container.Pod).Run() == nil {
+		return nil
+	}
+
+	network := container.Network
+	if network == "" {
+		network = m.config.Traefik.Network
+	}
+	args := []string{"pod", "create", "--name", container.Pod, "--network", network,
+		"--label", fmt.Sprintf("%s=%s", managedByLabel, m.config.Container.ManagedByLabel),
+		"--label", fmt.Sprintf("%s=%s", workspaceLabel, container.WorkspaceID)}
+	defaults, err := m.resolveConnectivity(connectivity{})
+	if err != nil {
+		return fmt.Errorf("failed to create pod %s: %w", container.Pod, err)
+	}
+	for _, server := range defaults.DNS {
+		args = append(args, "--dns", server)
+	}
+	for _, entry := range defaults.ExtraHosts {
+		args = append(args, "--add-host", entry)
+	}
+
+	if output, err := podmanCommand(ctx, m.logger, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create pod %s: %w: %s", container.Pod, err, strings.TrimSpace(string(output)))
+	}
+	m.logger.InfoContext(ctx, "Created workspace pod",
+		slog.String("pod", container.Pod),
+		slog.String("workspace", container.WorkspaceID))
+	return nil
+}
+
+// releasePodUnsafe removes a workspace pod once no tracked or archived container is in it, as
+// removing a pod also removes its stopped containers (caller must hold lock)
+func (m *Manager) releasePodUnsafe(ctx context.Context, pod string) {
+	if pod == "" {
+		return
+	}
+	for _, container := range m.containers {
+		if container.Pod == pod {
+			return
+		}
+	}
+	archived, err := m.ListArchived()
+	if err != nil {
+		return
+	}
+	for _, record := range archived {
+		if record.Pod == pod {
+			return
+		}
+	}
+
+	if output, err := podmanCommand(ctx, m.logger, "pod", "rm", "--ignore", pod).CombinedOutput(); err != nil {
+		m.logger.WarnContext(ctx, "Workspace pod not removed",
+			slog.String("pod", pod),
+			slog.String("error", err.Error()),
+			slog.String("output", string(output)))
+		return
+	}
+	m.logger.InfoContext(ctx, "Removed workspace pod", slog.String("pod", pod))
+}
+
+// podmanPodArgs returns the flags that run container in its workspace pod, in place of the
+// network flags
+func (m *Manager) podmanPodArgs(container *models.Container) []string {
+	return []string{"--pod", container.Pod, "--label", fmt.Sprintf("%s=%s", podLabel, container.Pod)}
+}
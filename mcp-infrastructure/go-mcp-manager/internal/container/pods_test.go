@@ -0,0 +1,47 @@
+package container
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestWorkspacePods(t *testing.T) {
+	cfg := &config.Config{
+		Container: config.ContainerConfig{NamePrefix: "test-", DNS: []string{"10.0.0.53"}},
+		Traefik:   config.TraefikConfig{Network: "mcp-network"},
+		Network:   config.NetworkConfig{PerWorkspace: true, Prefix: "mcp-ws-", Pods: true, PodPrefix: "mcp-pod-"},
+	}
+	manager := newTestManager(cfg)
+
+	member := &models.Container{Name: "test-search", ServiceName: "search", WorkspaceID: "Team_A", Network: "mcp-ws-team_a", Port: 8000, DNS: []string{"10.0.0.53"}}
+	if pod := manager.workspacePodUnsafe(member); pod != "mcp-pod-team_a" {
+		t.Fatalf("Expected the container to join mcp-pod-team_a, got %q", pod)
+	}
+	member.Pod = "mcp-pod-team_a"
+	manager.containers = map[string]*models.Container{"search": member}
+
+	outside := []*models.Container{
+		{ServiceName: "loose", Port: 8001},
+		{ServiceName: "shared", WorkspaceID: "Team_A", SharedNetwork: true, Port: 8001},
+		{ServiceName: "resolver", WorkspaceID: "Team_A", Port: 8001, DNS: []string{"1.1.1.1"}},
+		{ServiceName: "search-session", WorkspaceID: "Team_A", Port: 8000, DNS: []string{"10.0.0.53"}},
+	}
+	for _, container := range outside {
+		if pod := manager.workspacePodUnsafe(container); pod != "" {
+			t.Errorf("Expected %s to keep its own network namespace, got pod %q", container.ServiceName, pod)
+		}
+	}
+
+	args := strings.Join(manager.buildPodmanRunArgs(member), " ")
+	if !strings.Contains(args, "--pod mcp-pod-team_a") || !strings.Contains(args, "mcp.pod=mcp-pod-team_a") {
+		t.Errorf("Expected the container to run in its pod, got %q", args)
+	}
+	for _, flag := range []string{"--network", "--dns"} {
+		if strings.Contains(args, flag+" ") {
+			t.Errorf("Expected no %s for a pod member, got %q", flag, args)
+		}
+	}
+}
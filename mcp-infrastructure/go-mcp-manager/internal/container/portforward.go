@@ -0,0 +1,36 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// PortForwardAddress returns where the manager reaches a running container's server port, so a
+// developer's local client can be tunneled to it without a public route
+func (m *Manager) PortForwardAddress(ctx context.Context, serviceName string) (string, error) {
+	m.mutex.RLock()
+	container, exists := m.containers[serviceName]
+	var snapshot models.Container
+	if exists {
+		snapshot = *container
+	}
+	m.mutex.RUnlock()
+
+	if !exists {
+		return "", fmt.Errorf("container %s not found", serviceName)
+	}
+	if snapshot.Status != models.StatusRunning || snapshot.ID == "" {
+		return "", fmt.Errorf("%w: %s is %s", ErrContainerNotRunning, serviceName, snapshot.Status)
+	}
+
+	containerIP, err := m.getContainerIP(ctx, snapshot.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get container IP: %w", err)
+	}
+	host, port := m.upstreamAddress(ctx, &snapshot, containerIP)
+	return net.JoinHostPort(host, strconv.Itoa(port)), nil
+}
@@ -0,0 +1,25 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestPortForwardAddressNeedsRunningContainer(t *testing.T) {
+	manager := newTestManager(&config.Config{
+		Container: config.ContainerConfig{NamePrefix: "test-"},
+		State:     config.StateConfig{Dir: t.TempDir()},
+	})
+	manager.containers["test-search"] = &models.Container{ServiceName: "test-search", ID: "abc", Port: 8000, Status: models.StatusPreempted}
+
+	if _, err := manager.PortForwardAddress(context.Background(), "test-search"); !errors.Is(err, ErrContainerNotRunning) {
+		t.Errorf("Expected ErrContainerNotRunning for a preempted container, got %v", err)
+	}
+	if _, err := manager.PortForwardAddress(context.Background(), "test-missing"); err == nil || errors.Is(err, ErrContainerNotRunning) {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// portMismatchWarning is published when a container does not listen on the port it was created with
+	portMismatchWarning = "port_mismatch"
+	// exposedPortsFormat lists the ports an image or container exposes, e.g. "8080/tcp 53/udp "
+	exposedPortsFormat = "{{range $port, $config := .Config.ExposedPorts}}{{$port}} {{end}}"
+	// portProbeInterval is how often the configured port is retried while the server starts
+	portProbeInterval = 250 * time.Millisecond
+	// portDialTimeout bounds a single connection attempt
+	portDialTimeout = 500 * time.Millisecond
+)
+
+// commonMCPPorts are tried after the container's exposed ports when the configured port stays closed
+var commonMCPPorts = []int{8000, 8080, 3000, 5000, 80}
+
+// parseExposedPorts returns the TCP ports of podman's ExposedPorts listing, in order
+func parseExposedPorts(output string) []int {
+	var ports []int
+	for _, field := range strings.Fields(output) {
+		number, protocol, _ := strings.Cut(field, "/")
+		if protocol != "" && protocol != "tcp" {
+			continue
+		}
+		if port, err := strconv.Atoi(number); err == nil && port > 0 {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+// imageExposedPort returns the port an image exposes when it exposes exactly one TCP port
+func (m *Manager) imageExposedPort(ctx context.Context, image string) int {
+	output, err := podmanCommand(ctx, m.logger, "image", "inspect", image, "--format", exposedPortsFormat).CombinedOutput()
+	if err != nil {
+		return 0
+	}
+	if ports := parseExposedPorts(string(output)); len(ports) == 1 {
+		return ports[0]
+	}
+	return 0
+}
+
+// negotiatePort returns the port the container's route should use: its configured port if that
+// accepts connections within PORT_PROBE_TIMEOUT, otherwise the first exposed or common MCP port
+// that does. A warning is published when the route does not use the configured port, or when
+// nothing listens at all.
+func (m *Manager) negotiatePort(ctx context.Context, container *models.Container, containerIP string) int {
+	timeout := m.config.Traefik.PortProbeTimeout
+	if timeout <= 0 {
+		return container.Port
+	}
+
+	output, _ := podmanCommand(ctx, m.logger, "inspect", container.ID, "--format", exposedPortsFormat).CombinedOutput()
+	candidates := append(parseExposedPorts(string(output)), commonMCPPorts...)
+	port, found := probePorts(ctx, containerIP, container.Port, candidates, timeout)
+
+	var message string
+	switch {
+	case !found:
+		message = fmt.Sprintf("nothing accepted connections on port %d or the ports the container exposes; check the port in json_spec", container.Port)
+	case port != container.Port:
+		message = fmt.Sprintf("port %d did not accept connections but %d did, so the route uses %d", container.Port, port, port)
+	default:
+		return port
+	}
+
+	m.logger.WarnContext(ctx, "Container does not listen on its configured port",
+		slog.String("service", container.ServiceName),
+		slog.Int("configured_port", container.Port),
+		slog.Int("port", port),
+		slog.Bool("found", found))
+	if instanceID, exists := container.Environment["MCP_INSTANCE_ID"]; exists {
+		if err := m.eventPublisher.PublishWarning(ctx, instanceID, container.ServiceName, portMismatchWarning, message); err != nil {
+			m.logger.WarnContext(ctx, "Failed to publish port mismatch warning",
+				slog.String("instance_id", instanceID),
+				slog.String("error", err.Error()))
+		}
+	}
+	return port
+}
+
+// probePorts waits up to timeout for configured to accept connections on ip. If it never does,
+// the first candidate that does is returned; false means none did and configured is returned.
+func probePorts(ctx context.Context, ip string, configured int, candidates []int, timeout time.Duration) (int, bool) {
+	deadline := time.Now().Add(timeout)
+	for {
+		if portListening(ctx, ip, configured) {
+			return configured, true
+		}
+		if time.Now().After(deadline) || ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(portProbeInterval):
+		}
+	}
+
+	for _, port := range candidates {
+		if port != configured && portListening(ctx, ip, port) {
+			return port, true
+		}
+	}
+	return configured, false
+}
+
+// portListening reports whether ip accepts TCP connections on port
+func portListening(ctx context.Context, ip string, port int) bool {
+	dialer := net.Dialer{Timeout: portDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip, strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
@@ -0,0 +1,37 @@
+package container
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestProbePorts(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	listening := listener.Addr().(*net.TCPAddr).Port
+
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	ctx := context.Background()
+	if port, found := probePorts(ctx, "127.0.0.1", listening, nil, time.Second); !found || port != listening {
+		t.Errorf("Expected the configured port to be kept, got %d (%v)", port, found)
+	}
+	if port, found := probePorts(ctx, "127.0.0.1", closedPort, []int{closedPort, listening}, 100*time.Millisecond); !found || port != listening {
+		t.Errorf("Expected the route to follow the listening port %d, got %d (%v)", listening, port, found)
+	}
+	if port, found := probePorts(ctx, "127.0.0.1", closedPort, nil, 100*time.Millisecond); found || port != closedPort {
+		t.Errorf("Expected the configured port back when nothing listens, got %d (%v)", port, found)
+	}
+
+	ports := parseExposedPorts("8080/tcp 53/udp 3000/tcp ")
+	if len(ports) != 2 || ports[0] != 8080 || ports[1] != 3000 {
+		t.Errorf("Expected TCP ports 8080 and 3000, got %v", ports)
+	}
+}
@@ -0,0 +1,191 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default cloud metadata endpoints signalling an upcoming preemption
+const (
+	awsSpotActionURL = "http://169.254.169.254/latest/meta-data/spot/instance-action"
+	gcpPreemptedURL  = "http://metadata.google.internal/computeMetadata/v1/instance/preempted"
+)
+
+// ErrHostPreempted is returned when a container would be started on a host that is being preempted
+var ErrHostPreempted = errors.New("host is being preempted, not starting containers")
+
+// PreemptionStatus describes the preemption state of this host
+type PreemptionStatus struct {
+	Preemptible  bool      `json:"preemptible"`
+	Provider     string    `json:"provider,omitempty"`
+	Preempted    bool      `json:"preempted"`
+	Reason       string    `json:"reason,omitempty"`
+	DetectedAt   time.Time `json:"detected_at,omitempty"`
+	Instances    []string  `json:"instances,omitempty"`
+	CompletedAt  time.Time `json:"completed_at,omitempty"`
+	PollInterval string    `json:"poll_interval,omitempty"`
+}
+
+// preemptionState tracks whether this host is being preempted
+type preemptionState struct {
+	mutex  sync.RWMutex
+	status PreemptionStatus
+}
+
+// startPreemptionWatch polls the cloud metadata service for preemption notices
+func (m *Manager) startPreemptionWatch() {
+	cfg := m.config.Preemption
+	url := m.preemptionMetadataURL()
+	if !cfg.Preemptible || url == "" {
+		return
+	}
+
+	m.logger.Info("Watching for host preemption notices",
+		slog.String("provider", cfg.Provider),
+		slog.String("url", url),
+		slog.Duration("interval", cfg.PollInterval))
+
+	client := &http.Client{Timeout: 2 * time.Second}
+	ticker := time.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.healthCtx.Done():
+			return
+		case <-ticker.C:
+			preempted, detail := m.pollPreemptionNotice(client, url)
+			if preempted {
+				m.HandlePreemption(m.healthCtx, fmt.Sprintf("%s preemption notice: %s", cfg.Provider, detail))
+				return
+			}
+		}
+	}
+}
+
+// preemptionMetadataURL returns the metadata endpoint to poll for the configured provider
+func (m *Manager) preemptionMetadataURL() string {
+	if m.config.Preemption.MetadataURL != "" {
+		return m.config.Preemption.MetadataURL
+	}
+	switch m.config.Preemption.Provider {
+	case "aws":
+		return awsSpotActionURL
+	case "gcp":
+		return gcpPreemptedURL
+	default:
+		return ""
+	}
+}
+
+// pollPreemptionNotice checks the metadata endpoint once
+func (m *Manager) pollPreemptionNotice(client *http.Client, url string) (bool, string) {
+	req, err := http.NewRequestWithContext(m.healthCtx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, ""
+	}
+	if m.config.Preemption.Provider == "gcp" {
+		req.Header.Set("Metadata-Flavor", "Google")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		m.logger.Debug("Preemption metadata poll failed", slog.String("error", err.Error()))
+		return false, ""
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	detail := strings.TrimSpace(string(body))
+
+	if resp.StatusCode != http.StatusOK {
+		return false, ""
+	}
+
+	// GCP answers TRUE/FALSE, AWS only returns 200 once an interruption is scheduled
+	if m.config.Preemption.Provider == "gcp" {
+		return strings.EqualFold(detail, "TRUE"), detail
+	}
+	return true, detail
+}
+
+// HandlePreemption records that this host is about to be reclaimed. From then on it starts no
+// containers, so the platform places new instances elsewhere, and warns the platform about every
+// instance on it. Running instances keep their routes and serve until the host goes away: moving
+// them to another host first needs multi-host support the manager does not have yet.
+func (m *Manager) HandlePreemption(ctx context.Context, reason string) PreemptionStatus {
+	m.preemption.mutex.Lock()
+	if m.preemption.status.Preempted {
+		status := m.preemption.status
+		m.preemption.mutex.Unlock()
+		return status
+	}
+	m.preemption.status.Preempted = true
+	m.preemption.status.Reason = reason
+	m.preemption.status.DetectedAt = time.Now()
+	m.preemption.mutex.Unlock()
+
+	m.logger.WarnContext(ctx, "Host preemption detected, not starting containers anymore",
+		slog.String("reason", reason))
+
+	containers := m.ListContainers()
+	instances := make([]string, 0, len(containers))
+	for i := range containers {
+		container := &containers[i]
+		message := fmt.Sprintf("The host is being preempted (%s); the instance serves until it goes away", reason)
+
+		m.mutex.Lock()
+		if tracked, exists := m.containers[container.ServiceName]; exists {
+			m.recordContainerEvent(tracked, InstanceEventWarning, "HostPreempting", message)
+		}
+		m.mutex.Unlock()
+
+		if instanceID := container.Environment["MCP_INSTANCE_ID"]; instanceID != "" {
+			if err := m.eventPublisher.PublishWarning(ctx, instanceID, container.ServiceName, "host_preempting", message); err != nil {
+				m.logger.WarnContext(ctx, "Failed to publish preemption warning",
+					slog.String("instance_id", instanceID),
+					slog.String("error", err.Error()))
+			}
+		}
+		instances = append(instances, container.ServiceName)
+	}
+
+	m.preemption.mutex.Lock()
+	m.preemption.status.Instances = instances
+	m.preemption.status.CompletedAt = time.Now()
+	status := m.preemption.status
+	m.preemption.mutex.Unlock()
+
+	m.logger.InfoContext(ctx, "Preemption handling completed",
+		slog.Int("instances", len(instances)))
+
+	return status
+}
+
+// GetPreemptionStatus returns the preemption state of this host
+func (m *Manager) GetPreemptionStatus() PreemptionStatus {
+	m.preemption.mutex.RLock()
+	defer m.preemption.mutex.RUnlock()
+
+	status := m.preemption.status
+	status.Preemptible = m.config.Preemption.Preemptible
+	status.Provider = m.config.Preemption.Provider
+	if status.Preemptible {
+		status.PollInterval = m.config.Preemption.PollInterval.String()
+	}
+	return status
+}
+
+// IsPreempted reports whether the host is being preempted and must not start containers
+func (m *Manager) IsPreempted() bool {
+	m.preemption.mutex.RLock()
+	defer m.preemption.mutex.RUnlock()
+	return m.preemption.status.Preempted
+}
@@ -0,0 +1,70 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestPreemptionKeepsInstancesServing(t *testing.T) {
+	cfg := &config.Config{Container: config.ContainerConfig{NamePrefix: "test-", MaxContainers: 10}}
+	manager := newTestManager(cfg)
+	manager.containers["running"] = &models.Container{
+		Name: "test-running", ServiceName: "running", Slug: "running-abc", Status: models.StatusRunning,
+	}
+	manager.containers["stopped"] = &models.Container{
+		Name: "test-stopped", ServiceName: "stopped", Status: models.StatusStopped,
+	}
+
+	status := manager.HandlePreemption(context.Background(), "spot interruption")
+	if len(status.Instances) != 2 {
+		t.Errorf("Expected both instances to be listed, got %v", status.Instances)
+	}
+	if running := manager.containers["running"]; running.Status != models.StatusRunning {
+		t.Errorf("Expected a running instance to keep serving, got %s", running.Status)
+	}
+
+	if _, err := manager.StartContainer(context.Background(), "stopped"); !errors.Is(err, ErrHostPreempted) {
+		t.Errorf("Expected starting a container on a preempted host to be refused, got %v", err)
+	}
+	if _, err := manager.UnarchiveContainer(context.Background(), "archived"); !errors.Is(err, ErrHostPreempted) {
+		t.Errorf("Expected restoring a container on a preempted host to be refused, got %v", err)
+	}
+	if _, err := manager.CloneContainer(context.Background(), "running", models.CloneContainerRequest{ServiceName: "copy"}); !errors.Is(err, ErrHostPreempted) {
+		t.Errorf("Expected cloning on a preempted host to be refused, got %v", err)
+	}
+}
+
+func TestPreemptionRejectsNewContainers(t *testing.T) {
+	cfg := &config.Config{
+		Container: config.ContainerConfig{
+			NamePrefix:    "test-",
+			MaxContainers: 10,
+		},
+		Redis: config.RedisConfig{
+			URL: "redis://localhost:6379",
+		},
+	}
+	manager := newTestManager(cfg)
+
+	if manager.IsPreempted() {
+		t.Fatal("Expected manager not to be preempted initially")
+	}
+
+	status := manager.HandlePreemption(context.Background(), "test notice")
+	if !status.Preempted || status.Reason != "test notice" {
+		t.Errorf("Expected preempted status with reason, got %+v", status)
+	}
+
+	_, err := manager.CreateContainer(context.Background(), models.CreateContainerRequest{
+		ServiceName: "after-preemption",
+		Image:       "nginx:latest",
+		Port:        80,
+	})
+	if err == nil {
+		t.Error("Expected container creation to be rejected while preempted")
+	}
+}
@@ -0,0 +1,285 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/webhooks"
+	"github.com/agentarea/mcp-manager/pkg/events"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// priorityLabel stores a container's priority class
+	priorityLabel = "mcp.priority"
+	// preemptedBucket records the containers stopped to make room for others, keyed by service name
+	preemptedBucket = "preempted"
+	// preemptionLogBucket keeps past preemptions, keyed so that they sort oldest first
+	preemptionLogBucket = "preemption_log"
+	// maxPreemptionLog is how many preemptions are kept
+	maxPreemptionLog = 500
+)
+
+// priorityRanks orders the priority classes; a create may preempt instances of a lower rank
+var priorityRanks = map[string]int{
+	models.PriorityLow:    0,
+	models.PriorityNormal: 1,
+	models.PriorityHigh:   2,
+}
+
+// parsePrioritySpec reads the optional priority object from json_spec
+func parsePrioritySpec(jsonSpec map[string]interface{}) (*models.Priority, error) {
+	raw, exists := jsonSpec["priority"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+
+	if _, ok := raw.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("priority must be an object")
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("priority is not valid JSON: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	priority := &models.Priority{}
+	if err := decoder.Decode(priority); err != nil {
+		return nil, fmt.Errorf("invalid priority: %w", err)
+	}
+
+	if err := validatePriority(priority); err != nil {
+		return nil, err
+	}
+	return priority, nil
+}
+
+// validatePriority checks the priority class
+func validatePriority(priority *models.Priority) error {
+	if priority == nil || priority.Class == "" {
+		return nil
+	}
+	if _, ok := priorityRanks[priority.Class]; !ok {
+		return fmt.Errorf("invalid priority.class %q, must be low, normal or high", priority.Class)
+	}
+	return nil
+}
+
+// priorityClass returns the class of priority, "normal" when it is unset
+func priorityClass(priority *models.Priority) string {
+	if priority == nil || priority.Class == "" {
+		return models.PriorityNormal
+	}
+	return priority.Class
+}
+
+// discoverPriority restores the priority persisted on a podman container
+func (m *Manager) discoverPriority(ctx context.Context, containerID string) *models.Priority {
+	var priority models.Priority
+	if !m.discoverJSONLabel(ctx, containerID, priorityLabel, &priority) {
+		return nil
+	}
+	return &priority
+}
+
+// capacityUsedUnsafe counts the containers holding a slot of MaxContainers. Preempted containers
+// gave theirs up, so they do not count until they are started again.
+func (m *Manager) capacityUsedUnsafe() int {
+	used := 0
+	for _, container := range m.containers {
+		if container.Status != models.StatusPreempted {
+			used++
+		}
+	}
+	return used
+}
+
+// slotsFull reports whether every container slot is taken
+func (m *Manager) slotsFull() bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.capacityUsedUnsafe() >= m.config.Container.MaxContainers
+}
+
+// preemptionVictim picks the container to preempt for a create of the given class: a running,
+// preemptible container of a lower class, the lowest class first and the newest among equals,
+// as it has done the least work. It returns nil when there is none.
+func preemptionVictim(containers map[string]*models.Container, class string, forService string) *models.Container {
+	var victim *models.Container
+	for _, container := range containers {
+		if !preemptibleFor(container, class, forService) {
+			continue
+		}
+		candidateRank := priorityRanks[priorityClass(container.Priority)]
+		if victim == nil {
+			victim = container
+			continue
+		}
+		victimRank := priorityRanks[priorityClass(victim.Priority)]
+		if candidateRank < victimRank || candidateRank == victimRank && container.CreatedAt.After(victim.CreatedAt) {
+			victim = container
+		}
+	}
+	return victim
+}
+
+// preemptibleFor reports whether container may be preempted for a create of forService of the
+// given class: it is running, preemptible and of a lower class
+func preemptibleFor(container *models.Container, class string, forService string) bool {
+	if container.ServiceName == forService || container.Priority == nil || !container.Priority.Preemptible {
+		return false
+	}
+	if container.StoppedAt != nil || container.Status != models.StatusRunning && container.Status != models.StatusUnhealthy {
+		return false
+	}
+	return priorityRanks[priorityClass(container.Priority)] < priorityRanks[class]
+}
+
+// preemptibleCount counts the containers that could be preempted for a create of forService
+func (m *Manager) preemptibleCount(forService string, priority *models.Priority) int {
+	class := priorityClass(priority)
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	count := 0
+	for _, container := range m.containers {
+		if preemptibleFor(container, class, forService) {
+			count++
+		}
+	}
+	return count
+}
+
+// preemptFor stops one lower-priority preemptible container to make room for forService and
+// reports whether it did. The container is kept as StatusPreempted and the preemption is
+// published, sent to webhooks and recorded.
+func (m *Manager) preemptFor(ctx context.Context, forService string, priority *models.Priority, reason string) bool {
+	class := priorityClass(priority)
+	m.mutex.RLock()
+	victim := preemptionVictim(m.containers, class, forService)
+	var serviceName string
+	if victim != nil {
+		serviceName = victim.ServiceName
+	}
+	m.mutex.RUnlock()
+	if victim == nil {
+		return false
+	}
+
+	container, err := m.stopContainer(ctx, serviceName, false)
+	if err != nil {
+		m.logger.WarnContext(ctx, "Failed to preempt container",
+			slog.String("service", serviceName),
+			slog.String("preempted_for", forService),
+			slog.String("error", err.Error()))
+		return false
+	}
+
+	m.mutex.Lock()
+	record := models.Preemption{
+		ServiceName:  serviceName,
+		InstanceID:   container.Environment["MCP_INSTANCE_ID"],
+		Class:        priorityClass(container.Priority),
+		PreemptedFor: forService,
+		ForClass:     class,
+		Reason:       reason,
+		PreemptedAt:  time.Now(),
+	}
+	container.Status = models.StatusPreempted
+	m.mutex.Unlock()
+	m.recordPreemption(ctx, record)
+
+	message := fmt.Sprintf("Preempted to make room for %s (%s priority): %s", forService, class, reason)
+	if record.InstanceID != "" {
+		if err := m.eventPublisher.PublishStatusUpdate(ctx, record.InstanceID, serviceName, events.StatusPreempted, container.ID, ""); err != nil {
+			m.logger.WarnContext(ctx, "Failed to publish preempted status",
+				slog.String("instance_id", record.InstanceID),
+				slog.String("error", err.Error()))
+		}
+		if err := m.eventPublisher.PublishWarning(ctx, record.InstanceID, serviceName, "preempted", message); err != nil {
+			m.logger.WarnContext(ctx, "Failed to publish preemption warning",
+				slog.String("instance_id", record.InstanceID),
+				slog.String("error", err.Error()))
+		}
+	}
+	m.notifyWebhook(webhooks.EventContainerPreempted, container, message)
+
+	m.logger.WarnContext(ctx, "Container preempted",
+		slog.String("service", serviceName),
+		slog.String("class", record.Class),
+		slog.String("preempted_for", forService),
+		slog.String("for_class", class),
+		slog.String("reason", reason))
+	return true
+}
+
+// preemptWhileFull preempts lower-priority containers until a slot is free for forService or
+// nothing is left to preempt
+func (m *Manager) preemptWhileFull(ctx context.Context, forService string, priority *models.Priority) {
+	for m.slotsFull() {
+		reason := fmt.Sprintf("all %d container slots are taken", m.config.Container.MaxContainers)
+		if !m.preemptFor(ctx, forService, priority, reason) {
+			return
+		}
+	}
+}
+
+// recordPreemption marks the container as preempted across restarts and adds the preemption to
+// the log, dropping the oldest entries beyond maxPreemptionLog
+func (m *Manager) recordPreemption(ctx context.Context, record models.Preemption) {
+	if err := m.store.Put(preemptedBucket, record.ServiceName, record); err != nil {
+		m.logger.WarnContext(ctx, "Failed to record preempted container",
+			slog.String("service", record.ServiceName),
+			slog.String("error", err.Error()))
+	}
+	key := fmt.Sprintf("%020d-%s", record.PreemptedAt.UnixNano(), record.ServiceName)
+	if err := m.store.Put(preemptionLogBucket, key, record); err != nil {
+		m.logger.WarnContext(ctx, "Failed to log preemption",
+			slog.String("service", record.ServiceName),
+			slog.String("error", err.Error()))
+		return
+	}
+	keys, err := m.store.Keys(preemptionLogBucket)
+	if err != nil {
+		return
+	}
+	for _, key := range keys[:max(len(keys)-maxPreemptionLog, 0)] {
+		if err := m.store.Delete(preemptionLogBucket, key); err != nil {
+			m.logger.WarnContext(ctx, "Failed to trim preemption log", slog.String("error", err.Error()))
+			return
+		}
+	}
+}
+
+// Preemptions returns up to limit recent preemptions, newest first, only those of or for
+// serviceName when it is set
+func (m *Manager) Preemptions(serviceName string, limit int) (*models.PreemptionsResponse, error) {
+	records, err := m.store.List(preemptionLogBucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read preemption log: %w", err)
+	}
+	keys := slices.Sorted(maps.Keys(records))
+
+	response := &models.PreemptionsResponse{Preemptions: []models.Preemption{}}
+	for i := len(keys) - 1; i >= 0; i-- {
+		if limit > 0 && len(response.Preemptions) >= limit {
+			break
+		}
+		var record models.Preemption
+		if err := json.Unmarshal(records[keys[i]], &record); err != nil {
+			continue
+		}
+		if serviceName == "" || record.ServiceName == serviceName || record.PreemptedFor == serviceName {
+			response.Preemptions = append(response.Preemptions, record)
+		}
+	}
+	return response, nil
+}
@@ -0,0 +1,114 @@
+package container
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestPriorityPreemption(t *testing.T) {
+	invalid := []map[string]interface{}{
+		{"priority": "high"},
+		{"priority": map[string]interface{}{"class": "urgent"}},
+		{"priority": map[string]interface{}{"class": "low", "weight": 3}},
+	}
+	for _, spec := range invalid {
+		if _, err := parsePrioritySpec(spec); err == nil {
+			t.Errorf("Expected priority %v to be rejected", spec["priority"])
+		}
+	}
+	priority, err := parsePrioritySpec(map[string]interface{}{
+		"priority": map[string]interface{}{"class": "low", "preemptible": true},
+	})
+	if err != nil || priority == nil || !priority.Preemptible {
+		t.Fatalf("Expected the priority to be accepted, got %+v, %v", priority, err)
+	}
+
+	now := time.Now()
+	containers := map[string]*models.Container{
+		"old-low":    {ServiceName: "old-low", Status: models.StatusRunning, CreatedAt: now.Add(-time.Hour), Priority: &models.Priority{Class: models.PriorityLow, Preemptible: true}},
+		"new-low":    {ServiceName: "new-low", Status: models.StatusRunning, CreatedAt: now, Priority: &models.Priority{Class: models.PriorityLow, Preemptible: true}},
+		"normal":     {ServiceName: "normal", Status: models.StatusRunning, CreatedAt: now, Priority: &models.Priority{Preemptible: true}},
+		"pinned-low": {ServiceName: "pinned-low", Status: models.StatusRunning, CreatedAt: now, Priority: &models.Priority{Class: models.PriorityLow}},
+		"stopped":    {ServiceName: "stopped", Status: models.StatusStopped, StoppedAt: &now, Priority: &models.Priority{Class: models.PriorityLow, Preemptible: true}},
+	}
+	if victim := preemptionVictim(containers, models.PriorityHigh, "new"); victim == nil || victim.ServiceName != "new-low" {
+		t.Errorf("Expected the newest low-priority container to be preempted, got %+v", victim)
+	}
+	delete(containers, "new-low")
+	delete(containers, "old-low")
+	if victim := preemptionVictim(containers, models.PriorityHigh, "new"); victim == nil || victim.ServiceName != "normal" {
+		t.Errorf("Expected the normal preemptible container to be preempted, got %+v", victim)
+	}
+	if victim := preemptionVictim(containers, models.PriorityNormal, "new"); victim != nil {
+		t.Errorf("Expected nothing to be preempted for a normal create, got %s", victim.ServiceName)
+	}
+
+	manager := newTestManager(&config.Config{
+		Container: config.ContainerConfig{NamePrefix: "test-", MaxContainers: 2},
+		State:     config.StateConfig{Dir: t.TempDir()},
+	})
+	manager.containers = map[string]*models.Container{
+		"a": {ServiceName: "a", Status: models.StatusRunning},
+		"b": {ServiceName: "b", Status: models.StatusPreempted},
+	}
+	if manager.slotsFull() {
+		t.Error("Expected a preempted container to free its slot")
+	}
+
+	ctx := context.Background()
+	for i, service := range []string{"a", "b", "c"} {
+		manager.recordPreemption(ctx, models.Preemption{ServiceName: service, PreemptedFor: "big", PreemptedAt: now.Add(time.Duration(i) * time.Second)})
+	}
+	response, err := manager.Preemptions("", 2)
+	if err != nil {
+		t.Fatalf("Expected the preemption log to be read, got %v", err)
+	}
+	if len(response.Preemptions) != 2 || response.Preemptions[0].ServiceName != "c" {
+		t.Errorf("Expected the two newest preemptions, newest first, got %+v", response.Preemptions)
+	}
+	if response, _ := manager.Preemptions("big", 0); len(response.Preemptions) != 3 {
+		t.Errorf("Expected the preemptions made for big, got %+v", response.Preemptions)
+	}
+	if !manager.store.Has(preemptedBucket, "a") {
+		t.Error("Expected the preempted container to be recorded")
+	}
+}
+
+func TestPreemptionWaitsForValidation(t *testing.T) {
+	// Fake podman succeeds at everything, so a preemption would go through
+	bin := t.TempDir()
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	if err := os.WriteFile(filepath.Join(bin, "podman"), []byte("#!/bin/sh\nexit 0\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	manager := newTestManager(&config.Config{
+		Container: config.ContainerConfig{NamePrefix: "test-", MaxContainers: 1},
+		State:     config.StateConfig{Dir: t.TempDir()},
+	})
+	manager.containers["spot"] = &models.Container{
+		ID:          "abc123",
+		Name:        "test-spot",
+		ServiceName: "spot",
+		Status:      models.StatusRunning,
+		Priority:    &models.Priority{Class: models.PriorityLow, Preemptible: true},
+	}
+	high := &models.Priority{Class: models.PriorityHigh}
+	if count := manager.preemptibleCount("big", high); count != 1 {
+		t.Errorf("Expected the spot instance to count as preemptible, got %d", count)
+	}
+
+	// An instance that fails validation must not cost another instance its slot
+	spec := map[string]interface{}{"priority": map[string]interface{}{"class": "high"}}
+	if err := manager.HandleMCPInstanceCreated(context.Background(), "inst-big", "big", spec); err == nil {
+		t.Fatal("Expected a spec without an image to fail validation")
+	}
+	if status := manager.containers["spot"].Status; status != models.StatusRunning {
+		t.Errorf("Expected the spot instance to keep running, got %s", status)
+	}
+}
@@ -0,0 +1,160 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// filesLabel stores the paths of a container's projected files on the podman container; their
+// content is kept in podman secrets, not in the label
+const filesLabel = "mcp.files"
+
+// maxProjectedFileBytes is the largest content podman accepts for a secret
+const maxProjectedFileBytes = 512000
+
+// parseFilesSpec reads the optional files object from json_spec, mapping container paths to
+// their content. Secret references are resolved by the provider before the spec gets here.
+func parseFilesSpec(jsonSpec map[string]interface{}) (map[string]string, error) {
+	raw, exists := jsonSpec["files"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+	entries, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("files must be an object of container paths to content")
+	}
+	files := make(map[string]string, len(entries))
+	for filePath, value := range entries {
+		content, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("file %s: content must be a string", filePath)
+		}
+		files[filePath] = content
+	}
+	if err := validateFiles(files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// validateFiles checks the paths of projected files; sizes are checked by checkFilesPolicy
+func validateFiles(files map[string]string) error {
+	for filePath := range files {
+		if !strings.HasPrefix(filePath, "/") || path.Clean(filePath) != filePath || filePath == "/" {
+			return fmt.Errorf("file path %q must be an absolute, clean path below /", filePath)
+		}
+		// Commas and equals signs separate the options of podman's --secret flag
+		if strings.ContainsAny(filePath, ",=") {
+			return fmt.Errorf("file path %q must not contain ',' or '='", filePath)
+		}
+	}
+	return nil
+}
+
+// checkFilesPolicy validates projected files and holds each to the file size limit, which cannot
+// exceed what a podman secret holds
+func (m *Manager) checkFilesPolicy(files map[string]string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	if err := validateFiles(files); err != nil {
+		return err
+	}
+	if currentEngine().binary != enginePodman {
+		return fmt.Errorf("files need podman secrets, which the %s engine does not provide", currentEngine().binary)
+	}
+	maxBytes := min(m.config.Container.FileMaxBytes, maxProjectedFileBytes)
+	for filePath, content := range files {
+		if int64(len(content)) > maxBytes {
+			return fmt.Errorf("file %s is %d bytes, over the %d byte limit", filePath, len(content), maxBytes)
+		}
+	}
+	return nil
+}
+
+// projectedFilePaths returns the paths of a container's files in the order their secrets are numbered
+func projectedFilePaths(files map[string]string) []string {
+	return slices.Sorted(maps.Keys(files))
+}
+
+// fileSecretName returns the podman secret holding a container's projected file
+func fileSecretName(containerName string, index int) string {
+	return fmt.Sprintf("%s-file-%d", containerName, index)
+}
+
+// podmanFilesArgs mounts each projected file read-only at its path from its podman secret
+func (m *Manager) podmanFilesArgs(container *models.Container) []string {
+	if len(container.Files) == 0 {
+		return nil
+	}
+	paths := projectedFilePaths(container.Files)
+	var args []string
+	for i, filePath := range paths {
+		args = append(args, "--secret", fmt.Sprintf("%s,type=mount,target=%s,mode=0444", fileSecretName(container.Name, i), filePath))
+	}
+	if data, err := json.Marshal(paths); err == nil {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", filesLabel, data))
+	}
+	return args
+}
+
+// createFileSecrets stores each projected file in a podman secret, replacing the secret of an
+// earlier container of the same name. The caller removes the secrets already created on failure.
+func (m *Manager) createFileSecrets(ctx context.Context, container *models.Container) error {
+	for i, filePath := range projectedFilePaths(container.Files) {
+		name := fileSecretName(container.Name, i)
+		cmd := podmanCommand(ctx, m.logger, "secret", "create", "--replace",
+			"--label", fmt.Sprintf("%s=%s", filesLabel, container.Name), name, "-")
+		cmd.Stdin = strings.NewReader(container.Files[filePath])
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to store file %s: %w: %s", filePath, err, strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
+
+// removeFileSecrets deletes the podman secrets of a container's projected files
+func (m *Manager) removeFileSecrets(ctx context.Context, container *models.Container) {
+	for i := range len(container.Files) {
+		name := fileSecretName(container.Name, i)
+		if output, err := podmanCommand(ctx, m.logger, "secret", "rm", name).CombinedOutput(); err != nil &&
+			!strings.Contains(strings.ToLower(string(output)), "no such secret") {
+			m.logger.WarnContext(ctx, "Failed to remove file secret",
+				slog.String("secret", name),
+				slog.String("error", err.Error()),
+				slog.String("output", string(output)))
+		}
+	}
+}
+
+// discoverFiles restores the projected files of a podman container from the label listing their
+// paths and the secrets holding their content
+func (m *Manager) discoverFiles(ctx context.Context, containerID, containerName string) map[string]string {
+	var paths []string
+	m.discoverJSONLabel(ctx, containerID, filesLabel, &paths)
+	if len(paths) == 0 {
+		return nil
+	}
+	files := make(map[string]string, len(paths))
+	for i, filePath := range paths {
+		name := fileSecretName(containerName, i)
+		output, err := podmanCommand(ctx, m.logger, "secret", "inspect", "--showsecret", "--format", "{{.SecretData}}", name).Output()
+		if err != nil {
+			m.logger.WarnContext(ctx, "Failed to read file secret",
+				slog.String("secret", name),
+				slog.String("error", err.Error()))
+			continue
+		}
+		// The template output ends with a newline the secret does not hold
+		files[filePath] = strings.TrimSuffix(string(output), "\n")
+	}
+	return files
+}
@@ -0,0 +1,52 @@
+package container
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestProjectedFiles(t *testing.T) {
+	manager := newTestManager(&config.Config{
+		Container: config.ContainerConfig{NamePrefix: "test-", FileMaxBytes: 8},
+		State:     config.StateConfig{Dir: t.TempDir()},
+	})
+
+	files, err := parseFilesSpec(map[string]interface{}{
+		"files": map[string]interface{}{"/etc/b.yaml": "b: 1", "/config/a.json": "{}"},
+	})
+	if err != nil {
+		t.Fatalf("Expected files to parse, got %v", err)
+	}
+	if err := manager.checkFilesPolicy(files); err != nil {
+		t.Errorf("Expected files within the limit to pass, got %v", err)
+	}
+
+	args := strings.Join(manager.podmanFilesArgs(&models.Container{Name: "test-search", Files: files}), " ")
+	for _, expected := range []string{
+		"--secret test-search-file-0,type=mount,target=/config/a.json,mode=0444",
+		"--secret test-search-file-1,type=mount,target=/etc/b.yaml,mode=0444",
+		`--label mcp.files=["/config/a.json","/etc/b.yaml"]`,
+	} {
+		if !strings.Contains(args, expected) {
+			t.Errorf("Expected %q in %s", expected, args)
+		}
+	}
+
+	for _, spec := range []interface{}{
+		map[string]interface{}{"config/a.json": "{}"},
+		map[string]interface{}{"/config/../a.json": "{}"},
+		map[string]interface{}{"/config/a,b.json": "{}"},
+		map[string]interface{}{"/config/a.json": 1},
+		[]interface{}{"/config/a.json"},
+	} {
+		if _, err := parseFilesSpec(map[string]interface{}{"files": spec}); err == nil {
+			t.Errorf("Expected files %v to be rejected", spec)
+		}
+	}
+	if err := manager.checkFilesPolicy(map[string]string{"/config/a.json": "123456789"}); err == nil {
+		t.Error("Expected a file over FILES_MAX_BYTES to be rejected")
+	}
+}
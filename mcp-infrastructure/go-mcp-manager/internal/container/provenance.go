@@ -0,0 +1,236 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// provenanceLabel stores where each field of a container's spec came from
+	provenanceLabel = "mcp.provenance"
+	// maskedValue replaces values that may be credentials
+	maskedValue = "***"
+)
+
+// managedEnvironment are the variables the manager injects into every container
+var managedEnvironment = []string{"MCP_INSTANCE_ID", "MCP_SERVICE_NAME", "MCP_CONTAINER_PORT"}
+
+// runtimeSpecFields describe a container's current state rather than its spec
+var runtimeSpecFields = []string{"id", "status", "created_at", "updated_at", "stopped_at", "spec_hash", "provenance"}
+
+// managerSpecFields are always chosen by the manager
+var managerSpecFields = []string{"name", "service_name", "slug", "url", "host", "network", "gpu_devices", "upstream_tls", "platform", "emulated"}
+
+// requestedSpec records which fields a create request set, before runtime shortcuts, source
+// builds and defaults fill in the rest
+type requestedSpec struct {
+	fields      map[string]bool
+	environment map[string]bool
+	resources   *models.ResourceLimits
+}
+
+// requestedFromRequest records the fields an API create request set
+func requestedFromRequest(req models.CreateContainerRequest) requestedSpec {
+	var fields map[string]interface{}
+	if data, err := json.Marshal(req); err == nil {
+		_ = json.Unmarshal(data, &fields)
+	}
+	requested := requestedFields(fields)
+	requested.resources = requestedResources(&req)
+	return requested
+}
+
+// requestedFromJSONSpec records the fields an instance's json_spec set
+func requestedFromJSONSpec(jsonSpec map[string]interface{}) requestedSpec {
+	requested := requestedFields(jsonSpec)
+	// json_spec names the command "cmd"
+	if requested.fields["cmd"] {
+		requested.fields["command"] = true
+	}
+	requested.resources, _ = parseResourcesSpec(jsonSpec)
+	return requested
+}
+
+// requestedFields returns the fields of a decoded request that hold a non-zero value
+func requestedFields(fields map[string]interface{}) requestedSpec {
+	requested := requestedSpec{fields: make(map[string]bool), environment: make(map[string]bool)}
+	for field, value := range fields {
+		if value != nil && !reflect.ValueOf(value).IsZero() {
+			requested.fields[field] = true
+		}
+	}
+	if env, ok := fields["environment"].(map[string]interface{}); ok {
+		for key := range env {
+			requested.environment[key] = true
+		}
+	}
+	return requested
+}
+
+// specProvenance maps each field of container's spec, and each of its environment variables and
+// resource limits, to whether the request, a runtime or source template, a manager default or
+// the manager itself set it
+func specProvenance(requested requestedSpec, container *models.Container) map[string]string {
+	spec := specFields(container)
+	provenance := make(map[string]string, len(spec))
+	for field := range spec {
+		switch {
+		case field == "environment" || field == "resources":
+			// Annotated per variable and per limit below
+		case requested.fields[field]:
+			provenance[field] = models.ProvenanceRequest
+		case slices.Contains(managerSpecFields, field):
+			provenance[field] = models.ProvenanceManager
+		case container.Runtime != nil && (field == "image" || field == "command" || field == "health_check" || field == "route"):
+			provenance[field] = models.ProvenanceTemplate
+		case container.Source != nil && field == "image":
+			provenance[field] = models.ProvenanceTemplate
+		default:
+			provenance[field] = models.ProvenanceDefault
+		}
+	}
+
+	for key := range container.Environment {
+		field := "environment." + key
+		switch {
+		case slices.Contains(managedEnvironment, key):
+			provenance[field] = models.ProvenanceManager
+		case requested.environment[key]:
+			provenance[field] = models.ProvenanceRequest
+		default:
+			// Filled from an env_schema default
+			provenance[field] = models.ProvenanceDefault
+		}
+	}
+
+	if resources := container.Resources; resources != nil {
+		requestedLimits := requested.resources
+		if requestedLimits == nil {
+			requestedLimits = &models.ResourceLimits{}
+		}
+		for field, values := range map[string][2]string{
+			"memory":            {resources.Memory, requestedLimits.Memory},
+			"cpu":               {resources.CPU, requestedLimits.CPU},
+			"ephemeral_storage": {resources.EphemeralStorage, requestedLimits.EphemeralStorage},
+			"pids_limit":        {fmt.Sprint(resources.PidsLimit), fmt.Sprint(requestedLimits.PidsLimit)},
+		} {
+			if values[0] == "" || values[0] == "0" {
+				continue
+			}
+			provenance["resources."+field] = models.ProvenanceDefault
+			if values[1] != "" && values[1] != "0" {
+				provenance["resources."+field] = models.ProvenanceRequest
+			}
+		}
+	}
+	return provenance
+}
+
+// specFields returns the container as JSON fields, without the ones describing its current state
+func specFields(container *models.Container) map[string]interface{} {
+	var fields map[string]interface{}
+	data, err := json.Marshal(container)
+	if err != nil || json.Unmarshal(data, &fields) != nil {
+		return map[string]interface{}{}
+	}
+	for _, field := range runtimeSpecFields {
+		delete(fields, field)
+	}
+	return fields
+}
+
+// ContainerSpec returns the effective spec of a container with values that may be credentials
+// masked, along with where each field came from
+func (m *Manager) ContainerSpec(serviceName string) (*models.ContainerSpecResponse, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	container, exists := m.containers[serviceName]
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+
+	masked := maskContainer(container)
+	provenance := container.Provenance
+	if provenance == nil {
+		provenance = map[string]string{}
+	}
+	return &models.ContainerSpecResponse{
+		ServiceName: serviceName,
+		Spec:        specFields(&masked),
+		Provenance:  provenance,
+	}, nil
+}
+
+// maskContainer returns a copy of container with values that may be credentials masked
+func maskContainer(container *models.Container) models.Container {
+	masked := *container
+	masked.Environment = maskEnvironment(container.Environment, container.EnvSchema)
+	if route := container.Route; route != nil {
+		routeCopy := *route
+		routeCopy.RequestHeaders = maskAll(route.RequestHeaders)
+		if route.Auth != nil && route.Auth.Token != "" {
+			auth := *route.Auth
+			auth.Token = maskedValue
+			routeCopy.Auth = &auth
+		}
+		masked.Route = &routeCopy
+	}
+	if initSpec := container.Init; initSpec != nil {
+		initCopy := *initSpec
+		initCopy.Environment = maskAll(initSpec.Environment)
+		masked.Init = &initCopy
+	}
+	masked.Proxy = maskProxy(container.Proxy)
+	masked.Files = maskAll(container.Files)
+	return masked
+}
+
+// maskEnvironment masks every value except those the manager injects, secret references, which
+// name a secret without revealing it, and variables env_schema declares as not secret
+func maskEnvironment(environment map[string]string, schema []models.EnvVarSpec) map[string]string {
+	if environment == nil {
+		return nil
+	}
+	public := make(map[string]bool, len(schema))
+	for _, variable := range schema {
+		public[variable.Name] = !variable.Secret
+	}
+	masked := make(map[string]string, len(environment))
+	for key, value := range environment {
+		if slices.Contains(managedEnvironment, key) || public[key] || strings.HasPrefix(value, "secret_ref:") {
+			masked[key] = value
+		} else {
+			masked[key] = maskedValue
+		}
+	}
+	return masked
+}
+
+// maskAll masks every value of values
+func maskAll(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	masked := maps.Clone(values)
+	for key := range masked {
+		masked[key] = maskedValue
+	}
+	return masked
+}
+
+// discoverProvenance restores the spec provenance persisted on a podman container
+func (m *Manager) discoverProvenance(ctx context.Context, containerID string) map[string]string {
+	var provenance map[string]string
+	if !m.discoverJSONLabel(ctx, containerID, provenanceLabel, &provenance) {
+		return nil
+	}
+	return provenance
+}
@@ -0,0 +1,72 @@
+package container
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestContainerSpec(t *testing.T) {
+	cfg := &config.Config{Container: config.ContainerConfig{NamePrefix: "mcp-"}}
+	manager := newTestManager(cfg)
+
+	jsonSpec := map[string]interface{}{
+		"runtime":     map[string]interface{}{"type": "npx", "package": "server-memory"},
+		"environment": map[string]interface{}{"API_KEY": "sk-live", "REGION": "eu", "TOKEN": "secret_ref:token"},
+		"resources":   map[string]interface{}{"memory": "256m"},
+	}
+	container := &models.Container{
+		Name:        "mcp-memory",
+		ServiceName: "memory",
+		Slug:        "memory-ab12",
+		Image:       "bridge:latest",
+		Port:        8000,
+		Command:     []string{"--stdio", "npx -y 'server-memory'"},
+		Runtime:     &models.RuntimeConfig{Type: models.RuntimeNpx, Package: "server-memory"},
+		Environment: map[string]string{"API_KEY": "sk-live", "REGION": "eu", "TOKEN": "secret_ref:token", "LOG_LEVEL": "info", "MCP_INSTANCE_ID": "inst-1"},
+		EnvSchema:   []models.EnvVarSpec{{Name: "REGION"}, {Name: "LOG_LEVEL", Default: "info"}, {Name: "API_KEY", Secret: true}},
+		Resources:   &models.ResourceLimits{Memory: "256m", CPU: "1"},
+		Route:       &models.RouteConfig{RequestHeaders: map[string]string{"X-Api-Key": "sk-header"}},
+	}
+	container.Provenance = specProvenance(requestedFromJSONSpec(jsonSpec), container)
+	manager.containers["memory"] = container
+
+	spec, err := manager.ContainerSpec("memory")
+	if err != nil {
+		t.Fatalf("Expected the spec, got %v", err)
+	}
+	expected := map[string]string{
+		"image":                       models.ProvenanceTemplate,
+		"command":                     models.ProvenanceTemplate,
+		"runtime":                     models.ProvenanceRequest,
+		"port":                        models.ProvenanceDefault,
+		"slug":                        models.ProvenanceManager,
+		"environment.API_KEY":         models.ProvenanceRequest,
+		"environment.LOG_LEVEL":       models.ProvenanceDefault,
+		"environment.MCP_INSTANCE_ID": models.ProvenanceManager,
+		"resources.memory":            models.ProvenanceRequest,
+		"resources.cpu":               models.ProvenanceDefault,
+	}
+	for field, source := range expected {
+		if spec.Provenance[field] != source {
+			t.Errorf("Expected %s to come from %s, got %q", field, source, spec.Provenance[field])
+		}
+	}
+
+	data, _ := json.Marshal(spec)
+	for _, secret := range []string{"sk-live", "sk-header"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Expected %s to be masked, got %s", secret, data)
+		}
+	}
+	environment := spec.Spec["environment"].(map[string]interface{})
+	if environment["REGION"] != "eu" || environment["TOKEN"] != "secret_ref:token" || environment["MCP_INSTANCE_ID"] != "inst-1" {
+		t.Errorf("Expected public values and secret references to be shown, got %v", environment)
+	}
+	if _, exists := spec.Spec["status"]; exists {
+		t.Error("Expected the container's state to be left out of its spec")
+	}
+}
@@ -0,0 +1,146 @@
+package container
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// defaultProxyAuthUsername is the basic auth user name when a route does not set one
+const defaultProxyAuthUsername = "mcp"
+
+// Bounds accepted for proxy access tokens
+const (
+	minProxyTokenLength = 16
+	maxProxyTokenLength = 256
+	proxyTokenBytes     = 32
+)
+
+// validateRouteAuth checks the authentication scheme and any token supplied with a route
+func validateRouteAuth(auth *models.RouteAuth) error {
+	if auth == nil {
+		return nil
+	}
+
+	switch auth.Type {
+	case models.ProxyAuthBearer:
+		if auth.Username != "" {
+			return fmt.Errorf("route.auth.username requires route.auth.type %q", models.ProxyAuthBasic)
+		}
+	case models.ProxyAuthBasic:
+		if strings.ContainsAny(auth.Username, ":\r\n") {
+			return fmt.Errorf("route.auth.username must not contain colons or line breaks")
+		}
+	default:
+		return fmt.Errorf("route.auth.type must be %q or %q", models.ProxyAuthBearer, models.ProxyAuthBasic)
+	}
+
+	if auth.Token != "" {
+		if len(auth.Token) < minProxyTokenLength || len(auth.Token) > maxProxyTokenLength {
+			return fmt.Errorf("route.auth.token must be between %d and %d characters", minProxyTokenLength, maxProxyTokenLength)
+		}
+		for _, r := range auth.Token {
+			if r <= 0x20 || r > 0x7e {
+				return fmt.Errorf("route.auth.token must be printable ASCII without spaces")
+			}
+		}
+	}
+	return nil
+}
+
+// withProxyToken returns route with a generated access token when it asks for authentication without
+// one. The route is copied rather than modified, as callers may share it with the request.
+func withProxyToken(route *models.RouteConfig) (*models.RouteConfig, error) {
+	if route == nil || route.Auth == nil || route.Auth.Token != "" {
+		return route, nil
+	}
+
+	token := make([]byte, proxyTokenBytes)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	withToken := *route
+	auth := *route.Auth
+	auth.Token = base64.RawURLEncoding.EncodeToString(token)
+	withToken.Auth = &auth
+	return &withToken, nil
+}
+
+// proxyAuthPath returns the manager path the proxy asks whether a request to a route is authorized
+func proxyAuthPath(slug string) string {
+	return "/proxy/auth/" + slug
+}
+
+// proxyAuthUsername returns the basic auth user name of a route
+func proxyAuthUsername(auth *models.RouteAuth) string {
+	if auth.Username != "" {
+		return auth.Username
+	}
+	return defaultProxyAuthUsername
+}
+
+// proxyAuthorized reports whether an Authorization header carries the route's token in its scheme
+func proxyAuthorized(auth *models.RouteAuth, header string) bool {
+	scheme, credentials, found := strings.Cut(header, " ")
+	if !found {
+		return false
+	}
+	credentials = strings.TrimSpace(credentials)
+
+	switch auth.Type {
+	case models.ProxyAuthBearer:
+		if !strings.EqualFold(scheme, "Bearer") {
+			return false
+		}
+		return subtle.ConstantTimeCompare([]byte(credentials), []byte(auth.Token)) == 1
+	case models.ProxyAuthBasic:
+		if !strings.EqualFold(scheme, "Basic") {
+			return false
+		}
+		decoded, err := base64.StdEncoding.DecodeString(credentials)
+		if err != nil {
+			return false
+		}
+		username, password, found := strings.Cut(string(decoded), ":")
+		if !found {
+			return false
+		}
+		userMatches := subtle.ConstantTimeCompare([]byte(username), []byte(proxyAuthUsername(auth)))
+		passwordMatches := subtle.ConstantTimeCompare([]byte(password), []byte(auth.Token))
+		return userMatches&passwordMatches == 1
+	default:
+		return false
+	}
+}
+
+// AuthorizeProxyRequest checks the Authorization header of a request the proxy received for the
+// route with slug. It returns the route's scheme for the challenge of a rejected request; a route
+// without authentication admits every request.
+func (m *Manager) AuthorizeProxyRequest(slug, authorization string) (scheme string, authorized bool) {
+	m.mutex.RLock()
+	var auth *models.RouteAuth
+	found := false
+	for _, container := range m.containers {
+		if container.Slug == slug {
+			found = true
+			if container.Route != nil {
+				auth = container.Route.Auth
+			}
+			break
+		}
+	}
+	m.mutex.RUnlock()
+
+	if !found {
+		return models.ProxyAuthBearer, false
+	}
+	if auth == nil {
+		return "", true
+	}
+	return auth.Type, proxyAuthorized(auth, authorization)
+}
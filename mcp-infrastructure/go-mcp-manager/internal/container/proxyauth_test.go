@@ -0,0 +1,83 @@
+package container
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestProxyAuth(t *testing.T) {
+	invalid := []*models.RouteAuth{
+		{Type: "digest"},
+		{Type: models.ProxyAuthBearer, Username: "alice"},
+		{Type: models.ProxyAuthBasic, Username: "al:ice"},
+		{Type: models.ProxyAuthBearer, Token: "short"},
+		{Type: models.ProxyAuthBearer, Token: "has a space in the token"},
+	}
+	for _, auth := range invalid {
+		if err := validateRouteConfig(&models.RouteConfig{Auth: auth}); err == nil {
+			t.Errorf("Expected auth %+v to be rejected", auth)
+		}
+	}
+
+	// A token is generated without touching the route it was requested on
+	requested := &models.RouteConfig{Auth: &models.RouteAuth{Type: models.ProxyAuthBearer}}
+	route, err := withProxyToken(requested)
+	if err != nil {
+		t.Fatalf("Expected a token to be generated, got %v", err)
+	}
+	if len(route.Auth.Token) < minProxyTokenLength || requested.Auth.Token != "" {
+		t.Errorf("Expected a generated token on a copy of the route, got %q and %q", route.Auth.Token, requested.Auth.Token)
+	}
+	if err := validateRouteConfig(route); err != nil {
+		t.Errorf("Expected the generated token to be valid, got %v", err)
+	}
+
+	middlewares, chain := buildRouteMiddlewares("svc-abc", route, nil, nil, "http://localhost:8000/")
+	expected := []string{"mcp-svc-abc-auth", "mcp-svc-abc-headers", "mcp-svc-abc-stripprefix"}
+	if strings.Join(chain, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected middlewares %v, got %v", expected, chain)
+	}
+	if fa := middlewares[authMiddleware].ForwardAuth; fa == nil || fa.Address != "http://localhost:8000/proxy/auth/svc-abc" {
+		t.Errorf("Expected the token to be checked by the manager, got %+v", fa)
+	}
+	if value, exists := middlewares[headersMiddleware].Headers.CustomRequestHeaders["Authorization"]; !exists || value != "" {
+		t.Error("Expected the token to be stripped before the request reaches the container")
+	}
+
+	manager := newTestManager(&config.Config{})
+	manager.containers["github"] = &models.Container{ServiceName: "github", Slug: "github-ab12", Route: route}
+	manager.containers["slack"] = &models.Container{ServiceName: "slack", Slug: "slack-cd34", Route: &models.RouteConfig{
+		Auth: &models.RouteAuth{Type: models.ProxyAuthBasic, Token: "0123456789abcdef"},
+	}}
+	manager.containers["open"] = &models.Container{ServiceName: "open", Slug: "open-ef56"}
+
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte("mcp:0123456789abcdef"))
+	cases := []struct {
+		slug, header string
+		authorized   bool
+	}{
+		{"github-ab12", "Bearer " + route.Auth.Token, true},
+		{"github-ab12", "bearer " + route.Auth.Token, true},
+		{"github-ab12", "Bearer wrong-token-0123456789", false},
+		{"github-ab12", "", false},
+		{"slack-cd34", basic, true},
+		{"slack-cd34", "Basic " + base64.StdEncoding.EncodeToString([]byte("root:0123456789abcdef")), false},
+		{"slack-cd34", "Bearer 0123456789abcdef", false},
+		{"open-ef56", "", true},
+		{"unknown-0000", "Bearer " + route.Auth.Token, false},
+	}
+	for _, c := range cases {
+		if _, authorized := manager.AuthorizeProxyRequest(c.slug, c.header); authorized != c.authorized {
+			t.Errorf("Expected authorized=%v for %s with %q, got %v", c.authorized, c.slug, c.header, authorized)
+		}
+	}
+
+	contract, err := manager.GetConnectionContract("slack")
+	if err != nil || contract.Auth == nil || contract.Auth.Username != "mcp" || contract.Auth.Token != "0123456789abcdef" {
+		t.Errorf("Expected the connection contract to carry the credentials, got %+v, %v", contract, err)
+	}
+}
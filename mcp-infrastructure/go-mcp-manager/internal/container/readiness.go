@@ -0,0 +1,121 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// defaultReadinessPath is the conventional endpoint of servers on the streamable HTTP transport
+const defaultReadinessPath = "/mcp"
+
+// probeReadiness initializes an MCP session with a live container over streamable HTTP and ends
+// it again. Servers whose MCP endpoint is not known, or that skip readiness, are ready once they
+// pass the health check.
+func (h *HealthChecker) probeReadiness(ctx context.Context, container *models.Container, host string, port int) error {
+	path := readinessPath(container)
+	if path == "" {
+		return nil
+	}
+	return h.mcpHandshake(ctx, container, host, port, path)
+}
+
+// readinessPath returns the endpoint the readiness handshake uses: health_check.readiness_path,
+// or /mcp for a route declared as streamable HTTP. It is empty when readiness is skipped or the
+// server's MCP endpoint is not known, as for the plain HTTP, SSE and WebSocket transports.
+func readinessPath(container *models.Container) string {
+	if hc := container.HealthCheck; hc != nil {
+		if hc.SkipReadiness {
+			return ""
+		}
+		if hc.ReadinessPath != "" {
+			return hc.ReadinessPath
+		}
+	}
+	if container.Route != nil && container.Route.Transport == models.TransportStreamableHTTP {
+		return defaultReadinessPath
+	}
+	return ""
+}
+
+// mcpHandshake initializes an MCP session on path, /mcp if empty, and closes it again
+func (h *HealthChecker) mcpHandshake(ctx context.Context, container *models.Container, host string, port int, path string) error {
+	if path == "" {
+		path = defaultReadinessPath
+	}
+	ctx, cancel := context.WithTimeout(ctx, healthTimeout(container.HealthCheck))
+	defer cancel()
+
+	session := &mcpSession{
+		client:   h.httpClient,
+		endpoint: fmt.Sprintf("%s://%s:%d%s", upstreamScheme(container), host, port, path),
+	}
+	defer session.close(ctx)
+	if _, err := session.call(ctx, 1, "initialize", map[string]interface{}{
+		"protocolVersion": mcpProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]string{"name": "mcp-manager-readiness", "version": "1.0"},
+	}); err != nil {
+		return fmt.Errorf("MCP initialize on %s failed: %w", path, err)
+	}
+	return nil
+}
+
+// applyReadinessUnsafe tracks whether a container is ready, debounced by its health thresholds,
+// and with READINESS_GATES_ROUTES registers its route once it is ready and removes it once it is
+// not. Caller must hold m.mutex and have applied the health thresholds of the result.
+func (m *Manager) applyReadinessUnsafe(container *models.Container, result *HealthCheckResult) {
+	counters := m.healthCounters[container.Name]
+	if result.Ready {
+		counters.readySuccesses++
+		counters.readyFailures = 0
+	} else {
+		counters.readyFailures++
+		counters.readySuccesses = 0
+	}
+	healthyThreshold, unhealthyThreshold := healthThresholds(container.HealthCheck)
+
+	switch {
+	case !container.Ready && result.Ready && counters.readySuccesses >= healthyThreshold:
+		container.Ready = true
+		m.recordContainerEvent(container, InstanceEventNormal, "Ready", "The server answers the MCP handshake")
+		m.gateRouteUnsafe(container, true)
+	case container.Ready && !result.Ready && counters.readyFailures >= unhealthyThreshold:
+		container.Ready = false
+		m.recordContainerEvent(container, InstanceEventWarning, "NotReady", result.ReadinessError)
+		m.gateRouteUnsafe(container, false)
+	}
+}
+
+// gateRouteUnsafe registers or removes the route of a container as its readiness changed.
+// Caller must hold m.mutex.
+func (m *Manager) gateRouteUnsafe(container *models.Container, ready bool) {
+	if !m.config.HealthMonitor.ReadinessGatesRoutes || container.Slug == "" || container.StoppedAt != nil {
+		return
+	}
+	ctx := m.healthCtx
+
+	if ready {
+		if _, err := m.refreshRoute(ctx, container); err != nil {
+			m.logger.WarnContext(ctx, "Failed to register route of ready container",
+				slog.String("service", container.ServiceName),
+				slog.String("slug", container.Slug),
+				slog.String("error", err.Error()))
+		}
+		return
+	}
+	if err := m.traefikManager.RemoveMCPService(ctx, container.Slug); err != nil {
+		m.logger.WarnContext(ctx, "Failed to remove route of container that is not ready",
+			slog.String("service", container.ServiceName),
+			slog.String("slug", container.Slug),
+			slog.String("error", err.Error()))
+	}
+}
+
+// routesAwaitReadiness reports whether new and restarted containers are routed only once the health
+// monitor finds them ready
+func (m *Manager) routesAwaitReadiness() bool {
+	return m.config.HealthMonitor.ReadinessGatesRoutes
+}
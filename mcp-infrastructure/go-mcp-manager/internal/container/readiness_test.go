@@ -0,0 +1,83 @@
+package container
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestReadiness(t *testing.T) {
+	var deleted bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodDelete:
+			deleted = r.Header.Get("Mcp-Session-Id") == "session-1"
+		case r.URL.Path == "/mcp":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Mcp-Session-Id", "session-1")
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-03-26"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	addr := server.Listener.Addr().(*net.TCPAddr)
+	host, port := addr.IP.String(), addr.Port
+
+	checker := NewHealthChecker(testLogger())
+	container := &models.Container{Name: "mcp-github", ServiceName: "github", Status: models.StatusRunning}
+	if path := readinessPath(container); path != "" {
+		t.Errorf("Expected no handshake for a server whose MCP endpoint is not known, got %q", path)
+	}
+	container.Route = &models.RouteConfig{Transport: models.TransportStreamableHTTP}
+	if err := checker.probeReadiness(context.Background(), container, host, port); err != nil {
+		t.Errorf("Expected the handshake to succeed, got %v", err)
+	}
+	if !deleted {
+		t.Errorf("Expected the probe to close its session")
+	}
+	container.HealthCheck = &models.HealthCheckConfig{ReadinessPath: "/rpc"}
+	if err := checker.probeReadiness(context.Background(), container, host, port); err == nil {
+		t.Errorf("Expected the handshake on a missing path to fail")
+	}
+	container.HealthCheck.SkipReadiness = true
+	if err := checker.probeReadiness(context.Background(), container, host, port); err != nil {
+		t.Errorf("Expected skip_readiness to skip the handshake, got %v", err)
+	}
+
+	cfg := &config.Config{State: config.StateConfig{Dir: t.TempDir()}}
+	cfg.HealthMonitor.ReadinessGatesRoutes = true
+	manager := newTestManager(cfg)
+	container.HealthCheck = &models.HealthCheckConfig{HealthyThreshold: 2, UnhealthyThreshold: 1}
+	manager.containers["github"] = container
+	manager.healthCounters[container.Name] = &healthCounters{}
+
+	ready := &HealthCheckResult{Healthy: true, Live: true, Ready: true}
+	manager.applyReadinessUnsafe(container, ready)
+	if container.Ready {
+		t.Errorf("Expected readiness to wait for the healthy threshold")
+	}
+	manager.applyReadinessUnsafe(container, ready)
+	if !container.Ready {
+		t.Errorf("Expected the container to be ready after two passing handshakes")
+	}
+	failed := &HealthCheckResult{Healthy: true, Live: true, ReadinessError: "MCP initialize on /mcp failed"}
+	manager.applyReadinessUnsafe(container, failed)
+	if container.Ready {
+		t.Errorf("Expected the container to stop being ready after a failed handshake")
+	}
+
+	// A container discovered after a restart keeps its route until failures reach the threshold
+	container.Ready = true
+	container.HealthCheck.UnhealthyThreshold = 2
+	manager.healthCounters[container.Name] = &healthCounters{}
+	manager.applyReadinessUnsafe(container, failed)
+	if !container.Ready {
+		t.Errorf("Expected a single failed handshake not to unroute a discovered container")
+	}
+}
@@ -0,0 +1,153 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// resourcesLabel stores a container's effective resource limits on the podman container
+const resourcesLabel = "mcp.resources"
+
+// parseResourcesSpec reads the optional resources object from json_spec.
+// memory_limit and cpu_limit are accepted as older spellings of memory and cpu.
+func parseResourcesSpec(jsonSpec map[string]interface{}) (*models.ResourceLimits, error) {
+	raw, exists := jsonSpec["resources"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+
+	spec, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("resources must be an object")
+	}
+
+	limits := &models.ResourceLimits{}
+	stringFields := []struct {
+		names  []string
+		target *string
+	}{
+		{[]string{"memory", "memory_limit"}, &limits.Memory},
+		{[]string{"cpu", "cpu_limit"}, &limits.CPU},
+		{[]string{"ephemeral_storage"}, &limits.EphemeralStorage},
+	}
+	for _, field := range stringFields {
+		for _, name := range field.names {
+			value, exists := spec[name]
+			if !exists {
+				continue
+			}
+			switch v := value.(type) {
+			case string:
+				*field.target = v
+			case float64:
+				// CPU counts are commonly written as bare numbers
+				*field.target = strconv.FormatFloat(v, 'f', -1, 64)
+			default:
+				return nil, fmt.Errorf("resources.%s must be a string", name)
+			}
+		}
+	}
+
+	if value, exists := spec["pids_limit"]; exists {
+		n, ok := value.(float64)
+		if !ok || n != float64(int(n)) || n < 1 {
+			return nil, fmt.Errorf("resources.pids_limit must be a positive integer")
+		}
+		limits.PidsLimit = int(n)
+	}
+
+	// Formats are checked here; quotas are enforced by the manager's policy on create
+	if err := (config.ContainerConfig{}).ValidateResources(limits); err != nil {
+		return nil, fmt.Errorf("invalid resources: %w", err)
+	}
+
+	return limits, nil
+}
+
+// requestedResources merges a create request's resources with the legacy memory_limit and cpu_limit fields
+func requestedResources(req *models.CreateContainerRequest) *models.ResourceLimits {
+	if req.Resources == nil && req.MemoryLimit == "" && req.CPULimit == "" {
+		return nil
+	}
+
+	limits := models.ResourceLimits{}
+	if req.Resources != nil {
+		limits = *req.Resources
+	}
+	if limits.Memory == "" {
+		limits.Memory = req.MemoryLimit
+	}
+	if limits.CPU == "" {
+		limits.CPU = req.CPULimit
+	}
+	return &limits
+}
+
+// resolveResources applies the manager defaults to requested limits and checks them against the quota policy
+func (m *Manager) resolveResources(requested *models.ResourceLimits) (*models.ResourceLimits, error) {
+	if err := m.config.Container.ValidateResources(requested); err != nil {
+		return nil, fmt.Errorf("invalid resources: %w", err)
+	}
+
+	resolved := models.ResourceLimits{}
+	if requested != nil {
+		resolved = *requested
+	}
+	if resolved.Memory == "" {
+		resolved.Memory = m.config.Container.DefaultMemoryLimit
+	}
+	if resolved.CPU == "" {
+		resolved.CPU = m.config.Container.DefaultCPULimit
+	}
+	if resolved.PidsLimit == 0 {
+		resolved.PidsLimit = m.config.Container.DefaultPidsLimit
+	}
+
+	if resolved == (models.ResourceLimits{}) {
+		return nil, nil
+	}
+	return &resolved, nil
+}
+
+// podmanResourceArgs converts resource limits to podman run flags
+func podmanResourceArgs(limits *models.ResourceLimits) []string {
+	if limits == nil {
+		return nil
+	}
+
+	var args []string
+	if limits.Memory != "" {
+		// Normalise to bytes so Kubernetes-style sizes are understood by podman
+		if bytes, err := config.ParseMemory(limits.Memory); err == nil {
+			args = append(args, "--memory", strconv.FormatInt(bytes, 10))
+		}
+	}
+	if limits.CPU != "" {
+		if cpus, err := config.ParseCPU(limits.CPU); err == nil {
+			args = append(args, "--cpus", strconv.FormatFloat(cpus, 'f', -1, 64))
+		}
+	}
+	if limits.PidsLimit > 0 {
+		args = append(args, "--pids-limit", strconv.Itoa(limits.PidsLimit))
+	}
+	if limits.EphemeralStorage != "" {
+		// Requires a storage driver with quota support, such as overlay on xfs with pquota
+		if bytes, err := config.ParseMemory(limits.EphemeralStorage); err == nil {
+			args = append(args, "--storage-opt", fmt.Sprintf("size=%d", bytes))
+		}
+	}
+	return args
+}
+
+// discoverResources restores the resource limits persisted on a podman container
+func (m *Manager) discoverResources(ctx context.Context, containerID string) *models.ResourceLimits {
+	var limits models.ResourceLimits
+	if !m.discoverJSONLabel(ctx, containerID, resourcesLabel, &limits) {
+		return nil
+	}
+	return &limits
+}
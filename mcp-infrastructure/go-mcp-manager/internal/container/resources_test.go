@@ -0,0 +1,52 @@
+package container
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestResourceLimits(t *testing.T) {
+	cfg := &config.Config{
+		Container: config.ContainerConfig{
+			DefaultMemoryLimit: "512m",
+			DefaultCPULimit:    "1.0",
+			DefaultPidsLimit:   256,
+			MaxMemoryLimit:     "2g",
+			MaxCPULimit:        "2",
+		},
+	}
+	manager := newTestManager(cfg)
+
+	requested, err := parseResourcesSpec(map[string]interface{}{
+		"resources": map[string]interface{}{"memory": "1Gi", "cpu": "500m", "ephemeral_storage": "2g"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse resources: %v", err)
+	}
+
+	resolved, err := manager.resolveResources(requested)
+	if err != nil {
+		t.Fatalf("Failed to resolve resources: %v", err)
+	}
+	if resolved.Memory != "1Gi" || resolved.CPU != "500m" || resolved.PidsLimit != 256 {
+		t.Errorf("Expected requested limits with default pids limit, got %+v", resolved)
+	}
+
+	args := strings.Join(podmanResourceArgs(resolved), " ")
+	expected := "--memory 1073741824 --cpus 0.5 --pids-limit 256 --storage-opt size=2147483648"
+	if args != expected {
+		t.Errorf("Expected %q, got %q", expected, args)
+	}
+
+	if _, err := manager.resolveResources(&models.ResourceLimits{Memory: "4g"}); err == nil {
+		t.Errorf("Expected memory above the quota to be rejected")
+	}
+	if _, err := parseResourcesSpec(map[string]interface{}{
+		"resources": map[string]interface{}{"memory": "lots"},
+	}); err == nil {
+		t.Errorf("Expected invalid memory size to be rejected")
+	}
+}
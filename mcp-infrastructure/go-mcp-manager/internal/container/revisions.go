@@ -0,0 +1,209 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// specRevisionsBucket holds the deployment history of each instance, keyed by instance ID
+	specRevisionsBucket = "spec_revisions"
+	// maxSpecRevisions is how many revisions a history keeps, dropping the oldest
+	maxSpecRevisions = 20
+)
+
+// ErrRevisionNotFound is returned when an instance has no revision with the requested number
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// revisionState serializes updates of the stored histories
+type revisionState struct {
+	mutex sync.Mutex
+}
+
+// specHistory is the stored deployment history of an instance
+type specHistory struct {
+	Current   int                   `json:"current"`
+	Revisions []models.SpecRevision `json:"revisions"`
+}
+
+// recordSpecRevision adds the spec an instance's container was just created from to its history
+// and returns its revision. Redeploying the latest spec, as restarts and idempotent retries do,
+// reuses its revision. Containers without an instance ID have no history.
+func (m *Manager) recordSpecRevision(ctx context.Context, req models.CreateContainerRequest) int {
+	instanceID := req.Environment["MCP_INSTANCE_ID"]
+	if instanceID == "" {
+		return 0
+	}
+	hash := specHash(req)
+
+	m.revisions.mutex.Lock()
+	defer m.revisions.mutex.Unlock()
+
+	var history specHistory
+	if _, err := m.store.Get(specRevisionsBucket, instanceID, &history); err != nil {
+		m.logger.WarnContext(ctx, "Failed to read spec revisions",
+			slog.String("instance_id", instanceID),
+			slog.String("error", err.Error()))
+	}
+	if last := len(history.Revisions) - 1; last >= 0 && history.Revisions[last].SpecHash == hash {
+		history.Current = history.Revisions[last].Revision
+	} else {
+		history.Current = 1
+		if last >= 0 {
+			history.Current = history.Revisions[last].Revision + 1
+		}
+		history.Revisions = append(history.Revisions, models.SpecRevision{
+			Revision:  history.Current,
+			SpecHash:  hash,
+			Spec:      req,
+			CreatedAt: time.Now(),
+		})
+		history.Revisions = history.Revisions[max(len(history.Revisions)-maxSpecRevisions, 0):]
+	}
+
+	if err := m.store.Put(specRevisionsBucket, instanceID, history); err != nil {
+		m.logger.WarnContext(ctx, "Failed to save spec revision",
+			slog.String("instance_id", instanceID),
+			slog.String("error", err.Error()))
+	}
+	return history.Current
+}
+
+// markRollback records that revision was created by rolling back to rollbackOf
+func (m *Manager) markRollback(instanceID string, revision, rollbackOf int) {
+	m.revisions.mutex.Lock()
+	defer m.revisions.mutex.Unlock()
+
+	var history specHistory
+	if found, err := m.store.Get(specRevisionsBucket, instanceID, &history); !found || err != nil {
+		return
+	}
+	for i := range history.Revisions {
+		if history.Revisions[i].Revision == revision && revision != rollbackOf {
+			history.Revisions[i].RollbackOf = rollbackOf
+		}
+	}
+	if err := m.store.Put(specRevisionsBucket, instanceID, history); err != nil {
+		m.logger.Warn("Failed to save spec revision",
+			slog.String("instance_id", instanceID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// specRevisions returns the stored deployment history of an instance
+func (m *Manager) specRevisions(instanceID string) (specHistory, bool, error) {
+	m.revisions.mutex.Lock()
+	defer m.revisions.mutex.Unlock()
+
+	var history specHistory
+	found, err := m.store.Get(specRevisionsBucket, instanceID, &history)
+	if err != nil {
+		return history, false, fmt.Errorf("failed to read spec revisions: %w", err)
+	}
+	return history, found, nil
+}
+
+// GetSpecRevisions returns the deployment history of an instance, oldest first, with values that
+// may be credentials masked. Deleted instances keep theirs as long as their events.
+func (m *Manager) GetSpecRevisions(instanceID string) (*models.SpecRevisionsResponse, error) {
+	history, found, err := m.specRevisions(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("instance has no spec revisions: %s", instanceID)
+	}
+
+	response := &models.SpecRevisionsResponse{
+		InstanceID: instanceID,
+		Current:    history.Current,
+		Revisions:  make([]models.SpecRevision, len(history.Revisions)),
+	}
+	if serviceName, tracked := m.ServiceNameForInstance(instanceID); tracked {
+		response.ServiceName = serviceName
+	}
+	for i, revision := range history.Revisions {
+		revision.Spec = maskCreateRequest(revision.Spec)
+		response.Revisions[i] = revision
+	}
+	return response, nil
+}
+
+// RollbackInstance redeploys a previous revision of an instance: its current container, if any,
+// is replaced by one created from that revision's spec, which becomes the latest revision. The
+// spec is validated and its image pulled before the current container is touched, and when the
+// new container does not start the current one is recreated.
+func (m *Manager) RollbackInstance(ctx context.Context, instanceID string, revision int) (*models.SpecRollbackResult, error) {
+	history, _, err := m.specRevisions(instanceID)
+	if err != nil {
+		return nil, err
+	}
+	var target *models.SpecRevision
+	for i := range history.Revisions {
+		if history.Revisions[i].Revision == revision {
+			target = &history.Revisions[i]
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("%w: instance %s has no revision %d", ErrRevisionNotFound, instanceID, revision)
+	}
+
+	replacing := ""
+	serviceName, tracked := m.ServiceNameForInstance(instanceID)
+	if existing, found := m.containerSnapshot(serviceName); tracked && found {
+		if err := checkReplaceable(&existing, target.Spec.ServiceName); err != nil {
+			return nil, err
+		}
+		m.logger.InfoContext(ctx, "Replacing container to roll back its spec",
+			slog.String("instance_id", instanceID),
+			slog.String("service_name", serviceName),
+			slog.Int("revision", revision))
+		replacing = serviceName
+	}
+
+	container, err := m.createContainer(ctx, target.Spec, "", replacing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeploy revision %d: %w", revision, err)
+	}
+	// The create recorded the spec already, so this only looks up its revision
+	current := m.recordSpecRevision(ctx, target.Spec)
+	m.markRollback(instanceID, current, revision)
+	m.recordContainerEvent(container, InstanceEventNormal, "RolledBack",
+		fmt.Sprintf("Redeployed the spec of revision %d as revision %d", revision, current))
+
+	return &models.SpecRollbackResult{
+		InstanceID:   instanceID,
+		RolledBackTo: revision,
+		Revision:     current,
+		Container:    container,
+	}, nil
+}
+
+// maskCreateRequest returns a copy of req with values that may be credentials masked
+func maskCreateRequest(req models.CreateContainerRequest) models.CreateContainerRequest {
+	req.Environment = maskEnvironment(req.Environment, req.EnvSchema)
+	if route := req.Route; route != nil {
+		routeCopy := *route
+		routeCopy.RequestHeaders = maskAll(route.RequestHeaders)
+		if route.Auth != nil && route.Auth.Token != "" {
+			auth := *route.Auth
+			auth.Token = maskedValue
+			routeCopy.Auth = &auth
+		}
+		req.Route = &routeCopy
+	}
+	if initSpec := req.Init; initSpec != nil {
+		initCopy := *initSpec
+		initCopy.Environment = maskAll(initSpec.Environment)
+		req.Init = &initCopy
+	}
+	req.Proxy = maskProxy(req.Proxy)
+	req.Files = maskAll(req.Files)
+	return req
+}
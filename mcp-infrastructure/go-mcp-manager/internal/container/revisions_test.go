@@ -0,0 +1,84 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestSpecRevisions(t *testing.T) {
+	cfg := &config.Config{State: config.StateConfig{Dir: t.TempDir()}}
+	manager := newTestManager(cfg)
+	ctx := context.Background()
+
+	v1 := models.CreateContainerRequest{
+		ServiceName: "github",
+		Image:       "ghcr.io/example/github-mcp:1.0",
+		Port:        8080,
+		Environment: map[string]string{"MCP_INSTANCE_ID": "inst-1", "GITHUB_TOKEN": "ghp_secret"},
+	}
+	v2 := v1
+	v2.Image = "ghcr.io/example/github-mcp:2.0"
+
+	if revision := manager.recordSpecRevision(ctx, v1); revision != 1 {
+		t.Errorf("Expected the first spec to be revision 1, got %d", revision)
+	}
+	if revision := manager.recordSpecRevision(ctx, v1); revision != 1 {
+		t.Errorf("Expected redeploying the same spec to keep revision 1, got %d", revision)
+	}
+	if revision := manager.recordSpecRevision(ctx, v2); revision != 2 {
+		t.Errorf("Expected a changed spec to be revision 2, got %d", revision)
+	}
+	// Rolling back to revision 1 records its spec again
+	if revision := manager.recordSpecRevision(ctx, v1); revision != 3 {
+		t.Errorf("Expected the rolled back spec to be revision 3, got %d", revision)
+	}
+	manager.markRollback("inst-1", 3, 1)
+
+	response, err := manager.GetSpecRevisions("inst-1")
+	if err != nil {
+		t.Fatalf("Expected revisions, got %v", err)
+	}
+	if response.Current != 3 || len(response.Revisions) != 3 {
+		t.Fatalf("Expected 3 revisions with the last current, got %d of %d", response.Current, len(response.Revisions))
+	}
+	if response.Revisions[2].RollbackOf != 1 {
+		t.Errorf("Expected revision 3 to record the rollback, got %d", response.Revisions[2].RollbackOf)
+	}
+	if token := response.Revisions[0].Spec.Environment["GITHUB_TOKEN"]; token != maskedValue {
+		t.Errorf("Expected the token to be masked, got %s", token)
+	}
+
+	// A revision that cannot be deployed leaves the running container alone
+	t.Setenv("PATH", t.TempDir())
+	manager.containers["github"] = &models.Container{
+		ID:          "abc123",
+		Name:        "github",
+		ServiceName: "github",
+		Image:       v1.Image,
+		Status:      models.StatusRunning,
+		Environment: v1.Environment,
+		SpecHash:    specHash(v1),
+	}
+	broken := v1
+	broken.Image = ""
+	if revision := manager.recordSpecRevision(ctx, broken); revision != 4 {
+		t.Fatalf("Expected the broken spec to be revision 4, got %d", revision)
+	}
+	if _, err := manager.RollbackInstance(ctx, "inst-1", 4); err == nil {
+		t.Error("Expected a revision without an image to fail")
+	}
+	if container := manager.containers["github"]; container == nil || container.Image != v1.Image || container.ID != "abc123" {
+		t.Errorf("Expected the running container to be kept, got %+v", container)
+	}
+
+	if _, err := manager.RollbackInstance(ctx, "inst-1", 7); !errors.Is(err, ErrRevisionNotFound) {
+		t.Errorf("Expected an unknown revision to be rejected, got %v", err)
+	}
+	if _, err := manager.GetSpecRevisions("inst-2"); err == nil {
+		t.Errorf("Expected an instance without revisions to be reported")
+	}
+}
@@ -0,0 +1,647 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// Labels storing a container's route middleware and routing configuration on the podman container
+const (
+	routeLabel   = "mcp.route"
+	routingLabel = "mcp.routing"
+)
+
+// Bounds accepted for route middleware options
+const (
+	maxRouteRateLimit     = 100000
+	maxRouteRatePeriod    = 3600
+	maxRouteAllowListSize = 256
+	maxRouteHeaders       = 64
+	maxRouteIdleTimeout   = 24 * 60 * 60
+)
+
+// Proxy defaults for long-lived connections
+const (
+	// defaultStreamingIdleTimeout keeps idle SSE/WebSocket upstream connections open for an hour
+	defaultStreamingIdleTimeout = 60 * 60
+	// defaultIdleTimeout matches Traefik's own idle connection timeout
+	defaultIdleTimeout = 90
+	// defaultStickyCookieName is used when sticky sessions are enabled without a cookie name
+	defaultStickyCookieName = "mcp_sticky"
+)
+
+// Suffixes of the per-route Traefik middlewares, in the order they are applied
+const (
+	ipAllowListMiddleware = "ipallowlist"
+	rateLimitMiddleware   = "ratelimit"
+	authMiddleware        = "auth"
+	sessionMiddleware     = "session"
+	limitsMiddleware      = "limits"
+	limitErrorsMiddleware = "limiterrors"
+	inFlightMiddleware    = "inflight"
+	headersMiddleware     = "headers"
+	bufferingMiddleware   = "buffering"
+	stripPrefixMiddleware = "stripprefix"
+
+	unavailableMiddleware    = "unavailable"
+	circuitBreakerMiddleware = "circuitbreaker"
+	retryMiddleware          = "retry"
+)
+
+// routeMiddlewareSuffixes lists every middleware a route may own, so removal cleans them all up
+var routeMiddlewareSuffixes = []string{
+	ipAllowListMiddleware,
+	rateLimitMiddleware,
+	authMiddleware,
+	sessionMiddleware,
+	limitsMiddleware,
+	limitErrorsMiddleware,
+	inFlightMiddleware,
+	headersMiddleware,
+	bufferingMiddleware,
+	stripPrefixMiddleware,
+	unavailableMiddleware,
+	circuitBreakerMiddleware,
+	retryMiddleware,
+}
+
+// parseRouteSpec reads the optional route object from json_spec
+func parseRouteSpec(jsonSpec map[string]interface{}) (*models.RouteConfig, error) {
+	raw, exists := jsonSpec["route"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+
+	if _, ok := raw.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("route must be an object")
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("route is not valid JSON: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	route := &models.RouteConfig{}
+	if err := decoder.Decode(route); err != nil {
+		return nil, fmt.Errorf("invalid route: %w", err)
+	}
+
+	if err := validateRouteConfig(route); err != nil {
+		return nil, err
+	}
+
+	return route, nil
+}
+
+// validateRouteConfig checks route middleware options before they reach the proxy config
+func validateRouteConfig(route *models.RouteConfig) error {
+	if route == nil {
+		return nil
+	}
+
+	if rl := route.RateLimit; rl != nil {
+		if rl.Average < 1 || rl.Average > maxRouteRateLimit {
+			return fmt.Errorf("route.rate_limit.average must be between 1 and %d", maxRouteRateLimit)
+		}
+		if rl.Burst < 0 || rl.Burst > maxRouteRateLimit {
+			return fmt.Errorf("route.rate_limit.burst must be between 0 and %d", maxRouteRateLimit)
+		}
+		if rl.PeriodSeconds < 0 || rl.PeriodSeconds > maxRouteRatePeriod {
+			return fmt.Errorf("route.rate_limit.period_seconds must be between 0 and %d", maxRouteRatePeriod)
+		}
+	}
+
+	if len(route.IPAllowList) > maxRouteAllowListSize {
+		return fmt.Errorf("route.ip_allowlist may contain at most %d entries", maxRouteAllowListSize)
+	}
+	for _, entry := range route.IPAllowList {
+		if _, _, err := net.ParseCIDR(entry); err != nil && net.ParseIP(entry) == nil {
+			return fmt.Errorf("route.ip_allowlist entry %q is not an IP address or CIDR range", entry)
+		}
+	}
+
+	for field, headers := range map[string]map[string]string{
+		"request_headers":  route.RequestHeaders,
+		"response_headers": route.ResponseHeaders,
+	} {
+		if len(headers) > maxRouteHeaders {
+			return fmt.Errorf("route.%s may contain at most %d headers", field, maxRouteHeaders)
+		}
+		for name, value := range headers {
+			if !validHeaderName(name) {
+				return fmt.Errorf("route.%s has invalid header name %q", field, name)
+			}
+			if strings.ContainsAny(value, "\r\n") {
+				return fmt.Errorf("route.%s value for %s must not contain line breaks", field, name)
+			}
+		}
+	}
+
+	if b := route.Buffering; b != nil {
+		if b.MaxRequestBodyBytes < 0 || b.MaxResponseBodyBytes < 0 {
+			return fmt.Errorf("route.buffering limits must not be negative")
+		}
+	}
+
+	switch route.Transport {
+	case "", models.TransportHTTP, models.TransportSSE, models.TransportStreamableHTTP, models.TransportWebSocket:
+	default:
+		return fmt.Errorf("route.transport must be one of %s, %s, %s or %s",
+			models.TransportHTTP, models.TransportSSE, models.TransportStreamableHTTP, models.TransportWebSocket)
+	}
+
+	// Buffering holds whole bodies in the proxy, which breaks event streams
+	if routeStreaming(route) && route.Buffering != nil {
+		return fmt.Errorf("route.buffering cannot be used with the %s transport", route.Transport)
+	}
+
+	if route.IdleTimeoutSeconds < 0 || route.IdleTimeoutSeconds > maxRouteIdleTimeout {
+		return fmt.Errorf("route.idle_timeout_seconds must be between 0 and %d", maxRouteIdleTimeout)
+	}
+
+	if route.StickyCookieName != "" && !validHeaderName(route.StickyCookieName) {
+		return fmt.Errorf("route.sticky_cookie_name %q is not a valid cookie name", route.StickyCookieName)
+	}
+
+	if err := validateRouteAuth(route.Auth); err != nil {
+		return err
+	}
+
+	if err := validateRouteLimits(route); err != nil {
+		return err
+	}
+
+	if err := validateRouteSessions(route.Sessions); err != nil {
+		return err
+	}
+
+	return validateRouteResilience(route)
+}
+
+// parseRoutingSpec reads the optional routing object from json_spec
+func parseRoutingSpec(jsonSpec map[string]interface{}) (*models.RoutingConfig, error) {
+	raw, exists := jsonSpec["routing"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+
+	spec, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("routing must be an object")
+	}
+
+	routing := &models.RoutingConfig{}
+	for key, value := range spec {
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("routing.%s must be a string", key)
+		}
+		switch key {
+		case "type":
+			routing.Type = str
+		case "hostname":
+			routing.Hostname = str
+		default:
+			return nil, fmt.Errorf("unknown routing option %q", key)
+		}
+	}
+
+	if err := validateRoutingConfig(routing); err != nil {
+		return nil, err
+	}
+
+	return routing, nil
+}
+
+// validateRoutingConfig checks the routing type and hostname
+func validateRoutingConfig(routing *models.RoutingConfig) error {
+	if routing == nil {
+		return nil
+	}
+
+	switch routing.Type {
+	case "", models.RoutingPath:
+		if routing.Hostname != "" {
+			return fmt.Errorf("routing.hostname requires routing.type %q", models.RoutingHost)
+		}
+	case models.RoutingHost:
+		if !validHostname(routing.Hostname) {
+			return fmt.Errorf("routing.hostname %q is not a valid fully qualified hostname", routing.Hostname)
+		}
+	default:
+		return fmt.Errorf("routing.type must be %q or %q", models.RoutingPath, models.RoutingHost)
+	}
+
+	return nil
+}
+
+// validHostname reports whether name is a lower-case, fully qualified DNS hostname
+func validHostname(name string) bool {
+	if len(name) > 253 || !strings.Contains(name, ".") {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// routingHostname returns the hostname a container is routed by, or "" for path-only routing
+func routingHostname(routing *models.RoutingConfig) string {
+	if routing == nil || routing.Type != models.RoutingHost {
+		return ""
+	}
+	return routing.Hostname
+}
+
+// hostnameInUseUnsafe reports whether another container already claims hostname (caller must hold the lock)
+func (m *Manager) hostnameInUseUnsafe(hostname, serviceName string) bool {
+	if hostname == "" {
+		return false
+	}
+	for name, container := range m.containers {
+		if name != serviceName && routingHostname(container.Routing) == hostname {
+			return true
+		}
+	}
+	return false
+}
+
+// routeStreaming reports whether the route serves a long-lived streaming transport
+func routeStreaming(route *models.RouteConfig) bool {
+	if route == nil {
+		return false
+	}
+	switch route.Transport {
+	case models.TransportSSE, models.TransportStreamableHTTP, models.TransportWebSocket:
+		return true
+	default:
+		return false
+	}
+}
+
+// routeIdleTimeout returns the effective upstream idle timeout in seconds
+func routeIdleTimeout(route *models.RouteConfig) int {
+	switch {
+	case route != nil && route.IdleTimeoutSeconds > 0:
+		return route.IdleTimeoutSeconds
+	case routeStreaming(route):
+		return defaultStreamingIdleTimeout
+	default:
+		return defaultIdleTimeout
+	}
+}
+
+// routeStickyCookie returns the sticky session cookie name, or "" when sessions are not sticky
+func routeStickyCookie(route *models.RouteConfig) string {
+	if route == nil || !route.StickySessions {
+		return ""
+	}
+	if route.StickyCookieName != "" {
+		return route.StickyCookieName
+	}
+	return defaultStickyCookieName
+}
+
+// buildRouteService returns the Traefik service for a route and, when the defaults do not fit,
+// the servers transport carrying its upstream timeouts. A nil limits leaves the response unbounded.
+func buildRouteService(slug, containerIP string, containerPort int, route *models.RouteConfig, limits *models.RouteLimits) (TraefikService, *TraefikServersTransport) {
+	service := TraefikService{
+		LoadBalancer: TraefikLoadBalancer{
+			Servers: []TraefikServer{
+				{URL: mcpUpstreamURL(containerIP, containerPort)},
+			},
+		},
+	}
+
+	if cookie := routeStickyCookie(route); cookie != "" {
+		service.LoadBalancer.Sticky = &TraefikSticky{
+			Cookie: &TraefikStickyCookie{Name: cookie, HTTPOnly: true},
+		}
+	}
+
+	// Flush every write immediately so events reach the client as they are produced
+	if routeStreaming(route) {
+		service.LoadBalancer.ResponseForwarding = &TraefikResponseForwarding{FlushInterval: "-1"}
+	}
+
+	timeout := 0
+	if limits != nil {
+		timeout = limits.TimeoutSeconds
+	}
+	if routeIdleTimeout(route) == defaultIdleTimeout && timeout == 0 {
+		return service, nil
+	}
+
+	timeouts := &TraefikForwardingTimeouts{}
+	if routeIdleTimeout(route) != defaultIdleTimeout {
+		timeouts.IdleConnTimeout = fmt.Sprintf("%ds", routeIdleTimeout(route))
+	}
+	if timeout > 0 {
+		timeouts.ResponseHeaderTimeout = fmt.Sprintf("%ds", timeout)
+	}
+	service.LoadBalancer.ServersTransport = routeServersTransportName(slug)
+	return service, &TraefikServersTransport{ForwardingTimeouts: timeouts}
+}
+
+// routeServersTransportName returns the Traefik servers transport name for a route
+func routeServersTransportName(slug string) string {
+	return fmt.Sprintf("mcp-%s-transport", slug)
+}
+
+// GetConnectionContract describes how clients should connect to a container through the proxy
+func (m *Manager) GetConnectionContract(serviceName string) (*models.ConnectionContract, error) {
+	container, err := m.GetContainer(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	route := container.Route
+	transport := models.TransportHTTP
+	if route != nil && route.Transport != "" {
+		transport = route.Transport
+	}
+
+	contract := &models.ConnectionContract{
+		ServiceName:        container.ServiceName,
+		URL:                container.URL,
+		HostURL:            hostRoutedURL(container.Routing),
+		Transport:          transport,
+		Streaming:          routeStreaming(route),
+		IdleTimeoutSeconds: routeIdleTimeout(route),
+		StickyCookieName:   routeStickyCookie(route),
+		Limits:             resolveRouteLimits(m.config.Traefik.Limits, route),
+	}
+	contract.StickySessions = contract.StickyCookieName != ""
+	if route != nil {
+		contract.Buffered = route.Buffering != nil
+		contract.RateLimited = route.RateLimit != nil
+		contract.IPRestricted = len(route.IPAllowList) > 0
+		if route.Auth != nil {
+			auth := *route.Auth
+			if auth.Type == models.ProxyAuthBasic {
+				auth.Username = proxyAuthUsername(&auth)
+			}
+			contract.Auth = &auth
+		}
+	}
+
+	return contract, nil
+}
+
+// validHeaderName reports whether name is a valid HTTP header field name (an RFC 7230 token)
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r > 0x7e || r <= 0x20 || strings.ContainsRune("\"(),/:;<=>?@[\\]{}", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasHeader reports whether headers sets name, compared case-insensitively
+func hasHeader(headers map[string]string, name string) bool {
+	for header := range headers {
+		if strings.EqualFold(header, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// routeMiddlewareName returns the Traefik middleware name for one of a route's middlewares
+func routeMiddlewareName(slug, suffix string) string {
+	return fmt.Sprintf("mcp-%s-%s", slug, suffix)
+}
+
+// buildRouteMiddlewares returns the middlewares for a route, keyed by suffix, plus their application order.
+// A nil breaker leaves the route without a circuit breaker, and nil limits without size limits. Access
+// tokens and header sizes are checked by the manager at managerURL.
+func buildRouteMiddlewares(slug string, route *models.RouteConfig, breaker *models.RouteCircuitBreaker, limits *models.RouteLimits, managerURL string) (map[string]TraefikMiddleware, []string) {
+	middlewares := make(map[string]TraefikMiddleware)
+	var order []string
+
+	add := func(suffix string, middleware TraefikMiddleware) {
+		middlewares[suffix] = middleware
+		order = append(order, routeMiddlewareName(slug, suffix))
+	}
+
+	if route != nil {
+		if len(route.IPAllowList) > 0 {
+			add(ipAllowListMiddleware, TraefikMiddleware{
+				IPAllowList: &TraefikIPAllowList{SourceRange: route.IPAllowList},
+			})
+		}
+		if rl := route.RateLimit; rl != nil {
+			period := ""
+			if rl.PeriodSeconds > 0 {
+				period = fmt.Sprintf("%ds", rl.PeriodSeconds)
+			}
+			add(rateLimitMiddleware, TraefikMiddleware{
+				RateLimit: &TraefikRateLimit{Average: rl.Average, Burst: rl.Burst, Period: period},
+			})
+		}
+		// Rate limiting comes first so tokens cannot be guessed at full speed
+		if route.Auth != nil {
+			add(authMiddleware, TraefikMiddleware{
+				ForwardAuth: &TraefikForwardAuth{Address: strings.TrimSuffix(managerURL, "/") + proxyAuthPath(slug)},
+			})
+		}
+		// Only authorized requests may start a session's container
+		if route.Sessions != nil {
+			add(sessionMiddleware, TraefikMiddleware{
+				ForwardAuth: &TraefikForwardAuth{Address: strings.TrimSuffix(managerURL, "/") + proxySessionPath(slug)},
+			})
+		}
+	}
+	if limits != nil && limits.MaxHeaderBytes > 0 {
+		add(limitsMiddleware, TraefikMiddleware{
+			ForwardAuth: &TraefikForwardAuth{Address: strings.TrimSuffix(managerURL, "/") + proxyLimitsPath(slug)},
+		})
+	}
+
+	buffering := TraefikBuffering{}
+	if route != nil && route.Buffering != nil {
+		buffering.MaxRequestBodyBytes = route.Buffering.MaxRequestBodyBytes
+		buffering.MaxResponseBodyBytes = route.Buffering.MaxResponseBodyBytes
+	}
+	if limits != nil {
+		buffering.MaxRequestBodyBytes = smallestLimit(buffering.MaxRequestBodyBytes, limits.MaxRequestBodyBytes)
+		buffering.MaxResponseBodyBytes = smallestLimit(buffering.MaxResponseBodyBytes, limits.MaxResponseBodyBytes)
+	}
+	// The error page wraps the concurrency cap, buffering and the upstream so a busy instance, a body
+	// over its limit or a slow response reaches the client as a structured body naming the instance
+	if limits != nil {
+		var statuses []string
+		if buffering != (TraefikBuffering{}) {
+			statuses = append(statuses, "413")
+		}
+		if limits.MaxConcurrentRequests > 0 {
+			statuses = append(statuses, "429")
+		}
+		if limits.TimeoutSeconds > 0 {
+			statuses = append(statuses, "504")
+		}
+		if len(statuses) > 0 {
+			add(limitErrorsMiddleware, TraefikMiddleware{
+				Errors: &TraefikErrors{
+					Status:  statuses,
+					Service: managerServiceName,
+					Query:   limitExceededPath(slug),
+				},
+			})
+		}
+		// Grouping by the requested host counts every request to the route against one cap
+		if limits.MaxConcurrentRequests > 0 {
+			add(inFlightMiddleware, TraefikMiddleware{
+				InFlightReq: &TraefikInFlightReq{
+					Amount:          limits.MaxConcurrentRequests,
+					SourceCriterion: &TraefikSourceCriterion{RequestHost: true},
+				},
+			})
+		}
+	}
+
+	// The error page wraps the breaker so its bare 503s reach clients as a structured body with Retry-After
+	if breaker != nil {
+		add(unavailableMiddleware, TraefikMiddleware{
+			Errors: &TraefikErrors{
+				Status:  []string{"503"},
+				Service: managerServiceName,
+				Query:   unavailablePath(slug),
+			},
+		})
+		add(circuitBreakerMiddleware, TraefikMiddleware{
+			CircuitBreaker: &TraefikCircuitBreaker{
+				Expression:       breaker.Expression,
+				FallbackDuration: fmt.Sprintf("%ds", breaker.FallbackSeconds),
+				RecoveryDuration: fmt.Sprintf("%ds", breaker.RecoverySeconds),
+			},
+		})
+	}
+
+	if route != nil {
+		requestHeaders := route.RequestHeaders
+		// The access token is meant for the proxy; an empty value makes Traefik drop the header
+		if route.Auth != nil && !hasHeader(requestHeaders, "Authorization") {
+			requestHeaders = maps.Clone(requestHeaders)
+			if requestHeaders == nil {
+				requestHeaders = make(map[string]string)
+			}
+			requestHeaders["Authorization"] = ""
+		}
+		if len(requestHeaders) > 0 || len(route.ResponseHeaders) > 0 {
+			add(headersMiddleware, TraefikMiddleware{
+				Headers: &TraefikHeaders{
+					CustomRequestHeaders:  requestHeaders,
+					CustomResponseHeaders: route.ResponseHeaders,
+				},
+			})
+		}
+	}
+
+	if buffering != (TraefikBuffering{}) {
+		add(bufferingMiddleware, TraefikMiddleware{Buffering: &buffering})
+	}
+
+	if route != nil {
+		if r := route.Retry; r != nil {
+			interval := ""
+			if r.InitialIntervalMs > 0 {
+				interval = fmt.Sprintf("%dms", r.InitialIntervalMs)
+			}
+			add(retryMiddleware, TraefikMiddleware{
+				Retry: &TraefikRetry{Attempts: r.Attempts, InitialInterval: interval},
+			})
+		}
+	}
+
+	// Strip the routing prefix last so the other middlewares see the public path
+	add(stripPrefixMiddleware, TraefikMiddleware{
+		StripPrefix: &TraefikStripPrefix{
+			Prefixes:   []string{fmt.Sprintf("/mcp/%s", slug)},
+			ForceSlash: false,
+		},
+	})
+
+	return middlewares, order
+}
+
+// containerLabel reads a single label from a podman container, returning "" if it is unset
+func (m *Manager) containerLabel(ctx context.Context, containerID, label string) string {
+	cmd := podmanCommand(ctx, m.logger, "inspect", containerID, "--format", fmt.Sprintf("{{index .Config.Labels %q}}", label))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return ""
+	}
+
+	value := strings.TrimSpace(string(output))
+	if value == "<no value>" {
+		return ""
+	}
+	return value
+}
+
+// hostRoutedURL returns the public URL of a host-routed container, or "" for path-only routing
+func hostRoutedURL(routing *models.RoutingConfig) string {
+	if hostname := routingHostname(routing); hostname != "" {
+		return "https://" + hostname
+	}
+	return ""
+}
+
+// discoverRoute restores the route configuration persisted on a podman container
+func (m *Manager) discoverRoute(ctx context.Context, containerID string) *models.RouteConfig {
+	var route models.RouteConfig
+	if !m.discoverJSONLabel(ctx, containerID, routeLabel, &route) {
+		return nil
+	}
+	return &route
+}
+
+// discoverRouting restores the routing configuration persisted on a podman container
+func (m *Manager) discoverRouting(ctx context.Context, containerID string) *models.RoutingConfig {
+	var routing models.RoutingConfig
+	if !m.discoverJSONLabel(ctx, containerID, routingLabel, &routing) {
+		return nil
+	}
+	return &routing
+}
+
+// discoverJSONLabel decodes a JSON label from a podman container into v, reporting whether it was present
+func (m *Manager) discoverJSONLabel(ctx context.Context, containerID, label string, v interface{}) bool {
+	value := m.containerLabel(ctx, containerID, label)
+	if value == "" {
+		return false
+	}
+
+	if err := json.Unmarshal([]byte(value), v); err != nil {
+		m.logger.WarnContext(ctx, "Ignoring unreadable container label",
+			slog.String("container_id", containerID),
+			slog.String("label", label),
+			slog.String("error", err.Error()))
+		return false
+	}
+	return true
+}
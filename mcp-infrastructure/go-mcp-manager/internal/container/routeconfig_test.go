@@ -0,0 +1,39 @@
+package container
+
+import (
+	"testing"
+)
+
+func TestParseRouteSpec(t *testing.T) {
+	route, err := parseRouteSpec(map[string]interface{}{
+		"route": map[string]interface{}{
+			"rate_limit":       map[string]interface{}{"average": float64(50), "burst": float64(100)},
+			"ip_allowlist":     []interface{}{"10.0.0.0/8", "192.168.1.7"},
+			"request_headers":  map[string]interface{}{"X-Forwarded-Prefix": "/mcp"},
+			"response_headers": map[string]interface{}{"X-Frame-Options": "DENY"},
+			"buffering":        map[string]interface{}{"max_request_body_bytes": float64(1048576)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected valid route spec, got error: %v", err)
+	}
+	if route.RateLimit.Average != 50 || route.RateLimit.Burst != 100 {
+		t.Errorf("Expected rate limit 50/100, got %+v", route.RateLimit)
+	}
+	if route.Buffering.MaxRequestBodyBytes != 1048576 {
+		t.Errorf("Expected max request body 1048576, got %d", route.Buffering.MaxRequestBodyBytes)
+	}
+
+	invalid := []map[string]interface{}{
+		{"ip_allowlist": []interface{}{"not-an-ip"}},
+		{"rate_limit": map[string]interface{}{"average": float64(0)}},
+		{"request_headers": map[string]interface{}{"Bad Header": "x"}},
+		{"response_headers": map[string]interface{}{"X-Test": "a\r\nInjected: b"}},
+		{"unknown_option": true},
+	}
+	for _, spec := range invalid {
+		if _, err := parseRouteSpec(map[string]interface{}{"route": spec}); err == nil {
+			t.Errorf("Expected error for route spec %v", spec)
+		}
+	}
+}
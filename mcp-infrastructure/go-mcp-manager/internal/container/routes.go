@@ -0,0 +1,144 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/webhooks"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// routeChangedWarning is the warning code published when a stale route is re-registered
+const routeChangedWarning = "route_upstream_changed"
+
+// RouteRefreshResult describes the outcome of re-registering a container's proxy route
+type RouteRefreshResult struct {
+	ServiceName      string    `json:"service_name"`
+	Slug             string    `json:"slug"`
+	PreviousUpstream string    `json:"previous_upstream,omitempty"`
+	Upstream         string    `json:"upstream"`
+	Changed          bool      `json:"changed"`
+	RefreshedAt      time.Time `json:"refreshed_at"`
+}
+
+// startRouteReconciler periodically re-registers routes whose container IP has changed
+func (m *Manager) startRouteReconciler() {
+	interval := m.config.Traefik.RouteReconcileInterval
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.healthCtx.Done():
+			return
+		case <-ticker.C:
+			m.reconcileRoutes(m.healthCtx)
+		}
+	}
+}
+
+// reconcileRoutes compares every running container's IP with its routed upstream
+func (m *Manager) reconcileRoutes(ctx context.Context) {
+	m.mutex.RLock()
+	containers := make([]models.Container, 0, len(m.containers))
+	for _, container := range m.containers {
+		if container.Status == models.StatusRunning && container.Slug != "" && container.ID != "" &&
+			(container.Ready || !m.routesAwaitReadiness()) {
+			containers = append(containers, *container)
+		}
+	}
+	m.mutex.RUnlock()
+
+	for i := range containers {
+		if _, err := m.refreshRoute(ctx, &containers[i]); err != nil {
+			m.logger.DebugContext(ctx, "Route reconciliation skipped container",
+				slog.String("service", containers[i].ServiceName),
+				slog.String("error", err.Error()))
+		}
+	}
+}
+
+// RefreshRoute re-reads a container's IP and re-registers its route if the upstream is stale
+func (m *Manager) RefreshRoute(ctx context.Context, serviceName string) (*RouteRefreshResult, error) {
+	m.mutex.RLock()
+	tracked, exists := m.containers[serviceName]
+	var container models.Container
+	if exists {
+		container = *tracked
+	}
+	m.mutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+	if container.ID == "" || container.Slug == "" {
+		return nil, fmt.Errorf("container %s has no routable instance", serviceName)
+	}
+
+	return m.refreshRoute(ctx, &container)
+}
+
+// refreshRoute updates the Traefik upstream for a container when its IP no longer matches.
+// It does not take the manager lock, so callers holding it may use it.
+func (m *Manager) refreshRoute(ctx context.Context, container *models.Container) (*RouteRefreshResult, error) {
+	containerIP, err := m.getContainerIP(ctx, container.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container IP: %w", err)
+	}
+	upstreamHost, upstreamPort := m.upstreamAddress(ctx, container, containerIP)
+
+	previous, err := m.traefikManager.GetMCPServiceUpstream(container.Slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read current route: %w", err)
+	}
+
+	result := &RouteRefreshResult{
+		ServiceName:      container.ServiceName,
+		Slug:             container.Slug,
+		PreviousUpstream: previous,
+		Upstream:         upstreamURL(container, upstreamHost, upstreamPort),
+		RefreshedAt:      time.Now(),
+	}
+	if previous == result.Upstream {
+		return result, nil
+	}
+
+	if err := m.traefikManager.AddMCPService(ctx, container.Slug, upstreamHost, upstreamPort, container.Route, container.Routing, m.upstreamTLS(container)); err != nil {
+		return nil, fmt.Errorf("failed to update route: %w", err)
+	}
+	result.Changed = true
+
+	// A missing route is a plain registration; a different upstream means the IP moved
+	if previous != "" {
+		m.notifyRouteChanged(ctx, container, previous, result.Upstream)
+	}
+
+	return result, nil
+}
+
+// notifyRouteChanged logs and publishes a warning that a container's upstream moved
+func (m *Manager) notifyRouteChanged(ctx context.Context, container *models.Container, previous, current string) {
+	message := fmt.Sprintf("route upstream changed from %s to %s", previous, current)
+
+	m.logger.WarnContext(ctx, "Container IP changed, re-registered Traefik route",
+		slog.String("service", container.ServiceName),
+		slog.String("slug", container.Slug),
+		slog.String("previous_upstream", previous),
+		slog.String("upstream", current))
+
+	if instanceID, exists := container.Environment["MCP_INSTANCE_ID"]; exists {
+		if err := m.eventPublisher.PublishWarning(ctx, instanceID, container.ServiceName, routeChangedWarning, message); err != nil {
+			m.logger.WarnContext(ctx, "Failed to publish route change warning",
+				slog.String("instance_id", instanceID),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	m.notifyWebhook(webhooks.EventRouteChanged, container, message)
+}
@@ -0,0 +1,213 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// runtimeLabel stores the package runtime a container was created from
+	runtimeLabel = "mcp.runtime"
+	// runtimeHealthPath is the health endpoint the stdio bridge serves
+	runtimeHealthPath = "/healthz"
+)
+
+var (
+	// npmPackagePattern matches npm package names, optionally scoped
+	npmPackagePattern = regexp.MustCompile(`^(@[a-z0-9][a-z0-9._~-]*/)?[a-z0-9][a-z0-9._~-]*$`)
+	// pypiPackagePattern matches PyPI project names, optionally with extras
+	pypiPackagePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*(\[[A-Za-z0-9._,-]+\])?$`)
+	// packageVersionPattern matches versions and dist-tags without shell or option characters
+	packageVersionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.+_-]*$`)
+)
+
+// parseRuntimeSpec reads the optional runtime object from json_spec
+func parseRuntimeSpec(jsonSpec map[string]interface{}) (*models.RuntimeConfig, error) {
+	raw, exists := jsonSpec["runtime"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+
+	if _, ok := raw.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("runtime must be an object")
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("runtime is not valid JSON: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	runtime := &models.RuntimeConfig{}
+	if err := decoder.Decode(runtime); err != nil {
+		return nil, fmt.Errorf("invalid runtime: %w", err)
+	}
+
+	if err := validateRuntimeConfig(runtime); err != nil {
+		return nil, err
+	}
+	return runtime, nil
+}
+
+// validateRuntimeConfig checks the runtime type and that the package and version are plain names
+func validateRuntimeConfig(runtime *models.RuntimeConfig) error {
+	if runtime == nil {
+		return nil
+	}
+
+	switch runtime.Type {
+	case models.RuntimeNpx:
+		if !npmPackagePattern.MatchString(runtime.Package) {
+			return fmt.Errorf("runtime.package %q is not an npm package name", runtime.Package)
+		}
+	case models.RuntimeUvx:
+		if !pypiPackagePattern.MatchString(runtime.Package) {
+			return fmt.Errorf("runtime.package %q is not a PyPI package name", runtime.Package)
+		}
+	default:
+		return fmt.Errorf("runtime.type must be %q or %q", models.RuntimeNpx, models.RuntimeUvx)
+	}
+
+	if runtime.Version != "" && !packageVersionPattern.MatchString(runtime.Version) {
+		return fmt.Errorf("runtime.version %q is not a valid version", runtime.Version)
+	}
+	return nil
+}
+
+// runtimeImage returns the bridge base image configured for a runtime type
+func (m *Manager) runtimeImage(runtime *models.RuntimeConfig) string {
+	if runtime.Type == models.RuntimeUvx {
+		return m.config.Container.UvxRuntimeImage
+	}
+	return m.config.Container.NpxRuntimeImage
+}
+
+// stdioCommand returns the shell command the bridge spawns to run the package over stdio
+func stdioCommand(runtime *models.RuntimeConfig) string {
+	pkg := runtime.Package
+	if runtime.Version != "" {
+		pkg += "@" + runtime.Version
+	}
+
+	parts := []string{runtime.Type}
+	if runtime.Type == models.RuntimeNpx {
+		parts = append(parts, "-y")
+	}
+	parts = append(parts, shellQuote(pkg))
+	for _, arg := range runtime.Args {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+// shellQuote quotes a word for sh, since the bridge runs the stdio command through a shell
+func shellQuote(word string) string {
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// runtimeBridgeArgs returns the bridge arguments serving a runtime's stdio server as streamable HTTP
+func runtimeBridgeArgs(runtime *models.RuntimeConfig, port int) []string {
+	return []string{
+		"--stdio", stdioCommand(runtime),
+		"--port", strconv.Itoa(port),
+		"--outputTransport", "streamableHttp",
+		"--healthEndpoint", runtimeHealthPath,
+	}
+}
+
+// applyRuntimeRequest translates a request's runtime into the bridge image and command, defaulting
+// the health check and transport to what the bridge serves
+func (m *Manager) applyRuntimeRequest(req *models.CreateContainerRequest) error {
+	if req.Runtime == nil {
+		return nil
+	}
+	if err := validateRuntimeConfig(req.Runtime); err != nil {
+		return err
+	}
+	if req.Image != "" || req.Source != nil || len(req.Command) > 0 {
+		return fmt.Errorf("runtime cannot be combined with image, source or command")
+	}
+
+	req.Image = m.runtimeImage(req.Runtime)
+	req.Command = runtimeBridgeArgs(req.Runtime, req.Port)
+	if req.HealthCheck == nil {
+		req.HealthCheck = &models.HealthCheckConfig{Path: runtimeHealthPath}
+	}
+	if req.Route == nil {
+		req.Route = &models.RouteConfig{}
+	}
+	if req.Route.Transport == "" {
+		route := *req.Route
+		route.Transport = models.TransportStreamableHTTP
+		req.Route = &route
+	}
+	return nil
+}
+
+// applyRuntimeSpec returns json_spec with a runtime translated into image, cmd, health_check and
+// route, so the rest of provisioning treats it like any image. Specs without runtime are returned as is.
+func (m *Manager) applyRuntimeSpec(jsonSpec map[string]interface{}) (map[string]interface{}, *models.RuntimeConfig, error) {
+	runtime, err := parseRuntimeSpec(jsonSpec)
+	if err != nil || runtime == nil {
+		return jsonSpec, nil, err
+	}
+	for _, field := range []string{"image", "source", "cmd"} {
+		if _, exists := jsonSpec[field]; exists {
+			return nil, nil, fmt.Errorf("runtime cannot be combined with %s", field)
+		}
+	}
+
+	port := 8000
+	if p, ok := jsonSpec["port"].(float64); ok {
+		port = int(p)
+	}
+
+	// Copy so the translation is not written into the caller's spec
+	translated := maps.Clone(jsonSpec)
+	translated["image"] = m.runtimeImage(runtime)
+	translated["port"] = float64(port)
+
+	bridgeArgs := runtimeBridgeArgs(runtime, port)
+	cmd := make([]interface{}, len(bridgeArgs))
+	for i, arg := range bridgeArgs {
+		cmd[i] = arg
+	}
+	translated["cmd"] = cmd
+
+	if _, exists := translated["health_check"]; !exists {
+		translated["health_check"] = map[string]interface{}{"path": runtimeHealthPath}
+	}
+	// A malformed route is left for route validation to reject
+	route, isObject := translated["route"].(map[string]interface{})
+	if _, exists := translated["route"]; !exists || isObject {
+		if _, exists := route["transport"]; !exists {
+			route = maps.Clone(route)
+			if route == nil {
+				route = make(map[string]interface{})
+			}
+			route["transport"] = models.TransportStreamableHTTP
+			translated["route"] = route
+		}
+	}
+
+	return translated, runtime, nil
+}
+
+// discoverRuntime restores the package runtime persisted on a podman container
+func (m *Manager) discoverRuntime(ctx context.Context, containerID string) *models.RuntimeConfig {
+	var runtime models.RuntimeConfig
+	if !m.discoverJSONLabel(ctx, containerID, runtimeLabel, &runtime) {
+		return nil
+	}
+	return &runtime
+}
@@ -0,0 +1,78 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestRuntimeShortcut(t *testing.T) {
+	cfg := &config.Config{
+		Container: config.ContainerConfig{
+			NpxRuntimeImage: "supergateway:latest",
+			UvxRuntimeImage: "supergateway:uvx",
+		},
+	}
+	manager := newTestManager(cfg)
+
+	jsonSpec := map[string]interface{}{
+		"port": float64(9000),
+		"runtime": map[string]interface{}{
+			"type":    "npx",
+			"package": "@modelcontextprotocol/server-filesystem",
+			"version": "2025.1.14",
+			"args":    []interface{}{"/data", "it's"},
+		},
+	}
+	translated, runtime, err := manager.applyRuntimeSpec(jsonSpec)
+	if err != nil {
+		t.Fatalf("Expected runtime to translate, got %v", err)
+	}
+	if runtime == nil || runtime.Type != models.RuntimeNpx {
+		t.Errorf("Expected npx runtime, got %+v", runtime)
+	}
+	if translated["image"] != "supergateway:latest" {
+		t.Errorf("Expected npx bridge image, got %v", translated["image"])
+	}
+	if _, exists := jsonSpec["image"]; exists {
+		t.Errorf("Expected the caller's json_spec to be left unchanged")
+	}
+
+	cmd, _ := translated["cmd"].([]interface{})
+	expectedStdio := `npx -y '@modelcontextprotocol/server-filesystem@2025.1.14' '/data' 'it'\''s'`
+	if len(cmd) < 4 || cmd[1] != expectedStdio || cmd[3] != "9000" {
+		t.Errorf("Expected bridge args running %s on port 9000, got %v", expectedStdio, cmd)
+	}
+	if route, _ := translated["route"].(map[string]interface{}); route["transport"] != models.TransportStreamableHTTP {
+		t.Errorf("Expected streamable_http transport, got %v", translated["route"])
+	}
+
+	if stdio := stdioCommand(&models.RuntimeConfig{Type: models.RuntimeUvx, Package: "mcp-server-fetch"}); stdio != "uvx 'mcp-server-fetch'" {
+		t.Errorf("Expected uvx command, got %s", stdio)
+	}
+
+	invalid := []map[string]interface{}{
+		{"runtime": map[string]interface{}{"type": "pip", "package": "mcp-server-fetch"}},
+		{"runtime": map[string]interface{}{"type": "npx", "package": "pkg; rm -rf /"}},
+		{"runtime": map[string]interface{}{"type": "uvx", "package": "mcp-server-fetch", "version": "--index-url=x"}},
+		{"runtime": map[string]interface{}{"type": "npx", "package": "server"}, "image": "node:20"},
+	}
+	for _, spec := range invalid {
+		if _, _, err := manager.applyRuntimeSpec(spec); err == nil {
+			t.Errorf("Expected json_spec %v to be rejected", spec)
+		}
+	}
+
+	req := models.CreateContainerRequest{
+		ServiceName: "fetch",
+		Port:        8000,
+		Runtime:     &models.RuntimeConfig{Type: models.RuntimeUvx, Package: "mcp-server-fetch"},
+	}
+	if err := manager.applyRuntimeRequest(&req); err != nil {
+		t.Fatalf("Expected runtime request to translate, got %v", err)
+	}
+	if req.Image != "supergateway:uvx" || req.HealthCheck == nil || req.HealthCheck.Path != runtimeHealthPath {
+		t.Errorf("Expected uvx bridge image with health path, got %s %+v", req.Image, req.HealthCheck)
+	}
+}
@@ -0,0 +1,625 @@
+package container
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// scalingLabel stores a container's scaling bounds and targets
+	scalingLabel = "mcp.scaling"
+	// replicaOfLabel names the instance a replica container serves
+	replicaOfLabel = "mcp.replica_of"
+	// scalingInterval is how often the load of scaled instances is sampled
+	scalingInterval = 30 * time.Second
+	// maxScalingReplicas bounds max_replicas
+	maxScalingReplicas = 20
+	// defaultTargetCPUPercent is the CPU use per replica above which one is added
+	defaultTargetCPUPercent = 70
+	// maxTargetCPUPercent bounds target_cpu_percent, a hundred cores
+	maxTargetCPUPercent = 10000
+	// defaultScaleDownDelay is how long the load must fit in one replica less before one is removed
+	defaultScaleDownDelay = 5 * time.Minute
+	// maxScaleDownDelay bounds scale_down_delay_seconds
+	maxScaleDownDelay = 24 * time.Hour
+)
+
+// openConnectionsMetric is the proxy's gauge of open connections per Traefik service
+const openConnectionsMetric = "traefik_service_open_connections"
+
+// scalingState keeps the last load sample of each scaled instance
+type scalingState struct {
+	mutex   sync.Mutex
+	samples map[string]*scalingSample
+}
+
+// scalingSample is the load of a scaled instance at its last evaluation
+type scalingSample struct {
+	cpuPercent  float64
+	connections float64
+	sampledAt   time.Time
+	scaledAt    time.Time
+	// fitsSince is when the load started to fit in one replica less, zero while it does not
+	fitsSince time.Time
+	// upstreams are the routed addresses of the replicas, keyed by service name
+	upstreams map[string]string
+}
+
+// parseScalingSpec reads the optional scaling object from json_spec
+func parseScalingSpec(jsonSpec map[string]interface{}) (*models.Scaling, error) {
+	raw, exists := jsonSpec["scaling"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+
+	if _, ok := raw.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("scaling must be an object")
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("scaling is not valid JSON: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	scaling := &models.Scaling{}
+	if err := decoder.Decode(scaling); err != nil {
+		return nil, fmt.Errorf("invalid scaling: %w", err)
+	}
+
+	if err := validateScaling(scaling, nil); err != nil {
+		return nil, err
+	}
+	return scaling, nil
+}
+
+// validateScaling checks the replica bounds and targets. Instances with a container per session
+// cannot also balance their route over replicas.
+func validateScaling(scaling *models.Scaling, route *models.RouteConfig) error {
+	if scaling == nil {
+		return nil
+	}
+	if scaling.MaxReplicas < 1 || scaling.MaxReplicas > maxScalingReplicas {
+		return fmt.Errorf("scaling.max_replicas must be between 1 and %d", maxScalingReplicas)
+	}
+	if scaling.MinReplicas < 0 || scaling.MinReplicas > scaling.MaxReplicas {
+		return fmt.Errorf("scaling.min_replicas must be between 0 and max_replicas")
+	}
+	if scaling.TargetCPUPercent < 0 || scaling.TargetCPUPercent > maxTargetCPUPercent {
+		return fmt.Errorf("scaling.target_cpu_percent must be between 0 and %d", maxTargetCPUPercent)
+	}
+	if scaling.TargetConnections < 0 {
+		return fmt.Errorf("scaling.target_connections must not be negative")
+	}
+	if scaling.ScaleDownDelaySeconds < 0 || time.Duration(scaling.ScaleDownDelaySeconds)*time.Second > maxScaleDownDelay {
+		return fmt.Errorf("scaling.scale_down_delay_seconds must be between 0 and %d", int(maxScaleDownDelay.Seconds()))
+	}
+	if route != nil && route.Sessions != nil {
+		return fmt.Errorf("scaling cannot be combined with route.sessions, which runs a container per session")
+	}
+	return nil
+}
+
+// checkScaling validates scaling for an instance with route. Replicas cannot be reached over
+// upstream mutual TLS, whose certificates name a single container.
+func (m *Manager) checkScaling(scaling *models.Scaling, route *models.RouteConfig) error {
+	if err := validateScaling(scaling, route); err != nil {
+		return err
+	}
+	if scaling != nil && m.config.MTLS.Upstream {
+		return fmt.Errorf("scaling is not available with upstream mutual TLS")
+	}
+	return nil
+}
+
+// scalingBounds returns the minimum and maximum number of containers serving a scaled instance
+func scalingBounds(scaling *models.Scaling) (int, int) {
+	return max(scaling.MinReplicas, 1), scaling.MaxReplicas
+}
+
+// targetCPUPercent returns the CPU use per replica above which one is added
+func targetCPUPercent(scaling *models.Scaling) float64 {
+	if scaling.TargetCPUPercent == 0 {
+		return defaultTargetCPUPercent
+	}
+	return float64(scaling.TargetCPUPercent)
+}
+
+// scaleDownDelay returns how long the load must fit in one replica less before one is removed
+func scaleDownDelay(scaling *models.Scaling) time.Duration {
+	if scaling.ScaleDownDelaySeconds == 0 {
+		return defaultScaleDownDelay
+	}
+	return time.Duration(scaling.ScaleDownDelaySeconds) * time.Second
+}
+
+// scalingDecision returns 1 when a replica should be added to the running containers serving an
+// instance, -1 when their total CPU use and connections would stay under the targets with one
+// container less, and 0 otherwise
+func scalingDecision(scaling *models.Scaling, running int, cpuPercent, connections float64) int {
+	minimum, maximum := scalingBounds(scaling)
+	switch {
+	case running < minimum:
+		return 1
+	case running > maximum:
+		return -1
+	}
+
+	target := targetCPUPercent(scaling)
+	perConnections := float64(scaling.TargetConnections)
+	busy := cpuPercent/float64(running) > target ||
+		scaling.TargetConnections > 0 && connections/float64(running) > perConnections
+	if busy {
+		if running < maximum {
+			return 1
+		}
+		return 0
+	}
+
+	if running > minimum && cpuPercent/float64(running-1) <= target &&
+		(scaling.TargetConnections == 0 || connections/float64(running-1) <= perConnections) {
+		return -1
+	}
+	return 0
+}
+
+// discoverScaling restores the scaling persisted on a podman container
+func (m *Manager) discoverScaling(ctx context.Context, containerID string) *models.Scaling {
+	var scaling models.Scaling
+	if !m.discoverJSONLabel(ctx, containerID, scalingLabel, &scaling) {
+		return nil
+	}
+	return &scaling
+}
+
+// replicaSpec returns the create request of a replica of parent
+func replicaSpec(parent *models.Container, serviceName string) models.CreateContainerRequest {
+	spec := containerSpec(parent)
+	spec.ServiceName = serviceName
+	spec.Labels = maps.Clone(parent.Labels)
+	if spec.Labels == nil {
+		spec.Labels = make(map[string]string)
+	}
+	spec.Labels[replicaOfLabel] = parent.ServiceName
+	// Replicas are not instances known to the core API, and come and go with the load
+	delete(spec.Environment, "MCP_INSTANCE_ID")
+	spec.Schedule = nil
+	spec.Scaling = nil
+	// The parent's route, host included, balances to the replica
+	if spec.Routing != nil && spec.Routing.Type == models.RoutingHost {
+		spec.Routing = nil
+	}
+	return spec
+}
+
+// replicasUnsafe returns the replicas of serviceName, oldest first (caller must hold lock)
+func (m *Manager) replicasUnsafe(serviceName string) []*models.Container {
+	var replicas []*models.Container
+	for _, container := range m.containers {
+		if container.Labels[replicaOfLabel] == serviceName {
+			replicas = append(replicas, container)
+		}
+	}
+	sort.Slice(replicas, func(i, j int) bool {
+		return replicas[i].CreatedAt.Before(replicas[j].CreatedAt)
+	})
+	return replicas
+}
+
+// replicaServiceNameUnsafe returns the first free replica name of serviceName (caller must hold lock)
+func (m *Manager) replicaServiceNameUnsafe(serviceName string) string {
+	for n := 1; ; n++ {
+		name := fmt.Sprintf("%s-replica-%d", serviceName, n)
+		if _, exists := m.containers[name]; !exists {
+			return name
+		}
+	}
+}
+
+// startAutoscaler periodically scales the replicas of scaled instances to their load
+func (m *Manager) startAutoscaler() {
+	ticker := time.NewTicker(scalingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.healthCtx.Done():
+			return
+		case <-ticker.C:
+			m.autoscale(m.healthCtx)
+		}
+	}
+}
+
+// scaledInstance is a running scaled instance and its replicas at the start of an evaluation
+type scaledInstance struct {
+	parent   models.Container
+	replicas []models.Container
+}
+
+// autoscale samples the load of every running scaled instance, adds or removes one replica of each
+// as its load requires and balances its route over the running replicas. Replicas whose instance
+// is gone, stopped or no longer scaled are removed.
+func (m *Manager) autoscale(ctx context.Context) {
+	m.mutex.RLock()
+	var instances []scaledInstance
+	var orphans []string
+	for _, container := range m.containers {
+		if parentName := container.Labels[replicaOfLabel]; parentName != "" {
+			parent, exists := m.containers[parentName]
+			if !exists || parent.Scaling == nil || parent.StoppedAt != nil {
+				orphans = append(orphans, container.ServiceName)
+			}
+			continue
+		}
+		if container.Scaling == nil || container.StoppedAt != nil || container.Status != models.StatusRunning {
+			continue
+		}
+		instance := scaledInstance{parent: *container}
+		for _, replica := range m.replicasUnsafe(container.ServiceName) {
+			instance.replicas = append(instance.replicas, *replica)
+		}
+		instances = append(instances, instance)
+	}
+	m.mutex.RUnlock()
+
+	for _, serviceName := range orphans {
+		if err := m.DeleteContainer(ctx, serviceName); err != nil {
+			m.logger.WarnContext(ctx, "Failed to remove replica of an unscaled instance",
+				slog.String("service", serviceName),
+				slog.String("error", err.Error()))
+			continue
+		}
+		m.logger.InfoContext(ctx, "Removed replica of an unscaled instance", slog.String("service", serviceName))
+	}
+	if len(instances) == 0 {
+		return
+	}
+
+	var names []string
+	for _, instance := range instances {
+		names = append(names, instance.parent.Name)
+		for _, replica := range instance.replicas {
+			names = append(names, replica.Name)
+		}
+	}
+	cpu, err := m.sampleCPU(ctx, names)
+	if err != nil {
+		m.logger.WarnContext(ctx, "Failed to sample CPU use of scaled instances", slog.String("error", err.Error()))
+		return
+	}
+	connections := m.sampleConnections(ctx)
+
+	for _, instance := range instances {
+		m.scaleInstance(ctx, instance, cpu, connections)
+	}
+}
+
+// scaleInstance applies one scaling decision to instance and balances its route over its running
+// replicas
+func (m *Manager) scaleInstance(ctx context.Context, instance scaledInstance, cpu map[string]float64, connections map[string]float64) {
+	parent := &instance.parent
+	running := []models.Container{*parent}
+	pending := 0
+	for _, replica := range instance.replicas {
+		if replica.Status == models.StatusRunning {
+			running = append(running, replica)
+		} else {
+			pending++
+		}
+	}
+	totalCPU := 0.0
+	for _, container := range running {
+		totalCPU += cpu[container.Name]
+	}
+	// The route's service balances over every replica, so its connections are the instance's
+	totalConnections := connections[fmt.Sprintf("mcp-%s-service", parent.Slug)]
+
+	now := time.Now()
+	m.scaling.mutex.Lock()
+	if m.scaling.samples == nil {
+		m.scaling.samples = make(map[string]*scalingSample)
+	}
+	sample, exists := m.scaling.samples[parent.ServiceName]
+	if !exists {
+		sample = &scalingSample{}
+		m.scaling.samples[parent.ServiceName] = sample
+	}
+	sample.cpuPercent = totalCPU / float64(len(running))
+	sample.connections = totalConnections / float64(len(running))
+	sample.sampledAt = now
+
+	decision := scalingDecision(parent.Scaling, len(running), totalCPU, totalConnections)
+	if decision < 0 {
+		if sample.fitsSince.IsZero() {
+			sample.fitsSince = now
+		}
+		if now.Sub(sample.fitsSince) < scaleDownDelay(parent.Scaling) {
+			decision = 0
+		}
+	} else {
+		sample.fitsSince = time.Time{}
+	}
+	m.scaling.mutex.Unlock()
+
+	_, maximum := scalingBounds(parent.Scaling)
+	switch {
+	case decision > 0 && pending == 0 && 1+len(instance.replicas) < maximum && !m.IsPreempted():
+		m.addReplica(ctx, parent, len(running), totalCPU, totalConnections)
+	case decision < 0 && len(instance.replicas) > 0:
+		// A replica that is not running serves nothing, otherwise the newest has done the least work
+		victim := instance.replicas[len(instance.replicas)-1]
+		for _, replica := range instance.replicas {
+			if replica.Status != models.StatusRunning {
+				victim = replica
+				break
+			}
+		}
+		m.removeReplica(ctx, parent, victim.ServiceName, len(running), totalCPU, totalConnections)
+	}
+
+	m.routeReplicas(ctx, parent)
+}
+
+// addReplica creates a replica of parent
+func (m *Manager) addReplica(ctx context.Context, parent *models.Container, running int, cpuPercent, connections float64) {
+	m.mutex.RLock()
+	serviceName := m.replicaServiceNameUnsafe(parent.ServiceName)
+	m.mutex.RUnlock()
+
+	if _, err := m.CreateContainer(ctx, replicaSpec(parent, serviceName)); err != nil {
+		m.logger.WarnContext(ctx, "Failed to add replica",
+			slog.String("service", parent.ServiceName),
+			slog.String("replica", serviceName),
+			slog.String("error", err.Error()))
+		return
+	}
+	m.markScaled(parent.ServiceName)
+	m.logger.InfoContext(ctx, "Scaled up instance",
+		slog.String("service", parent.ServiceName),
+		slog.String("replica", serviceName),
+		slog.Int("replicas", running+1),
+		slog.Float64("cpu_percent", cpuPercent),
+		slog.Float64("connections", connections))
+}
+
+// removeReplica deletes a replica of parent once its route no longer balances to it
+func (m *Manager) removeReplica(ctx context.Context, parent *models.Container, serviceName string, running int, cpuPercent, connections float64) {
+	m.scaling.mutex.Lock()
+	if sample, exists := m.scaling.samples[parent.ServiceName]; exists {
+		delete(sample.upstreams, serviceName)
+	}
+	m.scaling.mutex.Unlock()
+	m.routeReplicas(ctx, parent)
+
+	if err := m.DeleteContainer(ctx, serviceName); err != nil {
+		m.logger.WarnContext(ctx, "Failed to remove replica",
+			slog.String("service", parent.ServiceName),
+			slog.String("replica", serviceName),
+			slog.String("error", err.Error()))
+		return
+	}
+	m.markScaled(parent.ServiceName)
+	m.logger.InfoContext(ctx, "Scaled down instance",
+		slog.String("service", parent.ServiceName),
+		slog.String("replica", serviceName),
+		slog.Int("replicas", running-1),
+		slog.Float64("cpu_percent", cpuPercent),
+		slog.Float64("connections", connections))
+}
+
+// markScaled records that an instance's replicas changed, restarting its scale-down delay
+func (m *Manager) markScaled(serviceName string) {
+	m.scaling.mutex.Lock()
+	defer m.scaling.mutex.Unlock()
+	if sample, exists := m.scaling.samples[serviceName]; exists {
+		sample.scaledAt = time.Now()
+		sample.fitsSince = time.Time{}
+	}
+}
+
+// routeReplicas balances parent's route over parent and its running replicas
+func (m *Manager) routeReplicas(ctx context.Context, parent *models.Container) {
+	m.mutex.RLock()
+	var replicas []models.Container
+	for _, replica := range m.replicasUnsafe(parent.ServiceName) {
+		if replica.Status == models.StatusRunning {
+			replicas = append(replicas, *replica)
+		}
+	}
+	m.mutex.RUnlock()
+
+	upstreams := make(map[string]string)
+	var urls []string
+	for _, replica := range replicas {
+		containerIP, err := m.getContainerIP(ctx, replica.ID)
+		if err != nil {
+			m.logger.WarnContext(ctx, "Replica not routed, its address is unknown",
+				slog.String("replica", replica.ServiceName),
+				slog.String("error", err.Error()))
+			continue
+		}
+		upstreamHost, upstreamPort := m.upstreamAddress(ctx, &replica, containerIP)
+		upstreams[replica.ServiceName] = mcpUpstreamURL(upstreamHost, upstreamPort)
+		urls = append(urls, upstreams[replica.ServiceName])
+	}
+
+	if err := m.traefikManager.SetReplicaServers(ctx, parent.Slug, urls); err != nil {
+		m.logger.WarnContext(ctx, "Failed to balance route over replicas",
+			slog.String("service", parent.ServiceName),
+			slog.String("error", err.Error()))
+		return
+	}
+	m.scaling.mutex.Lock()
+	if sample, exists := m.scaling.samples[parent.ServiceName]; exists {
+		sample.upstreams = upstreams
+	}
+	m.scaling.mutex.Unlock()
+}
+
+// sampleCPU returns the CPU use of the named containers, in percent of one core
+func (m *Manager) sampleCPU(ctx context.Context, names []string) (map[string]float64, error) {
+	args := append([]string{"stats", "--no-stream", "--format", "json"}, names...)
+	output, err := podmanCommand(ctx, m.logger, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read container stats: %w", err)
+	}
+	return parseStatsCPU(output)
+}
+
+// parseStatsCPU reads the CPU use per container name from `podman stats --format json`, which
+// reports it as a percentage string such as "12.5%"
+func parseStatsCPU(data []byte) (map[string]float64, error) {
+	var stats []struct {
+		Name       string          `json:"name"`
+		CPUPercent json.RawMessage `json:"cpu_percent"`
+	}
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, fmt.Errorf("failed to parse container stats: %w", err)
+	}
+
+	cpu := make(map[string]float64, len(stats))
+	for _, entry := range stats {
+		text := strings.TrimSuffix(strings.Trim(string(entry.CPUPercent), `"`), "%")
+		value, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			continue
+		}
+		cpu[entry.Name] = value
+	}
+	return cpu, nil
+}
+
+// sampleConnections returns the open proxy connections per Traefik service, nil when the proxy's
+// metrics are not exported and instances scale on CPU only
+func (m *Manager) sampleConnections(ctx context.Context) map[string]float64 {
+	metricsURL, err := m.proxyMetricsURL()
+	if err != nil {
+		return nil
+	}
+	var connections map[string]float64
+	if err := readProxyMetrics(ctx, metricsURL, func(r io.Reader) { connections = parseOpenConnections(r) }); err != nil {
+		m.logger.WarnContext(ctx, "Failed to read open connections from the proxy", slog.String("error", err.Error()))
+		return nil
+	}
+	return connections
+}
+
+// parseOpenConnections sums the Prometheus text exposition of traefik_service_open_connections by
+// service, without the provider suffix
+func parseOpenConnections(r io.Reader) map[string]float64 {
+	connections := make(map[string]float64)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if service, _, value, found := serviceSample(scanner.Text(), openConnectionsMetric); found {
+			connections[service] += value
+		}
+	}
+	return connections
+}
+
+// GetScaling reports a container's replicas and the load they were last scaled on
+func (m *Manager) GetScaling(serviceName string) (*models.ScalingStatus, error) {
+	m.mutex.RLock()
+	parent, exists := m.containers[serviceName]
+	if !exists {
+		m.mutex.RUnlock()
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+	status := &models.ScalingStatus{ServiceName: serviceName, Scaling: parent.Scaling, Replicas: []models.Replica{}}
+	if parent.Status == models.StatusRunning && parent.StoppedAt == nil {
+		status.Running = 1
+	}
+	for _, replica := range m.replicasUnsafe(serviceName) {
+		status.Replicas = append(status.Replicas, models.Replica{
+			ServiceName: replica.ServiceName,
+			Status:      replica.Status,
+			CreatedAt:   replica.CreatedAt,
+		})
+		if replica.Status == models.StatusRunning {
+			status.Running++
+		}
+	}
+	m.mutex.RUnlock()
+
+	m.scaling.mutex.Lock()
+	defer m.scaling.mutex.Unlock()
+	sample, sampled := m.scaling.samples[serviceName]
+	if !sampled {
+		return status, nil
+	}
+	status.CPUPercent = sample.cpuPercent
+	status.Connections = sample.connections
+	sampledAt := sample.sampledAt
+	status.SampledAt = &sampledAt
+	if !sample.scaledAt.IsZero() {
+		scaledAt := sample.scaledAt
+		status.LastScaledAt = &scaledAt
+	}
+	for i := range status.Replicas {
+		status.Replicas[i].Upstream = sample.upstreams[status.Replicas[i].ServiceName]
+	}
+	return status, nil
+}
+
+// SetReplicaServers balances the route with slug over its own container and the replicas at
+// urls. The replicas are kept when the route is added again, as when its container moves.
+func (tm *TraefikManager) SetReplicaServers(ctx context.Context, slug string, urls []string) error {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	if len(urls) == 0 {
+		delete(tm.replicas, slug)
+	} else {
+		if tm.replicas == nil {
+			tm.replicas = make(map[string][]string)
+		}
+		tm.replicas[slug] = slices.Clone(urls)
+	}
+
+	config, err := tm.loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	name := fmt.Sprintf("mcp-%s-service", slug)
+	service, exists := config.HTTP.Services[name]
+	if !exists || len(service.LoadBalancer.Servers) == 0 {
+		// The replicas are added with the route
+		return nil
+	}
+
+	servers := service.LoadBalancer.Servers[:1:1]
+	for _, url := range urls {
+		servers = append(servers, TraefikServer{URL: url})
+	}
+	if slices.Equal(servers, service.LoadBalancer.Servers) {
+		return nil
+	}
+	service.LoadBalancer.Servers = servers
+	config.HTTP.Services[name] = service
+
+	if err := tm.saveConfig(config); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	tm.logger.InfoContext(ctx, "Balanced MCP route over replicas",
+		slog.String("slug", slug),
+		slog.Int("servers", len(servers)))
+	return nil
+}
@@ -0,0 +1,98 @@
+package container
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestReplicaScaling(t *testing.T) {
+	if err := validateScaling(&models.Scaling{MinReplicas: 3, MaxReplicas: 2}, nil); err == nil {
+		t.Error("Expected min_replicas above max_replicas to be rejected")
+	}
+	if err := validateScaling(&models.Scaling{MaxReplicas: 2}, &models.RouteConfig{Sessions: &models.RouteSessions{}}); err == nil {
+		t.Error("Expected scaling with per-session containers to be rejected")
+	}
+	if _, err := parseScalingSpec(map[string]interface{}{"scaling": map[string]interface{}{"max_replicas": 3, "replicas": 2}}); err == nil {
+		t.Error("Expected an unknown scaling field to be rejected")
+	}
+
+	scaling := &models.Scaling{MinReplicas: 1, MaxReplicas: 3, TargetCPUPercent: 50, TargetConnections: 10}
+	cases := []struct {
+		running     int
+		cpu         float64
+		connections float64
+		expected    int
+	}{
+		{1, 80, 0, 1},
+		{1, 10, 15, 1},
+		{3, 200, 0, 0},
+		{2, 40, 8, -1},
+		{2, 60, 8, 0},
+		{2, 40, 12, 0},
+		{1, 0, 0, 0},
+	}
+	for _, tc := range cases {
+		if got := scalingDecision(scaling, tc.running, tc.cpu, tc.connections); got != tc.expected {
+			t.Errorf("Expected %d for %d running at %.0f%% CPU and %.0f connections, got %d", tc.expected, tc.running, tc.cpu, tc.connections, got)
+		}
+	}
+
+	cpu, err := parseStatsCPU([]byte(`[{"id":"ab12","name":"test-search","cpu_percent":"12.50%"},{"id":"cd34","name":"test-search-replica-1","cpu_percent":"--"}]`))
+	if err != nil || cpu["test-search"] != 12.5 || len(cpu) != 1 {
+		t.Errorf("Expected 12.5%% for test-search only, got %v, %v", cpu, err)
+	}
+	connections := parseOpenConnections(strings.NewReader(`traefik_service_open_connections{method="GET",protocol="http",service="mcp-search-ab12-service@file"} 3
+traefik_service_open_connections{method="POST",protocol="http",service="mcp-search-ab12-service@file"} 4
+`))
+	if connections["mcp-search-ab12-service"] != 7 {
+		t.Errorf("Expected 7 open connections, got %v", connections)
+	}
+
+	parent := &models.Container{
+		ServiceName: "search",
+		Environment: map[string]string{"MCP_INSTANCE_ID": "inst-1", "API_KEY": "secret"},
+		Scaling:     scaling,
+		Routing:     &models.RoutingConfig{Type: models.RoutingHost},
+	}
+	spec := replicaSpec(parent, "search-replica-1")
+	if spec.Labels[replicaOfLabel] != "search" || spec.Scaling != nil || spec.Routing != nil {
+		t.Errorf("Expected a labelled replica without scaling or host routing, got %+v", spec)
+	}
+	if _, exists := spec.Environment["MCP_INSTANCE_ID"]; exists || spec.Environment["API_KEY"] != "secret" {
+		t.Errorf("Expected the replica to drop only the instance ID, got %v", spec.Environment)
+	}
+
+	cfg := &config.Config{Traefik: config.TraefikConfig{ManagerServiceURL: "http://localhost:8000"}}
+	tm := NewTraefikManager(cfg, testLogger())
+	tm.configPath = filepath.Join(t.TempDir(), "dynamic.yml")
+	ctx := context.Background()
+	if err := tm.AddMCPService(ctx, "search-ab12", "10.88.0.5", 3000, nil, nil, nil); err != nil {
+		t.Fatalf("Failed to add route: %v", err)
+	}
+	if err := tm.SetReplicaServers(ctx, "search-ab12", []string{"http://10.88.0.6:3000"}); err != nil {
+		t.Fatalf("Failed to balance over replicas: %v", err)
+	}
+	// Moving the instance's own container keeps the replicas behind the route
+	if err := tm.AddMCPService(ctx, "search-ab12", "10.88.0.9", 3000, nil, nil, nil); err != nil {
+		t.Fatalf("Failed to update route: %v", err)
+	}
+	dynamic, err := tm.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	servers := dynamic.HTTP.Services["mcp-search-ab12-service"].LoadBalancer.Servers
+	if len(servers) != 2 || servers[0].URL != "http://10.88.0.9:3000" || servers[1].URL != "http://10.88.0.6:3000" {
+		t.Errorf("Expected the container and its replica, got %+v", servers)
+	}
+	if err := tm.SetReplicaServers(ctx, "search-ab12", nil); err != nil {
+		t.Fatalf("Failed to remove replicas: %v", err)
+	}
+	if dynamic, _ = tm.LoadConfig(); len(dynamic.HTTP.Services["mcp-search-ab12-service"].LoadBalancer.Servers) != 1 {
+		t.Errorf("Expected only the container after scaling down, got %+v", dynamic.HTTP.Services["mcp-search-ab12-service"].LoadBalancer.Servers)
+	}
+}
@@ -0,0 +1,198 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/cron"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// scheduleLabel stores a container's start/stop schedule
+	scheduleLabel = "mcp.schedule"
+	// scheduledOffBucket records the containers their schedule stopped, keyed by service name
+	scheduledOffBucket = "scheduled_off"
+	// scheduleCheckInterval is how often schedules are evaluated
+	scheduleCheckInterval = 30 * time.Second
+)
+
+// scheduleState remembers, per service, whether its schedule last wanted it running. The
+// scheduler only acts when that changes, so a user starting or stopping a scheduled container
+// by hand is respected until the next window boundary.
+type scheduleState struct {
+	mutex   sync.Mutex
+	running map[string]bool
+}
+
+// parseScheduleSpec reads the optional schedule object from json_spec
+func parseScheduleSpec(jsonSpec map[string]interface{}) (*models.Schedule, error) {
+	raw, exists := jsonSpec["schedule"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+
+	if _, ok := raw.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("schedule must be an object")
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("schedule is not valid JSON: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	schedule := &models.Schedule{}
+	if err := decoder.Decode(schedule); err != nil {
+		return nil, fmt.Errorf("invalid schedule: %w", err)
+	}
+
+	if err := validateSchedule(schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// validateSchedule checks both cron expressions and the time zone
+func validateSchedule(schedule *models.Schedule) error {
+	if schedule == nil {
+		return nil
+	}
+	if schedule.StartCron == "" || schedule.StopCron == "" {
+		return fmt.Errorf("schedule.start_cron and schedule.stop_cron are required")
+	}
+	if _, err := cron.Parse(schedule.StartCron); err != nil {
+		return fmt.Errorf("invalid schedule.start_cron: %w", err)
+	}
+	if _, err := cron.Parse(schedule.StopCron); err != nil {
+		return fmt.Errorf("invalid schedule.stop_cron: %w", err)
+	}
+	if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+		return fmt.Errorf("invalid schedule.timezone %q: %w", schedule.Timezone, err)
+	}
+	return nil
+}
+
+// scheduleWantsRunning reports whether now falls inside a window of the schedule, which is the
+// case when the start expression fired more recently than the stop expression
+func scheduleWantsRunning(schedule *models.Schedule, now time.Time) (bool, error) {
+	start, err := cron.Parse(schedule.StartCron)
+	if err != nil {
+		return false, err
+	}
+	stop, err := cron.Parse(schedule.StopCron)
+	if err != nil {
+		return false, err
+	}
+	location, err := time.LoadLocation(schedule.Timezone)
+	if err != nil {
+		return false, err
+	}
+
+	now = now.In(location)
+	lastStart, started := start.Prev(now)
+	if !started {
+		return false, nil
+	}
+	lastStop, stopped := stop.Prev(now)
+	return !stopped || lastStart.After(lastStop), nil
+}
+
+// discoverSchedule restores the schedule persisted on a podman container
+func (m *Manager) discoverSchedule(ctx context.Context, containerID string) *models.Schedule {
+	var schedule models.Schedule
+	if !m.discoverJSONLabel(ctx, containerID, scheduleLabel, &schedule) {
+		return nil
+	}
+	return &schedule
+}
+
+// startScheduler starts and stops scheduled containers at their window boundaries
+func (m *Manager) startScheduler() {
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		m.applySchedules(m.healthCtx, time.Now())
+
+		select {
+		case <-m.healthCtx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// applySchedules stops running containers whose window closed and starts the ones their schedule
+// stopped once their window opens. A container is also brought in line the first time it is seen.
+func (m *Manager) applySchedules(ctx context.Context, now time.Time) {
+	type scheduled struct {
+		serviceName string
+		schedule    *models.Schedule
+		status      models.ContainerStatus
+	}
+
+	m.mutex.RLock()
+	candidates := make([]scheduled, 0)
+	for serviceName, container := range m.containers {
+		if container.Schedule != nil {
+			candidates = append(candidates, scheduled{serviceName, container.Schedule, container.Status})
+		}
+	}
+	m.mutex.RUnlock()
+
+	for _, candidate := range candidates {
+		wantsRunning, err := scheduleWantsRunning(candidate.schedule, now)
+		if err != nil {
+			m.logger.WarnContext(ctx, "Ignoring invalid schedule",
+				slog.String("service", candidate.serviceName),
+				slog.String("error", err.Error()))
+			continue
+		}
+
+		m.scheduling.mutex.Lock()
+		if m.scheduling.running == nil {
+			m.scheduling.running = make(map[string]bool)
+		}
+		previous, seen := m.scheduling.running[candidate.serviceName]
+		m.scheduling.running[candidate.serviceName] = wantsRunning
+		m.scheduling.mutex.Unlock()
+		if seen && previous == wantsRunning {
+			continue
+		}
+
+		switch {
+		case wantsRunning && candidate.status == models.StatusScheduledOff:
+			if _, err := m.StartContainer(ctx, candidate.serviceName); err != nil {
+				m.logger.ErrorContext(ctx, "Failed to start scheduled container",
+					slog.String("service", candidate.serviceName),
+					slog.String("error", err.Error()))
+				m.forgetSchedule(candidate.serviceName)
+				continue
+			}
+			m.logger.InfoContext(ctx, "Started container for its schedule window",
+				slog.String("service", candidate.serviceName))
+		case !wantsRunning && candidate.status == models.StatusRunning:
+			if _, err := m.stopContainer(ctx, candidate.serviceName, true); err != nil {
+				m.logger.ErrorContext(ctx, "Failed to stop scheduled container",
+					slog.String("service", candidate.serviceName),
+					slog.String("error", err.Error()))
+				m.forgetSchedule(candidate.serviceName)
+			}
+		}
+	}
+}
+
+// forgetSchedule drops the scheduler's memory of a container, so it is brought in line again
+func (m *Manager) forgetSchedule(serviceName string) {
+	m.scheduling.mutex.Lock()
+	defer m.scheduling.mutex.Unlock()
+	delete(m.scheduling.running, serviceName)
+}
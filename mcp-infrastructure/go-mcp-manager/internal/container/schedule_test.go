@@ -0,0 +1,77 @@
+package container
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestSchedule(t *testing.T) {
+	cfg := &config.Config{Container: config.ContainerConfig{NamePrefix: "mcp-", ManagedByLabel: "mcp-manager"}}
+	manager := newTestManager(cfg)
+	ctx := context.Background()
+
+	invalid := []map[string]interface{}{
+		{"schedule": "0 9 * * *"},
+		{"schedule": map[string]interface{}{"start_cron": "0 9 * * *"}},
+		{"schedule": map[string]interface{}{"start_cron": "0 25 * * *", "stop_cron": "0 17 * * *"}},
+		{"schedule": map[string]interface{}{"start_cron": "0 9 * * *", "stop_cron": "0 17 * * *", "timezone": "Mars/Olympus"}},
+		{"schedule": map[string]interface{}{"start_cron": "0 9 * * *", "stop_cron": "0 17 * * *", "days": "weekdays"}},
+	}
+	for _, spec := range invalid {
+		if _, err := parseScheduleSpec(spec); err == nil {
+			t.Errorf("Expected schedule %v to be rejected", spec["schedule"])
+		}
+	}
+
+	schedule, err := parseScheduleSpec(map[string]interface{}{
+		"schedule": map[string]interface{}{"start_cron": "0 9 * * 1-5", "stop_cron": "0 17 * * 1-5", "timezone": "Europe/Berlin"},
+	})
+	if err != nil || schedule == nil {
+		t.Fatalf("Expected the schedule to be accepted, got %v", err)
+	}
+
+	// Business hours in Berlin, which is UTC+2 in summer
+	cases := []struct {
+		now     time.Time
+		running bool
+	}{
+		{time.Date(2026, 6, 10, 8, 0, 0, 0, time.UTC), true},   // Wednesday 10:00 in Berlin
+		{time.Date(2026, 6, 10, 6, 30, 0, 0, time.UTC), false}, // Wednesday 08:30 in Berlin
+		{time.Date(2026, 6, 10, 15, 0, 0, 0, time.UTC), false}, // Wednesday 17:00 in Berlin
+		{time.Date(2026, 6, 13, 10, 0, 0, 0, time.UTC), false}, // Saturday
+	}
+	for _, c := range cases {
+		running, err := scheduleWantsRunning(schedule, c.now)
+		if err != nil {
+			t.Fatalf("Expected the schedule to evaluate, got %v", err)
+		}
+		if running != c.running {
+			t.Errorf("Expected running=%v at %s, got %v", c.running, c.now, running)
+		}
+	}
+
+	// Containers already in line with their schedule are left alone, and the window is remembered
+	manager.containers["stopped"] = &models.Container{Name: "mcp-stopped", ServiceName: "stopped", Status: models.StatusStopped, Schedule: schedule}
+	manager.containers["off"] = &models.Container{Name: "mcp-off", ServiceName: "off", Status: models.StatusScheduledOff, Schedule: schedule}
+	manager.containers["always"] = &models.Container{Name: "mcp-always", ServiceName: "always", Status: models.StatusRunning}
+	manager.applySchedules(ctx, time.Date(2026, 6, 13, 10, 0, 0, 0, time.UTC))
+
+	if manager.containers["off"].Status != models.StatusScheduledOff || manager.containers["stopped"].Status != models.StatusStopped {
+		t.Error("Expected containers outside their window to stay stopped")
+	}
+	if running, seen := manager.scheduling.running["off"]; !seen || running {
+		t.Errorf("Expected the closed window to be remembered, got %v %v", running, seen)
+	}
+	if _, seen := manager.scheduling.running["always"]; seen {
+		t.Error("Expected containers without a schedule to be ignored")
+	}
+
+	manager.forgetSchedule("off")
+	if _, seen := manager.scheduling.running["off"]; seen {
+		t.Error("Expected the schedule memory to be dropped")
+	}
+}
@@ -0,0 +1,222 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// scratchLabel stores a container's tmpfs and scratch volume mounts on the podman container
+const scratchLabel = "mcp.scratch"
+
+// scratchMounts is the persisted form of a container's writable mounts
+type scratchMounts struct {
+	Tmpfs          []models.ScratchMount `json:"tmpfs,omitempty"`
+	ScratchVolumes []models.ScratchMount `json:"scratch_volumes,omitempty"`
+}
+
+// parseScratchSpec reads the optional tmpfs and scratch_volumes lists from json_spec
+func parseScratchSpec(jsonSpec map[string]interface{}) ([]models.ScratchMount, []models.ScratchMount, error) {
+	var mounts scratchMounts
+	for _, field := range []struct {
+		name   string
+		target *[]models.ScratchMount
+	}{
+		{"tmpfs", &mounts.Tmpfs},
+		{"scratch_volumes", &mounts.ScratchVolumes},
+	} {
+		raw, exists := jsonSpec[field.name]
+		if !exists || raw == nil {
+			continue
+		}
+		list, ok := raw.([]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("%s must be an array of {path, size} objects", field.name)
+		}
+		for _, item := range list {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, nil, fmt.Errorf("%s must be an array of {path, size} objects", field.name)
+			}
+			mountPath, _ := entry["path"].(string)
+			size, _ := entry["size"].(string)
+			*field.target = append(*field.target, models.ScratchMount{Path: mountPath, Size: size})
+		}
+	}
+
+	if err := validateScratchMounts(mounts.Tmpfs, mounts.ScratchVolumes); err != nil {
+		return nil, nil, err
+	}
+	return mounts.Tmpfs, mounts.ScratchVolumes, nil
+}
+
+// validateScratchMounts checks mount paths and size formats; quotas are checked by checkScratchPolicy
+func validateScratchMounts(tmpfs, volumes []models.ScratchMount) error {
+	seen := make(map[string]bool)
+	for _, mount := range append(append([]models.ScratchMount{}, tmpfs...), volumes...) {
+		if !strings.HasPrefix(mount.Path, "/") || path.Clean(mount.Path) != mount.Path || mount.Path == "/" {
+			return fmt.Errorf("mount path %q must be an absolute, clean path below /", mount.Path)
+		}
+		if strings.ContainsAny(mount.Path, ":,") {
+			return fmt.Errorf("mount path %q must not contain ':' or ','", mount.Path)
+		}
+		if seen[mount.Path] {
+			return fmt.Errorf("mount path %s is listed more than once", mount.Path)
+		}
+		seen[mount.Path] = true
+
+		if mount.Size != "" {
+			if _, err := config.ParseMemory(mount.Size); err != nil {
+				return fmt.Errorf("mount %s: %w", mount.Path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkScratchPolicy enforces the configured mount count and size limits
+func (m *Manager) checkScratchPolicy(tmpfs, volumes []models.ScratchMount) error {
+	if err := validateScratchMounts(tmpfs, volumes); err != nil {
+		return err
+	}
+
+	limits := m.config.Container
+	if limits.MaxScratchMounts > 0 && len(tmpfs)+len(volumes) > limits.MaxScratchMounts {
+		return fmt.Errorf("at most %d tmpfs and scratch mounts are allowed", limits.MaxScratchMounts)
+	}
+	if limits.MaxScratchSize == "" {
+		return nil
+	}
+
+	maxBytes, err := config.ParseMemory(limits.MaxScratchSize)
+	if err != nil {
+		return fmt.Errorf("invalid scratch size limit: %w", err)
+	}
+	for _, mount := range append(append([]models.ScratchMount{}, tmpfs...), volumes...) {
+		if bytes := m.scratchBytes(mount); bytes > maxBytes {
+			return fmt.Errorf("mount %s size %s exceeds the limit of %s", mount.Path, mount.Size, limits.MaxScratchSize)
+		}
+	}
+	return nil
+}
+
+// scratchBytes returns a mount's size in bytes, applying the default size; zero means unlimited
+func (m *Manager) scratchBytes(mount models.ScratchMount) int64 {
+	size := mount.Size
+	if size == "" {
+		size = m.config.Container.DefaultScratchSize
+	}
+	if size == "" {
+		return 0
+	}
+	bytes, err := config.ParseMemory(size)
+	if err != nil {
+		return 0
+	}
+	return bytes
+}
+
+// scratchVolumeName returns the podman volume backing a container's scratch mount
+func scratchVolumeName(containerName string, index int) string {
+	return fmt.Sprintf("%s-scratch-%d", containerName, index)
+}
+
+// podmanScratchArgs converts tmpfs and scratch mounts and projected files to podman run flags
+func (m *Manager) podmanScratchArgs(container *models.Container) []string {
+	var args []string
+	for _, mount := range container.Tmpfs {
+		options := "rw,nosuid,nodev,mode=1777"
+		if bytes := m.scratchBytes(mount); bytes > 0 {
+			options += fmt.Sprintf(",size=%d", bytes)
+		}
+		args = append(args, "--tmpfs", mount.Path+":"+options)
+	}
+	for i, mount := range container.ScratchVolumes {
+		// U chowns the volume to the container user, which is non-root when hardened
+		args = append(args, "-v", fmt.Sprintf("%s:%s:rw,U", scratchVolumeName(container.Name, i), mount.Path))
+	}
+
+	if len(container.Tmpfs) > 0 || len(container.ScratchVolumes) > 0 {
+		if data, err := json.Marshal(scratchMounts{Tmpfs: container.Tmpfs, ScratchVolumes: container.ScratchVolumes}); err == nil {
+			args = append(args, "--label", fmt.Sprintf("%s=%s", scratchLabel, data))
+		}
+	}
+	return append(args, m.podmanFilesArgs(container)...)
+}
+
+// createScratchVolumes creates the size-limited podman volumes for a container's scratch mounts
+// and the secrets its projected files are mounted from. Volumes that already exist are kept, and
+// a failure only removes the volumes this call created, as a container being replaced may still
+// mount the others.
+func (m *Manager) createScratchVolumes(ctx context.Context, container *models.Container) error {
+	var created []string
+	removeCreated := func() {
+		for _, name := range created {
+			m.removeScratchVolume(ctx, name)
+		}
+	}
+	for i, mount := range container.ScratchVolumes {
+		name := scratchVolumeName(container.Name, i)
+		if err := podmanCommand(ctx, m.logger, "volume", "exists", name).Run(); err == nil {
+			continue
+		}
+
+		args := []string{"volume", "create", "--label", fmt.Sprintf("%s=%s", scratchLabel, container.Name)}
+		bytes := m.scratchBytes(mount)
+		if bytes > 0 {
+			args = append(args, "--opt", fmt.Sprintf("size=%d", bytes))
+		}
+
+		output, err := podmanCommand(ctx, m.logger, append(args, name)...).CombinedOutput()
+		if err != nil && bytes > 0 {
+			// Size quotas need storage with project quota support; fall back to an unbounded volume
+			m.logger.WarnContext(ctx, "Scratch volume size limit not supported, creating without it",
+				slog.String("volume", name),
+				slog.String("output", strings.TrimSpace(string(output))))
+			args = args[:len(args)-2]
+			output, err = podmanCommand(ctx, m.logger, append(args, name)...).CombinedOutput()
+		}
+		if err != nil {
+			removeCreated()
+			return fmt.Errorf("failed to create scratch volume %s: %w: %s", name, err, strings.TrimSpace(string(output)))
+		}
+		created = append(created, name)
+	}
+	if err := m.createFileSecrets(ctx, container); err != nil {
+		removeCreated()
+		m.removeFileSecrets(ctx, container)
+		return err
+	}
+	return nil
+}
+
+// removeScratchVolumes deletes a container's scratch volumes and file secrets once the container is gone
+func (m *Manager) removeScratchVolumes(ctx context.Context, container *models.Container) {
+	for i := range container.ScratchVolumes {
+		m.removeScratchVolume(ctx, scratchVolumeName(container.Name, i))
+	}
+	m.removeFileSecrets(ctx, container)
+}
+
+// removeScratchVolume deletes one scratch volume
+func (m *Manager) removeScratchVolume(ctx context.Context, name string) {
+	if output, err := podmanCommand(ctx, m.logger, "volume", "rm", "-f", name).CombinedOutput(); err != nil {
+		m.logger.WarnContext(ctx, "Failed to remove scratch volume",
+			slog.String("volume", name),
+			slog.String("error", err.Error()),
+			slog.String("output", string(output)))
+	}
+}
+
+// discoverScratch restores the tmpfs and scratch mounts persisted on a podman container
+func (m *Manager) discoverScratch(ctx context.Context, containerID string) scratchMounts {
+	var mounts scratchMounts
+	m.discoverJSONLabel(ctx, containerID, scratchLabel, &mounts)
+	return mounts
+}
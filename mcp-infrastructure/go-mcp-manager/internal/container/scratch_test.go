@@ -0,0 +1,51 @@
+package container
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestScratchMounts(t *testing.T) {
+	cfg := &config.Config{
+		Container: config.ContainerConfig{
+			DefaultScratchSize: "64m",
+			MaxScratchSize:     "1g",
+			MaxScratchMounts:   2,
+		},
+	}
+	manager := newTestManager(cfg)
+
+	tmpfs, volumes, err := parseScratchSpec(map[string]interface{}{
+		"tmpfs":           []interface{}{map[string]interface{}{"path": "/tmp"}},
+		"scratch_volumes": []interface{}{map[string]interface{}{"path": "/workspace", "size": "512m"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse mounts: %v", err)
+	}
+	if err := manager.checkScratchPolicy(tmpfs, volumes); err != nil {
+		t.Fatalf("Expected mounts within limits to pass, got %v", err)
+	}
+
+	container := &models.Container{Name: "mcp-files", Tmpfs: tmpfs, ScratchVolumes: volumes}
+	args := strings.Join(manager.podmanScratchArgs(container), " ")
+	for _, expected := range []string{
+		"--tmpfs /tmp:rw,nosuid,nodev,mode=1777,size=67108864",
+		"-v mcp-files-scratch-0:/workspace:rw,U",
+	} {
+		if !strings.Contains(args, expected) {
+			t.Errorf("Expected %q in %q", expected, args)
+		}
+	}
+
+	if err := manager.checkScratchPolicy(nil, []models.ScratchMount{{Path: "/data", Size: "2g"}}); err == nil {
+		t.Errorf("Expected scratch volume above the size limit to be rejected")
+	}
+	if _, _, err := parseScratchSpec(map[string]interface{}{
+		"tmpfs": []interface{}{map[string]interface{}{"path": "/tmp/../etc"}},
+	}); err == nil {
+		t.Errorf("Expected unclean mount path to be rejected")
+	}
+}
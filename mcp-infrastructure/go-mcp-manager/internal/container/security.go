@@ -0,0 +1,169 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// securityLabel stores a container's security overrides on the podman container
+const securityLabel = "mcp.security"
+
+var (
+	// userPattern matches "uid", "uid:gid", "name" and "name:group"
+	userPattern = regexp.MustCompile(`^([0-9]+|[a-z_][a-z0-9_-]*)(:([0-9]+|[a-z_][a-z0-9_-]*))?$`)
+	// capabilityPattern matches capability names with or without the CAP_ prefix
+	capabilityPattern = regexp.MustCompile(`(?i)^(CAP_)?[A-Z_]+$`)
+	// profileNamePattern keeps seccomp profile names inside the profile directory
+	profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+)
+
+// parseSecuritySpec reads the optional security overrides from json_spec
+func parseSecuritySpec(jsonSpec map[string]interface{}) (*models.SecurityConfig, error) {
+	raw, exists := jsonSpec["security"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+
+	if _, ok := raw.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("security must be an object")
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("security is not valid JSON: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	security := &models.SecurityConfig{}
+	if err := decoder.Decode(security); err != nil {
+		return nil, fmt.Errorf("invalid security: %w", err)
+	}
+
+	if err := validateSecurityConfig(security); err != nil {
+		return nil, err
+	}
+
+	return security, nil
+}
+
+// validateSecurityConfig checks the format of security overrides
+func validateSecurityConfig(security *models.SecurityConfig) error {
+	if security == nil {
+		return nil
+	}
+
+	if security.User != "" && !userPattern.MatchString(security.User) {
+		return fmt.Errorf("security.user must be uid[:gid] or a user name")
+	}
+	for _, capability := range security.AddCapabilities {
+		if !capabilityPattern.MatchString(capability) {
+			return fmt.Errorf("security.add_capabilities contains invalid capability %q", capability)
+		}
+	}
+	if security.SeccompProfile != "" && !profileNamePattern.MatchString(security.SeccompProfile) {
+		return fmt.Errorf("security.seccomp_profile must be a file name in the seccomp profile directory")
+	}
+
+	return nil
+}
+
+// checkSecurityPolicy rejects overrides the manager's security policy does not permit
+func (m *Manager) checkSecurityPolicy(security *models.SecurityConfig) error {
+	if err := validateSecurityConfig(security); err != nil {
+		return err
+	}
+	if security == nil {
+		return nil
+	}
+
+	policy := m.config.Container.Security
+	if !policy.AllowRoot && runsAsRoot(security.User) {
+		return fmt.Errorf("running containers as root is not permitted")
+	}
+	for _, capability := range security.AddCapabilities {
+		if !slices.Contains(policy.AllowedCapabilities, normalizeCapability(capability)) {
+			return fmt.Errorf("capability %s is not in the allowed capabilities", capability)
+		}
+	}
+	if security.SeccompProfile != "" && policy.SeccompProfileDir == "" {
+		return fmt.Errorf("custom seccomp profiles are not enabled")
+	}
+
+	return nil
+}
+
+// runsAsRoot reports whether a user spec selects uid 0
+func runsAsRoot(user string) bool {
+	name, _, _ := strings.Cut(user, ":")
+	return name == "0" || name == "root"
+}
+
+// normalizeCapability strips the CAP_ prefix podman and Kubernetes both accept
+func normalizeCapability(capability string) string {
+	return strings.TrimPrefix(strings.ToUpper(capability), "CAP_")
+}
+
+// podmanSecurityArgs converts the hardened defaults and a container's overrides to podman run flags
+func (m *Manager) podmanSecurityArgs(container *models.Container) []string {
+	policy := m.config.Container.Security
+	if !policy.Hardened {
+		return nil
+	}
+
+	security := container.Security
+	if security == nil {
+		security = &models.SecurityConfig{}
+	}
+
+	var args []string
+	if security.ReadOnlyRootFS == nil || *security.ReadOnlyRootFS {
+		// podman mounts tmpfs on /tmp, /var/tmp and /run for read-only containers
+		args = append(args, "--read-only")
+		if _, exists := container.Environment["HOME"]; !exists {
+			// npx and uvx write caches under $HOME, which is not writable for the default user
+			args = append(args, "-e", "HOME=/tmp")
+		}
+	}
+
+	args = append(args, "--cap-drop=ALL")
+	for _, capability := range security.AddCapabilities {
+		args = append(args, "--cap-add="+normalizeCapability(capability))
+	}
+
+	if user := security.User; user != "" {
+		args = append(args, "--user", user)
+	} else if policy.DefaultUser != "" {
+		args = append(args, "--user", policy.DefaultUser)
+	}
+
+	if !security.AllowPrivilegeEscalation {
+		args = append(args, "--security-opt", "no-new-privileges")
+	}
+
+	if security.SeccompProfile != "" {
+		args = append(args, "--security-opt", "seccomp="+filepath.Join(policy.SeccompProfileDir, security.SeccompProfile))
+	} else if policy.SeccompProfile != "" {
+		args = append(args, "--security-opt", "seccomp="+policy.SeccompProfile)
+	}
+
+	return args
+}
+
+// discoverSecurity restores the security overrides persisted on a podman container
+func (m *Manager) discoverSecurity(ctx context.Context, containerID string) *models.SecurityConfig {
+	var security models.SecurityConfig
+	if !m.discoverJSONLabel(ctx, containerID, securityLabel, &security) {
+		return nil
+	}
+	return &security
+}
@@ -0,0 +1,61 @@
+package container
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestSecurityHardening(t *testing.T) {
+	cfg := &config.Config{
+		Container: config.ContainerConfig{
+			Security: config.ContainerSecurityConfig{
+				Hardened:            true,
+				DefaultUser:         "1000:1000",
+				SeccompProfileDir:   "/etc/mcp-manager/seccomp",
+				AllowedCapabilities: []string{"NET_BIND_SERVICE"},
+			},
+		},
+	}
+	manager := newTestManager(cfg)
+
+	args := strings.Join(manager.podmanSecurityArgs(&models.Container{}), " ")
+	expected := "--read-only -e HOME=/tmp --cap-drop=ALL --user 1000:1000 --security-opt no-new-privileges"
+	if args != expected {
+		t.Errorf("Expected hardened defaults %q, got %q", expected, args)
+	}
+
+	security, err := parseSecuritySpec(map[string]interface{}{
+		"security": map[string]interface{}{
+			"read_only_rootfs": false,
+			"add_capabilities": []interface{}{"cap_net_bind_service"},
+			"seccomp_profile":  "chromium.json",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse security: %v", err)
+	}
+	if err := manager.checkSecurityPolicy(security); err != nil {
+		t.Fatalf("Expected allowed overrides to pass policy, got %v", err)
+	}
+
+	args = strings.Join(manager.podmanSecurityArgs(&models.Container{Security: security}), " ")
+	expected = "--cap-drop=ALL --cap-add=NET_BIND_SERVICE --user 1000:1000 --security-opt no-new-privileges --security-opt seccomp=/etc/mcp-manager/seccomp/chromium.json"
+	if args != expected {
+		t.Errorf("Expected overridden args %q, got %q", expected, args)
+	}
+
+	if err := manager.checkSecurityPolicy(&models.SecurityConfig{User: "0:0"}); err == nil {
+		t.Errorf("Expected root user to be rejected")
+	}
+	if err := manager.checkSecurityPolicy(&models.SecurityConfig{AddCapabilities: []string{"SYS_ADMIN"}}); err == nil {
+		t.Errorf("Expected capability outside the allowlist to be rejected")
+	}
+	if _, err := parseSecuritySpec(map[string]interface{}{
+		"security": map[string]interface{}{"seccomp_profile": "../../etc/passwd"},
+	}); err == nil {
+		t.Errorf("Expected seccomp profile path traversal to be rejected")
+	}
+}
@@ -0,0 +1,490 @@
+package container
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// sessionOfLabel records the service a session container was started for, and sessionKeyLabel
+// the session it serves
+const (
+	sessionOfLabel  = "mcp.session_of"
+	sessionKeyLabel = "mcp.session_key"
+)
+
+// Session pool defaults and bounds
+const (
+	defaultSessionHeader      = "X-MCP-Session"
+	defaultSessionIdleTimeout = 15 * time.Minute
+	defaultMaxSessions        = 20
+	maxSessionIdleTimeout     = 7 * 24 * 60 * 60
+	maxSessionsPerInstance    = 1000
+	sessionReapInterval       = time.Minute
+	sessionHealthPoll         = time.Second
+)
+
+// sessionKeyPattern limits session keys to characters that are safe inside a proxy rule
+var sessionKeyPattern = regexp.MustCompile(`^[A-Za-z0-9._:@-]{1,128}$`)
+
+// ErrSessionPoolFull is returned when an instance already runs as many session containers as it allows
+var ErrSessionPoolFull = errors.New("session pool is full")
+
+// sessionState tracks when each session container last served a request and the ones being started
+type sessionState struct {
+	mutex    sync.Mutex
+	active   map[string]time.Time
+	spawning map[string]*sessionSpawn
+}
+
+// sessionSpawn lets concurrent first requests of a session wait for the one starting its container
+type sessionSpawn struct {
+	parent string
+	done   chan struct{}
+	err    error
+}
+
+// proxySessionPath returns the manager path the proxy asks which container serves a request's session
+func proxySessionPath(slug string) string {
+	return "/proxy/session/" + slug
+}
+
+// validateRouteSessions checks the session pool options of a route
+func validateRouteSessions(sessions *models.RouteSessions) error {
+	if sessions == nil {
+		return nil
+	}
+	if sessions.Header != "" && !validHeaderName(sessions.Header) {
+		return fmt.Errorf("route.sessions.header %q is not a valid header name", sessions.Header)
+	}
+	if sessions.IdleTimeoutSeconds < 0 || sessions.IdleTimeoutSeconds > maxSessionIdleTimeout {
+		return fmt.Errorf("route.sessions.idle_timeout_seconds must be between 0 and %d", maxSessionIdleTimeout)
+	}
+	if sessions.MaxSessions < 0 || sessions.MaxSessions > maxSessionsPerInstance {
+		return fmt.Errorf("route.sessions.max_sessions must be between 0 and %d", maxSessionsPerInstance)
+	}
+	return nil
+}
+
+// sessionHeader returns the header identifying a route's sessions
+func sessionHeader(sessions *models.RouteSessions) string {
+	if sessions.Header != "" {
+		return http.CanonicalHeaderKey(sessions.Header)
+	}
+	return defaultSessionHeader
+}
+
+// sessionIdleTimeout returns how long a route's session containers may go without requests
+func sessionIdleTimeout(sessions *models.RouteSessions) time.Duration {
+	if sessions.IdleTimeoutSeconds > 0 {
+		return time.Duration(sessions.IdleTimeoutSeconds) * time.Second
+	}
+	return defaultSessionIdleTimeout
+}
+
+// sessionLimit returns how many session containers a route may run at once
+func sessionLimit(sessions *models.RouteSessions) int {
+	if sessions.MaxSessions > 0 {
+		return sessions.MaxSessions
+	}
+	return defaultMaxSessions
+}
+
+// sessionServiceName returns the service name of the container serving session for serviceName
+func sessionServiceName(serviceName, session string) string {
+	sum := sha256.Sum256([]byte(session))
+	return serviceName + "-session-" + hex.EncodeToString(sum[:])[:10]
+}
+
+// sessionRouterName returns the name of the proxy router sending a session's requests for the
+// route with parentSlug to the session container routed under sessionSlug
+func sessionRouterName(parentSlug, sessionSlug string) string {
+	return fmt.Sprintf("mcp-%s-session-%s", parentSlug, sessionSlug)
+}
+
+// AddSessionRouter sends requests for the route with parentSlug that carry header with the value
+// session to the container routed under sessionSlug, through that container's middlewares
+func (tm *TraefikManager) AddSessionRouter(ctx context.Context, parentSlug, sessionSlug, header, session string) error {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	config, err := tm.loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	target, exists := config.HTTP.Routers[fmt.Sprintf("mcp-%s", sessionSlug)]
+	if !exists {
+		return fmt.Errorf("no route for MCP service %s", sessionSlug)
+	}
+
+	// The request path carries the parent's slug, so the parent's prefix is the one to strip
+	chain := make([]string, 0, len(target.Middlewares))
+	for _, middleware := range target.Middlewares {
+		if middleware == routeMiddlewareName(sessionSlug, stripPrefixMiddleware) {
+			middleware = routeMiddlewareName(parentSlug, stripPrefixMiddleware)
+		}
+		chain = append(chain, middleware)
+	}
+	// The longer rule gives the router precedence over the parent's own
+	config.HTTP.Routers[sessionRouterName(parentSlug, sessionSlug)] = TraefikRouter{
+		Rule:        fmt.Sprintf("PathPrefix(`/mcp/%s`) && Header(`%s`, `%s`)", parentSlug, header, session),
+		Service:     target.Service,
+		EntryPoints: []string{"web"},
+		Middlewares: chain,
+	}
+
+	if err := tm.saveConfig(config); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	tm.logger.InfoContext(ctx, "Added Traefik route for MCP session",
+		slog.String("slug", parentSlug),
+		slog.String("session_slug", sessionSlug))
+	return nil
+}
+
+// removeSessionRouters removes the session routers of the route with slug and those sending
+// sessions to it
+func removeSessionRouters(config *TraefikConfig, slug string) {
+	service := fmt.Sprintf("mcp-%s-service", slug)
+	for name, router := range config.HTTP.Routers {
+		if strings.HasPrefix(name, fmt.Sprintf("mcp-%s-session-", slug)) || router.Service == service {
+			delete(config.HTTP.Routers, name)
+		}
+	}
+}
+
+// ProxySession answers the proxy's session check for the route with slug. A session container's
+// own requests are let through and mark it active; a request to an instance with per-session
+// isolation starts its session's container if needed and is redirected to itself so the proxy
+// routes it there. The check is reachable without the proxy, so unless the caller presented a
+// verified client certificate, a route with an access token only starts containers for requests
+// carrying it. It returns 0 to let the request through, or the status and message to answer with.
+func (m *Manager) ProxySession(ctx context.Context, slug string, header http.Header, clientVerified bool) (int, string) {
+	m.mutex.RLock()
+	routed := m.slugRouterUnsafe(slug)
+	var parent models.Container
+	if routed != nil {
+		parent = *routed
+	}
+	m.mutex.RUnlock()
+
+	if routed == nil || parent.Route == nil || parent.Route.Sessions == nil {
+		return 0, ""
+	}
+	if parent.Labels[sessionOfLabel] != "" {
+		m.touchSession(parent.ServiceName)
+		return 0, ""
+	}
+	if auth := parent.Route.Auth; auth != nil && !clientVerified && !proxyAuthorized(auth, header.Get("Authorization")) {
+		return http.StatusUnauthorized, "a valid access token for this MCP server is required"
+	}
+
+	name := sessionHeader(parent.Route.Sessions)
+	session := header.Get(name)
+	if session == "" {
+		return http.StatusBadRequest, fmt.Sprintf("MCP server %s gives each session its own instance and requires the %s header",
+			parent.ServiceName, name)
+	}
+	if !sessionKeyPattern.MatchString(session) {
+		return http.StatusBadRequest, fmt.Sprintf("the %s header must be 1 to 128 letters, digits or . _ : @ -", name)
+	}
+
+	container, err := m.ensureSession(ctx, &parent, session)
+	if err != nil {
+		m.logger.WarnContext(ctx, "Failed to start session container",
+			slog.String("service", parent.ServiceName),
+			slog.String("error", err.Error()))
+		return http.StatusServiceUnavailable, fmt.Sprintf("no instance of MCP server %s is available for the session: %v",
+			parent.ServiceName, err)
+	}
+	m.logger.DebugContext(ctx, "Routing session to its container",
+		slog.String("service", parent.ServiceName),
+		slog.String("session_service", container.ServiceName))
+	return http.StatusTemporaryRedirect, ""
+}
+
+// ensureSession returns the running container of session for parent, starting it and routing
+// the session to it when needed. Concurrent calls for one session start a single container, and a
+// new session is refused once the pool is full.
+func (m *Manager) ensureSession(ctx context.Context, parent *models.Container, session string) (*models.Container, error) {
+	serviceName := sessionServiceName(parent.ServiceName, session)
+
+	// Tracked containers and those being started are counted under both locks, so first requests
+	// of different sessions cannot all pass the check before any of their containers is tracked
+	m.mutex.RLock()
+	m.sessions.mutex.Lock()
+	if pending, exists := m.sessions.spawning[serviceName]; exists {
+		m.sessions.mutex.Unlock()
+		m.mutex.RUnlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-pending.done:
+		}
+		if pending.err != nil {
+			return nil, pending.err
+		}
+		return m.GetContainer(serviceName)
+	}
+	if _, tracked := m.containers[serviceName]; !tracked {
+		if limit := sessionLimit(parent.Route.Sessions); m.sessionCountUnsafe(parent.ServiceName) >= limit {
+			m.sessions.mutex.Unlock()
+			m.mutex.RUnlock()
+			return nil, fmt.Errorf("%w: %s runs %d session containers", ErrSessionPoolFull, parent.ServiceName, limit)
+		}
+	}
+	if m.sessions.spawning == nil {
+		m.sessions.spawning = make(map[string]*sessionSpawn)
+	}
+	spawn := &sessionSpawn{parent: parent.ServiceName, done: make(chan struct{})}
+	m.sessions.spawning[serviceName] = spawn
+	m.sessions.mutex.Unlock()
+	m.mutex.RUnlock()
+
+	// The container outlives the request that started it
+	container, err := m.spawnSession(context.WithoutCancel(ctx), parent, serviceName, session)
+
+	m.sessions.mutex.Lock()
+	delete(m.sessions.spawning, serviceName)
+	spawn.err = err
+	close(spawn.done)
+	m.sessions.mutex.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	m.touchSession(serviceName)
+	return container, nil
+}
+
+// spawnSession starts the session container serviceName from parent's spec unless it runs already,
+// and routes the session to it. The caller reserved its place in the pool.
+func (m *Manager) spawnSession(ctx context.Context, parent *models.Container, serviceName, session string) (*models.Container, error) {
+	m.mutex.RLock()
+	var existing *models.Container
+	if tracked, exists := m.containers[serviceName]; exists {
+		copied := *tracked
+		existing = &copied
+	}
+	m.mutex.RUnlock()
+
+	container := existing
+	switch {
+	case existing == nil:
+		created, err := m.CreateContainer(ctx, sessionSpec(parent, serviceName, session))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create session container: %w", err)
+		}
+		container = created
+		m.logger.InfoContext(ctx, "Session container started",
+			slog.String("service", parent.ServiceName),
+			slog.String("session_service", serviceName))
+	case existing.Status != models.StatusRunning:
+		started, err := m.StartContainer(ctx, serviceName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start session container: %w", err)
+		}
+		container = started
+	}
+	m.waitSessionHealthy(ctx, container)
+
+	if err := m.traefikManager.AddSessionRouter(ctx, parent.Slug, container.Slug, sessionHeader(parent.Route.Sessions), session); err != nil {
+		return nil, fmt.Errorf("failed to route session: %w", err)
+	}
+	return container, nil
+}
+
+// sessionSpec returns the create request of the container serving session for parent
+func sessionSpec(parent *models.Container, serviceName, session string) models.CreateContainerRequest {
+	spec := containerSpec(parent)
+	spec.ServiceName = serviceName
+	spec.Labels = maps.Clone(parent.Labels)
+	if spec.Labels == nil {
+		spec.Labels = make(map[string]string)
+	}
+	spec.Labels[sessionOfLabel] = parent.ServiceName
+	spec.Labels[sessionKeyLabel] = session
+	// Session containers are not instances known to the core API, and live only as long as their session
+	delete(spec.Environment, "MCP_INSTANCE_ID")
+	spec.Schedule = nil
+	// A hostname routes to a single container, so sessions are reachable by path only
+	if spec.Routing != nil && spec.Routing.Type == models.RoutingHost {
+		spec.Routing = nil
+	}
+	return spec
+}
+
+// waitSessionHealthy waits until a new session container passes its health check, so the
+// session's first request does not reach a server that is still starting. A container that does
+// not become healthy in time is routed anyway and left to its health monitoring.
+func (m *Manager) waitSessionHealthy(ctx context.Context, container *models.Container) {
+	deadline := time.Now().Add(m.config.Container.StartupTimeout)
+	for {
+		result, err := m.healthChecker.PerformHealthCheck(ctx, container)
+		if err == nil && result.Healthy {
+			return
+		}
+		if time.Now().After(deadline) {
+			m.logger.WarnContext(ctx, "Session container not healthy after startup timeout",
+				slog.String("service", container.ServiceName))
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(sessionHealthPoll):
+		}
+	}
+}
+
+// sessionContainersUnsafe returns the session containers of serviceName. The caller holds the mutex.
+func (m *Manager) sessionContainersUnsafe(serviceName string) []*models.Container {
+	var sessions []*models.Container
+	for _, container := range m.containers {
+		if container.Labels[sessionOfLabel] == serviceName {
+			sessions = append(sessions, container)
+		}
+	}
+	return sessions
+}
+
+// sessionCountUnsafe returns the session containers of serviceName that are tracked or being
+// started. The caller holds the mutex and the sessions mutex.
+func (m *Manager) sessionCountUnsafe(serviceName string) int {
+	count := len(m.sessionContainersUnsafe(serviceName))
+	for name, spawn := range m.sessions.spawning {
+		if _, tracked := m.containers[name]; spawn.parent == serviceName && !tracked {
+			count++
+		}
+	}
+	return count
+}
+
+// touchSession records that a session container served a request
+func (m *Manager) touchSession(serviceName string) {
+	m.sessions.mutex.Lock()
+	defer m.sessions.mutex.Unlock()
+	if m.sessions.active == nil {
+		m.sessions.active = make(map[string]time.Time)
+	}
+	m.sessions.active[serviceName] = time.Now()
+}
+
+// lastSessionActivity returns when a session container last served a request. A container not
+// seen since the manager started counts as active now, so a restart does not reap busy sessions.
+func (m *Manager) lastSessionActivity(serviceName string) time.Time {
+	m.sessions.mutex.Lock()
+	defer m.sessions.mutex.Unlock()
+	if m.sessions.active == nil {
+		m.sessions.active = make(map[string]time.Time)
+	}
+	last, exists := m.sessions.active[serviceName]
+	if !exists {
+		last = time.Now()
+		m.sessions.active[serviceName] = last
+	}
+	return last
+}
+
+// ListSessions returns the session containers of a service, most recently active first
+func (m *Manager) ListSessions(serviceName string) (*models.SessionsResponse, error) {
+	m.mutex.RLock()
+	parent, exists := m.containers[serviceName]
+	if !exists {
+		m.mutex.RUnlock()
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+	response := &models.SessionsResponse{ServiceName: serviceName, Sessions: []models.SessionInstance{}}
+	if parent.Route != nil && parent.Route.Sessions != nil {
+		response.Header = sessionHeader(parent.Route.Sessions)
+	}
+	sessions := m.sessionContainersUnsafe(serviceName)
+	for _, container := range sessions {
+		response.Sessions = append(response.Sessions, models.SessionInstance{
+			Session:     container.Labels[sessionKeyLabel],
+			ServiceName: container.ServiceName,
+			Slug:        container.Slug,
+			Status:      container.Status,
+			CreatedAt:   container.CreatedAt,
+		})
+	}
+	m.mutex.RUnlock()
+
+	for i := range response.Sessions {
+		last := m.lastSessionActivity(response.Sessions[i].ServiceName)
+		response.Sessions[i].LastActiveAt = &last
+	}
+	sort.Slice(response.Sessions, func(i, j int) bool {
+		return response.Sessions[i].LastActiveAt.After(*response.Sessions[j].LastActiveAt)
+	})
+	return response, nil
+}
+
+// startSessionReaper periodically removes idle session containers
+func (m *Manager) startSessionReaper() {
+	ticker := time.NewTicker(sessionReapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.healthCtx.Done():
+			return
+		case <-ticker.C:
+			m.reapSessions(m.healthCtx)
+		}
+	}
+}
+
+// reapSessions removes session containers idle for longer than their instance allows, and those
+// whose instance is gone or no longer isolates sessions. It returns the removed service names.
+func (m *Manager) reapSessions(ctx context.Context) []string {
+	now := time.Now()
+	m.mutex.RLock()
+	var idle []string
+	for _, container := range m.containers {
+		parentName := container.Labels[sessionOfLabel]
+		if parentName == "" {
+			continue
+		}
+		parent, exists := m.containers[parentName]
+		if !exists || parent.Route == nil || parent.Route.Sessions == nil ||
+			now.Sub(m.lastSessionActivity(container.ServiceName)) >= sessionIdleTimeout(parent.Route.Sessions) {
+			idle = append(idle, container.ServiceName)
+		}
+	}
+	m.mutex.RUnlock()
+
+	var reaped []string
+	for _, serviceName := range idle {
+		m.sessions.mutex.Lock()
+		_, spawning := m.sessions.spawning[serviceName]
+		m.sessions.mutex.Unlock()
+		if spawning {
+			continue
+		}
+		if err := m.DeleteContainer(ctx, serviceName); err != nil {
+			m.logger.WarnContext(ctx, "Failed to remove idle session container",
+				slog.String("service", serviceName),
+				slog.String("error", err.Error()))
+			continue
+		}
+		m.sessions.mutex.Lock()
+		delete(m.sessions.active, serviceName)
+		m.sessions.mutex.Unlock()
+		m.logger.InfoContext(ctx, "Removed idle session container", slog.String("service", serviceName))
+		reaped = append(reaped, serviceName)
+	}
+	return reaped
+}
@@ -0,0 +1,127 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestSessionIsolation(t *testing.T) {
+	if err := validateRouteConfig(&models.RouteConfig{Sessions: &models.RouteSessions{Header: "bad header"}}); err == nil {
+		t.Error("Expected an invalid session header to be rejected")
+	}
+	if sessionServiceName("search", "agent-1") != sessionServiceName("search", "agent-1") ||
+		sessionServiceName("search", "agent-1") == sessionServiceName("search", "agent-2") {
+		t.Error("Expected one session container name per session")
+	}
+
+	route := &models.RouteConfig{Sessions: &models.RouteSessions{IdleTimeoutSeconds: 60}}
+	middlewares, _ := buildRouteMiddlewares("search-1234", route, nil, nil, "http://localhost:8000")
+	if check := middlewares[sessionMiddleware].ForwardAuth; check == nil || check.Address != "http://localhost:8000/proxy/session/search-1234" {
+		t.Errorf("Expected the session check, got %+v", check)
+	}
+
+	cfg := &config.Config{
+		Container: config.ContainerConfig{NamePrefix: "test-", MaxContainers: 10},
+		State:     config.StateConfig{Dir: t.TempDir()},
+		Traefik:   config.TraefikConfig{ManagerServiceURL: "http://localhost:8000"},
+	}
+	manager := newTestManager(cfg)
+	parent := &models.Container{
+		ServiceName: "search",
+		Slug:        "search-1234",
+		Route:       route,
+		Environment: map[string]string{"MCP_INSTANCE_ID": "inst-1", "API_KEY": "secret"},
+	}
+	manager.containers["search"] = parent
+
+	spec := sessionSpec(parent, sessionServiceName("search", "agent-1"), "agent-1")
+	if spec.Labels[sessionOfLabel] != "search" || spec.Labels[sessionKeyLabel] != "agent-1" {
+		t.Errorf("Expected session labels, got %v", spec.Labels)
+	}
+	if _, exists := spec.Environment["MCP_INSTANCE_ID"]; exists || spec.Environment["API_KEY"] != "secret" {
+		t.Errorf("Expected the instance ID dropped and other variables kept, got %v", spec.Environment)
+	}
+
+	ctx := context.Background()
+	if status, message := manager.ProxySession(ctx, "search-1234", http.Header{}, false); status != http.StatusBadRequest || !strings.Contains(message, defaultSessionHeader) {
+		t.Errorf("Expected 400 naming the session header, got %d %q", status, message)
+	}
+	if status, _ := manager.ProxySession(ctx, "search-1234", http.Header{defaultSessionHeader: {"agent`1"}}, false); status != http.StatusBadRequest {
+		t.Errorf("Expected a session key unsafe for the proxy rule to be rejected, got %d", status)
+	}
+	manager.containers[spec.ServiceName] = &models.Container{
+		ServiceName: spec.ServiceName,
+		Slug:        "search-session-5678",
+		Route:       route,
+		Labels:      spec.Labels,
+		Status:      models.StatusRunning,
+	}
+	if status, _ := manager.ProxySession(ctx, "search-session-5678", http.Header{}, false); status != 0 {
+		t.Errorf("Expected the session container's own requests through, got %d", status)
+	}
+	if sessions, err := manager.ListSessions("search"); err != nil || len(sessions.Sessions) != 1 || sessions.Sessions[0].Session != "agent-1" {
+		t.Errorf("Expected the session listed, got %+v %v", sessions, err)
+	}
+
+	// The pool is bounded by default, and containers still starting take their place in it
+	if limit := sessionLimit(&models.RouteSessions{}); limit != defaultMaxSessions {
+		t.Errorf("Expected sessions without max_sessions to be capped at %d, got %d", defaultMaxSessions, limit)
+	}
+	bounded := *parent
+	bounded.Route = &models.RouteConfig{Sessions: &models.RouteSessions{MaxSessions: 2}}
+	manager.sessions.spawning = map[string]*sessionSpawn{sessionServiceName("search", "agent-2"): {parent: "search", done: make(chan struct{})}}
+	if _, err := manager.ensureSession(ctx, &bounded, "agent-3"); !errors.Is(err, ErrSessionPoolFull) {
+		t.Errorf("Expected a session over the cap to be refused, got %v", err)
+	}
+	manager.sessions.spawning = nil
+
+	// Without a verified client certificate, only requests carrying the route's token start containers
+	manager.containers["secured"] = &models.Container{
+		ServiceName: "secured",
+		Slug:        "secured-1234",
+		Route:       &models.RouteConfig{Auth: &models.RouteAuth{Type: models.ProxyAuthBearer, Token: "route-token"}, Sessions: &models.RouteSessions{}},
+	}
+	if status, _ := manager.ProxySession(ctx, "secured-1234", http.Header{defaultSessionHeader: {"agent-1"}}, false); status != http.StatusUnauthorized {
+		t.Errorf("Expected a session check without the route's token to be refused, got %d", status)
+	}
+	if status, _ := manager.ProxySession(ctx, "secured-1234", http.Header{"Authorization": {"Bearer route-token"}}, false); status != http.StatusBadRequest {
+		t.Errorf("Expected the route's token to pass the check, got %d", status)
+	}
+	if status, _ := manager.ProxySession(ctx, "secured-1234", http.Header{}, true); status != http.StatusBadRequest {
+		t.Errorf("Expected a verified client certificate to pass the check, got %d", status)
+	}
+
+	tm := NewTraefikManager(cfg, testLogger())
+	tm.configPath = filepath.Join(t.TempDir(), "dynamic.yml")
+	for _, slug := range []string{"search-1234", "search-session-5678"} {
+		if err := tm.AddMCPService(ctx, slug, "10.88.0.5", 3000, route, nil, nil); err != nil {
+			t.Fatalf("Failed to add route: %v", err)
+		}
+	}
+	if err := tm.AddSessionRouter(ctx, "search-1234", "search-session-5678", defaultSessionHeader, "agent-1"); err != nil {
+		t.Fatalf("Failed to add session router: %v", err)
+	}
+	loaded, _ := tm.LoadConfig()
+	router := loaded.HTTP.Routers[sessionRouterName("search-1234", "search-session-5678")]
+	if router.Service != "mcp-search-session-5678-service" || !strings.Contains(router.Rule, "Header(`X-MCP-Session`, `agent-1`)") {
+		t.Errorf("Expected the session routed to its container, got %+v", router)
+	}
+	if !slices.Contains(router.Middlewares, "mcp-search-1234-stripprefix") {
+		t.Errorf("Expected the parent's path prefix stripped, got %v", router.Middlewares)
+	}
+	if err := tm.RemoveMCPService(ctx, "search-session-5678"); err != nil {
+		t.Fatalf("Failed to remove route: %v", err)
+	}
+	loaded, _ = tm.LoadConfig()
+	if _, exists := loaded.HTTP.Routers[sessionRouterName("search-1234", "search-session-5678")]; exists {
+		t.Error("Expected the session router removed with its container")
+	}
+}
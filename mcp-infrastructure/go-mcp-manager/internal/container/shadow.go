@@ -0,0 +1,400 @@
+package container
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// shadowJobType is the job type of a run mirroring production traffic to a staged container
+const shadowJobType = "mirror"
+
+const (
+	// defaultShadowDuration is how long traffic is mirrored when the request does not say
+	defaultShadowDuration = 10 * time.Minute
+	// maxShadowDuration bounds a mirroring run
+	maxShadowDuration = 24 * time.Hour
+	// defaultMaxErrorRateIncrease is the share of requests the staged container may fail beyond production's
+	defaultMaxErrorRateIncrease = 0.01
+	// shadowPollInterval is how often the comparison is updated from the proxy's counters
+	shadowPollInterval = 15 * time.Second
+)
+
+// serviceRequestsMetric counts the requests each Traefik service answered, by status code
+const serviceRequestsMetric = "traefik_service_requests_total"
+
+// ErrShadowingUnavailable is returned when traffic cannot be mirrored because the proxy's counters are not exported
+var ErrShadowingUnavailable = errors.New("traffic mirroring compares the proxy's per-service counters, enable TRAEFIK_METRICS")
+
+// requestCounts are the requests a Traefik service answered and how many of them failed with 5xx
+type requestCounts struct {
+	requests uint64
+	errors   uint64
+}
+
+// mirroringServiceName returns the Traefik service copying a route's traffic to a mirror
+func mirroringServiceName(slug string) string {
+	return fmt.Sprintf("mcp-%s-mirroring", slug)
+}
+
+// SetMirror copies percent of the requests to the route with slug to the service of the route
+// with mirrorSlug. The mirror's responses are discarded.
+func (tm *TraefikManager) SetMirror(ctx context.Context, slug, mirrorSlug string, percent int) error {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	config, err := tm.loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	main := fmt.Sprintf("mcp-%s-service", slug)
+	mirror := fmt.Sprintf("mcp-%s-service", mirrorSlug)
+	for _, service := range []string{main, mirror} {
+		if _, exists := config.HTTP.Services[service]; !exists {
+			return fmt.Errorf("service %s is not routed", service)
+		}
+	}
+
+	config.HTTP.Services[mirroringServiceName(slug)] = TraefikService{
+		Mirroring: &TraefikMirroring{
+			Service: main,
+			Mirrors: []TraefikMirror{{Name: mirror, Percent: percent}},
+		},
+	}
+	pointRouters(config, slug, mirroringServiceName(slug))
+
+	if err := tm.saveConfig(config); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	tm.logger.InfoContext(ctx, "Mirroring MCP route traffic",
+		slog.String("slug", slug),
+		slog.String("mirror", mirrorSlug),
+		slog.Int("percent", percent))
+	return nil
+}
+
+// ClearMirror stops copying the traffic of the route with slug
+func (tm *TraefikManager) ClearMirror(ctx context.Context, slug string) error {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	config, err := tm.loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if _, mirrored := config.HTTP.Services[mirroringServiceName(slug)]; !mirrored {
+		return nil
+	}
+	delete(config.HTTP.Services, mirroringServiceName(slug))
+	pointRouters(config, slug, fmt.Sprintf("mcp-%s-service", slug))
+
+	if err := tm.saveConfig(config); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	tm.logger.InfoContext(ctx, "Stopped mirroring MCP route traffic", slog.String("slug", slug))
+	return nil
+}
+
+// MirrorTarget returns the Traefik service the route with slug copies its traffic to, or "" when
+// it is not mirrored
+func (tm *TraefikManager) MirrorTarget(slug string) (string, error) {
+	config, err := tm.loadConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	service, mirrored := config.HTTP.Services[mirroringServiceName(slug)]
+	if !mirrored || service.Mirroring == nil || len(service.Mirroring.Mirrors) == 0 {
+		return "", nil
+	}
+	return service.Mirroring.Mirrors[0].Name, nil
+}
+
+// pointRouters routes the path and host routers of slug to service
+func pointRouters(config *TraefikConfig, slug, service string) {
+	for _, name := range []string{fmt.Sprintf("mcp-%s", slug), fmt.Sprintf("mcp-%s-host", slug)} {
+		if router, exists := config.HTTP.Routers[name]; exists {
+			router.Service = service
+			config.HTTP.Routers[name] = router
+		}
+	}
+}
+
+// clearMirrors removes the mirroring of the route with slug and any mirroring to its service
+func clearMirrors(config *TraefikConfig, slug string) {
+	delete(config.HTTP.Services, mirroringServiceName(slug))
+	target := fmt.Sprintf("mcp-%s-service", slug)
+	for name, service := range config.HTTP.Services {
+		mirrored, isMirroring := strings.CutSuffix(strings.TrimPrefix(name, "mcp-"), "-mirroring")
+		if !isMirroring || service.Mirroring == nil {
+			continue
+		}
+		for _, mirror := range service.Mirroring.Mirrors {
+			if mirror.Name == target {
+				delete(config.HTTP.Services, name)
+				pointRouters(config, mirrored, fmt.Sprintf("mcp-%s-service", mirrored))
+			}
+		}
+	}
+}
+
+// validateMirror checks the mirroring options of a staging request
+func validateMirror(mirror *models.MirrorConfig) error {
+	if mirror == nil {
+		return nil
+	}
+	if mirror.Percent < 1 || mirror.Percent > 100 {
+		return fmt.Errorf("mirror.percent must be between 1 and 100")
+	}
+	if mirror.DurationSeconds < 0 || time.Duration(mirror.DurationSeconds)*time.Second > maxShadowDuration {
+		return fmt.Errorf("mirror.duration_seconds must be between 0 and %d", int(maxShadowDuration.Seconds()))
+	}
+	if mirror.MaxErrorRateIncrease < 0 || mirror.MaxErrorRateIncrease > 1 {
+		return fmt.Errorf("mirror.max_error_rate_increase must be between 0 and 1")
+	}
+	return nil
+}
+
+// proxyMetricsURL returns where the proxy's Prometheus metrics are scraped; a wildcard address is
+// reached over loopback
+func (m *Manager) proxyMetricsURL() (string, error) {
+	metrics := m.config.Traefik.Metrics
+	if !metrics.Enabled {
+		return "", ErrShadowingUnavailable
+	}
+	host, port, err := net.SplitHostPort(metrics.Address)
+	if err != nil {
+		return "", fmt.Errorf("invalid TRAEFIK_METRICS_ADDRESS %q: %w", metrics.Address, err)
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return fmt.Sprintf("http://%s/metrics", net.JoinHostPort(host, port)), nil
+}
+
+// scrapeServiceRequests reads the proxy's request counters per Traefik service
+func scrapeServiceRequests(ctx context.Context, metricsURL string) (map[string]requestCounts, error) {
+	var counts map[string]requestCounts
+	err := readProxyMetrics(ctx, metricsURL, func(r io.Reader) { counts = parseServiceRequests(r) })
+	return counts, err
+}
+
+// readProxyMetrics fetches the proxy's Prometheus metrics and passes them to parse
+func readProxyMetrics(ctx context.Context, metricsURL string, parse func(io.Reader)) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metricsURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to read proxy metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("proxy metrics answered %s", resp.Status)
+	}
+	parse(resp.Body)
+	return nil
+}
+
+// parseServiceRequests sums the Prometheus text exposition of traefik_service_requests_total by
+// service, without the provider suffix
+func parseServiceRequests(r io.Reader) map[string]requestCounts {
+	counts := make(map[string]requestCounts)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		service, code, value, found := serviceSample(scanner.Text(), serviceRequestsMetric)
+		if !found {
+			continue
+		}
+		entry := counts[service]
+		entry.requests += uint64(value)
+		if strings.HasPrefix(code, "5") {
+			entry.errors += uint64(value)
+		}
+		counts[service] = entry
+	}
+	return counts
+}
+
+// serviceSample parses a sample of a per-service proxy metric, returning the Traefik service
+// without the provider suffix and the status code label when the sample has one
+func serviceSample(line, metric string) (service, code string, value float64, found bool) {
+	labels, found := strings.CutPrefix(line, metric+"{")
+	if !found {
+		return "", "", 0, false
+	}
+	labels, valueText, found := strings.Cut(labels, "} ")
+	if !found {
+		return "", "", 0, false
+	}
+	value, err := strconv.ParseFloat(strings.Fields(valueText)[0], 64)
+	if err != nil {
+		return "", "", 0, false
+	}
+
+	for _, label := range strings.Split(labels, ",") {
+		name, quoted, _ := strings.Cut(label, "=")
+		switch name {
+		case "service":
+			service, _, _ = strings.Cut(strings.Trim(quoted, `"`), "@")
+		case "code":
+			code = strings.Trim(quoted, `"`)
+		}
+	}
+	return service, code, value, service != ""
+}
+
+// compareMirror compares what production and the mirror answered since baseline
+func compareMirror(baseline, current map[string]requestCounts, production, mirror string, percent int, maxIncrease float64) models.MirrorComparison {
+	delta := func(service string) requestCounts {
+		before, after := baseline[service], current[service]
+		// A proxy restart resets its counters
+		if after.requests < before.requests {
+			return after
+		}
+		return requestCounts{requests: after.requests - before.requests, errors: after.errors - before.errors}
+	}
+	rate := func(counts requestCounts) float64 {
+		if counts.requests == 0 {
+			return 0
+		}
+		return float64(counts.errors) / float64(counts.requests)
+	}
+
+	productionCounts, mirrorCounts := delta(production), delta(mirror)
+	comparison := models.MirrorComparison{
+		Percent:             percent,
+		ProductionRequests:  productionCounts.requests,
+		ProductionErrors:    productionCounts.errors,
+		ProductionErrorRate: rate(productionCounts),
+		MirrorRequests:      mirrorCounts.requests,
+		MirrorErrors:        mirrorCounts.errors,
+		MirrorErrorRate:     rate(mirrorCounts),
+		UpdatedAt:           time.Now(),
+	}
+	comparison.Regressed = mirrorCounts.requests > 0 && comparison.MirrorErrorRate > comparison.ProductionErrorRate+maxIncrease
+	return comparison
+}
+
+// startShadowing mirrors a share of production's traffic to the staged container and starts the
+// job comparing their error rates
+func (m *Manager) startShadowing(ctx context.Context, serviceName, productionSlug, stagingSlug string, mirror *models.MirrorConfig) (*models.Job, error) {
+	metricsURL, err := m.proxyMetricsURL()
+	if err != nil {
+		return nil, err
+	}
+	baseline, err := scrapeServiceRequests(ctx, metricsURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.traefikManager.SetMirror(ctx, productionSlug, stagingSlug, mirror.Percent); err != nil {
+		return nil, fmt.Errorf("failed to mirror traffic: %w", err)
+	}
+
+	duration := defaultShadowDuration
+	if mirror.DurationSeconds > 0 {
+		duration = time.Duration(mirror.DurationSeconds) * time.Second
+	}
+	maxIncrease := defaultMaxErrorRateIncrease
+	if mirror.MaxErrorRateIncrease > 0 {
+		maxIncrease = mirror.MaxErrorRateIncrease
+	}
+
+	job := m.startJob(shadowJobType, serviceName)
+	fmt.Fprintf(job, "Mirroring %d%% of %s traffic to %s for %s\n", mirror.Percent, productionSlug, stagingSlug, duration)
+	go m.runShadowing(job, metricsURL, baseline, productionSlug, stagingSlug, mirror.Percent, maxIncrease, duration)
+
+	snapshot := job.snapshot()
+	return &snapshot, nil
+}
+
+// runShadowing updates the job's comparison until the mirroring period ends or the mirror is
+// removed, such as by a promotion, then stops mirroring. The job fails when the staged container
+// failed a larger share of requests than allowed.
+func (m *Manager) runShadowing(job *trackedJob, metricsURL string, baseline map[string]requestCounts, productionSlug, stagingSlug string, percent int, maxIncrease float64, duration time.Duration) {
+	production := fmt.Sprintf("mcp-%s-service", productionSlug)
+	mirror := fmt.Sprintf("mcp-%s-service", stagingSlug)
+	deadline := time.NewTimer(duration)
+	defer deadline.Stop()
+	ticker := time.NewTicker(shadowPollInterval)
+	defer ticker.Stop()
+
+	var comparison *models.MirrorComparison
+	update := func() {
+		current, err := scrapeServiceRequests(m.healthCtx, metricsURL)
+		if err != nil {
+			fmt.Fprintf(job, "%s\n", err)
+			return
+		}
+		compared := compareMirror(baseline, current, production, mirror, percent, maxIncrease)
+		comparison = &compared
+		job.setMirror(comparison)
+		fmt.Fprintf(job, "production %d/%d failed (%.2f%%), mirror %d/%d failed (%.2f%%)\n",
+			compared.ProductionErrors, compared.ProductionRequests, compared.ProductionErrorRate*100,
+			compared.MirrorErrors, compared.MirrorRequests, compared.MirrorErrorRate*100)
+	}
+
+	stopped := false
+	for !stopped {
+		select {
+		case <-m.healthCtx.Done():
+			fmt.Fprintln(job, "Manager is shutting down")
+			stopped = true
+		case <-deadline.C:
+			update()
+			stopped = true
+		case <-ticker.C:
+			if target, err := m.traefikManager.MirrorTarget(productionSlug); err == nil && target != mirror {
+				fmt.Fprintln(job, "Mirroring was stopped before the period ended")
+				stopped = true
+				continue
+			}
+			update()
+		}
+	}
+
+	// Mirroring only ends with its job, so a restart does not leave production copied to a stale container
+	if target, err := m.traefikManager.MirrorTarget(productionSlug); err == nil && target == mirror {
+		if err := m.traefikManager.ClearMirror(context.Background(), productionSlug); err != nil {
+			m.logger.Warn("Failed to stop mirroring traffic",
+				slog.String("slug", productionSlug),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	var err error
+	if comparison != nil && comparison.Regressed {
+		err = fmt.Errorf("staged container failed %.2f%% of mirrored requests, production %.2f%%",
+			comparison.MirrorErrorRate*100, comparison.ProductionErrorRate*100)
+	}
+	m.finishJob(job, "", err)
+}
+
+// shadowRegressed returns the failure of the service's latest mirroring run, if it found the staged
+// container failing more requests than production
+func (m *Manager) shadowRegressed(serviceName string) error {
+	for _, job := range m.ListJobs(serviceName) {
+		if job.Type != shadowJobType {
+			continue
+		}
+		if job.Mirror != nil && job.Mirror.Regressed {
+			return fmt.Errorf("mirroring job %s found the staged container failing %.2f%% of requests, production %.2f%%",
+				job.ID, job.Mirror.MirrorErrorRate*100, job.Mirror.ProductionErrorRate*100)
+		}
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package container
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestTrafficMirroring(t *testing.T) {
+	if err := validateMirror(&models.MirrorConfig{Percent: 0}); err == nil {
+		t.Error("Expected a mirror without a percentage to be rejected")
+	}
+	if err := validateMirror(&models.MirrorConfig{Percent: 10, DurationSeconds: 600}); err != nil {
+		t.Errorf("Expected a valid mirror, got %v", err)
+	}
+
+	metrics := `# TYPE traefik_service_requests_total counter
+traefik_service_requests_total{code="200",method="POST",protocol="http",service="mcp-search-ab12-service@file"} 190
+traefik_service_requests_total{code="502",method="POST",protocol="http",service="mcp-search-ab12-service@file"} 10
+traefik_service_requests_total{code="200",method="POST",protocol="http",service="mcp-search-staging-cd34-service@file"} 15
+traefik_service_requests_total{code="500",method="POST",protocol="http",service="mcp-search-staging-cd34-service@file"} 5
+traefik_service_open_connections{method="POST",protocol="http",service="mcp-search-ab12-service@file"} 3
+`
+	counts := parseServiceRequests(strings.NewReader(metrics))
+	if got := counts["mcp-search-ab12-service"]; got.requests != 200 || got.errors != 10 {
+		t.Errorf("Expected 200 requests with 10 errors, got %+v", got)
+	}
+	baseline := map[string]requestCounts{"mcp-search-ab12-service": {requests: 100, errors: 5}}
+	comparison := compareMirror(baseline, counts, "mcp-search-ab12-service", "mcp-search-staging-cd34-service", 10, 0.01)
+	if comparison.ProductionRequests != 100 || comparison.ProductionErrorRate != 0.05 {
+		t.Errorf("Expected production counted from the baseline, got %+v", comparison)
+	}
+	if comparison.MirrorErrorRate != 0.25 || !comparison.Regressed {
+		t.Errorf("Expected the mirror's 25%% error rate to be a regression, got %+v", comparison)
+	}
+	if compareMirror(counts, counts, "mcp-search-ab12-service", "mcp-search-staging-cd34-service", 10, 0.01).Regressed {
+		t.Error("Expected no regression without mirrored requests")
+	}
+	if slug := traefikServiceSlug("mcp-search-ab12-mirroring@file"); slug != "search-ab12" {
+		t.Errorf("Expected the mirrored route's slug, got %q", slug)
+	}
+
+	cfg := &config.Config{Traefik: config.TraefikConfig{ManagerServiceURL: "http://localhost:8000"}}
+	tm := NewTraefikManager(cfg, testLogger())
+	tm.configPath = filepath.Join(t.TempDir(), "dynamic.yml")
+	ctx := context.Background()
+	for _, slug := range []string{"search-ab12", "search-staging-cd34"} {
+		if err := tm.AddMCPService(ctx, slug, "10.88.0.5", 3000, nil, nil, nil); err != nil {
+			t.Fatalf("Failed to add route: %v", err)
+		}
+	}
+	if err := tm.SetMirror(ctx, "search-ab12", "search-staging-cd34", 10); err != nil {
+		t.Fatalf("Failed to mirror: %v", err)
+	}
+	// A route refresh keeps the mirroring in place
+	if err := tm.AddMCPService(ctx, "search-ab12", "10.88.0.6", 3000, nil, nil, nil); err != nil {
+		t.Fatalf("Failed to refresh route: %v", err)
+	}
+	loaded, _ := tm.LoadConfig()
+	if service := loaded.HTTP.Routers["mcp-search-ab12"].Service; service != "mcp-search-ab12-mirroring" {
+		t.Errorf("Expected the router to use the mirroring service, got %s", service)
+	}
+	if target, _ := tm.MirrorTarget("search-ab12"); target != "mcp-search-staging-cd34-service" {
+		t.Errorf("Expected the staging service as mirror, got %q", target)
+	}
+
+	if err := tm.RemoveMCPService(ctx, "search-staging-cd34"); err != nil {
+		t.Fatalf("Failed to remove route: %v", err)
+	}
+	loaded, _ = tm.LoadConfig()
+	if _, exists := loaded.HTTP.Services["mcp-search-ab12-mirroring"]; exists {
+		t.Error("Expected the mirroring to the removed route to be dropped")
+	}
+	if service := loaded.HTTP.Routers["mcp-search-ab12"].Service; service != "mcp-search-ab12-service" {
+		t.Errorf("Expected the router back on its own service, got %s", service)
+	}
+}
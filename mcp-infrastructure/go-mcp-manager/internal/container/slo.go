@@ -0,0 +1,346 @@
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// sloBucket holds the SLO counters of each instance, keyed by service name
+	sloBucket = "slo"
+	// sloSaveInterval bounds how often SLO counters are persisted
+	sloSaveInterval = 30 * time.Second
+	// sloRetention is the longest window reported
+	sloRetention = 30 * 24 * time.Hour
+)
+
+// sloWindow is a rolling window SLOs are reported over and the bucket size it is summed from
+type sloWindow struct {
+	name       string
+	span       time.Duration
+	resolution time.Duration
+}
+
+// sloWindows are the reported windows. The last hour is counted by the minute, longer windows by
+// the hour, so they reach back to the start of the hour they begin in.
+var sloWindows = []sloWindow{
+	{name: "1h", span: time.Hour, resolution: time.Minute},
+	{name: "24h", span: 24 * time.Hour, resolution: time.Hour},
+	{name: "30d", span: sloRetention, resolution: time.Hour},
+}
+
+// sloCounts counts the health checks and proxied requests of one bucket
+type sloCounts struct {
+	Checks   uint64 `json:"checks,omitempty"`
+	Healthy  uint64 `json:"healthy,omitempty"`
+	Requests uint64 `json:"requests,omitempty"`
+	Errors   uint64 `json:"errors,omitempty"`
+}
+
+// sloSeries holds the buckets of one instance, keyed by the Unix time each starts at
+type sloSeries struct {
+	Minutes map[int64]*sloCounts `json:"minutes"`
+	Hours   map[int64]*sloCounts `json:"hours"`
+}
+
+// sloState holds the SLO counters of every instance and which of them changed since the last save
+type sloState struct {
+	mutex  sync.Mutex
+	series map[string]*sloSeries
+	dirty  map[string]bool
+}
+
+// add counts an event at time at into the minute and hour buckets it falls in, dropping buckets
+// that left every window
+func (s *sloSeries) add(at, now time.Time, count func(*sloCounts)) {
+	addToBucket(s.Minutes, at, now, time.Minute, time.Hour, count)
+	addToBucket(s.Hours, at, now, time.Hour, sloRetention, count)
+}
+
+// addToBucket counts an event into the bucket of the given resolution, unless it is older than the
+// retention. Expired buckets are dropped whenever a new one is started.
+func addToBucket(buckets map[int64]*sloCounts, at, now time.Time, resolution, retention time.Duration, count func(*sloCounts)) {
+	oldest := now.Add(-retention).Truncate(resolution).Unix()
+	key := at.Truncate(resolution).Unix()
+	if key <= oldest {
+		return
+	}
+	counts, exists := buckets[key]
+	if !exists {
+		for start := range buckets {
+			if start <= oldest {
+				delete(buckets, start)
+			}
+		}
+		counts = &sloCounts{}
+		buckets[key] = counts
+	}
+	count(counts)
+}
+
+// sum adds up the buckets of a window ending now
+func (s *sloSeries) sum(window sloWindow, now time.Time) sloCounts {
+	buckets := s.Hours
+	if window.resolution == time.Minute {
+		buckets = s.Minutes
+	}
+	oldest := now.Add(-window.span).Truncate(window.resolution).Unix()
+	var total sloCounts
+	for start, counts := range buckets {
+		if start > oldest {
+			total.Checks += counts.Checks
+			total.Healthy += counts.Healthy
+			total.Requests += counts.Requests
+			total.Errors += counts.Errors
+		}
+	}
+	return total
+}
+
+// recordSLO counts an event for an instance, creating its series on first use
+func (m *Manager) recordSLO(serviceName string, at time.Time, count func(*sloCounts)) {
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	m.slo.mutex.Lock()
+	defer m.slo.mutex.Unlock()
+
+	m.sloSeriesUnsafe(serviceName).add(at, time.Now(), count)
+	m.slo.dirty[serviceName] = true
+}
+
+// recordSLOCheck counts a health check of an instance towards its availability
+func (m *Manager) recordSLOCheck(serviceName string, at time.Time, healthy bool) {
+	m.recordSLO(serviceName, at, func(counts *sloCounts) {
+		counts.Checks++
+		if healthy {
+			counts.Healthy++
+		}
+	})
+}
+
+// recordSLORequest counts a proxied request of an instance towards its error rate
+func (m *Manager) recordSLORequest(serviceName string, at time.Time, failed bool) {
+	m.recordSLO(serviceName, at, func(counts *sloCounts) {
+		counts.Requests++
+		if failed {
+			counts.Errors++
+		}
+	})
+}
+
+// sloSeriesUnsafe returns the series of an instance, creating it on first use.
+// Caller must hold m.slo.mutex.
+func (m *Manager) sloSeriesUnsafe(serviceName string) *sloSeries {
+	if m.slo.series == nil {
+		m.slo.series = make(map[string]*sloSeries)
+		m.slo.dirty = make(map[string]bool)
+	}
+	series, exists := m.slo.series[serviceName]
+	if !exists {
+		series = &sloSeries{Minutes: make(map[int64]*sloCounts), Hours: make(map[int64]*sloCounts)}
+		m.slo.series[serviceName] = series
+	}
+	return series
+}
+
+// startSLOTracking restores the SLO counters of a previous run and persists them as they change
+func (m *Manager) startSLOTracking() {
+	m.loadSLO()
+	defer m.saveSLO()
+
+	ticker := time.NewTicker(sloSaveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.healthCtx.Done():
+			return
+		case <-ticker.C:
+			m.saveSLO()
+		}
+	}
+}
+
+// loadSLO adds the persisted counters to those recorded since the manager started
+func (m *Manager) loadSLO() {
+	records, err := m.store.List(sloBucket)
+	if err != nil {
+		m.logger.Warn("Failed to load SLO counters",
+			slog.String("error", err.Error()))
+		return
+	}
+
+	now := time.Now()
+	m.slo.mutex.Lock()
+	defer m.slo.mutex.Unlock()
+
+	for serviceName, raw := range records {
+		var stored sloSeries
+		if err := json.Unmarshal(raw, &stored); err != nil {
+			continue
+		}
+		series := m.sloSeriesUnsafe(serviceName)
+		for start, counts := range stored.Minutes {
+			addToBucket(series.Minutes, time.Unix(start, 0), now, time.Minute, time.Hour, counts.addTo)
+		}
+		for start, counts := range stored.Hours {
+			addToBucket(series.Hours, time.Unix(start, 0), now, time.Hour, sloRetention, counts.addTo)
+		}
+	}
+}
+
+// addTo adds the counts to another bucket
+func (c *sloCounts) addTo(other *sloCounts) {
+	other.Checks += c.Checks
+	other.Healthy += c.Healthy
+	other.Requests += c.Requests
+	other.Errors += c.Errors
+}
+
+// saveSLO persists the counters that changed since the last save
+func (m *Manager) saveSLO() {
+	m.slo.mutex.Lock()
+	changed := make(map[string]sloSeries, len(m.slo.dirty))
+	for serviceName := range m.slo.dirty {
+		if series, exists := m.slo.series[serviceName]; exists {
+			changed[serviceName] = copySLOSeries(series)
+		}
+	}
+	clear(m.slo.dirty)
+	m.slo.mutex.Unlock()
+
+	for serviceName, series := range changed {
+		if err := m.store.Put(sloBucket, serviceName, series); err != nil {
+			m.logger.Warn("Failed to save SLO counters",
+				slog.String("service", serviceName),
+				slog.String("error", err.Error()))
+		}
+	}
+}
+
+// copySLOSeries returns a copy of a series that can be used without the lock
+func copySLOSeries(series *sloSeries) sloSeries {
+	result := sloSeries{
+		Minutes: make(map[int64]*sloCounts, len(series.Minutes)),
+		Hours:   make(map[int64]*sloCounts, len(series.Hours)),
+	}
+	for start, counts := range series.Minutes {
+		copied := *counts
+		result.Minutes[start] = &copied
+	}
+	for start, counts := range series.Hours {
+		copied := *counts
+		result.Hours[start] = &copied
+	}
+	return result
+}
+
+// forgetSLO drops the counters of a deleted instance, so a new instance of the same name starts afresh
+func (m *Manager) forgetSLO(serviceName string) {
+	m.slo.mutex.Lock()
+	delete(m.slo.series, serviceName)
+	delete(m.slo.dirty, serviceName)
+	m.slo.mutex.Unlock()
+
+	if err := m.store.Delete(sloBucket, serviceName); err != nil {
+		m.logger.Warn("Failed to remove SLO counters",
+			slog.String("service", serviceName),
+			slog.String("error", err.Error()))
+	}
+}
+
+// sloWindowReports returns the availability and error rate of each window from summed counts
+func sloWindowReports(sum func(sloWindow) sloCounts) []models.SLOWindow {
+	reports := make([]models.SLOWindow, 0, len(sloWindows))
+	for _, window := range sloWindows {
+		counts := sum(window)
+		report := models.SLOWindow{
+			Window:        window.name,
+			HealthChecks:  counts.Checks,
+			HealthyChecks: counts.Healthy,
+			Requests:      counts.Requests,
+			Errors:        counts.Errors,
+		}
+		if counts.Checks > 0 {
+			availability := float64(counts.Healthy) * 100 / float64(counts.Checks)
+			report.AvailabilityPercent = &availability
+		}
+		if counts.Requests > 0 {
+			errorRate := float64(counts.Errors) * 100 / float64(counts.Requests)
+			report.ErrorRatePercent = &errorRate
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// GetSLO returns the rolling availability and proxy error rate of a container over each window
+func (m *Manager) GetSLO(serviceName string) (*models.SLOReport, error) {
+	m.mutex.RLock()
+	_, exists := m.containers[serviceName]
+	m.mutex.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+
+	now := time.Now()
+	m.slo.mutex.Lock()
+	series := m.slo.series[serviceName]
+	windows := sloWindowReports(func(window sloWindow) sloCounts {
+		if series == nil {
+			return sloCounts{}
+		}
+		return series.sum(window, now)
+	})
+	m.slo.mutex.Unlock()
+
+	return &models.SLOReport{
+		ServiceName:      serviceName,
+		Windows:          windows,
+		TrafficCollected: m.trafficCollected(),
+		Timestamp:        now,
+	}, nil
+}
+
+// SLOSummary returns the windows of every container and of all of them together
+func (m *Manager) SLOSummary() models.SLOSummary {
+	m.mutex.RLock()
+	serviceNames := slices.Collect(maps.Keys(m.containers))
+	m.mutex.RUnlock()
+
+	now := time.Now()
+	summary := models.SLOSummary{Instances: make(map[string][]models.SLOWindow, len(serviceNames))}
+
+	m.slo.mutex.Lock()
+	defer m.slo.mutex.Unlock()
+
+	for _, serviceName := range serviceNames {
+		series := m.slo.series[serviceName]
+		if series == nil {
+			continue
+		}
+		summary.Instances[serviceName] = sloWindowReports(func(window sloWindow) sloCounts {
+			return series.sum(window, now)
+		})
+	}
+	summary.Windows = sloWindowReports(func(window sloWindow) sloCounts {
+		var total sloCounts
+		for _, serviceName := range serviceNames {
+			if series := m.slo.series[serviceName]; series != nil {
+				counts := series.sum(window, now)
+				counts.addTo(&total)
+			}
+		}
+		return total
+	})
+	return summary
+}
@@ -0,0 +1,70 @@
+package container
+
+import (
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestSLO(t *testing.T) {
+	cfg := &config.Config{State: config.StateConfig{Dir: t.TempDir()}}
+	manager := newTestManager(cfg)
+	container := &models.Container{Name: "mcp-github", ServiceName: "github", Slug: "github-ab12"}
+	manager.containers["github"] = container
+
+	now := time.Now()
+	for i, healthy := range []bool{true, true, true, false} {
+		result := &HealthCheckResult{Healthy: healthy, Timestamp: now.Add(-time.Duration(i) * time.Minute)}
+		manager.recordHealthHistory(container, result)
+	}
+	// Two hours ago only counts towards the longer windows, and 31 days ago towards none
+	manager.recordSLOCheck("github", now.Add(-2*time.Hour), false)
+	manager.recordSLOCheck("github", now.Add(-31*24*time.Hour), false)
+	manager.recordSLORequest("github", now, false)
+	manager.recordSLORequest("github", now, true)
+
+	report, err := manager.GetSLO("github")
+	if err != nil {
+		t.Fatalf("Expected an SLO report, got %v", err)
+	}
+	if len(report.Windows) != 3 {
+		t.Fatalf("Expected 3 windows, got %d", len(report.Windows))
+	}
+	hour, day := report.Windows[0], report.Windows[1]
+	if hour.Window != "1h" || hour.HealthChecks != 4 || *hour.AvailabilityPercent != 75 {
+		t.Errorf("Expected 3 of 4 checks healthy over 1h, got %+v", hour)
+	}
+	if day.HealthChecks != 5 || *day.AvailabilityPercent != 60 {
+		t.Errorf("Expected 3 of 5 checks healthy over 24h, got %+v", day)
+	}
+	if hour.Requests != 2 || *hour.ErrorRatePercent != 50 {
+		t.Errorf("Expected 1 of 2 requests failed, got %+v", hour)
+	}
+	if report.Windows[2].HealthChecks != 5 {
+		t.Errorf("Expected the check from 31 days ago to be dropped, got %d checks over 30d", report.Windows[2].HealthChecks)
+	}
+
+	summary := manager.SLOSummary()
+	if len(summary.Instances["github"]) != 3 || summary.Windows[1].HealthChecks != 5 {
+		t.Errorf("Expected the summary to include github, got %+v", summary)
+	}
+
+	// Counters survive a restart
+	manager.saveSLO()
+	restarted := newTestManager(cfg)
+	restarted.containers["github"] = container
+	restarted.loadSLO()
+	if report, _ := restarted.GetSLO("github"); report.Windows[1].HealthChecks != 5 || report.Windows[0].Requests != 2 {
+		t.Errorf("Expected restored counters, got %+v", report.Windows)
+	}
+
+	if _, err := manager.GetSLO("missing"); err == nil {
+		t.Error("Expected an error for an unknown container")
+	}
+	manager.forgetSLO("github")
+	if report, _ := manager.GetSLO("github"); report.Windows[2].HealthChecks != 0 {
+		t.Errorf("Expected no counters after the instance is forgotten, got %+v", report.Windows[2])
+	}
+}
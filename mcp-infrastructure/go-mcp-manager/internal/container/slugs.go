@@ -0,0 +1,334 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// slugsBucket records the instance each slug was assigned to, so an instance that is deleted and
+// created again, or redeployed, is routed under the same /mcp/{slug} URL
+const slugsBucket = "slugs"
+
+var (
+	// ErrSlugConflict is returned when a slug belongs to another instance
+	ErrSlugConflict = errors.New("slug belongs to another instance")
+	// ErrInvalidSlug is returned for a requested slug that cannot be used in a URL path or router name
+	ErrInvalidSlug = errors.New("invalid slug")
+)
+
+// slugPattern is a DNS label: lowercase letters, digits and inner hyphens, at most 63 characters
+var slugPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// validateSlug checks a slug requested by a client
+func validateSlug(slug string) error {
+	if !slugPattern.MatchString(slug) {
+		return fmt.Errorf("%w: %q must be 1-63 lowercase letters, digits and hyphens, starting and ending with a letter or digit", ErrInvalidSlug, slug)
+	}
+	return nil
+}
+
+// slugAssignment is the owner of a slug: an instance, or a service for containers created
+// without an instance ID
+type slugAssignment struct {
+	InstanceID  string    `json:"instance_id,omitempty"`
+	ServiceName string    `json:"service_name"`
+	WorkspaceID string    `json:"workspace_id,omitempty"`
+	AssignedAt  time.Time `json:"assigned_at"`
+}
+
+// owns reports whether the assignment belongs to instanceID, or to serviceName without one
+func (a slugAssignment) owns(instanceID, serviceName string) bool {
+	if a.InstanceID != "" || instanceID != "" {
+		return a.InstanceID == instanceID
+	}
+	return a.ServiceName == serviceName
+}
+
+// owner names the assignment's owner in messages
+func (a slugAssignment) owner() string {
+	if a.InstanceID != "" {
+		return "instance " + a.InstanceID
+	}
+	return "service " + a.ServiceName
+}
+
+// slugAssignments returns the recorded assignments by slug
+func (m *Manager) slugAssignments() map[string]slugAssignment {
+	assignments := make(map[string]slugAssignment)
+	records, err := m.store.List(slugsBucket)
+	if err != nil {
+		m.logger.Warn("Failed to read slug assignments", slog.String("error", err.Error()))
+		return assignments
+	}
+	for slug, raw := range records {
+		var assignment slugAssignment
+		if err := json.Unmarshal(raw, &assignment); err == nil {
+			assignments[slug] = assignment
+		}
+	}
+	return assignments
+}
+
+// slugForUnsafe returns the slug a container of instanceID, or of serviceName without one, is
+// routed under. A required slug, such as one restored from a backup, is used as is. Otherwise a
+// preferred slug is used when it is free and gets a random suffix when it is not, and without one
+// the slug assigned to the instance before is reused or a new one generated. A slug owned by
+// another instance, or routing another container, is rejected. Nothing is recorded until
+// recordSlug. The caller holds the mutex.
+func (m *Manager) slugForUnsafe(required, preferred, instanceID, serviceName string) (string, error) {
+	assignments := m.slugAssignments()
+	conflict := func(slug string) error {
+		if assignment, assigned := assignments[slug]; assigned && !assignment.owns(instanceID, serviceName) {
+			return fmt.Errorf("%w: %s is assigned to %s", ErrSlugConflict, slug, assignment.owner())
+		}
+		if routed := m.slugRouterUnsafe(slug); routed != nil && routed.ServiceName != serviceName {
+			return fmt.Errorf("%w: %s routes container %s", ErrSlugConflict, slug, routed.ServiceName)
+		}
+		return nil
+	}
+
+	slug, base := required, serviceName
+	switch {
+	case required != "":
+	case preferred != "":
+		if conflict(preferred) == nil {
+			return preferred, nil
+		}
+		// Room for the suffix, so the slug stays a DNS label
+		base = strings.TrimRight(preferred[:min(len(preferred), 54)], "-")
+	default:
+		for assigned, assignment := range assignments {
+			if assignment.owns(instanceID, serviceName) {
+				slug = assigned
+				break
+			}
+		}
+	}
+	if slug == "" {
+		// The random suffix makes a collision unlikely, but a recorded slug is never handed out again
+		for attempt := 0; attempt < 10; attempt++ {
+			slug = generateSlug(base)
+			if conflict(slug) == nil {
+				break
+			}
+		}
+	}
+	if err := conflict(slug); err != nil {
+		return "", err
+	}
+	return slug, nil
+}
+
+// assignedSlug returns the slug assigned to instanceID, or to serviceName without one
+func (m *Manager) assignedSlug(instanceID, serviceName string) (string, bool) {
+	for slug, assignment := range m.slugAssignments() {
+		if assignment.owns(instanceID, serviceName) {
+			return slug, true
+		}
+	}
+	return "", false
+}
+
+// slugRouterUnsafe returns the container routed under slug, if any. The caller holds the mutex.
+func (m *Manager) slugRouterUnsafe(slug string) *models.Container {
+	for _, container := range m.containers {
+		if container.Slug == slug {
+			return container
+		}
+	}
+	return nil
+}
+
+// recordSlug assigns container's slug to its instance, or its service without one, keeping the
+// time of an earlier assignment to the same owner. Any other slug the owner had is released.
+func (m *Manager) recordSlug(ctx context.Context, container *models.Container) {
+	if container.Slug == "" {
+		return
+	}
+	instanceID := container.Environment["MCP_INSTANCE_ID"]
+	for slug, assignment := range m.slugAssignments() {
+		if slug != container.Slug && assignment.owns(instanceID, container.ServiceName) {
+			_ = m.store.Delete(slugsBucket, slug)
+		}
+	}
+
+	var existing slugAssignment
+	found, _ := m.store.Get(slugsBucket, container.Slug, &existing)
+	if found && existing.owns(instanceID, container.ServiceName) && existing.WorkspaceID == container.WorkspaceID {
+		return
+	}
+
+	assignment := slugAssignment{
+		InstanceID:  instanceID,
+		ServiceName: container.ServiceName,
+		WorkspaceID: container.WorkspaceID,
+		AssignedAt:  time.Now(),
+	}
+	if found && existing.owns(instanceID, container.ServiceName) {
+		assignment.AssignedAt = existing.AssignedAt
+	}
+	if err := m.store.Put(slugsBucket, container.Slug, assignment); err != nil {
+		m.logger.WarnContext(ctx, "Failed to record slug assignment",
+			slog.String("slug", container.Slug),
+			slog.String("service", container.ServiceName),
+			slog.String("error", err.Error()))
+	}
+}
+
+// recordDiscoveredSlugs records the slugs of discovered containers that have none yet, such as
+// those created before assignments were recorded, and warns about those owned by another instance
+func (m *Manager) recordDiscoveredSlugs(ctx context.Context) {
+	assignments := m.slugAssignments()
+	for _, container := range m.containers {
+		if container.Slug == "" {
+			continue
+		}
+		assignment, assigned := assignments[container.Slug]
+		if !assigned {
+			m.recordSlug(ctx, container)
+			continue
+		}
+		if !assignment.owns(container.Environment["MCP_INSTANCE_ID"], container.ServiceName) {
+			m.logger.WarnContext(ctx, "Discovered container is routed under a slug assigned to another instance",
+				slog.String("service", container.ServiceName),
+				slog.String("slug", container.Slug),
+				slog.String("owner", assignment.owner()))
+		}
+	}
+}
+
+// SlugAssignments lists every recorded slug with the container routed under it, and reports
+// conflicts: a slug routing a container it is not assigned to, a container routed under a slug
+// that is not recorded, and an owner routed under another slug than the one assigned to it
+func (m *Manager) SlugAssignments() []models.SlugAssignment {
+	assignments := m.slugAssignments()
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	slugs := []models.SlugAssignment{}
+	for slug, assignment := range assignments {
+		entry := models.SlugAssignment{
+			Slug:        slug,
+			URL:         fmt.Sprintf("%s/mcp/%s", m.config.Traefik.ProxyHost, slug),
+			InstanceID:  assignment.InstanceID,
+			ServiceName: assignment.ServiceName,
+			WorkspaceID: assignment.WorkspaceID,
+			AssignedAt:  &assignment.AssignedAt,
+		}
+		if routed := m.slugRouterUnsafe(slug); routed != nil {
+			entry.Container = routed.ServiceName
+			if !assignment.owns(routed.Environment["MCP_INSTANCE_ID"], routed.ServiceName) {
+				entry.Conflict = fmt.Sprintf("routes container %s, but is assigned to %s", routed.ServiceName, assignment.owner())
+			}
+		}
+		if entry.Conflict == "" {
+			for _, container := range m.containers {
+				if container.Slug != "" && container.Slug != slug &&
+					assignment.owns(container.Environment["MCP_INSTANCE_ID"], container.ServiceName) {
+					entry.Conflict = fmt.Sprintf("%s is routed under %s instead", assignment.owner(), container.Slug)
+				}
+			}
+		}
+		slugs = append(slugs, entry)
+	}
+	for _, container := range m.containers {
+		if _, assigned := assignments[container.Slug]; container.Slug == "" || assigned {
+			continue
+		}
+		slugs = append(slugs, models.SlugAssignment{
+			Slug:        container.Slug,
+			URL:         container.URL,
+			InstanceID:  container.Environment["MCP_INSTANCE_ID"],
+			ServiceName: container.ServiceName,
+			WorkspaceID: container.WorkspaceID,
+			Container:   container.ServiceName,
+			Conflict:    "not recorded, so the slug is not kept when the instance is recreated",
+		})
+	}
+
+	sort.Slice(slugs, func(i, j int) bool { return slugs[i].Slug < slugs[j].Slug })
+	return slugs
+}
+
+// RenameSlug moves a container's route to slug. The old URL stops working at once. The new slug is
+// assigned to the instance, so it is kept when the instance is recreated.
+func (m *Manager) RenameSlug(ctx context.Context, serviceName, slug string) (*models.Container, error) {
+	if err := validateSlug(slug); err != nil {
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	container, exists := m.containers[serviceName]
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+	if container.Slug == "" {
+		return nil, fmt.Errorf("%w: container %s is kept for rollback and has no route", ErrInvalidSlug, serviceName)
+	}
+	if container.Slug == slug {
+		return container, nil
+	}
+	instanceID := container.Environment["MCP_INSTANCE_ID"]
+	if _, err := m.slugForUnsafe(slug, "", instanceID, serviceName); err != nil {
+		return nil, err
+	}
+
+	previous := container.Slug
+	previousURL := container.URL
+	container.Slug = slug
+	container.URL = fmt.Sprintf("%s/mcp/%s", m.config.Traefik.ProxyHost, slug)
+	// A stopped container is routed again when it starts
+	if container.Status == models.StatusRunning {
+		if _, err := m.refreshRoute(ctx, container); err != nil {
+			container.Slug, container.URL = previous, previousURL
+			return nil, fmt.Errorf("failed to route %s: %w", slug, err)
+		}
+	}
+	if err := m.traefikManager.RemoveMCPService(ctx, previous); err != nil {
+		m.logger.WarnContext(ctx, "Failed to remove the route of the previous slug",
+			slog.String("slug", previous),
+			slog.String("error", err.Error()))
+	}
+	container.UpdatedAt = time.Now()
+
+	// Labels cannot be changed, so the records discovery prefers over them carry the new slug
+	m.recordSlug(ctx, container)
+	if record, adopted := m.adoption(container.Name); adopted {
+		record.Slug = slug
+		if err := m.store.Put(adoptedBucket, container.Name, record); err != nil {
+			m.logger.WarnContext(ctx, "Failed to update adoption record", slog.String("error", err.Error()))
+		}
+	}
+	if record := m.deployment(serviceName); record.Production == container.Name {
+		record.ProductionSlug = slug
+		if err := m.store.Put(deploymentsBucket, serviceName, record); err != nil {
+			m.logger.WarnContext(ctx, "Failed to update deployment record", slog.String("error", err.Error()))
+		}
+	}
+
+	if instanceID != "" && container.Status == models.StatusRunning {
+		if err := m.eventPublisher.PublishRunning(ctx, instanceID, serviceName, container.ID, container.URL); err != nil {
+			m.logger.WarnContext(ctx, "Failed to publish the instance's new URL",
+				slog.String("instance_id", instanceID),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	m.logger.InfoContext(ctx, "Container slug renamed",
+		slog.String("service", serviceName),
+		slog.String("previous", previous),
+		slog.String("slug", slug))
+	return container, nil
+}
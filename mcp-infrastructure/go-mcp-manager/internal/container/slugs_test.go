@@ -0,0 +1,106 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestSlugAssignments(t *testing.T) {
+	cfg := &config.Config{
+		Container: config.ContainerConfig{NamePrefix: "test-", MaxContainers: 10},
+		State:     config.StateConfig{Dir: t.TempDir()},
+		Traefik:   config.TraefikConfig{ProxyHost: "http://proxy"},
+	}
+	manager := newTestManager(cfg)
+	ctx := context.Background()
+
+	slug, err := manager.slugForUnsafe("", "", "inst-1", "github")
+	if err != nil || !strings.HasPrefix(slug, "github-") {
+		t.Fatalf("Expected a generated slug, got %q, %v", slug, err)
+	}
+	manager.recordSlug(ctx, &models.Container{ServiceName: "github", Slug: slug, Environment: map[string]string{"MCP_INSTANCE_ID": "inst-1"}})
+
+	// The instance was deleted; created again, possibly under another service name, it gets its slug back
+	again, err := manager.slugForUnsafe("", "", "inst-1", "github-tools")
+	if err != nil || again != slug {
+		t.Errorf("Expected the recreated instance to keep slug %s, got %q, %v", slug, again, err)
+	}
+	if _, err := manager.slugForUnsafe(slug, "", "inst-2", "other"); !errors.Is(err, ErrSlugConflict) {
+		t.Errorf("Expected a slug assigned to another instance to conflict, got %v", err)
+	}
+
+	manager.containers["other"] = &models.Container{ServiceName: "other", Slug: slug, Environment: map[string]string{"MCP_INSTANCE_ID": "inst-2"}}
+	manager.containers["legacy"] = &models.Container{ServiceName: "legacy", Slug: "legacy-1234"}
+	assignments := manager.SlugAssignments()
+	if len(assignments) != 2 {
+		t.Fatalf("Expected the recorded slug and the unrecorded one, got %+v", assignments)
+	}
+	if assignments[0].Slug != slug || assignments[0].Container != "other" || assignments[0].Conflict == "" {
+		t.Errorf("Expected a conflict for the slug routing another instance, got %+v", assignments[0])
+	}
+	if assignments[1].Slug != "legacy-1234" || assignments[1].Conflict == "" {
+		t.Errorf("Expected the unrecorded slug to be reported, got %+v", assignments[1])
+	}
+
+	manager.recordDiscoveredSlugs(ctx)
+	if again, err := manager.slugForUnsafe("", "", "", "legacy"); err != nil || again != "legacy-1234" {
+		t.Errorf("Expected a discovered container to keep its slug, got %q, %v", again, err)
+	}
+}
+
+func TestCustomSlug(t *testing.T) {
+	cfg := &config.Config{
+		Container: config.ContainerConfig{NamePrefix: "test-", MaxContainers: 10},
+		State:     config.StateConfig{Dir: t.TempDir()},
+		Traefik:   config.TraefikConfig{ProxyHost: "http://proxy"},
+	}
+	manager := newTestManager(cfg)
+	manager.traefikManager.configPath = filepath.Join(t.TempDir(), "dynamic.yml")
+	ctx := context.Background()
+
+	for _, slug := range []string{"Bad_Slug", "-leading", "trailing-", strings.Repeat("a", 64)} {
+		if err := validateSlug(slug); !errors.Is(err, ErrInvalidSlug) {
+			t.Errorf("Expected %q to be rejected, got %v", slug, err)
+		}
+	}
+
+	slug, err := manager.slugForUnsafe("", "team-search", "inst-1", "search")
+	if err != nil || slug != "team-search" {
+		t.Fatalf("Expected the free preferred slug, got %q, %v", slug, err)
+	}
+	manager.recordSlug(ctx, &models.Container{ServiceName: "search", Slug: slug, Environment: map[string]string{"MCP_INSTANCE_ID": "inst-1"}})
+	if taken, err := manager.slugForUnsafe("", "team-search", "inst-2", "search-copy"); err != nil || !strings.HasPrefix(taken, "team-search-") {
+		t.Errorf("Expected a taken preferred slug to get a suffix, got %q, %v", taken, err)
+	}
+
+	manager.containers["search"] = &models.Container{
+		Name:        "test-search",
+		ServiceName: "search",
+		Slug:        slug,
+		Status:      models.StatusStopped,
+		Environment: map[string]string{"MCP_INSTANCE_ID": "inst-1"},
+	}
+	manager.containers["other"] = &models.Container{ServiceName: "other", Slug: "other-1234", Environment: map[string]string{"MCP_INSTANCE_ID": "inst-2"}}
+	if _, err := manager.RenameSlug(ctx, "search", "other-1234"); !errors.Is(err, ErrSlugConflict) {
+		t.Errorf("Expected renaming to another container's slug to conflict, got %v", err)
+	}
+	renamed, err := manager.RenameSlug(ctx, "search", "search-v2")
+	if err != nil {
+		t.Fatalf("Expected the rename to succeed, got %v", err)
+	}
+	if renamed.URL != "http://proxy/mcp/search-v2" {
+		t.Errorf("Expected the URL to follow the slug, got %s", renamed.URL)
+	}
+	if assigned, _ := manager.assignedSlug("inst-1", "search"); assigned != "search-v2" {
+		t.Errorf("Expected the instance to be assigned the new slug, got %q", assigned)
+	}
+	if _, assigned := manager.slugAssignments()[slug]; assigned {
+		t.Errorf("Expected the previous slug %s to be released", slug)
+	}
+}
@@ -0,0 +1,234 @@
+package container
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// defaultSmokeTestPath is the MCP endpoint smoke tests call when none is configured
+	defaultSmokeTestPath = "/mcp"
+	// defaultSmokeTestTimeout bounds the whole smoke test, from initialize to the tool result
+	defaultSmokeTestTimeout = 30 * time.Second
+	// mcpProtocolVersion is the protocol revision smoke tests announce
+	mcpProtocolVersion = "2025-03-26"
+)
+
+// validateSmokeTest checks a smoke test before a container is staged with it
+func validateSmokeTest(test *models.SmokeTest) error {
+	if test == nil {
+		return nil
+	}
+	if test.Tool == "" {
+		return fmt.Errorf("smoke_test.tool is required")
+	}
+	if test.Path != "" && !strings.HasPrefix(test.Path, "/") {
+		return fmt.Errorf("smoke_test.path must start with /")
+	}
+	if test.TimeoutSeconds < 0 || test.TimeoutSeconds > 300 {
+		return fmt.Errorf("smoke_test.timeout_seconds must be between 0 and 300")
+	}
+	return nil
+}
+
+// mcpResponse is a JSON-RPC response from an MCP server
+type mcpResponse struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// runSmokeTest initializes an MCP session with the container over streamable HTTP and calls the
+// configured tool; the test passes when the call returns a result that is not flagged as an error
+func (m *Manager) runSmokeTest(ctx context.Context, container *models.Container, test *models.SmokeTest) *models.SmokeTestResult {
+	result := &models.SmokeTestResult{Tool: test.Tool}
+	start := time.Now()
+	defer func() { result.DurationMs = time.Since(start).Milliseconds() }()
+
+	timeout := defaultSmokeTestTimeout
+	if test.TimeoutSeconds > 0 {
+		timeout = time.Duration(test.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	containerIP, err := m.getContainerIP(ctx, container.ID)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	path := test.Path
+	if path == "" {
+		path = defaultSmokeTestPath
+	}
+	upstreamHost, upstreamPort := m.upstreamAddress(ctx, container, containerIP)
+	endpoint := fmt.Sprintf("%s://%s:%d%s", upstreamScheme(container), upstreamHost, upstreamPort, path)
+
+	session := &mcpSession{client: m.healthChecker.httpClient, endpoint: endpoint}
+	if _, err := session.call(ctx, 1, "initialize", map[string]interface{}{
+		"protocolVersion": mcpProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]string{"name": "mcp-manager-smoke-test", "version": "1.0"},
+	}); err != nil {
+		result.Error = fmt.Sprintf("initialize failed: %v", err)
+		return result
+	}
+	if err := session.notify(ctx, "notifications/initialized"); err != nil {
+		result.Error = fmt.Sprintf("initialized notification failed: %v", err)
+		return result
+	}
+
+	arguments := test.Arguments
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+	raw, err := session.call(ctx, 2, "tools/call", map[string]interface{}{
+		"name":      test.Tool,
+		"arguments": arguments,
+	})
+	if err != nil {
+		result.Error = fmt.Sprintf("tools/call %s failed: %v", test.Tool, err)
+		return result
+	}
+
+	var toolResult struct {
+		IsError bool `json:"isError"`
+	}
+	if err := json.Unmarshal(raw, &toolResult); err != nil {
+		result.Error = fmt.Sprintf("unreadable tool result: %v", err)
+		return result
+	}
+	if toolResult.IsError {
+		result.Error = fmt.Sprintf("tool %s returned an error result", test.Tool)
+		return result
+	}
+
+	result.Passed = true
+	return result
+}
+
+// mcpSession is a minimal MCP streamable HTTP client that remembers the session the server assigns
+type mcpSession struct {
+	client    *http.Client
+	endpoint  string
+	sessionID string
+}
+
+// call sends a JSON-RPC request and returns the result of the response with the same ID
+func (s *mcpSession) call(ctx context.Context, id int, method string, params interface{}) (json.RawMessage, error) {
+	resp, err := s.post(ctx, map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if session := resp.Header.Get("Mcp-Session-Id"); session != "" {
+		s.sessionID = session
+	}
+
+	response, err := readMCPResponse(resp, id)
+	if err != nil {
+		return nil, err
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("error %d: %s", response.Error.Code, response.Error.Message)
+	}
+	return response.Result, nil
+}
+
+// notify sends a JSON-RPC notification
+func (s *mcpSession) notify(ctx context.Context, method string) error {
+	resp, err := s.post(ctx, map[string]interface{}{"jsonrpc": "2.0", "method": method})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// close ends the session the server assigned, if any, so probes do not leave sessions behind
+func (s *mcpSession) close(ctx context.Context) {
+	if s.sessionID == "" {
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.endpoint, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Mcp-Session-Id", s.sessionID)
+	if resp, err := s.client.Do(req); err == nil {
+		resp.Body.Close()
+	}
+}
+
+// post sends one JSON-RPC message and fails on non-2xx statuses
+func (s *mcpSession) post(ctx context.Context, message interface{}) (*http.Response, error) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if s.sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", s.sessionID)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return resp, nil
+}
+
+// readMCPResponse reads the response with the given ID from a JSON body or an SSE stream
+func readMCPResponse(resp *http.Response, id int) (*mcpResponse, error) {
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		var response mcpResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		return &response, nil
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		data, found := strings.CutPrefix(scanner.Text(), "data:")
+		if !found {
+			continue
+		}
+		var response mcpResponse
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &response); err != nil {
+			continue
+		}
+		// Servers may interleave requests and notifications of their own
+		if response.ID == id && (response.Result != nil || response.Error != nil) {
+			return &response, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read event stream: %w", err)
+	}
+	return nil, fmt.Errorf("event stream ended without a response")
+}
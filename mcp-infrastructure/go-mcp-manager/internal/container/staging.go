@@ -0,0 +1,370 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// deploymentsBucket records, per service, which container serves the production slug and which
+// one a promotion replaced, so both survive a manager restart
+const deploymentsBucket = "deployments"
+
+// Service name suffixes of a service's staged candidate and of the container kept for rollback
+const (
+	stagingSuffix  = "-staging"
+	previousSuffix = "-previous"
+)
+
+// ErrStagingCheckFailed is returned when a staged container fails its health check or smoke test
+var ErrStagingCheckFailed = errors.New("staging container failed verification")
+
+// deploymentRecord tracks a service's blue/green containers by container name
+type deploymentRecord struct {
+	ProductionSlug string `json:"production_slug"`
+	// Production serves ProductionSlug; Previous is the container the last promotion or rollback replaced
+	Production string            `json:"production"`
+	Previous   string            `json:"previous,omitempty"`
+	InstanceID string            `json:"instance_id,omitempty"`
+	SmokeTest  *models.SmokeTest `json:"smoke_test,omitempty"`
+}
+
+// StageContainer creates a copy of a service's container with the requested changes under a
+// preview URL, and verifies it with the health check and optional smoke test. A verified copy is
+// sent the share of production traffic the request asks to mirror.
+func (m *Manager) StageContainer(ctx context.Context, serviceName string, req models.StageContainerRequest) (*models.StagingResponse, error) {
+	if err := validateSmokeTest(req.SmokeTest); err != nil {
+		return nil, err
+	}
+	if err := validateMirror(req.Mirror); err != nil {
+		return nil, err
+	}
+	if req.Mirror != nil {
+		if _, err := m.proxyMetricsURL(); err != nil {
+			return nil, err
+		}
+	}
+	if strings.HasSuffix(serviceName, stagingSuffix) || strings.HasSuffix(serviceName, previousSuffix) {
+		return nil, fmt.Errorf("container %s is itself part of a staged deployment", serviceName)
+	}
+
+	m.mutex.RLock()
+	tracked, exists := m.containers[serviceName]
+	var production models.Container
+	if exists {
+		production = *tracked
+	}
+	_, staged := m.containers[serviceName+stagingSuffix]
+	m.mutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+	if staged {
+		return nil, fmt.Errorf("container %s already has a staging container, promote or delete it first", serviceName)
+	}
+
+	spec := containerSpec(&production)
+	spec.ServiceName = serviceName + stagingSuffix
+	if req.Image != "" {
+		spec.Image, spec.Source, spec.Runtime = req.Image, nil, nil
+	}
+	if req.Command != nil {
+		spec.Command = slices.Clone(req.Command)
+	}
+	if spec.Environment == nil {
+		spec.Environment = make(map[string]string)
+	}
+	maps.Copy(spec.Environment, req.Environment)
+	// The instance ID moves to the staging container only when it is promoted
+	delete(spec.Environment, "MCP_INSTANCE_ID")
+	// A hostname routes to a single container, so the preview is reachable by path only
+	if spec.Routing != nil && spec.Routing.Type == models.RoutingHost {
+		spec.Routing = nil
+	}
+
+	record := m.deployment(serviceName)
+	record.ProductionSlug = production.Slug
+	record.Production = production.Name
+	record.InstanceID = production.Environment["MCP_INSTANCE_ID"]
+	record.SmokeTest = req.SmokeTest
+	if err := m.store.Put(deploymentsBucket, serviceName, record); err != nil {
+		return nil, fmt.Errorf("failed to record deployment: %w", err)
+	}
+
+	staging, err := m.CreateContainer(ctx, spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging container for %s: %w", serviceName, err)
+	}
+	check := m.verifyStaging(ctx, staging, req.SmokeTest)
+
+	m.logger.InfoContext(ctx, "Container staged",
+		slog.String("service", serviceName),
+		slog.String("staging", staging.ServiceName),
+		slog.String("preview_url", staging.URL),
+		slog.Bool("passed", check.Passed))
+
+	response := &models.StagingResponse{
+		Container:  staging,
+		PreviewURL: staging.URL,
+		Check:      check,
+	}
+	if req.Mirror != nil && check.Passed {
+		job, err := m.startShadowing(ctx, serviceName, production.Slug, staging.Slug, req.Mirror)
+		if err != nil {
+			m.logger.WarnContext(ctx, "Failed to mirror traffic to staging container",
+				slog.String("service", serviceName),
+				slog.String("error", err.Error()))
+			response.MirrorError = err.Error()
+		}
+		response.MirrorJob = job
+	}
+	return response, nil
+}
+
+// verifyStaging runs the health check and, once healthy, the smoke test against a staged container
+func (m *Manager) verifyStaging(ctx context.Context, container *models.Container, smokeTest *models.SmokeTest) models.StagingCheck {
+	var check models.StagingCheck
+
+	health, err := m.healthChecker.PerformHealthCheck(ctx, container)
+	switch {
+	case err != nil:
+		check.HealthError = err.Error()
+	case !health.Healthy:
+		check.HealthError = health.Error
+	default:
+		check.Healthy = true
+	}
+
+	if check.Healthy && smokeTest != nil {
+		check.SmokeTest = m.runSmokeTest(ctx, container, smokeTest)
+	}
+	check.Passed = check.Healthy && (check.SmokeTest == nil || check.SmokeTest.Passed)
+	return check
+}
+
+// PromoteContainer moves a service's production slug to its staging container after verifying it
+// again, unless force is set. The replaced container keeps running without a route for RollbackContainer.
+func (m *Manager) PromoteContainer(ctx context.Context, serviceName string, force bool) (*models.PromotionResult, error) {
+	stagingName := serviceName + stagingSuffix
+
+	m.mutex.RLock()
+	tracked, exists := m.containers[stagingName]
+	var staging models.Container
+	if exists {
+		staging = *tracked
+	}
+	_, hasPrevious := m.containers[serviceName+previousSuffix]
+	m.mutex.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("container %s has no staging container", serviceName)
+	}
+
+	var check *models.StagingCheck
+	if !force {
+		verified := m.verifyStaging(ctx, &staging, m.deployment(serviceName).SmokeTest)
+		check = &verified
+		if !verified.Passed {
+			return &models.PromotionResult{ServiceName: serviceName, Check: check},
+				fmt.Errorf("%w: %s is not promoted", ErrStagingCheckFailed, stagingName)
+		}
+		if err := m.shadowRegressed(serviceName); err != nil {
+			return &models.PromotionResult{ServiceName: serviceName, Check: check},
+				fmt.Errorf("%w: %v", ErrStagingCheckFailed, err)
+		}
+	}
+
+	// Only one container is kept to roll back to
+	if hasPrevious {
+		if err := m.DeleteContainer(ctx, serviceName+previousSuffix); err != nil {
+			return nil, fmt.Errorf("failed to remove the previous container of %s: %w", serviceName, err)
+		}
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	incoming, exists := m.containers[stagingName]
+	if !exists {
+		return nil, fmt.Errorf("container %s has no staging container", serviceName)
+	}
+	previewSlug := incoming.Slug
+
+	// Production stops being mirrored to the container it is about to become
+	if production, exists := m.containers[serviceName]; exists {
+		if err := m.traefikManager.ClearMirror(ctx, production.Slug); err != nil {
+			return nil, fmt.Errorf("failed to stop mirroring traffic to %s: %w", stagingName, err)
+		}
+	}
+
+	result, err := m.swapProductionUnsafe(ctx, serviceName, incoming)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.traefikManager.RemoveMCPService(ctx, previewSlug); err != nil {
+		m.logger.WarnContext(ctx, "Failed to remove preview route",
+			slog.String("slug", previewSlug),
+			slog.String("error", err.Error()))
+	}
+	result.Check = check
+
+	m.logger.InfoContext(ctx, "Staging container promoted",
+		slog.String("service", serviceName),
+		slog.String("production", result.Production),
+		slog.String("previous", result.Previous),
+		slog.Bool("forced", force))
+
+	return result, nil
+}
+
+// RollbackContainer moves a service's production slug back to the container the last promotion
+// replaced; the rolled back container is kept in its place, so the rollback can be undone the same way
+func (m *Manager) RollbackContainer(ctx context.Context, serviceName string) (*models.PromotionResult, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	previous, exists := m.containers[serviceName+previousSuffix]
+	if !exists {
+		return nil, fmt.Errorf("container %s has no previous container to roll back to", serviceName)
+	}
+	if previous.Status != models.StatusRunning {
+		return nil, fmt.Errorf("previous container of %s is %s, start it before rolling back", serviceName, previous.Status)
+	}
+
+	result, err := m.swapProductionUnsafe(ctx, serviceName, previous)
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.InfoContext(ctx, "Container rolled back",
+		slog.String("service", serviceName),
+		slog.String("production", result.Production),
+		slog.String("previous", result.Previous))
+
+	return result, nil
+}
+
+// swapProductionUnsafe points the production slug at incoming in a single route update, then makes
+// incoming the service's container and keeps the replaced one as its previous container; the
+// caller holds the mutex
+func (m *Manager) swapProductionUnsafe(ctx context.Context, serviceName string, incoming *models.Container) (*models.PromotionResult, error) {
+	outgoing, exists := m.containers[serviceName]
+	if !exists {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+
+	containerIP, err := m.getContainerIP(ctx, incoming.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get IP of %s: %w", incoming.Name, err)
+	}
+	upstreamHost, upstreamPort := m.upstreamAddress(ctx, incoming, containerIP)
+	route, routing := incoming.Route, outgoing.Routing
+	if err := m.traefikManager.AddMCPService(ctx, outgoing.Slug, upstreamHost, upstreamPort, route, routing, m.upstreamTLS(incoming)); err != nil {
+		return nil, fmt.Errorf("failed to switch the route of %s: %w", serviceName, err)
+	}
+
+	record := m.deployment(serviceName)
+	record.ProductionSlug = outgoing.Slug
+	if record.InstanceID == "" {
+		record.InstanceID = outgoing.Environment["MCP_INSTANCE_ID"]
+	}
+
+	incomingServiceName := incoming.ServiceName
+	incoming.ServiceName = serviceName
+	incoming.Slug = outgoing.Slug
+	incoming.URL = outgoing.URL
+	incoming.Routing = routing
+	incoming.Environment = maps.Clone(incoming.Environment)
+	if incoming.Environment == nil {
+		incoming.Environment = make(map[string]string)
+	}
+	if record.InstanceID != "" {
+		incoming.Environment["MCP_INSTANCE_ID"] = record.InstanceID
+	}
+
+	outgoing.ServiceName = serviceName + previousSuffix
+	outgoing.Slug = ""
+	outgoing.URL = ""
+	outgoing.Routing = nil
+	outgoing.Environment = maps.Clone(outgoing.Environment)
+	delete(outgoing.Environment, "MCP_INSTANCE_ID")
+
+	delete(m.containers, incomingServiceName)
+	m.containers[serviceName] = incoming
+	m.containers[outgoing.ServiceName] = outgoing
+
+	record.Production = incoming.Name
+	record.Previous = outgoing.Name
+	if err := m.store.Put(deploymentsBucket, serviceName, record); err != nil {
+		m.logger.WarnContext(ctx, "Failed to record deployment",
+			slog.String("service", serviceName),
+			slog.String("error", err.Error()))
+	}
+
+	return &models.PromotionResult{
+		ServiceName: serviceName,
+		URL:         incoming.URL,
+		Production:  incoming.Name,
+		Previous:    outgoing.Name,
+	}, nil
+}
+
+// deployment returns the deployment record of a service, empty if it was never staged
+func (m *Manager) deployment(serviceName string) deploymentRecord {
+	var record deploymentRecord
+	if _, err := m.store.Get(deploymentsBucket, serviceName, &record); err != nil {
+		return deploymentRecord{}
+	}
+	return record
+}
+
+// deploymentRole returns the service name and slug a container has taken over through a promotion
+// or rollback, which differ from those recorded in its labels at create time
+func (m *Manager) deploymentRole(containerName string) (serviceName, slug, instanceID string, found bool) {
+	records, err := m.store.List(deploymentsBucket)
+	if err != nil {
+		return "", "", "", false
+	}
+	for service := range records {
+		record := m.deployment(service)
+		switch containerName {
+		case record.Production:
+			return service, record.ProductionSlug, record.InstanceID, true
+		case record.Previous:
+			return service + previousSuffix, "", "", true
+		}
+	}
+	return "", "", "", false
+}
+
+// forgetDeployment updates the deployment records when a container is deleted: deleting a service
+// drops its record, and deleting the previous container leaves nothing to roll back to
+func (m *Manager) forgetDeployment(ctx context.Context, serviceName string) {
+	if m.store.Has(deploymentsBucket, serviceName) {
+		if err := m.store.Delete(deploymentsBucket, serviceName); err != nil {
+			m.logger.WarnContext(ctx, "Failed to clear deployment record",
+				slog.String("service", serviceName),
+				slog.String("error", err.Error()))
+		}
+		return
+	}
+
+	service, isPrevious := strings.CutSuffix(serviceName, previousSuffix)
+	if !isPrevious || !m.store.Has(deploymentsBucket, service) {
+		return
+	}
+	record := m.deployment(service)
+	record.Previous = ""
+	if err := m.store.Put(deploymentsBucket, service, record); err != nil {
+		m.logger.WarnContext(ctx, "Failed to update deployment record",
+			slog.String("service", service),
+			slog.String("error", err.Error()))
+	}
+}
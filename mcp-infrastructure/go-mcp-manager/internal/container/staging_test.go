@@ -0,0 +1,97 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestStagingDeployment(t *testing.T) {
+	cfg := &config.Config{Container: config.ContainerConfig{NamePrefix: "mcp-", ManagedByLabel: "mcp-manager"}}
+	manager := newTestManager(cfg)
+	ctx := context.Background()
+
+	if err := validateSmokeTest(&models.SmokeTest{}); err == nil {
+		t.Error("Expected a smoke test without a tool to be rejected")
+	}
+	if err := validateSmokeTest(&models.SmokeTest{Tool: "search", Path: "mcp"}); err == nil {
+		t.Error("Expected a relative smoke test path to be rejected")
+	}
+	if _, err := manager.StageContainer(ctx, "github-staging", models.StageContainerRequest{}); err == nil {
+		t.Error("Expected staging a staging container to be rejected")
+	}
+
+	record := deploymentRecord{ProductionSlug: "github-abc", Production: "mcp-github-staging", Previous: "mcp-github", InstanceID: "inst-1"}
+	if err := manager.store.Put(deploymentsBucket, "github", record); err != nil {
+		t.Fatalf("Failed to record deployment: %v", err)
+	}
+	if service, slug, instanceID, found := manager.deploymentRole("mcp-github-staging"); !found || service != "github" || slug != "github-abc" || instanceID != "inst-1" {
+		t.Errorf("Expected the promoted container to serve github, got %s %s %s", service, slug, instanceID)
+	}
+	if service, slug, _, found := manager.deploymentRole("mcp-github"); !found || service != "github-previous" || slug != "" {
+		t.Errorf("Expected the replaced container to be kept without a route, got %s %q", service, slug)
+	}
+	if _, _, _, found := manager.deploymentRole("mcp-slack"); found {
+		t.Error("Expected containers outside deployments to keep their labels")
+	}
+
+	manager.forgetDeployment(ctx, "github-previous")
+	if manager.deployment("github").Previous != "" {
+		t.Error("Expected deleting the previous container to leave nothing to roll back to")
+	}
+	manager.forgetDeployment(ctx, "github")
+	if manager.store.Has(deploymentsBucket, "github") {
+		t.Error("Expected deleting the service to drop its deployment record")
+	}
+
+	// Smoke tests speak MCP over streamable HTTP, with JSON or SSE responses
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message struct {
+			ID     int    `json:"id"`
+			Method string `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&message)
+		calls = append(calls, message.Method+" "+r.Header.Get("Mcp-Session-Id"))
+		switch message.Method {
+		case "initialize":
+			w.Header().Set("Mcp-Session-Id", "session-1")
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, `{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-03-26"}}`)
+		case "tools/call":
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = io.WriteString(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n")
+			_, _ = io.WriteString(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":2,\"result\":{\"isError\":true}}\n\n")
+		default:
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	session := &mcpSession{client: server.Client(), endpoint: server.URL}
+	if _, err := session.call(ctx, 1, "initialize", nil); err != nil {
+		t.Fatalf("Expected initialize to succeed, got %v", err)
+	}
+	if err := session.notify(ctx, "notifications/initialized"); err != nil {
+		t.Fatalf("Expected the notification to be accepted, got %v", err)
+	}
+	raw, err := session.call(ctx, 2, "tools/call", map[string]interface{}{"name": "search"})
+	if err != nil {
+		t.Fatalf("Expected the tool call to succeed, got %v", err)
+	}
+	if !strings.Contains(string(raw), `"isError":true`) {
+		t.Errorf("Expected the tool result from the event stream, got %s", raw)
+	}
+	expected := []string{"initialize ", "notifications/initialized session-1", "tools/call session-1"}
+	if !slices.Equal(calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, calls)
+	}
+}
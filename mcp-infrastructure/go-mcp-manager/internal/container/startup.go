@@ -0,0 +1,251 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/webhooks"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// Defaults of startup probes that leave out their interval or maximum duration
+const (
+	defaultStartupInterval    = 5 * time.Second
+	defaultStartupMaxDuration = 10 * time.Minute
+)
+
+// errProcessNotRunning fails a startup probe whose container exited while starting
+var errProcessNotRunning = errors.New("container is not running")
+
+// startupState holds the startup probes of containers still starting, keyed by service name.
+// Guarded by m.mutex.
+type startupState struct {
+	probes map[string]*startupProbe
+}
+
+// startupProbe tracks one container waiting for its server to answer
+type startupProbe struct {
+	started  time.Time
+	deadline time.Time
+	// lastError is why the last attempt failed
+	lastError string
+}
+
+// parseStartupProbeSpec reads the startup object of a health_check
+func parseStartupProbeSpec(raw interface{}) (*models.StartupProbeConfig, error) {
+	spec, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("health_check.startup must be an object")
+	}
+
+	probe := &models.StartupProbeConfig{}
+	if value, exists := spec["type"]; exists {
+		str, _ := value.(string)
+		if str != models.StartupProbeHTTP && str != models.StartupProbeMCP {
+			return nil, fmt.Errorf("health_check.startup.type must be %q or %q", models.StartupProbeHTTP, models.StartupProbeMCP)
+		}
+		probe.Type = str
+	}
+	if value, exists := spec["path"]; exists {
+		str, ok := value.(string)
+		if !ok || !strings.HasPrefix(str, "/") {
+			return nil, fmt.Errorf("health_check.startup.path must be a string starting with /")
+		}
+		probe.Path = str
+	}
+
+	intFields := []struct {
+		name   string
+		target *int
+		min    int
+		max    int
+	}{
+		{"interval_seconds", &probe.IntervalSeconds, 1, 300},
+		{"max_duration_seconds", &probe.MaxDurationSeconds, 1, 7200},
+	}
+	for _, field := range intFields {
+		value, exists := spec[field.name]
+		if !exists {
+			continue
+		}
+		n, ok := specInt(value)
+		if !ok || n < field.min || n > field.max {
+			return nil, fmt.Errorf("health_check.startup.%s must be a number between %d and %d", field.name, field.min, field.max)
+		}
+		*field.target = n
+	}
+	return probe, nil
+}
+
+// startupInterval returns how often a starting container is probed
+func startupInterval(probe *models.StartupProbeConfig) time.Duration {
+	if probe.IntervalSeconds == 0 {
+		return defaultStartupInterval
+	}
+	return time.Duration(probe.IntervalSeconds) * time.Second
+}
+
+// startupMaxDuration returns how long a container may take to start
+func startupMaxDuration(probe *models.StartupProbeConfig) time.Duration {
+	if probe.MaxDurationSeconds == 0 {
+		return defaultStartupMaxDuration
+	}
+	return time.Duration(probe.MaxDurationSeconds) * time.Second
+}
+
+// beginStartupUnsafe keeps a container whose process just started as starting while it has a
+// startup probe, and reports whether it does. Caller must hold m.mutex.
+func (m *Manager) beginStartupUnsafe(container *models.Container) bool {
+	if container.HealthCheck == nil || container.HealthCheck.Startup == nil {
+		delete(m.startups.probes, container.ServiceName)
+		return false
+	}
+	if m.startups.probes == nil {
+		m.startups.probes = make(map[string]*startupProbe)
+	}
+
+	now := time.Now()
+	probe := container.HealthCheck.Startup
+	m.startups.probes[container.ServiceName] = &startupProbe{started: now, deadline: now.Add(startupMaxDuration(probe))}
+	counters, exists := m.healthCounters[container.Name]
+	if !exists {
+		counters = &healthCounters{}
+		m.healthCounters[container.Name] = counters
+	}
+	counters.nextCheck = now.Add(startupInterval(probe))
+	container.Status = models.StatusStarting
+	return true
+}
+
+// checkStartup runs the startup probe of a container that is still starting in place of its
+// health check, and reports whether it did
+func (m *Manager) checkStartup(container *models.Container) bool {
+	m.mutex.RLock()
+	_, starting := m.startups.probes[container.ServiceName]
+	m.mutex.RUnlock()
+	if !starting {
+		return false
+	}
+
+	err := m.healthChecker.probeStartup(m.healthCtx, container)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Ignore results for containers deleted, archived or restarted while the probe was running
+	state, starting := m.startups.probes[container.ServiceName]
+	counters, counted := m.healthCounters[container.Name]
+	if counted {
+		counters.inFlight = false
+	}
+	if tracked, exists := m.containers[container.ServiceName]; !exists || tracked != container || !starting || !counted {
+		return true
+	}
+	now := time.Now()
+
+	switch {
+	case err == nil:
+		// The next health check marks the container running once it passes
+		delete(m.startups.probes, container.ServiceName)
+		counters.nextCheck = now
+		m.recordContainerEvent(container, InstanceEventNormal, "StartupProbeSucceeded",
+			fmt.Sprintf("The server answered after %s", now.Sub(state.started).Round(time.Second)))
+	case errors.Is(err, errProcessNotRunning):
+		// The health check records the exit and restarts the container
+		delete(m.startups.probes, container.ServiceName)
+		counters.nextCheck = now
+	case now.After(state.deadline):
+		delete(m.startups.probes, container.ServiceName)
+		counters.nextCheck = now.Add(jitteredInterval(healthInterval(container.HealthCheck)))
+		m.failStartupUnsafe(container, fmt.Sprintf("Startup probe did not pass within %s: %s",
+			startupMaxDuration(container.HealthCheck.Startup), err.Error()))
+	default:
+		state.lastError = err.Error()
+		counters.nextCheck = now.Add(startupInterval(container.HealthCheck.Startup))
+		m.logger.Debug("Startup probe has not passed yet",
+			slog.String("service", container.ServiceName),
+			slog.String("error", state.lastError))
+	}
+	return true
+}
+
+// failStartupUnsafe marks a container whose server did not answer in time as failed. It keeps
+// running and is health checked from then on, so it still recovers if the server answers later.
+// Caller must hold m.mutex.
+func (m *Manager) failStartupUnsafe(container *models.Container, message string) {
+	container.Status = models.StatusError
+	container.UpdatedAt = time.Now()
+	m.logger.Warn("Container did not start in time",
+		slog.String("service", container.ServiceName),
+		slog.String("error", message))
+	m.notifyWebhook(webhooks.EventContainerFailed, container, message)
+
+	if instanceID := container.Environment["MCP_INSTANCE_ID"]; instanceID != "" {
+		go func() {
+			if err := m.eventPublisher.PublishFailed(m.healthCtx, instanceID, container.ServiceName, message); err != nil {
+				m.logger.Warn("Failed to publish startup failure",
+					slog.String("instance_id", instanceID),
+					slog.String("error", err.Error()))
+			}
+		}()
+	}
+}
+
+// probeStartup makes one attempt of a container's startup probe
+func (h *HealthChecker) probeStartup(ctx context.Context, container *models.Container) error {
+	if status, entry := h.getRealTimeContainerStatus(ctx, container); status == models.StatusStopped || status == models.StatusError {
+		return fmt.Errorf("%w: %s", errProcessNotRunning, entry.exitReason())
+	}
+	host, port, err := h.probeAddress(ctx, container)
+	if err != nil {
+		return err
+	}
+
+	probe := container.HealthCheck.Startup
+	if probe.Type == models.StartupProbeMCP {
+		path := probe.Path
+		if path == "" {
+			path = container.HealthCheck.ReadinessPath
+		}
+		return h.mcpHandshake(ctx, container, host, port, path)
+	}
+
+	path := probe.Path
+	if path == "" {
+		path = container.HealthCheck.Path
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, healthTimeout(container.HealthCheck))
+	defer cancel()
+	url := fmt.Sprintf("%s://%s:%d%s", upstreamScheme(container), host, port, path)
+	if reachable, _, err := h.checkHTTPEndpoint(probeCtx, url, container.HealthCheck.ExpectedStatus); err != nil {
+		return err
+	} else if !reachable {
+		return fmt.Errorf("HTTP endpoint not reachable")
+	}
+	return nil
+}
+
+// probeAddress returns where the manager reaches a container's server: its IP and health check
+// port, or the loopback port the engine published
+func (h *HealthChecker) probeAddress(ctx context.Context, container *models.Container) (string, int, error) {
+	containerIP, err := h.getContainerIP(ctx, container.ID)
+	if err != nil {
+		return "", 0, fmt.Errorf("could not determine container IP: %w", err)
+	}
+	port := 0
+	if container.HealthCheck != nil && container.HealthCheck.Port > 0 {
+		port = container.HealthCheck.Port
+	} else if port, err = h.getContainerExposedPort(ctx, container.ID); err != nil {
+		return "", 0, fmt.Errorf("could not determine container exposed port: %w", err)
+	}
+	if h.publishPorts {
+		if host, published, err := publishedAddress(ctx, h.logger, container.ID, port); err == nil {
+			return host, published, nil
+		}
+	}
+	return containerIP, port, nil
+}
@@ -0,0 +1,59 @@
+package container
+
+import (
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestStartupProbe(t *testing.T) {
+	hc, err := parseHealthCheckSpec(map[string]interface{}{
+		"health_check": map[string]interface{}{
+			"startup": map[string]interface{}{"type": "mcp", "interval_seconds": float64(10), "max_duration_seconds": float64(900)},
+		},
+	})
+	if err != nil || hc.Startup == nil {
+		t.Fatalf("Expected a startup probe, got %v", err)
+	}
+	if startupInterval(hc.Startup) != 10*time.Second || startupMaxDuration(hc.Startup) != 15*time.Minute {
+		t.Errorf("Expected a 10s interval and 15m maximum, got %s and %s", startupInterval(hc.Startup), startupMaxDuration(hc.Startup))
+	}
+	if _, err := parseHealthCheckSpec(map[string]interface{}{
+		"health_check": map[string]interface{}{"startup": map[string]interface{}{"type": "tcp"}},
+	}); err == nil {
+		t.Errorf("Expected an unknown probe type to be rejected")
+	}
+
+	cfg := &config.Config{State: config.StateConfig{Dir: t.TempDir()}}
+	manager := newTestManager(cfg)
+	container := &models.Container{Name: "mcp-llm", ServiceName: "llm", HealthCheck: hc}
+	manager.containers["llm"] = container
+
+	if !manager.beginStartupUnsafe(container) || container.Status != models.StatusStarting {
+		t.Fatalf("Expected the container to be starting, got %s", container.Status)
+	}
+	if counters := manager.healthCounters[container.Name]; counters == nil || time.Until(counters.nextCheck) <= 0 {
+		t.Errorf("Expected the first attempt to be scheduled after the interval")
+	}
+	if manager.beginStartupUnsafe(&models.Container{Name: "mcp-other", ServiceName: "other"}) {
+		t.Errorf("Expected no startup probe without one configured")
+	}
+
+	// A container that is not running ends its startup so the health check takes over
+	if !manager.checkStartup(container) {
+		t.Fatalf("Expected the startup probe to run")
+	}
+	if _, starting := manager.startups.probes["llm"]; starting {
+		t.Errorf("Expected the startup to end once the process is not running")
+	}
+	if manager.checkStartup(container) {
+		t.Errorf("Expected the health check to run once the startup ended")
+	}
+
+	manager.failStartupUnsafe(container, "Startup probe did not pass within 15m0s")
+	if container.Status != models.StatusError {
+		t.Errorf("Expected a container that did not start in time to fail, got %s", container.Status)
+	}
+}
@@ -0,0 +1,136 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// ErrStoragePressure is returned when creating a container while image storage is low on free space
+var ErrStoragePressure = errors.New("insufficient free space on image storage")
+
+// podmanContainerSize is the subset of `podman ps --size --format json` storage usage needs
+type podmanContainerSize struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Labels map[string]string `json:"Labels"`
+	Size   *struct {
+		RootFsSize int64 `json:"rootFsSize"`
+		RwSize     int64 `json:"rwSize"`
+	} `json:"Size"`
+}
+
+// checkStoragePressure fails with ErrStoragePressure when image storage has less free space than
+// configured, so a create is refused up front rather than failing midway through a pull or build
+func (m *Manager) checkStoragePressure(ctx context.Context) error {
+	limits := m.config.Storage
+	if limits.MinFreeBytes == 0 && limits.MinFreePercent <= 0 {
+		return nil
+	}
+
+	usage, err := filesystemUsage(m.imageStorageRoot(ctx))
+	if err != nil {
+		m.logger.DebugContext(ctx, "Skipping storage pressure check",
+			slog.String("error", err.Error()))
+		return nil
+	}
+
+	if reason := storagePressure(usage, limits.MinFreeBytes, limits.MinFreePercent); reason != "" {
+		return fmt.Errorf("%w: %s", ErrStoragePressure, reason)
+	}
+	return nil
+}
+
+// storagePressure describes why usage is below the free space limits, or returns "" if it is not
+func storagePressure(usage *models.FilesystemUsage, minFreeBytes uint64, minFreePercent float64) string {
+	if minFreeBytes > 0 && usage.AvailableBytes < minFreeBytes {
+		return fmt.Sprintf("%d MiB free on %s, %d MiB required", usage.AvailableBytes>>20, usage.Path, minFreeBytes>>20)
+	}
+	if minFreePercent > 0 && usage.TotalBytes > 0 {
+		freePercent := float64(usage.AvailableBytes) / float64(usage.TotalBytes) * 100
+		if freePercent < minFreePercent {
+			return fmt.Sprintf("%.1f%% free on %s, %.1f%% required", freePercent, usage.Path, minFreePercent)
+		}
+	}
+	return ""
+}
+
+// GetStorageUsage reports image storage usage and the disk consumed by images and managed containers
+func (m *Manager) GetStorageUsage(ctx context.Context) (*models.StorageUsage, error) {
+	report := &models.StorageUsage{
+		Images:     []models.ImageStorage{},
+		Containers: []models.ContainerStorage{},
+		Timestamp:  time.Now(),
+	}
+
+	if usage, err := filesystemUsage(m.imageStorageRoot(ctx)); err == nil {
+		report.Filesystem = usage
+		report.Pressure = storagePressure(usage, m.config.Storage.MinFreeBytes, m.config.Storage.MinFreePercent) != ""
+	}
+
+	output, err := podmanCommand(ctx, m.logger, "images", "--format", "json").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+	var images []podmanImage
+	if err := json.Unmarshal(output, &images); err != nil {
+		return nil, fmt.Errorf("failed to parse image list: %w", err)
+	}
+	for _, image := range images {
+		size := uint64(max(image.Size, 0))
+		report.Images = append(report.Images, models.ImageStorage{
+			ID:         image.ID,
+			Names:      image.Names,
+			Size:       size,
+			Containers: image.Containers,
+		})
+		report.TotalImageBytes += size
+	}
+
+	// Computing sizes walks each container's writable layer, so this is only done on request
+	output, err = listContainers(ctx, m.logger, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list container sizes: %w", err)
+	}
+	var listed []podmanContainerSize
+	if err := json.Unmarshal(output, &listed); err != nil {
+		return nil, fmt.Errorf("failed to parse container sizes: %w", err)
+	}
+
+	serviceByName := make(map[string]string)
+	m.mutex.RLock()
+	for _, container := range m.containers {
+		serviceByName[container.Name] = container.ServiceName
+	}
+	m.mutex.RUnlock()
+
+	for _, listing := range listed {
+		if len(listing.Names) == 0 {
+			continue
+		}
+		entry := models.ContainerStorage{Name: listing.Names[0], ID: listing.ID}
+		if m.containerOwnership(entry.Name, listing.Labels) == ownedByOther {
+			continue
+		}
+		if owner, isSidecar := listing.Labels[dependencyOfLabel]; isSidecar {
+			entry.ServiceName, entry.Sidecar = owner, true
+		} else if service, managed := serviceByName[entry.Name]; managed {
+			entry.ServiceName = service
+		} else {
+			continue
+		}
+		if listing.Size != nil {
+			entry.WritableBytes = uint64(max(listing.Size.RwSize, 0))
+			entry.RootFSBytes = uint64(max(listing.Size.RootFsSize, 0))
+		}
+		report.Containers = append(report.Containers, entry)
+		report.TotalContainerBytes += entry.WritableBytes
+	}
+
+	return report, nil
+}
@@ -0,0 +1,29 @@
+package container
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestStoragePressure(t *testing.T) {
+	usage := &models.FilesystemUsage{Path: "/var/lib/containers", TotalBytes: 100 << 30, AvailableBytes: 3 << 30}
+
+	if reason := storagePressure(usage, 2<<30, 0); reason != "" {
+		t.Errorf("Expected 3 GiB free to satisfy a 2 GiB minimum, got %q", reason)
+	}
+	if reason := storagePressure(usage, 4<<30, 0); reason == "" {
+		t.Errorf("Expected 3 GiB free to fail a 4 GiB minimum")
+	}
+	if reason := storagePressure(usage, 0, 5); !strings.Contains(reason, "3.0% free") {
+		t.Errorf("Expected 3%% free to fail a 5%% minimum, got %q", reason)
+	}
+
+	manager := newTestManager(&config.Config{})
+	if err := manager.checkStoragePressure(context.Background()); err != nil {
+		t.Errorf("Expected no storage check without limits, got %v", err)
+	}
+}
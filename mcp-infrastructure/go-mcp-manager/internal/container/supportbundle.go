@@ -0,0 +1,249 @@
+package container
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// supportBundleEvents is how many of the most recent scheduling decisions, preemptions and exec
+// attempts a support bundle holds
+const supportBundleEvents = 200
+
+// sensitiveConfigKeys are parts of configuration keys whose values are credentials
+var sensitiveConfigKeys = []string{"password", "secret", "token", "api_key", "private_key"}
+
+// supportBundle collects the files of a support bundle, recording the parts that fail instead of
+// giving up on the bundle
+type supportBundle struct {
+	root     string
+	writer   *tar.Writer
+	modTime  time.Time
+	manifest models.SupportBundleManifest
+}
+
+// add writes a file to the bundle
+func (b *supportBundle) add(name string, data []byte) error {
+	if err := b.writer.WriteHeader(&tar.Header{
+		Name:    path.Join(b.root, name),
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: b.modTime,
+	}); err != nil {
+		return err
+	}
+	if _, err := b.writer.Write(data); err != nil {
+		return err
+	}
+	b.manifest.Files = append(b.manifest.Files, name)
+	return nil
+}
+
+// addJSON writes value as indented JSON, or records err against name when it could not be collected
+func (b *supportBundle) addJSON(name string, value interface{}, err error) error {
+	if err != nil {
+		b.fail(name, err)
+		return nil
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		b.fail(name, err)
+		return nil
+	}
+	return b.add(name, append(data, '\n'))
+}
+
+// fail records why a file is missing from the bundle
+func (b *supportBundle) fail(name string, err error) {
+	if b.manifest.Errors == nil {
+		b.manifest.Errors = make(map[string]string)
+	}
+	b.manifest.Errors[name] = err.Error()
+}
+
+// WriteSupportBundle writes a gzipped tarball for a bug report: the manager's recent logs, its
+// configuration and containers with credentials masked, recent scheduling, preemption and exec
+// events, the route table, and each container's inspect data and health history. A part that
+// cannot be collected is listed in the manifest's errors rather than failing the bundle.
+func (m *Manager) WriteSupportBundle(ctx context.Context, w io.Writer, version string, managerLogs []byte) error {
+	now := time.Now().UTC()
+	gz := gzip.NewWriter(w)
+	bundle := &supportBundle{
+		root:    "support-bundle-" + now.Format("20060102T150405Z"),
+		writer:  tar.NewWriter(gz),
+		modTime: now,
+		manifest: models.SupportBundleManifest{
+			GeneratedAt: now,
+			Version:     version,
+			Runtime:     currentEngine().binary,
+		},
+	}
+
+	if err := m.writeSupportBundleFiles(ctx, bundle, managerLogs); err != nil {
+		return fmt.Errorf("failed to write support bundle: %w", err)
+	}
+	manifest, err := json.MarshalIndent(bundle.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write support bundle: %w", err)
+	}
+	if err := bundle.add("manifest.json", append(manifest, '\n')); err != nil {
+		return fmt.Errorf("failed to write support bundle: %w", err)
+	}
+	if err := bundle.writer.Close(); err != nil {
+		return fmt.Errorf("failed to write support bundle: %w", err)
+	}
+	return gz.Close()
+}
+
+// writeSupportBundleFiles adds everything but the manifest; only a failure to write is returned
+func (m *Manager) writeSupportBundleFiles(ctx context.Context, bundle *supportBundle, managerLogs []byte) error {
+	if managerLogs == nil {
+		bundle.fail("manager.log", fmt.Errorf("log buffering is disabled with LOG_BUFFER_LINES=0"))
+	} else if err := bundle.add("manager.log", managerLogs); err != nil {
+		return err
+	}
+
+	redacted, err := redactConfig(m.config)
+	if err := bundle.addJSON("config.json", redacted, err); err != nil {
+		return err
+	}
+
+	containers := m.ListContainers()
+	sort.Slice(containers, func(i, j int) bool { return containers[i].ServiceName < containers[j].ServiceName })
+	bundle.manifest.Containers = len(containers)
+	masked := make([]models.Container, 0, len(containers))
+	for i := range containers {
+		masked = append(masked, maskContainer(&containers[i]))
+	}
+	if err := bundle.addJSON("state.json", map[string]interface{}{
+		"containers": masked,
+		"slugs":      m.SlugAssignments(),
+		"cordon":     m.GetCordonStatus(),
+		"preemption": m.GetPreemptionStatus(),
+	}, nil); err != nil {
+		return err
+	}
+
+	preemptions, preemptionsErr := m.Preemptions("", supportBundleEvents)
+	execAudit, execAuditErr := m.ExecAudit("", supportBundleEvents)
+	events := map[string]interface{}{
+		"scheduling_decisions": m.SchedulingDecisions("", supportBundleEvents),
+		"preemptions":          preemptions,
+		"exec_audit":           execAudit,
+	}
+	// The events that could be read are kept, with the error saying which are missing
+	if err := errors.Join(preemptionsErr, execAuditErr); err != nil {
+		bundle.fail("events.json", err)
+	}
+	if err := bundle.addJSON("events.json", events, nil); err != nil {
+		return err
+	}
+
+	if routes, err := m.supportBundleRoutes(); err != nil {
+		bundle.fail("routes.yaml", err)
+	} else if err := bundle.add("routes.yaml", routes); err != nil {
+		return err
+	}
+
+	for _, container := range containers {
+		dir := path.Join("containers", container.ServiceName)
+		inspect, err := m.InspectContainer(ctx, container.ServiceName)
+		if err := bundle.addJSON(path.Join(dir, "inspect.json"), inspect, err); err != nil {
+			return err
+		}
+		history, uptime, err := m.GetHealthHistory(container.ServiceName)
+		health := map[string]interface{}{"history": history}
+		if uptime >= 0 {
+			health["uptime_percent"] = uptime
+		}
+		if err := bundle.addJSON(path.Join(dir, "health.json"), health, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// supportBundleRoutes returns the Traefik route table with the request headers routes add masked
+func (m *Manager) supportBundleRoutes() ([]byte, error) {
+	routes, err := m.traefikManager.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	for name, middleware := range routes.HTTP.Middlewares {
+		if middleware.Headers != nil {
+			headers := *middleware.Headers
+			headers.CustomRequestHeaders = maskAll(headers.CustomRequestHeaders)
+			middleware.Headers = &headers
+			routes.HTTP.Middlewares[name] = middleware
+		}
+	}
+	return yaml.Marshal(routes)
+}
+
+// redactConfig returns the configuration as generic JSON with credentials masked: the values of
+// keys naming a password, secret or token, and passwords in URLs. Secrets the configuration never
+// serializes stay out altogether.
+func redactConfig(cfg *config.Config) (interface{}, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return redactConfigValue("", value), nil
+}
+
+// redactConfigValue masks value when key names a credential, descending into objects and arrays
+func redactConfigValue(key string, value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for name, item := range typed {
+			typed[name] = redactConfigValue(name, item)
+		}
+		return typed
+	case []interface{}:
+		for i := range typed {
+			typed[i] = redactConfigValue(key, typed[i])
+		}
+		return typed
+	case string:
+		if typed != "" && sensitiveConfigKey(key) {
+			return maskedValue
+		}
+		return redactURL(typed)
+	default:
+		return value
+	}
+}
+
+// sensitiveConfigKey reports whether a configuration key holds a credential rather than where
+// one is kept, such as a file path or a Kubernetes Secret name
+func sensitiveConfigKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, suffix := range []string{"_name", "_file", "_dir", "_path", "_url"} {
+		if strings.HasSuffix(key, suffix) {
+			return false
+		}
+	}
+	for _, word := range sensitiveConfigKeys {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// supervisorSystemd runs every container under a generated systemd unit
+	supervisorSystemd = "systemd"
+	// unitMarker starts every unit the manager generates, so reconciliation leaves other units alone
+	unitMarker = "# Generated by mcp-manager"
+)
+
+// supervised reports whether containers run under systemd units. Units run the local podman, so
+// containers of a remote or docker engine are still run directly.
+func (m *Manager) supervised() bool {
+	engine := currentEngine()
+	return m.config.Container.Supervisor == supervisorSystemd && engine.binary == enginePodman && engine.host == ""
+}
+
+// unitName returns the name of the unit supervising the container named containerName
+func unitName(containerName string) string {
+	return containerName + ".service"
+}
+
+// unitDir returns the directory units are written to
+func (m *Manager) unitDir() string {
+	if dir := m.config.Container.SystemdUnitDir; dir != "" {
+		return dir
+	}
+	if m.config.Container.SystemdUser {
+		if configDir, err := os.UserConfigDir(); err == nil {
+			return filepath.Join(configDir, "systemd", "user")
+		}
+	}
+	return "/etc/systemd/system"
+}
+
+// unitPath returns the path of the unit supervising the container named containerName
+func (m *Manager) unitPath(containerName string) string {
+	return filepath.Join(m.unitDir(), unitName(containerName))
+}
+
+// hasUnit reports whether the manager generated a unit for the container named containerName
+func (m *Manager) hasUnit(containerName string) bool {
+	data, err := os.ReadFile(m.unitPath(containerName))
+	return err == nil && strings.HasPrefix(string(data), unitMarker)
+}
+
+// systemctl runs systemctl against the user or system instance of systemd
+func (m *Manager) systemctl(ctx context.Context, args ...string) ([]byte, error) {
+	if m.config.Container.SystemdUser {
+		args = append([]string{"--user"}, args...)
+	}
+	m.logger.DebugContext(ctx, "Running systemctl", slog.String("args", strings.Join(args, " ")))
+	return exec.CommandContext(ctx, "systemctl", args...).CombinedOutput()
+}
+
+// renderUnit returns the unit supervising container. It starts the created container attached, so
+// systemd follows its process and restarts it when it fails, and stops it without removing it, so
+// a stopped instance keeps the labels discovery reads. Quadlet units are not used because they
+// remove their container whenever the unit stops.
+func renderUnit(container *models.Container, podmanPath string, stopTimeout time.Duration) string {
+	seconds := int(stopTimeout.Seconds())
+	if seconds <= 0 {
+		seconds = 10
+	}
+	// systemd expands % specifiers in unit files
+	description := strings.ReplaceAll(container.ServiceName, "%", "%%")
+
+	var unit strings.Builder
+	fmt.Fprintf(&unit, "%s for %s\n", unitMarker, description)
+	unit.WriteString("[Unit]\n")
+	fmt.Fprintf(&unit, "Description=MCP server %s\n", description)
+	unit.WriteString("Wants=network-online.target\n")
+	unit.WriteString("After=network-online.target\n")
+	unit.WriteString("\n[Service]\n")
+	unit.WriteString("Environment=PODMAN_SYSTEMD_UNIT=%n\n")
+	unit.WriteString("Restart=on-failure\n")
+	fmt.Fprintf(&unit, "TimeoutStopSec=%d\n", seconds+10)
+	fmt.Fprintf(&unit, "ExecStart=%s start --attach %s\n", podmanPath, container.Name)
+	fmt.Fprintf(&unit, "ExecStop=%s stop -t %d %s\n", podmanPath, seconds, container.Name)
+	unit.WriteString("\n[Install]\nWantedBy=default.target\n")
+	return unit.String()
+}
+
+// supervisedArgs turns podman run -d arguments into podman create arguments, since the unit
+// starts the container
+func supervisedArgs(args []string) []string {
+	if len(args) < 2 || args[0] != "run" || args[1] != "-d" {
+		return args
+	}
+	return append([]string{"create"}, args[2:]...)
+}
+
+// installUnit writes the unit supervising container and enables it, starting it when start is set
+func (m *Manager) installUnit(ctx context.Context, container *models.Container, start bool) error {
+	podmanPath, err := exec.LookPath("podman")
+	if err != nil {
+		podmanPath = "/usr/bin/podman"
+	}
+	if err := os.MkdirAll(m.unitDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create unit directory: %w", err)
+	}
+	unit := renderUnit(container, podmanPath, m.config.Container.ShutdownTimeout)
+	if err := os.WriteFile(m.unitPath(container.Name), []byte(unit), 0644); err != nil {
+		return fmt.Errorf("failed to write unit: %w", err)
+	}
+	if output, err := m.systemctl(ctx, "daemon-reload"); err != nil {
+		return fmt.Errorf("failed to reload systemd: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	args := []string{"enable", unitName(container.Name)}
+	if start {
+		args = []string{"enable", "--now", unitName(container.Name)}
+	}
+	if output, err := m.systemctl(ctx, args...); err != nil {
+		return fmt.Errorf("failed to enable unit: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// removeUnit disables and deletes the unit of the container named containerName, if it has one
+func (m *Manager) removeUnit(ctx context.Context, containerName string) {
+	if !m.hasUnit(containerName) {
+		return
+	}
+	if output, err := m.systemctl(ctx, "disable", "--now", unitName(containerName)); err != nil {
+		m.logger.WarnContext(ctx, "Failed to disable unit",
+			slog.String("unit", unitName(containerName)),
+			slog.String("error", err.Error()),
+			slog.String("output", string(output)))
+	}
+	if err := os.Remove(m.unitPath(containerName)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		m.logger.WarnContext(ctx, "Failed to remove unit",
+			slog.String("unit", unitName(containerName)),
+			slog.String("error", err.Error()))
+		return
+	}
+	_, _ = m.systemctl(ctx, "daemon-reload")
+}
+
+// stopProcess stops container. A supervised container is stopped and disabled through its unit,
+// so systemd neither restarts it now nor starts it at boot.
+func (m *Manager) stopProcess(ctx context.Context, container *models.Container) ([]byte, error) {
+	if m.supervised() && m.hasUnit(container.Name) {
+		return m.systemctl(ctx, "disable", "--now", unitName(container.Name))
+	}
+	return podmanCommand(ctx, m.logger, "stop", container.ID).CombinedOutput()
+}
+
+// startProcess starts a stopped container, supervised by a unit when units are in use
+func (m *Manager) startProcess(ctx context.Context, container *models.Container) ([]byte, error) {
+	if m.supervised() {
+		return nil, m.installUnit(ctx, container, true)
+	}
+	return runPodman(ctx, m.logger, "start", container.ID)
+}
+
+// reconcileUnits brings systemd in line with the discovered containers: routed containers that were
+// not stopped by a user get an enabled, active unit, which starts those found stopped after a
+// reboot or a failure systemd gave up on, user-stopped containers keep theirs disabled, and units
+// of containers that no longer exist are removed. It returns the containers it started.
+func (m *Manager) reconcileUnits(ctx context.Context) []*models.Container {
+	var started []*models.Container
+	known := make(map[string]bool, len(m.containers))
+	for _, container := range m.containers {
+		known[unitName(container.Name)] = true
+		// A container kept for rollback has no route and is left as it is
+		if container.URL == "" {
+			continue
+		}
+		if container.StoppedAt != nil {
+			if m.hasUnit(container.Name) {
+				_, _ = m.systemctl(ctx, "disable", unitName(container.Name))
+			}
+			continue
+		}
+		wasRunning := container.Status == models.StatusRunning
+		if err := m.installUnit(ctx, container, true); err != nil {
+			m.logger.WarnContext(ctx, "Failed to supervise discovered container",
+				slog.String("service", container.ServiceName),
+				slog.String("error", err.Error()))
+			continue
+		}
+		if wasRunning {
+			continue
+		}
+		m.inspect.invalidate(container.ID)
+		if err := m.waitForContainer(ctx, container.ID); err != nil {
+			m.logger.WarnContext(ctx, "Supervised container did not start",
+				slog.String("service", container.ServiceName),
+				slog.String("error", err.Error()))
+			continue
+		}
+		container.Status = models.StatusRunning
+		started = append(started, container)
+		m.logger.InfoContext(ctx, "Started discovered container under its unit",
+			slog.String("service", container.ServiceName),
+			slog.String("unit", unitName(container.Name)))
+	}
+
+	entries, err := os.ReadDir(m.unitDir())
+	if err != nil {
+		return started
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, ".service") || known[name] {
+			continue
+		}
+		containerName := strings.TrimSuffix(name, ".service")
+		if !strings.HasPrefix(containerName, m.config.Container.NamePrefix) || !m.hasUnit(containerName) {
+			continue
+		}
+		if err := podmanCommand(ctx, m.logger, "container", "exists", containerName).Run(); err == nil {
+			// Archived, or owned by a create still in flight
+			continue
+		}
+		m.logger.InfoContext(ctx, "Removing unit of a container that no longer exists",
+			slog.String("unit", name))
+		m.removeUnit(ctx, containerName)
+	}
+	return started
+}
@@ -0,0 +1,56 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestSystemdUnits(t *testing.T) {
+	args := supervisedArgs([]string{"run", "-d", "--name", "mcp-github", "nginx"})
+	if strings.Join(args, " ") != "create --name mcp-github nginx" {
+		t.Errorf("Expected a podman create, got %v", args)
+	}
+
+	container := &models.Container{Name: "mcp-github", ServiceName: "github-100%"}
+	unit := renderUnit(container, "/usr/bin/podman", 30*time.Second)
+	for _, line := range []string{
+		unitMarker + " for github-100%%",
+		"ExecStart=/usr/bin/podman start --attach mcp-github",
+		"ExecStop=/usr/bin/podman stop -t 30 mcp-github",
+		"Restart=on-failure",
+		"WantedBy=default.target",
+	} {
+		if !strings.Contains(unit, line+"\n") {
+			t.Errorf("Expected the unit to contain %q, got:\n%s", line, unit)
+		}
+	}
+
+	cfg := &config.Config{}
+	cfg.Container.Supervisor = supervisorSystemd
+	cfg.Container.SystemdUnitDir = t.TempDir()
+	manager := &Manager{config: cfg}
+	if manager.unitPath("mcp-github") != filepath.Join(cfg.Container.SystemdUnitDir, "mcp-github.service") {
+		t.Errorf("Expected the unit in the configured directory, got %s", manager.unitPath("mcp-github"))
+	}
+	if manager.hasUnit("mcp-github") {
+		t.Error("Expected no unit before one is written")
+	}
+	if err := os.WriteFile(manager.unitPath("mcp-github"), []byte(unit), 0644); err != nil {
+		t.Fatalf("Failed to write unit: %v", err)
+	}
+	if !manager.hasUnit("mcp-github") {
+		t.Error("Expected a generated unit to be recognized")
+	}
+	if err := os.WriteFile(manager.unitPath("mcp-other"), []byte("[Unit]\n"), 0644); err != nil {
+		t.Fatalf("Failed to write unit: %v", err)
+	}
+	if manager.hasUnit("mcp-other") {
+		t.Error("Expected a unit the manager did not generate to be left alone")
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sync"
 
 	yaml "gopkg.in/yaml.v3"
 
 	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
 )
 
 // TraefikConfig represents the dynamic Traefik configuration
@@ -21,21 +23,75 @@ type TraefikHTTP struct {
 	Routers     map[string]TraefikRouter     `yaml:"routers"`
 	Services    map[string]TraefikService    `yaml:"services"`
 	Middlewares map[string]TraefikMiddleware `yaml:"middlewares"`
+
+	ServersTransports map[string]TraefikServersTransport `yaml:"serversTransports,omitempty"`
 }
 
 type TraefikRouter struct {
-	Rule        string   `yaml:"rule"`
-	Service     string   `yaml:"service"`
-	EntryPoints []string `yaml:"entryPoints"`
-	Middlewares []string `yaml:"middlewares,omitempty"`
+	Rule        string            `yaml:"rule"`
+	Service     string            `yaml:"service"`
+	EntryPoints []string          `yaml:"entryPoints"`
+	Middlewares []string          `yaml:"middlewares,omitempty"`
+	TLS         *TraefikRouterTLS `yaml:"tls,omitempty"`
+}
+
+type TraefikRouterTLS struct {
+	CertResolver string `yaml:"certResolver,omitempty"`
 }
 
 type TraefikService struct {
-	LoadBalancer TraefikLoadBalancer `yaml:"loadBalancer"`
+	LoadBalancer TraefikLoadBalancer `yaml:"loadBalancer,omitempty"`
+	Mirroring    *TraefikMirroring   `yaml:"mirroring,omitempty"`
+}
+
+type TraefikMirroring struct {
+	Service string          `yaml:"service"`
+	Mirrors []TraefikMirror `yaml:"mirrors"`
+}
+
+type TraefikMirror struct {
+	Name    string `yaml:"name"`
+	Percent int    `yaml:"percent"`
 }
 
 type TraefikLoadBalancer struct {
-	Servers []TraefikServer `yaml:"servers"`
+	Servers            []TraefikServer            `yaml:"servers"`
+	Sticky             *TraefikSticky             `yaml:"sticky,omitempty"`
+	ResponseForwarding *TraefikResponseForwarding `yaml:"responseForwarding,omitempty"`
+	ServersTransport   string                     `yaml:"serversTransport,omitempty"`
+}
+
+type TraefikSticky struct {
+	Cookie *TraefikStickyCookie `yaml:"cookie,omitempty"`
+}
+
+type TraefikStickyCookie struct {
+	Name     string `yaml:"name"`
+	HTTPOnly bool   `yaml:"httpOnly"`
+}
+
+type TraefikResponseForwarding struct {
+	FlushInterval string `yaml:"flushInterval"`
+}
+
+type TraefikServersTransport struct {
+	MaxIdleConnsPerHost int                        `yaml:"maxIdleConnsPerHost,omitempty"`
+	ForwardingTimeouts  *TraefikForwardingTimeouts `yaml:"forwardingTimeouts,omitempty"`
+
+	ServerName   string               `yaml:"serverName,omitempty"`
+	RootCAs      []string             `yaml:"rootCAs,omitempty"`
+	Certificates []TraefikCertificate `yaml:"certificates,omitempty"`
+}
+
+type TraefikCertificate struct {
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+}
+
+type TraefikForwardingTimeouts struct {
+	DialTimeout           string `yaml:"dialTimeout,omitempty"`
+	IdleConnTimeout       string `yaml:"idleConnTimeout,omitempty"`
+	ResponseHeaderTimeout string `yaml:"responseHeaderTimeout,omitempty"`
 }
 
 type TraefikServer struct {
@@ -44,6 +100,16 @@ type TraefikServer struct {
 
 type TraefikMiddleware struct {
 	StripPrefix *TraefikStripPrefix `yaml:"stripPrefix,omitempty"`
+	RateLimit   *TraefikRateLimit   `yaml:"rateLimit,omitempty"`
+	IPAllowList *TraefikIPAllowList `yaml:"ipAllowList,omitempty"`
+	Headers     *TraefikHeaders     `yaml:"headers,omitempty"`
+	Buffering   *TraefikBuffering   `yaml:"buffering,omitempty"`
+
+	CircuitBreaker *TraefikCircuitBreaker `yaml:"circuitBreaker,omitempty"`
+	Errors         *TraefikErrors         `yaml:"errors,omitempty"`
+	Retry          *TraefikRetry          `yaml:"retry,omitempty"`
+	ForwardAuth    *TraefikForwardAuth    `yaml:"forwardAuth,omitempty"`
+	InFlightReq    *TraefikInFlightReq    `yaml:"inFlightReq,omitempty"`
 }
 
 type TraefikStripPrefix struct {
@@ -51,55 +117,173 @@ type TraefikStripPrefix struct {
 	ForceSlash bool     `yaml:"forceSlash"`
 }
 
+type TraefikRateLimit struct {
+	Average int    `yaml:"average"`
+	Burst   int    `yaml:"burst,omitempty"`
+	Period  string `yaml:"period,omitempty"`
+}
+
+type TraefikIPAllowList struct {
+	SourceRange []string `yaml:"sourceRange"`
+}
+
+type TraefikHeaders struct {
+	CustomRequestHeaders  map[string]string `yaml:"customRequestHeaders,omitempty"`
+	CustomResponseHeaders map[string]string `yaml:"customResponseHeaders,omitempty"`
+}
+
+type TraefikBuffering struct {
+	MaxRequestBodyBytes  int64 `yaml:"maxRequestBodyBytes,omitempty"`
+	MaxResponseBodyBytes int64 `yaml:"maxResponseBodyBytes,omitempty"`
+}
+
+type TraefikCircuitBreaker struct {
+	Expression       string `yaml:"expression"`
+	FallbackDuration string `yaml:"fallbackDuration,omitempty"`
+	RecoveryDuration string `yaml:"recoveryDuration,omitempty"`
+}
+
+type TraefikErrors struct {
+	Status  []string `yaml:"status"`
+	Service string   `yaml:"service"`
+	Query   string   `yaml:"query"`
+}
+
+type TraefikForwardAuth struct {
+	Address string                 `yaml:"address"`
+	TLS     *TraefikForwardAuthTLS `yaml:"tls,omitempty"`
+}
+
+type TraefikForwardAuthTLS struct {
+	CA   string `yaml:"ca,omitempty"`
+	Cert string `yaml:"cert,omitempty"`
+	Key  string `yaml:"key,omitempty"`
+}
+
+type TraefikInFlightReq struct {
+	Amount          int                     `yaml:"amount"`
+	SourceCriterion *TraefikSourceCriterion `yaml:"sourceCriterion,omitempty"`
+}
+
+type TraefikSourceCriterion struct {
+	RequestHost bool `yaml:"requestHost,omitempty"`
+}
+
+type TraefikRetry struct {
+	Attempts        int    `yaml:"attempts"`
+	InitialInterval string `yaml:"initialInterval,omitempty"`
+}
+
 // TraefikManager manages Traefik configuration
 type TraefikManager struct {
+	mutex      sync.Mutex // Serializes read-modify-write cycles of the dynamic config
 	configPath string
 	logger     *slog.Logger
 	config     *config.Config
+	// replicas are the upstream URLs each scaled route balances to besides its own container
+	replicas map[string][]string
 }
 
 // NewTraefikManager creates a new Traefik manager
 func NewTraefikManager(cfg *config.Config, logger *slog.Logger) *TraefikManager {
+	configDir := cfg.Traefik.ConfigDir
+	if configDir == "" {
+		configDir = "/etc/traefik"
+	}
+
 	return &TraefikManager{
-		configPath: "/etc/traefik/dynamic.yml",
+		configPath: filepath.Join(configDir, "dynamic.yml"),
 		logger:     logger,
 		config:     cfg,
 	}
 }
 
-// AddMCPService adds a new MCP service route to Traefik
-func (tm *TraefikManager) AddMCPService(ctx context.Context, slug, containerIP string, containerPort int) error {
+// AddMCPService adds or replaces an MCP service route in Traefik, including its route middlewares.
+// A non-nil upstream reaches the container over mutual TLS.
+func (tm *TraefikManager) AddMCPService(ctx context.Context, slug, containerIP string, containerPort int, route *models.RouteConfig, routing *models.RoutingConfig, upstream *UpstreamTLS) error {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
 	config, err := tm.loadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Add router for the MCP service using slug
+	// Replace the route's middlewares so options removed from the spec do not linger
+	for _, suffix := range routeMiddlewareSuffixes {
+		delete(config.HTTP.Middlewares, routeMiddlewareName(slug, suffix))
+	}
+	breaker, _ := resolveCircuitBreaker(tm.config.Traefik.CircuitBreaker, route)
+	limits := resolveRouteLimits(tm.config.Traefik.Limits, route)
+	middlewares, chain := buildRouteMiddlewares(slug, route, breaker, limits, tm.config.Traefik.ManagerServiceURL)
+
+	// The error pages are served by the manager, which configs written elsewhere may lack
+	clientTLS := tm.managerClientTLS()
+	_, hasErrorPage := middlewares[limitErrorsMiddleware]
+	if _, exists := config.HTTP.Services[managerServiceName]; ((breaker != nil || hasErrorPage) && !exists) || clientTLS != nil {
+		tm.ensureManagerService(config)
+	}
+	for _, suffix := range []string{authMiddleware, sessionMiddleware, limitsMiddleware} {
+		if check := middlewares[suffix].ForwardAuth; check != nil && clientTLS != nil {
+			check.TLS = &TraefikForwardAuthTLS{CA: clientTLS.CAFile, Cert: clientTLS.CertFile, Key: clientTLS.KeyFile}
+		}
+	}
+	for suffix, middleware := range middlewares {
+		config.HTTP.Middlewares[routeMiddlewareName(slug, suffix)] = middleware
+	}
+
+	// Add router for the MCP service using slug, keeping traffic mirroring in place
+	routerService := fmt.Sprintf("mcp-%s-service", slug)
+	if _, mirrored := config.HTTP.Services[mirroringServiceName(slug)]; mirrored {
+		routerService = mirroringServiceName(slug)
+	}
 	routerName := fmt.Sprintf("mcp-%s", slug)
 	config.HTTP.Routers[routerName] = TraefikRouter{
 		Rule:        fmt.Sprintf("PathPrefix(`/mcp/%s`)", slug),
-		Service:     fmt.Sprintf("mcp-%s-service", slug),
+		Service:     routerService,
 		EntryPoints: []string{"web"},
-		Middlewares: []string{fmt.Sprintf("mcp-%s-stripprefix", slug)},
+		Middlewares: chain,
 	}
 
-	// Add service for the MCP service
-	serviceNameFull := fmt.Sprintf("mcp-%s-service", slug)
-	config.HTTP.Services[serviceNameFull] = TraefikService{
-		LoadBalancer: TraefikLoadBalancer{
-			Servers: []TraefikServer{
-				{URL: fmt.Sprintf("http://%s:%d", containerIP, containerPort)},
-			},
-		},
+	// Host routing serves the container at the root of its own hostname over TLS,
+	// so it shares every middleware except the path prefix strip
+	hostRouterName := fmt.Sprintf("mcp-%s-host", slug)
+	delete(config.HTTP.Routers, hostRouterName)
+	if hostname := routingHostname(routing); hostname != "" {
+		config.HTTP.Routers[hostRouterName] = TraefikRouter{
+			Rule:        fmt.Sprintf("Host(`%s`)", hostname),
+			Service:     routerService,
+			EntryPoints: []string{"websecure"},
+			Middlewares: chain[:len(chain)-1],
+			TLS:         &TraefikRouterTLS{CertResolver: tm.config.Traefik.CertResolver},
+		}
 	}
 
-	// Add middleware to strip prefix
-	middlewareName := fmt.Sprintf("mcp-%s-stripprefix", slug)
-	config.HTTP.Middlewares[middlewareName] = TraefikMiddleware{
-		StripPrefix: &TraefikStripPrefix{
-			Prefixes:   []string{fmt.Sprintf("/mcp/%s", slug)},
-			ForceSlash: false,
-		},
+	// Add service for the MCP service, with streaming and sticky session options
+	serviceNameFull := fmt.Sprintf("mcp-%s-service", slug)
+	service, transport := buildRouteService(slug, containerIP, containerPort, route, limits)
+	for _, url := range tm.replicas[slug] {
+		service.LoadBalancer.Servers = append(service.LoadBalancer.Servers, TraefikServer{URL: url})
+	}
+	if upstream != nil {
+		transport = applyUpstreamTLS(slug, &service, transport, upstream)
+	}
+	config.HTTP.Services[serviceNameFull] = service
+	delete(config.HTTP.ServersTransports, routeServersTransportName(slug))
+	if transport != nil {
+		// A route's own transport replaces the default one, so it carries the pool settings too
+		pool := tm.config.Traefik.Upstream
+		transport.MaxIdleConnsPerHost = pool.MaxIdleConnsPerHost
+		if pool.DialTimeout > 0 {
+			if transport.ForwardingTimeouts == nil {
+				transport.ForwardingTimeouts = &TraefikForwardingTimeouts{}
+			}
+			transport.ForwardingTimeouts.DialTimeout = pool.DialTimeout.String()
+		}
+		if config.HTTP.ServersTransports == nil {
+			config.HTTP.ServersTransports = make(map[string]TraefikServersTransport)
+		}
+		config.HTTP.ServersTransports[routeServersTransportName(slug)] = *transport
 	}
 
 	// Save updated configuration
@@ -107,41 +291,72 @@ func (tm *TraefikManager) AddMCPService(ctx context.Context, slug, containerIP s
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
-	tm.logger.Info("Added Traefik route for MCP service",
+	tm.logger.InfoContext(ctx, "Added Traefik route for MCP service",
 		slog.String("slug", slug),
 		slog.String("container_ip", containerIP),
-		slog.Int("port", containerPort))
+		slog.Int("port", containerPort),
+		slog.Int("middlewares", len(chain)))
 
 	return nil
 }
 
 // RemoveMCPService removes an MCP service route from Traefik
 func (tm *TraefikManager) RemoveMCPService(ctx context.Context, slug string) error {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
 	config, err := tm.loadConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Remove router, service, and middleware using slug
+	// Remove router, service, and middlewares using slug
 	routerName := fmt.Sprintf("mcp-%s", slug)
 	serviceNameFull := fmt.Sprintf("mcp-%s-service", slug)
-	middlewareName := fmt.Sprintf("mcp-%s-stripprefix", slug)
 
 	delete(config.HTTP.Routers, routerName)
+	delete(config.HTTP.Routers, fmt.Sprintf("mcp-%s-host", slug))
+	removeSessionRouters(config, slug)
+	delete(tm.replicas, slug)
+	// No route may keep mirroring to the removed service
+	clearMirrors(config, slug)
 	delete(config.HTTP.Services, serviceNameFull)
-	delete(config.HTTP.Middlewares, middlewareName)
+	delete(config.HTTP.ServersTransports, routeServersTransportName(slug))
+	for _, suffix := range routeMiddlewareSuffixes {
+		delete(config.HTTP.Middlewares, routeMiddlewareName(slug, suffix))
+	}
 
 	// Save updated configuration
 	if err := tm.saveConfig(config); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
-	tm.logger.Info("Removed Traefik route for MCP service",
+	tm.logger.InfoContext(ctx, "Removed Traefik route for MCP service",
 		slog.String("slug", slug))
 
 	return nil
 }
 
+// GetMCPServiceUpstream returns the upstream URL currently routed for a slug, or "" if there is no route
+func (tm *TraefikManager) GetMCPServiceUpstream(slug string) (string, error) {
+	config, err := tm.loadConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+
+	service, exists := config.HTTP.Services[fmt.Sprintf("mcp-%s-service", slug)]
+	if !exists || len(service.LoadBalancer.Servers) == 0 {
+		return "", nil
+	}
+
+	return service.LoadBalancer.Servers[0].URL, nil
+}
+
+// mcpUpstreamURL returns the URL Traefik proxies an MCP service's traffic to
+func mcpUpstreamURL(containerIP string, containerPort int) string {
+	return fmt.Sprintf("http://%s:%d", containerIP, containerPort)
+}
+
 // LoadConfig loads the current Traefik configuration
 func (tm *TraefikManager) LoadConfig() (*TraefikConfig, error) {
 	config := &TraefikConfig{
@@ -199,13 +414,40 @@ func (tm *TraefikManager) saveConfig(config *TraefikConfig) error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if err := os.WriteFile(tm.configPath, data, 0644); err != nil {
+	// Write to a temporary file and rename it so Traefik never watches a half-written config
+	tmpPath := tm.configPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
+	if err := os.Rename(tmpPath, tm.configPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace config file: %w", err)
+	}
 
 	return nil
 }
 
+// ensureManagerService adds the service routing to the manager API and, when the API requires client
+// certificates, the transport presenting the proxy's certificate
+func (tm *TraefikManager) ensureManagerService(config *TraefikConfig) {
+	service := TraefikService{
+		LoadBalancer: TraefikLoadBalancer{
+			Servers: []TraefikServer{{URL: tm.config.Traefik.ManagerServiceURL}},
+		},
+	}
+	if clientTLS := tm.managerClientTLS(); clientTLS != nil {
+		if config.HTTP.ServersTransports == nil {
+			config.HTTP.ServersTransports = make(map[string]TraefikServersTransport)
+		}
+		config.HTTP.ServersTransports[managerTransportName] = TraefikServersTransport{
+			RootCAs:      []string{clientTLS.CAFile},
+			Certificates: []TraefikCertificate{{CertFile: clientTLS.CertFile, KeyFile: clientTLS.KeyFile}},
+		}
+		service.LoadBalancer.ServersTransport = managerTransportName
+	}
+	config.HTTP.Services[managerServiceName] = service
+}
+
 // createDefaultConfig creates the default Traefik configuration
 func (tm *TraefikManager) createDefaultConfig() (*TraefikConfig, error) {
 	config := &TraefikConfig{
@@ -213,23 +455,23 @@ func (tm *TraefikManager) createDefaultConfig() (*TraefikConfig, error) {
 			Routers: map[string]TraefikRouter{
 				"mcp-manager-health": {
 					Rule:        "Path(`/health`)",
-					Service:     "mcp-manager-service",
+					Service:     managerServiceName,
 					EntryPoints: []string{"web"},
 				},
 				"mcp-manager-api": {
 					Rule:        "PathPrefix(`/api/mcp`)",
-					Service:     "mcp-manager-service",
+					Service:     managerServiceName,
 					EntryPoints: []string{"web"},
 					Middlewares: []string{"mcp-api-stripprefix"},
 				},
 				"mcp-manager-catchall": {
 					Rule:        "!PathPrefix(`/mcp/`) && !PathPrefix(`/api/mcp`)",
-					Service:     "mcp-manager-service",
+					Service:     managerServiceName,
 					EntryPoints: []string{"web"},
 				},
 			},
 			Services: map[string]TraefikService{
-				"mcp-manager-service": {
+				managerServiceName: {
 					LoadBalancer: TraefikLoadBalancer{
 						Servers: []TraefikServer{
 							{URL: tm.config.Traefik.ManagerServiceURL},
@@ -254,6 +496,8 @@ func (tm *TraefikManager) createDefaultConfig() (*TraefikConfig, error) {
 		},
 	}
 
+	tm.ensureManagerService(config)
+
 	if err := tm.saveConfig(config); err != nil {
 		return nil, fmt.Errorf("failed to save default config: %w", err)
 	}
@@ -0,0 +1,187 @@
+package container
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestTraefikUpstreamUpdatedInPlace(t *testing.T) {
+	cfg := &config.Config{
+		Traefik: config.TraefikConfig{
+			ManagerServiceURL: "http://localhost:8000",
+		},
+	}
+	logger := testLogger()
+
+	tm := NewTraefikManager(cfg, logger)
+	tm.configPath = filepath.Join(t.TempDir(), "dynamic.yml")
+
+	if upstream, err := tm.GetMCPServiceUpstream("svc-abc"); err != nil || upstream != "" {
+		t.Errorf("Expected no upstream before registration, got %q (%v)", upstream, err)
+	}
+
+	ctx := context.Background()
+	if err := tm.AddMCPService(ctx, "svc-abc", "10.88.0.5", 3000, nil, nil, nil); err != nil {
+		t.Fatalf("Failed to add route: %v", err)
+	}
+	if err := tm.AddMCPService(ctx, "svc-abc", "10.88.0.9", 3000, nil, nil, nil); err != nil {
+		t.Fatalf("Failed to update route: %v", err)
+	}
+
+	upstream, err := tm.GetMCPServiceUpstream("svc-abc")
+	if err != nil {
+		t.Fatalf("Failed to read upstream: %v", err)
+	}
+	if upstream != "http://10.88.0.9:3000" {
+		t.Errorf("Expected upstream http://10.88.0.9:3000, got %s", upstream)
+	}
+	if _, err := os.Stat(tm.configPath + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("Expected temporary config file to be renamed away")
+	}
+}
+
+func TestTraefikRouteMiddlewares(t *testing.T) {
+	cfg := &config.Config{
+		Traefik: config.TraefikConfig{
+			ManagerServiceURL: "http://localhost:8000",
+		},
+	}
+	logger := testLogger()
+
+	tm := NewTraefikManager(cfg, logger)
+	tm.configPath = filepath.Join(t.TempDir(), "dynamic.yml")
+
+	ctx := context.Background()
+	route := &models.RouteConfig{
+		RateLimit:   &models.RouteRateLimit{Average: 10, PeriodSeconds: 60},
+		IPAllowList: []string{"10.0.0.0/8"},
+	}
+	if err := tm.AddMCPService(ctx, "svc-abc", "10.88.0.5", 3000, route, nil, nil); err != nil {
+		t.Fatalf("Failed to add route: %v", err)
+	}
+
+	traefikConfig, err := tm.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	expected := []string{"mcp-svc-abc-ipallowlist", "mcp-svc-abc-ratelimit", "mcp-svc-abc-stripprefix"}
+	chain := traefikConfig.HTTP.Routers["mcp-svc-abc"].Middlewares
+	if len(chain) != len(expected) {
+		t.Fatalf("Expected middlewares %v, got %v", expected, chain)
+	}
+	for i := range expected {
+		if chain[i] != expected[i] {
+			t.Errorf("Expected middleware %s at position %d, got %s", expected[i], i, chain[i])
+		}
+	}
+	if rl := traefikConfig.HTTP.Middlewares["mcp-svc-abc-ratelimit"].RateLimit; rl == nil || rl.Period != "60s" {
+		t.Errorf("Expected rate limit with 60s period, got %+v", rl)
+	}
+
+	// Dropping options from the spec must remove their middlewares
+	if err := tm.AddMCPService(ctx, "svc-abc", "10.88.0.5", 3000, nil, nil, nil); err != nil {
+		t.Fatalf("Failed to update route: %v", err)
+	}
+	traefikConfig, _ = tm.LoadConfig()
+	if _, exists := traefikConfig.HTTP.Middlewares["mcp-svc-abc-ratelimit"]; exists {
+		t.Errorf("Expected rate limit middleware to be removed")
+	}
+
+	if err := tm.RemoveMCPService(ctx, "svc-abc"); err != nil {
+		t.Fatalf("Failed to remove route: %v", err)
+	}
+	traefikConfig, _ = tm.LoadConfig()
+	if _, exists := traefikConfig.HTTP.Middlewares["mcp-svc-abc-stripprefix"]; exists {
+		t.Errorf("Expected strip prefix middleware to be removed")
+	}
+}
+
+func TestStreamingRouteService(t *testing.T) {
+	route := &models.RouteConfig{Transport: models.TransportSSE, StickySessions: true}
+	if err := validateRouteConfig(route); err != nil {
+		t.Fatalf("Expected valid streaming route, got error: %v", err)
+	}
+
+	service, transport := buildRouteService("svc-abc", "10.88.0.5", 3000, route, nil)
+	if service.LoadBalancer.ResponseForwarding == nil || service.LoadBalancer.ResponseForwarding.FlushInterval != "-1" {
+		t.Errorf("Expected immediate flushing for SSE, got %+v", service.LoadBalancer.ResponseForwarding)
+	}
+	if service.LoadBalancer.Sticky == nil || service.LoadBalancer.Sticky.Cookie.Name != defaultStickyCookieName {
+		t.Errorf("Expected sticky cookie %s, got %+v", defaultStickyCookieName, service.LoadBalancer.Sticky)
+	}
+	if transport == nil || transport.ForwardingTimeouts.IdleConnTimeout != "3600s" {
+		t.Errorf("Expected 3600s idle timeout for SSE, got %+v", transport)
+	}
+
+	if _, transport := buildRouteService("svc-abc", "10.88.0.5", 3000, nil, nil); transport != nil {
+		t.Errorf("Expected default servers transport for plain HTTP routes, got %+v", transport)
+	}
+
+	buffered := &models.RouteConfig{Transport: models.TransportWebSocket, Buffering: &models.RouteBuffering{MaxRequestBodyBytes: 1024}}
+	if err := validateRouteConfig(buffered); err == nil {
+		t.Errorf("Expected error when buffering a WebSocket route")
+	}
+	if err := validateRouteConfig(&models.RouteConfig{Transport: "grpc"}); err == nil {
+		t.Errorf("Expected error for unknown transport")
+	}
+}
+
+func TestHostRouting(t *testing.T) {
+	if _, err := parseRoutingSpec(map[string]interface{}{
+		"routing": map[string]interface{}{"type": "host", "hostname": "Not_A_Host"},
+	}); err == nil {
+		t.Errorf("Expected error for invalid hostname")
+	}
+
+	routing, err := parseRoutingSpec(map[string]interface{}{
+		"routing": map[string]interface{}{"type": "host", "hostname": "github-mcp.example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Expected valid routing spec, got error: %v", err)
+	}
+
+	cfg := &config.Config{
+		Traefik: config.TraefikConfig{
+			ManagerServiceURL: "http://localhost:8000",
+			CertResolver:      "letsencrypt",
+		},
+	}
+	logger := testLogger()
+
+	tm := NewTraefikManager(cfg, logger)
+	tm.configPath = filepath.Join(t.TempDir(), "dynamic.yml")
+
+	route := &models.RouteConfig{RateLimit: &models.RouteRateLimit{Average: 10}}
+	if err := tm.AddMCPService(context.Background(), "svc-abc", "10.88.0.5", 3000, route, routing, nil); err != nil {
+		t.Fatalf("Failed to add route: %v", err)
+	}
+
+	traefikConfig, err := tm.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if _, exists := traefikConfig.HTTP.Routers["mcp-svc-abc"]; !exists {
+		t.Errorf("Expected path router to be kept alongside host routing")
+	}
+	hostRouter, exists := traefikConfig.HTTP.Routers["mcp-svc-abc-host"]
+	if !exists {
+		t.Fatalf("Expected host router to be created")
+	}
+	if hostRouter.Rule != "Host(`github-mcp.example.com`)" {
+		t.Errorf("Expected host rule, got %s", hostRouter.Rule)
+	}
+	if hostRouter.TLS == nil || hostRouter.TLS.CertResolver != "letsencrypt" {
+		t.Errorf("Expected TLS with cert resolver letsencrypt, got %+v", hostRouter.TLS)
+	}
+	if len(hostRouter.Middlewares) != 1 || hostRouter.Middlewares[0] != "mcp-svc-abc-ratelimit" {
+		t.Errorf("Expected host router to share middlewares without prefix stripping, got %v", hostRouter.Middlewares)
+	}
+	if url := hostRoutedURL(routing); url != "https://github-mcp.example.com" {
+		t.Errorf("Expected host URL https://github-mcp.example.com, got %s", url)
+	}
+}
@@ -0,0 +1,423 @@
+package container
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// trafficBucket holds cumulative traffic counters, keyed by service name
+	trafficBucket = "traffic"
+	// trafficOffsetBucket records how far the access log was read, keyed by its path
+	trafficOffsetBucket = "traffic_offset"
+	// trafficPollInterval is how often the access log is checked for new lines
+	trafficPollInterval = time.Second
+	// trafficSaveInterval bounds how often counters are persisted
+	trafficSaveInterval = 30 * time.Second
+)
+
+// ErrTrafficNotCollected is returned when the proxy access log is not written in a form the manager reads
+var ErrTrafficNotCollected = errors.New("proxy traffic is not collected, enable TRAEFIK_ACCESS_LOG with TRAEFIK_ACCESS_LOG_FORMAT=json and TRAEFIK_ACCESS_LOG_PATH")
+
+// trafficState holds the traffic counters and which of them changed since the last save
+type trafficState struct {
+	mutex sync.Mutex
+	stats map[string]*models.TrafficStats
+	dirty map[string]bool
+}
+
+// traefikAccessEntry is the subset of a Traefik JSON access log line traffic accounting needs
+type traefikAccessEntry struct {
+	ServiceName      string `json:"ServiceName"`
+	DownstreamStatus int    `json:"DownstreamStatus"`
+	// Duration is in nanoseconds
+	Duration              int64     `json:"Duration"`
+	DownstreamContentSize int64     `json:"DownstreamContentSize"`
+	RequestContentSize    int64     `json:"RequestContentSize"`
+	StartUTC              time.Time `json:"StartUTC"`
+}
+
+// trafficOwner is the instance a route slug belongs to
+type trafficOwner struct {
+	serviceName string
+	workspaceID string
+}
+
+// trafficCollected reports whether Traefik writes a JSON access log file the manager can follow
+func (m *Manager) trafficCollected() bool {
+	accessLog := m.config.Traefik.AccessLog
+	return accessLog.Enabled && accessLog.Format == "json" && accessLog.FilePath != ""
+}
+
+// startTrafficCollector follows the proxy access log and counts requests per instance
+func (m *Manager) startTrafficCollector() {
+	if !m.trafficCollected() {
+		if m.config.Traefik.AccessLog.Enabled {
+			m.logger.Info("Proxy traffic accounting needs a JSON access log file, traffic is not collected",
+				slog.String("format", m.config.Traefik.AccessLog.Format),
+				slog.String("file_path", m.config.Traefik.AccessLog.FilePath))
+		}
+		return
+	}
+
+	m.loadTrafficStats()
+
+	tail := &accessLogTail{path: m.config.Traefik.AccessLog.FilePath}
+	if _, err := m.store.Get(trafficOffsetBucket, tail.path, &tail.offset); err != nil {
+		m.logger.Warn("Failed to read access log offset",
+			slog.String("error", err.Error()))
+	}
+	defer tail.close()
+	defer m.saveTraffic(tail)
+
+	pollTicker := time.NewTicker(trafficPollInterval)
+	defer pollTicker.Stop()
+	saveTicker := time.NewTicker(trafficSaveInterval)
+	defer saveTicker.Stop()
+
+	for {
+		select {
+		case <-m.healthCtx.Done():
+			return
+		case <-saveTicker.C:
+			m.saveTraffic(tail)
+		case <-pollTicker.C:
+			owners := m.trafficOwners()
+			err := tail.read(func(line []byte) {
+				m.recordAccess(line, owners)
+			})
+			if err != nil {
+				m.logger.Debug("Failed to read proxy access log",
+					slog.String("path", tail.path),
+					slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+// trafficOwners maps the route slug of every managed container to its instance
+func (m *Manager) trafficOwners() map[string]trafficOwner {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	owners := make(map[string]trafficOwner, len(m.containers))
+	for _, container := range m.containers {
+		if container.Slug != "" {
+			owners[container.Slug] = trafficOwner{serviceName: container.ServiceName, workspaceID: container.WorkspaceID}
+		}
+	}
+	return owners
+}
+
+// recordAccess adds one access log line to the counters of the instance its route belongs to
+func (m *Manager) recordAccess(line []byte, owners map[string]trafficOwner) {
+	var entry traefikAccessEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return
+	}
+	owner, exists := owners[traefikServiceSlug(entry.ServiceName)]
+	if !exists {
+		return
+	}
+
+	m.traffic.mutex.Lock()
+	defer m.traffic.mutex.Unlock()
+
+	stats := m.trafficStatsUnsafe(owner.serviceName)
+	stats.WorkspaceID = owner.workspaceID
+	stats.Requests++
+	stats.StatusCodes[strconv.Itoa(entry.DownstreamStatus)]++
+	if entry.DownstreamStatus >= 500 {
+		stats.Errors++
+	}
+	stats.BytesIn += uint64(max(entry.RequestContentSize, 0))
+	stats.BytesOut += uint64(max(entry.DownstreamContentSize, 0))
+
+	latency := float64(entry.Duration) / float64(time.Millisecond)
+	stats.TotalLatencyMs += latency
+	stats.MaxLatencyMs = max(stats.MaxLatencyMs, latency)
+
+	at := entry.StartUTC
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if stats.FirstRequestAt == nil {
+		stats.FirstRequestAt = &at
+	}
+	stats.LastRequestAt = &at
+
+	m.traffic.dirty[owner.serviceName] = true
+	m.recordSLORequest(owner.serviceName, at, entry.DownstreamStatus >= 500)
+}
+
+// trafficStatsUnsafe returns the counters of an instance, creating them on first use.
+// Caller must hold m.traffic.mutex.
+func (m *Manager) trafficStatsUnsafe(serviceName string) *models.TrafficStats {
+	if m.traffic.stats == nil {
+		m.traffic.stats = make(map[string]*models.TrafficStats)
+		m.traffic.dirty = make(map[string]bool)
+	}
+	stats, exists := m.traffic.stats[serviceName]
+	if !exists {
+		stats = &models.TrafficStats{ServiceName: serviceName, StatusCodes: make(map[string]uint64)}
+		m.traffic.stats[serviceName] = stats
+	}
+	return stats
+}
+
+// traefikServiceSlug returns the route slug of a Traefik service name such as "mcp-github-ab12-service@file",
+// or "mcp-github-ab12-mirroring@file" while the route's traffic is mirrored
+func traefikServiceSlug(name string) string {
+	name, _, _ = strings.Cut(name, "@")
+	if !strings.HasPrefix(name, "mcp-") {
+		return ""
+	}
+	for _, suffix := range []string{"-service", "-mirroring"} {
+		if slug, found := strings.CutSuffix(strings.TrimPrefix(name, "mcp-"), suffix); found {
+			return slug
+		}
+	}
+	return ""
+}
+
+// loadTrafficStats restores the counters persisted by a previous run
+func (m *Manager) loadTrafficStats() {
+	records, err := m.store.List(trafficBucket)
+	if err != nil {
+		m.logger.Warn("Failed to load traffic counters",
+			slog.String("error", err.Error()))
+		return
+	}
+
+	m.traffic.mutex.Lock()
+	defer m.traffic.mutex.Unlock()
+
+	for serviceName, raw := range records {
+		stats := m.trafficStatsUnsafe(serviceName)
+		if err := json.Unmarshal(raw, stats); err != nil {
+			delete(m.traffic.stats, serviceName)
+			continue
+		}
+		if stats.StatusCodes == nil {
+			stats.StatusCodes = make(map[string]uint64)
+		}
+	}
+}
+
+// saveTraffic persists changed counters and how far the access log has been read
+func (m *Manager) saveTraffic(tail *accessLogTail) {
+	m.traffic.mutex.Lock()
+	changed := make([]models.TrafficStats, 0, len(m.traffic.dirty))
+	for serviceName := range m.traffic.dirty {
+		changed = append(changed, copyTrafficStats(m.traffic.stats[serviceName]))
+	}
+	clear(m.traffic.dirty)
+	m.traffic.mutex.Unlock()
+
+	for _, stats := range changed {
+		if err := m.store.Put(trafficBucket, stats.ServiceName, stats); err != nil {
+			m.logger.Warn("Failed to save traffic counters",
+				slog.String("service", stats.ServiceName),
+				slog.String("error", err.Error()))
+		}
+	}
+	if err := m.store.Put(trafficOffsetBucket, tail.path, tail.committed()); err != nil {
+		m.logger.Warn("Failed to save access log offset",
+			slog.String("error", err.Error()))
+	}
+}
+
+// copyTrafficStats returns a copy of the counters with the average latency filled in
+func copyTrafficStats(stats *models.TrafficStats) models.TrafficStats {
+	result := *stats
+	result.StatusCodes = maps.Clone(stats.StatusCodes)
+	if result.Requests > 0 {
+		result.AverageLatencyMs = result.TotalLatencyMs / float64(result.Requests)
+	}
+	return result
+}
+
+// GetTraffic returns the proxied traffic counters of a container
+func (m *Manager) GetTraffic(serviceName string) (*models.TrafficStats, error) {
+	if !m.trafficCollected() {
+		return nil, ErrTrafficNotCollected
+	}
+
+	m.traffic.mutex.Lock()
+	stats, exists := m.traffic.stats[serviceName]
+	var result models.TrafficStats
+	if exists {
+		result = copyTrafficStats(stats)
+	}
+	m.traffic.mutex.Unlock()
+	if exists {
+		return &result, nil
+	}
+
+	m.mutex.RLock()
+	container, tracked := m.containers[serviceName]
+	if tracked {
+		result = models.TrafficStats{
+			ServiceName: serviceName,
+			WorkspaceID: container.WorkspaceID,
+			StatusCodes: map[string]uint64{},
+		}
+	}
+	m.mutex.RUnlock()
+	if !tracked {
+		return nil, fmt.Errorf("container %s not found", serviceName)
+	}
+	return &result, nil
+}
+
+// GetTrafficUsage returns the traffic of every instance, including deleted ones so usage can
+// still be metered, and totals per workspace. A workspace ID limits the report to that workspace.
+func (m *Manager) GetTrafficUsage(workspaceID string) (*models.TrafficUsage, error) {
+	if !m.trafficCollected() {
+		return nil, ErrTrafficNotCollected
+	}
+
+	usage := &models.TrafficUsage{
+		Instances:  []models.TrafficStats{},
+		Workspaces: []models.WorkspaceTraffic{},
+		Timestamp:  time.Now(),
+	}
+	workspaces := make(map[string]*models.WorkspaceTraffic)
+
+	m.traffic.mutex.Lock()
+	for _, stats := range m.traffic.stats {
+		if workspaceID != "" && stats.WorkspaceID != workspaceID {
+			continue
+		}
+		usage.Instances = append(usage.Instances, copyTrafficStats(stats))
+
+		if stats.WorkspaceID == "" {
+			continue
+		}
+		workspace, exists := workspaces[stats.WorkspaceID]
+		if !exists {
+			workspace = &models.WorkspaceTraffic{WorkspaceID: stats.WorkspaceID}
+			workspaces[stats.WorkspaceID] = workspace
+		}
+		workspace.Instances++
+		workspace.Requests += stats.Requests
+		workspace.Errors += stats.Errors
+		workspace.BytesIn += stats.BytesIn
+		workspace.BytesOut += stats.BytesOut
+	}
+	m.traffic.mutex.Unlock()
+
+	sort.Slice(usage.Instances, func(i, j int) bool {
+		return usage.Instances[i].ServiceName < usage.Instances[j].ServiceName
+	})
+	for _, workspace := range workspaces {
+		usage.Workspaces = append(usage.Workspaces, *workspace)
+	}
+	sort.Slice(usage.Workspaces, func(i, j int) bool {
+		return usage.Workspaces[i].WorkspaceID < usage.Workspaces[j].WorkspaceID
+	})
+	return usage, nil
+}
+
+// accessLogTail follows a log file across truncation and rotation
+type accessLogTail struct {
+	path   string
+	file   *os.File
+	info   os.FileInfo
+	offset int64
+	// partial holds a trailing line that has not been completely written yet
+	partial []byte
+}
+
+// read calls fn for each complete line appended since the last read. When the file was rotated,
+// the rest of the old file is read before following the new one from its start.
+func (t *accessLogTail) read(fn func([]byte)) error {
+	info, err := os.Stat(t.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if t.file != nil && (!os.SameFile(t.info, info) || info.Size() < t.committed()) {
+		if !os.SameFile(t.info, info) {
+			_ = t.drain(fn)
+		}
+		t.close()
+		t.offset = 0
+	}
+
+	if t.file == nil {
+		file, err := os.Open(t.path)
+		if err != nil {
+			return err
+		}
+		// A stored offset past the end means the file was replaced while the manager was down
+		if t.offset > info.Size() {
+			t.offset = 0
+		}
+		if _, err := file.Seek(t.offset, io.SeekStart); err != nil {
+			file.Close()
+			return err
+		}
+		t.file = file
+		t.info = info
+		t.partial = nil
+	}
+
+	return t.drain(fn)
+}
+
+// drain reads the open file to its end
+func (t *accessLogTail) drain(fn func([]byte)) error {
+	buf := make([]byte, 64<<10)
+	for {
+		n, err := t.file.Read(buf)
+		if n > 0 {
+			t.offset += int64(n)
+			t.partial = append(t.partial, buf[:n]...)
+			for {
+				newline := bytes.IndexByte(t.partial, '\n')
+				if newline < 0 {
+					break
+				}
+				fn(t.partial[:newline])
+				t.partial = t.partial[newline+1:]
+			}
+			t.partial = bytes.Clone(t.partial)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// committed returns the offset just past the last complete line
+func (t *accessLogTail) committed() int64 {
+	return t.offset - int64(len(t.partial))
+}
+
+// close closes the followed file
+func (t *accessLogTail) close() {
+	if t.file != nil {
+		t.file.Close()
+		t.file = nil
+	}
+}
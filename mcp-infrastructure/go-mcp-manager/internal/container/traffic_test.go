@@ -0,0 +1,63 @@
+package container
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestTrafficAccounting(t *testing.T) {
+	if slug := traefikServiceSlug("mcp-github-ab12-service@file"); slug != "github-ab12" {
+		t.Errorf("Expected slug github-ab12, got %q", slug)
+	}
+	if slug := traefikServiceSlug("api@internal"); slug != "" {
+		t.Errorf("Expected no slug for an internal service, got %q", slug)
+	}
+
+	logPath := filepath.Join(t.TempDir(), "access.log")
+	cfg := &config.Config{Traefik: config.TraefikConfig{AccessLog: config.TraefikAccessLogConfig{
+		Enabled:  true,
+		Format:   "json",
+		FilePath: logPath,
+	}}}
+	manager := newTestManager(cfg)
+	manager.containers["github"] = &models.Container{ServiceName: "github", Slug: "github-ab12", WorkspaceID: "ws-1"}
+
+	lines := `{"ServiceName":"mcp-github-ab12-service@file","DownstreamStatus":200,"Duration":20000000,"RequestContentSize":100,"DownstreamContentSize":400}
+{"ServiceName":"mcp-github-ab12-service@file","DownstreamStatus":502,"Duration":40000000,"RequestContentSize":50,"DownstreamContentSize":10}
+{"ServiceName":"mcp-other-service@file","DownstreamStatus":200}
+{"ServiceName":"mcp-github-ab12-service@file","DownstreamStatus":200`
+	if err := os.WriteFile(logPath, []byte(lines), 0644); err != nil {
+		t.Fatalf("Failed to write access log: %v", err)
+	}
+
+	tail := &accessLogTail{path: logPath}
+	defer tail.close()
+	owners := manager.trafficOwners()
+	if err := tail.read(func(line []byte) { manager.recordAccess(line, owners) }); err != nil {
+		t.Fatalf("Expected access log to be read, got %v", err)
+	}
+
+	stats, err := manager.GetTraffic("github")
+	if err != nil {
+		t.Fatalf("Expected traffic for github, got %v", err)
+	}
+	if stats.Requests != 2 || stats.Errors != 1 || stats.StatusCodes["200"] != 1 {
+		t.Errorf("Expected 2 requests with one 502, got %+v", stats)
+	}
+	if stats.BytesIn != 150 || stats.BytesOut != 410 || stats.AverageLatencyMs != 30 || stats.MaxLatencyMs != 40 {
+		t.Errorf("Expected bytes and latency to be summed, got %+v", stats)
+	}
+	if tail.committed() != int64(strings.LastIndex(lines, "\n")+1) {
+		t.Errorf("Expected the incomplete last line to be left for the next read, got offset %d", tail.committed())
+	}
+
+	usage, err := manager.GetTrafficUsage("ws-1")
+	if err != nil || len(usage.Workspaces) != 1 || usage.Workspaces[0].Requests != 2 {
+		t.Errorf("Expected workspace ws-1 to total 2 requests, got %+v, %v", usage, err)
+	}
+}
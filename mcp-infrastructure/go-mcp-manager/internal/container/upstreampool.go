@@ -0,0 +1,133 @@
+package container
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// upstreamPool is the connection pool the manager uses to reach MCP containers. It counts dials and
+// open connections so pool churn, which exhausts ephemeral ports, is visible.
+type upstreamPool struct {
+	transport *http.Transport
+	settings  config.UpstreamPoolConfig
+
+	requests   atomic.Uint64
+	dials      atomic.Uint64
+	dialErrors atomic.Uint64
+
+	mutex  sync.Mutex
+	open   map[string]int
+	total  int
+	peak   int
+	closed uint64
+}
+
+// newUpstreamPool creates a pool with the configured idle limits, timeouts and TCP keep-alive
+func newUpstreamPool(settings config.UpstreamPoolConfig) *upstreamPool {
+	pool := &upstreamPool{
+		settings: settings,
+		open:     make(map[string]int),
+	}
+
+	dialer := &net.Dialer{Timeout: settings.DialTimeout, KeepAlive: settings.KeepAlive}
+	pool.transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return pool.dial(ctx, dialer, network, address)
+		},
+		MaxIdleConnsPerHost: settings.MaxIdleConnsPerHost,
+		IdleConnTimeout:     settings.IdleConnTimeout,
+	}
+	return pool
+}
+
+// RoundTrip counts the request and sends it through the pooled transport
+func (p *upstreamPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	p.requests.Add(1)
+	return p.transport.RoundTrip(req)
+}
+
+// dial opens a connection and tracks it until it is closed
+func (p *upstreamPool) dial(ctx context.Context, dialer *net.Dialer, network, address string) (net.Conn, error) {
+	p.dials.Add(1)
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		p.dialErrors.Add(1)
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	p.open[address]++
+	p.total++
+	p.peak = max(p.peak, p.total)
+	p.mutex.Unlock()
+
+	return &trackedConn{Conn: conn, release: func() {
+		p.mutex.Lock()
+		p.open[address]--
+		if p.open[address] <= 0 {
+			delete(p.open, address)
+		}
+		p.total--
+		p.closed++
+		p.mutex.Unlock()
+	}}, nil
+}
+
+// stats reports the pool's connections and how well they are reused
+func (p *upstreamPool) stats() models.ConnectionPoolStats {
+	stats := models.ConnectionPoolStats{
+		MaxIdleConnsPerHost: p.settings.MaxIdleConnsPerHost,
+		IdleConnTimeout:     p.settings.IdleConnTimeout.String(),
+		Requests:            p.requests.Load(),
+		Dials:               p.dials.Load(),
+		DialErrors:          p.dialErrors.Load(),
+		Timestamp:           time.Now(),
+	}
+	if stats.Requests > 0 {
+		stats.ReuseRatio = max(0, 1-float64(stats.Dials)/float64(stats.Requests))
+	}
+
+	p.mutex.Lock()
+	stats.OpenConns = p.total
+	stats.PeakOpenConns = p.peak
+	stats.ClosedConns = p.closed
+	busiest := 0
+	for _, open := range p.open {
+		busiest = max(busiest, open)
+		if p.settings.MaxIdleConnsPerHost > 0 && open > p.settings.MaxIdleConnsPerHost {
+			stats.HostsOverIdleLimit++
+		}
+	}
+	p.mutex.Unlock()
+
+	// Above 1 some connections to the busiest container cannot be kept idle and are closed after use
+	if p.settings.MaxIdleConnsPerHost > 0 {
+		stats.Saturation = float64(busiest) / float64(p.settings.MaxIdleConnsPerHost)
+	}
+	return stats
+}
+
+// trackedConn calls release once when the connection is closed
+type trackedConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+// Close closes the connection and releases its slot in the pool statistics
+func (c *trackedConn) Close() error {
+	c.once.Do(c.release)
+	return c.Conn.Close()
+}
+
+// UpstreamPoolStats reports the manager's connection pool to MCP containers
+func (m *Manager) UpstreamPoolStats() models.ConnectionPoolStats {
+	return m.upstreamPool.stats()
+}
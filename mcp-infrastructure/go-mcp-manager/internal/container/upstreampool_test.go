@@ -0,0 +1,48 @@
+package container
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+)
+
+func TestUpstreamPool(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	pool := newUpstreamPool(config.UpstreamPoolConfig{
+		MaxIdleConnsPerHost: 2,
+		IdleConnTimeout:     time.Minute,
+		DialTimeout:         time.Second,
+		KeepAlive:           15 * time.Second,
+	})
+	client := &http.Client{Transport: pool}
+
+	for i := 0; i < 5; i++ {
+		resp, err := client.Get(upstream.URL)
+		if err != nil {
+			t.Fatalf("Expected request to succeed, got %v", err)
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	stats := pool.stats()
+	if stats.Requests != 5 || stats.Dials != 1 {
+		t.Errorf("Expected 5 requests over 1 connection, got %d over %d", stats.Requests, stats.Dials)
+	}
+	if stats.ReuseRatio != 0.8 || stats.OpenConns != 1 || stats.Saturation != 0.5 {
+		t.Errorf("Expected reuse 0.8, 1 open connection and saturation 0.5, got %+v", stats)
+	}
+
+	pool.transport.CloseIdleConnections()
+	if stats := pool.stats(); stats.OpenConns != 0 || stats.ClosedConns != 1 || stats.PeakOpenConns != 1 {
+		t.Errorf("Expected the idle connection to be released, got %+v", stats)
+	}
+}
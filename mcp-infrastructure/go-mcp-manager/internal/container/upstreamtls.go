@@ -0,0 +1,213 @@
+package container
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/internal/mtls"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const (
+	// upstreamTLSLabel marks containers that serve the proxy over mutual TLS
+	upstreamTLSLabel = "mcp.upstream_tls"
+	// upstreamTLSMountPath is where a container finds its certificate, key and the CA of its clients
+	upstreamTLSMountPath = "/etc/mcp-tls"
+	// managerClientDir holds the certificate the proxy presents to the manager API
+	managerClientDir = "manager-client"
+)
+
+// UpstreamTLS locates the files the proxy reaches a container with over mutual TLS
+type UpstreamTLS struct {
+	// ServerName is the name in the container's certificate, which is reached by IP
+	ServerName string
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+}
+
+// initPKI loads the internal CA when mutual TLS is configured. The manager presents its own client
+// certificate to containers, and the proxy gets one for its callbacks to the manager API.
+func (m *Manager) initPKI() {
+	settings := m.config.MTLS
+	if !settings.Upstream && !settings.APIEnabled() {
+		return
+	}
+
+	authority, err := mtls.LoadOrCreateAuthority(settings.Dir)
+	if err != nil {
+		m.pkiErr = err
+		m.logger.Error("Failed to load the internal CA, containers cannot be created with mutual TLS",
+			slog.String("dir", settings.Dir),
+			slog.String("error", err.Error()))
+		return
+	}
+
+	if settings.Upstream {
+		issued, err := authority.Issue(mtls.Request{CommonName: "mcp-manager"})
+		var certificate tls.Certificate
+		if err == nil {
+			certificate, err = issued.TLSCertificate()
+		}
+		if err != nil {
+			m.pkiErr = err
+			m.logger.Error("Failed to issue the manager's client certificate", slog.String("error", err.Error()))
+			return
+		}
+		m.upstreamPool.transport.TLSClientConfig = mtls.ClientConfig(certificate, authority)
+	}
+
+	if settings.APIEnabled() {
+		if err := authority.IssueTo(filepath.Join(settings.Dir, managerClientDir), mtls.Request{CommonName: "mcp-proxy"}); err != nil {
+			m.logger.Error("Failed to issue the proxy's client certificate for the manager API",
+				slog.String("error", err.Error()))
+		}
+	}
+
+	m.pki = authority
+}
+
+// provisionUpstreamTLS issues a container's server certificate and the client certificate the
+// proxy presents to it; containers not marked for mutual TLS are left alone
+func (m *Manager) provisionUpstreamTLS(container *models.Container) error {
+	if !container.UpstreamTLS {
+		return nil
+	}
+	if m.pki == nil {
+		return fmt.Errorf("upstream mutual TLS is enabled but the internal CA is unavailable: %v", m.pkiErr)
+	}
+
+	if err := m.pki.IssueTo(m.containerTLSDir(container.Name), mtls.Request{
+		CommonName: container.Name,
+		DNSNames:   []string{container.Name},
+		Server:     true,
+	}); err != nil {
+		return fmt.Errorf("failed to issue certificate for %s: %w", container.Name, err)
+	}
+	if err := m.pki.IssueTo(m.proxyTLSDir(container.Name), mtls.Request{CommonName: "mcp-proxy"}); err != nil {
+		return fmt.Errorf("failed to issue proxy certificate for %s: %w", container.Name, err)
+	}
+	return nil
+}
+
+// upstreamTLSArgs mounts a container's certificates and tells it where they are
+func (m *Manager) upstreamTLSArgs(container *models.Container) []string {
+	if !container.UpstreamTLS {
+		return nil
+	}
+	// U hands the files to the container's user, as the key is readable by its owner only
+	args := []string{"--volume", fmt.Sprintf("%s:%s:ro,U", m.containerTLSDir(container.Name), upstreamTLSMountPath)}
+	environment := upstreamTLSEnvironment(container)
+	for _, name := range slices.Sorted(maps.Keys(environment)) {
+		args = append(args, "-e", name+"="+environment[name])
+	}
+	return append(args, "--label", upstreamTLSLabel+"=true")
+}
+
+// upstreamTLSEnvironment returns the variables pointing a container at its mounted certificates
+func upstreamTLSEnvironment(container *models.Container) map[string]string {
+	if !container.UpstreamTLS {
+		return nil
+	}
+	return map[string]string{
+		"MCP_TLS_CERT_FILE":      upstreamTLSMountPath + "/" + mtls.CertFile,
+		"MCP_TLS_KEY_FILE":       upstreamTLSMountPath + "/" + mtls.KeyFile,
+		"MCP_TLS_CLIENT_CA_FILE": upstreamTLSMountPath + "/" + mtls.CAFile,
+	}
+}
+
+// upstreamTLS returns the files the proxy uses to reach container, or nil for plain HTTP
+func (m *Manager) upstreamTLS(container *models.Container) *UpstreamTLS {
+	if !container.UpstreamTLS {
+		return nil
+	}
+	dir := m.proxyTLSDir(container.Name)
+	return &UpstreamTLS{
+		ServerName: container.Name,
+		CAFile:     filepath.Join(dir, mtls.CAFile),
+		CertFile:   filepath.Join(dir, mtls.CertFile),
+		KeyFile:    filepath.Join(dir, mtls.KeyFile),
+	}
+}
+
+// removeUpstreamTLS deletes the certificates issued for a deleted container
+func (m *Manager) removeUpstreamTLS(ctx context.Context, container *models.Container) {
+	if !container.UpstreamTLS {
+		return
+	}
+	for _, dir := range []string{m.containerTLSDir(container.Name), m.proxyTLSDir(container.Name)} {
+		if err := os.RemoveAll(dir); err != nil {
+			m.logger.WarnContext(ctx, "Failed to remove container certificates",
+				slog.String("dir", dir),
+				slog.String("error", err.Error()))
+		}
+	}
+}
+
+// upstreamScheme returns the scheme the manager and the proxy reach container with
+func upstreamScheme(container *models.Container) string {
+	if container.UpstreamTLS {
+		return "https"
+	}
+	return "http"
+}
+
+// containerUpstreamURL returns the address the proxy forwards a container's requests to
+func containerUpstreamURL(container *models.Container, containerIP string) string {
+	return upstreamURL(container, containerIP, container.Port)
+}
+
+// upstreamURL returns the address the proxy forwards a container's requests to when they reach
+// it at upstreamHost and upstreamPort, such as a port published on loopback
+func upstreamURL(container *models.Container, upstreamHost string, upstreamPort int) string {
+	return upstreamScheme(container) + "://" + strings.TrimPrefix(mcpUpstreamURL(upstreamHost, upstreamPort), "http://")
+}
+
+// containerTLSDir holds the certificate mounted into a container
+func (m *Manager) containerTLSDir(containerName string) string {
+	return filepath.Join(m.config.MTLS.Dir, "containers", containerName)
+}
+
+// proxyTLSDir holds the client certificate the proxy presents to a container
+func (m *Manager) proxyTLSDir(containerName string) string {
+	return filepath.Join(m.config.MTLS.Dir, "proxy", containerName)
+}
+
+// managerClientTLS returns the files the proxy presents to the manager API with, or nil when the
+// API does not require client certificates. The API's own certificate is expected to be issued by
+// its client CA.
+func (tm *TraefikManager) managerClientTLS() *UpstreamTLS {
+	settings := tm.config.MTLS
+	if !settings.APIEnabled() {
+		return nil
+	}
+	dir := filepath.Join(settings.Dir, managerClientDir)
+	return &UpstreamTLS{
+		CAFile:   settings.ClientCAFile,
+		CertFile: filepath.Join(dir, mtls.CertFile),
+		KeyFile:  filepath.Join(dir, mtls.KeyFile),
+	}
+}
+
+// applyUpstreamTLS points a route's service at the container over HTTPS, presenting the proxy's
+// client certificate and trusting only the internal CA
+func applyUpstreamTLS(slug string, service *TraefikService, transport *TraefikServersTransport, upstream *UpstreamTLS) *TraefikServersTransport {
+	for i, server := range service.LoadBalancer.Servers {
+		service.LoadBalancer.Servers[i].URL = "https://" + strings.TrimPrefix(server.URL, "http://")
+	}
+	if transport == nil {
+		transport = &TraefikServersTransport{}
+		service.LoadBalancer.ServersTransport = routeServersTransportName(slug)
+	}
+	transport.ServerName = upstream.ServerName
+	transport.RootCAs = []string{upstream.CAFile}
+	transport.Certificates = []TraefikCertificate{{CertFile: upstream.CertFile, KeyFile: upstream.KeyFile}}
+	return transport
+}
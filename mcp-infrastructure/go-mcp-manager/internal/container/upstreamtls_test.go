@@ -0,0 +1,70 @@
+package container
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestUpstreamTLS(t *testing.T) {
+	cfg := &config.Config{MTLS: config.MTLSConfig{Upstream: true, Dir: t.TempDir()}}
+	logger := testLogger()
+	manager := newTestManager(cfg)
+	if manager.pki == nil {
+		t.Fatalf("Expected the internal CA to be created, got %v", manager.pkiErr)
+	}
+	if manager.upstreamPool.transport.TLSClientConfig == nil {
+		t.Error("Expected the manager to reach containers with a client certificate")
+	}
+
+	container := &models.Container{Name: "mcp-github", Port: 3000, UpstreamTLS: true}
+	if err := manager.provisionUpstreamTLS(container); err != nil {
+		t.Fatalf("Expected certificates to be issued, got %v", err)
+	}
+	upstream := manager.upstreamTLS(container)
+	for _, path := range []string{upstream.CAFile, upstream.CertFile, upstream.KeyFile, filepath.Join(manager.containerTLSDir(container.Name), "tls.key")} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to exist, got %v", path, err)
+		}
+	}
+
+	args := strings.Join(manager.upstreamTLSArgs(container), " ")
+	if !strings.Contains(args, manager.containerTLSDir(container.Name)+":/etc/mcp-tls:ro,U") || !strings.Contains(args, "MCP_TLS_CERT_FILE=/etc/mcp-tls/tls.crt") {
+		t.Errorf("Expected the certificates to be mounted, got %s", args)
+	}
+	if containerUpstreamURL(container, "10.88.0.5") != "https://10.88.0.5:3000" {
+		t.Errorf("Expected an https upstream, got %s", containerUpstreamURL(container, "10.88.0.5"))
+	}
+
+	tm := NewTraefikManager(cfg, logger)
+	tm.configPath = filepath.Join(t.TempDir(), "dynamic.yml")
+	if err := tm.AddMCPService(context.Background(), "github-ab12", "10.88.0.5", 3000, nil, nil, upstream); err != nil {
+		t.Fatalf("Failed to add route: %v", err)
+	}
+	if url, err := tm.GetMCPServiceUpstream("github-ab12"); err != nil || url != "https://10.88.0.5:3000" {
+		t.Errorf("Expected the proxy to reach the container over https, got %q (%v)", url, err)
+	}
+	traefikConfig, err := tm.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	transport, exists := traefikConfig.HTTP.ServersTransports[routeServersTransportName("github-ab12")]
+	if !exists || transport.ServerName != "mcp-github" || len(transport.Certificates) != 1 || transport.Certificates[0].CertFile != upstream.CertFile {
+		t.Errorf("Expected a transport presenting the proxy certificate, got %+v", transport)
+	}
+
+	manager.removeUpstreamTLS(context.Background(), container)
+	if _, err := os.Stat(manager.containerTLSDir(container.Name)); !os.IsNotExist(err) {
+		t.Errorf("Expected the container certificates to be removed")
+	}
+
+	plain := &models.Container{Name: "mcp-slack", Port: 3000}
+	if manager.upstreamTLS(plain) != nil || manager.upstreamTLSArgs(plain) != nil {
+		t.Error("Expected containers created without mutual TLS to be reached over plain HTTP")
+	}
+}
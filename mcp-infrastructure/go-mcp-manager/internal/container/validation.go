@@ -1,14 +1,15 @@
 package container
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"os/exec"
 	"strings"
 
-	"github.com/agentarea/mcp-manager/internal/models"
+	"github.com/agentarea/mcp-manager/internal/retry"
+	"github.com/agentarea/mcp-manager/pkg/models"
 )
 
 // ValidationResult represents the result of container validation
@@ -19,6 +20,9 @@ type ValidationResult struct {
 	ImageExists   bool     `json:"image_exists"`
 	CanPull       bool     `json:"can_pull"`
 	EstimatedSize string   `json:"estimated_size,omitempty"`
+	// Platform is the image variant the container will run, and Emulated whether it runs under qemu
+	Platform string `json:"platform,omitempty"`
+	Emulated bool   `json:"emulated,omitempty"`
 }
 
 // ContainerValidator handles container validation and dry-run checks
@@ -37,7 +41,7 @@ func NewContainerValidator(logger *slog.Logger, manager *Manager) *ContainerVali
 
 // ValidateContainerImage validates that a container image exists and can be used
 func (v *ContainerValidator) ValidateContainerImage(ctx context.Context, imageName string, allowPull bool) (*ValidationResult, error) {
-	v.logger.Info("Validating container image",
+	v.logger.InfoContext(ctx, "Validating container image",
 		slog.String("image", imageName),
 		slog.Bool("allow_pull", allowPull))
 
@@ -58,7 +62,7 @@ func (v *ContainerValidator) ValidateContainerImage(ctx context.Context, imageNa
 	result.ImageExists = exists
 
 	if !exists {
-		v.logger.Info("Image not found locally, checking if it can be pulled",
+		v.logger.InfoContext(ctx, "Image not found locally, checking if it can be pulled",
 			slog.String("image", imageName))
 
 		if allowPull {
@@ -82,11 +86,24 @@ func (v *ContainerValidator) ValidateContainerImage(ctx context.Context, imageNa
 		}
 	}
 
+	// Choose the variant to run, rejecting images without one for the host
+	if v.manager != nil && (exists || result.CanPull) {
+		choice, err := v.manager.resolvePlatform(ctx, imageName)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			result.Valid = false
+		} else if choice.Emulated {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Image %s has no %s variant and will run degraded under qemu emulation as %s", imageName, hostPlatform(), choice.Platform))
+		}
+		result.Platform = choice.Platform
+		result.Emulated = choice.Emulated
+	}
+
 	// Get image info if it exists
 	if exists {
 		size, err := v.getImageSize(ctx, imageName)
 		if err != nil {
-			v.logger.Warn("Failed to get image size", slog.String("error", err.Error()))
+			v.logger.WarnContext(ctx, "Failed to get image size", slog.String("error", err.Error()))
 		} else {
 			result.EstimatedSize = size
 		}
@@ -97,7 +114,7 @@ func (v *ContainerValidator) ValidateContainerImage(ctx context.Context, imageNa
 
 // imageExistsLocally checks if an image exists in the local registry
 func (v *ContainerValidator) imageExistsLocally(ctx context.Context, imageName string) (bool, error) {
-	cmd := exec.CommandContext(ctx, "podman", "image", "exists", imageName)
+	cmd := podmanCommand(ctx, v.logger, "image", "exists", imageName)
 	err := cmd.Run()
 	return err == nil, nil
 }
@@ -105,7 +122,7 @@ func (v *ContainerValidator) imageExistsLocally(ctx context.Context, imageName s
 // canPullImage checks if an image can be pulled from a registry
 func (v *ContainerValidator) canPullImage(ctx context.Context, imageName string) (bool, error) {
 	// Use podman search to check if image is available in registries
-	cmd := exec.CommandContext(ctx, "podman", "search", "--limit", "1", imageName)
+	cmd := podmanCommand(ctx, v.logger, "search", "--limit", "1", imageName)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return false, nil // If search fails, assume image cannot be pulled
@@ -129,7 +146,7 @@ func (v *ContainerValidator) canPullImage(ctx context.Context, imageName string)
 
 // getImageSize gets the size of a local image
 func (v *ContainerValidator) getImageSize(ctx context.Context, imageName string) (string, error) {
-	cmd := exec.CommandContext(ctx, "podman", "image", "inspect", imageName, "--format", "{{.Size}}")
+	cmd := podmanCommand(ctx, v.logger, "image", "inspect", imageName, "--format", "{{.Size}}")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", err
@@ -147,7 +164,7 @@ func (v *ContainerValidator) formatSize(sizeStr string) string {
 
 // DryRunValidation performs comprehensive dry-run validation
 func (v *ContainerValidator) DryRunValidation(ctx context.Context, instance *models.MCPServerInstance) (*ValidationResult, error) {
-	v.logger.Info("Performing dry-run validation",
+	v.logger.InfoContext(ctx, "Performing dry-run validation",
 		slog.String("instance_id", instance.InstanceID),
 		slog.String("name", instance.Name))
 
@@ -165,29 +182,37 @@ func (v *ContainerValidator) DryRunValidation(ctx context.Context, instance *mod
 
 	// Extract image from json_spec
 	image, ok := instance.JSONSpec["image"].(string)
-	if !ok || image == "" {
+	_, hasSource := instance.JSONSpec["source"]
+	_, hasRuntime := instance.JSONSpec["runtime"]
+	if (hasSource || hasRuntime) && image == "" {
+		result.Warnings = append(result.Warnings, "Image is resolved from source or runtime when the instance is created")
+	} else if !ok || image == "" {
 		result.Errors = append(result.Errors, "Missing or invalid image in json_spec")
 		result.Valid = false
 		return result, nil
 	}
 
-	// Validate container image
-	imageValidation, err := v.ValidateContainerImage(ctx, image, true)
-	if err != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("Image validation failed: %v", err))
-		result.Valid = false
-		return result, nil
-	}
+	// Validate container image unless it is yet to be built
+	if image != "" {
+		imageValidation, err := v.ValidateContainerImage(ctx, image, true)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("Image validation failed: %v", err))
+			result.Valid = false
+			return result, nil
+		}
 
-	// Merge image validation results
-	result.ImageExists = imageValidation.ImageExists
-	result.CanPull = imageValidation.CanPull
-	result.EstimatedSize = imageValidation.EstimatedSize
-	result.Errors = append(result.Errors, imageValidation.Errors...)
-	result.Warnings = append(result.Warnings, imageValidation.Warnings...)
+		// Merge image validation results
+		result.ImageExists = imageValidation.ImageExists
+		result.CanPull = imageValidation.CanPull
+		result.EstimatedSize = imageValidation.EstimatedSize
+		result.Platform = imageValidation.Platform
+		result.Emulated = imageValidation.Emulated
+		result.Errors = append(result.Errors, imageValidation.Errors...)
+		result.Warnings = append(result.Warnings, imageValidation.Warnings...)
 
-	if !imageValidation.Valid {
-		result.Valid = false
+		if !imageValidation.Valid {
+			result.Valid = false
+		}
 	}
 
 	// Check container limits
@@ -217,7 +242,7 @@ func (v *ContainerValidator) DryRunValidation(ctx context.Context, instance *mod
 		}
 	}
 
-	v.logger.Info("Dry-run validation completed",
+	v.logger.InfoContext(ctx, "Dry-run validation completed",
 		slog.String("instance_id", instance.InstanceID),
 		slog.Bool("valid", result.Valid),
 		slog.Int("errors", len(result.Errors)),
@@ -228,7 +253,7 @@ func (v *ContainerValidator) DryRunValidation(ctx context.Context, instance *mod
 
 // DryRunValidationWithLimits performs comprehensive dry-run validation with explicit limits (deadlock-safe)
 func (v *ContainerValidator) DryRunValidationWithLimits(ctx context.Context, instance *models.MCPServerInstance, currentRunningCount int, maxContainers int) (*ValidationResult, error) {
-	v.logger.Info("Performing dry-run validation with limits",
+	v.logger.InfoContext(ctx, "Performing dry-run validation with limits",
 		slog.String("instance_id", instance.InstanceID),
 		slog.String("name", instance.Name),
 		slog.Int("current_running", currentRunningCount),
@@ -248,29 +273,37 @@ func (v *ContainerValidator) DryRunValidationWithLimits(ctx context.Context, ins
 
 	// Extract image from json_spec
 	image, ok := instance.JSONSpec["image"].(string)
-	if !ok || image == "" {
+	_, hasSource := instance.JSONSpec["source"]
+	_, hasRuntime := instance.JSONSpec["runtime"]
+	if (hasSource || hasRuntime) && image == "" {
+		result.Warnings = append(result.Warnings, "Image is resolved from source or runtime when the instance is created")
+	} else if !ok || image == "" {
 		result.Errors = append(result.Errors, "Missing or invalid image in json_spec")
 		result.Valid = false
 		return result, nil
 	}
 
-	// Validate container image
-	imageValidation, err := v.ValidateContainerImage(ctx, image, true)
-	if err != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("Image validation failed: %v", err))
-		result.Valid = false
-		return result, nil
-	}
+	// Validate container image unless it is yet to be built
+	if image != "" {
+		imageValidation, err := v.ValidateContainerImage(ctx, image, true)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("Image validation failed: %v", err))
+			result.Valid = false
+			return result, nil
+		}
 
-	// Merge image validation results
-	result.ImageExists = imageValidation.ImageExists
-	result.CanPull = imageValidation.CanPull
-	result.EstimatedSize = imageValidation.EstimatedSize
-	result.Errors = append(result.Errors, imageValidation.Errors...)
-	result.Warnings = append(result.Warnings, imageValidation.Warnings...)
+		// Merge image validation results
+		result.ImageExists = imageValidation.ImageExists
+		result.CanPull = imageValidation.CanPull
+		result.EstimatedSize = imageValidation.EstimatedSize
+		result.Platform = imageValidation.Platform
+		result.Emulated = imageValidation.Emulated
+		result.Errors = append(result.Errors, imageValidation.Errors...)
+		result.Warnings = append(result.Warnings, imageValidation.Warnings...)
 
-	if !imageValidation.Valid {
-		result.Valid = false
+		if !imageValidation.Valid {
+			result.Valid = false
+		}
 	}
 
 	// Check container limits using provided values (no manager callbacks)
@@ -291,11 +324,11 @@ func (v *ContainerValidator) DryRunValidationWithLimits(ctx context.Context, ins
 		containerName := v.manager.config.GetContainerName(instance.Name)
 		// Note: We skip the container existence check here to avoid mutex deadlock
 		// This will be checked in the manager after acquiring the lock
-		v.logger.Debug("Skipping container name conflict check during validation to avoid deadlock",
+		v.logger.DebugContext(ctx, "Skipping container name conflict check during validation to avoid deadlock",
 			slog.String("container_name", containerName))
 	}
 
-	v.logger.Info("Dry-run validation with limits completed",
+	v.logger.InfoContext(ctx, "Dry-run validation with limits completed",
 		slog.String("instance_id", instance.InstanceID),
 		slog.Bool("valid", result.Valid),
 		slog.Int("errors", len(result.Errors)),
@@ -306,7 +339,20 @@ func (v *ContainerValidator) DryRunValidationWithLimits(ctx context.Context, ins
 
 // validateJSONSpec validates the structure of json_spec
 func (v *ContainerValidator) validateJSONSpec(jsonSpec map[string]interface{}) error {
-	required := []string{"image", "port"}
+	// A source or runtime stands in for the image until it is built or translated
+	source, err := parseSourceSpec(jsonSpec)
+	if err != nil {
+		return err
+	}
+	runtime, err := parseRuntimeSpec(jsonSpec)
+	if err != nil {
+		return err
+	}
+
+	required := []string{"port"}
+	if source == nil && runtime == nil {
+		required = append(required, "image")
+	}
 	for _, field := range required {
 		if _, exists := jsonSpec[field]; !exists {
 			return fmt.Errorf("required field %s is missing", field)
@@ -314,7 +360,7 @@ func (v *ContainerValidator) validateJSONSpec(jsonSpec map[string]interface{}) e
 	}
 
 	// Validate image field
-	if image, ok := jsonSpec["image"].(string); !ok || image == "" {
+	if image, ok := jsonSpec["image"].(string); source == nil && runtime == nil && (!ok || image == "") {
 		return fmt.Errorf("image field must be a non-empty string")
 	}
 
@@ -346,55 +392,145 @@ func (v *ContainerValidator) validateJSONSpec(jsonSpec map[string]interface{}) e
 		}
 	}
 
+	// Validate health check configuration if present
+	if _, err := parseHealthCheckSpec(jsonSpec); err != nil {
+		return err
+	}
+
+	// Validate route middleware configuration if present
+	if _, err := parseRouteSpec(jsonSpec); err != nil {
+		return err
+	}
+
+	// Validate host/path routing if present
+	if _, err := parseRoutingSpec(jsonSpec); err != nil {
+		return err
+	}
+
+	// Validate GPU and device passthrough if present
+	if _, _, err := parseGPUSpec(jsonSpec); err != nil {
+		return err
+	}
+
+	// Validate security overrides if present
+	if _, err := parseSecuritySpec(jsonSpec); err != nil {
+		return err
+	}
+
+	// Validate workspace network settings if present
+	if _, _, err := parseNetworkSpec(jsonSpec); err != nil {
+		return err
+	}
+
+	// Validate tmpfs and scratch volume mounts if present
+	if _, _, err := parseScratchSpec(jsonSpec); err != nil {
+		return err
+	}
+
+	// Validate projected files if present
+	if _, err := parseFilesSpec(jsonSpec); err != nil {
+		return err
+	}
+
+	// Validate dependencies if present
+	if _, err := parseDependsOnSpec(jsonSpec); err != nil {
+		return err
+	}
+
+	// Validate the init command if present
+	if _, err := parseInitSpec(jsonSpec); err != nil {
+		return err
+	}
+
+	// Validate the log shipping override if present
+	if _, err := parseLogShippingSpec(jsonSpec); err != nil {
+		return err
+	}
+
+	// Validate the start/stop schedule if present
+	if _, err := parseScheduleSpec(jsonSpec); err != nil {
+		return err
+	}
+
+	// Validate the priority class if present
+	if _, err := parsePrioritySpec(jsonSpec); err != nil {
+		return err
+	}
+
+	// Validate the replica bounds and targets if present
+	if _, err := parseScalingSpec(jsonSpec); err != nil {
+		return err
+	}
+
+	// Validate the environment against its schema if present
+	if err := validateEnvSchemaSpec(jsonSpec); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // validateResourceRequirements validates resource requirements
 func (v *ContainerValidator) validateResourceRequirements(jsonSpec map[string]interface{}) error {
-	resources, exists := jsonSpec["resources"]
-	if !exists {
-		return nil // Resources are optional
+	limits, err := parseResourcesSpec(jsonSpec)
+	if err != nil || v.manager == nil {
+		return err
 	}
+	return v.manager.config.Container.ValidateResources(limits)
+}
 
-	resourceMap, ok := resources.(map[string]interface{})
-	if !ok {
-		return fmt.Errorf("resources must be an object")
+// PullImageWithProgress pulls an image with progress tracking. A platform such as "linux/amd64"
+// pulls that variant for emulation; empty pulls the host's.
+func (v *ContainerValidator) PullImageWithProgress(ctx context.Context, imageName, platform string, progressCallback func(string)) error {
+	v.logger.InfoContext(ctx, "Pulling image with progress tracking",
+		slog.String("image", imageName))
+
+	if err := v.manager.chaos.DelayPull(ctx, imageName); err != nil {
+		return fmt.Errorf("failed to pull image: %w", err)
 	}
 
-	// Validate memory limit
-	if memLimit, exists := resourceMap["memory_limit"]; exists {
-		if _, ok := memLimit.(string); !ok {
-			return fmt.Errorf("memory_limit must be a string")
-		}
-		// Could add more validation for memory format (e.g., "256m", "1g")
+	if v.manager.pullThroughMirror(ctx, imageName, platform, progressCallback) {
+		v.logger.InfoContext(ctx, "Image pulled through registry mirror",
+			slog.String("image", imageName))
+		return nil
 	}
 
-	// Validate CPU limit
-	if cpuLimit, exists := resourceMap["cpu_limit"]; exists {
-		if _, ok := cpuLimit.(string); !ok {
-			return fmt.Errorf("cpu_limit must be a string")
-		}
-		// Could add more validation for CPU format (e.g., "0.5", "1.0")
+	// A registry hiccup mid-pull is retried; podman resumes from the layers it already has
+	err := retry.For(retry.Podman).Do(ctx, func(ctx context.Context) error {
+		return v.pullImage(ctx, pullArgs(imageName, platform), progressCallback)
+	})
+	if err != nil {
+		return err
 	}
 
+	v.logger.InfoContext(ctx, "Image pulled successfully",
+		slog.String("image", imageName))
+
 	return nil
 }
 
-// PullImageWithProgress pulls an image with progress tracking
-func (v *ContainerValidator) PullImageWithProgress(ctx context.Context, imageName string, progressCallback func(string)) error {
-	v.logger.Info("Pulling image with progress tracking",
-		slog.String("image", imageName))
+// pullArgs returns the podman pull arguments for imageName, of platform when one is set
+func pullArgs(imageName, platform string) []string {
+	if platform != "" {
+		return []string{"pull", "--platform", platform, imageName}
+	}
+	return []string{"pull", imageName}
+}
 
-	cmd := exec.CommandContext(ctx, "podman", "pull", imageName)
+// pullImage makes a single pull attempt with args, streaming podman's progress to progressCallback
+func (v *ContainerValidator) pullImage(ctx context.Context, args []string, progressCallback func(string)) error {
+	cmd := podmanCommand(ctx, v.logger, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
 
 	// Create a pipe to capture output
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		return fmt.Errorf("failed to create stdout pipe: %w", err)
+		return retry.Permanent(fmt.Errorf("failed to create stdout pipe: %w", err))
 	}
 
 	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start pull command: %w", err)
+		return retry.Permanent(fmt.Errorf("failed to start pull command: %w", err))
 	}
 
 	// Read progress in a goroutine
@@ -412,18 +548,14 @@ func (v *ContainerValidator) PullImageWithProgress(ctx context.Context, imageNam
 	}()
 
 	if err := cmd.Wait(); err != nil {
-		return fmt.Errorf("failed to pull image: %w", err)
+		return podmanRetryable(fmt.Errorf("failed to pull image: %w", err), stderr.Bytes())
 	}
-
-	v.logger.Info("Image pulled successfully",
-		slog.String("image", imageName))
-
 	return nil
 }
 
 // GetContainerStatus gets detailed container status
 func (v *ContainerValidator) GetContainerStatus(ctx context.Context, containerID string) (*models.DetailedContainerStatus, error) {
-	cmd := exec.CommandContext(ctx, "podman", "inspect", containerID, "--format", "json")
+	cmd := podmanCommand(ctx, v.logger, "inspect", containerID, "--format", "json")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return nil, fmt.Errorf("failed to inspect container: %w", err)
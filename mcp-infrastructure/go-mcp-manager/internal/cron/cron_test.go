@@ -0,0 +1,66 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	valid := []string{"* * * * *", "0 9 * * 1-5", "*/15 8-18 * * *", "0 0 1,15 * 7", "30 6 * 1-12/3 0"}
+	for _, expr := range valid {
+		if _, err := Parse(expr); err != nil {
+			t.Errorf("Expected %q to parse, got %v", expr, err)
+		}
+	}
+
+	invalid := []string{"", "0 9 * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "*/15 8-18 * * mon"}
+	for _, expr := range invalid {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Expected %q to be rejected", expr)
+		}
+	}
+}
+
+func TestPrevAndNext(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("Time zone data unavailable: %v", err)
+	}
+	weekdays, _ := Parse("0 9 * * 1-5")
+
+	// Monday 2024-06-03 08:30 in Berlin: the last weekday 09:00 was Friday
+	monday := time.Date(2024, 6, 3, 8, 30, 0, 0, berlin)
+	prev, ok := weekdays.Prev(monday)
+	if expected := time.Date(2024, 5, 31, 9, 0, 0, 0, berlin); !ok || !prev.Equal(expected) {
+		t.Errorf("Expected previous firing %v, got %v", expected, prev)
+	}
+	next, ok := weekdays.Next(monday)
+	if expected := time.Date(2024, 6, 3, 9, 0, 0, 0, berlin); !ok || !next.Equal(expected) {
+		t.Errorf("Expected next firing %v, got %v", expected, next)
+	}
+
+	// A firing in the current minute counts as the previous one
+	atNine := time.Date(2024, 6, 3, 9, 0, 40, 0, berlin)
+	if prev, _ := weekdays.Prev(atNine); !prev.Equal(time.Date(2024, 6, 3, 9, 0, 0, 0, berlin)) {
+		t.Errorf("Expected the current minute to match, got %v", prev)
+	}
+
+	// Restricted day of month and day of week match either way
+	either, _ := Parse("0 0 1 * 0")
+	if !either.Matches(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)) || !either.Matches(time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Error("Expected the 1st and a Sunday to both match")
+	}
+
+	if _, ok := mustParse(t, "0 0 31 2 *").Next(monday); ok {
+		t.Error("Expected February 31st never to fire")
+	}
+}
+
+func mustParse(t *testing.T, expr string) *Expression {
+	t.Helper()
+	e, err := Parse(expr)
+	if err != nil {
+		t.Fatalf("Failed to parse %q: %v", expr, err)
+	}
+	return e
+}
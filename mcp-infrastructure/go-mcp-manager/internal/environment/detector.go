@@ -14,6 +14,8 @@ type Environment string
 const (
 	EnvironmentDocker     Environment = "docker"
 	EnvironmentKubernetes Environment = "kubernetes"
+	// EnvironmentFake is never detected, only forced for development without a container runtime
+	EnvironmentFake Environment = "fake"
 )
 
 // Detector handles environment detection logic
@@ -144,6 +146,9 @@ func (d *Detector) ForceEnvironment(env string) Environment {
 	case "docker", "podman":
 		d.logger.Info("Forced Docker environment via configuration")
 		return EnvironmentDocker
+	case "fake":
+		d.logger.Warn("Forced fake environment via configuration: instances are simulated in memory")
+		return EnvironmentFake
 	default:
 		d.logger.Warn("Invalid forced environment, falling back to auto-detection",
 			slog.String("forced_env", env))
@@ -0,0 +1,77 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	schema "github.com/agentarea/mcp-manager/pkg/events"
+)
+
+// errLegacyRejected is returned for unversioned events once legacy events are no longer accepted
+var errLegacyRejected = errors.New("unversioned events are no longer accepted, publish schema_version 1")
+
+// decodeInstanceCreated decodes a create request of any known schema version
+func decodeInstanceCreated(eventData schema.EventData, acceptLegacy bool) (*schema.MCPServerInstanceCreated, error) {
+	if eventData.SchemaVersion == schema.SchemaVersionLegacy {
+		if !acceptLegacy {
+			return nil, errLegacyRejected
+		}
+		return legacyInstanceCreated(eventData.Data)
+	}
+	var created schema.MCPServerInstanceCreated
+	if err := schema.DecodeData(eventData, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// decodeInstanceDeleted decodes a delete request of any known schema version
+func decodeInstanceDeleted(eventData schema.EventData, acceptLegacy bool) (*schema.MCPServerInstanceDeleted, error) {
+	if eventData.SchemaVersion == schema.SchemaVersionLegacy {
+		if !acceptLegacy {
+			return nil, errLegacyRejected
+		}
+		deleted := &schema.MCPServerInstanceDeleted{
+			InstanceID: legacyString(eventData.Data["instance_id"]),
+			Name:       legacyString(eventData.Data["name"]),
+		}
+		return deleted, deleted.Validate()
+	}
+	var deleted schema.MCPServerInstanceDeleted
+	if err := schema.DecodeData(eventData, &deleted); err != nil {
+		return nil, err
+	}
+	return &deleted, nil
+}
+
+// legacyInstanceCreated is the compatibility shim for create requests published before
+// versioning: fields of the wrong type are dropped rather than failing the event, and json_spec
+// may be missing or a JSON-encoded string
+func legacyInstanceCreated(data map[string]any) (*schema.MCPServerInstanceCreated, error) {
+	created := &schema.MCPServerInstanceCreated{
+		InstanceID:   legacyString(data["instance_id"]),
+		Name:         legacyString(data["name"]),
+		ServerSpecID: legacyString(data["server_spec_id"]),
+		WorkspaceID:  legacyString(data["workspace_id"]),
+	}
+
+	switch spec := data["json_spec"].(type) {
+	case map[string]any:
+		created.JSONSpec = spec
+	case string:
+		if err := json.Unmarshal([]byte(spec), &created.JSONSpec); err != nil {
+			return nil, fmt.Errorf("json_spec is not a JSON object: %w", err)
+		}
+	}
+	if created.JSONSpec == nil {
+		created.JSONSpec = map[string]any{}
+	}
+	return created, created.Validate()
+}
+
+// legacyString returns value when it is a string, and empty otherwise
+func legacyString(value any) string {
+	s, _ := value.(string)
+	return s
+}
@@ -0,0 +1,95 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"slices"
+	"testing"
+
+	schema "github.com/agentarea/mcp-manager/pkg/events"
+)
+
+func TestDecodeInstanceCreated(t *testing.T) {
+	v1 := schema.EventData{
+		EventID:       "evt-1",
+		EventType:     schema.ChannelInstanceCreated,
+		SchemaVersion: schema.SchemaVersion1,
+		Data: map[string]any{
+			"instance_id":  "inst-1",
+			"name":         "github",
+			"workspace_id": "ws-1",
+			"json_spec":    map[string]any{"image": "ghcr.io/github/github-mcp-server"},
+			"added_later":  true,
+		},
+	}
+	created, err := decodeInstanceCreated(v1, false)
+	if err != nil || created.InstanceID != "inst-1" || created.WorkspaceID != "ws-1" || created.JSONSpec["image"] == nil {
+		t.Errorf("Expected the v1 event to decode, got %+v, %v", created, err)
+	}
+
+	wrongType := v1
+	wrongType.Data = map[string]any{"instance_id": 42, "name": "github", "json_spec": map[string]any{}}
+	if _, err := decodeInstanceCreated(wrongType, true); err == nil {
+		t.Error("Expected a v1 event with a mistyped field to be rejected")
+	}
+	missing := v1
+	missing.Data = map[string]any{"instance_id": "inst-1", "name": "github"}
+	if _, err := decodeInstanceCreated(missing, true); err == nil {
+		t.Error("Expected a v1 event without json_spec to be rejected")
+	}
+	unknown := v1
+	unknown.SchemaVersion = 7
+	if _, err := decodeInstanceCreated(unknown, true); !errors.Is(err, schema.ErrUnknownSchemaVersion) {
+		t.Errorf("Expected an unknown version to be rejected, got %v", err)
+	}
+
+	// The shim takes the loose format the platform publishes today
+	legacy := schema.EventData{EventID: "evt-2", Data: map[string]any{
+		"instance_id":    "inst-2",
+		"name":           "slack",
+		"server_spec_id": nil,
+		"json_spec":      `{"image": "slack-mcp"}`,
+	}}
+	created, err = decodeInstanceCreated(legacy, true)
+	if err != nil || created.JSONSpec["image"] != "slack-mcp" {
+		t.Errorf("Expected the legacy event to decode, got %+v, %v", created, err)
+	}
+	if _, err := decodeInstanceCreated(legacy, false); err == nil {
+		t.Error("Expected legacy events to be rejected once the shim is off")
+	}
+
+	deleted, err := decodeInstanceDeleted(schema.EventData{Data: map[string]any{"instance_id": "inst-2"}}, true)
+	if err != nil || deleted.InstanceID != "inst-2" {
+		t.Errorf("Expected the legacy delete to decode, got %+v, %v", deleted, err)
+	}
+	if _, err := decodeInstanceDeleted(schema.EventData{SchemaVersion: 1, Data: map[string]any{"name": "slack"}}, true); err == nil {
+		t.Error("Expected a delete without instance_id to be rejected")
+	}
+}
+
+func TestEventSchemas(t *testing.T) {
+	required := map[string][]string{
+		"schemas/MCPServerInstanceCreated.v1.json": {"instance_id", "name", "json_spec"},
+		"schemas/MCPServerInstanceDeleted.v1.json": {"instance_id"},
+	}
+	for path, fields := range required {
+		data, err := fs.ReadFile(schema.Schemas, path)
+		if err != nil {
+			t.Fatalf("Expected %s to be embedded, got %v", path, err)
+		}
+		var document struct {
+			Properties struct {
+				Data struct {
+					Required []string `json:"required"`
+				} `json:"data"`
+			} `json:"properties"`
+		}
+		if err := json.Unmarshal(data, &document); err != nil {
+			t.Fatalf("Expected %s to be valid JSON, got %v", path, err)
+		}
+		if !slices.Equal(document.Properties.Data.Required, fields) {
+			t.Errorf("Expected %s to require %v like the Go validation, got %v", path, fields, document.Properties.Data.Required)
+		}
+	}
+}
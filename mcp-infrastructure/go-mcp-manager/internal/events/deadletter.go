@@ -0,0 +1,51 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/retry"
+	schema "github.com/agentarea/mcp-manager/pkg/events"
+)
+
+// maxDeadLetters caps the dead letter list so a misbehaving publisher cannot fill Redis
+const maxDeadLetters = 1000
+
+// deadLetter pushes an event the subscriber could not process onto the dead letter list, with
+// why, instead of acting on part of it
+func (s *EventSubscriber) deadLetter(ctx context.Context, channel, payload string, eventData *schema.EventData, reason error) {
+	entry := schema.DeadLetter{
+		Channel:    channel,
+		Payload:    payload,
+		Reason:     reason.Error(),
+		ReceivedAt: time.Now().UTC(),
+	}
+	if eventData != nil {
+		entry.EventID = eventData.EventID
+		entry.SchemaVersion = eventData.SchemaVersion
+	}
+
+	s.logger.WarnContext(ctx, "Moving event to the dead letter list",
+		slog.String("channel", channel),
+		slog.String("event_id", entry.EventID),
+		slog.String("reason", entry.Reason))
+
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	err = retry.For(retry.Redis).Do(ctx, func(ctx context.Context) error {
+		pipe := s.redisClient.TxPipeline()
+		pipe.LPush(ctx, schema.DeadLetterList, encoded)
+		pipe.LTrim(ctx, schema.DeadLetterList, 0, maxDeadLetters-1)
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to store dead letter",
+			slog.String("channel", channel),
+			slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,154 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/state"
+)
+
+// outboxBucket holds published events Redis has not accepted yet
+const outboxBucket = "event_outbox"
+
+// outboxEvent is a message waiting in the outbox for its channel
+type outboxEvent struct {
+	ID       string    `json:"id"`
+	Channel  string    `json:"channel"`
+	Payload  string    `json:"payload"`
+	QueuedAt time.Time `json:"queued_at"`
+}
+
+// SetOutbox makes the publisher write every event to an outbox in store before publishing it,
+// so events published while Redis is unavailable are kept and resent in order every
+// flushInterval once Run is started
+func (p *EventPublisher) SetOutbox(store *state.Store, flushInterval time.Duration) {
+	p.outbox = store
+	p.flushInterval = flushInterval
+}
+
+// Pending returns the number of events waiting in the outbox
+func (p *EventPublisher) Pending() int {
+	if p.outbox == nil {
+		return 0
+	}
+	keys, err := p.outbox.Keys(outboxBucket)
+	if err != nil {
+		return 0
+	}
+	return len(keys)
+}
+
+// Run resends events left in the outbox, including those from before a restart, until ctx is cancelled
+func (p *EventPublisher) Run(ctx context.Context) {
+	if p.outbox == nil {
+		return
+	}
+	interval := p.flushInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		p.outboxMutex.Lock()
+		if delivered, err := p.flush(ctx); err != nil {
+			p.logger.DebugContext(ctx, "Redis still unavailable, keeping events in the outbox",
+				slog.Int("pending", p.Pending()),
+				slog.String("error", err.Error()))
+		} else if delivered > 0 {
+			p.logger.InfoContext(ctx, "Delivered events from the outbox", slog.Int("count", delivered))
+		}
+		p.outboxMutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliver publishes payload on channel. With an outbox the event is stored first and published
+// behind any events still waiting, so an event Redis does not take is kept rather than lost.
+func (p *EventPublisher) deliver(ctx context.Context, channel string, payload []byte) error {
+	if p.outbox == nil {
+		return p.publish(ctx, channel, string(payload))
+	}
+
+	event := outboxEvent{
+		ID:       generateOutboxID(),
+		Channel:  channel,
+		Payload:  string(payload),
+		QueuedAt: time.Now(),
+	}
+
+	p.outboxMutex.Lock()
+	defer p.outboxMutex.Unlock()
+
+	if err := p.outbox.Put(outboxBucket, event.ID, event); err != nil {
+		p.logger.ErrorContext(ctx, "Failed to store event in the outbox, publishing it directly",
+			slog.String("channel", channel),
+			slog.String("error", err.Error()))
+		return p.publish(ctx, channel, event.Payload)
+	}
+	if _, err := p.flush(ctx); err != nil {
+		p.logger.WarnContext(ctx, "Redis unavailable, event kept in the outbox",
+			slog.String("channel", channel),
+			slog.Int("pending", p.Pending()),
+			slog.String("error", err.Error()))
+	}
+	return nil
+}
+
+// flush publishes outbox events oldest first and stops at the first one Redis does not take.
+// The caller holds outboxMutex, so events are neither reordered nor published twice.
+func (p *EventPublisher) flush(ctx context.Context) (int, error) {
+	records, err := p.outbox.List(outboxBucket)
+	if err != nil || len(records) == 0 {
+		return 0, err
+	}
+
+	pending := make([]outboxEvent, 0, len(records))
+	for id, data := range records {
+		var event outboxEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			p.logger.WarnContext(ctx, "Dropping unreadable event from the outbox", slog.String("id", id))
+			p.outbox.Delete(outboxBucket, id)
+			continue
+		}
+		pending = append(pending, event)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if !pending[i].QueuedAt.Equal(pending[j].QueuedAt) {
+			return pending[i].QueuedAt.Before(pending[j].QueuedAt)
+		}
+		return pending[i].ID < pending[j].ID
+	})
+
+	delivered := 0
+	for _, event := range pending {
+		if err := p.publish(ctx, event.Channel, event.Payload); err != nil {
+			return delivered, err
+		}
+		delivered++
+		if err := p.outbox.Delete(outboxBucket, event.ID); err != nil {
+			p.logger.WarnContext(ctx, "Failed to remove delivered event from the outbox",
+				slog.String("id", event.ID),
+				slog.String("error", err.Error()))
+		}
+	}
+	return delivered, nil
+}
+
+// generateOutboxID generates a random outbox key
+func generateOutboxID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "obx_" + hex.EncodeToString(b)
+}
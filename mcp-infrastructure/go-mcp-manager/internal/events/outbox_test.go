@@ -0,0 +1,98 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/state"
+	schema "github.com/agentarea/mcp-manager/pkg/events"
+)
+
+func TestOutbox(t *testing.T) {
+	dir := t.TempDir()
+	publisher := NewEventPublisher("redis://localhost:6379", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	defer publisher.Close()
+	publisher.SetOutbox(state.NewStore(dir), time.Minute)
+
+	redisDown := true
+	var published []string
+	publisher.publish = func(_ context.Context, channel, payload string) error {
+		if redisDown {
+			return errors.New("connection refused")
+		}
+		published = append(published, channel)
+		return nil
+	}
+	ctx := context.Background()
+
+	if err := publisher.PublishStarting(ctx, "inst-1", "github"); err != nil {
+		t.Errorf("Expected an event kept in the outbox not to fail the publish, got %v", err)
+	}
+	publisher.PublishWarning(ctx, "inst-1", "github", "route_changed", "moved")
+	if publisher.Pending() != 2 {
+		t.Errorf("Expected 2 pending events, got %d", publisher.Pending())
+	}
+
+	// The outbox survives a restart of the manager
+	restarted := NewEventPublisher("redis://localhost:6379", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	defer restarted.Close()
+	restarted.SetOutbox(state.NewStore(dir), time.Minute)
+	restarted.publish = publisher.publish
+	if restarted.Pending() != 2 {
+		t.Errorf("Expected 2 pending events after a restart, got %d", restarted.Pending())
+	}
+
+	redisDown = false
+	restarted.PublishRunning(ctx, "inst-1", "github", "abc123", "http://localhost/mcp/github/")
+	expected := []string{schema.ChannelStatusChanged, schema.ChannelWarning, schema.ChannelStatusChanged}
+	if len(published) != len(expected) {
+		t.Fatalf("Expected %d published events, got %v", len(expected), published)
+	}
+	for i, channel := range expected {
+		if published[i] != channel {
+			t.Errorf("Expected event %d on %s, got %s", i, channel, published[i])
+		}
+	}
+	if restarted.Pending() != 0 {
+		t.Errorf("Expected an empty outbox, got %d pending", restarted.Pending())
+	}
+}
+
+func TestOutboxKeepsOriginalEvent(t *testing.T) {
+	publisher := NewEventPublisher("redis://localhost:6379", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	defer publisher.Close()
+	publisher.SetOutbox(state.NewStore(""), time.Minute)
+
+	var payloads []string
+	fail := true
+	publisher.publish = func(_ context.Context, _, payload string) error {
+		if fail {
+			return errors.New("connection refused")
+		}
+		payloads = append(payloads, payload)
+		return nil
+	}
+	publisher.PublishError(context.Background(), "inst-1", "github", "image pull failed")
+
+	fail = false
+	if delivered, err := publisher.flush(context.Background()); err != nil || delivered != 1 {
+		t.Fatalf("Expected the queued event to be delivered, got %d, %v", delivered, err)
+	}
+	var message struct {
+		Data struct {
+			EventType string            `json:"event_type"`
+			Data      schema.ErrorEvent `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(payloads[0]), &message); err != nil {
+		t.Fatalf("Expected the FastStream message to be resent as is, got %v", err)
+	}
+	if message.Data.EventType != schema.ChannelError || message.Data.Data.Error != "image pull failed" {
+		t.Errorf("Expected the original error event, got %+v", message.Data)
+	}
+}
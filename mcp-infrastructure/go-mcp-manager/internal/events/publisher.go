@@ -5,34 +5,34 @@ import (
 	"encoding/json"
 	"log/slog"
 	"strings"
+	"sync"
 	"time"
 
 	redis "github.com/go-redis/redis/v8"
+
+	"github.com/agentarea/mcp-manager/internal/requestid"
+	"github.com/agentarea/mcp-manager/internal/retry"
+	"github.com/agentarea/mcp-manager/internal/state"
+	schema "github.com/agentarea/mcp-manager/pkg/events"
 )
 
-// StatusUpdateEvent represents a container status update event
-type StatusUpdateEvent struct {
-	InstanceID  string    `json:"instance_id"`
-	Name        string    `json:"name"`
-	Status      string    `json:"status"`
-	ContainerID string    `json:"container_id,omitempty"`
-	URL         string    `json:"url,omitempty"`
-	Error       string    `json:"error,omitempty"`
-	Timestamp   time.Time `json:"timestamp"`
-}
-
-// ErrorEvent represents a container error event
-type ErrorEvent struct {
-	InstanceID string    `json:"instance_id"`
-	Name       string    `json:"name"`
-	Error      string    `json:"error"`
-	Timestamp  time.Time `json:"timestamp"`
-}
+// StatusListener is told about every status update, whether or not it reached Redis
+type StatusListener func(ctx context.Context, event schema.StatusUpdateEvent)
 
 // EventPublisher handles publishing events to Redis
 type EventPublisher struct {
 	redisClient *redis.Client
 	logger      *slog.Logger
+	listeners   []StatusListener
+	// publish sends a message to Redis; tests replace it
+	publish func(ctx context.Context, channel, payload string) error
+
+	outbox        *state.Store
+	flushInterval time.Duration
+	outboxMutex   sync.Mutex
+
+	// signingSecret signs published events when set; see SetSigningSecret
+	signingSecret []byte
 }
 
 // NewEventPublisher creates a new event publisher
@@ -52,65 +52,128 @@ func NewEventPublisher(redisURL string, logger *slog.Logger) *EventPublisher {
 	return &EventPublisher{
 		redisClient: rdb,
 		logger:      logger,
+		publish: func(ctx context.Context, channel, payload string) error {
+			return retry.For(retry.Redis).Do(ctx, func(ctx context.Context) error {
+				return rdb.Publish(ctx, channel, payload).Err()
+			})
+		},
 	}
 }
 
+// SetSigningSecret makes the publisher sign events with secret the way the subscriber verifies
+// them. A signed envelope carries its data as the encoded event string the signature covers,
+// as FastStream sends it, rather than as an object.
+func (p *EventPublisher) SetSigningSecret(secret string) {
+	if secret != "" {
+		p.signingSecret = []byte(secret)
+	}
+}
+
+// OnStatusUpdate registers a listener; it must be called before anything is published
+func (p *EventPublisher) OnStatusUpdate(listener StatusListener) {
+	p.listeners = append(p.listeners, listener)
+}
+
 // PublishStatusUpdate publishes a container status update event
 func (p *EventPublisher) PublishStatusUpdate(ctx context.Context, instanceID, name, status string, containerID, url string) error {
-	event := StatusUpdateEvent{
+	return p.publishStatus(ctx, schema.StatusUpdateEvent{
 		InstanceID:  instanceID,
 		Name:        name,
 		Status:      status,
 		ContainerID: containerID,
 		URL:         url,
 		Timestamp:   time.Now(),
+	})
+}
+
+// publishStatus notifies the listeners and publishes event on the status channel
+func (p *EventPublisher) publishStatus(ctx context.Context, event schema.StatusUpdateEvent) error {
+	for _, listener := range p.listeners {
+		listener(ctx, event)
 	}
 
 	// Wrap in FastStream message format to match the API's expected structure
 	eventData := map[string]any{
 		"event_id":   generateEventID(),
 		"timestamp":  event.Timestamp.Format(time.RFC3339),
-		"event_type": "MCPServerInstanceStatusChanged",
+		"event_type": schema.ChannelStatusChanged,
 		"data":       event,
 	}
 
-	message := map[string]any{
-		"data":    eventData,
-		"headers": map[string]any{},
+	eventBytes, err := p.envelope(ctx, eventData)
+	if err != nil {
+		p.logger.ErrorContext(ctx, "Failed to marshal status update event",
+			slog.String("instance_id", event.InstanceID),
+			slog.String("error", err.Error()))
+		return err
+	}
+
+	err = p.deliver(ctx, schema.ChannelStatusChanged, eventBytes)
+	if err != nil {
+		p.logger.ErrorContext(ctx, "Failed to publish status update event",
+			slog.String("instance_id", event.InstanceID),
+			slog.String("status", event.Status),
+			slog.String("error", err.Error()))
+		return err
+	}
+
+	p.logger.InfoContext(ctx, "Published status update event",
+		slog.String("instance_id", event.InstanceID),
+		slog.String("name", event.Name),
+		slog.String("status", event.Status),
+		slog.String("container_id", event.ContainerID))
+
+	return nil
+}
+
+// PublishError publishes a container error event
+func (p *EventPublisher) PublishError(ctx context.Context, instanceID, name, errorMsg string) error {
+	event := schema.ErrorEvent{
+		InstanceID: instanceID,
+		Name:       name,
+		Error:      errorMsg,
+		Timestamp:  time.Now(),
+	}
+
+	// Wrap in FastStream message format
+	eventData := map[string]any{
+		"event_id":   generateEventID(),
+		"timestamp":  event.Timestamp.Format(time.RFC3339),
+		"event_type": schema.ChannelError,
+		"data":       event,
 	}
 
-	eventBytes, err := json.Marshal(message)
+	eventBytes, err := p.envelope(ctx, eventData)
 	if err != nil {
-		p.logger.Error("Failed to marshal status update event",
+		p.logger.ErrorContext(ctx, "Failed to marshal error event",
 			slog.String("instance_id", instanceID),
 			slog.String("error", err.Error()))
 		return err
 	}
 
-	err = p.redisClient.Publish(ctx, "MCPServerInstanceStatusChanged", string(eventBytes)).Err()
+	err = p.deliver(ctx, schema.ChannelError, eventBytes)
 	if err != nil {
-		p.logger.Error("Failed to publish status update event",
+		p.logger.ErrorContext(ctx, "Failed to publish error event",
 			slog.String("instance_id", instanceID),
-			slog.String("status", status),
 			slog.String("error", err.Error()))
 		return err
 	}
 
-	p.logger.Info("Published status update event",
+	p.logger.InfoContext(ctx, "Published error event",
 		slog.String("instance_id", instanceID),
 		slog.String("name", name),
-		slog.String("status", status),
-		slog.String("container_id", containerID))
+		slog.String("error_msg", errorMsg))
 
 	return nil
 }
 
-// PublishError publishes a container error event
-func (p *EventPublisher) PublishError(ctx context.Context, instanceID, name, errorMsg string) error {
-	event := ErrorEvent{
+// PublishWarning publishes a non-fatal warning about an instance
+func (p *EventPublisher) PublishWarning(ctx context.Context, instanceID, name, code, warningMsg string) error {
+	event := schema.WarningEvent{
 		InstanceID: instanceID,
 		Name:       name,
-		Error:      errorMsg,
+		Code:       code,
+		Message:    warningMsg,
 		Timestamp:  time.Now(),
 	}
 
@@ -118,58 +181,86 @@ func (p *EventPublisher) PublishError(ctx context.Context, instanceID, name, err
 	eventData := map[string]any{
 		"event_id":   generateEventID(),
 		"timestamp":  event.Timestamp.Format(time.RFC3339),
-		"event_type": "MCPServerInstanceError",
+		"event_type": schema.ChannelWarning,
 		"data":       event,
 	}
 
-	message := map[string]any{
-		"data":    eventData,
-		"headers": map[string]any{},
-	}
-
-	eventBytes, err := json.Marshal(message)
+	eventBytes, err := p.envelope(ctx, eventData)
 	if err != nil {
-		p.logger.Error("Failed to marshal error event",
+		p.logger.ErrorContext(ctx, "Failed to marshal warning event",
 			slog.String("instance_id", instanceID),
 			slog.String("error", err.Error()))
 		return err
 	}
 
-	err = p.redisClient.Publish(ctx, "MCPServerInstanceError", string(eventBytes)).Err()
+	err = p.deliver(ctx, schema.ChannelWarning, eventBytes)
 	if err != nil {
-		p.logger.Error("Failed to publish error event",
+		p.logger.ErrorContext(ctx, "Failed to publish warning event",
 			slog.String("instance_id", instanceID),
 			slog.String("error", err.Error()))
 		return err
 	}
 
-	p.logger.Info("Published error event",
+	p.logger.InfoContext(ctx, "Published warning event",
 		slog.String("instance_id", instanceID),
 		slog.String("name", name),
-		slog.String("error_msg", errorMsg))
+		slog.String("code", code))
 
 	return nil
 }
 
 // PublishRunning publishes that a container is running
 func (p *EventPublisher) PublishRunning(ctx context.Context, instanceID, name, containerID, url string) error {
-	return p.PublishStatusUpdate(ctx, instanceID, name, "running", containerID, url)
+	return p.PublishStatusUpdate(ctx, instanceID, name, schema.StatusRunning, containerID, url)
 }
 
 // PublishStarting publishes that a container is starting
 func (p *EventPublisher) PublishStarting(ctx context.Context, instanceID, name string) error {
-	return p.PublishStatusUpdate(ctx, instanceID, name, "starting", "", "")
+	return p.PublishStatusUpdate(ctx, instanceID, name, schema.StatusStarting, "", "")
 }
 
 // PublishValidating publishes that a container is being validated
 func (p *EventPublisher) PublishValidating(ctx context.Context, instanceID, name string) error {
-	return p.PublishStatusUpdate(ctx, instanceID, name, "validating", "", "")
+	return p.PublishStatusUpdate(ctx, instanceID, name, schema.StatusValidating, "", "")
 }
 
 // PublishFailed publishes that a container failed to start
 func (p *EventPublisher) PublishFailed(ctx context.Context, instanceID, name, errorMsg string) error {
 	p.PublishError(ctx, instanceID, name, errorMsg)
-	return p.PublishStatusUpdate(ctx, instanceID, name, "failed", "", "")
+	return p.publishFailure(ctx, instanceID, name, schema.StatusFailed, errorMsg)
+}
+
+// PublishInitializing publishes that a container's init command is running
+func (p *EventPublisher) PublishInitializing(ctx context.Context, instanceID, name string) error {
+	return p.PublishStatusUpdate(ctx, instanceID, name, schema.StatusInitializing, "", "")
+}
+
+// PublishInitFailed publishes that a container's init command failed, so the container was not started
+func (p *EventPublisher) PublishInitFailed(ctx context.Context, instanceID, name, errorMsg string) error {
+	p.PublishError(ctx, instanceID, name, errorMsg)
+	return p.publishFailure(ctx, instanceID, name, schema.StatusInitFailed, errorMsg)
+}
+
+// PublishCrashLoop publishes that a container kept exiting, so the manager stopped restarting it
+func (p *EventPublisher) PublishCrashLoop(ctx context.Context, instanceID, name, errorMsg string) error {
+	p.PublishError(ctx, instanceID, name, errorMsg)
+	return p.publishFailure(ctx, instanceID, name, schema.StatusCrashLoop, errorMsg)
+}
+
+// publishFailure publishes a failed status carrying the reason
+func (p *EventPublisher) publishFailure(ctx context.Context, instanceID, name, status, errorMsg string) error {
+	return p.publishStatus(ctx, schema.StatusUpdateEvent{
+		InstanceID: instanceID,
+		Name:       name,
+		Status:     status,
+		Error:      errorMsg,
+		Timestamp:  time.Now(),
+	})
+}
+
+// PublishBuilding publishes that a container's image is being built from source
+func (p *EventPublisher) PublishBuilding(ctx context.Context, instanceID, name string) error {
+	return p.PublishStatusUpdate(ctx, instanceID, name, schema.StatusBuilding, "", "")
 }
 
 // Close closes the Redis connection
@@ -177,6 +268,39 @@ func (p *EventPublisher) Close() error {
 	return p.redisClient.Close()
 }
 
+// envelope wraps eventData in a FastStream message, signed when a signing secret is set
+func (p *EventPublisher) envelope(ctx context.Context, eventData map[string]any) ([]byte, error) {
+	headers := messageHeaders(ctx)
+	if p.signingSecret == nil {
+		return json.Marshal(map[string]any{
+			"data":    eventData,
+			"headers": headers,
+		})
+	}
+
+	data, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, err
+	}
+	headers[schema.HeaderSignature] = schema.HMACSignature(p.signingSecret, string(data))
+	return json.Marshal(map[string]any{
+		"data":    string(data),
+		"headers": headers,
+	})
+}
+
+// messageHeaders returns FastStream headers carrying the request and trace IDs from ctx
+func messageHeaders(ctx context.Context) map[string]any {
+	headers := map[string]any{}
+	if id := requestid.FromContext(ctx); id != "" {
+		headers["correlation_id"] = id
+	}
+	if traceID := requestid.TraceIDFromContext(ctx); traceID != "" {
+		headers["trace_id"] = traceID
+	}
+	return headers
+}
+
 // generateEventID generates a unique event ID
 func generateEventID() string {
 	return "evt_" + time.Now().Format("20060102_150405") + "_" + randomString(8)
@@ -0,0 +1,140 @@
+package events
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/internal/jwks"
+	schema "github.com/agentarea/mcp-manager/pkg/events"
+)
+
+// eventTimestampLayouts are accepted for the inner event timestamp besides Unix seconds; the
+// platform's Python isoformat omits the zone for UTC
+var eventTimestampLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"}
+
+// signatureVerifier accepts only recent, unseen events signed by the platform
+type signatureVerifier struct {
+	secret []byte
+	keys   *jwks.KeySet
+	issuer string
+	maxAge time.Duration
+
+	mutex sync.Mutex
+	seen  map[string]time.Time
+	now   func() time.Time
+}
+
+// newSignatureVerifier creates a verifier for the configured secret and JWKS
+func newSignatureVerifier(cfg config.RedisConfig) *signatureVerifier {
+	verifier := &signatureVerifier{
+		issuer: cfg.SigningIssuer,
+		maxAge: cfg.SignatureMaxAge,
+		seen:   make(map[string]time.Time),
+		now:    time.Now,
+	}
+	if cfg.SigningSecret != "" {
+		verifier.secret = []byte(cfg.SigningSecret)
+	}
+	if cfg.SigningJWKSURL != "" {
+		verifier.keys = jwks.New(cfg.SigningJWKSURL, time.Hour)
+	}
+	return verifier
+}
+
+// verify checks the envelope signature over the event data, then that the event is recent and
+// has not been processed before, so a captured event cannot be replayed
+func (v *signatureVerifier) verify(ctx context.Context, message schema.EventMessage, eventData schema.EventData) error {
+	signature, _ := message.Headers[schema.HeaderSignature].(string)
+	switch {
+	case signature == "":
+		return errors.New("event is not signed")
+	case strings.HasPrefix(signature, "sha256="):
+		if v.secret == nil {
+			return errors.New("HMAC signatures are not accepted")
+		}
+		expected := schema.HMACSignature(v.secret, message.Data)
+		if !hmac.Equal([]byte(signature), []byte(expected)) {
+			return errors.New("HMAC signature does not match")
+		}
+	case strings.Count(signature, ".") == 2:
+		if v.keys == nil {
+			return errors.New("JWT signatures are not accepted")
+		}
+		if err := v.verifyJWT(ctx, signature, message.Data); err != nil {
+			return err
+		}
+	default:
+		return errors.New("unrecognized signature format")
+	}
+
+	return v.checkFresh(eventData)
+}
+
+// verifyJWT checks a JWT signature and that it covers data
+func (v *signatureVerifier) verifyJWT(ctx context.Context, token, data string) error {
+	claims, err := v.keys.Verify(ctx, token)
+	if err != nil {
+		return fmt.Errorf("invalid JWT signature: %w", err)
+	}
+	if v.issuer != "" && claims["iss"] != v.issuer {
+		return fmt.Errorf("unexpected JWT issuer %v", claims["iss"])
+	}
+	digest := sha256.Sum256([]byte(data))
+	claimed, _ := claims[schema.ClaimDataSHA256].(string)
+	if !hmac.Equal([]byte(strings.ToLower(claimed)), []byte(hex.EncodeToString(digest[:]))) {
+		return fmt.Errorf("JWT %s claim does not match the event data", schema.ClaimDataSHA256)
+	}
+	return nil
+}
+
+// checkFresh rejects events older than the maximum age and event IDs already processed
+func (v *signatureVerifier) checkFresh(eventData schema.EventData) error {
+	if eventData.EventID == "" {
+		return errors.New("signed events must carry an event_id")
+	}
+	timestamp, err := parseEventTimestamp(eventData.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	now := v.now()
+	if age := now.Sub(timestamp); age > v.maxAge || age < -v.maxAge {
+		return fmt.Errorf("event timestamp %s is outside the accepted %s window", eventData.Timestamp, v.maxAge)
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	for id, seenAt := range v.seen {
+		if now.Sub(seenAt) > 2*v.maxAge {
+			delete(v.seen, id)
+		}
+	}
+	if _, replayed := v.seen[eventData.EventID]; replayed {
+		return fmt.Errorf("event %s was already processed", eventData.EventID)
+	}
+	v.seen[eventData.EventID] = now
+	return nil
+}
+
+// parseEventTimestamp parses an event timestamp in Unix seconds, as the platform's broker sends
+// it, or as RFC 3339, taking one without a zone as UTC
+func parseEventTimestamp(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.UnixMicro(int64(seconds * 1e6)), nil
+	}
+	for _, layout := range eventTimestampLayouts {
+		if timestamp, err := time.Parse(layout, value); err == nil {
+			return timestamp, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid event timestamp %q", value)
+}
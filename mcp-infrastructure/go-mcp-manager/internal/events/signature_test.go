@@ -0,0 +1,137 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	schema "github.com/agentarea/mcp-manager/pkg/events"
+)
+
+func TestSignatureVerifier(t *testing.T) {
+	secret := "platform-secret"
+	verifier := newSignatureVerifier(config.RedisConfig{SigningSecret: secret, SignatureMaxAge: 5 * time.Minute})
+	now := time.Date(2025, 7, 29, 10, 0, 0, 0, time.UTC)
+	verifier.now = func() time.Time { return now }
+
+	envelope := func(eventID, timestamp, signature string) (schema.EventMessage, schema.EventData) {
+		eventData := schema.EventData{
+			EventID:   eventID,
+			Timestamp: timestamp,
+			EventType: schema.ChannelInstanceCreated,
+			Data:      map[string]any{"instance_id": "inst-1", "name": "github"},
+		}
+		data, _ := json.Marshal(eventData)
+		if signature == "" {
+			signature = schema.HMACSignature([]byte(secret), string(data))
+		}
+		return schema.EventMessage{Data: string(data), Headers: map[string]any{schema.HeaderSignature: signature}}, eventData
+	}
+	ctx := context.Background()
+
+	message, eventData := envelope("evt-1", "2025-07-29T09:59:30.123456", "")
+	if err := verifier.verify(ctx, message, eventData); err != nil {
+		t.Errorf("Expected a signed event to be accepted, got %v", err)
+	}
+	if err := verifier.verify(ctx, message, eventData); err == nil {
+		t.Error("Expected a replayed event to be rejected")
+	}
+
+	message, eventData = envelope("evt-2", "2025-07-29T10:00:00Z", "")
+	message.Data = message.Data[:len(message.Data)-1] + `,"extra":true}`
+	if err := verifier.verify(ctx, message, eventData); err == nil {
+		t.Error("Expected tampered event data to be rejected")
+	}
+
+	message, eventData = envelope("evt-3", "2025-07-29T09:00:00Z", "")
+	if err := verifier.verify(ctx, message, eventData); err == nil {
+		t.Error("Expected a stale event to be rejected")
+	}
+
+	message, eventData = envelope("evt-4", "2025-07-29T10:00:00Z", "sha256=00")
+	if err := verifier.verify(ctx, message, eventData); err == nil {
+		t.Error("Expected a wrong signature to be rejected")
+	}
+
+	message, eventData = envelope("evt-5", "2025-07-29T10:00:00Z", "")
+	delete(message.Headers, schema.HeaderSignature)
+	if err := verifier.verify(ctx, message, eventData); err == nil {
+		t.Error("Expected an unsigned event to be rejected")
+	}
+
+	message, eventData = envelope("evt-6", "2025-07-29T10:00:00Z", "header.claims.signature")
+	if err := verifier.verify(ctx, message, eventData); err == nil {
+		t.Error("Expected a JWT signature to be rejected without a JWKS")
+	}
+}
+
+func TestPublishedEventsPassVerification(t *testing.T) {
+	secret := "platform-secret"
+	publisher := NewEventPublisher("redis://localhost:6379", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	defer publisher.Close()
+	publisher.SetSigningSecret(secret)
+	var payloads []string
+	publisher.publish = func(_ context.Context, _, payload string) error {
+		payloads = append(payloads, payload)
+		return nil
+	}
+
+	ctx := context.Background()
+	publisher.PublishRunning(ctx, "inst-1", "github", "abc123", "http://localhost/mcp/github/")
+	publisher.PublishError(ctx, "inst-1", "github", "exited")
+	publisher.PublishWarning(ctx, "inst-1", "github", "route_changed", "moved")
+	if len(payloads) != 3 {
+		t.Fatalf("Expected 3 published events, got %d", len(payloads))
+	}
+
+	verifier := newSignatureVerifier(config.RedisConfig{SigningSecret: secret, SignatureMaxAge: 5 * time.Minute})
+	for _, payload := range payloads {
+		var message schema.EventMessage
+		if err := json.Unmarshal([]byte(payload), &message); err != nil {
+			t.Fatalf("Expected a signed event to carry its data as a string, got %v", err)
+		}
+		var eventData schema.EventData
+		if err := json.Unmarshal([]byte(message.Data), &eventData); err != nil {
+			t.Fatalf("Expected the signed data to decode, got %v", err)
+		}
+		if err := verifier.verify(ctx, message, eventData); err != nil {
+			t.Errorf("Expected the published %s event to pass verification, got %v", eventData.EventType, err)
+		}
+	}
+
+	other := newSignatureVerifier(config.RedisConfig{SigningSecret: "other-secret", SignatureMaxAge: 5 * time.Minute})
+	var message schema.EventMessage
+	json.Unmarshal([]byte(payloads[0]), &message)
+	var eventData schema.EventData
+	json.Unmarshal([]byte(message.Data), &eventData)
+	if err := other.verify(ctx, message, eventData); err == nil {
+		t.Error("Expected an event signed with another secret to be rejected")
+	}
+}
+
+func TestParseEventTimestamp(t *testing.T) {
+	expected := time.Date(2025, 7, 29, 9, 59, 30, 500000000, time.UTC)
+	for _, value := range []string{
+		"1753783170.5",
+		"2025-07-29T09:59:30.5Z",
+		"2025-07-29T11:59:30.5+02:00",
+		"2025-07-29T09:59:30.500000",
+	} {
+		timestamp, err := parseEventTimestamp(value)
+		if err != nil || !timestamp.Equal(expected) {
+			t.Errorf("Expected %q to parse as %s, got %s, %v", value, expected, timestamp, err)
+		}
+	}
+	if timestamp, err := parseEventTimestamp("1753783170"); err != nil || !timestamp.Equal(expected.Truncate(time.Second)) {
+		t.Errorf("Expected whole Unix seconds to parse, got %s, %v", timestamp, err)
+	}
+	for _, value := range []string{"", "yesterday", "2025-07-29"} {
+		if _, err := parseEventTimestamp(value); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
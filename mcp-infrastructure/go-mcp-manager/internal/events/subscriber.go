@@ -3,33 +3,27 @@ package events
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"strings"
 
-	"github.com/agentarea/mcp-manager/internal/models"
+	"github.com/agentarea/mcp-manager/internal/chaos"
+	"github.com/agentarea/mcp-manager/internal/config"
 	"github.com/agentarea/mcp-manager/internal/providers"
+	"github.com/agentarea/mcp-manager/internal/requestid"
+	schema "github.com/agentarea/mcp-manager/pkg/events"
+	"github.com/agentarea/mcp-manager/pkg/models"
 	redis "github.com/go-redis/redis/v8"
 )
 
-// MCPServerInstanceCreated represents the event when an MCP instance is created
-type MCPServerInstanceCreated struct {
-	InstanceID   string         `json:"instance_id"`
-	Name         string         `json:"name"`
-	ServerSpecID string         `json:"server_spec_id,omitempty"`
-	JSONSpec     map[string]any `json:"json_spec"`
-}
-
-// MCPServerInstanceDeleted represents the event when an MCP instance is deleted
-type MCPServerInstanceDeleted struct {
-	InstanceID string `json:"instance_id"`
-	Name       string `json:"name"`
-}
-
 // EventSubscriber handles Redis event subscriptions for MCP events
 type EventSubscriber struct {
 	redisClient     *redis.Client
 	providerManager *providers.ProviderManager
 	logger          *slog.Logger
+	chaos           *chaos.Controller
+	verifier        *signatureVerifier // Nil accepts unsigned events
+	acceptLegacy    bool
 }
 
 // NewEventSubscriber creates a new event subscriber
@@ -50,32 +44,39 @@ func NewEventSubscriber(redisURL string, providerManager *providers.ProviderMana
 		redisClient:     rdb,
 		providerManager: providerManager,
 		logger:          logger,
+		acceptLegacy:    true,
 	}
 }
 
+// AcceptLegacyEvents sets whether events without a schema_version are still decoded through the
+// compatibility shim; once the platform publishes versioned events they can be dead-lettered
+func (s *EventSubscriber) AcceptLegacyEvents(accept bool) {
+	s.acceptLegacy = accept
+}
+
 // Start begins listening for events
 func (s *EventSubscriber) Start(ctx context.Context) error {
-	s.logger.Info("Starting event subscriber")
+	s.logger.InfoContext(ctx, "Starting event subscriber")
 
 	// Subscribe to MCP events
-	pubsub := s.redisClient.Subscribe(ctx, "MCPServerInstanceCreated", "MCPServerInstanceDeleted")
+	pubsub := s.redisClient.Subscribe(ctx, schema.ChannelInstanceCreated, schema.ChannelInstanceDeleted)
 	defer pubsub.Close()
 
 	// Test Redis connection
 	_, err := s.redisClient.Ping(ctx).Result()
 	if err != nil {
-		s.logger.Error("Failed to connect to Redis", slog.String("error", err.Error()))
+		s.logger.ErrorContext(ctx, "Failed to connect to Redis", slog.String("error", err.Error()))
 		return err
 	}
 
-	s.logger.Info("Connected to Redis, listening for events")
+	s.logger.InfoContext(ctx, "Connected to Redis, listening for events")
 
 	// Listen for messages
 	ch := pubsub.Channel()
 	for {
 		select {
 		case <-ctx.Done():
-			s.logger.Info("Event subscriber shutting down")
+			s.logger.InfoContext(ctx, "Event subscriber shutting down")
 			return ctx.Err()
 		case msg := <-ch:
 			if msg == nil {
@@ -86,152 +87,151 @@ func (s *EventSubscriber) Start(ctx context.Context) error {
 	}
 }
 
+// SetChaos lets the subscriber drop events as configured in controller
+func (s *EventSubscriber) SetChaos(controller *chaos.Controller) {
+	s.chaos = controller
+}
+
+// RequireSignatures makes the subscriber ignore create and delete events not signed as cfg
+// describes, so write access to Redis alone cannot provision containers
+func (s *EventSubscriber) RequireSignatures(cfg config.RedisConfig) {
+	s.verifier = newSignatureVerifier(cfg)
+}
+
+// checkSignature reports whether an event may be acted on, logging why it may not
+func (s *EventSubscriber) checkSignature(ctx context.Context, message schema.EventMessage, eventData schema.EventData) bool {
+	if s.verifier == nil {
+		return true
+	}
+	if err := s.verifier.verify(ctx, message, eventData); err != nil {
+		s.logger.WarnContext(ctx, "Rejected event that failed signature verification",
+			slog.String("event_id", eventData.EventID),
+			slog.String("event_type", eventData.EventType),
+			slog.String("error", err.Error()))
+		return false
+	}
+	return true
+}
+
 // handleMessage processes incoming Redis messages
 func (s *EventSubscriber) handleMessage(ctx context.Context, msg *redis.Message) {
-	s.logger.Info("Received event",
+	s.logger.InfoContext(ctx, "Received event",
 		slog.String("channel", msg.Channel),
 		slog.String("payload", msg.Payload))
 
+	if s.chaos.DropEvent() {
+		s.logger.WarnContext(ctx, "Chaos: dropping event", slog.String("channel", msg.Channel))
+		return
+	}
+
 	switch msg.Channel {
-	case "MCPServerInstanceCreated":
+	case schema.ChannelInstanceCreated:
 		s.handleInstanceCreated(ctx, msg.Payload)
-	case "MCPServerInstanceDeleted":
+	case schema.ChannelInstanceDeleted:
 		s.handleInstanceDeleted(ctx, msg.Payload)
 	default:
-		s.logger.Warn("Unknown event channel", slog.String("channel", msg.Channel))
+		s.logger.WarnContext(ctx, "Unknown event channel", slog.String("channel", msg.Channel))
 	}
 }
 
-// EventMessage represents the wrapper structure from FastStream Redis
-type EventMessage struct {
-	Data    string         `json:"data"`
-	Headers map[string]any `json:"headers"`
-}
-
-// EventData represents the inner event data structure
-type EventData struct {
-	EventID   string         `json:"event_id"`
-	Timestamp string         `json:"timestamp"`
-	EventType string         `json:"event_type"`
-	Data      map[string]any `json:"data"`
-}
-
-// handleInstanceCreated processes MCP instance creation events
-func (s *EventSubscriber) handleInstanceCreated(ctx context.Context, payload string) {
-	s.logger.Info("Raw payload received", slog.String("payload", payload))
-
+// parseEvent unmarshals the FastStream envelope and the inner event, moving malformed events
+// to the dead letter list, and checks the signature. It returns false when the event must be
+// dropped.
+func (s *EventSubscriber) parseEvent(ctx context.Context, channel, payload string) (context.Context, schema.EventData, bool) {
 	// First unmarshal the outer FastStream message structure
-	var message EventMessage
+	var message schema.EventMessage
 	if err := json.Unmarshal([]byte(payload), &message); err != nil {
-		s.logger.Error("Failed to unmarshal event message",
-			slog.String("error", err.Error()),
-			slog.String("payload", payload))
-		return
+		s.deadLetter(ctx, channel, payload, nil, fmt.Errorf("invalid event message: %w", err))
+		return ctx, schema.EventData{}, false
 	}
 
-	s.logger.Info("Outer message parsed",
-		slog.String("data", message.Data),
-		slog.Any("headers", message.Headers))
-
 	// Then unmarshal the inner event data (message.Data is a JSON string)
-	var eventData EventData
+	var eventData schema.EventData
 	if err := json.Unmarshal([]byte(message.Data), &eventData); err != nil {
-		s.logger.Error("Failed to unmarshal event data",
-			slog.String("error", err.Error()),
-			slog.String("data", message.Data))
-		return
+		s.deadLetter(ctx, channel, payload, nil, fmt.Errorf("invalid event data: %w", err))
+		return ctx, schema.EventData{}, false
+	}
+	ctx = withEventCorrelation(ctx, message, eventData)
+	if !s.checkSignature(ctx, message, eventData) {
+		return ctx, eventData, false
 	}
 
-	s.logger.Info("Parsed event data structure",
+	s.logger.DebugContext(ctx, "Parsed event",
 		slog.String("event_id", eventData.EventID),
 		slog.String("event_type", eventData.EventType),
-		slog.Any("data_keys", getMapKeys(eventData.Data)),
-		slog.Any("full_data", eventData.Data))
-
-	// Extract the actual event fields from the data
-	instanceID, instanceOK := eventData.Data["instance_id"].(string)
-	name, nameOK := eventData.Data["name"].(string)
-	serverSpecID, serverSpecOK := eventData.Data["server_spec_id"].(string)
-	jsonSpecInterface, jsonSpecOK := eventData.Data["json_spec"]
-
-	var jsonSpec map[string]any
-	if jsonSpecInterface != nil {
-		jsonSpec, _ = jsonSpecInterface.(map[string]any)
+		slog.Int("schema_version", eventData.SchemaVersion),
+		slog.Any("data_keys", getMapKeys(eventData.Data)))
+	return ctx, eventData, true
+}
+
+// handleInstanceCreated processes MCP instance creation events
+func (s *EventSubscriber) handleInstanceCreated(ctx context.Context, payload string) {
+	ctx, eventData, ok := s.parseEvent(ctx, schema.ChannelInstanceCreated, payload)
+	if !ok {
+		return
+	}
+	created, err := decodeInstanceCreated(eventData, s.acceptLegacy)
+	if err != nil {
+		s.deadLetter(ctx, schema.ChannelInstanceCreated, payload, &eventData, err)
+		return
+	}
+	if eventData.SchemaVersion == schema.SchemaVersionLegacy {
+		s.logger.WarnContext(ctx, "Accepted unversioned event through the legacy format",
+			slog.String("event_id", eventData.EventID),
+			slog.String("instance_id", created.InstanceID))
 	}
 
-	s.logger.Info("Extracted event data",
-		slog.String("instance_id", instanceID),
-		slog.Bool("instance_id_ok", instanceOK),
-		slog.String("name", name),
-		slog.Bool("name_ok", nameOK),
-		slog.String("server_spec_id", serverSpecID),
-		slog.Bool("server_spec_id_ok", serverSpecOK),
-		slog.Any("json_spec_raw", jsonSpecInterface),
-		slog.Bool("json_spec_ok", jsonSpecOK),
-		slog.Any("json_spec_parsed", jsonSpec))
-
-	s.logger.Info("Processing MCP instance creation",
-		slog.String("instance_id", instanceID),
-		slog.String("name", name),
-		slog.Any("json_spec", jsonSpec))
+	s.logger.InfoContext(ctx, "Processing MCP instance creation",
+		slog.String("instance_id", created.InstanceID),
+		slog.String("name", created.Name),
+		slog.Any("json_spec", created.JSONSpec))
 
 	// Create MCP server instance model
 	instance := &models.MCPServerInstance{
-		InstanceID:   instanceID,
-		Name:         name,
-		ServerSpecID: serverSpecID,
-		JSONSpec:     jsonSpec,
+		InstanceID:   created.InstanceID,
+		Name:         created.Name,
+		ServerSpecID: created.ServerSpecID,
+		WorkspaceID:  created.WorkspaceID,
+		JSONSpec:     created.JSONSpec,
 		Status:       "pending",
 	}
+	instanceID := created.InstanceID
 
 	// Get the appropriate provider and create the instance
 	provider, err := s.providerManager.GetProvider(instance)
 	if err != nil {
-		s.logger.Error("Failed to get provider",
+		s.logger.ErrorContext(ctx, "Failed to get provider",
 			slog.String("instance_id", instanceID),
 			slog.String("error", err.Error()))
 		return
 	}
 
 	if err := provider.CreateInstance(ctx, instance); err != nil {
-		s.logger.Error("Failed to create MCP instance",
+		s.logger.ErrorContext(ctx, "Failed to create MCP instance",
 			slog.String("instance_id", instanceID),
 			slog.String("error", err.Error()))
 	} else {
-		s.logger.Info("Successfully created MCP instance",
+		s.logger.InfoContext(ctx, "Successfully created MCP instance",
 			slog.String("instance_id", instanceID))
 	}
 }
 
 // handleInstanceDeleted processes MCP instance deletion events
 func (s *EventSubscriber) handleInstanceDeleted(ctx context.Context, payload string) {
-	// First unmarshal the outer FastStream message structure
-	var message EventMessage
-	if err := json.Unmarshal([]byte(payload), &message); err != nil {
-		s.logger.Error("Failed to unmarshal event message",
-			slog.String("error", err.Error()),
-			slog.String("payload", payload))
+	ctx, eventData, ok := s.parseEvent(ctx, schema.ChannelInstanceDeleted, payload)
+	if !ok {
 		return
 	}
-
-	// Then unmarshal the inner event data
-	var eventData EventData
-	if err := json.Unmarshal([]byte(message.Data), &eventData); err != nil {
-		s.logger.Error("Failed to unmarshal event data",
-			slog.String("error", err.Error()),
-			slog.String("data", message.Data))
+	deleted, err := decodeInstanceDeleted(eventData, s.acceptLegacy)
+	if err != nil {
+		s.deadLetter(ctx, schema.ChannelInstanceDeleted, payload, &eventData, err)
 		return
 	}
+	instanceID, name := deleted.InstanceID, deleted.Name
 
-	// Extract the actual event fields from the data
-	instanceID, _ := eventData.Data["instance_id"].(string)
-
-	s.logger.Info("Processing MCP instance deletion",
+	s.logger.InfoContext(ctx, "Processing MCP instance deletion",
 		slog.String("instance_id", instanceID))
 
-	// Extract name from event data for deletion
-	name, _ := eventData.Data["name"].(string)
-
 	// For deletion, we need to determine which provider to use
 	// Since we don't have the full instance data, we'll try both providers
 	// In a production system, you might want to store provider type in a registry
@@ -241,7 +241,7 @@ func (s *EventSubscriber) handleInstanceDeleted(ctx context.Context, payload str
 		JSONSpec: map[string]any{"type": "docker"},
 	})
 	if err := dockerProvider.DeleteInstance(ctx, instanceID, name); err != nil {
-		s.logger.Debug("Docker provider deletion failed (may not be docker type)",
+		s.logger.DebugContext(ctx, "Docker provider deletion failed (may not be docker type)",
 			slog.String("instance_id", instanceID),
 			slog.String("error", err.Error()))
 	}
@@ -251,20 +251,46 @@ func (s *EventSubscriber) handleInstanceDeleted(ctx context.Context, payload str
 		JSONSpec: map[string]any{"type": "url"},
 	})
 	if err := urlProvider.DeleteInstance(ctx, instanceID, name); err != nil {
-		s.logger.Debug("URL provider deletion failed (may not be URL type)",
+		s.logger.DebugContext(ctx, "URL provider deletion failed (may not be URL type)",
 			slog.String("instance_id", instanceID),
 			slog.String("error", err.Error()))
 	}
 
-	s.logger.Info("Processed MCP instance deletion",
+	s.logger.InfoContext(ctx, "Processed MCP instance deletion",
 		slog.String("instance_id", instanceID))
 }
 
+// withEventCorrelation attaches the platform's correlation and trace IDs to ctx, falling back
+// to the event ID so every event-driven provision can be traced through the logs
+func withEventCorrelation(ctx context.Context, message schema.EventMessage, eventData schema.EventData) context.Context {
+	id, _ := message.Headers["correlation_id"].(string)
+	if id = requestid.Sanitize(id); id == "" {
+		id = requestid.Sanitize(eventData.EventID)
+	}
+	if id == "" {
+		id = requestid.New()
+	}
+	ctx = requestid.WithRequestID(ctx, id)
+
+	if traceparent, ok := message.Headers[requestid.TraceparentHeader].(string); ok {
+		ctx = requestid.WithTraceID(ctx, requestid.ParseTraceparent(traceparent))
+	} else if traceID, ok := message.Headers["trace_id"].(string); ok {
+		ctx = requestid.WithTraceID(ctx, requestid.Sanitize(traceID))
+	}
+
+	return ctx
+}
+
 // Close closes the Redis connection
 func (s *EventSubscriber) Close() error {
 	return s.redisClient.Close()
 }
 
+// Ping reports whether Redis answers
+func (s *EventSubscriber) Ping(ctx context.Context) error {
+	return s.redisClient.Ping(ctx).Err()
+}
+
 // Helper function to get map keys for debugging
 func getMapKeys(m map[string]any) []string {
 	keys := make([]string, 0, len(m))
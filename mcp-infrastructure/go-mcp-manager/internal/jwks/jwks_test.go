@@ -0,0 +1,73 @@
+package jwks
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]any{
+			{"kty": "EC", "kid": "ec-1", "crv": "P-256", "use": "sig", "x": b64(key.X.FillBytes(make([]byte, 32))), "y": b64(key.Y.FillBytes(make([]byte, 32)))},
+			{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"},
+		}})
+	}))
+	defer server.Close()
+	keys := New(server.URL, time.Hour)
+	ctx := context.Background()
+
+	claims, err := keys.Verify(ctx, sign(t, key, "ec-1", map[string]any{"sub": "platform", "exp": time.Now().Add(time.Minute).Unix()}))
+	if err != nil || claims["sub"] != "platform" {
+		t.Errorf("Expected the token to verify, got %v, %v", claims, err)
+	}
+	if _, err := keys.Verify(ctx, sign(t, key, "ec-1", map[string]any{"exp": time.Now().Add(-time.Hour).Unix()})); err == nil {
+		t.Error("Expected an expired token to be rejected")
+	}
+	if _, err := keys.Verify(ctx, sign(t, key, "ec-1", map[string]any{})); err == nil {
+		t.Error("Expected a token without expiry to be rejected")
+	}
+
+	// Unknown key IDs do not refetch the JWKS more than once a minute
+	for range 3 {
+		if _, err := keys.Verify(ctx, sign(t, key, "rotated", map[string]any{"exp": time.Now().Add(time.Minute).Unix()})); err == nil {
+			t.Error("Expected a token signed with an unknown key to be rejected")
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Expected one JWKS fetch, got %d", fetches)
+	}
+
+	if got := Strings([]any{"a", "", "b", 3}); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("Expected [a b], got %v", got)
+	}
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func sign(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	input := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(input))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return input + "." + b64(append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...))
+}
@@ -0,0 +1,62 @@
+// Package logbuffer keeps the manager's most recent log output in memory, so it can be attached
+// to a support bundle on hosts where the log collector is out of reach.
+package logbuffer
+
+import (
+	"bytes"
+	"sync"
+)
+
+// Buffer is an io.Writer keeping the last lines written to it. slog handlers write each record
+// with a single Write, so a line is one log entry.
+type Buffer struct {
+	mu    sync.Mutex
+	lines [][]byte
+	next  int
+	full  bool
+}
+
+// New creates a buffer holding up to size lines
+func New(size int) *Buffer {
+	if size <= 0 {
+		size = 1
+	}
+	return &Buffer{lines: make([][]byte, size)}
+}
+
+// Write records p as a line, overwriting the oldest once full
+func (b *Buffer) Write(p []byte) (int, error) {
+	line := bytes.Clone(p)
+	if !bytes.HasSuffix(line, []byte("\n")) {
+		line = append(line, '\n')
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lines[b.next] = line
+	b.next = (b.next + 1) % len(b.lines)
+	if b.next == 0 {
+		b.full = true
+	}
+	return len(p), nil
+}
+
+// Bytes returns the buffered lines, oldest first; a nil buffer has none
+func (b *Buffer) Bytes() []byte {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var out bytes.Buffer
+	if b.full {
+		for _, line := range b.lines[b.next:] {
+			out.Write(line)
+		}
+	}
+	for _, line := range b.lines[:b.next] {
+		out.Write(line)
+	}
+	return out.Bytes()
+}
@@ -0,0 +1,20 @@
+package logbuffer
+
+import "testing"
+
+func TestBufferKeepsLastLines(t *testing.T) {
+	buffer := New(2)
+	for _, line := range []string{"one\n", "two\n", "three"} {
+		if _, err := buffer.Write([]byte(line)); err != nil {
+			t.Fatalf("Expected write to succeed, got %v", err)
+		}
+	}
+	if got := string(buffer.Bytes()); got != "two\nthree\n" {
+		t.Errorf("Expected the last two lines, got %q", got)
+	}
+
+	var missing *Buffer
+	if got := missing.Bytes(); got != nil {
+		t.Errorf("Expected no lines from a nil buffer, got %q", got)
+	}
+}
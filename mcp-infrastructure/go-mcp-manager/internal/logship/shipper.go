@@ -0,0 +1,120 @@
+package logship
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// sendAttempts is how many times a batch is sent before it is dropped
+const sendAttempts = 3
+
+// Shipper batches entries and sends them to a sink from a single goroutine
+type Shipper struct {
+	sink          Sink
+	entries       chan Entry
+	batchSize     int
+	flushInterval time.Duration
+	dropped       atomic.Uint64
+	logger        *slog.Logger
+}
+
+// NewShipper creates a shipper buffering up to ten batches; entries beyond that are dropped
+// rather than blocking the container log readers
+func NewShipper(sink Sink, batchSize int, flushInterval time.Duration, logger *slog.Logger) *Shipper {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	if flushInterval <= 0 {
+		flushInterval = 5 * time.Second
+	}
+	return &Shipper{
+		sink:          sink,
+		entries:       make(chan Entry, batchSize*10),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		logger:        logger,
+	}
+}
+
+// Ship queues an entry without blocking and reports whether it was accepted
+func (s *Shipper) Ship(entry Entry) bool {
+	select {
+	case s.entries <- entry:
+		return true
+	default:
+		s.dropped.Add(1)
+		return false
+	}
+}
+
+// Dropped returns how many entries were discarded because the buffer was full or the sink failed
+func (s *Shipper) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Run sends batches when they are full or the flush interval passes, until ctx is done.
+// Entries still buffered at that point are flushed with a short grace period.
+func (s *Shipper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Entry, 0, s.batchSize)
+	for {
+		select {
+		case <-ctx.Done():
+		drain:
+			for {
+				select {
+				case entry := <-s.entries:
+					batch = append(batch, entry)
+				default:
+					break drain
+				}
+			}
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			s.flush(flushCtx, batch)
+			cancel()
+			return
+		case entry := <-s.entries:
+			batch = append(batch, entry)
+			if len(batch) >= s.batchSize {
+				s.flush(ctx, batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				s.flush(ctx, batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// flush sends a batch, retrying with linear backoff before dropping it
+func (s *Shipper) flush(ctx context.Context, batch []Entry) {
+	if len(batch) == 0 {
+		return
+	}
+
+	var err error
+retry:
+	for attempt := 1; attempt <= sendAttempts; attempt++ {
+		if err = s.sink.Send(ctx, batch); err == nil {
+			return
+		}
+		if attempt < sendAttempts {
+			select {
+			case <-ctx.Done():
+				break retry
+			case <-time.After(time.Duration(attempt) * time.Second):
+			}
+		}
+	}
+
+	s.dropped.Add(uint64(len(batch)))
+	s.logger.Warn("Dropped container log batch",
+		slog.Int("entries", len(batch)),
+		slog.String("error", err.Error()))
+}
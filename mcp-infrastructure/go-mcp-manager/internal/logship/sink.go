@@ -0,0 +1,216 @@
+package logship
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sink types
+const (
+	SinkLoki       = "loki"
+	SinkOpenSearch = "opensearch"
+	SinkHTTP       = "http"
+)
+
+// Entry is a single container log line
+type Entry struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Line      string            `json:"line"`
+	Stream    string            `json:"stream"`
+	Labels    map[string]string `json:"labels"`
+}
+
+// Sink delivers batches of log entries to a log store
+type Sink interface {
+	Send(ctx context.Context, entries []Entry) error
+}
+
+// Target identifies a sink; shippers are shared between instances with the same target
+type Target struct {
+	Type       string
+	URL        string
+	Index      string
+	AuthHeader string
+}
+
+// ValidSinkType reports whether a sink type is supported
+func ValidSinkType(sinkType string) bool {
+	switch sinkType {
+	case SinkLoki, SinkOpenSearch, SinkHTTP:
+		return true
+	}
+	return false
+}
+
+// NewSink creates the sink for a target
+func NewSink(target Target, client *http.Client) (Sink, error) {
+	if target.URL == "" {
+		return nil, fmt.Errorf("log sink URL is required")
+	}
+
+	base := httpSink{url: target.URL, authHeader: target.AuthHeader, client: client}
+	switch target.Type {
+	case SinkLoki:
+		base.url = strings.TrimSuffix(target.URL, "/") + "/loki/api/v1/push"
+		return &lokiSink{base}, nil
+	case SinkOpenSearch:
+		index := target.Index
+		if index == "" {
+			index = "mcp-logs"
+		}
+		base.url = strings.TrimSuffix(target.URL, "/") + "/_bulk"
+		return &openSearchSink{httpSink: base, index: index}, nil
+	case SinkHTTP:
+		return &base, nil
+	default:
+		return nil, fmt.Errorf("unsupported log sink %q", target.Type)
+	}
+}
+
+// httpSink POSTs batches as a JSON array; the Loki and OpenSearch sinks reuse its transport
+type httpSink struct {
+	url        string
+	authHeader string
+	client     *http.Client
+}
+
+// Send POSTs the entries as a JSON array
+func (s *httpSink) Send(ctx context.Context, entries []Entry) error {
+	body, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal log entries: %w", err)
+	}
+	return s.post(ctx, "application/json", body)
+}
+
+// post sends a body and treats any non-2xx response as a failure
+func (s *httpSink) post(ctx context.Context, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if s.authHeader != "" {
+		req.Header.Set("Authorization", s.authHeader)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send logs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("log sink returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// lokiSink pushes entries to the Loki push API, one stream per label set
+type lokiSink struct {
+	httpSink
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// Send groups entries into streams by label set and pushes them
+func (s *lokiSink) Send(ctx context.Context, entries []Entry) error {
+	body, err := json.Marshal(map[string][]lokiStream{"streams": lokiStreams(entries)})
+	if err != nil {
+		return fmt.Errorf("failed to marshal log entries: %w", err)
+	}
+	return s.post(ctx, "application/json", body)
+}
+
+// lokiStreams groups entries by their labels plus stream, keeping their order within each stream
+func lokiStreams(entries []Entry) []lokiStream {
+	var streams []lokiStream
+	index := make(map[string]int)
+
+	for _, entry := range entries {
+		labels := make(map[string]string, len(entry.Labels)+1)
+		for k, v := range entry.Labels {
+			labels[k] = v
+		}
+		labels["stream"] = entry.Stream
+
+		key := labelKey(labels)
+		i, exists := index[key]
+		if !exists {
+			i = len(streams)
+			index[key] = i
+			streams = append(streams, lokiStream{Stream: labels})
+		}
+		streams[i].Values = append(streams[i].Values, [2]string{
+			strconv.FormatInt(entry.Timestamp.UnixNano(), 10),
+			entry.Line,
+		})
+	}
+	return streams
+}
+
+// labelKey returns a stable key for a label set
+func labelKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(labels[k])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// openSearchSink indexes entries through the OpenSearch bulk API
+type openSearchSink struct {
+	httpSink
+	index string
+}
+
+// Send writes one document per entry with the labels as top-level fields
+func (s *openSearchSink) Send(ctx context.Context, entries []Entry) error {
+	action, err := json.Marshal(map[string]map[string]string{"index": {"_index": s.index}})
+	if err != nil {
+		return fmt.Errorf("failed to marshal bulk action: %w", err)
+	}
+
+	var body bytes.Buffer
+	for _, entry := range entries {
+		document := make(map[string]string, len(entry.Labels)+3)
+		for k, v := range entry.Labels {
+			document[k] = v
+		}
+		document["@timestamp"] = entry.Timestamp.UTC().Format(time.RFC3339Nano)
+		document["message"] = entry.Line
+		document["stream"] = entry.Stream
+
+		data, err := json.Marshal(document)
+		if err != nil {
+			return fmt.Errorf("failed to marshal log entry: %w", err)
+		}
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(data)
+		body.WriteByte('\n')
+	}
+
+	return s.post(ctx, "application/x-ndjson", body.Bytes())
+}
@@ -0,0 +1,105 @@
+package logship
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLokiSinkGroupsStreams(t *testing.T) {
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/loki/api/v1/push" {
+			t.Errorf("Expected Loki push path, got %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(Target{Type: SinkLoki, URL: server.URL}, server.Client())
+	if err != nil {
+		t.Fatalf("Expected Loki sink, got %v", err)
+	}
+
+	labels := map[string]string{"service_name": "github"}
+	at := time.Unix(0, 1700000000000000000)
+	err = sink.Send(context.Background(), []Entry{
+		{Timestamp: at, Line: "one", Stream: "stdout", Labels: labels},
+		{Timestamp: at, Line: "two", Stream: "stderr", Labels: labels},
+		{Timestamp: at, Line: "three", Stream: "stdout", Labels: labels},
+	})
+	if err != nil {
+		t.Fatalf("Expected push to succeed, got %v", err)
+	}
+
+	var payload struct {
+		Streams []lokiStream `json:"streams"`
+	}
+	if err := json.Unmarshal(<-bodies, &payload); err != nil {
+		t.Fatalf("Expected JSON payload, got %v", err)
+	}
+	if len(payload.Streams) != 2 {
+		t.Fatalf("Expected 2 streams, got %d", len(payload.Streams))
+	}
+	stdout := payload.Streams[0]
+	if stdout.Stream["stream"] != "stdout" || len(stdout.Values) != 2 || stdout.Values[1][1] != "three" {
+		t.Errorf("Expected stdout stream with two ordered lines, got %+v", stdout)
+	}
+	if stdout.Values[0][0] != "1700000000000000000" {
+		t.Errorf("Expected nanosecond timestamp, got %s", stdout.Values[0][0])
+	}
+}
+
+func TestOpenSearchSinkWritesBulk(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- string(body)
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(Target{Type: SinkOpenSearch, URL: server.URL, Index: "mcp", AuthHeader: "Basic abc"}, server.Client())
+	if err != nil {
+		t.Fatalf("Expected OpenSearch sink, got %v", err)
+	}
+	if err := sink.Send(context.Background(), []Entry{{Timestamp: time.Now(), Line: "hello", Stream: "stdout"}}); err != nil {
+		t.Fatalf("Expected bulk request to succeed, got %v", err)
+	}
+
+	r := <-received
+	if r.URL.Path != "/_bulk" || r.Header.Get("Authorization") != "Basic abc" {
+		t.Errorf("Expected authorized bulk request, got %s with %q", r.URL.Path, r.Header.Get("Authorization"))
+	}
+	lines := strings.Split(strings.TrimSpace(<-bodies), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"_index":"mcp"`) || !strings.Contains(lines[1], `"message":"hello"`) {
+		t.Errorf("Expected an action and a document line, got %v", lines)
+	}
+}
+
+func TestShipperDropsWhenFull(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	shipper := NewShipper(nil, 1, time.Second, logger)
+
+	for i := 0; i < 10; i++ {
+		if !shipper.Ship(Entry{Line: "buffered"}) {
+			t.Fatalf("Expected entry %d to be buffered", i)
+		}
+	}
+	if shipper.Ship(Entry{Line: "overflow"}) {
+		t.Errorf("Expected entry beyond the buffer to be dropped")
+	}
+	if shipper.Dropped() != 1 {
+		t.Errorf("Expected 1 dropped entry, got %d", shipper.Dropped())
+	}
+}
@@ -0,0 +1,233 @@
+// Package mtls provides the manager's internal certificate authority and the TLS configurations
+// for mutual TLS on the management API and between the proxy and MCP containers.
+package mtls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Files of the internal CA and of every issued certificate
+const (
+	CAFile   = "ca.crt"
+	CertFile = "tls.crt"
+	KeyFile  = "tls.key"
+
+	caKeyFile = "ca.key"
+)
+
+// Lifetimes of the internal CA and the certificates it issues
+const (
+	caLifetime   = 10 * 365 * 24 * time.Hour
+	leafLifetime = 365 * 24 * time.Hour
+)
+
+// Authority is the manager's internal CA. It issues the certificates of containers, of the proxy
+// and of the manager itself; nothing outside the host needs to trust it.
+type Authority struct {
+	cert    *x509.Certificate
+	certPEM []byte
+	key     *ecdsa.PrivateKey
+}
+
+// Request describes a certificate to issue
+type Request struct {
+	CommonName string
+	DNSNames   []string
+	URIs       []*url.URL
+	// Server issues a server certificate; otherwise a client certificate is issued
+	Server bool
+}
+
+// Issued is a certificate and its private key, PEM encoded
+type Issued struct {
+	CertPEM []byte
+	KeyPEM  []byte
+}
+
+// LoadOrCreateAuthority loads the CA kept in dir, creating it on first use
+func LoadOrCreateAuthority(dir string) (*Authority, error) {
+	certPEM, certErr := os.ReadFile(filepath.Join(dir, CAFile))
+	keyPEM, keyErr := os.ReadFile(filepath.Join(dir, caKeyFile))
+	if errors.Is(certErr, os.ErrNotExist) && errors.Is(keyErr, os.ErrNotExist) {
+		return createAuthority(dir)
+	}
+	if certErr != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", certErr)
+	}
+	if keyErr != nil {
+		return nil, fmt.Errorf("failed to read CA key: %w", keyErr)
+	}
+
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load CA: %w", err)
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA certificate: %w", err)
+	}
+	key, ok := pair.PrivateKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("CA key in %s is not an ECDSA key", dir)
+	}
+	return &Authority{cert: cert, certPEM: certPEM, key: key}, nil
+}
+
+// createAuthority generates a self-signed CA and writes it to dir
+func createAuthority(dir string) (*Authority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate CA key: %w", err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          randomSerial(),
+		Subject:               pkix.Name{CommonName: "mcp-manager internal CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(caLifetime),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA certificate: %w", err)
+	}
+
+	authority := &Authority{
+		cert:    cert,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:     key,
+	}
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFiles(dir, map[string][]byte{CAFile: authority.certPEM, caKeyFile: keyPEM}); err != nil {
+		return nil, err
+	}
+	return authority, nil
+}
+
+// CertPEM returns the CA certificate, PEM encoded
+func (a *Authority) CertPEM() []byte {
+	return a.certPEM
+}
+
+// Pool returns a pool trusting only the CA
+func (a *Authority) Pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(a.cert)
+	return pool
+}
+
+// Issue creates a certificate signed by the CA
+func (a *Authority) Issue(req Request) (*Issued, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	usage := x509.ExtKeyUsageClientAuth
+	if req.Server {
+		usage = x509.ExtKeyUsageServerAuth
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: randomSerial(),
+		Subject:      pkix.Name{CommonName: req.CommonName},
+		DNSNames:     req.DNSNames,
+		URIs:         req.URIs,
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(leafLifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, a.cert, &key.PublicKey, a.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue certificate for %s: %w", req.CommonName, err)
+	}
+
+	keyPEM, err := encodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Issued{
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		KeyPEM:  keyPEM,
+	}, nil
+}
+
+// IssueTo issues a certificate and writes it to dir with the CA certificate
+func (a *Authority) IssueTo(dir string, req Request) error {
+	issued, err := a.Issue(req)
+	if err != nil {
+		return err
+	}
+	return writeFiles(dir, map[string][]byte{
+		CAFile:   a.certPEM,
+		CertFile: issued.CertPEM,
+		KeyFile:  issued.KeyPEM,
+	})
+}
+
+// TLSCertificate returns the issued certificate for use in a tls.Config
+func (i *Issued) TLSCertificate() (tls.Certificate, error) {
+	return tls.X509KeyPair(i.CertPEM, i.KeyPEM)
+}
+
+// encodeKey PEM encodes a private key
+func encodeKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
+
+// writeFiles writes files to dir, readable only by the manager; each file is replaced atomically
+func writeFiles(dir string, files map[string][]byte) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		tmpPath := path + ".tmp"
+		if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		if err := os.Rename(tmpPath, path); err != nil {
+			os.Remove(tmpPath)
+			return fmt.Errorf("failed to replace %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// randomSerial returns a random 128-bit certificate serial number
+func randomSerial() *big.Int {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return big.NewInt(time.Now().UnixNano())
+	}
+	return serial
+}
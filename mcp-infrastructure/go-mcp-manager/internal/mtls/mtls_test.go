@@ -0,0 +1,152 @@
+package mtls
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+)
+
+func TestLoadOrCreateAuthority(t *testing.T) {
+	dir := t.TempDir()
+	created, err := LoadOrCreateAuthority(dir)
+	if err != nil {
+		t.Fatalf("Expected CA to be created, got %v", err)
+	}
+	loaded, err := LoadOrCreateAuthority(dir)
+	if err != nil {
+		t.Fatalf("Expected CA to be loaded, got %v", err)
+	}
+	if string(created.CertPEM()) != string(loaded.CertPEM()) {
+		t.Errorf("Expected the same CA to be loaded again")
+	}
+
+	issued, err := loaded.Issue(Request{CommonName: "mcp-proxy"})
+	if err != nil {
+		t.Fatalf("Expected certificate to be issued, got %v", err)
+	}
+	if _, err := issued.TLSCertificate(); err != nil {
+		t.Errorf("Expected issued certificate to load, got %v", err)
+	}
+}
+
+func TestClientConfig(t *testing.T) {
+	authority, err := LoadOrCreateAuthority(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create CA: %v", err)
+	}
+	serverCert := issue(t, authority, Request{CommonName: "mcp-fetch", DNSNames: []string{"mcp-fetch"}, Server: true})
+	clientCert := issue(t, authority, Request{CommonName: "mcp-manager"})
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    authority.Pool(),
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	// The server is reached by IP although its certificate only names the container
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: ClientConfig(clientCert, authority)}}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected mutual TLS request to succeed, got %v", err)
+	}
+	resp.Body.Close()
+
+	// A server certificate from another CA is rejected
+	other, err := LoadOrCreateAuthority(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create CA: %v", err)
+	}
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: ClientConfig(clientCert, other)}}
+	if resp, err := client.Get(server.URL); err == nil {
+		resp.Body.Close()
+		t.Errorf("Expected server certificate of another CA to be rejected")
+	}
+}
+
+func TestServerConfig(t *testing.T) {
+	internal, err := LoadOrCreateAuthority(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create CA: %v", err)
+	}
+	clientCADir := t.TempDir()
+	clientCA, err := LoadOrCreateAuthority(clientCADir)
+	if err != nil {
+		t.Fatalf("Failed to create CA: %v", err)
+	}
+	apiDir := t.TempDir()
+	if err := clientCA.IssueTo(apiDir, Request{CommonName: "mcp-manager", DNSNames: []string{"localhost"}, Server: true}); err != nil {
+		t.Fatalf("Failed to issue API certificate: %v", err)
+	}
+
+	if _, err := ServerConfig(config.MTLSConfig{CertFile: filepath.Join(apiDir, CertFile), KeyFile: filepath.Join(apiDir, KeyFile)}, internal); err == nil {
+		t.Errorf("Expected a client CA to be required")
+	}
+
+	allowed := "spiffe://agentarea.dev/platform"
+	tlsConfig, err := ServerConfig(config.MTLSConfig{
+		CertFile:         filepath.Join(apiDir, CertFile),
+		KeyFile:          filepath.Join(apiDir, KeyFile),
+		ClientCAFile:     filepath.Join(clientCADir, CAFile),
+		AllowedSPIFFEIDs: []string{allowed},
+	}, internal)
+	if err != nil {
+		t.Fatalf("Expected server configuration, got %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	platformID, _ := url.Parse(allowed)
+	otherID, _ := url.Parse("spiffe://agentarea.dev/other")
+	tests := []struct {
+		name     string
+		cert     tls.Certificate
+		admitted bool
+	}{
+		{"allowed SPIFFE ID", issue(t, clientCA, Request{CommonName: "platform", URIs: []*url.URL{platformID}}), true},
+		{"other SPIFFE ID", issue(t, clientCA, Request{CommonName: "other", URIs: []*url.URL{otherID}}), false},
+		{"internal CA", issue(t, internal, Request{CommonName: "mcp-proxy"}), true},
+	}
+	for _, tt := range tests {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			Certificates: []tls.Certificate{tt.cert},
+			RootCAs:      clientCA.Pool(),
+			ServerName:   "localhost",
+		}}}
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if admitted := err == nil; admitted != tt.admitted {
+			t.Errorf("%s: expected admitted=%v, got error %v", tt.name, tt.admitted, err)
+		}
+	}
+}
+
+// issue issues a certificate for a test
+func issue(t *testing.T, authority *Authority, req Request) tls.Certificate {
+	t.Helper()
+	issued, err := authority.Issue(req)
+	if err != nil {
+		t.Fatalf("Failed to issue certificate: %v", err)
+	}
+	cert, err := issued.TLSCertificate()
+	if err != nil {
+		t.Fatalf("Failed to load certificate: %v", err)
+	}
+	return cert
+}
@@ -0,0 +1,126 @@
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+)
+
+// ServerConfig returns the TLS configuration of the management API. Clients must present a
+// certificate issued by the configured client CA or, for the proxy's callbacks, by the internal
+// CA; with an allow list, certificates of the client CA must also carry an allowed SPIFFE ID.
+func ServerConfig(cfg config.MTLSConfig, internal *Authority) (*tls.Config, error) {
+	if cfg.ClientCAFile == "" {
+		return nil, fmt.Errorf("a client CA is required to serve the management API over mutual TLS")
+	}
+
+	certificate, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API certificate: %w", err)
+	}
+	clientCAs, err := loadPool(cfg.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// The internal CA is checked separately, so its certificates skip the SPIFFE allow list
+	pool := clientCAs.Clone()
+	if internal != nil {
+		pool.AddCert(internal.cert)
+	}
+
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{certificate},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return errors.New("client certificate required")
+			}
+			leaf := state.PeerCertificates[0]
+			if internal != nil && verifies(leaf, internal.Pool(), state.PeerCertificates[1:]) {
+				return nil
+			}
+			if !verifies(leaf, clientCAs, state.PeerCertificates[1:]) {
+				return errors.New("client certificate is not issued by the client CA")
+			}
+			return checkSPIFFEID(leaf, cfg.AllowedSPIFFEIDs)
+		},
+	}, nil
+}
+
+// ClientConfig returns the TLS configuration the manager reaches containers with: it presents
+// certificate and accepts servers whose certificate the internal CA issued, whatever address they
+// are dialled at, since containers are reached by IP
+func ClientConfig(certificate tls.Certificate, internal *Authority) *tls.Config {
+	roots := internal.Pool()
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{certificate},
+		// Chain verification happens in VerifyConnection, without the host name check
+		InsecureSkipVerify: true,
+		VerifyConnection: func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 {
+				return errors.New("server presented no certificate")
+			}
+			_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+				Roots:         roots,
+				Intermediates: intermediatePool(state.PeerCertificates[1:]),
+				KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			})
+			return err
+		},
+	}
+}
+
+// checkSPIFFEID admits a certificate carrying one of allowed as a SPIFFE URI SAN; an empty allow
+// list admits every certificate
+func checkSPIFFEID(cert *x509.Certificate, allowed []string) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+	for _, uri := range cert.URIs {
+		if uri.Scheme == "spiffe" && slices.Contains(allowed, uri.String()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("client certificate %q carries no allowed SPIFFE ID", cert.Subject.CommonName)
+}
+
+// verifies reports whether leaf chains to roots as a client certificate
+func verifies(leaf *x509.Certificate, roots *x509.CertPool, intermediates []*x509.Certificate) bool {
+	_, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediatePool(intermediates),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return err == nil
+}
+
+// intermediatePool returns a pool of the intermediate certificates a peer presented
+func intermediatePool(certs []*x509.Certificate) *x509.CertPool {
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	return pool
+}
+
+// loadPool reads a PEM bundle of CA certificates
+func loadPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
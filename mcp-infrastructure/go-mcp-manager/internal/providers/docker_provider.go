@@ -4,9 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 
-	"github.com/agentarea/mcp-manager/internal/models"
 	"github.com/agentarea/mcp-manager/internal/secrets"
+	"github.com/agentarea/mcp-manager/pkg/models"
 )
 
 // DockerProvider handles Docker-based MCP server instances
@@ -33,7 +34,7 @@ func NewDockerProvider(secretResolver *secrets.SecretResolver, containerManager
 
 // CreateInstance creates a new Docker container for the MCP server using the container manager
 func (p *DockerProvider) CreateInstance(ctx context.Context, instance *models.MCPServerInstance) error {
-	p.logger.Info("Creating Docker container via container manager",
+	p.logger.InfoContext(ctx, "Creating Docker container via container manager",
 		slog.String("instance_id", instance.InstanceID),
 		slog.String("name", instance.Name))
 
@@ -54,7 +55,7 @@ func (p *DockerProvider) CreateInstance(ctx context.Context, instance *models.MC
 
 			resolvedEnv, err := p.secretResolver.ResolveSecrets(instance.InstanceID, stringEnvMap)
 			if err != nil {
-				p.logger.Error("Failed to resolve secrets",
+				p.logger.ErrorContext(ctx, "Failed to resolve secrets",
 					slog.String("instance_id", instance.InstanceID),
 					slog.String("error", err.Error()))
 				return fmt.Errorf("failed to resolve secrets: %w", err)
@@ -69,17 +70,48 @@ func (p *DockerProvider) CreateInstance(ctx context.Context, instance *models.MC
 		}
 	}
 
+	// Resolve file content given as a secret reference; the resolver looks secrets up by the
+	// name after the prefix, since a path is not a secret name
+	if filesInterface, exists := resolvedSpec["files"]; exists {
+		if filesMap, ok := filesInterface.(map[string]interface{}); ok {
+			resolvedFiles := make(map[string]interface{}, len(filesMap))
+			for filePath, value := range filesMap {
+				content, isString := value.(string)
+				name, isRef := strings.CutPrefix(content, "secret_ref:")
+				if !isString || !isRef {
+					resolvedFiles[filePath] = value
+					continue
+				}
+				resolved, err := p.secretResolver.ResolveSecrets(instance.InstanceID, map[string]string{name: content})
+				if err != nil {
+					p.logger.ErrorContext(ctx, "Failed to resolve file secret",
+						slog.String("instance_id", instance.InstanceID),
+						slog.String("path", filePath),
+						slog.String("error", err.Error()))
+					return fmt.Errorf("failed to resolve secret for file %s: %w", filePath, err)
+				}
+				resolvedFiles[filePath] = resolved[name]
+			}
+			resolvedSpec["files"] = resolvedFiles
+		}
+	}
+
+	// The workspace selects the instance's isolated network; an explicit json_spec value wins
+	if _, exists := resolvedSpec["workspace_id"]; !exists && instance.WorkspaceID != "" {
+		resolvedSpec["workspace_id"] = instance.WorkspaceID
+	}
+
 	// Use the container manager to create the container
 	// This ensures the container is properly tracked in the manager's internal map
 	err := p.containerManager.HandleMCPInstanceCreated(ctx, instance.InstanceID, instance.Name, resolvedSpec)
 	if err != nil {
-		p.logger.Error("Failed to create container via container manager",
+		p.logger.ErrorContext(ctx, "Failed to create container via container manager",
 			slog.String("instance_id", instance.InstanceID),
 			slog.String("error", err.Error()))
 		return fmt.Errorf("failed to create container: %w", err)
 	}
 
-	p.logger.Info("Successfully created Docker container via container manager",
+	p.logger.InfoContext(ctx, "Successfully created Docker container via container manager",
 		slog.String("instance_id", instance.InstanceID),
 		slog.String("name", instance.Name))
 
@@ -88,7 +120,7 @@ func (p *DockerProvider) CreateInstance(ctx context.Context, instance *models.MC
 
 // DeleteInstance removes the Docker container using the container manager
 func (p *DockerProvider) DeleteInstance(ctx context.Context, instanceID, name string) error {
-	p.logger.Info("Deleting Docker container via container manager",
+	p.logger.InfoContext(ctx, "Deleting Docker container via container manager",
 		slog.String("instance_id", instanceID),
 		slog.String("name", name))
 
@@ -96,13 +128,13 @@ func (p *DockerProvider) DeleteInstance(ctx context.Context, instanceID, name st
 	// This ensures the container is properly removed from the manager's tracking
 	err := p.containerManager.HandleMCPInstanceDeleted(ctx, instanceID)
 	if err != nil {
-		p.logger.Error("Failed to delete container via container manager",
+		p.logger.ErrorContext(ctx, "Failed to delete container via container manager",
 			slog.String("instance_id", instanceID),
 			slog.String("error", err.Error()))
 		return fmt.Errorf("failed to delete container: %w", err)
 	}
 
-	p.logger.Info("Successfully deleted Docker container via container manager",
+	p.logger.InfoContext(ctx, "Successfully deleted Docker container via container manager",
 		slog.String("instance_id", instanceID),
 		slog.String("name", name))
 
@@ -113,7 +145,7 @@ func (p *DockerProvider) DeleteInstance(ctx context.Context, instanceID, name st
 func (p *DockerProvider) GetInstanceStatus(ctx context.Context, name string) (string, error) {
 	// This method can remain as-is since it's just querying status
 	// In a more complete implementation, this could also use the container manager
-	p.logger.Info("Getting instance status",
+	p.logger.InfoContext(ctx, "Getting instance status",
 		slog.String("name", name))
 
 	// For now, return a placeholder status
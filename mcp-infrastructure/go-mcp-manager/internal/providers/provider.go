@@ -3,7 +3,7 @@ package providers
 import (
 	"context"
 
-	"github.com/agentarea/mcp-manager/internal/models"
+	"github.com/agentarea/mcp-manager/pkg/models"
 )
 
 // Provider defines the interface for MCP server providers
@@ -7,7 +7,7 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/agentarea/mcp-manager/internal/models"
+	"github.com/agentarea/mcp-manager/pkg/models"
 )
 
 // URLProvider handles URL-based MCP server instances
@@ -36,21 +36,21 @@ func (p *URLProvider) CreateInstance(ctx context.Context, instance *models.MCPSe
 		return fmt.Errorf("missing or invalid endpoint in json_spec")
 	}
 
-	p.logger.Info("Registering URL-based MCP server",
+	p.logger.InfoContext(ctx, "Registering URL-based MCP server",
 		slog.String("instance_id", instance.InstanceID),
 		slog.String("name", instance.Name),
 		slog.String("endpoint", endpoint))
 
 	// Validate the endpoint is reachable
 	if err := p.validateEndpoint(ctx, endpoint, spec); err != nil {
-		p.logger.Error("Failed to validate URL endpoint",
+		p.logger.ErrorContext(ctx, "Failed to validate URL endpoint",
 			slog.String("instance_id", instance.InstanceID),
 			slog.String("endpoint", endpoint),
 			slog.String("error", err.Error()))
 		return fmt.Errorf("endpoint validation failed: %w", err)
 	}
 
-	p.logger.Info("Successfully registered URL-based MCP server",
+	p.logger.InfoContext(ctx, "Successfully registered URL-based MCP server",
 		slog.String("instance_id", instance.InstanceID),
 		slog.String("name", instance.Name),
 		slog.String("endpoint", endpoint))
@@ -60,14 +60,14 @@ func (p *URLProvider) CreateInstance(ctx context.Context, instance *models.MCPSe
 
 // DeleteInstance unregisters the URL-based MCP server
 func (p *URLProvider) DeleteInstance(ctx context.Context, instanceID, name string) error {
-	p.logger.Info("Unregistering URL-based MCP server",
+	p.logger.InfoContext(ctx, "Unregistering URL-based MCP server",
 		slog.String("instance_id", instanceID),
 		slog.String("name", name))
 
 	// For URL-based servers, we just log the deletion
 	// In a more complex setup, we might need to remove from a registry
 
-	p.logger.Info("Successfully unregistered URL-based MCP server",
+	p.logger.InfoContext(ctx, "Successfully unregistered URL-based MCP server",
 		slog.String("instance_id", instanceID),
 		slog.String("name", name))
 
@@ -115,7 +115,7 @@ func (p *URLProvider) validateEndpoint(ctx context.Context, endpoint string, spe
 		healthURL = endpoint + healthPath
 	}
 
-	p.logger.Debug("Validating endpoint",
+	p.logger.DebugContext(ctx, "Validating endpoint",
 		slog.String("endpoint", endpoint),
 		slog.String("health_url", healthURL))
 
@@ -0,0 +1,285 @@
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+)
+
+// Files written to the Traefik config directory
+const (
+	staticConfigFile    = "traefik.yml"
+	dashboardConfigFile = "dashboard.yml"
+)
+
+// Entrypoint names used in the generated static config
+const (
+	webEntryPoint       = "web"
+	webSecureEntryPoint = "websecure"
+	dashboardEntryPoint = "traefik"
+	metricsEntryPoint   = "metrics"
+)
+
+// staticConfig is the subset of Traefik's static configuration the manager generates
+type staticConfig struct {
+	Global      staticGlobal                `yaml:"global"`
+	Log         staticLog                   `yaml:"log"`
+	AccessLog   *staticAccessLog            `yaml:"accessLog,omitempty"`
+	EntryPoints map[string]staticEntryPoint `yaml:"entryPoints"`
+	Providers   staticProviders             `yaml:"providers"`
+	API         *staticAPI                  `yaml:"api,omitempty"`
+	Ping        *staticPing                 `yaml:"ping,omitempty"`
+	Metrics     *staticMetrics              `yaml:"metrics,omitempty"`
+
+	ServersTransport *staticServersTransport `yaml:"serversTransport,omitempty"`
+}
+
+type staticGlobal struct {
+	CheckNewVersion    bool `yaml:"checkNewVersion"`
+	SendAnonymousUsage bool `yaml:"sendAnonymousUsage"`
+}
+
+type staticLog struct {
+	Level string `yaml:"level"`
+}
+
+type staticAccessLog struct {
+	FilePath string `yaml:"filePath,omitempty"`
+	Format   string `yaml:"format"`
+}
+
+type staticEntryPoint struct {
+	Address   string           `yaml:"address"`
+	Transport *staticTransport `yaml:"transport,omitempty"`
+}
+
+type staticTransport struct {
+	RespondingTimeouts staticRespondingTimeouts `yaml:"respondingTimeouts"`
+}
+
+type staticRespondingTimeouts struct {
+	ReadTimeout string `yaml:"readTimeout"`
+}
+
+type staticProviders struct {
+	File staticFileProvider `yaml:"file"`
+}
+
+type staticFileProvider struct {
+	Directory string `yaml:"directory"`
+	Watch     bool   `yaml:"watch"`
+}
+
+type staticAPI struct {
+	Dashboard bool `yaml:"dashboard"`
+	Insecure  bool `yaml:"insecure"`
+}
+
+type staticPing struct {
+	EntryPoint string `yaml:"entryPoint"`
+}
+
+type staticMetrics struct {
+	Prometheus staticPrometheus `yaml:"prometheus"`
+}
+
+type staticPrometheus struct {
+	EntryPoint string `yaml:"entryPoint"`
+}
+
+// staticServersTransport is the default transport of services that do not name their own
+type staticServersTransport struct {
+	MaxIdleConnsPerHost int                       `yaml:"maxIdleConnsPerHost,omitempty"`
+	ForwardingTimeouts  *staticForwardingTimeouts `yaml:"forwardingTimeouts,omitempty"`
+}
+
+type staticForwardingTimeouts struct {
+	DialTimeout     string `yaml:"dialTimeout,omitempty"`
+	IdleConnTimeout string `yaml:"idleConnTimeout,omitempty"`
+}
+
+// ValidateConfig checks the Traefik settings used to generate the static config
+func ValidateConfig(cfg config.TraefikConfig) error {
+	addresses := map[string]string{
+		webEntryPoint:       cfg.WebAddress,
+		webSecureEntryPoint: cfg.WebSecureAddress,
+	}
+	if cfg.Dashboard.Enabled {
+		addresses[dashboardEntryPoint] = cfg.Dashboard.Address
+	}
+	if cfg.Metrics.Enabled {
+		addresses[metricsEntryPoint] = cfg.Metrics.Address
+	}
+
+	seen := make(map[string]string)
+	for name, address := range addresses {
+		port, err := addressPort(address)
+		if err != nil {
+			return fmt.Errorf("invalid %s entrypoint address %q: %w", name, address, err)
+		}
+		if other, exists := seen[port]; exists {
+			return fmt.Errorf("%s and %s entrypoints both listen on port %s", other, name, port)
+		}
+		seen[port] = name
+	}
+
+	switch strings.ToUpper(cfg.LogLevel) {
+	case "DEBUG", "INFO", "WARN", "ERROR", "FATAL", "PANIC":
+	default:
+		return fmt.Errorf("invalid Traefik log level %q", cfg.LogLevel)
+	}
+
+	if cfg.AccessLog.Enabled && cfg.AccessLog.Format != "common" && cfg.AccessLog.Format != "json" {
+		return fmt.Errorf("invalid Traefik access log format %q, expected common or json", cfg.AccessLog.Format)
+	}
+
+	if cfg.Upstream.MaxIdleConnsPerHost < 0 || cfg.Upstream.IdleConnTimeout < 0 || cfg.Upstream.DialTimeout < 0 {
+		return fmt.Errorf("upstream connection pool settings must not be negative")
+	}
+
+	for _, user := range cfg.Dashboard.Users {
+		name, hash, found := strings.Cut(user, ":")
+		if !found || name == "" || hash == "" {
+			return fmt.Errorf("dashboard users must be htpasswd entries in user:hash form")
+		}
+	}
+
+	return nil
+}
+
+// addressPort returns the port of a ":port" or "host:port" listen address
+func addressPort(address string) (string, error) {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", err
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		return "", fmt.Errorf("port must be between 1 and 65535")
+	}
+	return port, nil
+}
+
+// buildStaticConfig generates Traefik's static configuration from the manager config
+func buildStaticConfig(cfg config.TraefikConfig) *staticConfig {
+	// SSE and WebSocket streams stay open far longer than a request read
+	streaming := &staticTransport{
+		RespondingTimeouts: staticRespondingTimeouts{ReadTimeout: "0s"},
+	}
+
+	static := &staticConfig{
+		Log: staticLog{Level: strings.ToUpper(cfg.LogLevel)},
+		EntryPoints: map[string]staticEntryPoint{
+			webEntryPoint:       {Address: cfg.WebAddress, Transport: streaming},
+			webSecureEntryPoint: {Address: cfg.WebSecureAddress, Transport: streaming},
+		},
+		Providers: staticProviders{
+			File: staticFileProvider{Directory: cfg.ConfigDir, Watch: true},
+		},
+	}
+
+	if cfg.AccessLog.Enabled {
+		static.AccessLog = &staticAccessLog{FilePath: cfg.AccessLog.FilePath, Format: cfg.AccessLog.Format}
+	}
+
+	// Without users the dashboard is served unauthenticated; with users a basic auth router guards it
+	if cfg.Dashboard.Enabled {
+		static.EntryPoints[dashboardEntryPoint] = staticEntryPoint{Address: cfg.Dashboard.Address}
+		static.API = &staticAPI{Dashboard: true, Insecure: len(cfg.Dashboard.Users) == 0}
+		// The supervisor's readiness check pings Traefik here; /ping is served outside the routers
+		static.Ping = &staticPing{EntryPoint: dashboardEntryPoint}
+	}
+
+	if cfg.Metrics.Enabled {
+		static.EntryPoints[metricsEntryPoint] = staticEntryPoint{Address: cfg.Metrics.Address}
+		static.Metrics = &staticMetrics{Prometheus: staticPrometheus{EntryPoint: metricsEntryPoint}}
+	}
+
+	static.ServersTransport = buildServersTransport(cfg.Upstream)
+
+	return static
+}
+
+// buildServersTransport returns the default upstream transport, or nil to keep Traefik's defaults
+func buildServersTransport(upstream config.UpstreamPoolConfig) *staticServersTransport {
+	transport := &staticServersTransport{MaxIdleConnsPerHost: upstream.MaxIdleConnsPerHost}
+	if upstream.DialTimeout > 0 || upstream.IdleConnTimeout > 0 {
+		transport.ForwardingTimeouts = &staticForwardingTimeouts{
+			DialTimeout:     durationString(upstream.DialTimeout),
+			IdleConnTimeout: durationString(upstream.IdleConnTimeout),
+		}
+	}
+	if transport.MaxIdleConnsPerHost == 0 && transport.ForwardingTimeouts == nil {
+		return nil
+	}
+	return transport
+}
+
+// durationString formats a duration for Traefik, or "" to leave it unset
+func durationString(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return d.String()
+}
+
+// buildDashboardConfig returns the dynamic config protecting the dashboard with basic auth
+func buildDashboardConfig(cfg config.TraefikConfig) map[string]interface{} {
+	return map[string]interface{}{
+		"http": map[string]interface{}{
+			"routers": map[string]interface{}{
+				"traefik-dashboard": map[string]interface{}{
+					"rule":        "PathPrefix(`/api`) || PathPrefix(`/dashboard`)",
+					"service":     "api@internal",
+					"entryPoints": []string{dashboardEntryPoint},
+					"middlewares": []string{"traefik-dashboard-auth"},
+				},
+			},
+			"middlewares": map[string]interface{}{
+				"traefik-dashboard-auth": map[string]interface{}{
+					"basicAuth": map[string]interface{}{"users": cfg.Dashboard.Users},
+				},
+			},
+		},
+	}
+}
+
+// writeStaticConfig validates the config and writes Traefik's static config and dashboard router
+func writeStaticConfig(cfg config.TraefikConfig) error {
+	if err := ValidateConfig(cfg); err != nil {
+		return err
+	}
+
+	if err := writeYAML(filepath.Join(cfg.ConfigDir, staticConfigFile), buildStaticConfig(cfg)); err != nil {
+		return err
+	}
+
+	dashboardPath := filepath.Join(cfg.ConfigDir, dashboardConfigFile)
+	if cfg.Dashboard.Enabled && len(cfg.Dashboard.Users) > 0 {
+		return writeYAML(dashboardPath, buildDashboardConfig(cfg))
+	}
+	if err := os.Remove(dashboardPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove dashboard config: %w", err)
+	}
+	return nil
+}
+
+// writeYAML marshals v and writes it to path
+func writeYAML(path string, v interface{}) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", filepath.Base(path), err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
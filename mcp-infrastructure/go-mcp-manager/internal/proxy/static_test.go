@@ -0,0 +1,88 @@
+package proxy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+)
+
+func testTraefikConfig(t *testing.T) config.TraefikConfig {
+	return config.TraefikConfig{
+		ConfigDir:        t.TempDir(),
+		WebAddress:       ":80",
+		WebSecureAddress: ":443",
+		LogLevel:         "info",
+		Dashboard:        config.TraefikDashboardConfig{Enabled: true, Address: ":8080"},
+		AccessLog:        config.TraefikAccessLogConfig{Enabled: true, Format: "json"},
+		Metrics:          config.TraefikMetricsConfig{Enabled: true, Address: ":8082"},
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	if err := ValidateConfig(testTraefikConfig(t)); err != nil {
+		t.Fatalf("Expected valid config, got error: %v", err)
+	}
+
+	invalid := map[string]func(*config.TraefikConfig){
+		"bad address":       func(c *config.TraefikConfig) { c.WebAddress = "80" },
+		"port collision":    func(c *config.TraefikConfig) { c.Metrics.Address = ":8080" },
+		"bad log level":     func(c *config.TraefikConfig) { c.LogLevel = "VERBOSE" },
+		"bad log format":    func(c *config.TraefikConfig) { c.AccessLog.Format = "xml" },
+		"bad user entry":    func(c *config.TraefikConfig) { c.Dashboard.Users = []string{"admin"} },
+		"port out of range": func(c *config.TraefikConfig) { c.WebSecureAddress = ":70000" },
+	}
+	for name, mutate := range invalid {
+		cfg := testTraefikConfig(t)
+		mutate(&cfg)
+		if err := ValidateConfig(cfg); err == nil {
+			t.Errorf("Expected error for %s", name)
+		}
+	}
+}
+
+func TestWriteStaticConfig(t *testing.T) {
+	cfg := testTraefikConfig(t)
+	cfg.Dashboard.Users = []string{"admin:$apr1$abc$def"}
+
+	if err := writeStaticConfig(cfg); err != nil {
+		t.Fatalf("Failed to write static config: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(cfg.ConfigDir, staticConfigFile))
+	if err != nil {
+		t.Fatalf("Failed to read static config: %v", err)
+	}
+	var static staticConfig
+	if err := yaml.Unmarshal(data, &static); err != nil {
+		t.Fatalf("Failed to parse static config: %v", err)
+	}
+
+	if static.Log.Level != "INFO" {
+		t.Errorf("Expected log level INFO, got %s", static.Log.Level)
+	}
+	if static.API == nil || static.API.Insecure {
+		t.Errorf("Expected authenticated dashboard, got %+v", static.API)
+	}
+	if static.Metrics == nil || static.EntryPoints[metricsEntryPoint].Address != ":8082" {
+		t.Errorf("Expected metrics entrypoint on :8082")
+	}
+	if static.AccessLog == nil || static.AccessLog.Format != "json" {
+		t.Errorf("Expected json access log, got %+v", static.AccessLog)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.ConfigDir, dashboardConfigFile)); err != nil {
+		t.Errorf("Expected dashboard auth config to be written: %v", err)
+	}
+
+	// Dropping the users removes the auth router again
+	cfg.Dashboard.Users = nil
+	if err := writeStaticConfig(cfg); err != nil {
+		t.Fatalf("Failed to rewrite static config: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.ConfigDir, dashboardConfigFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected dashboard auth config to be removed")
+	}
+}
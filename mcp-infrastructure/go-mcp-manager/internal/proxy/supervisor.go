@@ -0,0 +1,232 @@
+// Package proxy runs and supervises the embedded Traefik reverse proxy.
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// Proxy modes accepted in config.TraefikConfig.Mode
+const (
+	ModeEmbedded = "embedded"
+	ModeExternal = "external"
+)
+
+// Restart backoff bounds for the embedded proxy
+const (
+	initialRestartBackoff = time.Second
+	// stableRunDuration resets the backoff once Traefik has stayed up this long
+	stableRunDuration = time.Minute
+	// stopGracePeriod is how long Traefik gets to exit after SIGTERM before it is killed
+	stopGracePeriod = 10 * time.Second
+)
+
+// Supervisor keeps the embedded Traefik process running, restarting it with backoff when it exits
+type Supervisor struct {
+	cfg    config.TraefikConfig
+	logger *slog.Logger
+
+	// args and initialBackoff are overridable in tests
+	args           []string
+	initialBackoff time.Duration
+
+	mutex  sync.RWMutex
+	status models.ProxyStatus
+}
+
+// NewSupervisor creates a supervisor for the configured proxy mode
+func NewSupervisor(cfg config.TraefikConfig, logger *slog.Logger) *Supervisor {
+	mode := cfg.Mode
+	if mode != ModeExternal {
+		mode = ModeEmbedded
+	}
+
+	state := models.ProxyStateStopped
+	if mode == ModeExternal {
+		state = models.ProxyStateExternal
+	}
+
+	return &Supervisor{
+		cfg:            cfg,
+		logger:         logger,
+		args:           []string{"--configfile=" + filepath.Join(cfg.ConfigDir, staticConfigFile)},
+		initialBackoff: initialRestartBackoff,
+		status:         models.ProxyStatus{Mode: mode, State: state},
+	}
+}
+
+// Run supervises Traefik until ctx is cancelled. In external mode it returns immediately.
+func (s *Supervisor) Run(ctx context.Context) error {
+	if s.Status().Mode == ModeExternal {
+		s.logger.InfoContext(ctx, "Using external Traefik, skipping proxy process management",
+			slog.String("config_dir", s.cfg.ConfigDir))
+		return nil
+	}
+
+	if err := os.MkdirAll(s.cfg.ConfigDir, 0755); err != nil {
+		return fmt.Errorf("failed to create Traefik config directory: %w", err)
+	}
+	if err := writeStaticConfig(s.cfg); err != nil {
+		return fmt.Errorf("failed to create Traefik static config: %w", err)
+	}
+
+	backoff := s.initialBackoff
+	for {
+		startedAt := time.Now()
+		err := s.runOnce(ctx)
+
+		if ctx.Err() != nil {
+			s.setState(models.ProxyStateStopped)
+			return nil
+		}
+
+		// A long healthy run means the next failure is unrelated to the last one
+		if time.Since(startedAt) >= stableRunDuration {
+			backoff = s.initialBackoff
+		}
+
+		s.logger.ErrorContext(ctx, "Traefik exited, restarting",
+			slog.String("error", errorString(err)),
+			slog.Duration("backoff", backoff))
+		s.recordExit(err)
+
+		select {
+		case <-ctx.Done():
+			s.setState(models.ProxyStateStopped)
+			return nil
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if maxBackoff := s.cfg.RestartMaxBackoff; maxBackoff > 0 && backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// runOnce starts Traefik and waits for it to exit
+func (s *Supervisor) runOnce(ctx context.Context) error {
+	s.setState(models.ProxyStateStarting)
+
+	cmd := exec.CommandContext(ctx, s.cfg.Binary, s.args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// Let Traefik drain connections on shutdown instead of killing it outright
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(syscall.SIGTERM)
+	}
+	cmd.WaitDelay = stopGracePeriod
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start Traefik: %w", err)
+	}
+
+	s.mutex.Lock()
+	s.status.State = models.ProxyStateRunning
+	s.status.PID = cmd.Process.Pid
+	s.status.StartedAt = time.Now()
+	s.mutex.Unlock()
+
+	s.logger.InfoContext(ctx, "Traefik started", slog.Int("pid", cmd.Process.Pid))
+
+	if err := cmd.Wait(); err != nil {
+		return err
+	}
+	return fmt.Errorf("traefik exited unexpectedly")
+}
+
+// recordExit marks the proxy as restarting after an unexpected exit
+func (s *Supervisor) recordExit(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.status.Restarts++
+	s.status.State = models.ProxyStateRestarting
+	s.status.PID = 0
+	s.status.LastError = errorString(err)
+}
+
+// setState updates the proxy state, clearing the PID when it is no longer running
+func (s *Supervisor) setState(state string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.status.State = state
+	if state != models.ProxyStateRunning {
+		s.status.PID = 0
+	}
+}
+
+// Status returns a snapshot of the proxy state
+func (s *Supervisor) Status() models.ProxyStatus {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.status
+}
+
+// Healthy reports whether traffic can currently be routed
+func (s *Supervisor) Healthy() bool {
+	state := s.Status().State
+	return state == models.ProxyStateRunning || state == models.ProxyStateExternal
+}
+
+// Ready returns an error unless Traefik can route traffic. The embedded Traefik must also answer
+// its ping endpoint when the dashboard entrypoint is enabled, which catches a hung process.
+func (s *Supervisor) Ready(ctx context.Context) error {
+	status := s.Status()
+	if !s.Healthy() {
+		if status.LastError != "" {
+			return fmt.Errorf("traefik is %s: %s", status.State, status.LastError)
+		}
+		return fmt.Errorf("traefik is %s", status.State)
+	}
+	if status.Mode == ModeExternal || !s.cfg.Dashboard.Enabled {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pingURL(s.cfg.Dashboard.Address), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("traefik ping failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("traefik ping returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// pingURL returns the local ping URL of the entrypoint listening on address
+func pingURL(address string) string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "http://" + address + "/ping"
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, port) + "/ping"
+}
+
+// errorString returns err's message, or "" for nil
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,117 @@
+package proxy
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestSupervisorRestartsExitedProxy(t *testing.T) {
+	binary, err := exec.LookPath("false")
+	if err != nil {
+		t.Skip("false binary not available")
+	}
+
+	cfg := config.TraefikConfig{
+		Mode:              ModeEmbedded,
+		ConfigDir:         t.TempDir(),
+		Binary:            binary,
+		RestartMaxBackoff: 20 * time.Millisecond,
+		WebAddress:        ":80",
+		WebSecureAddress:  ":443",
+		LogLevel:          "INFO",
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	supervisor := NewSupervisor(cfg, logger)
+	supervisor.args = nil
+	supervisor.initialBackoff = 5 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- supervisor.Run(ctx)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for supervisor.Status().Restarts < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatalf("Expected clean shutdown, got %v", err)
+	}
+
+	status := supervisor.Status()
+	if status.Restarts < 3 {
+		t.Errorf("Expected at least 3 restarts, got %d", status.Restarts)
+	}
+	if status.State != models.ProxyStateStopped {
+		t.Errorf("Expected state %s after shutdown, got %s", models.ProxyStateStopped, status.State)
+	}
+	if status.LastError == "" {
+		t.Errorf("Expected last exit error to be recorded")
+	}
+	if _, err := os.Stat(cfg.ConfigDir + "/traefik.yml"); err != nil {
+		t.Errorf("Expected static config to be written: %v", err)
+	}
+}
+
+func TestSupervisorExternalMode(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	supervisor := NewSupervisor(config.TraefikConfig{Mode: ModeExternal, Binary: "does-not-exist"}, logger)
+
+	if err := supervisor.Run(context.Background()); err != nil {
+		t.Fatalf("Expected external mode to skip process management, got %v", err)
+	}
+	if !supervisor.Healthy() {
+		t.Errorf("Expected external proxy to be reported healthy")
+	}
+	if supervisor.Status().State != models.ProxyStateExternal {
+		t.Errorf("Expected state %s, got %s", models.ProxyStateExternal, supervisor.Status().State)
+	}
+}
+
+func TestSupervisorReady(t *testing.T) {
+	pinged := false
+	traefik := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pinged = r.URL.Path == "/ping"
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer traefik.Close()
+
+	cfg := config.TraefikConfig{
+		Mode:      ModeEmbedded,
+		ConfigDir: t.TempDir(),
+		Dashboard: config.TraefikDashboardConfig{Enabled: true, Address: strings.TrimPrefix(traefik.URL, "http://")},
+	}
+	supervisor := NewSupervisor(cfg, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	ctx := context.Background()
+
+	if err := supervisor.Ready(ctx); err == nil {
+		t.Error("Expected a stopped proxy not to be ready")
+	}
+	supervisor.setState(models.ProxyStateRunning)
+	if err := supervisor.Ready(ctx); err != nil || !pinged {
+		t.Errorf("Expected a running proxy answering its ping to be ready, got %v", err)
+	}
+
+	traefik.Close()
+	if err := supervisor.Ready(ctx); err == nil {
+		t.Error("Expected a running proxy that does not answer its ping not to be ready")
+	}
+
+	if got := pingURL(":8080"); got != "http://127.0.0.1:8080/ping" {
+		t.Errorf("Expected a wildcard address to be pinged on loopback, got %s", got)
+	}
+}
@@ -0,0 +1,163 @@
+// Package registration keeps this manager registered with the Core API. The platform lists
+// registered managers, spreads instances across them and detects a manager whose heartbeats stop.
+package registration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// InstanceCounter returns the number of running instances and of all tracked instances
+type InstanceCounter func(ctx context.Context) (running, total int, err error)
+
+// Heartbeat periodically PUTs this manager's registration to the Core API
+type Heartbeat struct {
+	cfg        config.RegistrationConfig
+	target     string
+	token      string
+	httpClient *http.Client
+	logger     *slog.Logger
+	count      InstanceCounter
+
+	registration models.ManagerRegistration
+	// failing and registered track the last outcome, so only changes are logged
+	failing    bool
+	registered bool
+}
+
+// NewHeartbeat creates a heartbeat announcing the capacity in cfg to the Core API at cfg.CoreAPIURL
+func NewHeartbeat(cfg *config.Config, version, backendType string, count InstanceCounter, logger *slog.Logger) *Heartbeat {
+	reg := cfg.Registration
+	path := strings.ReplaceAll(reg.Path, "{manager_id}", url.PathEscape(reg.ManagerID))
+	return &Heartbeat{
+		cfg:        reg,
+		target:     strings.TrimSuffix(cfg.CoreAPIURL, "/") + path,
+		token:      cfg.CoreAPIToken,
+		httpClient: &http.Client{Timeout: reg.Timeout},
+		logger:     logger,
+		count:      count,
+		registration: models.ManagerRegistration{
+			ManagerID:   reg.ManagerID,
+			Version:     version,
+			BackendType: backendType,
+			URL:         reg.AdvertiseURL,
+			Capacity: models.ManagerCapacity{
+				MaxInstances: cfg.Container.MaxContainers,
+				GPUs:         cfg.GPU.Count,
+			},
+			HeartbeatIntervalSeconds: int(reg.Interval / time.Second),
+			StartedAt:                time.Now(),
+		},
+	}
+}
+
+// Run sends a heartbeat right away and then every interval until ctx is cancelled, when the
+// manager deregisters so the platform does not wait for it to time out
+func (h *Heartbeat) Run(ctx context.Context) {
+	if !h.cfg.Enabled {
+		return
+	}
+
+	interval := h.cfg.Interval
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	h.beat(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			h.deregister()
+			return
+		case <-ticker.C:
+			h.beat(ctx)
+		}
+	}
+}
+
+// Registration returns the registration the next heartbeat sends
+func (h *Heartbeat) Registration(ctx context.Context) models.ManagerRegistration {
+	registration := h.registration
+	registration.Timestamp = time.Now()
+	if running, total, err := h.count(ctx); err == nil {
+		registration.RunningInstances = running
+		registration.TotalInstances = total
+	} else {
+		h.logger.WarnContext(ctx, "Failed to count instances for heartbeat", slog.String("error", err.Error()))
+	}
+	return registration
+}
+
+// beat sends a single heartbeat
+func (h *Heartbeat) beat(ctx context.Context) {
+	body, err := json.Marshal(h.Registration(ctx))
+	if err != nil {
+		return
+	}
+
+	err = h.send(ctx, http.MethodPut, body)
+	switch {
+	case err != nil && !h.failing:
+		h.logger.WarnContext(ctx, "Failed to send heartbeat to Core API",
+			slog.String("manager_id", h.cfg.ManagerID),
+			slog.String("url", h.target),
+			slog.String("error", err.Error()))
+	case err != nil:
+		h.logger.DebugContext(ctx, "Core API still not accepting heartbeats", slog.String("error", err.Error()))
+	case h.failing || !h.registered:
+		h.logger.InfoContext(ctx, "Registered manager with Core API",
+			slog.String("manager_id", h.cfg.ManagerID),
+			slog.String("url", h.target))
+	}
+	h.failing = err != nil
+	h.registered = h.registered || err == nil
+}
+
+// deregister removes the registration on shutdown; a failure only delays detection until the heartbeat times out
+func (h *Heartbeat) deregister() {
+	ctx, cancel := context.WithTimeout(context.Background(), h.httpClient.Timeout)
+	defer cancel()
+
+	if err := h.send(ctx, http.MethodDelete, nil); err != nil {
+		h.logger.Warn("Failed to deregister manager from Core API", slog.String("error", err.Error()))
+		return
+	}
+	h.logger.Info("Deregistered manager from Core API", slog.String("manager_id", h.cfg.ManagerID))
+}
+
+// send performs a single request against the registration URL
+func (h *Heartbeat) send(ctx context.Context, method string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, h.target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create heartbeat request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if h.token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.token)
+	}
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("heartbeat request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("core API returned status %d", resp.StatusCode)
+	}
+	return nil
+}
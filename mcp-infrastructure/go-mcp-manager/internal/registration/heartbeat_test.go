@@ -0,0 +1,85 @@
+package registration
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestHeartbeatRegistersAndDeregisters(t *testing.T) {
+	requests := make(chan *http.Request, 4)
+	registrations := make(chan models.ManagerRegistration, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			var registration models.ManagerRegistration
+			_ = json.NewDecoder(r.Body).Decode(&registration)
+			registrations <- registration
+		}
+		requests <- r
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		CoreAPIURL:   server.URL,
+		CoreAPIToken: "manager-token",
+		Registration: config.RegistrationConfig{
+			Enabled:   true,
+			ManagerID: "node-1",
+			Path:      "/v1/managers/{manager_id}",
+			Interval:  time.Hour,
+			Timeout:   time.Second,
+		},
+		Container: config.ContainerConfig{MaxContainers: 50},
+	}
+	count := func(context.Context) (int, int, error) { return 3, 4, nil }
+	heartbeat := NewHeartbeat(cfg, "1.2.3", "docker", count, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		heartbeat.Run(ctx)
+	}()
+
+	select {
+	case registration := <-registrations:
+		r := <-requests
+		if r.URL.Path != "/v1/managers/node-1" {
+			t.Errorf("Expected the manager ID in the path, got %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer manager-token" {
+			t.Errorf("Expected the service token on the heartbeat, got %q", auth)
+		}
+		if registration.Version != "1.2.3" || registration.BackendType != "docker" || registration.Capacity.MaxInstances != 50 {
+			t.Errorf("Expected version, backend and capacity in the registration, got %+v", registration)
+		}
+		if registration.RunningInstances != 3 || registration.TotalInstances != 4 {
+			t.Errorf("Expected 3 of 4 instances running, got %d of %d", registration.RunningInstances, registration.TotalInstances)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Heartbeat was not sent")
+	}
+
+	cancel()
+	<-done
+	select {
+	case r := <-requests:
+		if r.Method != http.MethodDelete {
+			t.Errorf("Expected a DELETE on shutdown, got %s", r.Method)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer manager-token" {
+			t.Errorf("Expected the service token on the deregistration, got %q", auth)
+		}
+	default:
+		t.Error("Expected the manager to deregister on shutdown")
+	}
+}
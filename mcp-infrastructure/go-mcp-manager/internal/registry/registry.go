@@ -0,0 +1,372 @@
+// Package registry reads image manifests and configs from OCI and Docker registries over the
+// distribution API, without pulling layers. Only anonymous access is supported, which covers
+// public images on Docker Hub, GHCR, Quay and most mirrors.
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultRegistry is the registry of references without a registry host
+	DefaultRegistry = "docker.io"
+	// dockerHubHost serves the distribution API for docker.io
+	dockerHubHost = "registry-1.docker.io"
+	// maxDocumentSize bounds the manifests and configs read
+	maxDocumentSize = 4 << 20
+)
+
+// Media types of the manifests the client accepts
+const (
+	mediaTypeOCIIndex       = "application/vnd.oci.image.index.v1+json"
+	mediaTypeOCIManifest    = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeDockerList     = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+	acceptedManifestTypes   = mediaTypeOCIIndex + ", " + mediaTypeDockerList + ", " + mediaTypeOCIManifest + ", " + mediaTypeDockerManifest
+)
+
+// ErrNotFound is returned when the registry has no such repository, tag or digest
+var ErrNotFound = errors.New("image not found in registry")
+
+// PlatformError is returned when a multi-platform image has no variant for the client's platform
+type PlatformError struct {
+	Ref      Reference
+	Platform string
+	// Available are the platforms the image is published for, as "os/architecture"
+	Available []string
+}
+
+func (e *PlatformError) Error() string {
+	return fmt.Sprintf("image %s has no %s variant; it is published for %s", e.Ref, e.Platform, strings.Join(e.Available, ", "))
+}
+
+// Reference is an image reference split into its parts
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseReference splits an image reference such as "ghcr.io/org/server:1.2" or "busybox". A
+// reference without a registry is on Docker Hub, and one without a tag or digest is "latest".
+func ParseReference(ref string) (Reference, error) {
+	var parsed Reference
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return parsed, fmt.Errorf("image reference is empty")
+	}
+
+	name := ref
+	if at := strings.Index(name, "@"); at >= 0 {
+		name, parsed.Digest = name[:at], name[at+1:]
+		if !strings.Contains(parsed.Digest, ":") {
+			return parsed, fmt.Errorf("invalid digest in image reference %q", ref)
+		}
+	}
+	if colon := strings.LastIndex(name, ":"); colon > strings.LastIndex(name, "/") {
+		name, parsed.Tag = name[:colon], name[colon+1:]
+	}
+
+	parsed.Registry = DefaultRegistry
+	if first, rest, found := strings.Cut(name, "/"); found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		parsed.Registry, name = first, rest
+	}
+	if parsed.Registry == DefaultRegistry && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	if name == "" || strings.ContainsAny(name, " \t@") || strings.ToLower(name) != name {
+		return parsed, fmt.Errorf("invalid repository in image reference %q", ref)
+	}
+	parsed.Repository = name
+	if parsed.Tag == "" && parsed.Digest == "" {
+		parsed.Tag = "latest"
+	}
+	return parsed, nil
+}
+
+// String returns the fully qualified reference
+func (r Reference) String() string {
+	s := r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// manifestRef is the tag or digest the manifest is requested by
+func (r Reference) manifestRef() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+// apiHost is the host serving the registry's distribution API
+func (r Reference) apiHost() string {
+	if r.Registry == DefaultRegistry {
+		return dockerHubHost
+	}
+	return r.Registry
+}
+
+// Image is what a registry says about an image without its layers
+type Image struct {
+	// Digest is the digest of the platform manifest
+	Digest       string      `json:"digest"`
+	Architecture string      `json:"architecture"`
+	OS           string      `json:"os"`
+	Config       ImageConfig `json:"config"`
+	// Size is the compressed size of the layers, which is what a pull downloads
+	Size int64 `json:"size"`
+}
+
+// ImageConfig is the runtime configuration an image carries
+type ImageConfig struct {
+	ExposedPorts map[string]struct{} `json:"ExposedPorts"`
+	Env          []string            `json:"Env"`
+	Entrypoint   []string            `json:"Entrypoint"`
+	Cmd          []string            `json:"Cmd"`
+	WorkingDir   string              `json:"WorkingDir"`
+	User         string              `json:"User"`
+	Labels       map[string]string   `json:"Labels"`
+}
+
+// manifest is an image manifest or an index of them
+type manifest struct {
+	MediaType string `json:"mediaType"`
+	Config    struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+	Layers []struct {
+		Size int64 `json:"size"`
+	} `json:"layers"`
+	Manifests []struct {
+		Digest   string `json:"digest"`
+		Platform struct {
+			Architecture string `json:"architecture"`
+			OS           string `json:"os"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
+// Client reads manifests and configs from registries
+type Client struct {
+	httpClient *http.Client
+	// OS and Architecture select the image from multi-platform indexes
+	OS           string
+	Architecture string
+}
+
+// NewClient returns a client for the platform the manager runs on
+func NewClient(timeout time.Duration) *Client {
+	return NewClientWithHTTP(&http.Client{Timeout: timeout})
+}
+
+// NewClientWithHTTP returns a client sending its requests with httpClient
+func NewClientWithHTTP(httpClient *http.Client) *Client {
+	return &Client{httpClient: httpClient, OS: "linux", Architecture: runtime.GOARCH}
+}
+
+// Image fetches the manifest and config of ref, choosing the client's platform from an index
+func (c *Client) Image(ctx context.Context, ref Reference) (*Image, error) {
+	session := &session{client: c, ref: ref}
+
+	var m manifest
+	digest, err := session.getJSON(ctx, "manifests/"+ref.manifestRef(), acceptedManifestTypes, &m)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Manifests) > 0 {
+		platformDigest := ""
+		for _, candidate := range m.Manifests {
+			if candidate.Platform.OS == c.OS && candidate.Platform.Architecture == c.Architecture {
+				platformDigest = candidate.Digest
+				break
+			}
+		}
+		if platformDigest == "" {
+			platformErr := &PlatformError{Ref: ref, Platform: c.OS + "/" + c.Architecture}
+			for _, candidate := range m.Manifests {
+				// Attestation manifests are listed with an unknown platform
+				if candidate.Platform.OS != "" && candidate.Platform.OS != "unknown" {
+					platformErr.Available = append(platformErr.Available, candidate.Platform.OS+"/"+candidate.Platform.Architecture)
+				}
+			}
+			return nil, platformErr
+		}
+		m = manifest{}
+		if digest, err = session.getJSON(ctx, "manifests/"+platformDigest, acceptedManifestTypes, &m); err != nil {
+			return nil, err
+		}
+		if digest == "" {
+			digest = platformDigest
+		}
+	}
+	if m.Config.Digest == "" {
+		return nil, fmt.Errorf("manifest of %s has no config", ref)
+	}
+
+	var config struct {
+		Architecture string      `json:"architecture"`
+		OS           string      `json:"os"`
+		Config       ImageConfig `json:"config"`
+	}
+	if _, err := session.getJSON(ctx, "blobs/"+m.Config.Digest, "", &config); err != nil {
+		return nil, err
+	}
+
+	image := &Image{
+		Digest:       digest,
+		Architecture: config.Architecture,
+		OS:           config.OS,
+		Config:       config.Config,
+	}
+	for _, layer := range m.Layers {
+		image.Size += layer.Size
+	}
+	return image, nil
+}
+
+// session requests one repository, reusing the bearer token a registry asked for
+type session struct {
+	client *Client
+	ref    Reference
+	token  string
+}
+
+// getJSON decodes the document at path under the repository and returns its content digest
+func (s *session) getJSON(ctx context.Context, path, accept string, value interface{}) (string, error) {
+	endpoint := fmt.Sprintf("https://%s/v2/%s/%s", s.ref.apiHost(), s.ref.Repository, path)
+	response, err := s.get(ctx, endpoint, accept)
+	if err != nil {
+		return "", err
+	}
+	if response.StatusCode == http.StatusUnauthorized && s.token == "" {
+		challenge := response.Header.Get("WWW-Authenticate")
+		response.Body.Close()
+		if s.token, err = s.authenticate(ctx, challenge); err != nil {
+			return "", err
+		}
+		if response, err = s.get(ctx, endpoint, accept); err != nil {
+			return "", err
+		}
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%w: %s", ErrNotFound, s.ref)
+	case response.StatusCode == http.StatusUnauthorized, response.StatusCode == http.StatusForbidden:
+		return "", fmt.Errorf("registry %s requires credentials for %s", s.ref.Registry, s.ref.Repository)
+	case response.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("registry %s returned status %d for %s", s.ref.Registry, response.StatusCode, path)
+	}
+	if err := json.NewDecoder(io.LimitReader(response.Body, maxDocumentSize)).Decode(value); err != nil {
+		return "", fmt.Errorf("failed to decode %s from %s: %w", path, s.ref.Registry, err)
+	}
+	return response.Header.Get("Docker-Content-Digest"), nil
+}
+
+// get sends a GET with the session's token, if any
+func (s *session) get(ctx context.Context, endpoint, accept string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+	if s.token != "" {
+		request.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	response, err := s.client.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach registry %s: %w", s.ref.Registry, err)
+	}
+	return response, nil
+}
+
+// authenticate fetches an anonymous pull token from the realm of a Bearer challenge
+func (s *session) authenticate(ctx context.Context, challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("registry %s requires credentials for %s", s.ref.Registry, s.ref.Repository)
+	}
+	fields := parseChallenge(params)
+	realm, err := url.Parse(fields["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("registry %s sent an invalid token realm %q", s.ref.Registry, fields["realm"])
+	}
+	query := realm.Query()
+	if service := fields["service"]; service != "" {
+		query.Set("service", service)
+	}
+	scope := fields["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", s.ref.Repository)
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	response, err := s.get(ctx, realm.String(), "")
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry %s refused an anonymous token for %s: status %d", s.ref.Registry, s.ref.Repository, response.StatusCode)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(response.Body, maxDocumentSize)).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode token from %s: %w", s.ref.Registry, err)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	if token.AccessToken != "" {
+		return token.AccessToken, nil
+	}
+	return "", fmt.Errorf("registry %s returned an empty token", s.ref.Registry)
+}
+
+// parseChallenge parses the comma-separated key="value" pairs of a WWW-Authenticate challenge
+func parseChallenge(params string) map[string]string {
+	fields := make(map[string]string)
+	for params != "" {
+		var pair string
+		// Values are quoted and may contain commas, as scopes do
+		key, rest, found := strings.Cut(params, "=")
+		if !found {
+			break
+		}
+		key = strings.TrimSpace(strings.TrimLeft(key, ", "))
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				fields[key] = rest[1:]
+				break
+			}
+			pair, params = rest[1:end+1], rest[end+2:]
+		} else {
+			pair, params, _ = strings.Cut(rest, ",")
+		}
+		fields[key] = pair
+	}
+	return fields
+}
@@ -0,0 +1,104 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	cases := map[string]string{
+		"busybox":                        "docker.io/library/busybox:latest",
+		"mcp/github:1.2":                 "docker.io/mcp/github:1.2",
+		"ghcr.io/org/server":             "ghcr.io/org/server:latest",
+		"localhost:5000/server:dev":      "localhost:5000/server:dev",
+		"quay.io/org/server@sha256:abcd": "quay.io/org/server@sha256:abcd",
+	}
+	for ref, expected := range cases {
+		parsed, err := ParseReference(ref)
+		if err != nil || parsed.String() != expected {
+			t.Errorf("Expected %s to parse as %s, got %s (%v)", ref, expected, parsed, err)
+		}
+	}
+	for _, ref := range []string{"", "Upper/Case", "server@nodigest"} {
+		if _, err := ParseReference(ref); err == nil {
+			t.Errorf("Expected %q to be rejected", ref)
+		}
+	}
+}
+
+func TestImage(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if r.URL.Query().Get("scope") != "repository:org/server:pull" {
+				t.Errorf("Expected a pull scope for the repository, got %q", r.URL.Query().Get("scope"))
+			}
+			json.NewEncoder(w).Encode(map[string]string{"token": "anonymous"})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer anonymous" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="test",scope="repository:org/server:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/org/server/manifests/1.0":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"manifests": []map[string]interface{}{
+					{"digest": "sha256:arm", "platform": map[string]string{"os": "linux", "architecture": "arm64"}},
+					{"digest": "sha256:amd", "platform": map[string]string{"os": "linux", "architecture": "amd64"}},
+				},
+			})
+		case "/v2/org/server/manifests/sha256:amd":
+			w.Header().Set("Docker-Content-Digest", "sha256:amd")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"config": map[string]string{"digest": "sha256:config"},
+				"layers": []map[string]int64{{"size": 100}, {"size": 23}},
+			})
+		case "/v2/org/server/blobs/sha256:config":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"architecture": "amd64",
+				"os":           "linux",
+				"config": map[string]interface{}{
+					"ExposedPorts": map[string]struct{}{"3000/tcp": {}},
+					"Labels":       map[string]string{"mcp.transport": "streamable-http"},
+				},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client())
+	client.Architecture = "amd64"
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	ref, _ := ParseReference(host + "/org/server:1.0")
+	image, err := client.Image(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("Expected the image config, got %v", err)
+	}
+	if image.Digest != "sha256:amd" || image.Size != 123 || image.Architecture != "amd64" {
+		t.Errorf("Expected the amd64 manifest with 123 bytes of layers, got %+v", image)
+	}
+	if _, exposed := image.Config.ExposedPorts["3000/tcp"]; !exposed || image.Config.Labels["mcp.transport"] != "streamable-http" {
+		t.Errorf("Expected the config's ports and labels, got %+v", image.Config)
+	}
+
+	client.Architecture = "s390x"
+	var platformErr *PlatformError
+	if _, err := client.Image(context.Background(), ref); !errors.As(err, &platformErr) || strings.Join(platformErr.Available, ",") != "linux/arm64,linux/amd64" {
+		t.Errorf("Expected a PlatformError listing the published platforms, got %v", err)
+	}
+
+	missing, _ := ParseReference(host + "/org/server:2.0")
+	if _, err := client.Image(context.Background(), missing); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing tag, got %v", err)
+	}
+}
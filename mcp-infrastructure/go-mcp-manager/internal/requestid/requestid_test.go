@@ -0,0 +1,36 @@
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	traceID := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected trace ID to be extracted, got %q", traceID)
+	}
+
+	if ParseTraceparent("not-a-traceparent") != "" {
+		t.Error("Expected invalid traceparent to be rejected")
+	}
+	if ParseTraceparent("00-00000000000000000000000000000000-00f067aa0ba902b7-01") != "" {
+		t.Error("Expected all-zero trace ID to be rejected")
+	}
+}
+
+func TestHandlerAddsContextAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewTextHandler(&buf, nil)))
+
+	ctx := WithTraceID(WithRequestID(context.Background(), "req-123"), "trace-abc")
+	logger.InfoContext(ctx, "provisioning")
+
+	output := buf.String()
+	if !strings.Contains(output, "request_id=req-123") || !strings.Contains(output, "trace_id=trace-abc") {
+		t.Errorf("Expected correlation attributes in log output, got %q", output)
+	}
+}
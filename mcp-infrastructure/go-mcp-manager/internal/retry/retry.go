@@ -0,0 +1,221 @@
+// Package retry retries calls to the manager's external dependencies with exponential backoff
+// and jitter. Each dependency has a retry budget that calls refill, so a dependency that keeps
+// failing is not also flooded with retries.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Dependencies whose calls are retried
+const (
+	Podman  = "podman"
+	Redis   = "redis"
+	Secrets = "secrets"
+	CoreAPI = "core_api"
+)
+
+// Policy controls how often and how long a failed call is retried
+type Policy struct {
+	// MaxAttempts counts the first call; 1 disables retries
+	MaxAttempts    int           `json:"max_attempts"`
+	InitialBackoff time.Duration `json:"initial_backoff"`
+	MaxBackoff     time.Duration `json:"max_backoff"`
+	// BudgetRatio is the retry budget each call adds, e.g. 0.2 allows one retry per five calls
+	BudgetRatio float64 `json:"budget_ratio"`
+	// BudgetMin is the retries a dependency may always make, and the most the budget holds
+	BudgetMin float64 `json:"budget_min"`
+}
+
+// DefaultPolicy is used for dependencies until Configure is called
+var DefaultPolicy = Policy{
+	MaxAttempts:    3,
+	InitialBackoff: 250 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+	BudgetRatio:    0.2,
+	BudgetMin:      10,
+}
+
+// Backoff returns how long to wait before retrying after the given failed attempt: the initial
+// backoff doubled per attempt up to the maximum, with the upper half of it randomized so
+// callers failing together do not retry together
+func (p Policy) Backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < attempt && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	half := backoff / 2
+	return half + rand.N(backoff-half+1)
+}
+
+// Stats reports the calls and retries made to a dependency
+type Stats struct {
+	Dependency string `json:"dependency"`
+	Calls      int64  `json:"calls"`
+	Retries    int64  `json:"retries"`
+	// Failures are calls that failed after their last attempt
+	Failures int64 `json:"failures"`
+	// BudgetExhausted counts retries skipped because the budget ran out
+	BudgetExhausted int64   `json:"budget_exhausted"`
+	BudgetRemaining float64 `json:"budget_remaining"`
+}
+
+// Retrier retries calls to one dependency within its budget
+type Retrier struct {
+	mutex  sync.Mutex
+	policy Policy
+	budget float64
+	stats  Stats
+}
+
+var (
+	registryMutex sync.Mutex
+	registry      = map[string]*Retrier{}
+	configured    = DefaultPolicy
+)
+
+// Configure sets the policy of every dependency
+func Configure(policy Policy) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	configured = policy
+	for _, r := range registry {
+		r.mutex.Lock()
+		r.policy = policy
+		r.budget = min(r.budget, policy.BudgetMin)
+		r.mutex.Unlock()
+	}
+}
+
+// For returns the retrier of a dependency
+func For(dependency string) *Retrier {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	r, exists := registry[dependency]
+	if !exists {
+		r = &Retrier{policy: configured, budget: configured.BudgetMin, stats: Stats{Dependency: dependency}}
+		registry[dependency] = r
+	}
+	return r
+}
+
+// Snapshot returns the stats of every dependency called so far, by name
+func Snapshot() []Stats {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	snapshot := make([]Stats, 0, len(registry))
+	for _, r := range registry {
+		snapshot = append(snapshot, r.Stats())
+	}
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].Dependency < snapshot[j].Dependency })
+	return snapshot
+}
+
+// Stats returns the dependency's stats
+func (r *Retrier) Stats() Stats {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	stats := r.stats
+	stats.BudgetRemaining = r.budget
+	return stats
+}
+
+// Option adjusts the policy of a single call
+type Option func(*Policy)
+
+// MaxAttempts overrides the attempts of a call, for callers with their own setting
+func MaxAttempts(attempts int) Option {
+	return func(p *Policy) {
+		p.MaxAttempts = attempts
+	}
+}
+
+// Do calls fn until it succeeds, returns a permanent error, runs out of attempts or budget, or
+// ctx is done. It returns fn's last error.
+func (r *Retrier) Do(ctx context.Context, fn func(ctx context.Context) error, opts ...Option) error {
+	r.mutex.Lock()
+	policy := r.policy
+	r.stats.Calls++
+	r.budget = min(r.budget+policy.BudgetRatio, policy.BudgetMin)
+	r.mutex.Unlock()
+	for _, opt := range opts {
+		opt(&policy)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			r.fail()
+			return permanent.err
+		}
+		if attempt >= policy.MaxAttempts || ctx.Err() != nil || !r.withdraw() {
+			r.fail()
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			r.fail()
+			return err
+		case <-time.After(policy.Backoff(attempt)):
+		}
+	}
+}
+
+// withdraw takes one retry from the budget
+func (r *Retrier) withdraw() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.budget < 1 {
+		r.stats.BudgetExhausted++
+		return false
+	}
+	r.budget--
+	r.stats.Retries++
+	return true
+}
+
+// fail counts a call that gave up
+func (r *Retrier) fail() {
+	r.mutex.Lock()
+	r.stats.Failures++
+	r.mutex.Unlock()
+}
+
+// permanentError marks an error retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent stops Do from retrying err; Do returns err itself
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// RetryableStatus reports whether an HTTP status may succeed when the request is repeated
+func RetryableStatus(code int) bool {
+	return code == http.StatusRequestTimeout || code == http.StatusTooManyRequests || code >= 500
+}
@@ -0,0 +1,85 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	policy := Policy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	bounds := map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 10: time.Second}
+	for attempt, upper := range bounds {
+		for range 20 {
+			backoff := policy.Backoff(attempt)
+			if backoff < upper/2 || backoff > upper {
+				t.Errorf("Expected attempt %d to back off between %s and %s, got %s", attempt, upper/2, upper, backoff)
+			}
+		}
+	}
+}
+
+func TestDo(t *testing.T) {
+	r := &Retrier{policy: Policy{MaxAttempts: 3, InitialBackoff: time.Millisecond, BudgetRatio: 0.5, BudgetMin: 2}, budget: 2}
+	ctx := context.Background()
+
+	calls := 0
+	err := r.Do(ctx, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("Expected success on the third attempt, got %d calls, %v", calls, err)
+	}
+
+	notFound := errors.New("not found")
+	calls = 0
+	err = r.Do(ctx, func(context.Context) error {
+		calls++
+		return Permanent(notFound)
+	})
+	if err != notFound || calls != 1 {
+		t.Errorf("Expected a permanent error to be returned unretried, got %d calls, %v", calls, err)
+	}
+
+	// Two calls earned one retry since the budget was spent
+	calls = 0
+	r.Do(ctx, func(context.Context) error {
+		calls++
+		return errors.New("connection refused")
+	})
+	if calls != 2 {
+		t.Errorf("Expected the budget to allow a single retry, got %d calls", calls)
+	}
+
+	stats := r.Stats()
+	if stats.Calls != 3 || stats.Retries != 3 || stats.Failures != 2 || stats.BudgetExhausted != 1 {
+		t.Errorf("Expected 3 calls, 3 retries, 2 failures and 1 exhausted budget, got %+v", stats)
+	}
+}
+
+func TestFor(t *testing.T) {
+	if For(Redis) != For(Redis) {
+		t.Error("Expected a dependency to share one retrier")
+	}
+	Configure(Policy{MaxAttempts: 1, BudgetMin: 5})
+	defer Configure(DefaultPolicy)
+
+	calls := 0
+	For(Redis).Do(context.Background(), func(context.Context) error {
+		calls++
+		return errors.New("connection refused")
+	})
+	if calls != 1 {
+		t.Errorf("Expected the configured policy to disable retries, got %d calls", calls)
+	}
+	for _, stats := range Snapshot() {
+		if stats.Dependency == Redis && stats.Failures != 1 {
+			t.Errorf("Expected the failure in the snapshot, got %+v", stats)
+		}
+	}
+}
@@ -3,12 +3,17 @@ package secrets
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"strings"
 
 	infisical "github.com/infisical/go-sdk"
+	infisicalerrors "github.com/infisical/go-sdk/packages/errors"
+
+	"github.com/agentarea/mcp-manager/internal/retry"
 )
 
 // InfisicalConfig represents the bootstrap configuration structure
@@ -47,6 +52,7 @@ type Credentials struct {
 // SecretResolver handles secure secret resolution using Infisical SDK
 type SecretResolver struct {
 	client      infisical.InfisicalClientInterface
+	siteURL     string
 	logger      *slog.Logger
 	projectID   string
 	environment string
@@ -119,6 +125,7 @@ func NewSecretResolver(logger *slog.Logger) (*SecretResolver, error) {
 
 	return &SecretResolver{
 		client:      client,
+		siteURL:     strings.TrimSuffix(infisicalURL, "/"),
 		logger:      logger,
 		projectID:   projectID,
 		environment: environment,
@@ -172,12 +179,21 @@ func (sr *SecretResolver) resolveSecretFromInfisical(instanceID, secretKey strin
 		return "", fmt.Errorf("Infisical client not initialized - secret resolution not available for: %s", infisicalSecretKey)
 	}
 
-	// Retrieve secret from Infisical
-	secret, err := sr.client.Secrets().Retrieve(infisical.RetrieveSecretOptions{
-		SecretKey:   infisicalSecretKey,
-		ProjectID:   sr.projectID,
-		Environment: sr.environment,
-		SecretPath:  "/", // Default path
+	// Retrieve secret from Infisical, retrying outages but not answers such as a missing secret
+	var secret infisical.Secret
+	err := retry.For(retry.Secrets).Do(context.Background(), func(context.Context) error {
+		var err error
+		secret, err = sr.client.Secrets().Retrieve(infisical.RetrieveSecretOptions{
+			SecretKey:   infisicalSecretKey,
+			ProjectID:   sr.projectID,
+			Environment: sr.environment,
+			SecretPath:  "/", // Default path
+		})
+		var apiErr *infisicalerrors.APIError
+		if errors.As(err, &apiErr) && !retry.RetryableStatus(apiErr.StatusCode) {
+			return retry.Permanent(err)
+		}
+		return err
 	})
 
 	if err != nil {
@@ -197,6 +213,53 @@ func (sr *SecretResolver) resolveSecretFromInfisical(instanceID, secretKey strin
 	return secret.SecretValue, nil
 }
 
+// Configured reports whether secrets are resolved from Infisical rather than unavailable
+func (sr *SecretResolver) Configured() bool {
+	return sr.client != nil
+}
+
+// Ping reports whether Infisical answers its status endpoint
+func (sr *SecretResolver) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sr.siteURL+"/api/status", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("infisical status request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("infisical returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SelfTest looks up a secret that does not exist. Infisical answering "not found" shows it is
+// reachable and accepts the manager's token for the project.
+func (sr *SecretResolver) SelfTest(ctx context.Context) error {
+	if err := sr.Ping(ctx); err != nil {
+		return err
+	}
+	_, err := sr.client.Secrets().Retrieve(infisical.RetrieveSecretOptions{
+		SecretKey:   "mcp_manager_doctor_probe",
+		ProjectID:   sr.projectID,
+		Environment: sr.environment,
+		SecretPath:  "/",
+	})
+	var apiErr *infisicalerrors.APIError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+		return nil
+	case errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden):
+		return fmt.Errorf("infisical rejected the manager's token for project %s, environment %s: %w", sr.projectID, sr.environment, err)
+	default:
+		return fmt.Errorf("failed to look up a secret in Infisical: %w", err)
+	}
+}
+
 // Close closes the secret resolver
 func (sr *SecretResolver) Close() error {
 	sr.logger.Info("Closing Infisical secret resolver")
@@ -0,0 +1,358 @@
+// Package serverjson translates the server.json documents of the MCP registry into what the
+// manager runs: an OCI image serving HTTP, or an npm or PyPI package behind the stdio bridge.
+package serverjson
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// Package registry types the manager can run
+const (
+	RegistryOCI  = "oci"
+	RegistryNPM  = "npm"
+	RegistryPyPI = "pypi"
+)
+
+// Transport types a package or remote declares
+const (
+	transportStdio = "stdio"
+	transportSSE   = "sse"
+)
+
+// defaultPort is used when an HTTP transport URL does not say which port the server listens on
+const defaultPort = 8000
+
+// placeholderPattern matches the {variable} placeholders of transport URLs and argument values
+var placeholderPattern = regexp.MustCompile(`\{[A-Za-z0-9_.-]+\}`)
+
+// ErrNoRunnablePackage is returned when a server publishes no package the manager can run,
+// such as one that is only offered as a hosted remote
+var ErrNoRunnablePackage = errors.New("server.json has no oci, npm or pypi package the manager can run")
+
+// MissingInputsError lists the required environment variables, arguments and headers a
+// translation was not given values for
+type MissingInputsError struct {
+	Environment []string
+	Arguments   []string
+	Headers     []string
+}
+
+func (e *MissingInputsError) Error() string {
+	var parts []string
+	if len(e.Environment) > 0 {
+		parts = append(parts, "environment variables "+strings.Join(e.Environment, ", "))
+	}
+	if len(e.Arguments) > 0 {
+		parts = append(parts, "arguments "+strings.Join(e.Arguments, ", "))
+	}
+	if len(e.Headers) > 0 {
+		parts = append(parts, "headers "+strings.Join(e.Headers, ", "))
+	}
+	return "server requires values for " + strings.Join(parts, " and ")
+}
+
+// Server is a server.json document as the MCP registry publishes it
+type Server struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Version     string    `json:"version,omitempty"`
+	Packages    []Package `json:"packages,omitempty"`
+	Remotes     []Remote  `json:"remotes,omitempty"`
+}
+
+// Package is one way a server is distributed
+type Package struct {
+	RegistryType    string `json:"registryType"`
+	RegistryBaseURL string `json:"registryBaseUrl,omitempty"`
+	Identifier      string `json:"identifier"`
+	Version         string `json:"version,omitempty"`
+	// RuntimeHint names the launcher, e.g. "npx", "uvx" or "docker"; the manager picks its own
+	RuntimeHint          string     `json:"runtimeHint,omitempty"`
+	Transport            Transport  `json:"transport"`
+	RuntimeArguments     []Argument `json:"runtimeArguments,omitempty"`
+	PackageArguments     []Argument `json:"packageArguments,omitempty"`
+	EnvironmentVariables []Input    `json:"environmentVariables,omitempty"`
+}
+
+// Transport is how a package or remote speaks MCP
+type Transport struct {
+	Type    string  `json:"type"`
+	URL     string  `json:"url,omitempty"`
+	Headers []Input `json:"headers,omitempty"`
+}
+
+// Remote is a hosted endpoint of the server
+type Remote struct {
+	Type    string  `json:"type"`
+	URL     string  `json:"url"`
+	Headers []Input `json:"headers,omitempty"`
+}
+
+// Input is an environment variable or header the server reads
+type Input struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	IsRequired  bool     `json:"isRequired,omitempty"`
+	IsSecret    bool     `json:"isSecret,omitempty"`
+	Format      string   `json:"format,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Value       string   `json:"value,omitempty"`
+	Choices     []string `json:"choices,omitempty"`
+}
+
+// Argument is a positional or named command line argument
+type Argument struct {
+	Type       string `json:"type"`
+	Name       string `json:"name,omitempty"`
+	Value      string `json:"value,omitempty"`
+	Default    string `json:"default,omitempty"`
+	ValueHint  string `json:"valueHint,omitempty"`
+	IsRequired bool   `json:"isRequired,omitempty"`
+	IsRepeated bool   `json:"isRepeated,omitempty"`
+}
+
+// Options are the caller's choices and values for a translation
+type Options struct {
+	// RegistryType selects the package to run; empty prefers oci, then npm, then pypi
+	RegistryType string
+	Environment  map[string]string
+	// Arguments give values to package arguments, keyed by name or value hint
+	Arguments map[string]string
+	Headers   map[string]string
+}
+
+// Translation is a runnable spec: exactly one of Image and Runtime is set
+type Translation struct {
+	Image   string
+	Runtime *models.RuntimeConfig
+	Port    int
+	Command []string
+	// Transport is the route transport the server speaks
+	Transport   string
+	Environment map[string]string
+	EnvSchema   []models.EnvVarSpec
+	// RequestHeaders are set by the proxy on every request to the server
+	RequestHeaders map[string]string
+	// Package is the package that was chosen
+	Package Package
+}
+
+// Translate turns server into a runnable spec, filling environment variables, arguments and
+// headers from opts or their defaults. Values the caller passes for undeclared environment
+// variables are kept, since servers often read more than they declare.
+func Translate(server *Server, opts Options) (*Translation, error) {
+	if server == nil {
+		return nil, fmt.Errorf("server.json is required")
+	}
+	pkg, err := selectPackage(server.Packages, opts.RegistryType)
+	if errors.Is(err, ErrNoRunnablePackage) && len(server.Remotes) > 0 {
+		return nil, fmt.Errorf("%w; it is hosted at %s and needs no container", err, server.Remotes[0].URL)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	translation := &Translation{Package: *pkg}
+	missing := &MissingInputsError{}
+
+	args := packageArguments(pkg.PackageArguments, opts.Arguments, missing)
+	switch pkg.RegistryType {
+	case RegistryOCI:
+		if pkg.Transport.Type == transportStdio || pkg.Transport.Type == "" {
+			return nil, fmt.Errorf("oci package %s speaks stdio; only images serving streamable-http or sse can be run", pkg.Identifier)
+		}
+		translation.Image = imageReference(pkg)
+		translation.Command = args
+		translation.Port = transportPort(pkg.Transport.URL)
+	case RegistryNPM, RegistryPyPI:
+		if pkg.Transport.Type != transportStdio && pkg.Transport.Type != "" {
+			return nil, fmt.Errorf("%s package %s speaks %s; only stdio packages can be run behind the bridge", pkg.RegistryType, pkg.Identifier, pkg.Transport.Type)
+		}
+		runtime := &models.RuntimeConfig{Type: models.RuntimeNpx, Package: pkg.Identifier, Version: pkg.Version, Args: args}
+		if pkg.RegistryType == RegistryPyPI {
+			runtime.Type = models.RuntimeUvx
+		}
+		translation.Runtime = runtime
+		translation.Port = defaultPort
+	}
+	translation.Transport = routeTransport(pkg.Transport.Type)
+
+	translation.Environment, translation.EnvSchema = environment(pkg.EnvironmentVariables, opts.Environment, missing)
+	translation.RequestHeaders = headers(pkg.Transport.Headers, opts.Headers, missing)
+
+	if len(missing.Environment)+len(missing.Arguments)+len(missing.Headers) > 0 {
+		return nil, missing
+	}
+	return translation, nil
+}
+
+// selectPackage returns the package of registryType, or the first runnable one by preference
+func selectPackage(packages []Package, registryType string) (*Package, error) {
+	preference := []string{RegistryOCI, RegistryNPM, RegistryPyPI}
+	if registryType != "" {
+		if !slices.Contains(preference, registryType) {
+			return nil, fmt.Errorf("registry type %q cannot be run; use oci, npm or pypi", registryType)
+		}
+		preference = []string{registryType}
+	}
+	for _, kind := range preference {
+		for i := range packages {
+			if packages[i].RegistryType == kind && packages[i].Identifier != "" {
+				return &packages[i], nil
+			}
+		}
+	}
+	if registryType != "" {
+		return nil, fmt.Errorf("server.json has no %s package", registryType)
+	}
+	return nil, ErrNoRunnablePackage
+}
+
+// imageReference returns the package's image, tagged with its version when the identifier has no
+// tag or digest
+func imageReference(pkg *Package) string {
+	image := pkg.Identifier
+	if base := strings.TrimPrefix(strings.TrimPrefix(pkg.RegistryBaseURL, "https://"), "http://"); base != "" && !strings.Contains(strings.SplitN(image, "/", 2)[0], ".") {
+		image = strings.TrimSuffix(base, "/") + "/" + image
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if pkg.Version != "" && !strings.Contains(name, ":") && !strings.Contains(image, "@") {
+		image += ":" + pkg.Version
+	}
+	return image
+}
+
+// transportPort returns the port of an HTTP transport URL such as "http://localhost:8080/mcp"
+func transportPort(rawURL string) int {
+	parsed, err := url.Parse(placeholderPattern.ReplaceAllString(rawURL, "placeholder"))
+	if err != nil || parsed.Host == "" {
+		return defaultPort
+	}
+	if port, err := strconv.Atoi(parsed.Port()); err == nil && port > 0 {
+		return port
+	}
+	if parsed.Port() == "" && parsed.Scheme == "http" {
+		return 80
+	}
+	return defaultPort
+}
+
+// routeTransport maps the package transport to the route transport the proxy serves. The stdio
+// bridge serves streamable HTTP.
+func routeTransport(transport string) string {
+	if transport == transportSSE {
+		return models.TransportSSE
+	}
+	return models.TransportStreamableHTTP
+}
+
+// packageArguments returns the package's command line, recording required arguments left empty
+func packageArguments(declared []Argument, values map[string]string, missing *MissingInputsError) []string {
+	var args []string
+	for _, arg := range declared {
+		key := arg.Name
+		if arg.Type == "positional" || key == "" {
+			key = arg.ValueHint
+		}
+		value, given := values[key]
+		if key == "" {
+			given = false
+		}
+		if !given {
+			value = arg.Value
+			if value == "" {
+				value = arg.Default
+			}
+		}
+		// Placeholders refer to variables the registry leaves to the client; they are not filled
+		if placeholderPattern.MatchString(value) && !given {
+			value = ""
+		}
+
+		switch {
+		case value != "" && arg.Type == "named":
+			args = append(args, arg.Name, value)
+		case value != "":
+			args = append(args, value)
+		case arg.Type == "named" && arg.ValueHint == "" && arg.Default == "" && arg.Value == "":
+			// A named argument that declares no value is a flag
+			args = append(args, arg.Name)
+		case arg.IsRequired:
+			missing.Arguments = append(missing.Arguments, key)
+		}
+	}
+	return args
+}
+
+// environment fills the declared variables from values or their defaults and returns them with
+// their schema
+func environment(declared []Input, values map[string]string, missing *MissingInputsError) (map[string]string, []models.EnvVarSpec) {
+	env := make(map[string]string, len(declared)+len(values))
+	schema := make([]models.EnvVarSpec, 0, len(declared))
+	for _, variable := range declared {
+		schema = append(schema, models.EnvVarSpec{
+			Name:        variable.Name,
+			Description: variable.Description,
+			Required:    variable.IsRequired,
+			Secret:      variable.IsSecret,
+			Format:      variable.Format,
+			Default:     variable.Default,
+			Choices:     variable.Choices,
+		})
+		if value := inputValue(variable, values); value != "" {
+			env[variable.Name] = value
+		} else if variable.IsRequired {
+			missing.Environment = append(missing.Environment, variable.Name)
+		}
+	}
+	for name, value := range values {
+		if _, exists := env[name]; !exists && value != "" {
+			env[name] = value
+		}
+	}
+	return env, schema
+}
+
+// headers fills the declared headers from values or their defaults. Headers the caller passes
+// without a declaration are kept, as for environment variables.
+func headers(declared []Input, values map[string]string, missing *MissingInputsError) map[string]string {
+	result := make(map[string]string, len(declared)+len(values))
+	for _, header := range declared {
+		if value := inputValue(header, values); value != "" {
+			result[header.Name] = value
+		} else if header.IsRequired {
+			missing.Headers = append(missing.Headers, header.Name)
+		}
+	}
+	for name, value := range values {
+		if _, exists := result[name]; !exists && value != "" {
+			result[name] = value
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// inputValue returns the caller's value for input, or its fixed value or default. Values with
+// unfilled placeholders count as unset.
+func inputValue(input Input, values map[string]string) string {
+	if value, given := values[input.Name]; given {
+		return value
+	}
+	for _, value := range []string{input.Value, input.Default} {
+		if value != "" && !placeholderPattern.MatchString(value) {
+			return value
+		}
+	}
+	return ""
+}
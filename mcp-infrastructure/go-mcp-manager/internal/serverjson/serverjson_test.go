@@ -0,0 +1,85 @@
+package serverjson
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+const braveSearch = `{
+	"name": "io.github.brave/brave-search-mcp-server",
+	"version": "2.0.0",
+	"packages": [
+		{
+			"registryType": "npm",
+			"identifier": "@brave/brave-search-mcp-server",
+			"version": "2.0.0",
+			"runtimeHint": "npx",
+			"transport": {"type": "stdio"},
+			"packageArguments": [{"type": "named", "name": "--transport", "value": "stdio"}],
+			"environmentVariables": [
+				{"name": "BRAVE_API_KEY", "isRequired": true, "isSecret": true},
+				{"name": "BRAVE_LOG_LEVEL", "default": "info"}
+			]
+		},
+		{
+			"registryType": "oci",
+			"registryBaseUrl": "https://docker.io",
+			"identifier": "brave/brave-search-mcp",
+			"version": "2.0.0",
+			"transport": {
+				"type": "streamable-http",
+				"url": "http://localhost:8080/mcp",
+				"headers": [{"name": "X-Tenant", "default": "default"}]
+			},
+			"environmentVariables": [{"name": "BRAVE_API_KEY", "isRequired": true, "isSecret": true}]
+		}
+	]
+}`
+
+func TestTranslate(t *testing.T) {
+	var server Server
+	if err := json.Unmarshal([]byte(braveSearch), &server); err != nil {
+		t.Fatalf("Failed to decode server.json: %v", err)
+	}
+	env := map[string]string{"BRAVE_API_KEY": "secret_ref:brave"}
+
+	translation, err := Translate(&server, Options{Environment: env})
+	if err != nil {
+		t.Fatalf("Expected the oci package to translate, got %v", err)
+	}
+	if translation.Image != "docker.io/brave/brave-search-mcp:2.0.0" || translation.Port != 8080 {
+		t.Errorf("Expected the tagged image on port 8080, got %s on %d", translation.Image, translation.Port)
+	}
+	if translation.Transport != models.TransportStreamableHTTP || translation.RequestHeaders["X-Tenant"] != "default" {
+		t.Errorf("Expected streamable HTTP with the default header, got %s and %v", translation.Transport, translation.RequestHeaders)
+	}
+
+	translation, err = Translate(&server, Options{RegistryType: RegistryNPM, Environment: env})
+	if err != nil {
+		t.Fatalf("Expected the npm package to translate, got %v", err)
+	}
+	expected := &models.RuntimeConfig{Type: models.RuntimeNpx, Package: "@brave/brave-search-mcp-server", Version: "2.0.0", Args: []string{"--transport", "stdio"}}
+	if translation.Image != "" || !reflect.DeepEqual(translation.Runtime, expected) {
+		t.Errorf("Expected runtime %+v, got %+v", expected, translation.Runtime)
+	}
+	if translation.Environment["BRAVE_LOG_LEVEL"] != "info" || translation.Environment["BRAVE_API_KEY"] != "secret_ref:brave" {
+		t.Errorf("Expected the given and default environment, got %v", translation.Environment)
+	}
+	if len(translation.EnvSchema) != 2 || !translation.EnvSchema[0].Required || !translation.EnvSchema[0].Secret {
+		t.Errorf("Expected the declared variables in the schema, got %+v", translation.EnvSchema)
+	}
+
+	var missing *MissingInputsError
+	if _, err := Translate(&server, Options{RegistryType: RegistryNPM}); !errors.As(err, &missing) || len(missing.Environment) != 1 {
+		t.Errorf("Expected BRAVE_API_KEY to be reported missing, got %v", err)
+	}
+
+	hosted := &Server{Name: "hosted", Remotes: []Remote{{Type: "streamable-http", URL: "https://mcp.example.com"}}}
+	if _, err := Translate(hosted, Options{}); !errors.Is(err, ErrNoRunnablePackage) {
+		t.Errorf("Expected a remote-only server to have no runnable package, got %v", err)
+	}
+}
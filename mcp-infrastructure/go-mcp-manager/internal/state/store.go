@@ -0,0 +1,195 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Store is a small file-backed key/value store for manager state that must survive restarts.
+// Records are grouped in buckets, each persisted as a single JSON document under the store
+// directory. An empty directory keeps everything in memory.
+type Store struct {
+	dir     string
+	mutex   sync.Mutex
+	buckets map[string]map[string]json.RawMessage
+}
+
+// NewStore creates a store rooted at dir
+func NewStore(dir string) *Store {
+	return &Store{
+		dir:     dir,
+		buckets: make(map[string]map[string]json.RawMessage),
+	}
+}
+
+// Check returns an error unless the store directory is writable. An in-memory store always is.
+func (s *Store) Check() error {
+	if s.dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	probe, err := os.CreateTemp(s.dir, ".check-*")
+	if err != nil {
+		return fmt.Errorf("state directory is not writable: %w", err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
+// Put stores value under key in bucket
+func (s *Store) Put(bucket, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s/%s: %w", bucket, key, err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	records, err := s.load(bucket)
+	if err != nil {
+		return err
+	}
+	records[key] = data
+	return s.flush(bucket, records)
+}
+
+// Get decodes the value stored under key into value and reports whether it exists
+func (s *Store) Get(bucket, key string, value interface{}) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	records, err := s.load(bucket)
+	if err != nil {
+		return false, err
+	}
+	data, exists := records[key]
+	if !exists {
+		return false, nil
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return true, fmt.Errorf("failed to decode %s/%s: %w", bucket, key, err)
+	}
+	return true, nil
+}
+
+// Has reports whether key exists in bucket
+func (s *Store) Has(bucket, key string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	records, err := s.load(bucket)
+	if err != nil {
+		return false
+	}
+	_, exists := records[key]
+	return exists
+}
+
+// Delete removes key from bucket
+func (s *Store) Delete(bucket, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	records, err := s.load(bucket)
+	if err != nil {
+		return err
+	}
+	if _, exists := records[key]; !exists {
+		return nil
+	}
+	delete(records, key)
+	return s.flush(bucket, records)
+}
+
+// Keys returns the sorted keys of bucket
+func (s *Store) Keys(bucket string) ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	records, err := s.load(bucket)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// List returns a copy of every raw record in bucket
+func (s *Store) List(bucket string) (map[string]json.RawMessage, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	records, err := s.load(bucket)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]json.RawMessage, len(records))
+	for key, data := range records {
+		result[key] = data
+	}
+	return result, nil
+}
+
+// load returns the cached bucket, reading it from disk on first access. Callers hold the mutex.
+func (s *Store) load(bucket string) (map[string]json.RawMessage, error) {
+	if records, exists := s.buckets[bucket]; exists {
+		return records, nil
+	}
+
+	records := make(map[string]json.RawMessage)
+	if s.dir != "" {
+		data, err := os.ReadFile(s.path(bucket))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read state bucket %s: %w", bucket, err)
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &records); err != nil {
+				return nil, fmt.Errorf("failed to parse state bucket %s: %w", bucket, err)
+			}
+		}
+	}
+
+	s.buckets[bucket] = records
+	return records, nil
+}
+
+// flush atomically writes the bucket to disk. Callers hold the mutex.
+func (s *Store) flush(bucket string, records map[string]json.RawMessage) error {
+	if s.dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state bucket %s: %w", bucket, err)
+	}
+
+	tmpPath := s.path(bucket) + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write state bucket %s: %w", bucket, err)
+	}
+	if err := os.Rename(tmpPath, s.path(bucket)); err != nil {
+		return fmt.Errorf("failed to replace state bucket %s: %w", bucket, err)
+	}
+	return nil
+}
+
+// path returns the file backing bucket
+func (s *Store) path(bucket string) string {
+	return filepath.Join(s.dir, bucket+".json")
+}
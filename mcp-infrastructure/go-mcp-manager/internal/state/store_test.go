@@ -0,0 +1,43 @@
+package state
+
+import (
+	"testing"
+)
+
+func TestStorePersistsAcrossInstances(t *testing.T) {
+	dir := t.TempDir()
+
+	store := NewStore(dir)
+	if err := store.Put("archived", "svc-a", map[string]string{"slug": "svc-a-1234"}); err != nil {
+		t.Fatalf("Expected put to succeed, got %v", err)
+	}
+
+	reopened := NewStore(dir)
+	var record map[string]string
+	found, err := reopened.Get("archived", "svc-a", &record)
+	if err != nil || !found {
+		t.Fatalf("Expected record to be found after reopen, got found=%v err=%v", found, err)
+	}
+	if record["slug"] != "svc-a-1234" {
+		t.Errorf("Expected slug svc-a-1234, got %s", record["slug"])
+	}
+
+	if err := reopened.Delete("archived", "svc-a"); err != nil {
+		t.Fatalf("Expected delete to succeed, got %v", err)
+	}
+	if NewStore(dir).Has("archived", "svc-a") {
+		t.Error("Expected record to be gone after delete")
+	}
+}
+
+func TestInMemoryStore(t *testing.T) {
+	store := NewStore("")
+	if err := store.Put("bucket", "key", 42); err != nil {
+		t.Fatalf("Expected put to succeed, got %v", err)
+	}
+
+	keys, err := store.Keys("bucket")
+	if err != nil || len(keys) != 1 || keys[0] != "key" {
+		t.Errorf("Expected [key], got %v (err %v)", keys, err)
+	}
+}
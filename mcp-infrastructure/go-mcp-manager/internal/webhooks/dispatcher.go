@@ -0,0 +1,260 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+)
+
+// EventType identifies a lifecycle or health transition delivered to webhooks
+type EventType string
+
+const (
+	EventContainerCreated   EventType = "container.created"
+	EventContainerFailed    EventType = "container.failed"
+	EventContainerUnhealthy EventType = "container.unhealthy"
+	EventContainerRecovered EventType = "container.recovered"
+	EventContainerDeleted   EventType = "container.deleted"
+	EventContainerStopped   EventType = "container.stopped"
+	EventContainerStarted   EventType = "container.started"
+	// EventContainerPreempted is sent when a container was stopped to make room for a higher-priority one
+	EventContainerPreempted EventType = "container.preempted"
+	// EventContainerCrashLoop is sent when a container kept exiting and is no longer restarted
+	EventContainerCrashLoop EventType = "container.crash_loop"
+	// EventRouteChanged is sent when a container's proxy upstream was re-registered after an IP change
+	EventRouteChanged EventType = "container.route_changed"
+	// EventBudgetThreshold is sent when a workspace or the host used 80% or 100% of a budget
+	EventBudgetThreshold EventType = "budget.threshold"
+)
+
+// SignatureHeader carries the hex encoded HMAC-SHA256 of the request body
+const SignatureHeader = "X-MCP-Manager-Signature"
+
+// Event is the payload POSTed to registered webhook URLs
+type Event struct {
+	ID          string    `json:"id"`
+	Type        EventType `json:"type"`
+	InstanceID  string    `json:"instance_id,omitempty"`
+	ServiceName string    `json:"service_name"`
+	ContainerID string    `json:"container_id,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	URL         string    `json:"url,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	// WorkspaceID and Threshold describe budget events; the workspace is empty for the global budget
+	WorkspaceID string    `json:"workspace_id,omitempty"`
+	Threshold   int       `json:"threshold,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// Webhook is a registered notification target
+type Webhook struct {
+	ID        string      `json:"id"`
+	URL       string      `json:"url"`
+	Secret    string      `json:"-"`
+	Events    []EventType `json:"events,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Accepts reports whether the webhook is subscribed to the given event type
+func (w *Webhook) Accepts(eventType EventType) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Dispatcher delivers lifecycle events to registered webhooks
+type Dispatcher struct {
+	webhooks   map[string]*Webhook
+	mutex      sync.RWMutex
+	httpClient *http.Client
+	maxRetries int
+	logger     *slog.Logger
+}
+
+// NewDispatcher creates a dispatcher and registers the webhooks from configuration
+func NewDispatcher(cfg config.WebhookConfig, logger *slog.Logger) *Dispatcher {
+	d := &Dispatcher{
+		webhooks: make(map[string]*Webhook),
+		httpClient: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+		maxRetries: cfg.MaxRetries,
+		logger:     logger,
+	}
+
+	for _, url := range cfg.URLs {
+		if url == "" {
+			continue
+		}
+		d.Register(url, cfg.Secret, nil)
+	}
+
+	return d
+}
+
+// Register adds a new webhook and returns it
+func (d *Dispatcher) Register(url, secret string, events []EventType) *Webhook {
+	webhook := &Webhook{
+		ID:        generateID("wh"),
+		URL:       url,
+		Secret:    secret,
+		Events:    events,
+		CreatedAt: time.Now(),
+	}
+
+	d.mutex.Lock()
+	d.webhooks[webhook.ID] = webhook
+	d.mutex.Unlock()
+
+	d.logger.Info("Registered webhook",
+		slog.String("webhook_id", webhook.ID),
+		slog.String("url", url),
+		slog.Bool("signed", secret != ""))
+
+	return webhook
+}
+
+// Unregister removes a webhook by ID
+func (d *Dispatcher) Unregister(id string) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if _, exists := d.webhooks[id]; !exists {
+		return fmt.Errorf("webhook %s not found", id)
+	}
+	delete(d.webhooks, id)
+	return nil
+}
+
+// List returns all registered webhooks ordered by creation time
+func (d *Dispatcher) List() []Webhook {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	webhooks := make([]Webhook, 0, len(d.webhooks))
+	for _, w := range d.webhooks {
+		webhooks = append(webhooks, *w)
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+	return webhooks
+}
+
+// Notify delivers the event asynchronously to every subscribed webhook
+func (d *Dispatcher) Notify(event Event) {
+	if event.ID == "" {
+		event.ID = generateID("whe")
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	d.mutex.RLock()
+	targets := make([]*Webhook, 0, len(d.webhooks))
+	for _, w := range d.webhooks {
+		if w.Accepts(event.Type) {
+			targets = append(targets, w)
+		}
+	}
+	d.mutex.RUnlock()
+
+	for _, w := range targets {
+		go d.deliver(w, event)
+	}
+}
+
+// deliver POSTs the event to a single webhook, retrying with linear backoff
+func (d *Dispatcher) deliver(webhook *Webhook, event Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		d.logger.Error("Failed to marshal webhook event",
+			slog.String("event_id", event.ID),
+			slog.String("error", err.Error()))
+		return
+	}
+
+	attempts := d.maxRetries + 1
+	for attempt := 1; attempt <= attempts; attempt++ {
+		err = d.send(webhook, event, body)
+		if err == nil {
+			d.logger.Debug("Delivered webhook event",
+				slog.String("webhook_id", webhook.ID),
+				slog.String("event_id", event.ID),
+				slog.String("type", string(event.Type)))
+			return
+		}
+
+		if attempt < attempts {
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+	}
+
+	d.logger.Warn("Failed to deliver webhook event",
+		slog.String("webhook_id", webhook.ID),
+		slog.String("url", webhook.URL),
+		slog.String("event_id", event.ID),
+		slog.String("type", string(event.Type)),
+		slog.Int("attempts", attempts),
+		slog.String("error", err.Error()))
+}
+
+// send performs a single delivery attempt
+func (d *Dispatcher) send(webhook *Webhook, event Event, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d.httpClient.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-MCP-Manager-Event", string(event.Type))
+	req.Header.Set("X-MCP-Manager-Delivery", event.ID)
+	if webhook.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(webhook.Secret, body))
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign computes the hex encoded HMAC-SHA256 of body using secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// generateID generates a random identifier with the given prefix
+func generateID(prefix string) string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return prefix + "_" + hex.EncodeToString(b)
+}
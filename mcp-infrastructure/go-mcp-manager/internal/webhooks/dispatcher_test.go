@@ -0,0 +1,64 @@
+package webhooks
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/internal/config"
+)
+
+func TestNotifySignsPayload(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	dispatcher := NewDispatcher(config.WebhookConfig{
+		URLs:    []string{server.URL},
+		Secret:  "top-secret",
+		Timeout: 2 * time.Second,
+	}, logger)
+
+	dispatcher.Notify(Event{Type: EventContainerCreated, ServiceName: "github"})
+
+	select {
+	case r := <-received:
+		body := <-bodies
+		expected := "sha256=" + Sign("top-secret", body)
+		if got := r.Header.Get(SignatureHeader); got != expected {
+			t.Errorf("Expected signature %s, got %s", expected, got)
+		}
+		if got := r.Header.Get("X-MCP-Manager-Event"); got != string(EventContainerCreated) {
+			t.Errorf("Expected event header %s, got %s", EventContainerCreated, got)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Webhook was not delivered")
+	}
+}
+
+func TestWebhookEventFilter(t *testing.T) {
+	webhook := &Webhook{Events: []EventType{EventContainerFailed}}
+
+	if !webhook.Accepts(EventContainerFailed) {
+		t.Error("Expected webhook to accept subscribed event")
+	}
+	if webhook.Accepts(EventContainerCreated) {
+		t.Error("Expected webhook to reject unsubscribed event")
+	}
+
+	all := &Webhook{}
+	if !all.Accepts(EventContainerDeleted) {
+		t.Error("Expected webhook without filter to accept all events")
+	}
+}
@@ -0,0 +1,247 @@
+// Package client is a Go SDK for the MCP Manager HTTP API.
+//
+// The client is versioned together with pkg/models and pkg/events: breaking changes to its
+// exported API only happen in a new major version of the module.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+// Client talks to a single MCP Manager
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient overrides the HTTP client used for requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAPIKey sends the given key in the X-API-Key header
+func WithAPIKey(apiKey string) Option {
+	return func(c *Client) {
+		c.apiKey = apiKey
+	}
+}
+
+// New creates a client for the manager at baseURL, e.g. "http://mcp-manager:8000"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError is returned when the manager answers with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Response   models.ErrorResponse
+	// RetryAfter is set when the manager asked the caller to back off
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.Response.Message != "" {
+		return fmt.Sprintf("mcp-manager: %s (%d): %s", e.Response.Error, e.StatusCode, e.Response.Message)
+	}
+	return fmt.Sprintf("mcp-manager: unexpected status %d", e.StatusCode)
+}
+
+// Health returns the manager health status
+func (c *Client) Health(ctx context.Context) (*models.HealthResponse, error) {
+	var health models.HealthResponse
+	if err := c.do(ctx, http.MethodGet, "/health", nil, &health); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// ListContainers lists managed containers, optionally including archived ones
+func (c *Client) ListContainers(ctx context.Context, includeArchived bool) (*models.ListContainersResponse, error) {
+	path := "/containers"
+	if includeArchived {
+		path += "?include=archived"
+	}
+
+	var list models.ListContainersResponse
+	if err := c.do(ctx, http.MethodGet, path, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetContainer returns a container by service name
+func (c *Client) GetContainer(ctx context.Context, serviceName string) (*models.Container, error) {
+	var container models.Container
+	if err := c.do(ctx, http.MethodGet, "/containers/"+url.PathEscape(serviceName), nil, &container); err != nil {
+		return nil, err
+	}
+	return &container, nil
+}
+
+// CreateContainer creates a container
+func (c *Client) CreateContainer(ctx context.Context, req models.CreateContainerRequest) (*models.Container, error) {
+	var container models.Container
+	if err := c.do(ctx, http.MethodPost, "/containers", req, &container); err != nil {
+		return nil, err
+	}
+	return &container, nil
+}
+
+// DeleteContainer stops and removes a container
+func (c *Client) DeleteContainer(ctx context.Context, serviceName string) error {
+	return c.do(ctx, http.MethodDelete, "/containers/"+url.PathEscape(serviceName), nil, nil)
+}
+
+// StopContainer stops a container while keeping its slug and route configuration
+func (c *Client) StopContainer(ctx context.Context, serviceName string) (*models.Container, error) {
+	var container models.Container
+	if err := c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(serviceName)+"/stop", nil, &container); err != nil {
+		return nil, err
+	}
+	return &container, nil
+}
+
+// StartContainer starts a stopped container and re-enables its route
+func (c *Client) StartContainer(ctx context.Context, serviceName string) (*models.Container, error) {
+	var container models.Container
+	if err := c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(serviceName)+"/start", nil, &container); err != nil {
+		return nil, err
+	}
+	return &container, nil
+}
+
+// CloneContainer creates a new container from an existing container's effective spec
+func (c *Client) CloneContainer(ctx context.Context, serviceName string, req models.CloneContainerRequest) (*models.CloneContainerResponse, error) {
+	var clone models.CloneContainerResponse
+	if err := c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(serviceName)+"/clone", req, &clone); err != nil {
+		return nil, err
+	}
+	return &clone, nil
+}
+
+// ArchiveContainer moves a stopped container to cold storage
+func (c *Client) ArchiveContainer(ctx context.Context, serviceName string) (*models.ArchivedContainer, error) {
+	var archived models.ArchivedContainer
+	if err := c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(serviceName)+"/archive", nil, &archived); err != nil {
+		return nil, err
+	}
+	return &archived, nil
+}
+
+// UnarchiveContainer restores and starts an archived container
+func (c *Client) UnarchiveContainer(ctx context.Context, serviceName string) (*models.Container, error) {
+	var container models.Container
+	if err := c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(serviceName)+"/unarchive", nil, &container); err != nil {
+		return nil, err
+	}
+	return &container, nil
+}
+
+// GetConnectionContract describes how to connect to a container through the proxy
+func (c *Client) GetConnectionContract(ctx context.Context, serviceName string) (*models.ConnectionContract, error) {
+	var contract models.ConnectionContract
+	if err := c.do(ctx, http.MethodGet, "/containers/"+url.PathEscape(serviceName)+"/connection", nil, &contract); err != nil {
+		return nil, err
+	}
+	return &contract, nil
+}
+
+// GetJob returns the state of a background job such as an image build
+func (c *Client) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	var job models.Job
+	if err := c.do(ctx, http.MethodGet, "/jobs/"+url.PathEscape(id), nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetJobLogs returns the output a background job has written so far
+func (c *Client) GetJobLogs(ctx context.Context, id string) (*models.JobLogsResponse, error) {
+	var logs models.JobLogsResponse
+	if err := c.do(ctx, http.MethodGet, "/jobs/"+url.PathEscape(id)+"/logs", nil, &logs); err != nil {
+		return nil, err
+	}
+	return &logs, nil
+}
+
+// RunGC removes unused images and build cache now; with dryRun it only reports what would be removed
+func (c *Client) RunGC(ctx context.Context, dryRun bool) (*models.GCReport, error) {
+	var report models.GCReport
+	body := map[string]bool{"dry_run": dryRun}
+	if err := c.do(ctx, http.MethodPost, "/admin/gc", body, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// do performs a JSON request and decodes the response into out when non-nil
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to mcp-manager failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr.Response)
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/agentarea/mcp-manager/pkg/models"
+)
+
+func TestCreateContainerRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "secret" {
+			t.Errorf("Expected API key header, got %q", r.Header.Get("X-API-Key"))
+		}
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Error:   "too_many_requests",
+			Code:    http.StatusTooManyRequests,
+			Message: "too many concurrent create operations",
+		})
+	}))
+	defer server.Close()
+
+	c := New(server.URL, WithAPIKey("secret"))
+	_, err := c.CreateContainer(context.Background(), models.CreateContainerRequest{
+		ServiceName: "svc",
+		Image:       "nginx:latest",
+		Port:        80,
+	})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429, got %d", apiErr.StatusCode)
+	}
+	if apiErr.RetryAfter != 7*time.Second {
+		t.Errorf("Expected retry after 7s, got %s", apiErr.RetryAfter)
+	}
+}
+
+func TestListContainersIncludeArchived(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("include") != "archived" {
+			t.Errorf("Expected include=archived, got %q", r.URL.RawQuery)
+		}
+		json.NewEncoder(w).Encode(models.ListContainersResponse{
+			Containers: []models.Container{{ServiceName: "live"}},
+			Total:      1,
+			Archived:   []models.ArchivedContainer{{Container: models.Container{ServiceName: "old"}}},
+		})
+	}))
+	defer server.Close()
+
+	list, err := New(server.URL).ListContainers(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if list.Total != 1 || len(list.Archived) != 1 || list.Archived[0].ServiceName != "old" {
+		t.Errorf("Unexpected list response: %+v", list)
+	}
+}
@@ -0,0 +1,129 @@
+// Package events defines the Redis event schemas exchanged between the MCP Manager and
+// the AgentArea platform.
+//
+// These types are part of the manager's public API. Within a major version, channel names
+// and JSON field names are never renamed or removed; new optional fields may be added, so
+// consumers should ignore fields they do not recognise.
+package events
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Redis channels used by the MCP Manager
+const (
+	// ChannelInstanceCreated carries platform requests to provision an MCP server instance
+	ChannelInstanceCreated = "MCPServerInstanceCreated"
+	// ChannelInstanceDeleted carries platform requests to tear an instance down
+	ChannelInstanceDeleted = "MCPServerInstanceDeleted"
+	// ChannelStatusChanged carries status updates published by the manager
+	ChannelStatusChanged = "MCPServerInstanceStatusChanged"
+	// ChannelError carries provisioning and runtime errors published by the manager
+	ChannelError = "MCPServerInstanceError"
+	// ChannelWarning carries non-fatal conditions the manager corrected or wants surfaced
+	ChannelWarning = "MCPServerInstanceWarning"
+)
+
+// Instance statuses reported on ChannelStatusChanged
+const (
+	StatusValidating   = "validating"
+	StatusStarting     = "starting"
+	StatusRunning      = "running"
+	StatusUnhealthy    = "unhealthy"
+	StatusRescheduling = "rescheduling"
+	StatusFailed       = "failed"
+	// StatusInitializing and StatusInitFailed report the init command run before the server starts
+	StatusInitializing = "initializing"
+	StatusInitFailed   = "init_failed"
+	// StatusBuilding reports that the instance image is being built from source
+	StatusBuilding = "building"
+	// StatusPreempted reports that the instance was stopped to make room for a higher-priority one
+	StatusPreempted = "preempted"
+	// StatusOverBudget reports that the instance was stopped because a budget was exhausted
+	StatusOverBudget = "over_budget"
+	// StatusCrashLoop reports that the instance kept exiting and is no longer restarted
+	StatusCrashLoop = "crash_loop"
+)
+
+// StatusUpdateEvent represents a container status update event
+type StatusUpdateEvent struct {
+	InstanceID  string    `json:"instance_id"`
+	Name        string    `json:"name"`
+	Status      string    `json:"status"`
+	ContainerID string    `json:"container_id,omitempty"`
+	URL         string    `json:"url,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// ErrorEvent represents a container error event
+type ErrorEvent struct {
+	InstanceID string    `json:"instance_id"`
+	Name       string    `json:"name"`
+	Error      string    `json:"error"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// WarningEvent represents a non-fatal condition on an instance, e.g. a route that had to be re-registered
+type WarningEvent struct {
+	InstanceID string    `json:"instance_id"`
+	Name       string    `json:"name"`
+	Code       string    `json:"code"`
+	Message    string    `json:"message"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// MCPServerInstanceCreated represents the event when an MCP instance is created
+type MCPServerInstanceCreated struct {
+	InstanceID   string         `json:"instance_id"`
+	Name         string         `json:"name"`
+	ServerSpecID string         `json:"server_spec_id,omitempty"`
+	WorkspaceID  string         `json:"workspace_id,omitempty"`
+	JSONSpec     map[string]any `json:"json_spec"`
+}
+
+// MCPServerInstanceDeleted represents the event when an MCP instance is deleted
+type MCPServerInstanceDeleted struct {
+	InstanceID string `json:"instance_id"`
+	Name       string `json:"name"`
+}
+
+// Event signatures. When the manager is configured to verify events, create and delete requests
+// must carry HeaderSignature in the envelope headers, covering the envelope's data string as sent:
+//   - "sha256=<hex>": HMAC-SHA256 of the data with the shared secret, see HMACSignature
+//   - a JWT signed with a key of the platform's JWKS, whose ClaimDataSHA256 claim is the hex
+//     SHA-256 of the data
+//
+// The inner event's event_id and timestamp are required too, as signed events are accepted once
+// and only while recent. The timestamp is Unix seconds, as the platform's broker sends it, or
+// RFC 3339, where one without a zone is taken as UTC.
+const (
+	HeaderSignature = "signature"
+	ClaimDataSHA256 = "data_sha256"
+)
+
+// HMACSignature returns the HeaderSignature value of data signed with secret
+func HMACSignature(secret []byte, data string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(data))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// EventMessage represents the wrapper structure from FastStream Redis
+type EventMessage struct {
+	Data    string         `json:"data"`
+	Headers map[string]any `json:"headers"`
+}
+
+// EventData represents the inner event data structure
+type EventData struct {
+	EventID   string `json:"event_id"`
+	Timestamp string `json:"timestamp"`
+	EventType string `json:"event_type"`
+	// SchemaVersion selects the payload structs Data decodes into; see DecodeData
+	SchemaVersion int            `json:"schema_version,omitempty"`
+	Data          map[string]any `json:"data"`
+}
@@ -0,0 +1,80 @@
+package events
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Schema versions of the events the manager consumes
+const (
+	// SchemaVersionLegacy is the version of events published before versioning, which carry no
+	// schema_version and are decoded leniently while the platform migrates
+	SchemaVersionLegacy = 0
+	// SchemaVersion1 decodes data into MCPServerInstanceCreated and MCPServerInstanceDeleted
+	SchemaVersion1 = 1
+	// CurrentSchemaVersion is the version publishers should send
+	CurrentSchemaVersion = SchemaVersion1
+)
+
+// DeadLetterList is the Redis list consumed events the manager could not process are pushed to
+const DeadLetterList = "MCPServerInstanceEventsDLQ"
+
+// ErrUnknownSchemaVersion is returned for events of a schema version the manager does not know
+var ErrUnknownSchemaVersion = errors.New("unknown event schema version")
+
+// Schemas holds the JSON Schema of every consumed event, named <channel>.v<version>.json
+//
+//go:embed schemas/*.json
+var Schemas embed.FS
+
+// DeadLetter is an event the manager rejected, kept on DeadLetterList for inspection and replay
+type DeadLetter struct {
+	Channel       string    `json:"channel"`
+	Payload       string    `json:"payload"`
+	Reason        string    `json:"reason"`
+	EventID       string    `json:"event_id,omitempty"`
+	SchemaVersion int       `json:"schema_version,omitempty"`
+	ReceivedAt    time.Time `json:"received_at"`
+}
+
+// Validate checks the fields a create request cannot do without
+func (e *MCPServerInstanceCreated) Validate() error {
+	if e.InstanceID == "" {
+		return errors.New("instance_id is required")
+	}
+	if e.Name == "" {
+		return errors.New("name is required")
+	}
+	if e.JSONSpec == nil {
+		return errors.New("json_spec is required")
+	}
+	return nil
+}
+
+// Validate checks the fields a delete request cannot do without
+func (e *MCPServerInstanceDeleted) Validate() error {
+	if e.InstanceID == "" {
+		return errors.New("instance_id is required")
+	}
+	return nil
+}
+
+// DecodeData decodes the data of a versioned event into payload, which must be the struct of
+// the event's channel, and validates it. Fields payload does not know are ignored.
+func DecodeData(eventData EventData, payload interface{ Validate() error }) error {
+	if eventData.SchemaVersion != SchemaVersion1 {
+		return fmt.Errorf("%w %d", ErrUnknownSchemaVersion, eventData.SchemaVersion)
+	}
+
+	data, err := json.Marshal(eventData.Data)
+	if err != nil {
+		return fmt.Errorf("failed to encode event data: %w", err)
+	}
+	if err := json.Unmarshal(data, payload); err != nil {
+		return fmt.Errorf("event data does not match schema version %d: %w", eventData.SchemaVersion, err)
+	}
+	return payload.Validate()
+}
@@ -0,0 +1,6 @@
+// Package models holds the request and response types of the MCP Manager HTTP API.
+//
+// It is importable by other Go services and follows the module's semantic version: within
+// a major version exported types and JSON field names are only ever added to, never renamed
+// or removed. Implementation details stay under internal/ and carry no such guarantee.
+package models
@@ -0,0 +1,1649 @@
+package models
+
+import (
+	"time"
+)
+
+// ContainerStatus represents the status of a container
+type ContainerStatus string
+
+const (
+	StatusValidating ContainerStatus = "validating"
+	StatusPulling    ContainerStatus = "pulling"
+	StatusStopped    ContainerStatus = "stopped"
+	StatusStarting   ContainerStatus = "starting"
+	StatusRunning    ContainerStatus = "running"
+	StatusStopping   ContainerStatus = "stopping"
+	StatusError      ContainerStatus = "error"
+	StatusHealthy    ContainerStatus = "healthy"
+	StatusUnhealthy  ContainerStatus = "unhealthy"
+	// StatusInitializing and StatusInitFailed cover the init command run before the container starts
+	StatusInitializing ContainerStatus = "initializing"
+	StatusInitFailed   ContainerStatus = "init_failed"
+	// StatusBuilding covers building the image of a container created from source
+	StatusBuilding ContainerStatus = "building"
+	// StatusScheduledOff is a container its schedule stopped outside its start/stop window
+	StatusScheduledOff ContainerStatus = "scheduled_off"
+	// StatusPreempted is a container stopped to make room for an instance of a higher priority class
+	StatusPreempted ContainerStatus = "preempted"
+	// StatusCheckpointed is a container whose processes were saved to a checkpoint and stopped
+	StatusCheckpointed ContainerStatus = "checkpointed"
+	// StatusOverBudget is a container stopped because its workspace or the host exhausted a budget
+	StatusOverBudget ContainerStatus = "over_budget"
+	// StatusCrashLoop is a container that exited too often within a window and is no longer restarted
+	StatusCrashLoop ContainerStatus = "crash_loop"
+)
+
+// DetailedContainerStatus represents detailed container status information
+type DetailedContainerStatus struct {
+	Status     string `json:"status"`
+	Running    bool   `json:"running"`
+	Paused     bool   `json:"paused"`
+	Restarting bool   `json:"restarting"`
+	OOMKilled  bool   `json:"oom_killed"`
+	Dead       bool   `json:"dead"`
+	Pid        int    `json:"pid"`
+	ExitCode   int    `json:"exit_code"`
+	Error      string `json:"error"`
+	StartedAt  string `json:"started_at"`
+	FinishedAt string `json:"finished_at"`
+}
+
+// Container represents a managed container
+type Container struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	ServiceName string             `json:"service_name"`
+	Slug        string             `json:"slug"`
+	Image       string             `json:"image"`
+	Status      ContainerStatus    `json:"status"`
+	Port        int                `json:"port"`
+	URL         string             `json:"url,omitempty"`
+	Host        string             `json:"host,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+	Labels      map[string]string  `json:"labels,omitempty"`
+	Environment map[string]string  `json:"environment,omitempty"`
+	Command     []string           `json:"command,omitempty"`
+	HealthCheck *HealthCheckConfig `json:"health_check,omitempty"`
+	Route       *RouteConfig       `json:"route,omitempty"`
+	Routing     *RoutingConfig     `json:"routing,omitempty"`
+	GPUs        int                `json:"gpus,omitempty"`
+	Devices     []string           `json:"devices,omitempty"`
+	// GPUDevices are the CDI devices assigned from the host's GPU pool
+	GPUDevices []string `json:"gpu_devices,omitempty"`
+	// Resources are the limits applied to the container, including manager defaults
+	Resources *ResourceLimits `json:"resources,omitempty"`
+	// Security holds overrides of the hardened defaults; nil runs fully hardened
+	Security *SecurityConfig `json:"security,omitempty"`
+	// WorkspaceID owns the container; with per-workspace networks it selects the network
+	WorkspaceID string `json:"workspace_id,omitempty"`
+	// Network is the podman network the container is attached to
+	Network string `json:"network,omitempty"`
+	// SharedNetwork also attaches the container to the shared proxy network
+	SharedNetwork bool `json:"shared_network,omitempty"`
+	// Pod is the workspace pod whose network namespace the container shares; empty when it has its own
+	Pod string `json:"pod,omitempty"`
+	// DNS, ExtraHosts and Proxy are the resolvers, "host:ip" entries and HTTP proxy the container
+	// uses, including manager defaults
+	DNS        []string     `json:"dns,omitempty"`
+	ExtraHosts []string     `json:"extra_hosts,omitempty"`
+	Proxy      *ProxyConfig `json:"proxy,omitempty"`
+	// Tmpfs and ScratchVolumes are writable directories provisioned alongside a read-only root
+	Tmpfs          []ScratchMount `json:"tmpfs,omitempty"`
+	ScratchVolumes []ScratchMount `json:"scratch_volumes,omitempty"`
+	// Files maps container paths to the content mounted read-only there before the container starts
+	Files map[string]string `json:"files,omitempty"`
+	// StoppedAt is set while a user has stopped the container; it is kept but not restarted or routed
+	StoppedAt *time.Time `json:"stopped_at,omitempty"`
+	// LastExitCode, OOMKilled and FinishedAt describe the last exit of the container's process and
+	// RestartCount how often the runtime restarted it, as podman inspect last reported them
+	LastExitCode *int       `json:"last_exit_code,omitempty"`
+	OOMKilled    bool       `json:"oom_killed,omitempty"`
+	RestartCount int        `json:"restart_count,omitempty"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	// Ready is set while the server answers the MCP handshake; with READINESS_GATES_ROUTES its route
+	// is only registered while it is
+	Ready bool `json:"ready"`
+	// DependsOn lists the instances started before this container and advertised to it through env vars
+	DependsOn []Dependency `json:"depends_on,omitempty"`
+	// Init runs to completion before the container starts for the first time
+	Init *InitConfig `json:"init,omitempty"`
+	// Source is the repository Image was built from, for servers that publish no image
+	Source *SourceConfig `json:"source,omitempty"`
+	// Runtime is the npx or uvx package the container runs behind a stdio-to-HTTP bridge
+	Runtime *RuntimeConfig `json:"runtime,omitempty"`
+	// LogShipping overrides where the container's logs are forwarded; nil uses the manager default
+	LogShipping *LogShippingConfig `json:"log_shipping,omitempty"`
+	// Schedule limits when the container runs; nil runs it all the time
+	Schedule *Schedule `json:"schedule,omitempty"`
+	// Priority ranks the container when capacity runs out; nil is a non-preemptible "normal"
+	Priority *Priority `json:"priority,omitempty"`
+	// Scaling runs replicas of the container behind its route as load requires; nil runs it alone
+	Scaling *Scaling `json:"scaling,omitempty"`
+	// UpstreamTLS is set when the proxy and the manager reach the container over mutual TLS
+	UpstreamTLS bool `json:"upstream_tls,omitempty"`
+	// Platform is the "os/architecture" variant of the image the container runs
+	Platform string `json:"platform,omitempty"`
+	// Emulated is set when Platform is not the host's and the container runs degraded under qemu
+	Emulated bool `json:"emulated,omitempty"`
+	// SpecHash fingerprints the spec the container was created from, so a repeated create is recognized
+	SpecHash string `json:"spec_hash,omitempty"`
+	// EnvSchema declares the environment variables the server reads
+	EnvSchema []EnvVarSpec `json:"env_schema,omitempty"`
+	// Provenance maps spec fields such as "port" or "environment.API_KEY" to where their value came from
+	Provenance map[string]string `json:"provenance,omitempty"`
+}
+
+// Sources of a spec field reported in Container.Provenance
+const (
+	// ProvenanceRequest is a value the create request or json_spec set
+	ProvenanceRequest = "request"
+	// ProvenanceTemplate is derived from a runtime shortcut or a source build
+	ProvenanceTemplate = "template"
+	// ProvenanceDefault is a manager or env_schema default for a field the request left out
+	ProvenanceDefault = "default"
+	// ProvenanceManager is chosen by the manager, such as the slug or injected MCP_* variables
+	ProvenanceManager = "manager"
+)
+
+// ProxyConfig sets the HTTP proxy a container's outbound requests go through, as the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables
+type ProxyConfig struct {
+	HTTPProxy  string `json:"http_proxy,omitempty"`
+	HTTPSProxy string `json:"https_proxy,omitempty"`
+	NoProxy    string `json:"no_proxy,omitempty"`
+	// Disabled runs the container without the manager's default proxy
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// Schedule runs an instance only between its start and stop times, e.g. during business hours
+type Schedule struct {
+	// StartCron and StopCron are five-field cron expressions such as "0 9 * * 1-5"
+	StartCron string `json:"start_cron"`
+	StopCron  string `json:"stop_cron"`
+	// Timezone is an IANA zone name the expressions are evaluated in; empty uses UTC
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// Priority classes of an instance, from the first to be preempted to the last
+const (
+	PriorityLow    = "low"
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+)
+
+// Priority decides which instances make room when a create finds the host full. A create may
+// stop preemptible instances of a lower class; they are kept and can be started again later.
+type Priority struct {
+	// Class is "low", "normal" or "high"; empty is "normal"
+	Class string `json:"class,omitempty"`
+	// Preemptible lets creates of a higher class stop the instance, as for ephemeral workloads
+	Preemptible bool `json:"preemptible,omitempty"`
+}
+
+// Preemption records an instance stopped to make room for one of a higher priority class
+type Preemption struct {
+	ServiceName string `json:"service_name"`
+	InstanceID  string `json:"instance_id,omitempty"`
+	Class       string `json:"class"`
+	// PreemptedFor is the service whose create needed the room, ForClass its priority class
+	PreemptedFor string    `json:"preempted_for"`
+	ForClass     string    `json:"for_class"`
+	Reason       string    `json:"reason"`
+	PreemptedAt  time.Time `json:"preempted_at"`
+}
+
+// PreemptionsResponse lists recent preemptions, newest first
+type PreemptionsResponse struct {
+	Preemptions []Preemption `json:"preemptions"`
+}
+
+// Scaling runs a shared instance as several replicas behind its route, adding replicas while the
+// running ones are busy and removing them once load subsides
+type Scaling struct {
+	// MinReplicas and MaxReplicas bound the containers serving the instance, itself included;
+	// zero MinReplicas is 1
+	MinReplicas int `json:"min_replicas,omitempty"`
+	MaxReplicas int `json:"max_replicas"`
+	// TargetCPUPercent is the average CPU use per replica, in percent of one core, above which a
+	// replica is added; zero is 70
+	TargetCPUPercent int `json:"target_cpu_percent,omitempty"`
+	// TargetConnections is the average number of open proxy connections per replica above which a
+	// replica is added; zero scales on CPU only
+	TargetConnections int `json:"target_connections,omitempty"`
+	// ScaleDownDelaySeconds is how long the load must fit in one replica less before one is
+	// removed; zero is 300
+	ScaleDownDelaySeconds int `json:"scale_down_delay_seconds,omitempty"`
+}
+
+// Replica is a container added to serve a scaled instance's route
+type Replica struct {
+	ServiceName string          `json:"service_name"`
+	Status      ContainerStatus `json:"status"`
+	// Upstream is the address the route balances to, empty while the replica is not routed
+	Upstream  string    `json:"upstream,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ScalingStatus reports the replicas of a scaled instance and the load they were last scaled on
+type ScalingStatus struct {
+	ServiceName string   `json:"service_name"`
+	Scaling     *Scaling `json:"scaling"`
+	// Running counts the running containers serving the instance, itself included
+	Running  int       `json:"running"`
+	Replicas []Replica `json:"replicas"`
+	// CPUPercent and Connections are the average load per running container at SampledAt
+	CPUPercent   float64    `json:"cpu_percent"`
+	Connections  float64    `json:"connections"`
+	SampledAt    *time.Time `json:"sampled_at,omitempty"`
+	LastScaledAt *time.Time `json:"last_scaled_at,omitempty"`
+}
+
+// Actions taken once a budget is exhausted
+const (
+	// BudgetActionRefuse refuses to create or start instances
+	BudgetActionRefuse = "refuse"
+	// BudgetActionStop also stops the running instances
+	BudgetActionStop = "stop"
+)
+
+// Budget caps what the instances of a workspace, or all instances on the host, may use. A zero
+// limit is unlimited.
+type Budget struct {
+	// WorkspaceID is empty for the global budget
+	WorkspaceID    string  `json:"workspace_id,omitempty"`
+	ContainerHours float64 `json:"container_hours,omitempty"`
+	CPUSeconds     float64 `json:"cpu_seconds,omitempty"`
+	// EgressBytes counts the bytes the instances sent through the proxy
+	EgressBytes uint64 `json:"egress_bytes,omitempty"`
+	// Action is "refuse", the default, or "stop"
+	Action    string    `json:"action,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BudgetUsage is what instances used since Since, when usage was last reset
+type BudgetUsage struct {
+	ContainerHours float64   `json:"container_hours"`
+	CPUSeconds     float64   `json:"cpu_seconds"`
+	EgressBytes    uint64    `json:"egress_bytes"`
+	Since          time.Time `json:"since"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// BudgetStatus is the usage of a workspace, or of the host when WorkspaceID is empty, and its budget
+type BudgetStatus struct {
+	WorkspaceID string `json:"workspace_id,omitempty"`
+	// Budget is nil when no budget is set
+	Budget *Budget     `json:"budget,omitempty"`
+	Usage  BudgetUsage `json:"usage"`
+	// Percent is the largest share of a limit used
+	Percent   float64 `json:"percent"`
+	Exhausted bool    `json:"exhausted"`
+}
+
+// BudgetsResponse lists the global budget and the workspaces with a budget or usage
+type BudgetsResponse struct {
+	Global     BudgetStatus   `json:"global"`
+	Workspaces []BudgetStatus `json:"workspaces"`
+}
+
+// Alert rule kinds
+const (
+	// AlertKindInstanceUnhealthy matches each instance whose health checks fail or that crashed
+	AlertKindInstanceUnhealthy = "instance_unhealthy"
+	// AlertKindCreateFailureRate matches when the share of failed creates in the window is over the threshold
+	AlertKindCreateFailureRate = "create_failure_rate"
+	// AlertKindCapacity matches when the share of container slots taken is over the threshold
+	AlertKindCapacity = "capacity"
+)
+
+// Alert severities
+const (
+	AlertSeverityInfo     = "info"
+	AlertSeverityWarning  = "warning"
+	AlertSeverityCritical = "critical"
+)
+
+// Alert states
+const (
+	// AlertStatePending is an alert whose condition holds for less than its rule's for_seconds
+	AlertStatePending = "pending"
+	AlertStateFiring  = "firing"
+	// AlertStateResolved is only sent to notifiers; resolved alerts are no longer listed
+	AlertStateResolved = "resolved"
+)
+
+// AlertRule is a condition the manager evaluates and notifies about once it held long enough
+type AlertRule struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	// Threshold is the failure rate or the share of slots, in percent, the value must exceed
+	Threshold float64 `json:"threshold,omitempty"`
+	// ForSeconds is how long the condition must hold before the alert fires
+	ForSeconds int `json:"for_seconds,omitempty"`
+	// WindowSeconds is how far back creates are counted for create_failure_rate (default 15 minutes)
+	WindowSeconds int    `json:"window_seconds,omitempty"`
+	Severity      string `json:"severity,omitempty"`
+	// Notifiers are slack, pagerduty and events; empty sends to every configured notifier
+	Notifiers []string `json:"notifiers,omitempty"`
+	// Source is config for rules from ALERT_RULES_FILE, which the API cannot change, and api otherwise
+	Source    string    `json:"source"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// AlertRulesResponse lists the alert rules from configuration and the API
+type AlertRulesResponse struct {
+	Rules []AlertRule `json:"rules"`
+}
+
+// Alert is a rule whose condition holds, for one instance or for the whole host
+type Alert struct {
+	// ID is the rule ID, followed by the service name for per-instance rules
+	ID          string `json:"id"`
+	RuleID      string `json:"rule_id"`
+	Kind        string `json:"kind"`
+	Severity    string `json:"severity"`
+	State       string `json:"state"`
+	ServiceName string `json:"service_name,omitempty"`
+	InstanceID  string `json:"instance_id,omitempty"`
+	// Value is the measured failure rate or share of slots in percent
+	Value   float64 `json:"value,omitempty"`
+	Summary string  `json:"summary"`
+	// Since is when the condition was first seen to hold
+	Since      time.Time  `json:"since"`
+	FiredAt    *time.Time `json:"fired_at,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	// Silenced alerts are listed but not sent to notifiers
+	Silenced bool `json:"silenced"`
+}
+
+// AlertsResponse lists the pending and firing alerts and the notifiers they are sent to
+type AlertsResponse struct {
+	Alerts    []Alert  `json:"alerts"`
+	Notifiers []string `json:"notifiers"`
+}
+
+// AlertSilence mutes the notifications of a rule, of an instance or of both until EndsAt
+type AlertSilence struct {
+	ID string `json:"id"`
+	// RuleID and ServiceName select the silenced alerts; an empty one matches any
+	RuleID      string `json:"rule_id,omitempty"`
+	ServiceName string `json:"service_name,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+	// DurationSeconds sets EndsAt from the time the silence is created
+	DurationSeconds int       `json:"duration_seconds,omitempty"`
+	EndsAt          time.Time `json:"ends_at"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// AlertSilencesResponse lists the silences that have not ended
+type AlertSilencesResponse struct {
+	Silences []AlertSilence `json:"silences"`
+}
+
+// LogShippingConfig overrides log forwarding for one instance
+type LogShippingConfig struct {
+	// Disabled stops forwarding this instance's logs, including to the default sink
+	Disabled bool `json:"disabled,omitempty"`
+	// Sink and URL select a sink other than the default; "loki", "opensearch" or "http"
+	Sink  string `json:"sink,omitempty"`
+	URL   string `json:"url,omitempty"`
+	Index string `json:"index,omitempty"`
+	// Labels are attached to every line in addition to the instance and workspace labels
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// RuntimeConfig runs a stdio MCP server published as an npm or PyPI package, as registry
+// entries such as `npx -y @modelcontextprotocol/server-memory` describe it, without an image
+type RuntimeConfig struct {
+	// Type is "npx" or "uvx"
+	Type    string `json:"type"`
+	Package string `json:"package"`
+	// Version pins the package; empty runs the latest release
+	Version string   `json:"version,omitempty"`
+	Args    []string `json:"args,omitempty"`
+}
+
+// Package runtimes accepted in RuntimeConfig.Type
+const (
+	RuntimeNpx = "npx"
+	RuntimeUvx = "uvx"
+)
+
+// EnvVarSpec describes an environment variable an MCP server reads, as registry entries declare them
+type EnvVarSpec struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	// Secret values are credentials and are never echoed back
+	Secret bool `json:"secret,omitempty"`
+	// Format is "string" (the default), "number", "boolean" or "filepath"
+	Format  string   `json:"format,omitempty"`
+	Default string   `json:"default,omitempty"`
+	Choices []string `json:"choices,omitempty"`
+}
+
+// Value formats accepted in EnvVarSpec.Format
+const (
+	EnvFormatString   = "string"
+	EnvFormatNumber   = "number"
+	EnvFormatBoolean  = "boolean"
+	EnvFormatFilepath = "filepath"
+)
+
+// EnvVarProblem is an environment variable that does not satisfy its spec
+type EnvVarProblem struct {
+	Name string `json:"name"`
+	// Reason is "missing" or "invalid"
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// Reasons reported in EnvVarProblem.Reason
+const (
+	EnvProblemMissing = "missing"
+	EnvProblemInvalid = "invalid"
+)
+
+// ContainerSpecResponse is a container's effective spec, with values that may be credentials
+// masked, and where each field came from
+type ContainerSpecResponse struct {
+	ServiceName string                 `json:"service_name"`
+	Spec        map[string]interface{} `json:"spec"`
+	Provenance  map[string]string      `json:"provenance"`
+}
+
+// EnvSchemaResponse is the environment schema of a container, for rendering configuration forms
+type EnvSchemaResponse struct {
+	ServiceName string       `json:"service_name"`
+	EnvSchema   []EnvVarSpec `json:"env_schema"`
+}
+
+// SourceConfig locates the repository and Dockerfile an image is built from
+type SourceConfig struct {
+	GitURL string `json:"git_url"`
+	// Ref is a branch, tag or commit; empty builds the default branch
+	Ref string `json:"ref,omitempty"`
+	// Dockerfile is relative to the repository root and defaults to "Dockerfile"
+	Dockerfile string `json:"dockerfile,omitempty"`
+}
+
+// JobStatus represents the state of a background job
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// Job is a long-running operation, such as an image build, whose output can be fetched while it runs
+type Job struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	ServiceName string    `json:"service_name"`
+	Status      JobStatus `json:"status"`
+	// Image is the image a build job produced
+	Image string `json:"image,omitempty"`
+	// Mirror is the comparison a traffic mirroring job has made so far
+	Mirror     *MirrorComparison `json:"mirror,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+}
+
+// JobLogsResponse carries a job's output; only the most recent output of long jobs is kept
+type JobLogsResponse struct {
+	ID     string    `json:"id"`
+	Status JobStatus `json:"status"`
+	Logs   string    `json:"logs"`
+}
+
+// InstanceLogsResponse carries the most recent output of an instance's container
+type InstanceLogsResponse struct {
+	InstanceID  string `json:"instance_id"`
+	ServiceName string `json:"service_name"`
+	Logs        string `json:"logs"`
+}
+
+// InitConfig is a one-shot setup job, such as migrations or a model download, that must
+// exit successfully before the container starts and its route is registered
+type InitConfig struct {
+	Command []string `json:"command"`
+	// Image defaults to the container's image
+	Image string `json:"image,omitempty"`
+	// Environment is added to the container's environment for the init run only
+	Environment map[string]string `json:"environment,omitempty"`
+	// TimeoutSeconds bounds the run; zero uses the manager default
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+}
+
+// Dependency is an instance a container needs before it starts. With Image set it is a sidecar
+// provisioned and removed together with the container; otherwise Service names an existing container.
+type Dependency struct {
+	// Name identifies the dependency and prefixes its env vars, e.g. "vector-db" sets VECTOR_DB_HOST
+	Name        string            `json:"name"`
+	Service     string            `json:"service,omitempty"`
+	Image       string            `json:"image,omitempty"`
+	Port        int               `json:"port,omitempty"`
+	Command     []string          `json:"command,omitempty"`
+	Environment map[string]string `json:"environment,omitempty"`
+}
+
+// ScratchMount is a writable directory the manager provisions and removes with the container
+type ScratchMount struct {
+	Path string `json:"path"`
+	// Size caps the mount, e.g. "64m" or "1Gi"; empty uses the manager default
+	Size string `json:"size,omitempty"`
+}
+
+// SecurityConfig relaxes the hardened container defaults for servers that need more.
+// Unset fields keep the manager's defaults.
+type SecurityConfig struct {
+	// ReadOnlyRootFS set to false gives the container a writable root filesystem
+	ReadOnlyRootFS *bool `json:"read_only_rootfs,omitempty"`
+	// User runs the container as "uid[:gid]" or a user name instead of the default user
+	User string `json:"user,omitempty"`
+	// AddCapabilities re-adds Linux capabilities after all are dropped
+	AddCapabilities []string `json:"add_capabilities,omitempty"`
+	// AllowPrivilegeEscalation disables no-new-privileges, e.g. for setuid binaries
+	AllowPrivilegeEscalation bool `json:"allow_privilege_escalation,omitempty"`
+	// SeccompProfile names a profile file in the manager's seccomp profile directory
+	SeccompProfile string `json:"seccomp_profile,omitempty"`
+}
+
+// ResourceLimits caps what a single container may consume.
+// Sizes accept podman (512m, 1g) or Kubernetes (512Mi, 1Gi) notation.
+type ResourceLimits struct {
+	Memory           string `json:"memory,omitempty"`
+	CPU              string `json:"cpu,omitempty"`
+	PidsLimit        int    `json:"pids_limit,omitempty"`
+	EphemeralStorage string `json:"ephemeral_storage,omitempty"`
+}
+
+// HealthCheckConfig customizes how a container's health is probed.
+// Zero values fall back to the manager defaults.
+type HealthCheckConfig struct {
+	// Path is requested on the container, e.g. "/health"; empty probes "/"
+	Path string `json:"path,omitempty"`
+	// Port overrides the port probed; defaults to the container's exposed port
+	Port               int `json:"port,omitempty"`
+	IntervalSeconds    int `json:"interval_seconds,omitempty"`
+	TimeoutSeconds     int `json:"timeout_seconds,omitempty"`
+	HealthyThreshold   int `json:"healthy_threshold,omitempty"`
+	UnhealthyThreshold int `json:"unhealthy_threshold,omitempty"`
+	// ExpectedStatus requires an exact HTTP status; zero accepts any 2xx or 3xx
+	ExpectedStatus int `json:"expected_status,omitempty"`
+	// ReadinessPath is the MCP endpoint the readiness handshake initializes a session on; empty uses
+	// /mcp for routes on the streamable HTTP transport and skips the handshake for others
+	ReadinessPath string `json:"readiness_path,omitempty"`
+	// SkipReadiness counts the server ready once it passes the health check, for servers that cannot
+	// take an MCP handshake over streamable HTTP
+	SkipReadiness bool `json:"skip_readiness,omitempty"`
+	// Startup holds a new or restarted container as starting until its server first answers
+	Startup *StartupProbeConfig `json:"startup,omitempty"`
+}
+
+// Startup probe types
+const (
+	StartupProbeHTTP = "http"
+	StartupProbeMCP  = "mcp"
+)
+
+// StartupProbeConfig gives a slow server, such as one loading a model, time to start before it is
+// health checked. Until the probe passes the container is starting and failing checks do not count.
+type StartupProbeConfig struct {
+	// Type is "http" (default) to request Path, or "mcp" to complete an MCP handshake on it
+	Type string `json:"type,omitempty"`
+	// Path defaults to the health check path for HTTP probes and the readiness path for MCP ones
+	Path            string `json:"path,omitempty"`
+	IntervalSeconds int    `json:"interval_seconds,omitempty"`
+	// MaxDurationSeconds is how long the server may take to answer before the container fails
+	MaxDurationSeconds int `json:"max_duration_seconds,omitempty"`
+}
+
+// RouteConfig configures the proxy middlewares applied in front of a container.
+// Nil sections add no middleware.
+type RouteConfig struct {
+	RateLimit *RouteRateLimit `json:"rate_limit,omitempty"`
+	// IPAllowList restricts clients to these IPs or CIDR ranges
+	IPAllowList     []string          `json:"ip_allowlist,omitempty"`
+	RequestHeaders  map[string]string `json:"request_headers,omitempty"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	Buffering       *RouteBuffering   `json:"buffering,omitempty"`
+
+	// Transport is the MCP transport served: "http" (default), "sse", "streamable_http" or "websocket".
+	// Streaming transports are proxied without buffering and with long idle timeouts.
+	Transport string `json:"transport,omitempty"`
+	// IdleTimeoutSeconds bounds how long an idle upstream connection is kept open
+	IdleTimeoutSeconds int `json:"idle_timeout_seconds,omitempty"`
+	// StickySessions pins each client to one replica using a cookie
+	StickySessions   bool   `json:"sticky_sessions,omitempty"`
+	StickyCookieName string `json:"sticky_cookie_name,omitempty"`
+
+	// CircuitBreaker overrides the proxy-wide breaker that fails fast while the upstream is failing
+	CircuitBreaker *RouteCircuitBreaker `json:"circuit_breaker,omitempty"`
+	// Retry resends requests that failed to reach the upstream
+	Retry *RouteRetry `json:"retry,omitempty"`
+
+	// Auth makes the proxy reject requests without the instance's access token
+	Auth *RouteAuth `json:"auth,omitempty"`
+
+	// Limits overrides the proxy-wide size and timeout limits of requests to the instance
+	Limits *RouteLimits `json:"limits,omitempty"`
+
+	// Sessions gives each calling session its own container of the server, for servers that keep
+	// per-session state and cannot be shared
+	Sessions *RouteSessions `json:"sessions,omitempty"`
+}
+
+// Proxy authentication schemes
+const (
+	ProxyAuthBearer = "bearer"
+	ProxyAuthBasic  = "basic"
+)
+
+// RouteAuth is the access token the proxy requires before forwarding a request to a container.
+// Clients send it as a bearer token, or with basic auth as the password of Username.
+type RouteAuth struct {
+	Type string `json:"type"`
+	// Username is the basic auth user name, "mcp" when empty
+	Username string `json:"username,omitempty"`
+	// Token is generated at create time when left empty
+	Token string `json:"token,omitempty"`
+}
+
+// RouteCircuitBreaker tunes when the proxy stops forwarding to a failing upstream. Unset fields use
+// the proxy-wide defaults; setting it on a route enables the breaker even when it is off by default.
+type RouteCircuitBreaker struct {
+	Disabled bool `json:"disabled,omitempty"`
+	// Expression is a Traefik circuit breaker expression, e.g. "NetworkErrorRatio() > 0.5"
+	Expression string `json:"expression,omitempty"`
+	// FallbackSeconds is how long requests are rejected once the breaker trips
+	FallbackSeconds int `json:"fallback_seconds,omitempty"`
+	// RecoverySeconds is how long traffic is ramped back up after the fallback period
+	RecoverySeconds int `json:"recovery_seconds,omitempty"`
+}
+
+// RouteRetry resends requests that could not reach the upstream, with exponential backoff
+type RouteRetry struct {
+	Attempts          int `json:"attempts"`
+	InitialIntervalMs int `json:"initial_interval_ms,omitempty"`
+}
+
+// Circuit breaker states reported in CircuitBreakerStatus.State
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+	CircuitDisabled = "disabled"
+)
+
+// ConcurrencyStatus reports an instance's concurrency cap and the requests the proxy rejected over it
+type ConcurrencyStatus struct {
+	ServiceName string `json:"service_name"`
+	Limit       int    `json:"limit"`
+	// Rejected counts the requests answered with 429 since the manager started
+	Rejected uint64 `json:"rejected"`
+	// RejectedLastMinute is the recent rejection count; Saturated is set while it is not zero
+	RejectedLastMinute int        `json:"rejected_last_minute"`
+	Saturated          bool       `json:"saturated"`
+	LastRejectedAt     *time.Time `json:"last_rejected_at,omitempty"`
+}
+
+// CircuitBreakerStatus reports a route's breaker as observed from the requests the proxy rejected
+type CircuitBreakerStatus struct {
+	State           string     `json:"state"`
+	Expression      string     `json:"expression,omitempty"`
+	FallbackSeconds int        `json:"fallback_seconds,omitempty"`
+	RecoverySeconds int        `json:"recovery_seconds,omitempty"`
+	Trips           uint64     `json:"trips"`
+	LastTrippedAt   *time.Time `json:"last_tripped_at,omitempty"`
+}
+
+// RoutingConfig selects how the proxy matches requests to a container.
+// Path routing under /mcp/{slug} is always available; host routing adds a dedicated hostname served over TLS.
+type RoutingConfig struct {
+	// Type is "path" (default) or "host"
+	Type     string `json:"type,omitempty"`
+	Hostname string `json:"hostname,omitempty"`
+}
+
+// Routing types accepted in RoutingConfig.Type
+const (
+	RoutingPath = "path"
+	RoutingHost = "host"
+)
+
+// MCP transports accepted in RouteConfig.Transport
+const (
+	TransportHTTP           = "http"
+	TransportSSE            = "sse"
+	TransportStreamableHTTP = "streamable_http"
+	TransportWebSocket      = "websocket"
+)
+
+// ConnectionContract tells clients how to connect to a container through the proxy
+type ConnectionContract struct {
+	ServiceName string `json:"service_name"`
+	URL         string `json:"url"`
+	// HostURL is set when the container is also routed by hostname
+	HostURL   string `json:"host_url,omitempty"`
+	Transport string `json:"transport"`
+	// Streaming is true when responses are flushed to the client as they are produced
+	Streaming          bool   `json:"streaming"`
+	Buffered           bool   `json:"buffered"`
+	IdleTimeoutSeconds int    `json:"idle_timeout_seconds"`
+	StickySessions     bool   `json:"sticky_sessions"`
+	StickyCookieName   string `json:"sticky_cookie_name,omitempty"`
+	RateLimited        bool   `json:"rate_limited"`
+	IPRestricted       bool   `json:"ip_restricted"`
+	// Auth holds the credentials clients must present to the proxy
+	Auth *RouteAuth `json:"auth,omitempty"`
+	// Limits are the size and timeout limits the proxy enforces, the route's own or the defaults
+	Limits *RouteLimits `json:"limits,omitempty"`
+}
+
+// RouteRateLimit limits requests per client IP
+type RouteRateLimit struct {
+	// Average is the sustained number of requests allowed per PeriodSeconds
+	Average       int `json:"average"`
+	Burst         int `json:"burst,omitempty"`
+	PeriodSeconds int `json:"period_seconds,omitempty"`
+}
+
+// RouteBuffering caps request and response body sizes; zero leaves a limit unset
+type RouteBuffering struct {
+	MaxRequestBodyBytes  int64 `json:"max_request_body_bytes,omitempty"`
+	MaxResponseBodyBytes int64 `json:"max_response_body_bytes,omitempty"`
+}
+
+// RouteLimits bounds what the proxy passes between clients and an MCP server. Zero keeps the
+// proxy-wide default for a limit.
+type RouteLimits struct {
+	MaxRequestBodyBytes  int64 `json:"max_request_body_bytes,omitempty"`
+	MaxResponseBodyBytes int64 `json:"max_response_body_bytes,omitempty"`
+	// MaxHeaderBytes bounds the request's header names and values together
+	MaxHeaderBytes int `json:"max_header_bytes,omitempty"`
+	// TimeoutSeconds bounds how long the server may take to start its response
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+	// MaxConcurrentRequests bounds the requests the server handles at once; more are answered with 429
+	MaxConcurrentRequests int `json:"max_concurrent_requests,omitempty"`
+}
+
+// RouteSessions runs a pool of containers keyed by a request header: the first request of a
+// session starts its container and the container is removed once the session goes idle
+type RouteSessions struct {
+	// Header names the request header identifying the session, X-MCP-Session when empty
+	Header string `json:"header,omitempty"`
+	// IdleTimeoutSeconds is how long a session's container may go without requests
+	IdleTimeoutSeconds int `json:"idle_timeout_seconds,omitempty"`
+	// MaxSessions caps the session containers running at once; zero uses the manager's default of 20
+	MaxSessions int `json:"max_sessions,omitempty"`
+}
+
+// VolumeMount represents a volume mount
+type VolumeMount struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	ReadOnly    bool   `json:"read_only,omitempty"`
+}
+
+// CreateContainerRequest represents a request to create a new container
+type CreateContainerRequest struct {
+	ServiceName string             `json:"service_name" binding:"required"`
+	Slug        string             `json:"slug,omitempty"` // Preferred; one taken by another instance gets a random suffix
+	Image       string             `json:"image"`
+	Port        int                `json:"port" binding:"required"`
+	Environment map[string]string  `json:"environment,omitempty"`
+	Labels      map[string]string  `json:"labels,omitempty"`
+	Command     []string           `json:"command,omitempty"`
+	Volumes     []VolumeMount      `json:"volumes,omitempty"`
+	MemoryLimit string             `json:"memory_limit,omitempty"`
+	CPULimit    string             `json:"cpu_limit,omitempty"`
+	HealthCheck *HealthCheckConfig `json:"health_check,omitempty"`
+	Route       *RouteConfig       `json:"route,omitempty"`
+	Routing     *RoutingConfig     `json:"routing,omitempty"`
+	GPUs        int                `json:"gpus,omitempty"`
+	Devices     []string           `json:"devices,omitempty"`
+	Resources   *ResourceLimits    `json:"resources,omitempty"`
+	Security    *SecurityConfig    `json:"security,omitempty"`
+	WorkspaceID string             `json:"workspace_id,omitempty"`
+	// SharedNetwork opts a workspace container into the shared network to reach other workspaces
+	SharedNetwork bool `json:"shared_network,omitempty"`
+	// DNS, ExtraHosts and Proxy add to or override the manager's defaults
+	DNS            []string       `json:"dns,omitempty"`
+	ExtraHosts     []string       `json:"extra_hosts,omitempty"`
+	Proxy          *ProxyConfig   `json:"proxy,omitempty"`
+	Tmpfs          []ScratchMount `json:"tmpfs,omitempty"`
+	ScratchVolumes []ScratchMount `json:"scratch_volumes,omitempty"`
+	DependsOn      []Dependency   `json:"depends_on,omitempty"`
+	Init           *InitConfig    `json:"init,omitempty"`
+	// Files maps container paths to file content; secret references are resolved before creation
+	Files map[string]string `json:"files,omitempty"`
+	// Source builds the image from a repository; exactly one of Image and Source is set
+	Source *SourceConfig `json:"source,omitempty"`
+	// Runtime replaces Image and Command with a bridged npx or uvx package
+	Runtime     *RuntimeConfig     `json:"runtime,omitempty"`
+	LogShipping *LogShippingConfig `json:"log_shipping,omitempty"`
+	Schedule    *Schedule          `json:"schedule,omitempty"`
+	Priority    *Priority          `json:"priority,omitempty"`
+	Scaling     *Scaling           `json:"scaling,omitempty"`
+	// EnvSchema is checked against Environment, whose missing values take the declared defaults
+	EnvSchema []EnvVarSpec `json:"env_schema,omitempty"`
+}
+
+// CreatePlan is what a create request would do, returned by a dry run without creating anything.
+// Environment values are masked, both in Environment and in the podman arguments.
+type CreatePlan struct {
+	Backend     string `json:"backend"`
+	ServiceName string `json:"service_name"`
+	Name        string `json:"name"`
+	Image       string `json:"image"`
+	// Slug is drawn again when the instance is actually created, so its random suffix differs
+	Slug        string            `json:"slug,omitempty"`
+	URL         string            `json:"url"`
+	Environment map[string]string `json:"environment,omitempty"`
+	Resources   *ResourceLimits   `json:"resources,omitempty"`
+	Network     string            `json:"network,omitempty"`
+	Pod         string            `json:"pod,omitempty"`
+	GPUDevices  []string          `json:"gpu_devices,omitempty"`
+	// PodmanArgs are the arguments of the podman run the Docker backend would execute
+	PodmanArgs []string `json:"podman_args,omitempty"`
+	// Manifests are the Kubernetes objects the Kubernetes backend would create
+	Manifests []interface{} `json:"manifests,omitempty"`
+	// Policies are the manager defaults and limits applied on top of the request
+	Policies []string `json:"policies,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// GPUCapacity reports the host's GPU pool and which containers hold each device
+type GPUCapacity struct {
+	Total       int                 `json:"total"`
+	Allocated   int                 `json:"allocated"`
+	Available   int                 `json:"available"`
+	Assignments map[string][]string `json:"assignments"`
+}
+
+// HealthResponse represents the health check response
+type HealthResponse struct {
+	Status            string    `json:"status"`
+	Version           string    `json:"version"`
+	ContainersRunning int       `json:"containers_running"`
+	Timestamp         time.Time `json:"timestamp"`
+	Uptime            string    `json:"uptime,omitempty"`
+	// Proxy reports the reverse proxy's state when the manager supervises or depends on one
+	Proxy *ProxyStatus `json:"proxy,omitempty"`
+}
+
+// Outcomes of a doctor check
+const (
+	DoctorPass = "pass"
+	DoctorFail = "fail"
+	DoctorSkip = "skip"
+)
+
+// DoctorReport is the result of the environment self-test
+type DoctorReport struct {
+	Passed     bool          `json:"passed"`
+	Checks     []DoctorCheck `json:"checks"`
+	StartedAt  time.Time     `json:"started_at"`
+	DurationMs int64         `json:"duration_ms"`
+}
+
+// DoctorCheck is one step of the self-test; Hint says what to look at when it fails
+type DoctorCheck struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+	Hint       string `json:"hint,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Readiness states of the manager and of each dependency
+const (
+	ReadinessReady    = "ready"
+	ReadinessNotReady = "not_ready"
+	DependencyOK      = "ok"
+	DependencyFailing = "failing"
+)
+
+// ReadinessResponse reports whether the manager can serve traffic, and the state of each
+// dependency it needs
+type ReadinessResponse struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+	Timestamp    time.Time                   `json:"timestamp"`
+}
+
+// DependencyStatus is the outcome of one readiness check
+type DependencyStatus struct {
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// ProxyStatus describes the reverse proxy routing traffic to MCP containers
+type ProxyStatus struct {
+	// Mode is "embedded" when the manager runs Traefik itself, or "external"
+	Mode      string    `json:"mode"`
+	State     string    `json:"state"`
+	PID       int       `json:"pid,omitempty"`
+	Restarts  int       `json:"restarts"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// Proxy states reported in ProxyStatus.State
+const (
+	ProxyStateStarting   = "starting"
+	ProxyStateRunning    = "running"
+	ProxyStateRestarting = "restarting"
+	ProxyStateStopped    = "stopped"
+	ProxyStateExternal   = "external"
+)
+
+// ListContainersResponse represents the response for listing containers
+type ListContainersResponse struct {
+	Containers []Container         `json:"containers"`
+	Total      int                 `json:"total"`
+	Archived   []ArchivedContainer `json:"archived,omitempty"`
+}
+
+// ArchivedContainer is a long-inactive container whose metadata was moved to cold storage.
+// Its slug stays reserved so the instance keeps its URL when it is restored.
+type ArchivedContainer struct {
+	Container
+	LastStartedAt time.Time `json:"last_started_at,omitempty"`
+	ArchivedAt    time.Time `json:"archived_at"`
+}
+
+// CheckpointRequest sets how a container's processes are saved with CRIU
+type CheckpointRequest struct {
+	// LeaveRunning keeps the container running after the checkpoint is written
+	LeaveRunning bool `json:"leave_running,omitempty"`
+	// TCPEstablished saves open TCP connections instead of refusing to checkpoint them
+	TCPEstablished bool `json:"tcp_established,omitempty"`
+}
+
+// RestoreRequest sets how a container is restored from a checkpoint
+type RestoreRequest struct {
+	// Archive is a checkpoint archive in the checkpoint directory, such as one copied from another
+	// host; empty restores the container's latest checkpoint
+	Archive string `json:"archive,omitempty"`
+	// TCPEstablished restores the TCP connections saved with the checkpoint
+	TCPEstablished bool `json:"tcp_established,omitempty"`
+}
+
+// Checkpoint describes a container's processes saved to an archive
+type Checkpoint struct {
+	ServiceName string `json:"service_name"`
+	// Archive is the file the checkpoint was exported to; it can be copied to another host
+	Archive        string    `json:"archive"`
+	SizeBytes      int64     `json:"size_bytes"`
+	LeftRunning    bool      `json:"left_running"`
+	TCPEstablished bool      `json:"tcp_established"`
+	CreatedAt      time.Time `json:"created_at"`
+	// RestoredAt is when the container was last restored from the checkpoint
+	RestoredAt *time.Time `json:"restored_at,omitempty"`
+}
+
+// ExecRequest runs a diagnostic command inside a container
+type ExecRequest struct {
+	// Command is the program and its arguments. It runs without a shell, and the program must be
+	// one of the allowed commands.
+	Command []string `json:"command" binding:"required"`
+}
+
+// ExecResult is the outcome of a diagnostic command
+type ExecResult struct {
+	ServiceName string   `json:"service_name"`
+	Command     []string `json:"command"`
+	ExitCode    int      `json:"exit_code"`
+	Stdout      string   `json:"stdout"`
+	Stderr      string   `json:"stderr"`
+	// Truncated is set when stdout or stderr was longer than the output limit
+	Truncated  bool  `json:"truncated"`
+	TimedOut   bool  `json:"timed_out"`
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// Encodings of file content
+const (
+	FileEncodingText   = "text"
+	FileEncodingBase64 = "base64"
+)
+
+// ContainerFile is a file copied into or out of a container
+type ContainerFile struct {
+	// Path is absolute and must be under one of the allowed directories
+	Path    string `json:"path" binding:"required"`
+	Content string `json:"content"`
+	// Encoding is "text", the default, or "base64" for binary content
+	Encoding string `json:"encoding,omitempty"`
+	// Mode is the octal permission of a written file, 0644 by default
+	Mode      string `json:"mode,omitempty"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// SupportBundleManifest describes a support bundle: when it was taken, the files it holds and the
+// parts that could not be collected, keyed by file
+type SupportBundleManifest struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	Version     string            `json:"version"`
+	Runtime     string            `json:"runtime"`
+	Containers  int               `json:"containers"`
+	Files       []string          `json:"files"`
+	Errors      map[string]string `json:"errors,omitempty"`
+}
+
+// ContainerInspect is the part of a container's podman or Kubernetes inspect data needed to debug
+// it without access to the host
+type ContainerInspect struct {
+	ServiceName string `json:"service_name"`
+	ContainerID string `json:"container_id"`
+	// Runtime is "podman", "docker" or "kubernetes"
+	Runtime string `json:"runtime"`
+	// Pod is the Kubernetes pod inspected, or the podman pod the container runs in
+	Pod         string `json:"pod,omitempty"`
+	Image       string `json:"image"`
+	ImageID     string `json:"image_id,omitempty"`
+	ImageDigest string `json:"image_digest,omitempty"`
+	// State is the runtime's state, such as running, exited or waiting
+	State string `json:"state"`
+	// Reason explains a Kubernetes state, such as CrashLoopBackOff or OOMKilled
+	Reason       string `json:"reason,omitempty"`
+	Error        string `json:"error,omitempty"`
+	ExitCode     int    `json:"exit_code"`
+	OOMKilled    bool   `json:"oom_killed"`
+	RestartCount int    `json:"restart_count"`
+	// LastExitCode and LastReason describe the previous run of a restarted Kubernetes container
+	LastExitCode *int             `json:"last_exit_code,omitempty"`
+	LastReason   string           `json:"last_reason,omitempty"`
+	StartedAt    *time.Time       `json:"started_at,omitempty"`
+	FinishedAt   *time.Time       `json:"finished_at,omitempty"`
+	Mounts       []InspectMount   `json:"mounts"`
+	Networks     []InspectNetwork `json:"networks"`
+	// Ports maps each exposed port, such as 8000/tcp, to the host address it is published on,
+	// empty when it is only reachable through the proxy
+	Ports map[string]string `json:"ports,omitempty"`
+}
+
+// InspectMount is a volume, bind or tmpfs mounted into a container
+type InspectMount struct {
+	// Type is bind, volume or tmpfs for podman, or the volume source kind for Kubernetes
+	Type        string `json:"type"`
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination"`
+	ReadOnly    bool   `json:"read_only"`
+	// Options are the mount options, such as the size of a tmpfs
+	Options string `json:"options,omitempty"`
+}
+
+// InspectNetwork is a network a container is attached to and its address on it
+type InspectNetwork struct {
+	Name       string   `json:"name"`
+	IPAddress  string   `json:"ip_address,omitempty"`
+	Gateway    string   `json:"gateway,omitempty"`
+	MACAddress string   `json:"mac_address,omitempty"`
+	Aliases    []string `json:"aliases,omitempty"`
+}
+
+// Backup is a portable snapshot of a manager's desired state, from which POST /admin/restore
+// recreates the same containers, routes and webhooks on another host. It holds secrets in clear.
+type Backup struct {
+	Version    int               `json:"version"`
+	CreatedAt  time.Time         `json:"created_at"`
+	ManagedBy  string            `json:"managed_by"`
+	Containers []BackupContainer `json:"containers"`
+	Webhooks   []BackupWebhook   `json:"webhooks,omitempty"`
+	// Skipped lists containers that could not be captured, such as adopted ones, with the reason
+	Skipped map[string]string `json:"skipped,omitempty"`
+}
+
+// Backup container states; stopped and archived containers are restored in the same state
+const (
+	BackupStateRunning  = "running"
+	BackupStateStopped  = "stopped"
+	BackupStateArchived = "archived"
+)
+
+// BackupContainer is the spec a container was created from, with the slug its URL uses
+type BackupContainer struct {
+	Spec  CreateContainerRequest `json:"spec"`
+	Slug  string                 `json:"slug"`
+	State string                 `json:"state"`
+}
+
+// BackupWebhook is a registered webhook including its signing secret
+type BackupWebhook struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events,omitempty"`
+}
+
+// RestoreResult reports how POST /admin/restore reconciled the host to a backup
+type RestoreResult struct {
+	Created  []string `json:"created"`
+	Existing []string `json:"existing"`
+	Webhooks int      `json:"webhooks"`
+	// Failed maps each container that could not be restored to the error
+	Failed map[string]string `json:"failed,omitempty"`
+}
+
+// GCReport describes a garbage collection run over unused images and build cache
+type GCReport struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	DryRun     bool      `json:"dry_run,omitempty"`
+	// Skipped explains why nothing was removed, e.g. disk usage below the threshold
+	Skipped       string        `json:"skipped,omitempty"`
+	RemovedImages []UnusedImage `json:"removed_images"`
+	// PrunedDangling counts dangling layers and build cache entries removed
+	PrunedDangling int `json:"pruned_dangling"`
+	// ReclaimedBytes is the space freed on the image storage filesystem, or the removed
+	// images' sizes when filesystem usage is unavailable
+	ReclaimedBytes  uint64   `json:"reclaimed_bytes"`
+	DiskUsageBefore float64  `json:"disk_usage_percent_before,omitempty"`
+	DiskUsageAfter  float64  `json:"disk_usage_percent_after,omitempty"`
+	Errors          []string `json:"errors,omitempty"`
+}
+
+// RegistryCacheStatus reports how image pulls went through the pull-through registry cache
+type RegistryCacheStatus struct {
+	Enabled     bool   `json:"enabled"`
+	Mirror      string `json:"mirror,omitempty"`
+	Upstream    string `json:"upstream,omitempty"`
+	Provisioned bool   `json:"provisioned"`
+	// Pulls went through the mirror, Fallbacks went to the upstream after the mirror failed, and
+	// Bypassed were of images from other registries
+	Pulls     int64 `json:"pulls"`
+	Fallbacks int64 `json:"fallbacks"`
+	Bypassed  int64 `json:"bypassed"`
+	// Manifests and Blobs are the mirror's own counters, when its metrics can be read
+	Manifests *RegistryCacheCounters `json:"manifests,omitempty"`
+	Blobs     *RegistryCacheCounters `json:"blobs,omitempty"`
+	// HitRatio is the share of blob requests served from the cache
+	HitRatio     float64 `json:"hit_ratio"`
+	MetricsError string  `json:"metrics_error,omitempty"`
+}
+
+// RegistryCacheCounters are a pull-through cache's request counters for manifests or blobs
+type RegistryCacheCounters struct {
+	Requests    uint64 `json:"requests"`
+	Hits        uint64 `json:"hits"`
+	Misses      uint64 `json:"misses"`
+	BytesPulled uint64 `json:"bytes_pulled"`
+}
+
+// FilesystemUsage describes the filesystem holding container and image storage
+type FilesystemUsage struct {
+	Path           string  `json:"path"`
+	TotalBytes     uint64  `json:"total_bytes"`
+	UsedBytes      uint64  `json:"used_bytes"`
+	AvailableBytes uint64  `json:"available_bytes"`
+	UsedPercent    float64 `json:"used_percent"`
+}
+
+// StorageUsage reports image storage usage and what images and containers consume
+type StorageUsage struct {
+	Filesystem *FilesystemUsage `json:"filesystem,omitempty"`
+	// Pressure is set while free space is below the configured minimum and creates are refused
+	Pressure   bool               `json:"pressure"`
+	Images     []ImageStorage     `json:"images"`
+	Containers []ContainerStorage `json:"containers"`
+	// TotalImageBytes sums image sizes; shared layers are counted once per image
+	TotalImageBytes     uint64    `json:"total_image_bytes"`
+	TotalContainerBytes uint64    `json:"total_container_bytes"`
+	Timestamp           time.Time `json:"timestamp"`
+}
+
+// SchedulingDecision explains how a new container fitted the host it was to be created on
+type SchedulingDecision struct {
+	ServiceName string `json:"service_name"`
+	Image       string `json:"image"`
+	// Host is the container engine's socket, "local" for the engine's default
+	Host     string `json:"host"`
+	Admitted bool   `json:"admitted"`
+	// Enforced is set when a container that did not fit was refused rather than only recorded
+	Enforced bool `json:"enforced"`
+	// Score rates the placement from 0 to 100 by memory and CPU headroom, whether the image is
+	// already pulled and free container slots
+	Score              float64   `json:"score"`
+	MemoryRequestBytes int64     `json:"memory_request_bytes"`
+	MemoryFreeBytes    int64     `json:"memory_free_bytes,omitempty"`
+	CPURequest         float64   `json:"cpu_request"`
+	CPUAllocated       float64   `json:"cpu_allocated"`
+	CPUCapacity        float64   `json:"cpu_capacity,omitempty"`
+	ImagePresent       bool      `json:"image_present"`
+	Containers         int       `json:"containers"`
+	MaxContainers      int       `json:"max_containers"`
+	Reasons            []string  `json:"reasons"`
+	DecidedAt          time.Time `json:"decided_at"`
+}
+
+// SchedulingDecisionsResponse lists recent scheduling decisions, newest first
+type SchedulingDecisionsResponse struct {
+	Enforced  bool                 `json:"enforced"`
+	Decisions []SchedulingDecision `json:"decisions"`
+}
+
+// ImageStorage is the size of a local image and how many containers use it
+type ImageStorage struct {
+	ID         string   `json:"id"`
+	Names      []string `json:"names,omitempty"`
+	Size       uint64   `json:"size"`
+	Containers int      `json:"containers"`
+}
+
+// ContainerStorage is the disk consumed by a managed container or one of its sidecars
+type ContainerStorage struct {
+	ServiceName string `json:"service_name"`
+	Name        string `json:"name"`
+	ID          string `json:"id"`
+	// Sidecar is set for a dependency container owned by ServiceName
+	Sidecar bool `json:"sidecar,omitempty"`
+	// WritableBytes is what the container wrote on top of its image
+	WritableBytes uint64 `json:"writable_bytes"`
+	// RootFSBytes includes the image layers the container runs on
+	RootFSBytes uint64 `json:"rootfs_bytes"`
+}
+
+// Where image metadata was read from
+const (
+	ImageSourceLocal    = "local"
+	ImageSourceRegistry = "registry"
+	ImageSourcePulled   = "pulled"
+)
+
+// ImageMetadata describes an image, for prefilling an instance spec
+type ImageMetadata struct {
+	Reference string `json:"reference"`
+	Digest    string `json:"digest,omitempty"`
+	// Source is local for an image already on the host, registry when only its config was fetched,
+	// and pulled when the image had to be pulled to inspect it
+	Source       string   `json:"source"`
+	Architecture string   `json:"architecture,omitempty"`
+	OS           string   `json:"os,omitempty"`
+	ExposedPorts []int    `json:"exposed_ports"`
+	Entrypoint   []string `json:"entrypoint,omitempty"`
+	Cmd          []string `json:"cmd,omitempty"`
+	WorkingDir   string   `json:"working_dir,omitempty"`
+	User         string   `json:"user,omitempty"`
+	// Env holds the environment defaults the image sets
+	Env    map[string]string `json:"env"`
+	Labels map[string]string `json:"labels"`
+	// MCP holds the mcp.* labels without their prefix, e.g. transport for mcp.transport
+	MCP map[string]string `json:"mcp"`
+	// SizeBytes is the unpacked size of a local image, or the download size read from a registry
+	SizeBytes      int64 `json:"size_bytes"`
+	SizeCompressed bool  `json:"size_compressed"`
+	// SuggestedPort is the port for json_spec: the mcp.port label, or the only exposed TCP port
+	SuggestedPort int `json:"suggested_port,omitempty"`
+}
+
+// TrafficStats counts the requests the proxy forwarded to one instance since FirstRequestAt.
+// Counters are cumulative and survive restarts, so usage over a period is the difference of two reads.
+type TrafficStats struct {
+	ServiceName string `json:"service_name"`
+	WorkspaceID string `json:"workspace_id,omitempty"`
+	Requests    uint64 `json:"requests"`
+	// StatusCodes counts responses by HTTP status code
+	StatusCodes map[string]uint64 `json:"status_codes"`
+	// Errors counts 5xx responses, including those the proxy returned itself
+	Errors   uint64 `json:"errors"`
+	BytesIn  uint64 `json:"bytes_in"`
+	BytesOut uint64 `json:"bytes_out"`
+	// TotalLatencyMs sums request durations; AverageLatencyMs is derived from it
+	TotalLatencyMs   float64    `json:"total_latency_ms"`
+	AverageLatencyMs float64    `json:"average_latency_ms"`
+	MaxLatencyMs     float64    `json:"max_latency_ms"`
+	FirstRequestAt   *time.Time `json:"first_request_at,omitempty"`
+	LastRequestAt    *time.Time `json:"last_request_at,omitempty"`
+}
+
+// SLOWindow is the availability and proxy error rate of an instance over a rolling window
+type SLOWindow struct {
+	// Window is 1h, 24h or 30d
+	Window        string `json:"window"`
+	HealthChecks  uint64 `json:"health_checks"`
+	HealthyChecks uint64 `json:"healthy_checks"`
+	// AvailabilityPercent is the share of healthy checks, absent without checks in the window
+	AvailabilityPercent *float64 `json:"availability_percent,omitempty"`
+	Requests            uint64   `json:"requests"`
+	Errors              uint64   `json:"errors"`
+	// ErrorRatePercent is the share of proxied requests answered with 5xx, absent without requests
+	ErrorRatePercent *float64 `json:"error_rate_percent,omitempty"`
+}
+
+// SLOReport is the rolling availability and error rate of one instance
+type SLOReport struct {
+	ServiceName string      `json:"service_name"`
+	Windows     []SLOWindow `json:"windows"`
+	// TrafficCollected is false when the proxy access log is not read, so requests are not counted
+	TrafficCollected bool      `json:"traffic_collected"`
+	Timestamp        time.Time `json:"timestamp"`
+}
+
+// SLOSummary is the rolling availability and error rate of all instances together and of each one
+type SLOSummary struct {
+	Windows   []SLOWindow            `json:"windows"`
+	Instances map[string][]SLOWindow `json:"instances"`
+}
+
+// WorkspaceTraffic sums the traffic of every instance a workspace owns or owned
+type WorkspaceTraffic struct {
+	WorkspaceID string `json:"workspace_id"`
+	Instances   int    `json:"instances"`
+	Requests    uint64 `json:"requests"`
+	Errors      uint64 `json:"errors"`
+	BytesIn     uint64 `json:"bytes_in"`
+	BytesOut    uint64 `json:"bytes_out"`
+}
+
+// TrafficUsage aggregates proxied traffic per instance and per workspace
+type TrafficUsage struct {
+	Instances  []TrafficStats     `json:"instances"`
+	Workspaces []WorkspaceTraffic `json:"workspaces"`
+	Timestamp  time.Time          `json:"timestamp"`
+}
+
+// ConnectionPoolStats reports the manager's connection pool to MCP containers. Dials close to
+// Requests means connections are not reused and each request costs an ephemeral port.
+type ConnectionPoolStats struct {
+	MaxIdleConnsPerHost int    `json:"max_idle_conns_per_host"`
+	IdleConnTimeout     string `json:"idle_conn_timeout"`
+	Requests            uint64 `json:"requests"`
+	Dials               uint64 `json:"dials"`
+	DialErrors          uint64 `json:"dial_errors"`
+	// ReuseRatio is the share of requests served over an existing connection
+	ReuseRatio    float64 `json:"reuse_ratio"`
+	OpenConns     int     `json:"open_conns"`
+	PeakOpenConns int     `json:"peak_open_conns"`
+	ClosedConns   uint64  `json:"closed_conns"`
+	// Saturation is the busiest container's open connections over MaxIdleConnsPerHost
+	Saturation         float64   `json:"saturation"`
+	HostsOverIdleLimit int       `json:"hosts_over_idle_limit"`
+	Timestamp          time.Time `json:"timestamp"`
+}
+
+// ManagerRegistration is the heartbeat a manager sends the Core API, which lists the
+// registered managers and treats one that stops sending it as dead
+type ManagerRegistration struct {
+	ManagerID   string `json:"manager_id"`
+	Version     string `json:"version"`
+	BackendType string `json:"backend_type"`
+	// URL is where the platform reaches the manager's HTTP API; empty when not advertised
+	URL      string          `json:"url,omitempty"`
+	Capacity ManagerCapacity `json:"capacity"`
+	// RunningInstances counts running instances, TotalInstances all instances the manager tracks
+	RunningInstances int `json:"running_instances"`
+	TotalInstances   int `json:"total_instances"`
+	// HeartbeatIntervalSeconds tells the platform how soon to expect the next heartbeat
+	HeartbeatIntervalSeconds int       `json:"heartbeat_interval_seconds"`
+	StartedAt                time.Time `json:"started_at"`
+	Timestamp                time.Time `json:"timestamp"`
+}
+
+// ManagerCapacity is how many instances and GPUs a manager can host
+type ManagerCapacity struct {
+	MaxInstances int `json:"max_instances"`
+	GPUs         int `json:"gpus,omitempty"`
+}
+
+// UnusedImage is an image no container has used since LastUsed
+type UnusedImage struct {
+	ID       string    `json:"id"`
+	Names    []string  `json:"names,omitempty"`
+	Size     uint64    `json:"size"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// AdoptContainerRequest brings a container started outside the manager under management
+type AdoptContainerRequest struct {
+	// Container is the name or ID of the running podman container
+	Container string `json:"container" binding:"required"`
+	// ServiceName defaults to the container name without the manager's name prefix
+	ServiceName string `json:"service_name,omitempty"`
+	// Port is the port the MCP server listens on inside the container
+	Port        int                `json:"port" binding:"required"`
+	InstanceID  string             `json:"instance_id,omitempty"`
+	HealthCheck *HealthCheckConfig `json:"health_check,omitempty"`
+	Route       *RouteConfig       `json:"route,omitempty"`
+}
+
+// CloneContainerRequest creates a new container from an existing container's effective spec
+type CloneContainerRequest struct {
+	ServiceName string `json:"service_name" binding:"required"`
+	// Environment is merged over the copied environment, for example to re-supply omitted secrets
+	Environment map[string]string `json:"environment,omitempty"`
+	// Snapshot commits the source container's filesystem to an image and runs the clone from it
+	Snapshot bool `json:"snapshot,omitempty"`
+}
+
+// UpdateEnvironmentRequest changes some of a container's environment variables
+type UpdateEnvironmentRequest struct {
+	// Environment sets each variable to its value, or removes it when the value is null. A
+	// "secret_ref:" value is resolved from the secret store for the container's instance.
+	Environment map[string]*string `json:"environment" binding:"required"`
+}
+
+// UpdateEnvironmentResponse describes a container after an environment update
+type UpdateEnvironmentResponse struct {
+	Container *Container `json:"container"`
+	// Changed lists the variables whose value changed, and Restarted whether the container was
+	// recreated with them, which it is not when nothing changed
+	Changed   []string `json:"changed"`
+	Restarted bool     `json:"restarted"`
+}
+
+// SlugAssignment is a slug and the instance it belongs to. A recreated instance is routed under
+// its slug again.
+type SlugAssignment struct {
+	Slug        string     `json:"slug"`
+	URL         string     `json:"url"`
+	InstanceID  string     `json:"instance_id,omitempty"`
+	ServiceName string     `json:"service_name"`
+	WorkspaceID string     `json:"workspace_id,omitempty"`
+	AssignedAt  *time.Time `json:"assigned_at,omitempty"`
+	// Container is the service routed under the slug now, empty while the instance does not exist
+	Container string `json:"container,omitempty"`
+	// Conflict explains why the slug does not route the instance it belongs to
+	Conflict string `json:"conflict,omitempty"`
+}
+
+// RenameSlugRequest moves a container's route to another slug
+type RenameSlugRequest struct {
+	Slug string `json:"slug" binding:"required"`
+}
+
+// SessionInstance is the container serving one session of an instance with per-session isolation
+type SessionInstance struct {
+	Session      string          `json:"session"`
+	ServiceName  string          `json:"service_name"`
+	Slug         string          `json:"slug"`
+	Status       ContainerStatus `json:"status"`
+	CreatedAt    time.Time       `json:"created_at"`
+	LastActiveAt *time.Time      `json:"last_active_at,omitempty"`
+}
+
+// SessionsResponse lists the session containers of an instance
+type SessionsResponse struct {
+	ServiceName string            `json:"service_name"`
+	Header      string            `json:"header"`
+	Sessions    []SessionInstance `json:"sessions"`
+}
+
+// SlugsResponse lists slug assignments
+type SlugsResponse struct {
+	Slugs     []SlugAssignment `json:"slugs"`
+	Conflicts int              `json:"conflicts"`
+}
+
+// CloneContainerResponse describes a container cloned from another one
+type CloneContainerResponse struct {
+	Container *Container `json:"container"`
+	Source    string     `json:"source"`
+	Image     string     `json:"image"`
+	Snapshot  bool       `json:"snapshot"`
+	// OmittedEnvironment lists variables that were not copied because they look like secrets
+	OmittedEnvironment []string `json:"omitted_environment,omitempty"`
+}
+
+// StageContainerRequest creates a staging copy of a container with a new version, served under a
+// preview URL until it is promoted. Unset fields keep the production container's values.
+type StageContainerRequest struct {
+	Image string `json:"image,omitempty"`
+	// Environment is merged over the production environment
+	Environment map[string]string `json:"environment,omitempty"`
+	Command     []string          `json:"command,omitempty"`
+	SmokeTest   *SmokeTest        `json:"smoke_test,omitempty"`
+	// Mirror copies a share of production traffic to the staged container once it passes its checks
+	Mirror *MirrorConfig `json:"mirror,omitempty"`
+}
+
+// MirrorConfig copies a share of production requests to a staged container, whose responses are
+// discarded, and compares the share of requests each fails with 5xx
+type MirrorConfig struct {
+	Percent int `json:"percent"`
+	// DurationSeconds is how long traffic is mirrored, 600 when unset
+	DurationSeconds int `json:"duration_seconds,omitempty"`
+	// MaxErrorRateIncrease is how much larger a share of requests the staged container may fail than
+	// production, 0.01 when unset
+	MaxErrorRateIncrease float64 `json:"max_error_rate_increase,omitempty"`
+}
+
+// MirrorComparison compares the answers of production and a staged container to the same period of
+// traffic, of which the staged container received Percent
+type MirrorComparison struct {
+	Percent             int     `json:"percent"`
+	ProductionRequests  uint64  `json:"production_requests"`
+	ProductionErrors    uint64  `json:"production_errors"`
+	ProductionErrorRate float64 `json:"production_error_rate"`
+	MirrorRequests      uint64  `json:"mirror_requests"`
+	MirrorErrors        uint64  `json:"mirror_errors"`
+	MirrorErrorRate     float64 `json:"mirror_error_rate"`
+	// Regressed is set when the staged container failed a larger share than production plus the allowed increase
+	Regressed bool      `json:"regressed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SmokeTest is an MCP tool call a staged container must answer without error to be promoted
+type SmokeTest struct {
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	// Path is the MCP endpoint on the container (default /mcp)
+	Path           string `json:"path,omitempty"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
+}
+
+// StagingCheck is the outcome of verifying a staged container before promotion
+type StagingCheck struct {
+	Passed      bool   `json:"passed"`
+	Healthy     bool   `json:"healthy"`
+	HealthError string `json:"health_error,omitempty"`
+	// SmokeTest is set when a smoke test is configured and the container is healthy
+	SmokeTest *SmokeTestResult `json:"smoke_test,omitempty"`
+}
+
+// SmokeTestResult is the outcome of a smoke test tool call
+type SmokeTestResult struct {
+	Tool       string `json:"tool"`
+	Passed     bool   `json:"passed"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// StagingResponse describes a staged container and its first verification
+type StagingResponse struct {
+	Container  *Container   `json:"container"`
+	PreviewURL string       `json:"preview_url"`
+	Check      StagingCheck `json:"check"`
+	// MirrorJob compares the staged container with production while traffic is mirrored to it
+	MirrorJob   *Job   `json:"mirror_job,omitempty"`
+	MirrorError string `json:"mirror_error,omitempty"`
+}
+
+// PromotionResult describes which container serves a service's production URL after a promotion
+// or rollback, and which one is kept to roll back to
+type PromotionResult struct {
+	ServiceName string        `json:"service_name"`
+	URL         string        `json:"url"`
+	Production  string        `json:"production"`
+	Previous    string        `json:"previous"`
+	Check       *StagingCheck `json:"check,omitempty"`
+}
+
+// Whoami describes the caller of the management API and its effective permissions
+type Whoami struct {
+	Subject     string   `json:"subject"`
+	Source      string   `json:"source"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+	// Workspaces limits the caller to instances of these workspaces; empty means every workspace
+	Workspaces []string `json:"workspaces,omitempty"`
+	// Enforced is false when the API accepts every caller as an admin
+	Enforced bool `json:"enforced"`
+}
+
+// SpecRevision is one spec an instance was deployed from. Values that may be credentials are
+// masked when it is returned.
+type SpecRevision struct {
+	Revision int                    `json:"revision"`
+	SpecHash string                 `json:"spec_hash"`
+	Spec     CreateContainerRequest `json:"spec"`
+	// RollbackOf is the revision this one redeployed, for revisions created by a rollback
+	RollbackOf int       `json:"rollback_of,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// SpecRevisionsResponse is the deployment history of an instance, oldest first
+type SpecRevisionsResponse struct {
+	InstanceID  string `json:"instance_id"`
+	ServiceName string `json:"service_name,omitempty"`
+	// Current is the revision the instance's container was last deployed from
+	Current   int            `json:"current"`
+	Revisions []SpecRevision `json:"revisions"`
+}
+
+// SpecRollbackResult is the outcome of redeploying a previous revision of an instance
+type SpecRollbackResult struct {
+	InstanceID   string     `json:"instance_id"`
+	RolledBackTo int        `json:"rolled_back_to"`
+	Revision     int        `json:"revision"`
+	Container    *Container `json:"container"`
+}
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Error   string `json:"error"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// EnvSchemaErrorResponse rejects a create whose environment does not match its env_schema
+type EnvSchemaErrorResponse struct {
+	ErrorResponse
+	Variables []EnvVarProblem `json:"variables"`
+}
+
+// MCPServerInstance represents an MCP server instance from events
+type MCPServerInstance struct {
+	InstanceID   string                 `json:"instance_id"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description,omitempty"`
+	ServerSpecID string                 `json:"server_spec_id,omitempty"`
+	WorkspaceID  string                 `json:"workspace_id,omitempty"`
+	JSONSpec     map[string]interface{} `json:"json_spec"`
+	Status       string                 `json:"status"`
+}
+
+// MCPEventData represents the data structure from Redis events
+type MCPEventData struct {
+	InstanceID   string                 `json:"instance_id"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description,omitempty"`
+	ServerSpecID string                 `json:"server_spec_id,omitempty"`
+	JSONSpec     map[string]interface{} `json:"json_spec"`
+}